@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/devnadeemashraf/depman/pkg/depman"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Allow command
+	allowCmd = &cobra.Command{
+		Use:   "allow [path]",
+		Short: "Let the shell hook apply a project's environment",
+		Long: `Allow trusts a configuration, as its files are now, to change the shell's
+environment through 'depman hook'. Until then the hook applies nothing for
+the project, so entering a cloned repository cannot run code from it. Any
+edit to the configuration, its includes or its local overlay revokes the
+trust until it is allowed again.
+
+Without a path, the configuration of the current directory or the closest of
+its parents is allowed.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runAllow(args, true)
+		},
+	}
+
+	// Deny command
+	denyCmd = &cobra.Command{
+		Use:   "deny [path]",
+		Short: "Stop the shell hook from applying a project's environment",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runAllow(args, false)
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(allowCmd, denyCmd)
+}
+
+// runAllow allows or denies the configuration the hook would apply
+func runAllow(args []string, allow bool) error {
+	config, err := trustedConfig(args)
+	if err != nil {
+		return err
+	}
+
+	if !allow {
+		if err := depman.DenyConfig(config); err != nil {
+			return err
+		}
+		fmt.Printf("The shell hook no longer applies %s\n", config)
+		return nil
+	}
+	if err := depman.AllowConfig(config); err != nil {
+		return err
+	}
+	fmt.Printf("The shell hook applies %s from the next prompt\n", config)
+	return nil
+}
+
+// trustedConfig returns the configuration allow and deny act on: the one
+// given, else that of --config, else the one the hook finds
+func trustedConfig(args []string) (string, error) {
+	path := configPath
+	if len(args) > 0 {
+		path = args[0]
+	}
+	if depman.IsRemoteConfig(path) {
+		return "", fmt.Errorf("remote configurations are not applied by the shell hook")
+	}
+	if path != "" {
+		return depman.FindDependencyFile(path)
+	}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	if config := findProjectConfig(dir); config != "" {
+		return config, nil
+	}
+	return "", fmt.Errorf("no dependency configuration found in %s or its parents", dir)
+}
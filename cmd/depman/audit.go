@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/devnadeemashraf/depman/pkg/depman"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Audit flags
+	auditFailOn string
+
+	// Audit command
+	auditCmd = &cobra.Command{
+		Use:   "audit",
+		Short: "Report known vulnerabilities in installed dependency versions",
+		Long: `Audit looks up each dependency's installed (or pinned) version in the OSV
+database (osv.dev) and lists known vulnerabilities with their severity and
+fixed versions. Dependencies need an audit.ecosystem, such as Go, npm or
+PyPI, to be looked up.
+
+It exits with an error when a vulnerability is at least as severe as
+--fail-on. Vulnerabilities of unknown severity always fail the audit.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runAudit(cmd.Context())
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+	auditCmd.Flags().StringVar(&auditFailOn, "fail-on", "low", "Lowest severity that fails the audit (low, medium, high, critical, none)")
+}
+
+// parseAuditFailOn parses the --fail-on severity, reporting false for "none"
+func parseAuditFailOn(value string) (depman.VulnerabilitySeverity, bool, error) {
+	if strings.EqualFold(value, "none") {
+		return depman.VulnerabilityUnknown, false, nil
+	}
+	severity, err := depman.ParseVulnerabilitySeverity(value)
+	if err != nil || severity == depman.VulnerabilityUnknown {
+		return depman.VulnerabilityUnknown, false, fmt.Errorf("invalid --fail-on value '%s' (expected low, medium, high, critical or none)", value)
+	}
+	return severity, true, nil
+}
+
+// runAudit prints the vulnerabilities found and applies the --fail-on gate
+func runAudit(ctx context.Context) error {
+	threshold, gate, err := parseAuditFailOn(auditFailOn)
+	if err != nil {
+		return err
+	}
+
+	manager, err := createManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize: %w", err)
+	}
+
+	results, err := manager.Audit(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to audit dependencies: %w", err)
+	}
+
+	failed := 0
+	for _, result := range results {
+		if gate && result.Exceeds(threshold) {
+			failed++
+		}
+	}
+
+	if jsonOutput() {
+		if err := printJSON(results); err != nil {
+			return err
+		}
+	} else {
+		printAuditResults(results)
+	}
+
+	if failed > 0 {
+		return &exitError{code: exitVulnerable, err: fmt.Errorf("%d of %d dependencies have vulnerabilities of %s severity or higher", failed, len(results), threshold)}
+	}
+	return nil
+}
+
+// printAuditResults prints a table of vulnerabilities and the dependencies that were not audited
+func printAuditResults(results []*depman.AuditResult) {
+	total := 0
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tVERSION\tID\tSEVERITY\tFIXED IN\tSUMMARY")
+	for _, result := range results {
+		for _, vuln := range result.Vulnerabilities {
+			id := vuln.ID
+			if len(vuln.Aliases) > 0 {
+				id += " (" + strings.Join(vuln.Aliases, ", ") + ")"
+			}
+			fixed := strings.Join(vuln.Fixed, ", ")
+			if fixed == "" {
+				fixed = "-"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", result.Name, result.Version, id, vuln.Severity, fixed, vuln.Summary)
+			total++
+		}
+	}
+	if total > 0 {
+		w.Flush()
+	} else {
+		fmt.Println("No known vulnerabilities found.")
+	}
+
+	var notAudited []string
+	for _, result := range results {
+		switch {
+		case result.Error != "":
+			notAudited = append(notAudited, fmt.Sprintf("- %s: %s", result.Name, result.Error))
+		case result.Skipped != "":
+			notAudited = append(notAudited, fmt.Sprintf("- %s: %s", result.Name, result.Skipped))
+		}
+	}
+	if len(notAudited) > 0 {
+		fmt.Println("\nNot audited:")
+		fmt.Println(strings.Join(notAudited, "\n"))
+	}
+}
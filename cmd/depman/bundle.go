@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Bundle command
+	bundleCmd = &cobra.Command{
+		Use:   "bundle",
+		Short: "Package artifacts for air-gapped installs",
+		Long: `A bundle holds every artifact the configuration downloads on this platform.
+Copy it to a machine without network access and run
+'depman ensure --offline --bundle <path>' there.`,
+	}
+
+	// Bundle create command
+	bundleCreateCmd = &cobra.Command{
+		Use:   "create [path]",
+		Short: "Download and verify all artifacts into a bundle (default: depman-bundle.tar.gz)",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := "depman-bundle.tar.gz"
+			if len(args) > 0 {
+				path = args[0]
+			}
+			return runBundleCreate(cmd.Context(), path)
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(bundleCmd)
+	bundleCmd.AddCommand(bundleCreateCmd)
+}
+
+// runBundleCreate writes an offline bundle for the configuration
+func runBundleCreate(ctx context.Context, path string) error {
+	manager, err := createManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize: %w", err)
+	}
+
+	manifest, err := manager.CreateBundle(ctx, path)
+	if err != nil {
+		return fmt.Errorf("failed to create bundle: %w", err)
+	}
+
+	if jsonOutput() {
+		return printJSON(manifest)
+	}
+
+	fmt.Printf("Bundled %d artifacts for %s/%s into %s\n", len(manifest.Artifacts), manifest.Platform, manifest.Arch, path)
+	for _, artifact := range manifest.Artifacts {
+		fmt.Printf("- %s %s: %s\n", artifact.Dependency, artifact.Version, artifact.URL)
+	}
+	for _, skipped := range manifest.Skipped {
+		fmt.Printf("- %s: skipped, needs network access to install\n", skipped)
+	}
+	return nil
+}
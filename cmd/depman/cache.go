@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devnadeemashraf/depman/internal/cache"
+	"github.com/devnadeemashraf/depman/pkg/depman"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Cache prune flags
+	pruneOlderThan string
+
+	// Cache command
+	cacheCmd = &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the download cache",
+		Long: `Downloaded artifacts are cached in ~/.depman/cache, keyed by URL and checksum,
+so installs with a known checksum are not downloaded twice.`,
+	}
+
+	// Cache list command
+	cacheListCmd = &cobra.Command{
+		Use:     "ls",
+		Aliases: []string{"list"},
+		Short:   "List cached artifacts",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCacheList()
+		},
+	}
+
+	// Cache clean command
+	cacheCleanCmd = &cobra.Command{
+		Use:   "clean",
+		Short: "Remove every cached artifact",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCacheClean()
+		},
+	}
+
+	// Cache prune command
+	cachePruneCmd = &cobra.Command{
+		Use:   "prune",
+		Short: "Remove cached artifacts that have not been used recently",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCachePrune()
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheListCmd, cacheCleanCmd, cachePruneCmd)
+	cachePruneCmd.Flags().StringVar(&pruneOlderThan, "older-than", "30d", "Remove artifacts not used for this long (e.g. 12h, 30d)")
+}
+
+// runCacheList prints the cached artifacts
+func runCacheList() error {
+	entries, err := cache.New(depman.DefaultCacheDir()).List()
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput() {
+		if entries == nil {
+			entries = []cache.Entry{}
+		}
+		return printJSON(entries)
+	}
+
+	if len(entries) == 0 {
+		fmt.Println("The download cache is empty")
+		return nil
+	}
+
+	var total int64
+	for _, entry := range entries {
+		total += entry.Size
+		fmt.Printf("%s\t%s\t%s\t%s\n", entry.Key[:12], formatSize(entry.Size), entry.LastUsed.Format("2006-01-02"), entry.URL)
+	}
+	fmt.Printf("%d artifacts, %s\n", len(entries), formatSize(total))
+	return nil
+}
+
+// runCacheClean empties the download cache
+func runCacheClean() error {
+	if err := cache.New(depman.DefaultCacheDir()).Clean(); err != nil {
+		return err
+	}
+
+	fmt.Println("Cleaned the download cache")
+	return nil
+}
+
+// runCachePrune removes artifacts not used within --older-than
+func runCachePrune() error {
+	age, err := parseAge(pruneOlderThan)
+	if err != nil {
+		return err
+	}
+
+	removed, err := cache.New(depman.DefaultCacheDir()).Prune(age)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput() {
+		if removed == nil {
+			removed = []cache.Entry{}
+		}
+		return printJSON(removed)
+	}
+
+	var total int64
+	for _, entry := range removed {
+		total += entry.Size
+	}
+	fmt.Printf("Removed %d artifacts (%s)\n", len(removed), formatSize(total))
+	return nil
+}
+
+// parseAge parses a duration, also accepting whole days such as "30d"
+func parseAge(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid age %q", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	age, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid age %q: %w", value, err)
+	}
+	return age, nil
+}
+
+// formatSize renders a byte count for humans
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/devnadeemashraf/depman/internal/selfupdate"
+	"github.com/devnadeemashraf/depman/pkg/depman"
+	"github.com/spf13/cobra"
+)
+
+// Completion command
+var completionCmd = &cobra.Command{
+	Use:   "completion bash|zsh|fish|powershell",
+	Short: "Generate a shell completion script",
+	Long: `Completion prints a completion script for the given shell. Dependency
+names, tags and profiles are completed from the loaded configuration.
+
+  bash:        source <(depman completion bash)
+  zsh:         depman completion zsh > "${fpath[1]}/_depman"
+  fish:        depman completion fish > ~/.config/fish/completions/depman.fish
+  powershell:  depman completion powershell | Out-String | Invoke-Expression`,
+	Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+	DisableFlagsInUseLine: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runCompletion(cmd, args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(completionCmd)
+	rootCmd.CompletionOptions.DisableDefaultCmd = true
+}
+
+// runCompletion writes the completion script for a shell to stdout
+func runCompletion(cmd *cobra.Command, shell string) error {
+	root := cmd.Root()
+	switch shell {
+	case "bash":
+		return root.GenBashCompletionV2(os.Stdout, true)
+	case "zsh":
+		return root.GenZshCompletion(os.Stdout)
+	case "fish":
+		return root.GenFishCompletion(os.Stdout, true)
+	case "powershell":
+		return root.GenPowerShellCompletionWithDesc(os.Stdout)
+	default:
+		return fmt.Errorf("unsupported shell '%s' (expected bash, zsh, fish or powershell)", shell)
+	}
+}
+
+// registerCompletions completes arguments and flag values from the
+// configuration. It runs after every command has defined its flags.
+func registerCompletions() {
+	for _, cmd := range []*cobra.Command{installCmd, removeCmd, useCmd, updateCmd, rollbackCmd} {
+		cmd.ValidArgsFunction = completeDependencyNames
+	}
+	runCmd.ValidArgsFunction = completeTasks
+
+	flags := map[string]func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective){
+		"profile":    completeProfiles,
+		"platform":   fixedCompletions("windows", "linux", "darwin", "freebsd", "openbsd", "netbsd"),
+		"arch":       fixedCompletions("amd64", "arm64", "386", "arm", "riscv64", "ppc64le", "s390x"),
+		"distro":     fixedCompletions("ubuntu", "debian", "fedora", "rhel", "centos", "rocky", "almalinux", "amzn", "opensuse-leap", "arch", "alpine"),
+		"scope":      fixedCompletions(string(depman.ScopeProject), string(depman.ScopeGlobal)),
+		"privilege":  fixedCompletions("sudo", "doas", "fail", "prompt"),
+		"log-format": fixedCompletions("text", "json"),
+		"output":     fixedCompletions("text", "json"),
+	}
+	for name, complete := range flags {
+		rootCmd.RegisterFlagCompletionFunc(name, complete)
+	}
+
+	for _, cmd := range []*cobra.Command{checkCmd, ensureCmd, listCmd} {
+		cmd.RegisterFlagCompletionFunc("only", completeDependencyNames)
+		cmd.RegisterFlagCompletionFunc("tag", completeTags)
+		cmd.RegisterFlagCompletionFunc("skip", completeNamesAndTags)
+	}
+	checkCmd.RegisterFlagCompletionFunc("fail-on", fixedCompletions("missing", "incompatible", "outdated", "major", "minor", "patch", "error", "all"))
+	auditCmd.RegisterFlagCompletionFunc("fail-on", fixedCompletions("low", "medium", "high", "critical", "none"))
+	sbomCmd.RegisterFlagCompletionFunc("format", fixedCompletions(string(depman.SBOMCycloneDX), string(depman.SBOMSPDX)))
+	updateCmd.RegisterFlagCompletionFunc("level", fixedCompletions("patch", "minor", "major"))
+	selfUpdateCmd.RegisterFlagCompletionFunc("channel", fixedCompletions(string(selfupdate.ChannelStable), string(selfupdate.ChannelBeta)))
+	envCmd.RegisterFlagCompletionFunc("shell", fixedCompletions("sh", "bash", "zsh", "fish", "powershell", "cmd"))
+}
+
+// fixedCompletions completes a flag from a fixed list of values
+func fixedCompletions(values ...string) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return values, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completionConfig loads the configuration for completions without logging,
+// returning nil if it cannot be loaded
+func completionConfig() *depman.DependencyConfig {
+	manager, err := createManager(depman.WithLogOutput(io.Discard))
+	if err != nil {
+		return nil
+	}
+	return manager.Config
+}
+
+// completeDependencyNames completes dependency names not already given
+func completeDependencyNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	config := completionConfig()
+	if config == nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	given := make(map[string]bool)
+	for _, arg := range args {
+		name, _, _ := strings.Cut(arg, "@")
+		given[name] = true
+	}
+
+	var names []string
+	for _, dep := range config.Dependencies {
+		if !given[dep.Name] {
+			names = append(names, completionEntry(dep.Name, dep.Description))
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeTags completes the tags used in the configuration
+func completeTags(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	config := completionConfig()
+	if config == nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return configTags(config), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeNamesAndTags completes dependency names and tags, as accepted by --skip
+func completeNamesAndTags(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	config := completionConfig()
+	if config == nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var values []string
+	for _, dep := range config.Dependencies {
+		values = append(values, completionEntry(dep.Name, dep.Description))
+	}
+	return append(values, configTags(config)...), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeTasks completes the task names the configuration defines, as the
+// first argument only; later ones belong to the task
+func completeTasks(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+	config := completionConfig()
+	if config == nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var names []string
+	for name, task := range config.Tasks {
+		names = append(names, completionEntry(name, task.Description))
+	}
+	sort.Strings(names)
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeProfiles completes the profiles the configuration defines
+func completeProfiles(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	// The profile being completed must not be applied while loading
+	profile = ""
+	os.Unsetenv("DEPMAN_PROFILE")
+
+	config := completionConfig()
+	if config == nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var names []string
+	for name := range config.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// configTags returns the distinct tags of the configured dependencies, sorted
+func configTags(config *depman.DependencyConfig) []string {
+	seen := make(map[string]bool)
+	var tags []string
+	for _, dep := range config.Dependencies {
+		for _, tag := range dep.Tags {
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// completionEntry returns a completion with its description, shown by shells that support it
+func completionEntry(value, description string) string {
+	if description == "" {
+		return value
+	}
+	return value + "\t" + description
+}
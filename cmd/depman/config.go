@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/devnadeemashraf/depman/pkg/depman"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Config command
+	configCmd = &cobra.Command{
+		Use:   "config",
+		Short: "Manage user-level settings",
+		Long: `Settings are defaults for every project on this machine, kept in
+~/.config/depman/config.yaml (or config.toml, or under $XDG_CONFIG_HOME):
+
+  home_dir     Root directory for files managed by depman (default ~/.depman)
+  cache_dir    Directory of the download cache (default <home_dir>/cache)
+  proxy        Proxy for every request, instead of HTTPS_PROXY and HTTP_PROXY
+  jobs         Dependencies to check or install in parallel
+  policy_file  Organization policy file instead of the machine-wide one
+  credentials  How to authenticate to artifact hosts, as a YAML list
+  registries   Recipe registries, as a YAML list
+
+Flags override settings, and $DEPMAN_HOME or --home overrides home_dir and
+cache_dir. The credentials of a project configuration replace those of the
+settings for the same host.`,
+	}
+
+	// Config list command
+	configListCmd = &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "Print the settings that are set",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runConfigList()
+		},
+	}
+
+	// Config get command
+	configGetCmd = &cobra.Command{
+		Use:   "get <key>",
+		Short: "Print a setting, or nothing if it is not set",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runConfigGet(args[0])
+		},
+	}
+
+	// Config set command
+	configSetCmd = &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Change a setting; an empty value removes it",
+		Long: `Set changes a setting in the settings file. Lists are given as YAML, e.g.
+
+  depman config set jobs 4
+  depman config set proxy http://proxy.internal:3128
+  depman config set credentials '[{host: artifacts.acme.internal, type: bearer}]'
+  depman config set cache_dir ""`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runConfigSet(args[0], args[1])
+		},
+	}
+
+	// Config path command
+	configPathCmd = &cobra.Command{
+		Use:   "path",
+		Short: "Print the path of the settings file",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Println(depman.SettingsPath())
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configListCmd, configGetCmd, configSetCmd, configPathCmd)
+}
+
+// runConfigList prints every setting that is set as key=value
+func runConfigList() error {
+	settings, err := depman.LoadSettings(depman.SettingsPath())
+	if err != nil {
+		return err
+	}
+
+	values := make(map[string]string)
+	for _, key := range depman.SettingKeys {
+		value, err := settings.Get(key)
+		if err != nil {
+			return err
+		}
+		if value != "" {
+			values[key] = value
+		}
+	}
+	if jsonOutput() {
+		return printJSON(values)
+	}
+	for _, key := range depman.SettingKeys {
+		if value, ok := values[key]; ok {
+			fmt.Printf("%s=%s\n", key, value)
+		}
+	}
+	return nil
+}
+
+// runConfigGet prints one setting
+func runConfigGet(key string) error {
+	settings, err := depman.LoadSettings(depman.SettingsPath())
+	if err != nil {
+		return err
+	}
+	value, err := settings.Get(key)
+	if err != nil {
+		return err
+	}
+	if value != "" {
+		fmt.Println(value)
+	}
+	return nil
+}
+
+// runConfigSet changes one setting and writes the settings file
+func runConfigSet(key, value string) error {
+	path := depman.SettingsPath()
+	settings, err := depman.LoadSettings(path)
+	if err != nil {
+		return err
+	}
+	if err := settings.Set(key, value); err != nil {
+		return err
+	}
+	if err := depman.SaveSettings(path, settings); err != nil {
+		return err
+	}
+	if value == "" {
+		fmt.Printf("Removed %s from %s\n", key, path)
+	} else {
+		fmt.Printf("Set %s in %s\n", key, path)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/devnadeemashraf/depman/pkg/depman"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Diff flags
+	diffJSON bool
+
+	// Diff command
+	diffCmd = &cobra.Command{
+		Use:   "diff",
+		Short: "Show how installed dependencies differ from the configuration",
+		Long: `Diff compares the desired state, the configuration with versions and
+sources from the lockfile where it has them, with this machine, and prints it
+like a unified diff: lines starting with - are desired, lines starting with +
+are installed. It reports missing dependencies, version drift, installs from
+other sources than configured, and tools depman installed for this
+configuration that it no longer defines. It exits with code 9 when anything
+differs, so CI can gate on it.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if diffJSON {
+				outputFormat = "json"
+			}
+			return runDiff(cmd.Context())
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+	diffCmd.Flags().BoolVar(&diffJSON, "json", false, "Print the differences as JSON (same as --output json)")
+	diffCmd.Flags().StringSliceVar(&onlyDeps, "only", nil, "Only these dependencies (comma-separated), plus what they depend on")
+	diffCmd.Flags().StringSliceVar(&skipDeps, "skip", nil, "Leave out dependencies with these names or tags, unless a selected one depends on them")
+	diffCmd.Flags().StringSliceVar(&tags, "tag", nil, "Only dependencies with any of these tags, plus what they depend on")
+}
+
+// runDiff prints the differences and fails if there are any
+func runDiff(ctx context.Context) error {
+	manager, err := createManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize: %w", err)
+	}
+
+	diff, err := manager.Diff(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to compare dependencies: %w", err)
+	}
+
+	if jsonOutput() {
+		if err := printJSON(diff); err != nil {
+			return err
+		}
+	} else {
+		printDiff(os.Stdout, manager, diff, colorOutput(os.Stdout))
+	}
+
+	if !diff.Empty() {
+		return &exitError{code: exitDiffers, err: fmt.Errorf("%d dependencies differ from the configuration", len(diff.Entries))}
+	}
+	return nil
+}
+
+// printDiff prints the differences as a unified diff of desired and installed
+func printDiff(w io.Writer, manager *depman.Manager, diff *depman.Diff, color bool) {
+	if diff.Empty() {
+		fmt.Fprintf(w, "No differences: %d dependencies are installed as configured\n", diff.Unchanged)
+		return
+	}
+
+	line := func(prefix, code, format string, args ...interface{}) {
+		fmt.Fprintln(w, colorize(prefix+fmt.Sprintf(format, args...), code, color))
+	}
+	removed := func(format string, args ...interface{}) { line("-", colorRed, format, args...) }
+	added := func(format string, args ...interface{}) { line("+", colorGreen, format, args...) }
+
+	line("--- ", colorBold, "desired (%s)", manager.ConfigPath)
+	line("+++ ", colorBold, "installed")
+	for _, entry := range diff.Entries {
+		line("@@ ", colorCyan, "%s: %s @@", entry.Name, describeDiff(entry))
+		switch entry.Kind {
+		case depman.DiffMissing:
+			removed("%s %s", entry.Name, entry.Desired)
+		case depman.DiffDrift:
+			removed("%s %s", entry.Name, entry.Desired)
+			added("%s %s", entry.Name, entry.Installed)
+		case depman.DiffSource:
+			removed("%s %s", entry.Name, entry.DesiredSource)
+			added("%s %s", entry.Name, entry.InstalledSource)
+		case depman.DiffExtra:
+			added("%s %s", entry.Name, entry.Installed)
+		}
+	}
+	fmt.Fprintf(w, "%d differ, %d unchanged\n", len(diff.Entries), diff.Unchanged)
+}
+
+// describeDiff summarizes a difference for its hunk header
+func describeDiff(entry *depman.DiffEntry) string {
+	switch entry.Kind {
+	case depman.DiffMissing:
+		return "not installed"
+	case depman.DiffSource:
+		return "installed from another source"
+	default:
+		return entry.Reason
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/devnadeemashraf/depman/pkg/depman"
+	"github.com/spf13/cobra"
+)
+
+// Doctor command
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose problems with the environment depman installs into",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDoctor()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
+
+// runDoctor prints every finding with a suggested fix
+func runDoctor() error {
+	manager, err := createManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize: %w", err)
+	}
+
+	findings := manager.Diagnose()
+
+	errors := 0
+	for _, finding := range findings {
+		if finding.Severity == depman.SeverityError {
+			errors++
+		}
+	}
+
+	if jsonOutput() {
+		if err := printJSON(findings); err != nil {
+			return err
+		}
+	} else {
+		check := ""
+		for _, finding := range findings {
+			if finding.Check != check {
+				if check != "" {
+					fmt.Println()
+				}
+				check = finding.Check
+				fmt.Printf("%s:\n", check)
+			}
+
+			marker := "ok"
+			switch finding.Severity {
+			case depman.SeverityWarning:
+				marker = "warn"
+			case depman.SeverityError:
+				marker = "FAIL"
+			}
+			fmt.Printf("  [%s] %s\n", marker, finding.Message)
+			if finding.Fix != "" {
+				fmt.Printf("         fix: %s\n", finding.Fix)
+			}
+		}
+	}
+
+	if errors > 0 {
+		return fmt.Errorf("doctor found %d problem(s)", errors)
+	}
+	return nil
+}
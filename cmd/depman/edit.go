@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/devnadeemashraf/depman/pkg/depman"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Add flags
+	addConstraint  string
+	addRequired    string
+	addDescription string
+	addMethod      string
+	addRepo        string
+	addPackage     string
+	addFormula     string
+	addModule      string
+	addPlatforms   []string
+	addDependsOn   []string
+	addTags        []string
+
+	// Rm flags
+	rmForce bool
+
+	// Add command
+	addCmd = &cobra.Command{
+		Use:   "add <name>",
+		Short: "Add a dependency to the configuration file",
+		Long: `Add appends a dependency to the configuration file, keeping its comments.
+Tools with a recipe in a registry (see 'depman registry search') only need a
+name and are added as "from: registry/<name>", or "from: <registry>/<name>"
+when given as <registry>/<name>; --version overrides the
+recipe's constraint and --method its installers on the --platform list. Other
+tools need --method and the flags it uses, e.g.
+
+  depman add jq --version ">=1.7"
+  depman add jq --version ">=1.7" --method github-release --repo jqlang/jq
+  depman add ripgrep --version ">=14" --method brew --formula ripgrep --platform darwin`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runAdd(args[0])
+		},
+	}
+
+	// Rm command
+	rmCmd = &cobra.Command{
+		Use:   "rm <name>...",
+		Short: "Remove dependencies from the configuration file without uninstalling them",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRm(args)
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(addCmd)
+	addCmd.Flags().StringVar(&addConstraint, "version", "", "Version constraint, e.g. \">=1.7\" (defaults to the recipe's)")
+	addCmd.Flags().StringVar(&addRequired, "required", "", "Exact version to pin")
+	addCmd.Flags().StringVar(&addDescription, "description", "", "Description of the dependency")
+	addCmd.Flags().StringVar(&addMethod, "method", "", "Install method, e.g. brew, system, winget, github-release, npm or go")
+	addCmd.Flags().StringVar(&addRepo, "repo", "", "GitHub repository in owner/name form (github-release)")
+	addCmd.Flags().StringVar(&addPackage, "package", "", "Package name (defaults to the dependency name)")
+	addCmd.Flags().StringVar(&addFormula, "formula", "", "Homebrew formula (brew; defaults to the dependency name)")
+	addCmd.Flags().StringVar(&addModule, "module", "", "Package path passed to go install (go)")
+	addCmd.Flags().StringSliceVar(&addPlatforms, "platform", []string{"darwin", "linux", "windows"}, "Platforms the install method applies to")
+	addCmd.Flags().StringSliceVar(&addDependsOn, "depends-on", nil, "Dependencies to install first")
+	addCmd.Flags().StringSliceVar(&addTags, "tag", nil, "Tags for selecting the dependency with --tag")
+
+	rootCmd.AddCommand(rmCmd)
+	rmCmd.Flags().BoolVarP(&rmForce, "force", "f", false, "Remove even if other dependencies depend on it, dropping those references")
+}
+
+// editablePath returns the local configuration file add and rm edit
+func editablePath() (string, error) {
+	if depman.IsRemoteConfig(configPath) {
+		return "", fmt.Errorf("%s is a remote configuration and cannot be edited", configPath)
+	}
+	return depman.FindDependencyFile(configPath)
+}
+
+// runAdd adds a registry or flag-described dependency to the configuration file
+func runAdd(name string) error {
+	path, err := editablePath()
+	if err != nil {
+		return err
+	}
+
+	registry, err := depman.LoadRegistry(depman.DefaultHomeDir())
+	if err != nil {
+		return err
+	}
+	dep, ok := registry.Dependency(name, addConstraint)
+	if !ok && addMethod == "" {
+		return fmt.Errorf("%s has no recipe in the registries (%s); describe how to install it with --method", name, strings.Join(registry.Names(), ", "))
+	}
+	if !ok {
+		dep = depman.NewDependency(name, addConstraint)
+	}
+	if addMethod != "" {
+		installer := depman.Installer{
+			Method:  addMethod,
+			Repo:    addRepo,
+			Package: addPackage,
+			Formula: addFormula,
+			Module:  addModule,
+		}
+		for _, platform := range addPlatforms {
+			dep = dep.OnPlatform(platform, depman.PlatformConfig{Installer: installer})
+		}
+	}
+	if addRequired != "" {
+		dep.Version.Required = addRequired
+	}
+	if addDescription != "" {
+		dep.Description = addDescription
+	}
+	dep = dep.DependsOn(addDependsOn...).Tagged(addTags...)
+
+	if err := depman.AddToConfig(path, dep); err != nil {
+		return err
+	}
+	fmt.Printf("Added %s to %s\n", dep.Name, path)
+	return nil
+}
+
+// runRm removes dependencies from the configuration file
+func runRm(names []string) error {
+	path, err := editablePath()
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		if err := depman.RemoveFromConfig(path, name, rmForce); err != nil {
+			return err
+		}
+		fmt.Printf("Removed %s from %s\n", name, path)
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/devnadeemashraf/depman/pkg/depman"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Env flags
+	envShell string
+
+	// Env command
+	envCmd = &cobra.Command{
+		Use:   "env",
+		Short: "Print shell commands that put depman-managed tools on PATH",
+		Long: `Print shell commands that put depman-managed tools on PATH.
+
+Add the output to your shell profile, for example:
+
+  eval "$(depman env)"                 # bash, zsh
+  depman env --shell fish | source     # fish
+  depman env --shell powershell | iex  # PowerShell
+
+With --shell direnv, the output is meant for a project's .envrc: it uses
+direnv's PATH_add and reloads when the configuration changes.
+
+  echo 'eval "$(depman env --shell direnv)"' > .envrc`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runEnv()
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(envCmd)
+	envCmd.Flags().StringVar(&envShell, "shell", "", "Shell syntax to print (sh, bash, zsh, fish, powershell, cmd, direnv; default: detected)")
+}
+
+// runEnv prints the activation commands for the selected shell
+func runEnv() error {
+	logsToStderr = true
+	manager, err := createManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize: %w", err)
+	}
+
+	activation, err := manager.ActivationEnvironment()
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput() {
+		return printJSON(activation)
+	}
+
+	shell := envShell
+	if shell == "" {
+		shell = detectShell()
+	}
+
+	// Names are printed as they are, so only valid ones may get this far
+	var keys []string
+	for key := range activation.Variables {
+		if err := depman.ValidateVariableName(key); err != nil {
+			return err
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	switch shell {
+	case "sh", "bash", "zsh":
+		fmt.Printf("export PATH=\"%s:$PATH\"\n", shellQuote(strings.Join(activation.Paths, ":")))
+		for _, key := range keys {
+			fmt.Printf("export %s=\"%s\"\n", key, shellQuote(activation.Variables[key]))
+		}
+	case "direnv":
+		// PATH_add prepends, so the first entry goes last
+		for _, file := range envConfigFiles() {
+			fmt.Printf("watch_file \"%s\"\n", shellQuote(file))
+		}
+		for i := len(activation.Paths) - 1; i >= 0; i-- {
+			fmt.Printf("PATH_add \"%s\"\n", shellQuote(activation.Paths[i]))
+		}
+		for _, key := range keys {
+			fmt.Printf("export %s=\"%s\"\n", key, shellQuote(activation.Variables[key]))
+		}
+	case "fish":
+		var paths []string
+		for _, path := range activation.Paths {
+			paths = append(paths, fishQuote(path))
+		}
+		fmt.Printf("set -gx PATH %s $PATH\n", strings.Join(paths, " "))
+		for _, key := range keys {
+			fmt.Printf("set -gx %s %s\n", key, fishQuote(activation.Variables[key]))
+		}
+	case "powershell", "pwsh":
+		fmt.Printf("$env:PATH = %s + [IO.Path]::PathSeparator + $env:PATH\n", powershellQuote(strings.Join(activation.Paths, string(os.PathListSeparator))))
+		for _, key := range keys {
+			fmt.Printf("$env:%s = %s\n", key, powershellQuote(activation.Variables[key]))
+		}
+	case "cmd":
+		fmt.Printf("set \"PATH=%s;%%PATH%%\"\n", strings.Join(activation.Paths, ";"))
+		for _, key := range keys {
+			fmt.Printf("set \"%s=%s\"\n", key, activation.Variables[key])
+		}
+	default:
+		return fmt.Errorf("unsupported shell '%s' (expected sh, bash, zsh, fish, powershell, cmd or direnv)", shell)
+	}
+
+	return nil
+}
+
+// envConfigFiles returns the local configuration files direnv should watch,
+// or none for a remote configuration
+func envConfigFiles() []string {
+	if depman.IsRemoteConfig(configPath) {
+		return nil
+	}
+	path, err := depman.FindDependencyFile(configPath)
+	if err != nil {
+		return nil
+	}
+	return depman.ConfigFiles(path)
+}
+
+// detectShell guesses the user's shell from the environment
+func detectShell() string {
+	if shell := os.Getenv("SHELL"); shell != "" {
+		return filepath.Base(shell)
+	}
+	if runtime.GOOS == "windows" {
+		if os.Getenv("PSModulePath") != "" {
+			return "powershell"
+		}
+		return "cmd"
+	}
+	return "sh"
+}
+
+// shellQuote escapes a value for use inside double quotes in POSIX shells
+func shellQuote(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`").Replace(s)
+}
+
+// fishQuote single-quotes a value for fish
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
+
+// powershellQuote single-quotes a value for PowerShell
+func powershellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
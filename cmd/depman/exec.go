@@ -0,0 +1,147 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Exec flags
+	execNoEnsure bool
+
+	// Exec command
+	execCmd = &cobra.Command{
+		Use:   "exec [flags] [--] <command> [args...]",
+		Short: "Run a command with the managed toolchain, installing what is missing first",
+		Long: `Exec ensures the dependencies, then runs a command with the managed bin
+directories in front of PATH and every dependency's environment settings
+applied, as 'depman env' would set them in a shell, like 'bundle exec' for
+system tools. --tag, --only and --skip limit what is ensured, e.g. to the
+tools a Makefile target needs:
+
+  lint:
+  	depman exec --tag lint -- golangci-lint run
+
+Depman logs only warnings and errors, to stderr, so the command's output is
+left alone. Exec exits with the command's exit code.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runExec(cmd, args)
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(execCmd)
+	// Flags after the command belong to it
+	execCmd.Flags().SetInterspersed(false)
+	execCmd.Flags().BoolVar(&execNoEnsure, "no-ensure", false, "Run the command without installing or updating anything first")
+	execCmd.Flags().StringSliceVar(&onlyDeps, "only", nil, "Only ensure these dependencies (comma-separated), plus what they depend on")
+	execCmd.Flags().StringSliceVar(&skipDeps, "skip", nil, "Do not ensure dependencies with these names or tags, unless a selected one depends on them")
+	execCmd.Flags().StringSliceVar(&tags, "tag", nil, "Only ensure dependencies with any of these tags, plus what they depend on")
+}
+
+// runExec ensures the dependencies and runs the command with the managed
+// environment, exiting with its exit code
+func runExec(cmd *cobra.Command, args []string) error {
+	logsToStderr = true
+	if !rootCmd.PersistentFlags().Changed("log-level") && !verbose {
+		logLevel = "warn"
+	}
+
+	manager, err := createManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize: %w", err)
+	}
+
+	if !execNoEnsure {
+		result, err := manager.Ensure(cmd.Context())
+		if err != nil {
+			if result != nil {
+				printFailures(os.Stderr, result.Statuses, ensureGroup, true, colorOutput(os.Stderr))
+			}
+			return fmt.Errorf("failed to ensure dependencies: %w", err)
+		}
+	}
+
+	activation, err := manager.ActivationEnvironment()
+	if err != nil {
+		return err
+	}
+	environ := activation.Environ(os.Environ())
+
+	// Look the command up on the activated PATH, not depman's own
+	path, err := lookPath(args[0], environ)
+	if err != nil {
+		return err
+	}
+
+	command := exec.Command(path, args[1:]...)
+	command.Env = environ
+	command.Stdin, command.Stdout, command.Stderr = os.Stdin, os.Stdout, os.Stderr
+
+	// Interrupts reach the command through the terminal; depman waits for
+	// it to exit instead of being stopped first
+	err = command.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		code := exitErr.ExitCode()
+		if code < 0 {
+			code = exitFailure
+		}
+		return &exitError{code: code, err: fmt.Errorf("%s exited with status %d", args[0], code)}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to run %s: %w", args[0], err)
+	}
+	return nil
+}
+
+// lookPath finds an executable as exec.LookPath does, but on the PATH of
+// environ
+func lookPath(name string, environ []string) (string, error) {
+	if strings.ContainsAny(name, `/\`) {
+		return name, nil
+	}
+	for _, entry := range environ {
+		key, value, _ := strings.Cut(entry, "=")
+		if key != "PATH" && !(runtime.GOOS == "windows" && strings.EqualFold(key, "PATH")) {
+			continue
+		}
+		for _, dir := range filepath.SplitList(value) {
+			if dir == "" {
+				continue
+			}
+			for _, candidate := range executableNames(name) {
+				path := filepath.Join(dir, candidate)
+				if info, err := os.Stat(path); err == nil && !info.IsDir() && (runtime.GOOS == "windows" || info.Mode()&0111 != 0) {
+					return path, nil
+				}
+			}
+		}
+	}
+	return "", fmt.Errorf("%s not found in the managed bin directories or on PATH", name)
+}
+
+// executableNames returns the file names an executable may have on this
+// platform
+func executableNames(name string) []string {
+	if runtime.GOOS != "windows" || filepath.Ext(name) != "" {
+		return []string{name}
+	}
+	var names []string
+	for _, ext := range filepath.SplitList(os.Getenv("PATHEXT")) {
+		names = append(names, name+strings.ToLower(ext))
+	}
+	if len(names) == 0 {
+		names = []string{name + ".exe", name + ".cmd", name + ".bat"}
+	}
+	return names
+}
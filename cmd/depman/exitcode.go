@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/devnadeemashraf/depman/pkg/depman"
+)
+
+// Exit codes. When check finds several kinds of problems it exits with the
+// most severe one, which is the lowest code.
+const (
+	exitFailure      = 1 // Any error not listed below
+	exitCheckError   = 2 // A dependency's installed version could not be checked
+	exitMissing      = 3 // A dependency is not installed
+	exitIncompatible = 4 // An installed version violates its constraint
+	exitOutdated     = 5 // An installed version is older than required or available
+	exitVulnerable   = 6 // An installed version has known vulnerabilities (audit)
+	exitLicense      = 7 // A dependency's license violates the license policy (licenses)
+	exitChanged      = 8 // Ensure applied changes (with --detailed-exitcode)
+	exitDiffers      = 9 // Installed dependencies differ from the configuration (diff)
+)
+
+// successCode is the exit code of a run that did not fail, set by commands
+// whose successful runs can end in more than one way
+var successCode = 0
+
+// exitError is an error that ends the process with a specific exit code
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// problemExitCodes maps each check problem to its exit code
+var problemExitCodes = map[depman.Problem]int{
+	depman.ProblemError:        exitCheckError,
+	depman.ProblemMissing:      exitMissing,
+	depman.ProblemIncompatible: exitIncompatible,
+	depman.ProblemMajor:        exitOutdated,
+	depman.ProblemMinor:        exitOutdated,
+	depman.ProblemPatch:        exitOutdated,
+}
+
+// parseFailOn parses a --fail-on list such as "missing,major" into the set of
+// problems that fail the check. "outdated" stands for major, minor and patch,
+// and "all" for every problem.
+func parseFailOn(value string) (map[depman.Problem]bool, error) {
+	failOn := make(map[depman.Problem]bool)
+	for _, name := range strings.Split(value, ",") {
+		switch problem := depman.Problem(strings.ToLower(strings.TrimSpace(name))); problem {
+		case "all":
+			for p := range problemExitCodes {
+				failOn[p] = true
+			}
+		case "outdated":
+			failOn[depman.ProblemMajor] = true
+			failOn[depman.ProblemMinor] = true
+			failOn[depman.ProblemPatch] = true
+		case depman.ProblemError, depman.ProblemMissing, depman.ProblemIncompatible,
+			depman.ProblemMajor, depman.ProblemMinor, depman.ProblemPatch:
+			failOn[problem] = true
+		case "":
+		default:
+			return nil, fmt.Errorf("invalid --fail-on value '%s' (expected missing, incompatible, outdated, major, minor, patch, error or all)", name)
+		}
+	}
+	return failOn, nil
+}
+
+// checkFailure returns an error carrying the exit code of the most severe
+// problem in statuses that failOn includes, or nil if there is none
+func checkFailure(statuses []*depman.DependencyStatus, failOn map[depman.Problem]bool) error {
+	code, failed := 0, 0
+	for _, status := range statuses {
+		problem := status.Problem()
+		if problem == depman.ProblemNone || !failOn[problem] {
+			continue
+		}
+		failed++
+		if c := problemExitCodes[problem]; code == 0 || c < code {
+			code = c
+		}
+	}
+	if failed == 0 {
+		return nil
+	}
+	return &exitError{code: code, err: fmt.Errorf("%d of %d dependencies need attention", failed, len(statuses))}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// Explain command
+var explainCmd = &cobra.Command{
+	Use:   "explain <dependency>",
+	Short: "Explain how ensure handles a dependency",
+	Long: `Explain runs the same planning as 'depman ensure --dry-run' and prints every
+decision it made for one dependency: why it is in the plan, which dependencies
+require it, which platform entry and install method were selected, which
+version would be installed and how it was chosen, and what ensure would do.
+Global flags such as --profile, --platform and --frozen apply as they do for
+ensure, as do --only, --tag and --skip.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runExplain(cmd.Context(), args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(explainCmd)
+	explainCmd.Flags().StringSliceVar(&onlyDeps, "only", nil, "Explain the plan for only these dependencies (comma-separated), plus what they depend on")
+	explainCmd.Flags().StringSliceVar(&skipDeps, "skip", nil, "Explain the plan without dependencies with these names or tags")
+	explainCmd.Flags().StringSliceVar(&tags, "tag", nil, "Explain the plan for only dependencies with any of these tags")
+}
+
+// runExplain prints the decisions made for a dependency
+func runExplain(ctx context.Context, name string) error {
+	manager, err := createManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize: %w", err)
+	}
+
+	e, err := manager.Explain(ctx, name)
+	if err != nil {
+		return err
+	}
+	if jsonOutput() {
+		return printJSON(e)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	inPlan := "no"
+	if e.Selected {
+		inPlan = "yes"
+	}
+	fmt.Fprintf(w, "Dependency:\t%s\n", e.Name)
+	fmt.Fprintf(w, "In plan:\t%s (%s)\n", inPlan, strings.Join(e.Reasons, "; "))
+	fmt.Fprintf(w, "Required by:\t%s\n", listOrNone(e.RequiredBy))
+	fmt.Fprintf(w, "Requires:\t%s\n", listOrNone(e.Requires))
+	if e.Recipe != "" {
+		fmt.Fprintf(w, "Recipe:\t%s (registry %s)\n", e.Recipe, orNone(e.RecipeRegistry))
+	}
+
+	var version []string
+	if e.Required != "" {
+		version = append(version, "required "+e.Required)
+	}
+	if e.Constraint != "" {
+		version = append(version, "constraint "+e.Constraint)
+	}
+	fmt.Fprintf(w, "Version:\t%s\n", listOrNone(version))
+
+	fmt.Fprintf(w, "Target:\t%s\n", e.Target)
+	switch {
+	case e.Platform != "":
+		fmt.Fprintf(w, "Platform entry:\t%s (%s; entries: %s)\n", e.Platform, e.PlatformReason, strings.Join(e.Platforms, ", "))
+	case e.PlatformReason != "":
+		fmt.Fprintf(w, "Platform entry:\t- (%s)\n", e.PlatformReason)
+	default:
+		fmt.Fprintf(w, "Platform entry:\tnone (entries: %s)\n", listOrNone(e.Platforms))
+	}
+	if e.Method != "" {
+		fmt.Fprintf(w, "Install method:\t%s\n", e.Method)
+		fmt.Fprintf(w, "Source:\t%s\n", e.Source)
+	}
+	if e.Mirror != "" {
+		fmt.Fprintf(w, "Mirror:\t%s\n", e.Mirror)
+	}
+	if e.Resolved != "" {
+		fmt.Fprintf(w, "Resolved:\t%s (%s)\n", e.Resolved, e.ResolvedFrom)
+	} else if e.ResolvedFrom != "" {
+		fmt.Fprintf(w, "Resolved:\t%s\n", e.ResolvedFrom)
+	}
+	fmt.Fprintf(w, "Locked:\t%s\n", orNone(e.Locked))
+	fmt.Fprintf(w, "Installed:\t%s\n", orNone(e.Installed))
+	fmt.Fprintf(w, "Action:\t%s (%s)\n", e.Action, e.ActionReason)
+	if e.Error != "" {
+		fmt.Fprintf(w, "Error:\t%s\n", e.Error)
+	}
+	return w.Flush()
+}
+
+// listOrNone joins a list, or returns "none" if it is empty
+func listOrNone(list []string) string {
+	return orNone(strings.Join(list, ", "))
+}
+
+// orNone returns s, or "none" if it is empty
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/devnadeemashraf/depman/pkg/depman"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// GC flags
+	gcDryRun bool
+	gcKeep   int
+
+	// GC command
+	gcCmd = &cobra.Command{
+		Use:   "gc",
+		Short: "Remove tool versions and cached downloads no configuration needs",
+		Long: `GC removes installed tool versions and cached downloads that no known
+configuration or its lockfile references. Known configurations are the ones
+depman recorded installing from (see 'depman list --managed') plus the current
+one, if any. The most recent --keep versions of each tool are kept regardless.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runGC()
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(gcCmd)
+	gcCmd.Flags().BoolVar(&gcDryRun, "dry-run", false, "Show what would be removed without removing anything")
+	gcCmd.Flags().IntVar(&gcKeep, "keep", 1, "Most recent versions of each tool to keep even if no configuration references them")
+}
+
+// runGC collects garbage in the depman home directory and reports what was freed
+func runGC() error {
+	if gcKeep < 0 {
+		return fmt.Errorf("--keep must not be negative")
+	}
+
+	opts := depman.GCOptions{Keep: gcKeep, DryRun: gcDryRun, CacheDir: depman.DefaultCacheDir()}
+	if !depman.IsRemoteConfig(configPath) {
+		if path, err := depman.FindDependencyFile(configPath); err == nil {
+			opts.Configs = append(opts.Configs, path)
+		}
+	}
+
+	result, err := depman.CollectGarbage(depman.DefaultHomeDir(), opts)
+	if err != nil {
+		return fmt.Errorf("garbage collection failed: %w", err)
+	}
+
+	if jsonOutput() {
+		return printJSON(result)
+	}
+
+	if len(result.Removed) == 0 {
+		fmt.Println("Nothing to remove")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KIND\tNAME\tVERSION\tSIZE")
+	for _, item := range result.Removed {
+		version := item.Version
+		if version == "" {
+			version = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", item.Kind, item.Name, version, formatSize(item.Size))
+	}
+	w.Flush()
+
+	verb := "Removed"
+	if result.DryRun {
+		verb = "Would remove"
+	}
+	fmt.Printf("%s %d items, freeing %s\n", verb, len(result.Removed), formatSize(result.Freed))
+	return nil
+}
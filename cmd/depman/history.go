@@ -0,0 +1,285 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/devnadeemashraf/depman/pkg/depman"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// History flags
+	historyDependency string
+	historyLimit      int
+
+	// History command
+	historyCmd = &cobra.Command{
+		Use:   "history",
+		Short: "List past check and ensure runs",
+		Long: `History lists the check and ensure runs recorded in ~/.depman/state.json,
+newest first, with what each run changed. Pass --dependency to list only the
+runs that changed a dependency, e.g. to see when a tool was last upgraded.
+The last 100 runs are kept.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runHistory()
+		},
+	}
+
+	// History show command
+	historyShowCmd = &cobra.Command{
+		Use:   "show <run-id>",
+		Short: "Show what a run found and did for each dependency",
+		Long: `Show prints the outcome of every dependency in a run, and what changed
+since the previous run of the same configuration. The run ID may be
+shortened to any unique prefix, or given as "last".`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runHistoryShow(args[0])
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+	historyCmd.AddCommand(historyShowCmd)
+	historyCmd.Flags().StringVar(&historyDependency, "dependency", "", "List only the runs that changed this dependency")
+	historyCmd.Flags().IntVarP(&historyLimit, "limit", "n", 20, "List at most this many runs (0 for all)")
+}
+
+// runHistory lists the recorded runs
+func runHistory() error {
+	runs, err := depman.LoadHistory(depman.DefaultStatePath())
+	if err != nil {
+		return err
+	}
+
+	var listed []*depman.Run
+	for _, run := range runs {
+		if historyDependency != "" {
+			if result := run.Result(historyDependency); result == nil || result.Change == depman.ChangeNone {
+				continue
+			}
+		}
+		listed = append(listed, run)
+		if historyLimit > 0 && len(listed) == historyLimit {
+			break
+		}
+	}
+
+	if jsonOutput() {
+		if listed == nil {
+			listed = []*depman.Run{}
+		}
+		return printJSON(listed)
+	}
+	if len(listed) == 0 {
+		if historyDependency != "" {
+			fmt.Printf("No recorded run changed %s\n", historyDependency)
+		} else {
+			fmt.Println("No runs recorded yet")
+		}
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RUN\tCOMMAND\tSTARTED\tDURATION\tRESULT\tCHANGES")
+	for _, run := range listed {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", run.ID, run.Command, run.StartedAt.Local().Format("2006-01-02 15:04:05"),
+			run.Duration.Round(time.Millisecond), runOutcome(run), runChanges(run))
+	}
+	return w.Flush()
+}
+
+// runHistoryShow prints the outcome of each dependency in a run and what
+// changed since the previous run of the same configuration
+func runHistoryShow(id string) error {
+	runs, err := depman.LoadHistory(depman.DefaultStatePath())
+	if err != nil {
+		return err
+	}
+	index, err := findRun(runs, id)
+	if err != nil {
+		return err
+	}
+	run := runs[index]
+
+	// Runs are newest first, so the previous one is further down
+	var previous *depman.Run
+	for _, earlier := range runs[index+1:] {
+		if earlier.Config == run.Config {
+			previous = earlier
+			break
+		}
+	}
+
+	if jsonOutput() {
+		return printJSON(struct {
+			*depman.Run
+			Previous string `json:"previous,omitempty"`
+		}{run, runID(previous)})
+	}
+
+	fmt.Printf("Run:      %s\n", run.ID)
+	fmt.Printf("Command:  %s\n", run.Command)
+	fmt.Printf("Config:   %s (%s)\n", run.Config, run.ConfigHash)
+	fmt.Printf("Started:  %s\n", run.StartedAt.Local().Format("2006-01-02 15:04:05"))
+	fmt.Printf("Duration: %s\n", run.Duration.Round(time.Millisecond))
+	if run.Error != "" {
+		fmt.Printf("Error:    %s\n", run.Error)
+	}
+	fmt.Println()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tRESULT\tVERSION\tPREVIOUS\tDURATION\tERROR")
+	for _, result := range run.Results {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", result.Name, resultOutcome(result), dash(result.Version),
+			dash(result.PreviousVersion), result.Duration.Round(time.Millisecond), firstLine(result.Error))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Println()
+	if previous == nil {
+		fmt.Println("No earlier run of this configuration to compare with")
+		return nil
+	}
+	changes := compareRuns(previous, run)
+	if previous.ConfigHash != run.ConfigHash {
+		changes = append([]string{"the configuration was edited"}, changes...)
+	}
+	if len(changes) == 0 {
+		fmt.Printf("Nothing changed since run %s\n", previous.ID)
+		return nil
+	}
+	fmt.Printf("Since run %s:\n", previous.ID)
+	for _, change := range changes {
+		fmt.Printf("  - %s\n", change)
+	}
+	return nil
+}
+
+// findRun returns the index of the run whose ID is or starts with id, or of
+// the newest run if id is "last"
+func findRun(runs []*depman.Run, id string) (int, error) {
+	if len(runs) == 0 {
+		return 0, fmt.Errorf("no runs recorded yet")
+	}
+	if id == "last" {
+		return 0, nil
+	}
+	found := -1
+	for i, run := range runs {
+		if run.ID == id {
+			return i, nil
+		}
+		if strings.HasPrefix(run.ID, id) {
+			if found >= 0 {
+				return 0, fmt.Errorf("run ID %q is ambiguous", id)
+			}
+			found = i
+		}
+	}
+	if found < 0 {
+		return 0, fmt.Errorf("no run %q in the history", id)
+	}
+	return found, nil
+}
+
+// compareRuns describes how each dependency differs between two runs
+func compareRuns(before, after *depman.Run) []string {
+	var changes []string
+	for _, result := range after.Results {
+		old := before.Result(result.Name)
+		switch {
+		case old == nil:
+			changes = append(changes, fmt.Sprintf("%s was added", result.Name))
+		case old.Version != result.Version:
+			changes = append(changes, fmt.Sprintf("%s: %s -> %s", result.Name, dash(old.Version), dash(result.Version)))
+		case old.Problem != result.Problem:
+			changes = append(changes, fmt.Sprintf("%s: %s -> %s", result.Name, resultOutcome(*old), resultOutcome(result)))
+		}
+	}
+	for _, result := range before.Results {
+		if after.Result(result.Name) == nil {
+			changes = append(changes, fmt.Sprintf("%s was removed", result.Name))
+		}
+	}
+	return changes
+}
+
+// runOutcome sums up a run: ok, failed, or how many dependencies had problems
+func runOutcome(run *depman.Run) string {
+	if run.Error != "" {
+		return "failed"
+	}
+	problems := 0
+	for _, result := range run.Results {
+		if result.Problem != depman.ProblemNone {
+			problems++
+		}
+	}
+	if problems > 0 {
+		return fmt.Sprintf("%d problems", problems)
+	}
+	return "ok"
+}
+
+// runChanges lists what a run changed, e.g. "go 1.21.0 -> 1.22.0, jq installed"
+func runChanges(run *depman.Run) string {
+	var changes []string
+	for _, result := range run.Results {
+		switch {
+		case result.Change == depman.ChangeNone:
+		case result.PreviousVersion != "" && result.PreviousVersion != result.Version:
+			changes = append(changes, fmt.Sprintf("%s %s -> %s", result.Name, result.PreviousVersion, result.Version))
+		default:
+			changes = append(changes, fmt.Sprintf("%s %s", result.Name, result.Change))
+		}
+	}
+	if len(changes) == 0 {
+		return "-"
+	}
+	return strings.Join(changes, ", ")
+}
+
+// resultOutcome names what a run found or did for a dependency
+func resultOutcome(result depman.RunResult) string {
+	switch {
+	case result.Error != "":
+		return "failed"
+	case result.Change != depman.ChangeNone:
+		return string(result.Change)
+	case result.Problem != depman.ProblemNone:
+		return string(result.Problem)
+	default:
+		return "ok"
+	}
+}
+
+// runID returns the ID of a run, or "" if there is none
+func runID(run *depman.Run) string {
+	if run == nil {
+		return ""
+	}
+	return run.ID
+}
+
+// firstLine returns the first line of s, e.g. of an error with installer output
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
+
+// dash returns s, or "-" if it is empty
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
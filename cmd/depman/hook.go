@@ -0,0 +1,341 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/devnadeemashraf/depman/pkg/depman"
+	"github.com/spf13/cobra"
+)
+
+// hookStateVar is the variable the shell hook keeps its state in, so it can
+// undo what it applied when leaving the project
+const hookStateVar = "DEPMAN_HOOK_STATE"
+
+var (
+	// Hook command
+	hookCmd = &cobra.Command{
+		Use:   "hook <shell>",
+		Short: "Print a shell hook that applies each project's environment on cd",
+		Long: `Print a shell hook that runs before each prompt. Inside a directory holding
+a depman configuration, or below one, it puts the project's managed bin
+directory and every dependency's environment settings into the shell, as
+'depman env' would; leaving the project restores what was there before.
+Editing the configuration re-applies it at the next prompt.
+
+The hook only applies configurations trusted with 'depman allow', and an
+edit revokes the trust until they are allowed again, so entering a cloned
+repository cannot run code from it.
+
+Add it to your shell profile:
+
+  eval "$(depman hook bash)"                # ~/.bashrc
+  eval "$(depman hook zsh)"                 # ~/.zshrc
+  depman hook fish | source                 # ~/.config/fish/config.fish
+  depman hook powershell | Out-String | iex # $PROFILE`,
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: []string{"bash", "zsh", "fish", "powershell"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runHook(args[0])
+		},
+	}
+
+	// Hook environment command, run by the hook before each prompt
+	hookEnvCmd = &cobra.Command{
+		Use:    "hook-env <shell>",
+		Short:  "Print the commands that bring the shell's environment up to date with the current directory",
+		Hidden: true,
+		Args:   cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runHookEnv(args[0])
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(hookCmd, hookEnvCmd)
+}
+
+// runHook prints the hook for a shell, calling this executable
+func runHook(shell string) error {
+	self, err := os.Executable()
+	if err != nil {
+		self = "depman"
+	}
+
+	switch shell {
+	case "bash":
+		fmt.Printf(`_depman_hook() {
+  local status=$?
+  eval "$("%s" hook-env bash)"
+  return $status
+}
+if [[ ";${PROMPT_COMMAND[*]:-};" != *";_depman_hook;"* ]]; then
+  if [[ "$(declare -p PROMPT_COMMAND 2>&1)" == "declare -a"* ]]; then
+    PROMPT_COMMAND=(_depman_hook "${PROMPT_COMMAND[@]}")
+  else
+    PROMPT_COMMAND="_depman_hook${PROMPT_COMMAND:+;$PROMPT_COMMAND}"
+  fi
+fi
+`, shellQuote(self))
+	case "zsh":
+		fmt.Printf(`_depman_hook() {
+  eval "$("%s" hook-env zsh)"
+}
+typeset -ag precmd_functions chpwd_functions
+if (( ! ${precmd_functions[(I)_depman_hook]} )); then
+  precmd_functions=(_depman_hook $precmd_functions)
+fi
+if (( ! ${chpwd_functions[(I)_depman_hook]} )); then
+  chpwd_functions=(_depman_hook $chpwd_functions)
+fi
+`, shellQuote(self))
+	case "fish":
+		fmt.Printf(`function __depman_hook --on-event fish_prompt --on-variable PWD
+    %s hook-env fish | source
+end
+`, fishQuote(self))
+	case "powershell", "pwsh":
+		fmt.Printf(`$global:__depmanPrompt = $function:prompt
+function global:prompt {
+    & %s hook-env powershell | Out-String | Invoke-Expression
+    & $global:__depmanPrompt
+}
+`, powershellQuote(self))
+	default:
+		return fmt.Errorf("unsupported shell '%s' (expected bash, zsh, fish or powershell)", shell)
+	}
+	return nil
+}
+
+// hookState is what the hook applied, kept in hookStateVar
+type hookState struct {
+	Config      string             `json:"config"`      // Configuration file applied
+	Fingerprint string             `json:"fingerprint"` // Digest of its files' sizes and modification times, to notice edits
+	Paths       []string           `json:"paths"`       // PATH entries the hook added
+	Saved       map[string]*string `json:"saved"`       // Values the variables it set had before, or nil if unset
+	Blocked     bool               `json:"blocked"`     // Whether the configuration was not allowed, so nothing was applied
+}
+
+// runHookEnv prints the commands that undo the environment applied for the
+// previous project, if it is no longer current, and apply the current one
+func runHookEnv(shell string) error {
+	// This runs before every prompt: only errors may be printed
+	logsToStderr = true
+	if !rootCmd.PersistentFlags().Changed("log-level") && !verbose {
+		logLevel = "error"
+	}
+
+	state := decodeHookState(os.Getenv(hookStateVar))
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	config := findProjectConfig(dir)
+	allowed, allowErr := false, error(nil)
+	if config != "" {
+		allowed, allowErr = depman.IsConfigAllowed(config)
+	}
+	if state == nil && config == "" || state != nil && state.Config == config && state.Fingerprint == configFingerprint(config) && state.Blocked == !allowed {
+		return nil
+	}
+
+	// Undo the previous project's environment
+	paths := filepath.SplitList(os.Getenv("PATH"))
+	changes := make(map[string]*string)
+	if state != nil {
+		paths = withoutPaths(paths, state.Paths)
+		for key, value := range state.Saved {
+			changes[key] = value
+		}
+	}
+
+	// Apply the current one
+	changes[hookStateVar] = nil
+	if config != "" {
+		var next *hookState
+		var err error
+		if allowed {
+			next, err = hookActivation(config, paths, changes)
+		} else {
+			// Nothing is applied, nor the configuration even loaded; the
+			// state remembers it so the notice is printed once
+			if allowErr != nil {
+				fmt.Fprintf(os.Stderr, "depman: %v\n", allowErr)
+			}
+			fmt.Fprintf(os.Stderr, "depman: %s is not allowed to change the environment; run 'depman allow' to apply it\n", config)
+			next = &hookState{Config: config, Fingerprint: configFingerprint(config), Blocked: true}
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "depman: %v\n", err)
+		} else {
+			paths = append(next.Paths, paths...)
+			encoded, err := json.Marshal(next)
+			if err != nil {
+				return err
+			}
+			value := base64.RawURLEncoding.EncodeToString(encoded)
+			changes[hookStateVar] = &value
+		}
+	}
+	path := strings.Join(paths, string(os.PathListSeparator))
+	changes["PATH"] = &path
+
+	keys := make([]string, 0, len(changes))
+	for key := range changes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := printHookChange(shell, key, changes[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hookActivation sets the variables of a project's environment in changes,
+// remembering the values they replace, and returns the state to keep with
+// the PATH entries to add in front of paths
+func hookActivation(config string, paths []string, changes map[string]*string) (*hookState, error) {
+	configPath = config
+	manager, err := createManager()
+	if err != nil {
+		return nil, err
+	}
+	activation, err := manager.ActivationEnvironment()
+	if err != nil {
+		return nil, err
+	}
+
+	state := &hookState{Config: config, Fingerprint: configFingerprint(config), Saved: make(map[string]*string)}
+	present := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		present[path] = true
+	}
+	for _, path := range activation.Paths {
+		if !present[path] {
+			state.Paths = append(state.Paths, path)
+			present[path] = true
+		}
+	}
+	for key, value := range activation.Variables {
+		// A variable the previous project set is restored first
+		if previous, ok := changes[key]; ok {
+			state.Saved[key] = previous
+		} else if previous, ok := os.LookupEnv(key); ok {
+			state.Saved[key] = &previous
+		} else {
+			state.Saved[key] = nil
+		}
+		value := value
+		changes[key] = &value
+	}
+	return state, nil
+}
+
+// findProjectConfig returns the configuration file in dir or the closest of
+// its parents, or "" if there is none
+func findProjectConfig(dir string) string {
+	for {
+		if path, err := depman.FindDependencyFile(dir); err == nil {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// configFingerprint digests the sizes and modification times of the files a
+// configuration is loaded from, or returns "" for no configuration
+func configFingerprint(config string) string {
+	if config == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(snapshot(depman.ConfigFiles(config))))
+	return hex.EncodeToString(sum[:8])
+}
+
+// decodeHookState reads the hook's state, or returns nil if there is none
+func decodeHookState(value string) *hookState {
+	if value == "" {
+		return nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil
+	}
+	var state hookState
+	if json.Unmarshal(data, &state) != nil {
+		return nil
+	}
+	return &state
+}
+
+// withoutPaths removes one occurrence of each entry of remove from paths
+func withoutPaths(paths, remove []string) []string {
+	pending := make(map[string]int)
+	for _, path := range remove {
+		pending[path]++
+	}
+	var kept []string
+	for _, path := range paths {
+		if pending[path] > 0 {
+			pending[path]--
+			continue
+		}
+		kept = append(kept, path)
+	}
+	return kept
+}
+
+// printHookChange prints the command setting a variable in a shell, or
+// unsetting it if value is nil. Names are printed as they are, so only valid
+// ones get this far.
+func printHookChange(shell, key string, value *string) error {
+	if key != "PATH" && key != hookStateVar {
+		if err := depman.ValidateVariableName(key); err != nil {
+			return err
+		}
+	}
+	switch shell {
+	case "bash", "zsh":
+		if value == nil {
+			fmt.Printf("unset %s\n", key)
+		} else {
+			fmt.Printf("export %s=\"%s\"\n", key, shellQuote(*value))
+		}
+	case "fish":
+		switch {
+		case value == nil:
+			fmt.Printf("set -e %s\n", key)
+		case key == "PATH":
+			var paths []string
+			for _, path := range filepath.SplitList(*value) {
+				paths = append(paths, fishQuote(path))
+			}
+			fmt.Printf("set -gx PATH %s\n", strings.Join(paths, " "))
+		default:
+			fmt.Printf("set -gx %s %s\n", key, fishQuote(*value))
+		}
+	case "powershell", "pwsh":
+		if value == nil {
+			fmt.Printf("Remove-Item Env:%s -ErrorAction SilentlyContinue\n", key)
+		} else {
+			fmt.Printf("$env:%s = %s\n", key, powershellQuote(*value))
+		}
+	default:
+		return fmt.Errorf("unsupported shell '%s' (expected bash, zsh, fish or powershell)", shell)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/devnadeemashraf/depman/pkg/depman"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Init flags
+	initForce  bool
+	initDryRun bool
+
+	// Init command
+	initCmd = &cobra.Command{
+		Use:   "init [directory]",
+		Short: "Create a dependency configuration for the toolchains a repository uses",
+		Long: `Init inspects a repository for go.mod, package.json, Dockerfiles, Compose
+files, Makefiles and Terraform files and writes app-dependencies.yml with a
+dependency on each toolchain they need. Constraints come from the files where
+they state one, such as the go directive, engines.node or Terraform's
+required_version; review them and the install methods before committing.
+In a git repository, depman.override.yaml, which holds local version pins,
+is added to .gitignore.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir := "."
+			if len(args) > 0 {
+				dir = args[0]
+			}
+			return runInit(dir)
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(initCmd)
+	initCmd.Flags().BoolVarP(&initForce, "force", "f", false, "Overwrite an existing app-dependencies.yml")
+	initCmd.Flags().BoolVar(&initDryRun, "dry-run", false, "Print the configuration instead of writing it")
+}
+
+// runInit detects the repository's toolchains and writes a starter configuration
+func runInit(dir string) error {
+	detections, err := depman.DetectStack(dir)
+	if err != nil {
+		return err
+	}
+	if len(detections) == 0 {
+		return fmt.Errorf("no go.mod, package.json, Dockerfile, Makefile or Terraform files found in %s; use 'depman generate' for a template", dir)
+	}
+
+	name := filepath.Base(dir)
+	if abs, err := filepath.Abs(dir); err == nil {
+		name = filepath.Base(abs)
+	}
+	config := depman.NewConfig(name)
+	config.Description = "Toolchain dependencies of " + name
+	var sources []string
+	for _, detection := range detections {
+		config.Dependencies = append(config.Dependencies, detection.Dependency)
+		if !slices.Contains(sources, detection.Source) {
+			sources = append(sources, detection.Source)
+		}
+	}
+	if err := config.Validate(); err != nil {
+		return fmt.Errorf("generated configuration is invalid: %w", err)
+	}
+
+	data, err := config.Encode()
+	if err != nil {
+		return err
+	}
+	header := fmt.Sprintf("# Generated by depman init from %s.\n# Review the constraints and install methods before committing.\n", strings.Join(sources, ", "))
+	data = append([]byte(header), data...)
+
+	if initDryRun {
+		fmt.Print(string(data))
+		return nil
+	}
+
+	path := filepath.Join(dir, "app-dependencies.yml")
+	if _, err := os.Stat(path); err == nil && !initForce {
+		return fmt.Errorf("%s already exists; use --force to overwrite it", path)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write configuration file: %w", err)
+	}
+
+	for _, detection := range detections {
+		fmt.Printf("  %s %s (from %s)\n", detection.Dependency.Name, detection.Dependency.Version.Constraint, detection.Source)
+	}
+	fmt.Printf("Created %s with %d dependencies\n", path, len(detections))
+
+	// Local version overrides are personal and stay out of the repository
+	if ignored, err := ignoreOverrideFile(dir); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to add %s to .gitignore: %v\n", depman.OverrideFileName, err)
+	} else if ignored {
+		fmt.Printf("Added %s to .gitignore\n", depman.OverrideFileName)
+	}
+	return nil
+}
+
+// ignoreOverrideFile adds the override file to the .gitignore of a git
+// repository's root directory unless it is listed already, and reports
+// whether it did
+func ignoreOverrideFile(dir string) (bool, error) {
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil {
+		return false, nil
+	}
+	path := filepath.Join(dir, ".gitignore")
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.TrimPrefix(strings.TrimSpace(line), "/") == depman.OverrideFileName {
+			return false, nil
+		}
+	}
+
+	if len(data) > 0 && !strings.HasSuffix(string(data), "\n") {
+		data = append(data, '\n')
+	}
+	data = append(data, depman.OverrideFileName+"\n"...)
+	return true, os.WriteFile(path, data, 0644)
+}
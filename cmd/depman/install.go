@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Install flags
+	installForce bool
+
+	// Install command
+	installCmd = &cobra.Command{
+		Use:   "install <name>[@<version>]...",
+		Short: "Install specific dependencies and everything they depend on",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runInstall(cmd.Context(), args)
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(installCmd)
+	installCmd.Flags().BoolVarP(&installForce, "force", "f", false, "Reinstall even when the installed version satisfies the configuration")
+}
+
+// runInstall installs the named dependencies and their transitive dependencies
+func runInstall(ctx context.Context, names []string) error {
+	progress, stopProgress := progressOptions()
+	manager, err := createManager(progress...)
+	if err != nil {
+		stopProgress()
+		return fmt.Errorf("failed to initialize: %w", err)
+	}
+
+	statuses, err := manager.InstallDependencies(ctx, names, installForce)
+	stopProgress()
+	if jsonOutput() && statuses != nil {
+		if jsonErr := printJSON(orderedStatuses(manager, statuses)); jsonErr != nil {
+			return jsonErr
+		}
+	}
+	if !jsonOutput() && statuses != nil {
+		ordered := orderedStatuses(manager, statuses)
+		printInstallResults(ordered, ordered)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to install dependencies: %w", err)
+	}
+
+	return nil
+}
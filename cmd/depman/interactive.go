@@ -0,0 +1,42 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/devnadeemashraf/depman/pkg/depman"
+)
+
+// promptAction returns a prompt that asks on stderr whether to carry out each
+// planned action, reading answers from in until a valid one is given
+func promptAction(in io.Reader) depman.PromptFunc {
+	reader := bufio.NewReader(in)
+	return func(ctx context.Context, action *depman.PlannedAction) (depman.Choice, error) {
+		for {
+			verb := action.Action.String()
+			description := strings.ToUpper(verb[:1]) + verb[1:] + " " + action.Name
+			if action.TargetVersion != "" {
+				description += " v" + action.TargetVersion
+			}
+			description += " via " + action.Method
+			if action.CurrentVersion != "" {
+				description += fmt.Sprintf(" (installed: v%s)", action.CurrentVersion)
+			}
+			fmt.Fprintf(os.Stderr, "%s? [i]nstall, [s]kip, [a]lways, [n]ever: ", description)
+
+			answer, err := reader.ReadString('\n')
+			if err != nil && answer == "" {
+				return depman.ChoiceSkip, fmt.Errorf("no answer for %s: %w", action.Name, err)
+			}
+			choice, err := depman.ParseChoice(answer)
+			if err == nil {
+				return choice, nil
+			}
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/devnadeemashraf/depman/pkg/depman"
+	"github.com/spf13/cobra"
+)
+
+// Licenses command
+var licensesCmd = &cobra.Command{
+	Use:   "licenses",
+	Short: "Report dependency licenses and check them against the license policy",
+	Long: `Licenses lists the license of each dependency and whether the policy in
+the configuration (policy.allowed_licenses and policy.denied_licenses)
+permits it. Licenses come from the configuration, the lockfile, or the
+package or release metadata.
+
+It exits with an error when a license is denied.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runLicenses(cmd.Context())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(licensesCmd)
+}
+
+// runLicenses prints the license report and fails on policy violations
+func runLicenses(ctx context.Context) error {
+	manager, err := createManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize: %w", err)
+	}
+
+	results, err := manager.Licenses(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to look up licenses: %w", err)
+	}
+
+	if jsonOutput() {
+		if err := printJSON(results); err != nil {
+			return err
+		}
+	} else {
+		printLicenseResults(results)
+	}
+
+	denied := 0
+	for _, result := range results {
+		if result.Status == depman.LicenseDenied {
+			denied++
+		}
+	}
+	if denied > 0 {
+		return &exitError{code: exitLicense, err: fmt.Errorf("%d of %d dependencies violate the license policy", denied, len(results))}
+	}
+	return nil
+}
+
+// printLicenseResults prints a table of licenses and their policy status
+func printLicenseResults(results []*depman.LicenseResult) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tVERSION\tLICENSE\tSTATUS")
+	for _, result := range results {
+		license := result.License
+		if license == "" {
+			license = "-"
+		}
+		version := result.Version
+		if version == "" {
+			version = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", result.Name, version, license, result.Status)
+	}
+	w.Flush()
+}
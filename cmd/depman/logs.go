@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/devnadeemashraf/depman/pkg/depman"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Logs flags
+	logsLast bool
+
+	// Logs command
+	logsCmd = &cobra.Command{
+		Use:   "logs [dependency]",
+		Short: "List or show the logs of past installs",
+		Long: `Logs lists the install logs depman keeps under ~/.depman/logs, newest
+first: one directory per run, holding a log per dependency with every command
+its install ran and the full output. Name a dependency to list only its logs,
+and pass --last to print the most recent log instead of listing them, or with
+no dependency named, every log of the most recent run. The logs of the last
+20 runs are kept.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := ""
+			if len(args) > 0 {
+				name = args[0]
+			}
+			return runLogs(name)
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(logsCmd)
+	logsCmd.Flags().BoolVar(&logsLast, "last", false, "Print the most recent log (of every dependency of the last run, if none is named)")
+}
+
+// runLogs lists the install logs, or prints the latest with --last
+func runLogs(name string) error {
+	logs, err := depman.InstallLogs(depman.DefaultHomeDir(), name)
+	if err != nil {
+		return err
+	}
+	if len(logs) == 0 {
+		if name != "" {
+			return fmt.Errorf("no install logs of %s", name)
+		}
+		return fmt.Errorf("no install logs")
+	}
+
+	if logsLast {
+		// Only the newest run, and with a name only its newest log
+		var last []depman.InstallLog
+		for _, log := range logs {
+			if log.Run == logs[0].Run && (name == "" || len(last) == 0) {
+				last = append(last, log)
+			}
+		}
+		logs = last
+	}
+
+	if jsonOutput() {
+		return printJSON(logs)
+	}
+	if !logsLast {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "RUN\tDEPENDENCY\tWRITTEN\tPATH")
+		for _, log := range logs {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", log.Run, log.Dependency, log.Time.Local().Format("2006-01-02 15:04:05"), log.Path)
+		}
+		return w.Flush()
+	}
+
+	for i, log := range logs {
+		if len(logs) > 1 {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("==> %s <==\n", log.Path)
+		}
+		if err := printFile(os.Stdout, log.Path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printFile copies a file to w
+func printFile(w io.Writer, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open log: %w", err)
+	}
+	defer file.Close()
+	_, err = io.Copy(w, file)
+	return err
+}
@@ -1,10 +1,18 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
+	"os/signal"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/devnadeemashraf/depman/internal/httpclient"
 	"github.com/devnadeemashraf/depman/internal/logger"
 	"github.com/devnadeemashraf/depman/pkg/depman"
 	"github.com/spf13/cobra"
@@ -14,13 +22,67 @@ var (
 	// Versioning
 	version = "dev"
 
+	// Base64 Ed25519 key release binaries are signed with, set at build time for self-update
+	releasePublicKey = ""
+
+	// Set by commands whose output is evaluated by a shell, to keep logs off stdout
+	logsToStderr bool
+
 	// Flags
 	configPath   string
 	platformFlag string
+	archFlag     string
+	distroFlag   string
+	homeFlag     string
 	logLevel     string
+	logFormat    string
+	logFile      string
 	verbose      bool
+	outputFormat string
+	jobs         int
+	maxDownloads int
+	maxPerHost   int
+	downloadRate string
+	retries      int
+	privilege    string
+	strict       bool
+	noScripts    bool
+	noRollback   bool
+	policyFile   string
+	binDir       string
+	scope        string
+	profile      string
 	outputFile   string
 	force        bool
+	frozen       bool
+	pins         []string
+	offline      bool
+	bundlePath   string
+	dryRun       bool
+	interactive  bool
+	changedOnly  bool
+	detailedExit bool
+	checkFailOn  string
+	checkAudit   bool
+	checkWatch   bool
+	checkPaths   bool
+	watchPath    bool
+	watchEvery   time.Duration
+	onlyDeps     []string
+	skipDeps     []string
+	tags         []string
+	caBundle     string
+	clientCert   string
+	clientKey    string
+	insecure     bool
+	noColor      bool
+	quiet        bool
+	porcelain    bool
+
+	// Table flags
+	tableColumns []string
+	tableSort    string
+	tableFilters []string
 
 	// Root command
 	rootCmd = &cobra.Command{
@@ -35,6 +97,16 @@ It can check for, install, and verify dependencies on various platforms.`,
 			if verbose {
 				logLevel = "debug"
 			}
+
+			// Relocate every managed file, for this process and the hooks,
+			// scripts and shims it runs
+			if homeFlag != "" {
+				os.Setenv(depman.HomeEnvVar, homeFlag)
+			}
+
+			// The arguments parsed, so failures from here on are not usage
+			// mistakes; main prints them after the results they concern
+			cmd.Root().SilenceUsage, cmd.Root().SilenceErrors = true, true
 		},
 	}
 
@@ -43,7 +115,7 @@ It can check for, install, and verify dependencies on various platforms.`,
 		Use:   "check",
 		Short: "Check dependencies without installing them",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runCheck()
+			return runCheck(cmd.Context())
 		},
 	}
 
@@ -52,7 +124,7 @@ It can check for, install, and verify dependencies on various platforms.`,
 		Use:   "ensure",
 		Short: "Ensure all dependencies are installed and up to date",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runEnsure()
+			return runEnsure(cmd.Context())
 		},
 	}
 
@@ -85,19 +157,60 @@ It can check for, install, and verify dependencies on various platforms.`,
 )
 
 func main() {
+	// Configurations can require a minimum depman version
+	depman.ToolVersion = version
+
+	// Cancel running installs on interrupt
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+
+	// Complete dependency names and flag values from the configuration
+	registerCompletions()
+
 	// Execute the root command
-	if err := rootCmd.Execute(); err != nil {
+	err := rootCmd.ExecuteContext(ctx)
+	stop()
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		code := exitFailure
+		var exitErr *exitError
+		if errors.As(err, &exitErr) {
+			code = exitErr.code
+		}
+		os.Exit(code)
 	}
+	os.Exit(successCode)
 }
 
 func init() {
 	// Add flags to root command
-	rootCmd.PersistentFlags().StringVarP(&configPath, "config", "c", "", "Path to dependency configuration file")
-	rootCmd.PersistentFlags().StringVarP(&platformFlag, "platform", "p", "", "Override platform detection (windows, linux, darwin)")
-	rootCmd.PersistentFlags().StringVarP(&logLevel, "log-level", "l", "info", "Log level (debug, info, warn, error)")
+	rootCmd.PersistentFlags().StringVarP(&configPath, "config", "c", "", "Path to dependency configuration file, or a remote reference (https://..., git::<repo>//<file>?ref=..., oci://<registry>/<repo>:<tag>)")
+	rootCmd.PersistentFlags().StringVarP(&platformFlag, "platform", "p", "", "Override platform detection (windows, linux, darwin), optionally with an architecture, e.g. linux/arm64")
+	rootCmd.PersistentFlags().StringVar(&archFlag, "arch", "", "Override CPU architecture detection (amd64, arm64, 386, ...)")
+	rootCmd.PersistentFlags().StringVar(&distroFlag, "distro", "", "Override Linux distribution detection, as id or id@version (e.g. ubuntu@22.04)")
+	rootCmd.PersistentFlags().StringVarP(&logLevel, "log-level", "l", "info", "Log level (debug, info, warn, error), optionally with component levels, e.g. info,download=debug")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "text", "Log format (text, json)")
+	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "Write logs to this file instead of the terminal, rotating it at 10 MiB")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output")
+	rootCmd.PersistentFlags().IntVarP(&jobs, "jobs", "j", 0, "Number of dependencies to check or install in parallel (default: jobs setting, else number of CPUs)")
+	rootCmd.PersistentFlags().IntVar(&maxDownloads, "max-downloads", 0, "Number of artifacts to download in parallel (default: no limit beyond --jobs)")
+	rootCmd.PersistentFlags().IntVar(&maxPerHost, "max-host-downloads", 0, "Number of artifacts to download in parallel from the same host, e.g. an internal mirror (default: no limit)")
+	rootCmd.PersistentFlags().StringVar(&downloadRate, "download-rate", "", "Combined bandwidth of all downloads, e.g. 10MB/s (default: no limit)")
+	rootCmd.PersistentFlags().IntVar(&retries, "retries", depman.DefaultRetryPolicy.MaxAttempts-1, "Times to retry downloads, API lookups and package-manager installs that fail transiently")
+	rootCmd.PersistentFlags().StringVar(&privilege, "privilege", "sudo", "How to gain root for system package managers (sudo, doas, fail, prompt)")
+	rootCmd.PersistentFlags().BoolVar(&strict, "strict", false, "Fail on undefined environment variables and template fields in the configuration")
+	rootCmd.PersistentFlags().BoolVar(&noScripts, "no-scripts", false, "Refuse to run install scripts of dependencies using the script method")
+	rootCmd.PersistentFlags().BoolVar(&noRollback, "no-rollback", false, "Leave a failed install of a side-by-side tool in place instead of rolling back to the previous version")
+	rootCmd.PersistentFlags().StringVar(&policyFile, "policy", "", "Organization policy file restricting install methods and download sources (default: $DEPMAN_POLICY, else the policy_file setting, else the machine-wide policy file)")
+	rootCmd.PersistentFlags().StringVar(&homeFlag, "home", "", "Root directory for everything depman manages: state, cache, bin and tools directories, logs and plugins (default: $DEPMAN_HOME, else the home_dir setting, else ~/.depman)")
+	rootCmd.PersistentFlags().StringVar(&binDir, "bin-dir", "", "Directory for shims of downloaded tools (default: bin under --home, e.g. ~/.depman/bin)")
+	rootCmd.PersistentFlags().StringVar(&profile, "profile", "", "Configuration profile to apply, e.g. ci (default: $DEPMAN_PROFILE)")
+	rootCmd.PersistentFlags().StringVar(&scope, "scope", "", "Install scope for downloaded tools: project (.depman/ next to the configuration) or global (default: configuration's scope, else global)")
+	rootCmd.PersistentFlags().StringVar(&caBundle, "ca-bundle", "", "PEM file with extra CA certificates to trust, e.g. for a TLS-intercepting proxy")
+	rootCmd.PersistentFlags().StringVar(&clientCert, "client-cert", "", "PEM client certificate for mutual TLS")
+	rootCmd.PersistentFlags().StringVar(&clientKey, "client-key", "", "PEM key of --client-cert")
+	rootCmd.PersistentFlags().BoolVar(&insecure, "insecure", false, "Skip TLS certificate verification (unsafe, for debugging only)")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text, json; wide for every column of tables)")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Do not color the output (also when $NO_COLOR is set)")
 
 	// Add commands
 	rootCmd.AddCommand(checkCmd)
@@ -105,134 +218,617 @@ func init() {
 	rootCmd.AddCommand(listCmd)
 	rootCmd.AddCommand(versionCmd)
 
+	for _, cmd := range []*cobra.Command{checkCmd, ensureCmd, listCmd} {
+		cmd.Flags().StringSliceVar(&onlyDeps, "only", nil, "Only these dependencies (comma-separated), plus what they depend on")
+		cmd.Flags().StringSliceVar(&skipDeps, "skip", nil, "Leave out dependencies with these names or tags, unless a selected one depends on them")
+		cmd.Flags().StringSliceVar(&tags, "tag", nil, "Only dependencies with any of these tags, plus what they depend on")
+	}
+	for _, cmd := range []*cobra.Command{checkCmd, listCmd} {
+		cmd.Flags().StringSliceVar(&tableColumns, "columns", nil, "Columns to show, in order (comma-separated, e.g. name,status,installed)")
+		cmd.Flags().StringVar(&tableSort, "sort", "", "Column to sort by; prefix with - for descending order (e.g. -installed)")
+		cmd.Flags().StringArrayVar(&tableFilters, "filter", nil, "Only rows where column=value or column!=value; values may list alternatives (e.g. status=missing,error)")
+	}
+	for _, cmd := range []*cobra.Command{checkCmd, ensureCmd} {
+		cmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "Print nothing but errors; the exit code carries the result")
+		cmd.Flags().BoolVar(&porcelain, "porcelain", false, "Print a tab-separated line per dependency in a format that does not change between versions")
+	}
+	checkCmd.Flags().BoolVar(&checkAudit, "audit", false, "Also look up known vulnerabilities of installed versions (see 'depman audit')")
+	checkCmd.Flags().BoolVar(&checkPaths, "path-audit", false, "Instead of checking versions, list every copy of each tool's executable on PATH and in the bin directories and how to resolve shadowing")
+	checkCmd.Flags().BoolVarP(&checkWatch, "watch", "w", false, "Re-run the check whenever the configuration changes, until interrupted")
+	checkCmd.Flags().BoolVar(&watchPath, "watch-path", false, "With --watch, also re-run when tools are added to or removed from PATH or the bin directory")
+	checkCmd.Flags().DurationVar(&watchEvery, "interval", 2*time.Second, "With --watch, how often to look for changes")
+	checkCmd.Flags().StringVar(&checkFailOn, "fail-on", "all", "Problems that fail the check: missing, incompatible, outdated, major, minor, patch, error or all")
+	ensureCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print what would be installed, upgraded or skipped without installing anything")
+	ensureCmd.Flags().BoolVar(&frozen, "frozen", false, "Fail if the lockfile is missing or out of date and install exactly what it records")
+	ensureCmd.Flags().StringArrayVar(&pins, "pin", nil, fmt.Sprintf("Pin a dependency to an exact version for this run only, as name=version (repeatable; see %s for lasting local pins)", depman.OverrideFileName))
+	ensureCmd.Flags().BoolVar(&offline, "offline", false, "Install without network access, taking every artifact from --bundle")
+	ensureCmd.Flags().StringVar(&bundlePath, "bundle", "", "Offline bundle created by 'depman bundle create'")
+	ensureCmd.Flags().BoolVarP(&interactive, "interactive", "i", false, "Ask before each install or upgrade, remembering always/never answers in .depman/choices.yml")
+	ensureCmd.Flags().BoolVar(&changedOnly, "changed-only", false, "Only report dependencies that were installed, upgraded, downgraded or reinstalled")
+	ensureCmd.Flags().BoolVar(&detailedExit, "detailed-exitcode", false, fmt.Sprintf("Exit with %d instead of 0 when changes were applied, so wrappers can tell them from an already satisfied run", exitChanged))
+
 	// Add Generate Command
 	rootCmd.AddCommand(generateCmd)
 	generateCmd.Flags().StringVarP(&outputFile, "output", "o", "app-dependencies.yml", "Output file path")
 	generateCmd.Flags().BoolVarP(&force, "force", "f", false, "Force overwrite existing file")
 }
 
+// Log file rotation limits for --log-file
+const (
+	logFileMaxSize = 10 << 20
+	logFileBackups = 3
+)
+
 // createManager creates a new dependency manager with the specified options
-func createManager() (*depman.Manager, error) {
+func createManager(extra ...depman.Option) (*depman.Manager, error) {
+	options, err := managerOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	// Create manager
+	return depman.NewManager(configPath, append(options, extra...)...)
+}
+
+// managerOptions returns the manager options set by the global flags
+func managerOptions() ([]depman.Option, error) {
 	// Set up options
 	var options []depman.Option
 
+	// Ask questions such as privilege prompts on the terminal
+	options = append(options, depman.WithReporter(newTerminalReporter(nil)))
+
 	// Set platform if specified
 	if platformFlag != "" {
 		options = append(options, depman.WithPlatform(platformFlag))
 	}
+	if archFlag != "" {
+		options = append(options, depman.WithArch(archFlag))
+	}
+	if distroFlag != "" {
+		distro, err := depman.ParseDistro(distroFlag)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, depman.WithDistro(distro))
+	}
 
-	// Set log level
-	loggerLevel := logger.LevelInfo
-	switch strings.ToLower(logLevel) {
-	case "debug":
-		loggerLevel = logger.LevelDebug
-	case "info":
-		loggerLevel = logger.LevelInfo
-	case "warn":
-		loggerLevel = logger.LevelWarn
-	case "error":
-		loggerLevel = logger.LevelError
+	// Set log level, optionally per component
+	loggerLevel, componentLevels, err := logger.ParseLevels(logLevel)
+	if err != nil {
+		return nil, err
 	}
 	options = append(options, depman.WithLogLevel(loggerLevel))
+	if len(componentLevels) > 0 {
+		options = append(options, depman.WithComponentLogLevels(componentLevels))
+	}
 
-	// Create manager
-	return depman.NewManager(configPath, options...)
+	// Set log format
+	format, err := logger.ParseFormat(logFormat)
+	if err != nil {
+		return nil, err
+	}
+	options = append(options, depman.WithLogFormat(format))
+
+	// Set check concurrency if specified
+	if jobs > 0 {
+		options = append(options, depman.WithConcurrency(jobs))
+	}
+
+	// Cap parallel downloads and their bandwidth
+	if maxDownloads > 0 || maxPerHost > 0 || downloadRate != "" {
+		limits := depman.DownloadLimits{MaxParallel: maxDownloads, MaxPerHost: maxPerHost}
+		if downloadRate != "" {
+			if limits.MaxBytesPerSecond, err = depman.ParseByteRate(downloadRate); err != nil {
+				return nil, err
+			}
+		}
+		options = append(options, depman.WithDownloadLimits(limits))
+	}
+
+	// Set retry policy
+	retryPolicy := depman.DefaultRetryPolicy
+	retryPolicy.MaxAttempts = retries + 1
+	options = append(options, depman.WithRetryPolicy(retryPolicy))
+
+	// Set privilege escalation policy
+	policy, err := depman.ParsePrivilegePolicy(privilege)
+	if err != nil {
+		return nil, err
+	}
+	options = append(options, depman.WithPrivilegePolicy(policy))
+
+	// Keep stdout clean for machine-readable output
+	if logFile != "" {
+		file, err := logger.OpenRotatingFile(logFile, logFileMaxSize, logFileBackups)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, depman.WithLogOutput(file))
+	} else if jsonOutput() || quiet || porcelain || logsToStderr {
+		options = append(options, depman.WithLogOutput(os.Stderr))
+	} else {
+		options = append(options, depman.WithLogOutput(os.Stdout))
+	}
+
+	// Install into the project or the user directory
+	if scope != "" {
+		parsed, err := depman.ParseScope(scope)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, depman.WithScope(parsed))
+	}
+
+	// Place shims in a custom bin directory
+	if binDir != "" {
+		options = append(options, depman.WithBinDir(binDir))
+	}
+
+	// Treat undefined variables in the configuration as errors
+	if strict {
+		options = append(options, depman.WithStrictInterpolation(true))
+	}
+
+	// Refuse configuration-supplied install scripts
+	if noScripts {
+		options = append(options, depman.WithScriptsDisabled(true))
+	}
+
+	// Keep failed installs around for inspection
+	if noRollback {
+		options = append(options, depman.WithRollback(false))
+	}
+
+	// Enforce an organization policy other than the machine-wide one
+	policyPath := policyFile
+	if policyPath == "" {
+		policyPath = os.Getenv("DEPMAN_POLICY")
+	}
+	if policyPath != "" {
+		options = append(options, depman.WithOrgPolicyFile(policyPath))
+	}
+
+	// Follow the lockfile exactly if requested
+	if frozen {
+		options = append(options, depman.WithFrozenLockfile(true))
+	}
+
+	// Pin versions for this run without touching the configuration
+	if len(pins) > 0 {
+		parsed, err := parsePins(pins)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, depman.WithVersionPins(parsed))
+	}
+
+	// Install from an offline bundle if requested
+	if offline != (bundlePath != "") {
+		return nil, fmt.Errorf("--offline and --bundle must be used together")
+	}
+	if offline {
+		options = append(options, depman.WithOfflineBundle(bundlePath))
+	}
+
+	// Apply a configuration profile, from the flag or the environment
+	profileName := profile
+	if profileName == "" {
+		profileName = os.Getenv("DEPMAN_PROFILE")
+	}
+	if profileName != "" {
+		options = append(options, depman.WithProfile(profileName))
+	}
+
+	// Limit the run to part of the configuration
+	if len(onlyDeps) > 0 || len(skipDeps) > 0 || len(tags) > 0 {
+		options = append(options, depman.WithSelection(depman.Selection{Only: onlyDeps, Skip: skipDeps, Tags: tags}))
+	}
+
+	// Use custom TLS settings for every network operation
+	client, err := httpClient()
+	if err != nil {
+		return nil, err
+	}
+	if client != nil {
+		options = append(options, depman.WithHTTPClient(client))
+	}
+
+	return options, nil
 }
 
-// runCheck checks dependencies without installing them
-func runCheck() error {
-	manager, err := createManager()
+// httpClient returns a client with the TLS settings of the global flags and
+// the proxy of the settings file, or nil if none are set
+func httpClient() (*http.Client, error) {
+	settings, err := depman.LoadSettings(depman.SettingsPath())
 	if err != nil {
-		return fmt.Errorf("failed to initialize: %w", err)
+		return nil, err
+	}
+	if caBundle == "" && clientCert == "" && clientKey == "" && !insecure && settings.Proxy == "" {
+		return nil, nil
 	}
+	if insecure {
+		fmt.Fprintln(os.Stderr, "WARNING: TLS certificate verification is disabled (--insecure); downloads are not protected against tampering")
+	}
+	return httpclient.New(httpclient.Options{
+		CABundle:           caBundle,
+		ClientCert:         clientCert,
+		ClientKey:          clientKey,
+		InsecureSkipVerify: insecure,
+		Proxy:              settings.Proxy,
+	})
+}
 
-	// Check dependencies
-	statuses, err := manager.CheckAllDependencies()
+// runCheck checks dependencies without installing them
+func runCheck(ctx context.Context) error {
+	failOn, err := parseFailOn(checkFailOn)
 	if err != nil {
-		return fmt.Errorf("failed to check dependencies: %w", err)
+		return err
+	}
+	if err := scriptOutput(); err != nil {
+		return err
 	}
 
-	// Print results
-	fmt.Println("Dependency Status:")
-	fmt.Println("==================")
+	if checkPaths {
+		if checkWatch || quiet || porcelain {
+			return fmt.Errorf("--path-audit cannot be combined with --watch, --quiet or --porcelain")
+		}
+		return runPathAudit(ctx)
+	}
 
-	allOk := true
-	for name, status := range statuses {
-		fmt.Printf("- %s: ", name)
+	if checkWatch {
+		if quiet || porcelain {
+			return fmt.Errorf("--watch cannot be combined with --quiet or --porcelain")
+		}
+		return runCheckWatch(ctx)
+	}
 
-		if status.Installed {
-			fmt.Printf("Installed (v%s)", status.CurrentVersion)
-			if status.RequiredUpdate != depman.NoUpdate {
-				fmt.Printf(" [%s needed]", status.RequiredUpdate)
-				allOk = false
-			}
-			if !status.Compatible {
-				fmt.Printf(" [Incompatible]")
-				allOk = false
-			}
-		} else {
-			fmt.Printf("Not installed")
-			allOk = false
+	manager, statuses, audits, err := checkDependencies(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Emit machine-readable results if requested
+	if jsonOutput() {
+		if err := printJSON(manager.NewCheckReport(statuses)); err != nil {
+			return err
 		}
+		return checkFailure(orderedStatuses(manager, statuses), failOn)
+	}
 
-		if status.Error != nil {
-			fmt.Printf(" [Error: %v]", status.Error)
-			allOk = false
+	ordered := orderedStatuses(manager, statuses)
+	switch {
+	case quiet:
+	case porcelain:
+		printPorcelainCheck(os.Stdout, manager, ordered)
+	default:
+		if err := printCheckResults(manager, ordered, audits); err != nil {
+			return err
 		}
+	}
 
-		fmt.Println()
+	return checkFailure(ordered, failOn)
+}
+
+// checkDependencies loads the configuration and checks the dependencies,
+// looking up their vulnerabilities if --audit is set
+func checkDependencies(ctx context.Context) (*depman.Manager, map[string]*depman.DependencyStatus, map[string]*depman.AuditResult, error) {
+	manager, err := createManager()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to initialize: %w", err)
+	}
+
+	// Check dependencies, recording the run in the history
+	result, err := manager.Check(ctx)
+	if err != nil {
+		return manager, nil, nil, fmt.Errorf("failed to check dependencies: %w", err)
+	}
+	statuses := make(map[string]*depman.DependencyStatus, len(result.Statuses))
+	for _, status := range result.Statuses {
+		statuses[status.Name] = status
+	}
+
+	// Add known vulnerabilities to the statuses if requested
+	audits := make(map[string]*depman.AuditResult)
+	if checkAudit {
+		results, err := manager.Audit(ctx, statuses)
+		if err != nil {
+			return manager, nil, nil, fmt.Errorf("failed to audit dependencies: %w", err)
+		}
+		for _, result := range results {
+			audits[result.Name] = result
+		}
 	}
 
-	if !allOk {
-		return fmt.Errorf("one or more dependencies need attention")
+	return manager, statuses, audits, nil
+}
+
+// printCheckResults prints the status of each dependency after a check,
+// grouped by outcome unless sorted otherwise, followed by a summary and a
+// section per dependency that could not be checked
+func printCheckResults(manager *depman.Manager, ordered []*depman.DependencyStatus, audits map[string]*depman.AuditResult) error {
+	columns := []tableColumn{{name: "NAME"}, {name: "STATUS"}, {name: "INSTALLED"}, {name: "WANTED"}}
+	if checkAudit {
+		columns = append(columns, tableColumn{name: "VULNERABILITIES"})
+	}
+	columns = append(columns, tableColumn{name: "PROVIDER", wide: true}, tableColumn{name: "PROJECTS", wide: true}, tableColumn{name: "ERROR"})
+	t := newTable(columns...)
+	t.color = statusColor
+
+	rows := ordered
+	if tableSort == "" {
+		rows = sortByGroup(ordered, checkGroup)
+	}
+	for _, status := range rows {
+		wanted := ""
+		if dep := manager.FindDependency(status.Name); dep != nil {
+			wanted = dep.Version.Target()
+		}
+		errText := ""
+		if status.Error != nil {
+			errText = status.Error.Error()
+		}
+		cells := []string{status.Name, statusLabel(status), status.CurrentVersion, wanted}
+		if checkAudit {
+			vulnerabilities := ""
+			if audit := audits[status.Name]; audit != nil && len(audit.Vulnerabilities) > 0 {
+				vulnerabilities = fmt.Sprintf("%d (highest: %s)", len(audit.Vulnerabilities), audit.Highest())
+			}
+			cells = append(cells, vulnerabilities)
+		}
+		cells = append(cells, status.ProvidedBy, strings.Join(status.Projects, ", "), errText)
+		t.add(cells...)
+	}
+	if err := t.render(os.Stdout); err != nil {
+		return err
 	}
 
+	color := colorOutput(os.Stdout)
+	fmt.Println()
+	printSummary(os.Stdout, ordered, checkGroup, color)
+	printFailures(os.Stdout, ordered, checkGroup, false, color)
+	printShadowed(os.Stdout, ordered, color)
+	printOverrides(os.Stdout, manager, color)
 	return nil
 }
 
+// statusLabel names a dependency's status in the STATUS column: its most
+// severe problem, "provided" if an alternative satisfies it, or "ok"
+func statusLabel(status *depman.DependencyStatus) string {
+	switch problem := status.Problem(); {
+	case status.ProvidedBy != "":
+		return "provided"
+	case problem == depman.ProblemNone:
+		return "ok"
+	default:
+		return string(problem)
+	}
+}
+
+// statusColor colors the STATUS column: green when nothing needs doing,
+// yellow for updates and red for anything broken
+func statusColor(column, value string) string {
+	if column != "STATUS" {
+		return ""
+	}
+	switch depman.Problem(value) {
+	case depman.ProblemMajor, depman.ProblemMinor, depman.ProblemPatch:
+		return colorYellow
+	case depman.ProblemMissing, depman.ProblemError, depman.ProblemIncompatible, depman.ProblemUnhealthy:
+		return colorRed
+	default:
+		return colorGreen
+	}
+}
+
 // runEnsure ensures all dependencies are installed and up to date
-func runEnsure() error {
-	manager, err := createManager()
+func runEnsure(ctx context.Context) error {
+	if err := scriptOutput(); err != nil {
+		return err
+	}
+	if dryRun && (quiet || porcelain) {
+		return fmt.Errorf("--dry-run cannot be combined with --quiet or --porcelain")
+	}
+
+	var options []depman.Option
+	stopProgress := func() {}
+	if interactive {
+		if dryRun {
+			return fmt.Errorf("--interactive cannot be combined with --dry-run")
+		}
+		if !isTerminal(os.Stdin) {
+			return fmt.Errorf("--interactive requires a terminal")
+		}
+		options = append(options, depman.WithPrompt(promptAction(os.Stdin)))
+	}
+	// Progress lines would redraw over the prompts
+	if !dryRun && !interactive {
+		var progress []depman.Option
+		progress, stopProgress = progressOptions()
+		options = append(options, progress...)
+	}
+
+	manager, err := createManager(options...)
 	if err != nil {
+		stopProgress()
 		return fmt.Errorf("failed to initialize: %w", err)
 	}
 
+	// Only show the plan if requested
+	if dryRun {
+		return runEnsurePlan(ctx, manager)
+	}
+
 	// Ensure dependencies
-	statuses, err := manager.EnsureDependencies()
+	result, err := manager.Ensure(ctx)
+	stopProgress()
+	var reported []*depman.DependencyStatus
+	if result != nil {
+		reported = result.Statuses
+		if changedOnly {
+			reported = append([]*depman.DependencyStatus{}, result.Changed()...)
+		}
+	}
+	if jsonOutput() && result != nil {
+		if jsonErr := printJSON(reported); jsonErr != nil {
+			return jsonErr
+		}
+	}
+	if porcelain && result != nil {
+		printPorcelainEnsure(os.Stdout, reported)
+	}
+	// Failures are reported before the error so they can be drilled into
+	if !jsonOutput() && !quiet && !porcelain && result != nil {
+		if changedOnly && len(reported) == 0 && err == nil {
+			fmt.Println("All dependencies were already satisfied; nothing changed.")
+		} else {
+			printInstallResults(reported, result.Statuses)
+		}
+	}
 	if err != nil {
 		return fmt.Errorf("failed to ensure dependencies: %w", err)
 	}
 
-	// Print results
-	fmt.Println("Dependency Status:")
-	fmt.Println("==================")
+	if detailedExit && len(result.Changed()) > 0 {
+		successCode = exitChanged
+	}
+	return nil
+}
 
-	for name, status := range statuses {
-		fmt.Printf("- %s: ", name)
+// runEnsurePlan prints what ensure would do without executing any installer
+func runEnsurePlan(ctx context.Context, manager *depman.Manager) error {
+	plan, err := manager.PlanEnsure(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to plan dependencies: %w", err)
+	}
 
-		if status.Installed {
-			fmt.Printf("Installed (v%s)", status.CurrentVersion)
-			if status.Compatible {
-				fmt.Printf(" [Compatible]")
-			} else {
-				fmt.Printf(" [Incompatible]")
+	if jsonOutput() {
+		return printJSON(plan)
+	}
+
+	fmt.Println("Ensure Plan (dry run):")
+	fmt.Println("======================")
+
+	for _, action := range plan.Actions {
+		fmt.Printf("- %s: %s", action.Name, action.Action)
+
+		switch action.Action {
+		case depman.ActionSkip:
+			fmt.Printf(" (v%s, %s)", action.CurrentVersion, action.Reason)
+		default:
+			if action.CurrentVersion != "" {
+				fmt.Printf(" v%s ->", action.CurrentVersion)
 			}
-		} else {
-			fmt.Printf("Failed to install")
+			fmt.Printf(" v%s via %s", action.TargetVersion, action.Method)
+			if action.Source != "" {
+				fmt.Printf(" from %s", action.Source)
+			}
+			fmt.Printf(" (%s)", action.Reason)
 		}
 
-		if status.Error != nil {
-			fmt.Printf(" [Error: %v]", status.Error)
+		fmt.Println()
+	}
+
+	fmt.Printf("\n%d of %d dependencies would change. No changes were made.\n",
+		len(plan.Pending()), len(plan.Actions))
+
+	return nil
+}
+
+// printInstallResults prints the reported dependencies after an install run
+// grouped by outcome, then a summary and a section per failure of all of them
+func printInstallResults(reported, all []*depman.DependencyStatus) {
+	color := colorOutput(os.Stdout)
+	sorted := sortByGroup(reported, ensureGroup)
+	for i, status := range sorted {
+		if group := ensureGroup(status); i == 0 || group != ensureGroup(sorted[i-1]) {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Println(colorize(group.title()+":", group.color(), color))
 		}
+		fmt.Printf("- %s: %s\n", status.Name, describeInstall(status))
+	}
 
-		fmt.Println()
+	fmt.Println()
+	printSummary(os.Stdout, all, ensureGroup, color)
+	printFailures(os.Stdout, all, ensureGroup, true, color)
+}
+
+// describeInstall describes the state an install run left a dependency in
+func describeInstall(status *depman.DependencyStatus) string {
+	var b strings.Builder
+	if status.Installed {
+		fmt.Fprintf(&b, "Installed (v%s)", status.CurrentVersion)
+		if status.Compatible {
+			b.WriteString(" [Compatible]")
+		} else {
+			b.WriteString(" [Incompatible]")
+		}
+		if status.Unhealthy {
+			b.WriteString(" [Unhealthy]")
+		}
+	} else if status.Skipped {
+		b.WriteString("Not installed")
+	} else if status.ProvidedBy != "" {
+		fmt.Fprintf(&b, "Provided by %s", status.ProvidedBy)
+	} else {
+		b.WriteString("Failed to install")
+	}
+
+	if status.Skipped {
+		b.WriteString(" [Skipped]")
+	}
+
+	switch status.Change {
+	case depman.ChangeInstalled, depman.ChangeReinstalled:
+		fmt.Fprintf(&b, " [%s]", status.Change)
+	case depman.ChangeUpgraded, depman.ChangeDowngraded:
+		fmt.Fprintf(&b, " [%s from v%s]", status.Change, status.PreviousVersion)
 	}
 
+	if status.Attempts > 1 {
+		fmt.Fprintf(&b, " [%d attempts]", status.Attempts)
+	}
+
+	if len(status.Projects) > 0 {
+		fmt.Fprintf(&b, " (used by %s)", strings.Join(status.Projects, ", "))
+	}
+	return b.String()
+}
+
+// jsonOutput reports whether machine-readable JSON output was requested
+func jsonOutput() bool {
+	return strings.ToLower(outputFormat) == "json"
+}
+
+// orderedStatuses returns statuses in configuration order so output is stable between runs
+func orderedStatuses(manager *depman.Manager, statuses map[string]*depman.DependencyStatus) []*depman.DependencyStatus {
+	ordered := make([]*depman.DependencyStatus, 0, len(statuses))
+	for _, dep := range manager.Config.Dependencies {
+		if status, ok := statuses[dep.Name]; ok {
+			ordered = append(ordered, status)
+		}
+	}
+	return ordered
+}
+
+// printJSON writes a value to stdout as indented JSON
+func printJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return fmt.Errorf("failed to encode JSON output: %w", err)
+	}
 	return nil
 }
 
 // runList lists all dependencies in the configuration
 func runList() error {
+	if listManaged || listOrphans {
+		return runListManaged()
+	}
+
 	manager, err := createManager()
 	if err != nil {
 		return fmt.Errorf("failed to initialize: %w", err)
@@ -246,37 +842,43 @@ func runList() error {
 		fmt.Printf("Description: %s\n", config.Description)
 	}
 	fmt.Printf("Configuration Version: %s\n", config.Version)
+	if manager.Profile() != "" {
+		fmt.Printf("Profile: %s\n", manager.Profile())
+	}
 	fmt.Println()
 
-	fmt.Println("Dependencies:")
-	fmt.Println("=============")
-
-	for _, dep := range config.Dependencies {
-		fmt.Printf("- %s: %s\n", dep.Name, dep.Description)
-		fmt.Printf("  Version: %s", dep.Version.Required)
-		if dep.Version.Constraint != "" {
-			fmt.Printf(" (Constraint: %s)", dep.Version.Constraint)
-		}
-		fmt.Println()
+	deps, err := manager.SelectedDependencies()
+	if err != nil {
+		return err
+	}
 
-		// Show platforms
+	t := newTable(
+		tableColumn{name: "NAME"},
+		tableColumn{name: "VERSION"},
+		tableColumn{name: "CONSTRAINT"},
+		tableColumn{name: "METHOD"},
+		tableColumn{name: "KIND", wide: true},
+		tableColumn{name: "FROM", wide: true},
+		tableColumn{name: "PLATFORMS", wide: true},
+		tableColumn{name: "DEPENDS"},
+		tableColumn{name: "TAGS"},
+		tableColumn{name: "DESCRIPTION"},
+	)
+	for _, dep := range deps {
 		platforms := make([]string, 0, len(dep.Platforms))
 		for platform := range dep.Platforms {
 			platforms = append(platforms, platform)
 		}
-		if len(platforms) > 0 {
-			fmt.Printf("  Platforms: %s\n", strings.Join(platforms, ", "))
-		}
+		sort.Strings(platforms)
 
-		// Show dependencies if any
-		if len(dep.Dependencies) > 0 {
-			fmt.Printf("  Depends on: %s\n", strings.Join(dep.Dependencies, ", "))
+		kind := dep.Kind
+		if kind == "" {
+			kind = string(depman.KindTool)
 		}
-
-		fmt.Println()
+		t.add(dep.Name, dep.Version.Required, dep.Version.Constraint, manager.InstallMethod(dep), kind, dep.From,
+			strings.Join(platforms, ", "), strings.Join(dep.Dependencies, ", "), strings.Join(dep.Tags, ", "), dep.Description)
 	}
-
-	return nil
+	return t.render(os.Stdout)
 }
 
 // Add this function to handle the generate command
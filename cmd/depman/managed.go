@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/devnadeemashraf/depman/pkg/depman"
+)
+
+// List flags
+var (
+	listManaged bool
+	listOrphans bool
+)
+
+func init() {
+	listCmd.Flags().BoolVar(&listManaged, "managed", false, "List what depman has installed in every scope and project instead of the configuration")
+	listCmd.Flags().BoolVar(&listOrphans, "orphans", false, "List only managed installs that no configuration that installed them still defines")
+}
+
+// runListManaged prints the installs recorded in the state file; it needs no configuration
+func runListManaged() error {
+	installs, err := depman.LoadManagedInstalls(depman.DefaultStatePath())
+	if err != nil {
+		return err
+	}
+
+	if listOrphans {
+		var orphans []*depman.ManagedInstall
+		for _, install := range installs {
+			if install.Orphaned {
+				orphans = append(orphans, install)
+			}
+		}
+		installs = orphans
+	}
+
+	if jsonOutput() {
+		if installs == nil {
+			installs = []*depman.ManagedInstall{}
+		}
+		return printJSON(installs)
+	}
+
+	if len(installs) == 0 {
+		if listOrphans {
+			fmt.Println("No orphaned installs")
+		} else {
+			fmt.Println("depman has not recorded any installs")
+		}
+		return nil
+	}
+
+	globalHome := depman.DefaultHomeDir()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tVERSION\tMETHOD\tSCOPE\tINSTALLED\tCONFIG")
+	for _, install := range installs {
+		scope := "global"
+		if install.Home != globalHome {
+			scope = install.Home
+		}
+		version := install.Version
+		if version == "" {
+			version = "-"
+		}
+		configs := strings.Join(install.Configs, ", ")
+		if install.Orphaned {
+			configs += " [Orphaned]"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", install.Name, version, install.Method, scope,
+			install.InstalledAt.Local().Format("2006-01-02 15:04"), configs)
+	}
+	w.Flush()
+
+	if !listOrphans {
+		orphans := 0
+		for _, install := range installs {
+			if install.Orphaned {
+				orphans++
+			}
+		}
+		if orphans > 0 {
+			fmt.Printf("\n%d installs are no longer in any configuration; see depman list --orphans\n", orphans)
+		}
+	}
+	return nil
+}
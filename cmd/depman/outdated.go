@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Outdated flags
+	outdatedJSON bool
+
+	// Outdated command
+	outdatedCmd = &cobra.Command{
+		Use:   "outdated",
+		Short: "List dependencies with newer versions available",
+		Long: `Outdated compares each installed dependency with the versions its source
+offers and prints the current, wanted (newest allowed by the
+configuration) and latest versions. It exits with an error when anything is
+missing or outdated, so CI can gate on it.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if outdatedJSON {
+				outputFormat = "json"
+			}
+			return runOutdated(cmd.Context())
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(outdatedCmd)
+	outdatedCmd.Flags().BoolVar(&outdatedJSON, "json", false, "Print the report as JSON (same as --output json)")
+}
+
+// runOutdated prints the outdated report and fails if anything is outdated
+func runOutdated(ctx context.Context) error {
+	manager, err := createManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize: %w", err)
+	}
+
+	report, err := manager.Outdated(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check for newer versions: %w", err)
+	}
+
+	outdated := 0
+	for _, entry := range report {
+		if entry.Outdated {
+			outdated++
+		}
+	}
+
+	if jsonOutput() {
+		if err := printJSON(report); err != nil {
+			return err
+		}
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tCURRENT\tWANTED\tLATEST\t")
+		for _, entry := range report {
+			current := entry.Current
+			if current == "" {
+				current = "missing"
+			}
+			if entry.Error != "" {
+				fmt.Fprintf(w, "%s\t%s\t-\t-\t(%s)\n", entry.Name, current, entry.Error)
+				continue
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t\n", entry.Name, current, entry.Wanted, entry.Latest)
+		}
+		w.Flush()
+	}
+
+	if outdated > 0 {
+		return &exitError{code: exitOutdated, err: fmt.Errorf("%d of %d dependencies are outdated", outdated, len(report))}
+	}
+	return nil
+}
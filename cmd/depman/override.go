@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/devnadeemashraf/depman/pkg/depman"
+)
+
+// parsePins parses --pin values of the form name=version
+func parsePins(values []string) (map[string]string, error) {
+	pins := make(map[string]string, len(values))
+	for _, value := range values {
+		name, version, ok := strings.Cut(value, "=")
+		name, version = strings.TrimSpace(name), strings.TrimSpace(version)
+		if !ok || name == "" || version == "" {
+			return nil, fmt.Errorf("invalid --pin '%s' (expected name=version)", value)
+		}
+		if previous, ok := pins[name]; ok && previous != version {
+			return nil, fmt.Errorf("--pin %s given twice, as %s and %s", name, previous, version)
+		}
+		pins[name] = version
+	}
+	return pins, nil
+}
+
+// printOverrides prints a line per dependency whose required version is
+// overridden locally, so a check does not pass only because of a pin
+func printOverrides(w io.Writer, manager *depman.Manager, color bool) {
+	overrides := manager.Overrides()
+	if len(overrides) == 0 {
+		return
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, colorize("Local overrides:", colorYellow, color))
+	for _, override := range overrides {
+		fmt.Fprintf(w, "  %s: %s (%s)\n", override.Dependency, override.String(), override.Source)
+	}
+	fmt.Fprintf(w, "The shared configuration is unchanged; remove the entries from %s to follow it again\n", manager.OverridePath())
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/devnadeemashraf/depman/pkg/depman"
+)
+
+// runPathAudit lists the dependencies with more than one copy of their
+// executable reachable, with the version of each and how to resolve
+// conflicts. It fails if any dependency is shadowed.
+func runPathAudit(ctx context.Context) error {
+	manager, err := createManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize: %w", err)
+	}
+	audits, err := manager.AuditPath(ctx)
+	if err != nil {
+		return err
+	}
+
+	shadowed := 0
+	for _, audit := range audits {
+		if audit.Shadowed() {
+			shadowed++
+		}
+	}
+
+	if jsonOutput() {
+		if err := printJSON(audits); err != nil {
+			return err
+		}
+	} else if len(audits) == 0 {
+		fmt.Println("Every dependency has a single copy of its executable on PATH")
+	} else {
+		color := colorOutput(os.Stdout)
+		for i, audit := range audits {
+			if i > 0 {
+				fmt.Println()
+			}
+			printPathAudit(os.Stdout, audit, color)
+		}
+	}
+
+	if shadowed > 0 {
+		return fmt.Errorf("%d dependencies are shadowed on PATH", shadowed)
+	}
+	return nil
+}
+
+// printPathAudit prints every copy of a dependency's executable, marking the
+// one shells run and the one depman checked, and what to do about conflicts
+func printPathAudit(w io.Writer, audit *depman.PathAudit, color bool) {
+	heading := fmt.Sprintf("== %s (%s) ==", audit.Dependency, audit.Binary)
+	if audit.Shadowed() {
+		fmt.Fprintln(w, colorize(heading+" shadowed", colorRed, color))
+	} else {
+		fmt.Fprintln(w, colorize(heading, colorYellow, color))
+	}
+
+	t := newTable(tableColumn{name: "PATH"}, tableColumn{name: "VERSION"}, tableColumn{name: "MANAGED"}, tableColumn{name: "ROLE"})
+	for _, installation := range audit.Installations {
+		role := ""
+		switch {
+		case audit.Active != nil && installation.Path == audit.Active.Path && audit.Checked != nil && installation.Path == audit.Checked.Path:
+			role = "run by shells, checked"
+		case audit.Active != nil && installation.Path == audit.Active.Path:
+			role = "run by shells"
+		case audit.Checked != nil && installation.Path == audit.Checked.Path:
+			role = "checked"
+		default:
+			role = "hidden"
+		}
+		managed := "no"
+		if installation.Managed {
+			managed = "yes"
+		}
+		t.add(installation.Path, installation.Version, managed, role)
+	}
+	t.render(w)
+
+	if steps := audit.Suggestions(); len(steps) > 0 {
+		fmt.Fprintln(w, "Next steps:")
+		for _, step := range steps {
+			fmt.Fprintf(w, "  - %s\n", step)
+		}
+	}
+}
+
+// printShadowed prints a line per checked dependency whose managed copy is
+// shadowed by another earlier on PATH
+func printShadowed(w io.Writer, ordered []*depman.DependencyStatus, color bool) {
+	first := true
+	for _, status := range ordered {
+		if status.ShadowedBy == nil {
+			continue
+		}
+		if first {
+			fmt.Fprintln(w)
+			fmt.Fprintln(w, colorize("Shadowed on PATH:", colorYellow, color))
+			first = false
+		}
+		version := status.ShadowedBy.Version
+		if version == "" {
+			version = "unknown version"
+		}
+		fmt.Fprintf(w, "  %s: shells run %s (%s), not the managed copy (%s) that was checked\n", status.Name, status.ShadowedBy.Path, version, status.CurrentVersion)
+	}
+	if !first {
+		fmt.Fprintln(w, "Run 'depman check --path-audit' to see every copy and how to fix it")
+	}
+}
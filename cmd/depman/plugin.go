@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/devnadeemashraf/depman/pkg/depman"
+	"github.com/devnadeemashraf/depman/pkg/plugin"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Plugin install flags
+	pluginName string
+
+	// Plugin command
+	pluginCmd = &cobra.Command{
+		Use:   "plugin",
+		Short: "Manage install method plugins",
+		Long: `Plugins are executables named depman-plugin-<name> that provide additional
+install methods. A dependency uses a plugin by setting installer.method to the
+plugin's name.`,
+	}
+
+	// Plugin install command
+	pluginInstallCmd = &cobra.Command{
+		Use:   "install <path>",
+		Short: "Install a plugin executable",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPluginInstall(args[0])
+		},
+	}
+
+	// Plugin list command
+	pluginListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List installed plugins",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPluginList()
+		},
+	}
+
+	// Plugin remove command
+	pluginRemoveCmd = &cobra.Command{
+		Use:   "remove <name>",
+		Short: "Remove an installed plugin",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPluginRemove(args[0])
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(pluginCmd)
+	pluginCmd.AddCommand(pluginInstallCmd, pluginListCmd, pluginRemoveCmd)
+	pluginInstallCmd.Flags().StringVar(&pluginName, "name", "", "Install method name (default: derived from the file name)")
+}
+
+// runPluginInstall copies a plugin into the plugin directory
+func runPluginInstall(path string) error {
+	target, err := plugin.Install(path, depman.DefaultPluginDir(), pluginName)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Installed plugin %s to %s\n", plugin.NameFromPath(target), target)
+	return nil
+}
+
+// runPluginList prints the installed plugins
+func runPluginList() error {
+	plugins, err := plugin.List(depman.DefaultPluginDir())
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput() {
+		return printJSON(plugins)
+	}
+
+	if len(plugins) == 0 {
+		fmt.Println("No plugins installed")
+		return nil
+	}
+
+	for _, p := range plugins {
+		fmt.Printf("%s\t%s\n", p.Name, p.Path)
+	}
+	return nil
+}
+
+// runPluginRemove deletes an installed plugin
+func runPluginRemove(name string) error {
+	if err := plugin.Remove(depman.DefaultPluginDir(), name); err != nil {
+		return err
+	}
+
+	fmt.Printf("Removed plugin %s\n", name)
+	return nil
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/devnadeemashraf/depman/pkg/depman"
+)
+
+// The --porcelain format is fixed: fields are separated by tabs, empty fields
+// are "-", and neither the fields nor the words in them change between
+// versions. check prints "<name> <status> <installed> <wanted>" and ensure
+// "<name> <result> <version> <previous>" for each dependency, in
+// configuration order.
+
+// scriptOutput checks that --quiet and --porcelain are not combined with each
+// other or with another output format, and keeps logs below errors out of
+// the way when --quiet is set
+func scriptOutput() error {
+	if !quiet && !porcelain {
+		return nil
+	}
+	if quiet && porcelain {
+		return fmt.Errorf("--quiet cannot be combined with --porcelain")
+	}
+	if strings.ToLower(outputFormat) != "text" {
+		return fmt.Errorf("--quiet and --porcelain cannot be combined with --output %s", outputFormat)
+	}
+	if quiet && !rootCmd.PersistentFlags().Changed("log-level") && !verbose {
+		logLevel = "error"
+	}
+	return nil
+}
+
+// printPorcelainCheck prints a line per dependency after a check
+func printPorcelainCheck(w io.Writer, manager *depman.Manager, ordered []*depman.DependencyStatus) {
+	for _, status := range ordered {
+		wanted := ""
+		if dep := manager.FindDependency(status.Name); dep != nil {
+			wanted = dep.Version.Target()
+		}
+		porcelainLine(w, status.Name, statusLabel(status), status.CurrentVersion, wanted)
+	}
+}
+
+// printPorcelainEnsure prints a line per dependency after an install run
+func printPorcelainEnsure(w io.Writer, ordered []*depman.DependencyStatus) {
+	for _, status := range ordered {
+		porcelainLine(w, status.Name, ensureResult(status), status.CurrentVersion, status.PreviousVersion)
+	}
+}
+
+// ensureResult names what an install run did to a dependency: failed,
+// installed, upgraded, downgraded, reinstalled, skipped, provided or unchanged
+func ensureResult(status *depman.DependencyStatus) string {
+	switch {
+	case status.Error != nil:
+		return "failed"
+	case status.Change != depman.ChangeNone:
+		return string(status.Change)
+	case status.Skipped:
+		return "skipped"
+	case status.ProvidedBy != "":
+		return "provided"
+	default:
+		return "unchanged"
+	}
+}
+
+// porcelainLine writes fields separated by tabs, with "-" for empty ones
+func porcelainLine(w io.Writer, fields ...string) {
+	for i, field := range fields {
+		if field == "" {
+			fields[i] = "-"
+		}
+	}
+	fmt.Fprintln(w, strings.Join(fields, "\t"))
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/devnadeemashraf/depman/internal/logger"
+	"github.com/devnadeemashraf/depman/pkg/depman"
+)
+
+// spinnerFrames animate steps that report no progress of their own
+var spinnerFrames = []string{"-", "\\", "|", "/"}
+
+// progressLine is the rendered state of one dependency
+type progressLine struct {
+	name       string
+	step       depman.Step
+	done       bool
+	err        error
+	downloaded int64
+	total      int64
+}
+
+// progressRenderer draws a live line per dependency on a terminal
+type progressRenderer struct {
+	mu    sync.Mutex
+	out   io.Writer
+	lines []*progressLine
+	index map[string]*progressLine
+	drawn int
+	frame int
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+// newProgressRenderer returns a renderer drawing to stderr, or nil when stderr
+// is not a terminal or machine-readable or quiet output was requested
+func newProgressRenderer() *progressRenderer {
+	if jsonOutput() || quiet || porcelain || verbose || !isTerminal(os.Stderr) {
+		return nil
+	}
+
+	r := &progressRenderer{
+		out:   os.Stderr,
+		index: make(map[string]*progressLine),
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	go r.animate()
+	return r
+}
+
+// isTerminal reports whether f is an interactive terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// OnStepStart marks a dependency as working on a step
+func (r *progressRenderer) OnStepStart(dependency string, step depman.Step) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	line := r.line(dependency)
+	line.step, line.done, line.err = step, false, nil
+	line.downloaded, line.total = 0, 0
+	r.draw()
+}
+
+// OnStepEnd marks a step as finished
+func (r *progressRenderer) OnStepEnd(dependency string, step depman.Step, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	line := r.line(dependency)
+	line.step, line.done, line.err = step, true, err
+	r.draw()
+}
+
+// OnDownloadProgress updates a dependency's download bar
+func (r *progressRenderer) OnDownloadProgress(dependency string, downloaded, total int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	line := r.line(dependency)
+	line.downloaded, line.total = downloaded, total
+	r.draw()
+}
+
+// Stop ends the animation and leaves the final state on screen
+func (r *progressRenderer) Stop() {
+	close(r.stop)
+	<-r.done
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.draw()
+}
+
+// animate redraws periodically so spinners move while steps run
+func (r *progressRenderer) animate() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			r.mu.Lock()
+			r.frame++
+			r.draw()
+			r.mu.Unlock()
+		}
+	}
+}
+
+// line returns the state of a dependency, adding it in first-seen order
+func (r *progressRenderer) line(dependency string) *progressLine {
+	line, ok := r.index[dependency]
+	if !ok {
+		line = &progressLine{name: dependency}
+		r.index[dependency] = line
+		r.lines = append(r.lines, line)
+	}
+	return line
+}
+
+// draw rewrites every line in place; callers hold r.mu
+func (r *progressRenderer) draw() {
+	var b strings.Builder
+	if r.drawn > 0 {
+		fmt.Fprintf(&b, "\033[%dA", r.drawn)
+	}
+
+	width := 0
+	for _, line := range r.lines {
+		width = max(width, len(line.name))
+	}
+	for _, line := range r.lines {
+		fmt.Fprintf(&b, "\r\033[K%s %-*s  %s\n", r.icon(line), width, line.name, describeProgress(line))
+	}
+
+	r.drawn = len(r.lines)
+	io.WriteString(r.out, b.String())
+}
+
+// icon returns the status symbol of a line
+func (r *progressRenderer) icon(line *progressLine) string {
+	switch {
+	case line.err != nil:
+		return "x"
+	case line.done:
+		return "+"
+	default:
+		return spinnerFrames[r.frame%len(spinnerFrames)]
+	}
+}
+
+// describeProgress renders what a dependency is doing
+func describeProgress(line *progressLine) string {
+	if line.err != nil {
+		message := line.err.Error()
+		if len(message) > 60 {
+			message = message[:57] + "..."
+		}
+		return fmt.Sprintf("%s failed: %s", line.step, message)
+	}
+
+	if line.done {
+		switch line.step {
+		case depman.StepCheck:
+			return "checked"
+		case depman.StepDownload:
+			return "downloaded " + formatBytes(line.downloaded)
+		case depman.StepInstall:
+			return "installed"
+		case depman.StepVerify:
+			return "verified"
+		default:
+			return "done"
+		}
+	}
+
+	if line.step == depman.StepDownload && line.total > 0 {
+		const barWidth = 24
+		filled := int(line.downloaded * barWidth / line.total)
+		return fmt.Sprintf("downloading [%s%s] %3d%% %s/%s",
+			strings.Repeat("=", filled), strings.Repeat(" ", barWidth-filled),
+			line.downloaded*100/line.total, formatBytes(line.downloaded), formatBytes(line.total))
+	}
+	if line.step == depman.StepDownload {
+		return "downloading " + formatBytes(line.downloaded)
+	}
+
+	return string(line.step) + "ing..."
+}
+
+// formatBytes renders a byte count with a binary unit
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// progressOptions returns manager options that render progress on the
+// terminal, and a function to call once the run is over
+func progressOptions() ([]depman.Option, func()) {
+	renderer := newProgressRenderer()
+	if renderer == nil {
+		return nil, func() {}
+	}
+
+	options := []depman.Option{depman.WithReporter(newTerminalReporter(renderer))}
+
+	// Informational logs would tear the live lines apart
+	if strings.ToLower(logLevel) == "info" {
+		options = append(options, depman.WithLogLevel(logger.LevelWarn))
+	}
+
+	return options, renderer.Stop
+}
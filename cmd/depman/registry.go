@@ -0,0 +1,257 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/devnadeemashraf/depman/pkg/depman"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Registry add flags
+	registryPriority int
+	registryAuthType string
+	registryUsername string
+	registryHelper   []string
+	registryKeychain bool
+
+	// Registry command
+	registryCmd = &cobra.Command{
+		Use:   "registry",
+		Short: "Manage the registries of install recipes",
+		Long: `Registries hold install recipes for tools. A dependency with
+"from: registry/<name>" inherits the recipe's installers, checksums and version
+detection, and only needs to pin a version. depman ships with the public
+registry, named depman; organizations can host their own registry file over
+HTTP, in a git repository or in an OCI registry and add it with
+'depman registry add'. When several registries have a recipe for a tool, the
+one with the highest priority wins; "from: <registry>/<name>" picks a
+registry explicitly. Registries are fetched into ~/.depman with
+'depman registry update'.`,
+	}
+
+	// Registry add command
+	registryAddCmd = &cobra.Command{
+		Use:   "add <name> <url>",
+		Short: "Add a registry and fetch it",
+		Long: `Add configures a registry and fetches it. The URL is an http(s) URL, a
+git::<repo>//<file>?ref=<ref> reference or an oci:// reference of a registry
+file, e.g.
+
+  depman registry add acme https://tools.acme.internal/registry.yml --priority 10
+  depman registry add acme "git::git@github.com:acme/tools.git//registry.yml?ref=main"
+
+Private hosts authenticate with DEPMAN_AUTH_<HOST>_* variables or netrc like
+downloads do, or with the credential given by the flags.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRegistryAdd(cmd.Context(), args[0], args[1])
+		},
+	}
+
+	// Registry remove command
+	registryRemoveCmd = &cobra.Command{
+		Use:     "remove <name>",
+		Aliases: []string{"rm"},
+		Short:   "Remove a registry and its fetched recipes",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRegistryRemove(args[0])
+		},
+	}
+
+	// Registry list command
+	registryListCmd = &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List the registries, highest priority first",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRegistryList()
+		},
+	}
+
+	// Registry search command
+	registrySearchCmd = &cobra.Command{
+		Use:   "search [term]",
+		Short: "Search the registries for recipes (same as 'depman search')",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			term := ""
+			if len(args) > 0 {
+				term = args[0]
+			}
+			return runSearch(cmd.Context(), term)
+		},
+	}
+
+	// Registry show command
+	registryShowCmd = &cobra.Command{
+		Use:   "show <name>",
+		Short: "Print the recipe for a tool, as <name> or <registry>/<name>",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRegistryShow(args[0])
+		},
+	}
+
+	// Registry update command
+	registryUpdateCmd = &cobra.Command{
+		Use:   "update [name]...",
+		Short: "Fetch the newest recipes of the registries (all by default)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRegistryUpdate(cmd.Context(), args)
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(registryCmd)
+	registryCmd.AddCommand(registryAddCmd, registryRemoveCmd, registryListCmd, registrySearchCmd, registryShowCmd, registryUpdateCmd)
+	registrySearchCmd.Flags().IntVar(&searchVersions, "versions", 3, "Newest versions to list per recipe (0 to skip looking them up)")
+	registryAddCmd.Flags().IntVar(&registryPriority, "priority", 0, "Priority over other registries with the same recipes (the public registry has 0)")
+	registryAddCmd.Flags().StringVar(&registryAuthType, "auth-type", "", "How to authenticate to the host: basic, bearer or api-key")
+	registryAddCmd.Flags().StringVar(&registryUsername, "username", "", "Username for basic auth when the secret's source has none")
+	registryAddCmd.Flags().StringSliceVar(&registryHelper, "credential-helper", nil, "Credential helper command, run with \"get\" appended")
+	registryAddCmd.Flags().BoolVar(&registryKeychain, "keychain", false, "Look the secret up in the OS keychain")
+}
+
+// runRegistryAdd configures a registry and fetches it
+func runRegistryAdd(ctx context.Context, name, url string) error {
+	source := depman.RegistrySource{Name: name, URL: url, Priority: registryPriority}
+	if registryAuthType != "" || registryUsername != "" || len(registryHelper) > 0 || registryKeychain {
+		source.Credential = &depman.Credential{
+			Type:     registryAuthType,
+			Username: registryUsername,
+			Helper:   registryHelper,
+			Keychain: registryKeychain,
+		}
+	}
+
+	homeDir := depman.DefaultHomeDir()
+	if err := depman.AddRegistrySource(homeDir, source); err != nil {
+		return err
+	}
+	registry, err := updateRegistry(ctx, homeDir, name)
+	if err != nil {
+		return fmt.Errorf("%w; the registry was added, retry with 'depman registry update %s'", err, name)
+	}
+	fmt.Printf("Added registry %s: %d recipes\n", name, len(registry.Recipes))
+	return nil
+}
+
+// runRegistryRemove removes a configured registry
+func runRegistryRemove(name string) error {
+	if err := depman.RemoveRegistrySource(depman.DefaultHomeDir(), name); err != nil {
+		return err
+	}
+	fmt.Printf("Removed registry %s\n", name)
+	return nil
+}
+
+// runRegistryList prints the configured registries
+func runRegistryList() error {
+	statuses, err := depman.RegistryStatuses(depman.DefaultHomeDir())
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput() {
+		type registryJSON struct {
+			Name     string `json:"name"`
+			URL      string `json:"url"`
+			Priority int    `json:"priority"`
+			Recipes  int    `json:"recipes"`
+			Updated  string `json:"updated,omitempty"`
+		}
+		out := make([]registryJSON, 0, len(statuses))
+		for _, status := range statuses {
+			entry := registryJSON{Name: status.Name, URL: status.URL, Priority: status.Priority, Recipes: status.Recipes}
+			if !status.Updated.IsZero() {
+				entry.Updated = status.Updated.UTC().Format("2006-01-02T15:04:05Z")
+			}
+			out = append(out, entry)
+		}
+		return printJSON(out)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tPRIORITY\tRECIPES\tUPDATED\tURL")
+	for _, status := range statuses {
+		updated := "never"
+		if !status.Updated.IsZero() {
+			updated = status.Updated.Format("2006-01-02 15:04")
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%s\n", status.Name, status.Priority, status.Recipes, updated, status.URL)
+	}
+	return w.Flush()
+}
+
+// runRegistryShow prints a recipe as YAML
+func runRegistryShow(ref string) error {
+	registry, err := depman.LoadRegistry(depman.DefaultHomeDir())
+	if err != nil {
+		return err
+	}
+	from := ref
+	if !strings.Contains(ref, "/") {
+		from = "registry/" + ref
+	}
+	recipe, err := registry.Lookup(from)
+	if err != nil {
+		return err
+	}
+
+	data, err := recipe.Encode()
+	if err != nil {
+		return err
+	}
+	fmt.Print(string(data))
+	return nil
+}
+
+// runRegistryUpdate fetches the named registries, or all of them
+func runRegistryUpdate(ctx context.Context, names []string) error {
+	homeDir := depman.DefaultHomeDir()
+	if len(names) == 0 {
+		sources, err := depman.RegistrySources(homeDir)
+		if err != nil {
+			return err
+		}
+		for _, source := range sources {
+			names = append(names, source.Name)
+		}
+	}
+
+	var failed []string
+	for _, name := range names {
+		registry, err := updateRegistry(ctx, homeDir, name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			failed = append(failed, name)
+			continue
+		}
+		fmt.Printf("Registry %s updated: %d recipes\n", name, len(registry.Recipes))
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to update registries: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// updateRegistry fetches a registry with the TLS settings of the global flags
+func updateRegistry(ctx context.Context, homeDir, name string) (*depman.Registry, error) {
+	client, err := httpClient()
+	if err != nil {
+		return nil, err
+	}
+	var options []depman.Option
+	if client != nil {
+		options = append(options, depman.WithHTTPClient(client))
+	}
+	return depman.UpdateRegistry(ctx, homeDir, name, options...)
+}
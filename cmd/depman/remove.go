@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Remove flags
+	removeForce bool
+
+	// Remove command
+	removeCmd = &cobra.Command{
+		Use:     "remove <name>...",
+		Aliases: []string{"uninstall"},
+		Short:   "Uninstall dependencies that were installed by depman",
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRemove(cmd.Context(), args)
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(removeCmd)
+	removeCmd.Flags().BoolVarP(&removeForce, "force", "f", false, "Remove even if other dependencies still require it")
+}
+
+// runRemove uninstalls the named dependencies
+func runRemove(ctx context.Context, names []string) error {
+	manager, err := createManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize: %w", err)
+	}
+
+	for _, name := range names {
+		if err := manager.RemoveDependency(ctx, name, removeForce); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", name, err)
+		}
+		fmt.Printf("Removed %s\n", name)
+	}
+
+	return nil
+}
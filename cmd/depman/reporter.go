@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/devnadeemashraf/depman/pkg/depman"
+)
+
+// terminalReporter shows depman's progress and questions on the terminal
+type terminalReporter struct {
+	mu       sync.Mutex
+	renderer *progressRenderer // nil when progress is not drawn
+	stdin    *bufio.Reader
+}
+
+// newTerminalReporter returns a reporter drawing progress with renderer, which may be nil
+func newTerminalReporter(renderer *progressRenderer) *terminalReporter {
+	return &terminalReporter{renderer: renderer, stdin: bufio.NewReader(os.Stdin)}
+}
+
+func (r *terminalReporter) OnStepStart(dependency string, step depman.Step) {
+	if r.renderer != nil {
+		r.renderer.OnStepStart(dependency, step)
+	}
+}
+
+func (r *terminalReporter) OnStepEnd(dependency string, step depman.Step, err error) {
+	if r.renderer != nil {
+		r.renderer.OnStepEnd(dependency, step, err)
+	}
+}
+
+func (r *terminalReporter) OnDownloadProgress(dependency string, downloaded, total int64) {
+	if r.renderer != nil {
+		r.renderer.OnDownloadProgress(dependency, downloaded, total)
+	}
+}
+
+// Confirm asks on stderr and reads the answer from stdin, one question at a time
+func (r *terminalReporter) Confirm(question string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", question)
+	response, err := r.stdin.ReadString('\n')
+	if err != nil && response == "" {
+		return false, fmt.Errorf("failed to read answer: %w", err)
+	}
+	response = strings.ToLower(strings.TrimSpace(response))
+	return response == "y" || response == "yes", nil
+}
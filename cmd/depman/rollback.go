@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// Rollback command
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback <name>",
+	Short: "Revert the last install or upgrade of a dependency to the version before it",
+	Long: `Revert the last install or upgrade of a dependency to the version before it.
+
+Tools installed side by side switch back to the previous version; other
+install methods install it again. Running rollback twice undoes the rollback.
+Failed installs are rolled back automatically unless --no-rollback is set.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runRollback(cmd.Context(), args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rollbackCmd)
+}
+
+// runRollback reverts a dependency to its previous version
+func runRollback(ctx context.Context, name string) error {
+	manager, err := createManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize: %w", err)
+	}
+
+	status, err := manager.RollbackDependency(ctx, name)
+	if err != nil {
+		return fmt.Errorf("failed to roll back %s: %w", name, err)
+	}
+
+	if jsonOutput() {
+		return printJSON(status)
+	}
+	fmt.Printf("Rolled back %s to %s\n", name, status.CurrentVersion)
+	return nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/devnadeemashraf/depman/pkg/depman"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Run flags
+	runNoEnsure bool
+
+	// Run command
+	runCmd = &cobra.Command{
+		Use:   "run [task] [args...]",
+		Short: "Run a task from the configuration with the managed toolchain",
+		Long: `Run ensures the dependencies a task needs, then runs its commands from the
+configuration's directory with the managed toolchain active, as 'depman
+exec' does. Arguments after the task name are appended to its last command.
+Without a task name, run lists the tasks.
+
+  tasks:
+    lint:
+      description: Lint the Go code
+      needs: [golangci-lint]      # Dependencies or tags (default: all)
+      run: ["golangci-lint run ./..."]
+
+Depman logs only warnings and errors, to stderr. Run exits with the exit
+code of the command that failed.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return runListTasks()
+			}
+			return runTask(cmd, args[0], args[1:])
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(runCmd)
+	// Flags after the task name belong to it
+	runCmd.Flags().SetInterspersed(false)
+	runCmd.Flags().BoolVar(&runNoEnsure, "no-ensure", false, "Run the task without installing or updating anything first")
+}
+
+// runTask runs a task, exiting with the exit code of a failed command
+func runTask(cmd *cobra.Command, name string, args []string) error {
+	logsToStderr = true
+	if !rootCmd.PersistentFlags().Changed("log-level") && !verbose {
+		logLevel = "warn"
+	}
+
+	manager, err := createManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize: %w", err)
+	}
+
+	err = manager.RunTask(cmd.Context(), name, depman.TaskOptions{
+		Args:     args,
+		NoEnsure: runNoEnsure,
+		Stdin:    os.Stdin,
+		Stdout:   os.Stdout,
+		Stderr:   os.Stderr,
+	})
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 {
+		return &exitError{code: exitErr.ExitCode(), err: err}
+	}
+	return err
+}
+
+// runListTasks lists the configuration's tasks
+func runListTasks() error {
+	manager, err := createManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize: %w", err)
+	}
+
+	names := manager.TaskNames()
+	if jsonOutput() {
+		type taskEntry struct {
+			Name        string   `json:"name"`
+			Description string   `json:"description,omitempty"`
+			Needs       []string `json:"needs,omitempty"`
+			Run         []string `json:"run"`
+			Dir         string   `json:"dir,omitempty"`
+		}
+		entries := []taskEntry{}
+		for _, name := range names {
+			task := manager.Config.Tasks[name]
+			entries = append(entries, taskEntry{Name: name, Description: task.Description, Needs: task.Needs, Run: task.Run, Dir: task.Dir})
+		}
+		return printJSON(entries)
+	}
+
+	if len(names) == 0 {
+		fmt.Println("No tasks defined; add them under tasks in the configuration")
+		return nil
+	}
+	t := newTable(tableColumn{name: "TASK"}, tableColumn{name: "DESCRIPTION"}, tableColumn{name: "NEEDS"}, tableColumn{name: "RUNS", wide: true})
+	for _, name := range names {
+		task := manager.Config.Tasks[name]
+		t.add(name, task.Description, strings.Join(task.Needs, ", "), strings.Join(task.Run, " && "))
+	}
+	return t.render(os.Stdout)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/devnadeemashraf/depman/pkg/depman"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// SBOM flags
+	sbomFormat string
+
+	// SBOM command
+	sbomCmd = &cobra.Command{
+		Use:   "sbom [path]",
+		Short: "Write a software bill of materials for the managed dependencies",
+		Long: `SBOM lists every dependency on this platform with its resolved version,
+download URL, checksum and license as a CycloneDX or SPDX JSON document.
+The document is written to path, or to standard output if none is given.
+Run it after 'depman ensure' so the lockfile holds the resolved artifacts.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := ""
+			if len(args) > 0 {
+				path = args[0]
+			}
+			return runSBOM(cmd.Context(), path)
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(sbomCmd)
+	sbomCmd.Flags().StringVar(&sbomFormat, "format", "cyclonedx", "SBOM format (cyclonedx, spdx)")
+}
+
+// runSBOM writes the bill of materials to path or standard output
+func runSBOM(ctx context.Context, path string) error {
+	format, err := depman.ParseSBOMFormat(sbomFormat)
+	if err != nil {
+		return err
+	}
+
+	manager, err := createManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize: %w", err)
+	}
+
+	var w io.Writer = os.Stdout
+	if path != "" {
+		file, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", path, err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	if err := manager.WriteSBOM(ctx, w, format); err != nil {
+		return fmt.Errorf("failed to write SBOM: %w", err)
+	}
+	return nil
+}
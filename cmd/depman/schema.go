@@ -0,0 +1,26 @@
+package main
+
+import (
+	"os"
+
+	"github.com/devnadeemashraf/depman/pkg/depman"
+	"github.com/spf13/cobra"
+)
+
+// Schema command
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema of 'depman check --output json'",
+	Long: `Schema prints the JSON Schema document describing the report printed by
+'depman check --output json'. The report's schema_version is raised when a
+field is removed or changes meaning.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, err := os.Stdout.Write(depman.CheckReportSchema())
+		return err
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+}
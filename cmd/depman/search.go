@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/devnadeemashraf/depman/pkg/depman"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Search flags
+	searchVersions int
+
+	// Search command
+	searchCmd = &cobra.Command{
+		Use:   "search [term]",
+		Short: "Search the registries for recipes",
+		Long: `Search looks for recipes whose name, description or tags contain the term
+in every registry, including the built-in recipes, and prints the newest
+versions each one's installer for this platform offers and the platforms it
+supports. Use the FROM column with 'depman add', e.g.
+
+  depman search kube
+  depman add kubectl`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			term := ""
+			if len(args) > 0 {
+				term = args[0]
+			}
+			return runSearch(cmd.Context(), term)
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+	searchCmd.Flags().IntVar(&searchVersions, "versions", 3, "Newest versions to list per recipe (0 to skip looking them up)")
+}
+
+// runSearch prints the recipes matching a term
+func runSearch(ctx context.Context, term string) error {
+	options, err := managerOptions()
+	if err != nil {
+		return err
+	}
+	manager, err := depman.NewManager("", append(options, depman.WithConfig(depman.NewConfig("search")))...)
+	if err != nil {
+		return fmt.Errorf("failed to initialize: %w", err)
+	}
+
+	results, err := manager.SearchRecipes(ctx, term, searchVersions)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput() {
+		return printJSON(results)
+	}
+	if len(results) == 0 {
+		return fmt.Errorf("no recipe matches '%s'; see 'depman registry list' for the registries searched", term)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tFROM\tCONSTRAINT\tVERSIONS\tPLATFORMS\tDESCRIPTION")
+	for _, result := range results {
+		versions := strings.Join(result.Versions, ", ")
+		switch {
+		case searchVersions <= 0:
+			versions = "-"
+		case result.Error != "":
+			versions = "unavailable"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", result.Name, result.From, result.Constraint, versions,
+			strings.Join(result.Platforms, ","), result.Description)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/devnadeemashraf/depman/internal/downloader"
+	"github.com/devnadeemashraf/depman/internal/github"
+	"github.com/devnadeemashraf/depman/internal/httpclient"
+	"github.com/devnadeemashraf/depman/internal/selfupdate"
+	"github.com/devnadeemashraf/depman/pkg/depman"
+	"github.com/spf13/cobra"
+)
+
+// channelFileName is the file under the depman home holding the pinned release channel
+const channelFileName = "channel"
+
+var (
+	// Self-update flags
+	selfUpdateChannel string
+	selfUpdateCheck   bool
+	selfUpdateForce   bool
+
+	// Self-update command
+	selfUpdateCmd = &cobra.Command{
+		Use:   "self-update",
+		Short: "Update depman to the newest signed release",
+		Long: `Self-update installs the newest depman release for this platform from
+GitHub. The binary must carry a valid signature from the depman release key
+and replaces the running executable atomically.
+
+--channel pins the release channel for later updates: stable (default)
+installs published releases only, beta prereleases too.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSelfUpdate(cmd.Context())
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(selfUpdateCmd)
+	selfUpdateCmd.Flags().StringVar(&selfUpdateChannel, "channel", "", "Release channel to pin, stable or beta (default: the pinned channel, else stable)")
+	selfUpdateCmd.Flags().BoolVar(&selfUpdateCheck, "check", false, "Only report whether an update is available")
+	selfUpdateCmd.Flags().BoolVarP(&selfUpdateForce, "force", "f", false, "Install the newest release even if it is not newer, or over a development build")
+}
+
+// updateChannel returns the channel to update from, pinning --channel if given
+func updateChannel() (selfupdate.Channel, error) {
+	path := filepath.Join(depman.DefaultHomeDir(), channelFileName)
+
+	if selfUpdateChannel != "" {
+		channel, err := selfupdate.ParseChannel(selfUpdateChannel)
+		if err != nil {
+			return "", err
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return "", fmt.Errorf("failed to pin channel: %w", err)
+		}
+		if err := os.WriteFile(path, []byte(string(channel)+"\n"), 0644); err != nil {
+			return "", fmt.Errorf("failed to pin channel: %w", err)
+		}
+		return channel, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return selfupdate.ChannelStable, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read pinned channel: %w", err)
+	}
+	return selfupdate.ParseChannel(strings.TrimSpace(string(data)))
+}
+
+// runSelfUpdate downloads, verifies and installs the newest release
+func runSelfUpdate(ctx context.Context) error {
+	channel, err := updateChannel()
+	if err != nil {
+		return err
+	}
+
+	client, err := httpClient()
+	if err != nil {
+		return err
+	}
+	if client == nil {
+		client = httpclient.Default()
+	}
+
+	release, err := selfupdate.Latest(ctx, client, channel, runtime.GOOS, runtime.GOARCH, github.Token(""))
+	if err != nil {
+		return fmt.Errorf("failed to look up releases: %w", err)
+	}
+
+	current, err := semver.NewVersion(version)
+	switch {
+	case err != nil && !selfUpdateForce && !selfUpdateCheck:
+		return fmt.Errorf("this is a development build (version %s); use --force to replace it with %s", version, release.Tag)
+	case err == nil && !release.Version.GreaterThan(current) && !selfUpdateForce:
+		fmt.Printf("depman %s is up to date (newest on the %s channel: %s)\n", version, channel, release.Tag)
+		return nil
+	}
+
+	if selfUpdateCheck {
+		fmt.Printf("Update available: %s -> %s (%s channel)\n", version, release.Tag, channel)
+		return nil
+	}
+
+	if releasePublicKey == "" {
+		return fmt.Errorf("this build of depman has no release signing key; download %s from https://github.com/%s/releases", release.Tag, selfupdate.Repo)
+	}
+	key, err := selfupdate.ParsePublicKey(releasePublicKey)
+	if err != nil {
+		return err
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate the depman executable: %w", err)
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return fmt.Errorf("failed to locate the depman executable: %w", err)
+	}
+
+	dir, err := selfupdate.StagingDir(exe)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	fmt.Printf("Downloading depman %s...\n", release.Tag)
+	binary, err := downloader.Download(ctx, downloader.DownloadOptions{URL: release.BinaryURL, DestDir: dir, Filename: "depman.new", Client: client})
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", release.Tag, err)
+	}
+	signature, err := downloader.Download(ctx, downloader.DownloadOptions{URL: release.SignatureURL, DestDir: dir, Filename: "depman.sig", Client: client})
+	if err != nil {
+		return fmt.Errorf("failed to download the signature of %s: %w", release.Tag, err)
+	}
+
+	sig, err := os.ReadFile(signature.FilePath)
+	if err != nil {
+		return err
+	}
+	if err := selfupdate.VerifyFile(binary.FilePath, sig, key); err != nil {
+		return err
+	}
+
+	if err := selfupdate.Replace(exe, binary.FilePath); err != nil {
+		return err
+	}
+	fmt.Printf("Updated depman %s -> %s\n", version, release.Tag)
+	return nil
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/devnadeemashraf/depman/pkg/depman"
+)
+
+// maxOutputLines is how much captured installer output a failure shows
+const maxOutputLines = 20
+
+// resultGroup is an outcome dependencies are grouped by in reports, from
+// best to worst
+type resultGroup int
+
+const (
+	groupOK resultGroup = iota
+	groupUpdate
+	groupMissing
+	groupErrored
+)
+
+// resultGroups lists the groups in the order they are printed
+var resultGroups = []resultGroup{groupOK, groupUpdate, groupMissing, groupErrored}
+
+func (g resultGroup) String() string {
+	return [...]string{"ok", "needs update", "missing", "errored"}[g]
+}
+
+// title is the heading of the group's section
+func (g resultGroup) title() string {
+	return [...]string{"OK", "Needs update", "Missing", "Errored"}[g]
+}
+
+// color returns the ANSI color the group is shown in
+func (g resultGroup) color() string {
+	return [...]string{colorGreen, colorYellow, colorRed, colorRed}[g]
+}
+
+// checkGroup groups a dependency by the most severe problem a check found
+func checkGroup(status *depman.DependencyStatus) resultGroup {
+	switch status.Problem() {
+	case depman.ProblemNone:
+		return groupOK
+	case depman.ProblemMajor, depman.ProblemMinor, depman.ProblemPatch, depman.ProblemIncompatible:
+		return groupUpdate
+	case depman.ProblemMissing:
+		return groupMissing
+	default:
+		return groupErrored
+	}
+}
+
+// ensureGroup groups a dependency by what an install run left it at: any
+// error other than a skipped dependency's means the run failed for it
+func ensureGroup(status *depman.DependencyStatus) resultGroup {
+	switch {
+	case status.Skipped:
+		return groupMissing
+	case status.Error != nil && status.ProvidedBy == "":
+		return groupErrored
+	default:
+		return checkGroup(status)
+	}
+}
+
+// sortByGroup orders statuses from best to worst group, keeping the order
+// within a group
+func sortByGroup(statuses []*depman.DependencyStatus, group func(*depman.DependencyStatus) resultGroup) []*depman.DependencyStatus {
+	sorted := append([]*depman.DependencyStatus{}, statuses...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return group(sorted[i]) < group(sorted[j])
+	})
+	return sorted
+}
+
+// colorize wraps text in an ANSI color if color is set
+func colorize(text, code string, color bool) string {
+	if !color || code == "" {
+		return text
+	}
+	return "\033[" + code + "m" + text + "\033[0m"
+}
+
+// printSummary prints a line counting the dependencies in each group, e.g.
+// "5 dependencies: 3 ok, 1 needs update, 1 missing, 0 errored"
+func printSummary(w io.Writer, statuses []*depman.DependencyStatus, group func(*depman.DependencyStatus) resultGroup, color bool) {
+	counts := make(map[resultGroup]int)
+	for _, status := range statuses {
+		counts[group(status)]++
+	}
+	parts := make([]string, 0, len(resultGroups))
+	for _, g := range resultGroups {
+		part := fmt.Sprintf("%d %s", counts[g], g)
+		if counts[g] > 0 {
+			part = colorize(part, g.color(), color)
+		}
+		parts = append(parts, part)
+	}
+	fmt.Fprintf(w, "%d dependencies: %s\n", len(statuses), strings.Join(parts, ", "))
+}
+
+// printFailures prints a section per dependency in the errored group with its
+// error, the end of its captured installer output and what to try next
+func printFailures(w io.Writer, statuses []*depman.DependencyStatus, group func(*depman.DependencyStatus) resultGroup, installing, color bool) {
+	for _, status := range statuses {
+		if group(status) != groupErrored || status.Error == nil {
+			continue
+		}
+
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, colorize(fmt.Sprintf("== %s ==", status.Name), colorRed, color))
+
+		// The output and log are shown below, not inside the error
+		message := status.Error.Error()
+		if i := strings.Index(message, ", output: "); i >= 0 && status.InstallOutput != "" {
+			message = message[:i]
+		}
+		message = strings.TrimSuffix(message, fmt.Sprintf(" (log: %s)", status.InstallLog))
+		fmt.Fprintf(w, "Error: %s\n", message)
+		if status.InstallLog != "" {
+			fmt.Fprintf(w, "Full log: %s\n", status.InstallLog)
+		}
+
+		if output := strings.TrimSpace(status.InstallOutput); output != "" {
+			lines := strings.Split(output, "\n")
+			if len(lines) > maxOutputLines {
+				fmt.Fprintf(w, "Installer output (last %d of %d lines):\n", maxOutputLines, len(lines))
+				lines = lines[len(lines)-maxOutputLines:]
+			} else {
+				fmt.Fprintln(w, "Installer output:")
+			}
+			for _, line := range lines {
+				fmt.Fprintf(w, "  | %s\n", line)
+			}
+		}
+
+		fmt.Fprintln(w, "Next steps:")
+		for _, step := range nextSteps(status, installing) {
+			fmt.Fprintf(w, "  - %s\n", step)
+		}
+	}
+}
+
+// nextSteps suggests what to try after a dependency failed to install or to
+// be checked
+func nextSteps(status *depman.DependencyStatus, installing bool) []string {
+	var steps []string
+	var verification *depman.VerificationError
+	var policy *depman.PolicyError
+	var conflict *depman.ConflictError
+	var health *depman.HealthCheckError
+	switch err := status.Error; {
+	case errors.As(err, &verification):
+		steps = append(steps, fmt.Sprintf("The download's %s did not match: check its URL and checksum in the configuration, then clear the download cache with 'depman cache clean'", verification.Kind))
+	case errors.As(err, &policy):
+		steps = append(steps, fmt.Sprintf("The organization policy refuses it (%s): use an allowed install method or source, or ask for an exception", policy.Rule))
+	case errors.As(err, &conflict):
+		steps = append(steps, fmt.Sprintf("Uninstall %s, or drop one of the two from the configuration", conflict.Conflict))
+	case errors.As(err, &health):
+		steps = append(steps, fmt.Sprintf("It was installed but does not work: run its health check (%s) by hand and see 'depman doctor'", health.Check))
+	case errors.Is(err, os.ErrPermission):
+		steps = append(steps, "depman was not allowed to write somewhere: check --privilege, or install into the project with --scope project")
+	case errors.Is(err, context.DeadlineExceeded):
+		steps = append(steps, "It timed out: raise the dependency's timeout")
+	case !installing:
+		steps = append(steps, "Its version could not be read: run its verify command by hand, or set version_command and version_regex")
+	}
+	steps = append(steps, fmt.Sprintf("Run 'depman explain %s' to see how its install method, source and version were chosen", status.Name))
+	if status.InstallLog != "" {
+		steps = append(steps, fmt.Sprintf("Read every command the install ran, with its output, with 'depman logs %s --last'", status.Name))
+	} else if !verbose {
+		steps = append(steps, "Re-run with --verbose for the full log")
+	}
+	return steps
+}
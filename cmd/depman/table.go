@@ -0,0 +1,228 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// narrowCellWidth is the widest a cell is printed without -o wide
+const narrowCellWidth = 48
+
+// ANSI styles for colored output
+const (
+	colorBold   = "1"
+	colorRed    = "31"
+	colorGreen  = "32"
+	colorYellow = "33"
+	colorCyan   = "36"
+)
+
+// tableColumn is a column a table can show
+type tableColumn struct {
+	name string // Header, also how --columns, --sort and --filter refer to it
+	wide bool   // Only shown with -o wide, unless picked with --columns
+}
+
+// table renders rows of named cells, honoring --columns, --sort, --filter
+// and -o wide
+type table struct {
+	columns []tableColumn
+	rows    []map[string]string               // Cells keyed by column name
+	color   func(column, value string) string // ANSI color of a cell, or "" for none
+}
+
+// newTable returns an empty table with the given columns
+func newTable(columns ...tableColumn) *table {
+	return &table{columns: columns}
+}
+
+// add appends a row of cells, one per column in order
+func (t *table) add(cells ...string) {
+	row := make(map[string]string, len(t.columns))
+	for i, column := range t.columns {
+		if i < len(cells) {
+			row[column.name] = cells[i]
+		}
+	}
+	t.rows = append(t.rows, row)
+}
+
+// wideOutput reports whether -o wide was given
+func wideOutput() bool {
+	return strings.ToLower(outputFormat) == "wide"
+}
+
+// colorOutput reports whether output to f may be colored: it is a terminal,
+// --no-color is not set and $NO_COLOR is empty
+func colorOutput(f *os.File) bool {
+	return !noColor && os.Getenv("NO_COLOR") == "" && isTerminal(f)
+}
+
+// column returns the column named name, case-insensitively
+func (t *table) column(name string) (tableColumn, error) {
+	for _, column := range t.columns {
+		if strings.EqualFold(column.name, strings.TrimSpace(name)) {
+			return column, nil
+		}
+	}
+	names := make([]string, len(t.columns))
+	for i, column := range t.columns {
+		names[i] = strings.ToLower(column.name)
+	}
+	return tableColumn{}, fmt.Errorf("unknown column '%s' (columns: %s)", name, strings.Join(names, ", "))
+}
+
+// render writes the table with the columns, order and filters of the flags
+func (t *table) render(w io.Writer) error {
+	// Pick the columns to show
+	var shown []tableColumn
+	if len(tableColumns) > 0 {
+		for _, name := range tableColumns {
+			column, err := t.column(name)
+			if err != nil {
+				return err
+			}
+			shown = append(shown, column)
+		}
+	} else {
+		for _, column := range t.columns {
+			if !column.wide || wideOutput() {
+				shown = append(shown, column)
+			}
+		}
+	}
+
+	rows, err := t.filter(t.rows)
+	if err != nil {
+		return err
+	}
+	if err := t.sort(rows); err != nil {
+		return err
+	}
+
+	// Size the columns on the text without colors
+	cells := make([][]string, len(rows))
+	widths := make([]int, len(shown))
+	for i, column := range shown {
+		widths[i] = utf8.RuneCountInString(column.name)
+	}
+	for r, row := range rows {
+		cells[r] = make([]string, len(shown))
+		for i, column := range shown {
+			value := row[column.name]
+			if value == "" {
+				value = "-"
+			}
+			if !wideOutput() && utf8.RuneCountInString(value) > narrowCellWidth {
+				value = string([]rune(value)[:narrowCellWidth-3]) + "..."
+			}
+			cells[r][i] = value
+			if n := utf8.RuneCountInString(value); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+
+	color := w == os.Stdout && t.color != nil && colorOutput(os.Stdout)
+	writeLine := func(values []string, colors []string) {
+		var b strings.Builder
+		for i, value := range values {
+			padding := ""
+			if i < len(values)-1 {
+				padding = strings.Repeat(" ", widths[i]-utf8.RuneCountInString(value)+2)
+			}
+			if colors != nil {
+				value = colorize(value, colors[i], true)
+			}
+			b.WriteString(value + padding)
+		}
+		fmt.Fprintln(w, strings.TrimRight(b.String(), " "))
+	}
+
+	headers := make([]string, len(shown))
+	for i, column := range shown {
+		headers[i] = column.name
+	}
+	writeLine(headers, nil)
+	for r, row := range rows {
+		var colors []string
+		if color {
+			colors = make([]string, len(shown))
+			for i, column := range shown {
+				colors[i] = t.color(column.name, row[column.name])
+			}
+		}
+		writeLine(cells[r], colors)
+	}
+	return nil
+}
+
+// filter returns the rows matching every --filter: column=value or
+// column!=value, where value may list alternatives separated by commas
+func (t *table) filter(rows []map[string]string) ([]map[string]string, error) {
+	for _, expr := range tableFilters {
+		name, value, negate := "", "", false
+		if i := strings.Index(expr, "!="); i >= 0 {
+			name, value, negate = expr[:i], expr[i+2:], true
+		} else if i := strings.Index(expr, "="); i >= 0 {
+			name, value = expr[:i], expr[i+1:]
+		} else {
+			return nil, fmt.Errorf("invalid filter '%s' (expected column=value or column!=value)", expr)
+		}
+		column, err := t.column(name)
+		if err != nil {
+			return nil, err
+		}
+
+		var kept []map[string]string
+		for _, row := range rows {
+			matched := false
+			for _, alternative := range strings.Split(value, ",") {
+				matched = matched || strings.EqualFold(row[column.name], strings.TrimSpace(alternative))
+			}
+			if matched != negate {
+				kept = append(kept, row)
+			}
+		}
+		rows = kept
+	}
+	return rows, nil
+}
+
+// sort orders the rows by the --sort column, descending if it starts with
+// "-"; versions compare as versions and everything else as text
+func (t *table) sort(rows []map[string]string) error {
+	if tableSort == "" {
+		return nil
+	}
+	name, descending := strings.TrimPrefix(tableSort, "-"), strings.HasPrefix(tableSort, "-")
+	column, err := t.column(name)
+	if err != nil {
+		return err
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i][column.name], rows[j][column.name]
+		if descending {
+			a, b = b, a
+		}
+		return compareCells(a, b) < 0
+	})
+	return nil
+}
+
+// compareCells compares two cells as versions if both are, else as text
+func compareCells(a, b string) int {
+	va, errA := semver.NewVersion(a)
+	vb, errB := semver.NewVersion(b)
+	if errA == nil && errB == nil {
+		return va.Compare(vb)
+	}
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
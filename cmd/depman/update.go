@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/devnadeemashraf/depman/pkg/depman"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Update flags
+	updateDryRun bool
+	updateLevel  string
+	updateWrite  bool
+
+	// Update command
+	updateCmd = &cobra.Command{
+		Use:   "update [name...]",
+		Short: "Upgrade dependencies to the newest versions their constraints allow",
+		Long: `Update looks up the versions available for each dependency, shows the
+upgrades grouped by patch, minor and major, and installs those up to --level.
+Name dependencies to update only those.
+
+Without --write the upgrades are installed as one-off versions; with --write
+the pinned versions in the configuration file and the lockfile are updated.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runUpdate(cmd.Context(), args)
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(updateCmd)
+	updateCmd.Flags().BoolVar(&updateDryRun, "dry-run", false, "Show available upgrades without installing anything")
+	updateCmd.Flags().StringVar(&updateLevel, "level", "minor", "Largest upgrade to apply (patch, minor, major)")
+	updateCmd.Flags().BoolVar(&updateWrite, "write", false, "Rewrite pinned versions in the configuration file and lockfile")
+}
+
+// parseUpdateLevel parses the --level flag
+func parseUpdateLevel(level string) (depman.UpdateType, error) {
+	switch strings.ToLower(level) {
+	case "patch":
+		return depman.PatchUpdate, nil
+	case "minor":
+		return depman.MinorUpdate, nil
+	case "major":
+		return depman.MajorUpdate, nil
+	default:
+		return depman.NoUpdate, fmt.Errorf("invalid update level '%s' (expected patch, minor or major)", level)
+	}
+}
+
+// runUpdate plans upgrades and applies the selected ones
+func runUpdate(ctx context.Context, names []string) error {
+	level, err := parseUpdateLevel(updateLevel)
+	if err != nil {
+		return err
+	}
+
+	manager, err := createManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize: %w", err)
+	}
+	for _, name := range names {
+		if manager.FindDependency(name) == nil {
+			return fmt.Errorf("dependency '%s' is not defined in the configuration", name)
+		}
+	}
+
+	plan, err := manager.PlanUpdates(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to plan updates: %w", err)
+	}
+
+	// Select the upgrades to apply
+	var selected []*depman.Upgrade
+	for _, upgrade := range plan.Upgrades {
+		if upgrade.Type <= level && (len(names) == 0 || contains(names, upgrade.Name)) {
+			selected = append(selected, upgrade)
+		}
+	}
+
+	if updateDryRun {
+		if jsonOutput() {
+			return printJSON(plan)
+		}
+		printUpdatePlan(plan, selected)
+		fmt.Printf("\n%d of %d upgrades would be applied. No changes were made.\n", len(selected), len(plan.Upgrades))
+		return nil
+	}
+
+	if !jsonOutput() {
+		printUpdatePlan(plan, selected)
+		fmt.Println()
+	}
+	if len(selected) == 0 {
+		if !jsonOutput() {
+			fmt.Println("Nothing to update.")
+		}
+		return nil
+	}
+
+	// Show progress only while installing, after the plan is printed
+	progress, stopProgress := progressOptions()
+	manager, err = createManager(progress...)
+	if err != nil {
+		stopProgress()
+		return fmt.Errorf("failed to initialize: %w", err)
+	}
+
+	statuses, err := manager.ApplyUpdates(ctx, selected, updateWrite)
+	stopProgress()
+	if jsonOutput() && statuses != nil {
+		if jsonErr := printJSON(orderedStatuses(manager, statuses)); jsonErr != nil {
+			return jsonErr
+		}
+	}
+	if !jsonOutput() && statuses != nil {
+		ordered := orderedStatuses(manager, statuses)
+		printInstallResults(ordered, ordered)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update dependencies: %w", err)
+	}
+
+	return nil
+}
+
+// printUpdatePlan prints the available upgrades grouped by type, marking the
+// ones that were not selected
+func printUpdatePlan(plan *depman.UpdatePlan, selected []*depman.Upgrade) {
+	fmt.Println("Available Updates:")
+	fmt.Println("==================")
+
+	chosen := make(map[string]bool, len(selected))
+	for _, upgrade := range selected {
+		chosen[upgrade.Name] = true
+	}
+
+	for _, updateType := range []depman.UpdateType{depman.MajorUpdate, depman.MinorUpdate, depman.PatchUpdate} {
+		group := plan.Group(updateType)
+		if len(group) == 0 {
+			continue
+		}
+		fmt.Printf("%s:\n", updateType)
+		for _, upgrade := range group {
+			fmt.Printf("- %s: v%s -> v%s", upgrade.Name, upgrade.CurrentVersion, upgrade.LatestVersion)
+			if !chosen[upgrade.Name] {
+				fmt.Printf(" (skipped)")
+			}
+			fmt.Println()
+		}
+	}
+
+	if len(plan.Upgrades) == 0 {
+		fmt.Println("Every dependency is at the newest allowed version.")
+	}
+
+	if len(plan.Unknown) > 0 {
+		unknown := make([]string, 0, len(plan.Unknown))
+		for name := range plan.Unknown {
+			unknown = append(unknown, name)
+		}
+		sort.Strings(unknown)
+
+		fmt.Println("Not checked:")
+		for _, name := range unknown {
+			fmt.Printf("- %s: %s\n", name, plan.Unknown[name])
+		}
+	}
+}
+
+// contains reports whether a list of names includes name
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
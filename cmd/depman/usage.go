@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"text/tabwriter"
+	"time"
+
+	"github.com/devnadeemashraf/depman/pkg/depman"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Report flags
+	reportSince string
+	reportTop   int
+
+	// Report command
+	reportCmd = &cobra.Command{
+		Use:   "report",
+		Short: "Sum up the local run history into a report to share",
+		Long: `Report sums up the check and ensure runs in the local history (see
+'depman history'): the dependencies that fail most often, the slowest
+installs and how often each dependency drifts from the configuration.
+
+The report holds dependency names, counts and durations only: no paths, host
+names, errors or installer output. depman never sends it anywhere; print it
+with --output json and pass it on yourself, e.g. to the team maintaining your
+configuration.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runReport()
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+	reportCmd.Flags().StringVar(&reportSince, "since", "30d", "Cover the runs started this long ago or later (e.g. 12h, 30d)")
+	reportCmd.Flags().IntVar(&reportTop, "top", 5, "List at most this many dependencies in each section (0 for all)")
+}
+
+// runReport prints a usage report of the runs within --since
+func runReport() error {
+	age, err := parseAge(reportSince)
+	if err != nil {
+		return err
+	}
+	runs, err := depman.LoadHistory(depman.DefaultStatePath())
+	if err != nil {
+		return err
+	}
+
+	report := depman.NewUsageReport(runs, time.Now().Add(-age))
+	report.Platform = runtime.GOOS + "/" + runtime.GOARCH
+	report.DepmanVersion = version
+	if jsonOutput() {
+		return printJSON(report)
+	}
+
+	fmt.Printf("depman usage report, %s to %s\n", report.Since.Local().Format("2006-01-02"), report.GeneratedAt.Local().Format("2006-01-02"))
+	fmt.Printf("Platform: %s, depman %s\n", report.Platform, report.DepmanVersion)
+	fmt.Printf("Runs: %d (%d checks, %d ensures), %d failed, %d configurations\n",
+		report.Runs, report.Checks, report.Ensures, report.FailedRuns, report.Configs)
+	if report.Runs == 0 {
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\nMost failing dependencies:")
+	if failing := report.MostFailing(reportTop); len(failing) > 0 {
+		fmt.Fprintln(w, "  NAME\tFAILURES\tRUNS")
+		for _, u := range failing {
+			fmt.Fprintf(w, "  %s\t%d\t%d\n", u.Name, u.Failures, u.Runs)
+		}
+	} else {
+		fmt.Fprintln(w, "  none")
+	}
+
+	fmt.Fprintln(w, "\nSlowest installs:")
+	if slowest := report.SlowestInstalls(reportTop); len(slowest) > 0 {
+		fmt.Fprintln(w, "  NAME\tSLOWEST\tAVERAGE\tINSTALLS")
+		for _, u := range slowest {
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%d\n", u.Name, u.SlowestInstall.Round(time.Millisecond), u.AverageInstall.Round(time.Millisecond), u.Installs)
+		}
+	} else {
+		fmt.Fprintln(w, "  none")
+	}
+
+	fmt.Fprintln(w, "\nDrift (missing or out of date when checked):")
+	if drifting := report.MostDrifting(reportTop); len(drifting) > 0 {
+		fmt.Fprintln(w, "  NAME\tDRIFTED\tRUNS\tRATE")
+		for _, u := range drifting {
+			fmt.Fprintf(w, "  %s\t%d\t%d\t%.0f%%\n", u.Name, u.Drifted, u.Runs, u.DriftRate()*100)
+		}
+	} else {
+		fmt.Fprintln(w, "  none")
+	}
+	return w.Flush()
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Use command
+var useCmd = &cobra.Command{
+	Use:   "use <name>[@<version>]",
+	Short: "Switch the active version of a tool installed side by side, or list its versions",
+	Long: `Switch the active version of a tool installed side by side, or list its versions.
+
+Install another version first with 'depman install <name>@<version>'.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runUse(args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(useCmd)
+}
+
+// runUse points the shim of a tool at an installed version
+func runUse(spec string) error {
+	manager, err := createManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize: %w", err)
+	}
+
+	name, version, ok := strings.Cut(spec, "@")
+	if !ok || version == "" {
+		return printInstalledVersions(manager.InstalledVersions(name))
+	}
+
+	if err := manager.UseVersion(name, version); err != nil {
+		return err
+	}
+
+	fmt.Printf("Now using %s %s\n", name, strings.TrimPrefix(version, "v"))
+	return nil
+}
+
+// printInstalledVersions lists installed versions, marking the active one
+func printInstalledVersions(versions []string, active string, err error) error {
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput() {
+		return printJSON(struct {
+			Versions []string `json:"versions"`
+			Active   string   `json:"active,omitempty"`
+		}{Versions: versions, Active: active})
+	}
+
+	if len(versions) == 0 {
+		fmt.Println("No versions installed")
+		return nil
+	}
+	for _, version := range versions {
+		marker := " "
+		if version == active {
+			marker = "*"
+		}
+		fmt.Printf("%s %s\n", marker, version)
+	}
+	return nil
+}
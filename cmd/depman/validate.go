@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/devnadeemashraf/depman/pkg/depman"
+	"github.com/spf13/cobra"
+)
+
+// Validate command
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the dependency configuration for errors without installing anything",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runValidate(cmd.Context())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+}
+
+// runValidate checks the configuration file against the schema
+func runValidate(ctx context.Context) error {
+	path, err := configFile(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = depman.ValidateConfigFile(path)
+
+	// A valid configuration also reports what it needs from depman
+	var compatibility *depman.Compatibility
+	if err == nil {
+		if config, loadErr := depman.LoadDependencyConfig(path); loadErr == nil {
+			compatibility = depman.CheckCompatibility(config)
+		}
+	}
+
+	var validationErr *depman.ValidationError
+	if jsonOutput() {
+		result := struct {
+			File          string               `json:"file"`
+			Valid         bool                 `json:"valid"`
+			Issues        []string             `json:"issues,omitempty"`
+			Compatibility *compatibilityResult `json:"compatibility,omitempty"`
+		}{File: path, Valid: err == nil}
+		if compatibility != nil {
+			result.Compatibility = &compatibilityResult{
+				RequiresDepman: compatibility.RequiresDepman,
+				Version:        compatibility.Version,
+				Declared:       compatibility.Declared,
+				Used:           compatibility.Used,
+				Undeclared:     compatibility.Undeclared,
+			}
+		}
+		if errors.As(err, &validationErr) {
+			for _, issue := range validationErr.Issues {
+				result.Issues = append(result.Issues, issue.String())
+			}
+		} else if err != nil {
+			result.Issues = []string{err.Error()}
+		}
+		if jsonErr := printJSON(result); jsonErr != nil {
+			return jsonErr
+		}
+		if err != nil {
+			return fmt.Errorf("configuration is invalid")
+		}
+		return nil
+	}
+
+	if errors.As(err, &validationErr) {
+		for _, issue := range validationErr.Issues {
+			fmt.Println(issue.String())
+		}
+		return fmt.Errorf("%d problem(s) found in %s", len(validationErr.Issues), path)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s is valid\n", path)
+	if compatibility != nil {
+		printCompatibility(compatibility)
+	}
+	return nil
+}
+
+// compatibilityResult is the JSON form of a compatibility report
+type compatibilityResult struct {
+	RequiresDepman string   `json:"requires_depman,omitempty"`
+	Version        string   `json:"version"`
+	Declared       []string `json:"declared_features,omitempty"`
+	Used           []string `json:"used_features,omitempty"`
+	Undeclared     []string `json:"undeclared_features,omitempty"`
+}
+
+// printCompatibility prints which depman versions can load the configuration
+func printCompatibility(compatibility *depman.Compatibility) {
+	if compatibility.RequiresDepman != "" {
+		fmt.Printf("Requires depman %s (this is %s)\n", compatibility.RequiresDepman, compatibility.Version)
+	} else {
+		fmt.Println("Requires no particular depman version (set requires_depman to fail fast on older ones)")
+	}
+	if len(compatibility.Used) > 0 {
+		fmt.Printf("Uses features: %s\n", strings.Join(compatibility.Used, ", "))
+	}
+	for _, feature := range compatibility.Undeclared {
+		fmt.Printf("Warning: uses %s without listing it under features; depman versions without it fail with unclear errors\n", feature)
+	}
+}
+
+// configFile returns the local path of the configuration, fetching a remote
+// one into the cache first
+func configFile(ctx context.Context) (string, error) {
+	if !depman.IsRemoteConfig(configPath) {
+		return depman.FindDependencyFile(configPath)
+	}
+
+	options, err := managerOptions()
+	if err != nil {
+		return "", err
+	}
+	return depman.FetchRemoteConfig(ctx, configPath, options...)
+}
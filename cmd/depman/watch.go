@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/devnadeemashraf/depman/pkg/depman"
+)
+
+// clearScreen moves the cursor home and clears the terminal
+const clearScreen = "\033[H\033[2J"
+
+// runCheckWatch re-runs the check whenever the configuration files (and, with
+// --watch-path, the PATH and bin directories) change, until interrupted
+func runCheckWatch(ctx context.Context) error {
+	if depman.IsRemoteConfig(configPath) {
+		return fmt.Errorf("--watch cannot watch a remote configuration")
+	}
+	if watchEvery <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+
+	path, err := depman.FindDependencyFile(configPath)
+	if err != nil {
+		return err
+	}
+
+	// Progress logs would scroll the table away
+	if !rootCmd.PersistentFlags().Changed("log-level") && !verbose {
+		logLevel = "warn"
+	}
+
+	for {
+		manager, statuses, audits, err := checkDependencies(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		watched := watchedPaths(path, manager)
+		renderWatch(manager, statuses, audits, err)
+
+		if !waitForChange(ctx, watched) {
+			return nil
+		}
+	}
+}
+
+// watchedPaths returns the files and directories whose changes trigger a new check
+func watchedPaths(path string, manager *depman.Manager) []string {
+	paths := depman.ConfigFiles(path)
+	if !watchPath {
+		return paths
+	}
+
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir != "" {
+			paths = append(paths, dir)
+		}
+	}
+	if manager != nil {
+		paths = append(paths, manager.BinDir())
+	}
+	return paths
+}
+
+// snapshot fingerprints the modification time and size of each path
+func snapshot(paths []string) string {
+	entries := make([]string, 0, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			entries = append(entries, path+":missing")
+			continue
+		}
+		entries = append(entries, fmt.Sprintf("%s:%d:%d", path, info.ModTime().UnixNano(), info.Size()))
+	}
+	sort.Strings(entries)
+	return strings.Join(entries, "\n")
+}
+
+// waitForChange polls the paths until one changes, reporting false if ctx is done first
+func waitForChange(ctx context.Context, paths []string) bool {
+	before := snapshot(paths)
+
+	ticker := time.NewTicker(watchEvery)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+			if snapshot(paths) != before {
+				return true
+			}
+		}
+	}
+}
+
+// renderWatch prints the result of one check, replacing the previous one on a terminal
+func renderWatch(manager *depman.Manager, statuses map[string]*depman.DependencyStatus, audits map[string]*depman.AuditResult, err error) {
+	if jsonOutput() {
+		// One report per line for tools following the stream
+		if err == nil {
+			if data, jsonErr := json.Marshal(manager.NewCheckReport(statuses)); jsonErr == nil {
+				fmt.Println(string(data))
+			}
+		} else {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+		return
+	}
+
+	if isTerminal(os.Stdout) {
+		fmt.Print(clearScreen)
+	}
+	fmt.Printf("Last checked %s; watching for changes (Ctrl+C to stop)\n\n", time.Now().Format("15:04:05"))
+
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	if err := printCheckResults(manager, orderedStatuses(manager, statuses), audits); err != nil {
+		fmt.Printf("Error: %v\n", err)
+	}
+}
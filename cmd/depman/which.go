@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// Which command
+var whichCmd = &cobra.Command{
+	Use:   "which <dependency>",
+	Short: "Show the executable currently satisfying a dependency",
+	Long: `Which checks a dependency the way 'depman check' does and prints the
+executable that satisfied it: its absolute path (and where a symlink points),
+its version, the configured install method, and what put it there: depman,
+the system (e.g. apt or dnf), Homebrew, or unknown. If another copy earlier
+on PATH is what shells run instead, it is shown too.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runWhich(cmd.Context(), args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(whichCmd)
+}
+
+// runWhich prints the executable satisfying a dependency
+func runWhich(ctx context.Context, name string) error {
+	manager, err := createManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize: %w", err)
+	}
+
+	r, err := manager.Which(ctx, name)
+	if err != nil {
+		return err
+	}
+	if jsonOutput() {
+		return printJSON(r)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Dependency:\t%s\n", r.Dependency)
+	switch {
+	case r.Path != "":
+		fmt.Fprintf(w, "Path:\t%s\n", r.Path)
+		if r.RealPath != "" {
+			fmt.Fprintf(w, "Links to:\t%s\n", r.RealPath)
+		}
+	case r.Binary != "":
+		fmt.Fprintf(w, "Path:\tnone (%s was not found in the bin directories or on PATH)\n", r.Binary)
+	default:
+		fmt.Fprintf(w, "Path:\t- (no verify command names an executable; the install method reports the version)\n")
+	}
+	fmt.Fprintf(w, "Version:\t%s\n", orNone(r.Version))
+	fmt.Fprintf(w, "Install method:\t%s\n", r.Method)
+	fmt.Fprintf(w, "Managed by:\t%s\n", r.Manager)
+	if r.ShadowedBy != nil {
+		version := r.ShadowedBy.Version
+		if version == "" {
+			version = "unknown version"
+		}
+		fmt.Fprintf(w, "Shadowed by:\t%s (%s), which shells run instead; see 'depman check --path-audit'\n", r.ShadowedBy.Path, version)
+	}
+	if r.Error != "" {
+		fmt.Fprintf(w, "Error:\t%s\n", r.Error)
+	}
+	return w.Flush()
+}
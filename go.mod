@@ -3,6 +3,7 @@ module github.com/devnadeemashraf/depman
 go 1.24.3
 
 require (
+	github.com/BurntSushi/toml v1.6.0
 	github.com/Masterminds/semver/v3 v3.3.1
 	github.com/spf13/cobra v1.9.1
 	gopkg.in/yaml.v3 v3.0.1
@@ -0,0 +1,251 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Format identifies a supported archive format
+type Format string
+
+// Supported archive formats
+const (
+	FormatNone   Format = ""        // Not an archive (a plain file)
+	FormatTar    Format = "tar"     // Uncompressed tarball
+	FormatTarGz  Format = "tar.gz"  // Gzip-compressed tarball
+	FormatTarBz2 Format = "tar.bz2" // Bzip2-compressed tarball
+	FormatZip    Format = "zip"     // Zip archive
+)
+
+// DetectFormat determines the archive format from a file name
+func DetectFormat(name string) Format {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return FormatTarGz
+	case strings.HasSuffix(lower, ".tar.bz2"), strings.HasSuffix(lower, ".tbz2"):
+		return FormatTarBz2
+	case strings.HasSuffix(lower, ".tar"):
+		return FormatTar
+	case strings.HasSuffix(lower, ".zip"):
+		return FormatZip
+	default:
+		return FormatNone
+	}
+}
+
+// Extract unpacks an archive into destDir, rejecting entries that would escape it
+func Extract(archivePath, destDir string) error {
+	return ExtractAs(archivePath, destDir, DetectFormat(archivePath))
+}
+
+// ExtractAs unpacks an archive of the given format regardless of its file name
+func ExtractAs(archivePath, destDir string, format Format) error {
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return fmt.Errorf("failed to create extraction directory: %w", err)
+	}
+
+	switch format {
+	case FormatZip:
+		return extractZip(archivePath, destDir)
+	case FormatTar, FormatTarGz, FormatTarBz2:
+		return extractTar(archivePath, destDir, format)
+	default:
+		return fmt.Errorf("unsupported archive format: %s", filepath.Base(archivePath))
+	}
+}
+
+// CreateTarGz packs the files below srcDir into a gzip-compressed tarball,
+// naming entries relative to srcDir
+func CreateTarGz(archivePath, srcDir string) error {
+	file, err := os.Create(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+	defer file.Close()
+
+	gz := gzip.NewWriter(file)
+	tw := tar.NewWriter(gz)
+
+	err = filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || path == srcDir {
+			return err
+		}
+		name, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			return err
+		}
+
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(name)
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		in, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		_, err = io.Copy(tw, in)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	return file.Close()
+}
+
+// FindFile searches an extracted tree for a file with the given base name
+func FindFile(root, name string) (string, error) {
+	var found string
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && info.Name() == name {
+			found = path
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to search extracted files: %w", err)
+	}
+	if found == "" {
+		return "", fmt.Errorf("file %s not found in archive", name)
+	}
+	return found, nil
+}
+
+// safeJoin joins an archive entry name onto destDir, refusing paths outside of it
+func safeJoin(destDir, name string) (string, error) {
+	target := filepath.Join(destDir, name)
+	if target != filepath.Clean(destDir) && !strings.HasPrefix(target, filepath.Clean(destDir)+string(os.PathSeparator)) {
+		return "", fmt.Errorf("archive entry %s escapes the extraction directory", name)
+	}
+	return target, nil
+}
+
+// extractTar unpacks a possibly compressed tarball
+func extractTar(archivePath, destDir string, format Format) error {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer file.Close()
+
+	var reader io.Reader = file
+	switch format {
+	case FormatTarGz:
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return fmt.Errorf("failed to read gzip stream: %w", err)
+		}
+		defer gz.Close()
+		reader = gz
+	case FormatTarBz2:
+		reader = bzip2.NewReader(file)
+	}
+
+	tr := tar.NewReader(reader)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read archive: %w", err)
+		}
+
+		target, err := safeJoin(destDir, header.Name)
+		if err != nil {
+			return err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return fmt.Errorf("failed to create directory: %w", err)
+			}
+		case tar.TypeReg:
+			if err := writeFile(target, tr, os.FileMode(header.Mode)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// extractZip unpacks a zip archive
+func extractZip(archivePath, destDir string) error {
+	zr, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer zr.Close()
+
+	for _, entry := range zr.File {
+		target, err := safeJoin(destDir, entry.Name)
+		if err != nil {
+			return err
+		}
+
+		if entry.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return fmt.Errorf("failed to create directory: %w", err)
+			}
+			continue
+		}
+
+		rc, err := entry.Open()
+		if err != nil {
+			return fmt.Errorf("failed to read archive entry %s: %w", entry.Name, err)
+		}
+		err = writeFile(target, rc, entry.Mode())
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeFile writes an archive entry to disk, creating parent directories as needed
+func writeFile(target string, r io.Reader, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode.Perm()|0600)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", target, err)
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, r); err != nil {
+		return fmt.Errorf("failed to write %s: %w", target, err)
+	}
+
+	return nil
+}
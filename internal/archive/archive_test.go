@@ -0,0 +1,74 @@
+package archive
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectFormat(t *testing.T) {
+	testCases := map[string]Format{
+		"tool.tar.gz":  FormatTarGz,
+		"tool.TGZ":     FormatTarGz,
+		"tool.tar.bz2": FormatTarBz2,
+		"tool.tar":     FormatTar,
+		"tool.zip":     FormatZip,
+		"tool":         FormatNone,
+		"tool.exe":     FormatNone,
+	}
+
+	for name, expected := range testCases {
+		if got := DetectFormat(name); got != expected {
+			t.Errorf("DetectFormat(%q) = %q, expected %q", name, got, expected)
+		}
+	}
+}
+
+func TestExtractZip(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "archive-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	writeZip := func(name string, entries map[string]string) string {
+		path := filepath.Join(tempDir, name)
+		file, err := os.Create(path)
+		if err != nil {
+			t.Fatalf("Failed to create zip: %v", err)
+		}
+		zw := zip.NewWriter(file)
+		for entry, content := range entries {
+			w, _ := zw.Create(entry)
+			w.Write([]byte(content))
+		}
+		zw.Close()
+		file.Close()
+		return path
+	}
+
+	t.Run("Extract and find file", func(t *testing.T) {
+		path := writeZip("ok.zip", map[string]string{"dist/bin/tool": "binary"})
+		dest := filepath.Join(tempDir, "ok")
+
+		if err := Extract(path, dest); err != nil {
+			t.Fatalf("Did not expect an error but got: %v", err)
+		}
+
+		found, err := FindFile(dest, "tool")
+		if err != nil {
+			t.Fatalf("Did not expect an error but got: %v", err)
+		}
+		if found != filepath.Join(dest, "dist", "bin", "tool") {
+			t.Errorf("Unexpected path %s", found)
+		}
+	})
+
+	t.Run("Reject path traversal", func(t *testing.T) {
+		path := writeZip("evil.zip", map[string]string{"../../evil": "payload"})
+		if err := Extract(path, filepath.Join(tempDir, "evil")); err == nil {
+			t.Errorf("Expected an error but got none")
+		}
+	})
+}
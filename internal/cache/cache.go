@@ -0,0 +1,262 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/devnadeemashraf/depman/internal/verify"
+)
+
+// metadataFile holds an entry's metadata next to the cached artifact
+const metadataFile = "entry.json"
+
+// validatorsDir holds the validators recorded for artifact URLs
+const validatorsDir = "validators"
+
+// Cache is a content-addressed store of downloaded artifacts. Entries are
+// keyed by the artifact URL and its checksum, so a URL whose content changes
+// under a new checksum gets a new entry.
+type Cache struct {
+	Dir string // Root directory of the cache
+}
+
+// Entry describes a cached artifact
+type Entry struct {
+	Key      string    `json:"key"`       // Content address of the entry
+	URL      string    `json:"url"`       // URL the artifact was downloaded from
+	Checksum string    `json:"checksum"`  // Checksum of the artifact ("algorithm:hash")
+	Filename string    `json:"filename"`  // File name of the artifact
+	Size     int64     `json:"size"`      // Size of the artifact in bytes
+	LastUsed time.Time `json:"last_used"` // When the entry was last stored or read
+}
+
+// Validators identify the content a server last sent for a URL whose
+// checksum is not known in advance, such as a "latest" download, so it can
+// be revalidated with a conditional request instead of downloaded again
+type Validators struct {
+	Checksum     string `json:"checksum"`                // Checksum of the artifact stored for the URL
+	ETag         string `json:"etag,omitempty"`          // ETag header of the response
+	LastModified string `json:"last_modified,omitempty"` // Last-Modified header of the response
+}
+
+// New returns a cache rooted at dir
+func New(dir string) *Cache {
+	return &Cache{Dir: dir}
+}
+
+// Key returns the content address for an artifact URL and checksum
+func Key(url, checksum string) string {
+	if algorithm, digest, err := verify.ParseChecksum(checksum); err == nil {
+		checksum = algorithm + ":" + digest
+	}
+	sum := sha256.Sum256([]byte(url + "\n" + checksum))
+	return hex.EncodeToString(sum[:])
+}
+
+// Lookup copies the cached artifact for url and checksum into destDir and
+// returns its path. It reports false when there is no entry or the cached
+// file no longer matches its checksum, in which case the entry is dropped.
+func (c *Cache) Lookup(url, checksum, destDir string) (string, bool) {
+	if checksum == "" {
+		return "", false
+	}
+
+	dir := filepath.Join(c.Dir, Key(url, checksum))
+	entry, err := readEntry(dir)
+	if err != nil {
+		return "", false
+	}
+
+	cached := filepath.Join(dir, entry.Filename)
+	if err := verify.Checksum(cached, checksum); err != nil {
+		os.RemoveAll(dir)
+		return "", false
+	}
+
+	target := filepath.Join(destDir, entry.Filename)
+	if err := copyFile(cached, target); err != nil {
+		return "", false
+	}
+
+	// Touch the entry so pruning goes by last use
+	now := time.Now()
+	os.Chtimes(filepath.Join(dir, metadataFile), now, now)
+	return target, true
+}
+
+// Store adds a downloaded artifact to the cache under url and checksum
+func (c *Cache) Store(url, checksum, path string) error {
+	dir := filepath.Join(c.Dir, Key(url, checksum))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create cache entry: %w", err)
+	}
+
+	filename := filepath.Base(path)
+	if err := copyFile(path, filepath.Join(dir, filename)); err != nil {
+		os.RemoveAll(dir)
+		return fmt.Errorf("failed to store artifact in cache: %w", err)
+	}
+
+	data, err := json.MarshalIndent(Entry{URL: url, Checksum: checksum, Filename: filename}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, metadataFile), data, 0644); err != nil {
+		os.RemoveAll(dir)
+		return fmt.Errorf("failed to store artifact in cache: %w", err)
+	}
+	return nil
+}
+
+// SetValidators records the validators of the artifact last downloaded from
+// url, which must be stored under url and v.Checksum
+func (c *Cache) SetValidators(url string, v Validators) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := c.validatorsPath(url)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to record validators: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to record validators: %w", err)
+	}
+	return nil
+}
+
+// Validators returns the validators recorded for url. It reports false when
+// none were recorded or the artifact they describe is no longer cached.
+func (c *Cache) Validators(url string) (Validators, bool) {
+	var v Validators
+	data, err := os.ReadFile(c.validatorsPath(url))
+	if err != nil {
+		return v, false
+	}
+	if err := json.Unmarshal(data, &v); err != nil || (v.ETag == "" && v.LastModified == "") {
+		return v, false
+	}
+	if _, err := readEntry(filepath.Join(c.Dir, Key(url, v.Checksum))); err != nil {
+		return v, false
+	}
+	return v, true
+}
+
+// validatorsPath returns where the validators for url are recorded
+func (c *Cache) validatorsPath(url string) string {
+	return filepath.Join(c.Dir, validatorsDir, Key(url, "")+".json")
+}
+
+// List returns the cached entries, most recently used first
+func (c *Cache) List() ([]Entry, error) {
+	dirs, err := os.ReadDir(c.Dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache: %w", err)
+	}
+
+	var entries []Entry
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+		entry, err := readEntry(filepath.Join(c.Dir, d.Name()))
+		if err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].LastUsed.After(entries[j].LastUsed)
+	})
+	return entries, nil
+}
+
+// Remove deletes a single entry
+func (c *Cache) Remove(key string) error {
+	return os.RemoveAll(filepath.Join(c.Dir, key))
+}
+
+// Clean deletes every cached artifact
+func (c *Cache) Clean() error {
+	if err := os.RemoveAll(c.Dir); err != nil {
+		return fmt.Errorf("failed to clean cache: %w", err)
+	}
+	return nil
+}
+
+// Prune deletes entries not used within maxAge and returns them
+func (c *Cache) Prune(maxAge time.Duration) ([]Entry, error) {
+	entries, err := c.List()
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := time.Now().Add(-maxAge)
+	var removed []Entry
+	for _, entry := range entries {
+		if entry.LastUsed.After(cutoff) {
+			continue
+		}
+		if err := c.Remove(entry.Key); err != nil {
+			return removed, fmt.Errorf("failed to remove cache entry %s: %w", entry.Key, err)
+		}
+		removed = append(removed, entry)
+	}
+	return removed, nil
+}
+
+// readEntry reads the metadata of the entry in dir
+func readEntry(dir string) (Entry, error) {
+	metadataPath := filepath.Join(dir, metadataFile)
+	data, err := os.ReadFile(metadataPath)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return Entry{}, err
+	}
+	entry.Key = filepath.Base(dir)
+
+	if info, err := os.Stat(metadataPath); err == nil {
+		entry.LastUsed = info.ModTime()
+	}
+	if info, err := os.Stat(filepath.Join(dir, entry.Filename)); err == nil {
+		entry.Size = info.Size()
+	}
+	return entry, nil
+}
+
+// copyFile copies src to dst, creating dst's directory
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,137 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "cache-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	content := []byte("artifact")
+	sum := sha256.Sum256(content)
+	checksum := "sha256:" + hex.EncodeToString(sum[:])
+	url := "https://example.com/tool.tar.gz"
+
+	artifact := filepath.Join(tempDir, "download", "tool.tar.gz")
+	if err := os.MkdirAll(filepath.Dir(artifact), 0755); err != nil {
+		t.Fatalf("Failed to create download directory: %v", err)
+	}
+	if err := os.WriteFile(artifact, content, 0644); err != nil {
+		t.Fatalf("Failed to write artifact: %v", err)
+	}
+
+	c := New(filepath.Join(tempDir, "cache"))
+	if err := c.Store(url, checksum, artifact); err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+
+	t.Run("Lookup copies the artifact", func(t *testing.T) {
+		dest := filepath.Join(tempDir, "dest")
+		path, ok := c.Lookup(url, "SHA256:"+hex.EncodeToString(sum[:]), dest)
+		if !ok {
+			t.Fatalf("Expected a cache hit")
+		}
+		if data, _ := os.ReadFile(path); string(data) != "artifact" {
+			t.Errorf("Expected the cached content but got %q", data)
+		}
+	})
+
+	t.Run("Misses", func(t *testing.T) {
+		if _, ok := c.Lookup(url, "", tempDir); ok {
+			t.Errorf("Expected no hit without a checksum")
+		}
+		if _, ok := c.Lookup("https://example.com/other.tar.gz", checksum, tempDir); ok {
+			t.Errorf("Expected no hit for another URL")
+		}
+	})
+
+	t.Run("List", func(t *testing.T) {
+		entries, err := c.List()
+		if err != nil {
+			t.Fatalf("Did not expect an error but got: %v", err)
+		}
+		if len(entries) != 1 || entries[0].URL != url || entries[0].Size != int64(len(content)) {
+			t.Errorf("Expected one entry for %s but got %+v", url, entries)
+		}
+	})
+
+	t.Run("Validators", func(t *testing.T) {
+		latest := "https://example.com/latest/tool.tar.gz"
+		if _, ok := c.Validators(latest); ok {
+			t.Errorf("Expected no validators before any were recorded")
+		}
+
+		v := Validators{Checksum: checksum, ETag: `"abc"`}
+		if err := c.SetValidators(latest, v); err != nil {
+			t.Fatalf("Did not expect an error but got: %v", err)
+		}
+		if _, ok := c.Validators(latest); ok {
+			t.Errorf("Expected no validators while the artifact is not cached")
+		}
+
+		if err := c.Store(latest, checksum, artifact); err != nil {
+			t.Fatalf("Did not expect an error but got: %v", err)
+		}
+		if got, ok := c.Validators(latest); !ok || got != v {
+			t.Errorf("Expected %+v but got %+v", v, got)
+		}
+		if entries, _ := c.List(); len(entries) != 2 {
+			t.Errorf("Expected validators not to be listed as entries but got %+v", entries)
+		}
+		c.Remove(Key(latest, checksum))
+	})
+
+	t.Run("Corrupt entries are dropped", func(t *testing.T) {
+		other := "https://example.com/corrupt.tar.gz"
+		if err := c.Store(other, checksum, artifact); err != nil {
+			t.Fatalf("Did not expect an error but got: %v", err)
+		}
+		cached := filepath.Join(c.Dir, Key(other, checksum), "tool.tar.gz")
+		if err := os.WriteFile(cached, []byte("tampered"), 0644); err != nil {
+			t.Fatalf("Failed to corrupt entry: %v", err)
+		}
+		if _, ok := c.Lookup(other, checksum, tempDir); ok {
+			t.Errorf("Expected no hit for a corrupt entry")
+		}
+		if _, err := os.Stat(filepath.Dir(cached)); !os.IsNotExist(err) {
+			t.Errorf("Expected the corrupt entry to be removed")
+		}
+	})
+
+	t.Run("Prune", func(t *testing.T) {
+		old := time.Now().Add(-48 * time.Hour)
+		os.Chtimes(filepath.Join(c.Dir, Key(url, checksum), metadataFile), old, old)
+
+		removed, err := c.Prune(24 * time.Hour)
+		if err != nil {
+			t.Fatalf("Did not expect an error but got: %v", err)
+		}
+		if len(removed) != 1 {
+			t.Fatalf("Expected 1 entry to be pruned but got %d", len(removed))
+		}
+		if entries, _ := c.List(); len(entries) != 0 {
+			t.Errorf("Expected an empty cache but got %+v", entries)
+		}
+	})
+
+	t.Run("Clean", func(t *testing.T) {
+		if err := c.Store(url, checksum, artifact); err != nil {
+			t.Fatalf("Did not expect an error but got: %v", err)
+		}
+		if err := c.Clean(); err != nil {
+			t.Fatalf("Did not expect an error but got: %v", err)
+		}
+		if entries, _ := c.List(); len(entries) != 0 {
+			t.Errorf("Expected an empty cache but got %+v", entries)
+		}
+	})
+}
@@ -0,0 +1,377 @@
+package credentials
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// Kind is the way a credential is presented to a server
+type Kind string
+
+const (
+	KindBasic  Kind = "basic"   // HTTP basic auth with a username and password
+	KindBearer Kind = "bearer"  // "Authorization: Bearer <token>"
+	KindAPIKey Kind = "api-key" // Artifactory API key in the X-JFrog-Art-Api header
+)
+
+// ParseKind parses a credential type name
+func ParseKind(s string) (Kind, error) {
+	switch kind := Kind(strings.ToLower(s)); kind {
+	case KindBasic, KindBearer, KindAPIKey:
+		return kind, nil
+	default:
+		return "", fmt.Errorf("unknown credential type '%s' (expected basic, bearer or api-key)", s)
+	}
+}
+
+// Credential is a secret for a single host
+type Credential struct {
+	Kind     Kind   // How the secret is sent; empty if the source does not say
+	Username string // Username for basic auth
+	Secret   string // Password, token or API key
+	Source   string // Where the credential was found, for logs ("env", "netrc", ...)
+}
+
+// Apply adds the credential to a request
+func (c *Credential) Apply(req *http.Request) {
+	switch c.Kind {
+	case KindBearer:
+		req.Header.Set("Authorization", "Bearer "+c.Secret)
+	case KindAPIKey:
+		req.Header.Set("X-JFrog-Art-Api", c.Secret)
+	default:
+		req.SetBasicAuth(c.Username, c.Secret)
+	}
+}
+
+// Provider looks up the credential for a host. It returns nil and no error
+// when it has none.
+type Provider interface {
+	Lookup(ctx context.Context, host string) (*Credential, error)
+}
+
+// Chain asks each provider in turn and returns the first credential found
+type Chain []Provider
+
+// Lookup implements Provider
+func (c Chain) Lookup(ctx context.Context, host string) (*Credential, error) {
+	for _, provider := range c {
+		cred, err := provider.Lookup(ctx, host)
+		if err != nil || cred != nil {
+			return cred, err
+		}
+	}
+	return nil, nil
+}
+
+// Env reads credentials from environment variables named after the host,
+// upper-cased with every other character replaced by "_":
+//
+//	DEPMAN_AUTH_<HOST>_TOKEN      bearer token
+//	DEPMAN_AUTH_<HOST>_API_KEY    Artifactory API key
+//	DEPMAN_AUTH_<HOST>_USERNAME   basic auth username, with
+//	DEPMAN_AUTH_<HOST>_PASSWORD   its password
+type Env struct{}
+
+// EnvPrefix returns the prefix of the variables holding credentials for host
+func EnvPrefix(host string) string {
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' {
+			return r - 'a' + 'A'
+		}
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, host)
+	return "DEPMAN_AUTH_" + name + "_"
+}
+
+// Lookup implements Provider
+func (Env) Lookup(ctx context.Context, host string) (*Credential, error) {
+	prefix := EnvPrefix(host)
+	if token := os.Getenv(prefix + "TOKEN"); token != "" {
+		return &Credential{Kind: KindBearer, Secret: token, Source: "env"}, nil
+	}
+	if key := os.Getenv(prefix + "API_KEY"); key != "" {
+		return &Credential{Kind: KindAPIKey, Secret: key, Source: "env"}, nil
+	}
+	if password := os.Getenv(prefix + "PASSWORD"); password != "" {
+		return &Credential{Kind: KindBasic, Username: os.Getenv(prefix + "USERNAME"), Secret: password, Source: "env"}, nil
+	}
+	return nil, nil
+}
+
+// Netrc reads credentials from a netrc file. The path defaults to $NETRC,
+// then ~/.netrc (~/_netrc on Windows).
+type Netrc struct {
+	Path string
+}
+
+// Lookup implements Provider
+func (n Netrc) Lookup(ctx context.Context, host string) (*Credential, error) {
+	path := n.Path
+	if path == "" {
+		path = defaultNetrcPath()
+	}
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	login, password, ok := parseNetrc(data, host)
+	if !ok {
+		return nil, nil
+	}
+	return &Credential{Username: login, Secret: password, Source: "netrc"}, nil
+}
+
+// defaultNetrcPath returns the netrc file used when none is configured
+func defaultNetrcPath() string {
+	if path := os.Getenv("NETRC"); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	if runtime.GOOS == "windows" {
+		return filepath.Join(home, "_netrc")
+	}
+	return filepath.Join(home, ".netrc")
+}
+
+// parseNetrc returns the login and password of the entry for host, falling
+// back to the "default" entry
+func parseNetrc(data []byte, host string) (string, string, bool) {
+	type entry struct{ login, password string }
+	var (
+		found, fallback *entry
+		current         *entry
+		inMacro         bool
+	)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		// Macro definitions run until the next blank line
+		if inMacro {
+			inMacro = strings.TrimSpace(line) != ""
+			continue
+		}
+
+		fields := strings.Fields(line)
+		for i := 0; i < len(fields); i++ {
+			switch fields[i] {
+			case "machine":
+				current = nil
+				if i+1 < len(fields) {
+					i++
+					if fields[i] == host && found == nil {
+						found = &entry{}
+						current = found
+					}
+				}
+			case "default":
+				current = nil
+				if fallback == nil {
+					fallback = &entry{}
+					current = fallback
+				}
+			case "login", "password", "account":
+				if i+1 >= len(fields) {
+					continue
+				}
+				i++
+				if current == nil {
+					continue
+				}
+				if fields[i-1] == "login" {
+					current.login = fields[i]
+				} else if fields[i-1] == "password" {
+					current.password = fields[i]
+				}
+			case "macdef":
+				inMacro = true
+				i = len(fields)
+			}
+		}
+	}
+
+	if found == nil {
+		found = fallback
+	}
+	if found == nil || found.password == "" {
+		return "", "", false
+	}
+	return found.login, found.password, true
+}
+
+// Keychain reads the secret for a host from the OS keychain, stored under
+// the given service name with the host as account: the login keychain on
+// macOS ("security") and the Secret Service on Linux ("secret-tool"). Other
+// platforms and missing tools yield no credential.
+type Keychain struct {
+	Service string
+}
+
+// Lookup implements Provider
+func (k Keychain) Lookup(ctx context.Context, host string) (*Credential, error) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.CommandContext(ctx, "security", "find-generic-password", "-s", k.Service, "-a", host, "-w")
+	case "linux", "freebsd", "openbsd", "netbsd":
+		cmd = exec.CommandContext(ctx, "secret-tool", "lookup", "service", k.Service, "host", host)
+	default:
+		return nil, nil
+	}
+	if cmd.Err != nil {
+		return nil, nil // Tool not installed
+	}
+
+	// Both tools exit non-zero when there is no matching item
+	output, err := cmd.Output()
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, nil
+	}
+	secret := strings.TrimRight(string(output), "\r\n")
+	if secret == "" {
+		return nil, nil
+	}
+	return &Credential{Secret: secret, Source: "keychain"}, nil
+}
+
+// Helper runs a credential-helper executable. It is invoked as
+// "<command...> get" with "host=<host>" on stdin, like a git credential
+// helper, and prints "key=value" lines: username, password, token or
+// api_key. Empty output means it has no credential for the host.
+type Helper struct {
+	Command []string
+}
+
+// Lookup implements Provider
+func (h Helper) Lookup(ctx context.Context, host string) (*Credential, error) {
+	if len(h.Command) == 0 {
+		return nil, nil
+	}
+	cmd := exec.CommandContext(ctx, h.Command[0], append(h.Command[1:], "get")...)
+	cmd.Stdin = strings.NewReader("host=" + host + "\n\n")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("credential helper %s failed: %w: %s", h.Command[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	values := make(map[string]string)
+	for _, line := range strings.Split(string(output), "\n") {
+		if key, value, ok := strings.Cut(strings.TrimRight(line, "\r"), "="); ok {
+			values[strings.TrimSpace(key)] = value
+		}
+	}
+
+	cred := &Credential{Username: values["username"], Source: "helper " + h.Command[0]}
+	switch {
+	case values["token"] != "":
+		cred.Kind, cred.Secret = KindBearer, values["token"]
+	case values["api_key"] != "":
+		cred.Kind, cred.Secret = KindAPIKey, values["api_key"]
+	case values["password"] != "":
+		cred.Secret = values["password"]
+	default:
+		return nil, nil
+	}
+	return cred, nil
+}
+
+// Transport adds credentials from Provider to outgoing requests. Requests
+// that already carry an Authorization header are left alone, and
+// credentials are only sent over HTTPS or to loopback addresses. Lookups
+// are cached per host, so each redirect target is authenticated with its
+// own credential rather than the original host's.
+type Transport struct {
+	Base     http.RoundTripper // Transport that sends the requests (http.DefaultTransport if nil)
+	Provider Provider
+
+	mu    sync.Mutex
+	cache map[string]*Credential
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if t.Provider == nil || req.Header.Get("Authorization") != "" || !secure(req.URL) {
+		return base.RoundTrip(req)
+	}
+
+	cred, err := t.lookup(req.Context(), req.URL.Hostname())
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up credentials for %s: %w", req.URL.Hostname(), err)
+	}
+	if cred == nil {
+		return base.RoundTrip(req)
+	}
+
+	authed := req.Clone(req.Context())
+	cred.Apply(authed)
+	return base.RoundTrip(authed)
+}
+
+// lookup returns the cached credential for host, asking the provider once
+func (t *Transport) lookup(ctx context.Context, host string) (*Credential, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if cred, ok := t.cache[host]; ok {
+		return cred, nil
+	}
+
+	cred, err := t.Provider.Lookup(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	if t.cache == nil {
+		t.cache = make(map[string]*Credential)
+	}
+	t.cache[host] = cred
+	return cred, nil
+}
+
+// secure reports whether credentials may be sent to u without exposing them
+// on the network
+func secure(u *url.URL) bool {
+	if u.Scheme == "https" {
+		return true
+	}
+	host := u.Hostname()
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,168 @@
+package credentials
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseNetrc(t *testing.T) {
+	data := []byte(`machine other.example.com login bob password hunter2
+macdef init
+	cd /pub
+	machine artifacts.example.com login eve password stolen
+
+machine artifacts.example.com
+	login alice
+	password s3cret
+default login anonymous password guest
+`)
+
+	testCases := []struct {
+		name     string
+		host     string
+		login    string
+		password string
+	}{
+		{"Matching machine", "artifacts.example.com", "alice", "s3cret"},
+		{"Single-line machine", "other.example.com", "bob", "hunter2"},
+		{"Default entry", "unknown.example.com", "anonymous", "guest"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			login, password, ok := parseNetrc(data, tc.host)
+			if !ok {
+				t.Fatalf("Expected an entry for %s", tc.host)
+			}
+			if login != tc.login || password != tc.password {
+				t.Errorf("Expected %s/%s but got %s/%s", tc.login, tc.password, login, password)
+			}
+		})
+	}
+
+	if _, _, ok := parseNetrc([]byte("machine a login x password y\n"), "b"); ok {
+		t.Errorf("Did not expect an entry for an unknown host without a default")
+	}
+}
+
+func TestEnv(t *testing.T) {
+	t.Setenv("DEPMAN_AUTH_ARTIFACTS_EXAMPLE_COM_API_KEY", "key")
+	t.Setenv("DEPMAN_AUTH_LOCALHOST_USERNAME", "alice")
+	t.Setenv("DEPMAN_AUTH_LOCALHOST_PASSWORD", "s3cret")
+
+	cred, err := Env{}.Lookup(context.Background(), "artifacts.example.com")
+	if err != nil || cred == nil || cred.Kind != KindAPIKey || cred.Secret != "key" {
+		t.Errorf("Expected an API key but got %+v, %v", cred, err)
+	}
+
+	cred, err = Env{}.Lookup(context.Background(), "localhost")
+	if err != nil || cred == nil || cred.Kind != KindBasic || cred.Username != "alice" || cred.Secret != "s3cret" {
+		t.Errorf("Expected basic credentials but got %+v, %v", cred, err)
+	}
+
+	if cred, _ := (Env{}).Lookup(context.Background(), "other.example.com"); cred != nil {
+		t.Errorf("Did not expect credentials but got %+v", cred)
+	}
+}
+
+func TestHelper(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "credentials-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	script := filepath.Join(tempDir, "helper")
+	content := "#!/bin/sh\nread line\nif [ \"$1\" = get ] && [ \"$line\" = host=artifacts.example.com ]; then echo token=abc; fi\n"
+	if err := os.WriteFile(script, []byte(content), 0755); err != nil {
+		t.Fatalf("Failed to write helper: %v", err)
+	}
+
+	cred, err := Helper{Command: []string{script}}.Lookup(context.Background(), "artifacts.example.com")
+	if err != nil || cred == nil || cred.Kind != KindBearer || cred.Secret != "abc" {
+		t.Errorf("Expected a bearer token but got %+v, %v", cred, err)
+	}
+
+	cred, err = Helper{Command: []string{script}}.Lookup(context.Background(), "other.example.com")
+	if err != nil || cred != nil {
+		t.Errorf("Expected no credentials but got %+v, %v", cred, err)
+	}
+
+	if _, err := (Helper{Command: []string{"false"}}).Lookup(context.Background(), "artifacts.example.com"); err == nil {
+		t.Errorf("Expected an error from a failing helper")
+	}
+}
+
+// staticProvider returns the same credential for every host and counts lookups
+type staticProvider struct {
+	cred    *Credential
+	lookups int
+}
+
+func (p *staticProvider) Lookup(ctx context.Context, host string) (*Credential, error) {
+	p.lookups++
+	return p.cred, nil
+}
+
+func TestTransport(t *testing.T) {
+	var header string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("Authorization")
+	}))
+	defer server.Close()
+
+	provider := &staticProvider{cred: &Credential{Kind: KindBearer, Secret: "abc"}}
+	client := &http.Client{Transport: &Transport{Provider: provider}}
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("Did not expect an error but got: %v", err)
+		}
+		resp.Body.Close()
+	}
+	if header != "Bearer abc" {
+		t.Errorf("Expected the bearer token to be sent but got %q", header)
+	}
+	if provider.lookups != 1 {
+		t.Errorf("Expected 1 cached lookup but got %d", provider.lookups)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	req.Header.Set("Authorization", "token explicit")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	resp.Body.Close()
+	if header != "token explicit" {
+		t.Errorf("Expected an explicit Authorization header to be kept but got %q", header)
+	}
+}
+
+func TestSecure(t *testing.T) {
+	testCases := []struct {
+		url      string
+		expected bool
+	}{
+		{"https://artifacts.example.com/tool", true},
+		{"http://artifacts.example.com/tool", false},
+		{"http://127.0.0.1:8080/tool", true},
+		{"http://localhost/tool", true},
+		{"http://[::1]/tool", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.url, func(t *testing.T) {
+			u, _ := url.Parse(tc.url)
+			if got := secure(u); got != tc.expected {
+				t.Errorf("Expected %v but got %v", tc.expected, got)
+			}
+		})
+	}
+}
@@ -1,15 +1,18 @@
 package downloader
 
 import (
-	"crypto/sha256"
+	"context"
 	"encoding/hex"
+	"errors"
 	"fmt"
-	"hash"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+
+	"github.com/devnadeemashraf/depman/internal/verify"
 )
 
 // DownloadOptions configures the download operation
@@ -28,6 +31,47 @@ type DownloadOptions struct {
 
 	// Whether to show progress
 	ShowProgress bool
+
+	// Called as data arrives with the bytes written so far and the total
+	// size, which is -1 when the server does not report it
+	Progress func(downloaded, total int64)
+
+	// Client used for the request (defaults to http.DefaultClient)
+	Client *http.Client
+
+	// ETag and Last-Modified header of a copy the caller already has. When
+	// set, the request is conditional and ErrNotModified is returned if the
+	// copy is still current.
+	ETag         string
+	LastModified string
+
+	// Limiter shared by concurrent downloads to cap their number and rate
+	// (no limits if nil)
+	Limiter *Limiter
+}
+
+// maxResumes is how many times an interrupted transfer is resumed with a
+// Range request before the download fails
+const maxResumes = 3
+
+// ErrNotModified is returned when a conditional download finds the caller's
+// copy still current
+var ErrNotModified = errors.New("not modified")
+
+// StatusError is returned when the server answers with a status other than 200 OK
+type StatusError struct {
+	URL        string // URL that was requested
+	StatusCode int    // HTTP status code of the response
+	Status     string // HTTP status line, e.g. "503 Service Unavailable"
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("bad status: %s", e.Status)
+}
+
+// Temporary reports whether the status suggests the request may succeed if repeated
+func (e *StatusError) Temporary() bool {
+	return e.StatusCode == http.StatusRequestTimeout || e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
 }
 
 // Result contains information about the downloaded file
@@ -38,91 +82,181 @@ type Result struct {
 	// Size of the downloaded file in bytes
 	Size int64
 
-	// Calculated checksum of the file
+	// Calculated checksum of the file (format: "algorithm:hash")
 	Checksum string
+
+	// ETag and Last-Modified header of the response, for conditional
+	// downloads of the same URL later
+	ETag         string
+	LastModified string
 }
 
-// Download downloads a file from a URL with progress reporting and checksum verification
-func Download(opts DownloadOptions) (*Result, error) {
+// Download downloads a file from a URL with progress reporting and checksum
+// verification, aborting when ctx is done
+func Download(ctx context.Context, opts DownloadOptions) (*Result, error) {
 	// Create destination directory if it doesn't exist
 	if err := os.MkdirAll(opts.DestDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create destination directory: %w", err)
 	}
 
-	// Determine filename from URL if not specified
+	// Determine filename from URL if not specified, leaving out the query,
+	// which may hold an access token
 	if opts.Filename == "" {
-		opts.Filename = filepath.Base(opts.URL)
+		name, _, _ := strings.Cut(opts.URL, "?")
+		opts.Filename = filepath.Base(name)
 	}
 
 	// Full path to the downloaded file
 	destPath := filepath.Join(opts.DestDir, opts.Filename)
 
-	// Create the file
-	out, err := os.Create(destPath)
+	// Get the data
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, opts.URL, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create destination file: %w", err)
+		return nil, fmt.Errorf("failed to download file: %w", err)
 	}
-	defer out.Close()
-
-	// Get the data
-	resp, err := http.Get(opts.URL)
+	release, err := opts.Limiter.acquire(ctx, req.URL.Host)
 	if err != nil {
 		return nil, fmt.Errorf("failed to download file: %w", err)
 	}
-	defer resp.Body.Close()
+	defer release()
+	if opts.ETag != "" {
+		req.Header.Set("If-None-Match", opts.ETag)
+	}
+	if opts.LastModified != "" {
+		req.Header.Set("If-Modified-Since", opts.LastModified)
+	}
+	client := opts.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file: %w", err)
+	}
+	body := resp.Body
+	defer func() { body.Close() }()
 
 	// Check server response
+	if resp.StatusCode == http.StatusNotModified && (opts.ETag != "" || opts.LastModified != "") {
+		return nil, ErrNotModified
+	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("bad status: %s", resp.Status)
+		return nil, &StatusError{URL: opts.URL, StatusCode: resp.StatusCode, Status: resp.Status}
 	}
 
-	// Initialize variables for checksum calculation
-	var hasher hash.Hash
-	var resultChecksum string
-	var writer io.Writer = out
+	// Create the file
+	out, err := os.Create(destPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create destination file: %w", err)
+	}
+	defer out.Close()
+
+	// Always hash the download so callers can record the artifact checksum
+	algorithm := "sha256"
+	expectedChecksum := ""
 
 	// Set up checksum verification if requested
 	if opts.Checksum != "" {
-		parts := strings.Split(opts.Checksum, ":")
-		if len(parts) != 2 {
-			return nil, fmt.Errorf("invalid checksum format, expected 'algorithm:hash'")
+		algorithm, expectedChecksum, err = verify.ParseChecksum(opts.Checksum)
+		if err != nil {
+			return nil, err
 		}
+	}
 
-		algorithm := strings.ToLower(parts[0])
-		if algorithm != "sha256" {
-			return nil, fmt.Errorf("unsupported checksum algorithm: %s", algorithm)
-		}
+	hasher, err := verify.NewHash(algorithm)
+	if err != nil {
+		return nil, err
+	}
 
-		// Create SHA-256 hasher
-		hasher = sha256.New()
-		// Write to both file and hasher
-		writer = io.MultiWriter(out, hasher)
+	// Write to both file and hasher
+	writer := io.MultiWriter(out, hasher)
+	if opts.Progress != nil {
+		opts.Progress(0, resp.ContentLength)
+		writer = &progressWriter{writer: writer, total: resp.ContentLength, report: opts.Progress}
 	}
 
-	// Copy data with optional progress reporting
-	size, err := io.Copy(writer, resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to write file: %w", err)
+	// Copy data with optional progress reporting, resuming interrupted
+	// transfers where they stopped if the server supports ranges
+	var size int64
+	for resumes := 0; ; resumes++ {
+		n, err := io.Copy(writer, opts.Limiter.reader(ctx, body))
+		size += n
+		if err == nil {
+			break
+		}
+		if resumes == maxResumes || ctx.Err() != nil || !resumable(resp) {
+			return nil, fmt.Errorf("failed to write file: %w", err)
+		}
+		next, err := resume(ctx, client, resp, size)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resume download: %w", err)
+		}
+		body.Close()
+		body = next
 	}
 
+	actualChecksum := hex.EncodeToString(hasher.Sum(nil))
+	resultChecksum := algorithm + ":" + actualChecksum
+
 	// Verify checksum if provided
-	if opts.Checksum != "" && hasher != nil {
-		parts := strings.Split(opts.Checksum, ":")
-		expectedChecksum := parts[1]
-		actualChecksum := hex.EncodeToString(hasher.Sum(nil))
-		resultChecksum = actualChecksum
-
-		if !strings.EqualFold(actualChecksum, expectedChecksum) {
-			// Remove the file if checksum verification fails
-			os.Remove(destPath)
-			return nil, fmt.Errorf("checksum verification failed: expected %s, got %s",
-				expectedChecksum, actualChecksum)
-		}
+	if expectedChecksum != "" && !strings.EqualFold(actualChecksum, expectedChecksum) {
+		// Remove the file if checksum verification fails
+		os.Remove(destPath)
+		return nil, &verify.ChecksumError{Algorithm: algorithm, Expected: expectedChecksum, Actual: actualChecksum}
 	}
 
 	return &Result{
-		FilePath: destPath,
-		Size:     size,
-		Checksum: resultChecksum,
+		FilePath:     destPath,
+		Size:         size,
+		Checksum:     resultChecksum,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
 	}, nil
 }
+
+// resumable reports whether a transfer can continue with a Range request:
+// the server accepts ranges and identifies the content, so If-Range makes
+// sure the rest comes from the same file
+func resumable(resp *http.Response) bool {
+	return resp.Header.Get("Accept-Ranges") == "bytes" && (resp.Header.Get("ETag") != "" || resp.Header.Get("Last-Modified") != "")
+}
+
+// resume requests the content of resp from offset on and returns the body
+func resume(ctx context.Context, client *http.Client, resp *http.Response, offset int64) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, resp.Request.URL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-")
+	validator := resp.Header.Get("ETag")
+	if validator == "" {
+		validator = resp.Header.Get("Last-Modified")
+	}
+	req.Header.Set("If-Range", validator)
+
+	resumed, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	prefix := "bytes " + strconv.FormatInt(offset, 10) + "-"
+	if resumed.StatusCode != http.StatusPartialContent || !strings.HasPrefix(resumed.Header.Get("Content-Range"), prefix) {
+		resumed.Body.Close()
+		return nil, fmt.Errorf("the file changed or the server did not return the rest of it (%s)", resumed.Status)
+	}
+	return resumed.Body, nil
+}
+
+// progressWriter reports the number of bytes written through it
+type progressWriter struct {
+	writer  io.Writer
+	written int64
+	total   int64
+	report  func(downloaded, total int64)
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	n, err := w.writer.Write(p)
+	w.written += int64(n)
+	w.report(w.written, w.total)
+	return n, err
+}
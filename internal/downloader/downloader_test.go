@@ -0,0 +1,140 @@
+package downloader
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDownloadResumes(t *testing.T) {
+	content := []byte(strings.Repeat("depman artifact ", 4096))
+	sum := sha256.Sum256(content)
+	checksum := "sha256:" + hex.EncodeToString(sum[:])
+
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Accept-Ranges", "bytes")
+		w.Header().Set("ETag", `"v1"`)
+		if header := r.Header.Get("Range"); header != "" {
+			ranges = append(ranges, header+" "+r.Header.Get("If-Range"))
+			var offset int
+			fmt.Sscanf(header, "bytes=%d-", &offset)
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, len(content)-1, len(content)))
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write(content[offset:])
+			return
+		}
+
+		// The first transfer breaks off half way
+		w.Header().Set("Content-Length", fmt.Sprint(len(content)))
+		w.Write(content[:len(content)/2])
+		w.(http.Flusher).Flush()
+		panic(http.ErrAbortHandler)
+	}))
+	defer server.Close()
+
+	result, err := Download(context.Background(), DownloadOptions{URL: server.URL + "/tool.tar.gz", Checksum: checksum, DestDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if result.Size != int64(len(content)) || result.Checksum != checksum {
+		t.Errorf("Expected the whole file but got %d bytes with %s", result.Size, result.Checksum)
+	}
+	if data, _ := os.ReadFile(result.FilePath); string(data) != string(content) {
+		t.Errorf("Expected the downloaded file to match the content")
+	}
+	if len(ranges) != 1 || ranges[0] != fmt.Sprintf(`bytes=%d- "v1"`, len(content)/2) {
+		t.Errorf("Expected one Range request for the second half but got %v", ranges)
+	}
+}
+
+func TestDownloadNotModified(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte("artifact"))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	result, err := Download(context.Background(), DownloadOptions{URL: server.URL + "/tool", DestDir: dir})
+	if err != nil || result.ETag != `"v1"` {
+		t.Fatalf("Expected a download with its ETag but got %+v (%v)", result, err)
+	}
+	if _, err := Download(context.Background(), DownloadOptions{URL: server.URL + "/tool", DestDir: dir, ETag: result.ETag}); !errors.Is(err, ErrNotModified) {
+		t.Errorf("Expected ErrNotModified but got %v", err)
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	var mu sync.Mutex
+	inFlight, peak := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		peak = max(peak, inFlight)
+		mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		w.Write(make([]byte, 1000))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name   string
+		limits Limits
+		peak   int
+	}{
+		{name: "parallel", limits: Limits{Parallel: 2}, peak: 2},
+		{name: "per host", limits: Limits{Parallel: 4, PerHost: 1}, peak: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			peak = 0
+			limiter := NewLimiter(tt.limits)
+			dir := t.TempDir()
+			var wg sync.WaitGroup
+			for i := 0; i < 6; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					if _, err := Download(context.Background(), DownloadOptions{URL: server.URL, DestDir: dir, Filename: fmt.Sprint(i), Limiter: limiter}); err != nil {
+						t.Errorf("Did not expect an error but got: %v", err)
+					}
+				}(i)
+			}
+			wg.Wait()
+			if peak != tt.peak {
+				t.Errorf("Expected at most %d downloads in flight but got %d", tt.peak, peak)
+			}
+		})
+	}
+
+	t.Run("bandwidth", func(t *testing.T) {
+		limiter := NewLimiter(Limits{BytesPerSecond: 10000})
+		start := time.Now()
+		for i := 0; i < 3; i++ {
+			if _, err := Download(context.Background(), DownloadOptions{URL: server.URL, DestDir: t.TempDir(), Filename: "tool", Limiter: limiter}); err != nil {
+				t.Fatalf("Did not expect an error but got: %v", err)
+			}
+		}
+		// 3000 bytes at 10000 bytes per second take at least 0.2s after the first chunk
+		if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+			t.Errorf("Expected the rate limit to slow the downloads down but they took %v", elapsed)
+		}
+	})
+}
@@ -0,0 +1,132 @@
+package downloader
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// Limits caps the downloads made through the same Limiter
+type Limits struct {
+	Parallel       int   // Downloads in flight at once (0 for no limit)
+	PerHost        int   // Downloads in flight to the same host (0 for no limit)
+	BytesPerSecond int64 // Combined rate of every download (0 for no limit)
+}
+
+// Limiter enforces Limits across concurrent downloads. A nil Limiter does
+// not limit anything.
+type Limiter struct {
+	limits Limits
+	slots  chan struct{}            // One token per download in flight
+	mu     sync.Mutex               // Guards hosts and next
+	hosts  map[string]chan struct{} // One token per download in flight, by host
+	next   time.Time                // When the bytes read so far are paid for at the rate limit
+}
+
+// NewLimiter returns a limiter enforcing limits
+func NewLimiter(limits Limits) *Limiter {
+	l := &Limiter{limits: limits, hosts: make(map[string]chan struct{})}
+	if limits.Parallel > 0 {
+		l.slots = make(chan struct{}, limits.Parallel)
+	}
+	return l
+}
+
+// acquire waits for a download slot, overall and for host, and returns the
+// function that releases it
+func (l *Limiter) acquire(ctx context.Context, host string) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	var held []chan struct{}
+	release := func() {
+		for _, slots := range held {
+			<-slots
+		}
+	}
+	for _, slots := range []chan struct{}{l.slots, l.hostSlots(host)} {
+		if slots == nil {
+			continue
+		}
+		select {
+		case slots <- struct{}{}:
+			held = append(held, slots)
+		case <-ctx.Done():
+			release()
+			return nil, ctx.Err()
+		}
+	}
+	return release, nil
+}
+
+// hostSlots returns the slots of host, or nil without a per-host limit
+func (l *Limiter) hostSlots(host string) chan struct{} {
+	if l.limits.PerHost <= 0 {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	slots, ok := l.hosts[host]
+	if !ok {
+		slots = make(chan struct{}, l.limits.PerHost)
+		l.hosts[host] = slots
+	}
+	return slots
+}
+
+// wait blocks until n more bytes may be read at the rate limit
+func (l *Limiter) wait(ctx context.Context, n int) error {
+	if l == nil || l.limits.BytesPerSecond <= 0 || n <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	delay := l.next.Sub(now)
+	l.next = l.next.Add(time.Duration(int64(n) * int64(time.Second) / l.limits.BytesPerSecond))
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// reader returns r limited to the rate limit
+func (l *Limiter) reader(ctx context.Context, r io.Reader) io.Reader {
+	if l == nil || l.limits.BytesPerSecond <= 0 {
+		return r
+	}
+	return &limitedReader{ctx: ctx, reader: r, limiter: l}
+}
+
+// limitedReader reads no faster than its limiter allows
+type limitedReader struct {
+	ctx     context.Context
+	reader  io.Reader
+	limiter *Limiter
+}
+
+func (r *limitedReader) Read(p []byte) (int, error) {
+	// Small reads keep the rate smooth for slow limits
+	if chunk := int(r.limiter.limits.BytesPerSecond / 10); chunk > 0 && len(p) > chunk {
+		p = p[:chunk]
+	}
+	n, err := r.reader.Read(p)
+	if waitErr := r.limiter.wait(r.ctx, n); waitErr != nil && err == nil {
+		err = waitErr
+	}
+	return n, err
+}
@@ -45,6 +45,23 @@ func (m *Manager) AddPath(path string) {
 	m.Paths = append(m.Paths, path)
 }
 
+// RemoveVariable removes a previously added environment variable
+func (m *Manager) RemoveVariable(key string) {
+	delete(m.Variables, key)
+}
+
+// RemovePath removes a previously added path from the PATH variable
+func (m *Manager) RemovePath(path string) {
+	path = filepath.Clean(path)
+
+	for i, p := range m.Paths {
+		if p == path {
+			m.Paths = append(m.Paths[:i], m.Paths[i+1:]...)
+			return
+		}
+	}
+}
+
 // GetUpdatedEnvironment returns a new environment with the applied changes
 func (m *Manager) GetUpdatedEnvironment() []string {
 	// Start with the current environment
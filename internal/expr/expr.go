@@ -0,0 +1,424 @@
+// Package expr parses and evaluates the small boolean expressions used in
+// configuration conditions, such as platform == "darwin" && !exists("/opt/x").
+package expr
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Env supplies what an expression can refer to
+type Env struct {
+	Vars   map[string]string            // Variables referred to by name, e.g. "platform"
+	Lookup func(name string) string     // Value of env.NAME, or "" if unset
+	Funcs  map[string]func(string) bool // Functions of one string, e.g. exists("/usr/local/cuda")
+}
+
+// Expr is a parsed boolean expression. It compares strings and booleans with
+// == and !=, combines conditions with &&, || and !, and groups them with
+// parentheses.
+type Expr struct {
+	source string
+	root   node
+}
+
+// String returns the expression's source
+func (e *Expr) String() string {
+	return e.source
+}
+
+// Eval evaluates the expression, which must result in a boolean
+func (e *Expr) Eval(env Env) (bool, error) {
+	v, err := e.root.eval(env)
+	if err != nil {
+		return false, err
+	}
+	if v.kind != kindBool {
+		return false, fmt.Errorf("expression results in the %s %q, not a boolean", v.kind, v.s)
+	}
+	return v.b, nil
+}
+
+// Check reports variables and functions the expression uses that env does
+// not define, without evaluating anything
+func (e *Expr) Check(env Env) error {
+	return e.root.check(env)
+}
+
+// kind is the type of a value
+type kind string
+
+const (
+	kindString kind = "string"
+	kindBool   kind = "boolean"
+)
+
+// value is the result of evaluating a node
+type value struct {
+	kind kind
+	s    string
+	b    bool
+}
+
+// node is an element of the expression tree
+type node interface {
+	eval(env Env) (value, error)
+	check(env Env) error
+}
+
+// literal is a string or boolean constant
+type literal struct{ v value }
+
+func (n literal) eval(Env) (value, error) { return n.v, nil }
+func (n literal) check(Env) error         { return nil }
+
+// variable is a name such as platform, or env.NAME
+type variable struct{ name string }
+
+func (n variable) eval(env Env) (value, error) {
+	if name, ok := strings.CutPrefix(n.name, "env."); ok {
+		if env.Lookup == nil {
+			return value{kind: kindString}, nil
+		}
+		return value{kind: kindString, s: env.Lookup(name)}, nil
+	}
+	s, ok := env.Vars[n.name]
+	if !ok {
+		return value{}, n.check(env)
+	}
+	return value{kind: kindString, s: s}, nil
+}
+
+func (n variable) check(env Env) error {
+	if strings.HasPrefix(n.name, "env.") && len(n.name) > len("env.") {
+		return nil
+	}
+	if _, ok := env.Vars[n.name]; !ok {
+		return fmt.Errorf("unknown variable '%s'%s", n.name, expected("variables", env.Vars))
+	}
+	return nil
+}
+
+// call is a function applied to one argument
+type call struct {
+	name string
+	arg  node
+}
+
+func (n call) eval(env Env) (value, error) {
+	if err := n.check(env); err != nil {
+		return value{}, err
+	}
+	arg, err := n.arg.eval(env)
+	if err != nil {
+		return value{}, err
+	}
+	if arg.kind != kindString {
+		return value{}, fmt.Errorf("%s() takes a string", n.name)
+	}
+	return value{kind: kindBool, b: env.Funcs[n.name](arg.s)}, nil
+}
+
+func (n call) check(env Env) error {
+	if _, ok := env.Funcs[n.name]; !ok {
+		return fmt.Errorf("unknown function '%s'%s", n.name, expected("functions", env.Funcs))
+	}
+	return n.arg.check(env)
+}
+
+// not negates a boolean
+type not struct{ x node }
+
+func (n not) eval(env Env) (value, error) {
+	v, err := n.x.eval(env)
+	if err != nil {
+		return value{}, err
+	}
+	if v.kind != kindBool {
+		return value{}, fmt.Errorf("! needs a boolean but got the %s %q", v.kind, v.s)
+	}
+	return value{kind: kindBool, b: !v.b}, nil
+}
+
+func (n not) check(env Env) error { return n.x.check(env) }
+
+// binary is a comparison or a logical operator
+type binary struct {
+	op   string
+	l, r node
+}
+
+func (n binary) eval(env Env) (value, error) {
+	l, err := n.l.eval(env)
+	if err != nil {
+		return value{}, err
+	}
+
+	if n.op == "&&" || n.op == "||" {
+		if l.kind != kindBool {
+			return value{}, fmt.Errorf("%s needs booleans but got the %s %q", n.op, l.kind, l.s)
+		}
+		// Short-circuit, so exists() and the like only run when needed
+		if (n.op == "&&") != l.b {
+			return l, nil
+		}
+		r, err := n.r.eval(env)
+		if err != nil {
+			return value{}, err
+		}
+		if r.kind != kindBool {
+			return value{}, fmt.Errorf("%s needs booleans but got the %s %q", n.op, r.kind, r.s)
+		}
+		return r, nil
+	}
+
+	r, err := n.r.eval(env)
+	if err != nil {
+		return value{}, err
+	}
+	if l.kind != r.kind {
+		return value{}, fmt.Errorf("cannot compare a %s with a %s", l.kind, r.kind)
+	}
+	equal := l == r
+	return value{kind: kindBool, b: equal == (n.op == "==")}, nil
+}
+
+func (n binary) check(env Env) error {
+	if err := n.l.check(env); err != nil {
+		return err
+	}
+	return n.r.check(env)
+}
+
+// expected lists the defined names for an error message
+func expected[T any](what string, defined map[string]T) string {
+	if len(defined) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(defined))
+	for name := range defined {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return fmt.Sprintf(" (%s: %s)", what, strings.Join(names, ", "))
+}
+
+// Parse parses an expression such as
+//
+//	platform == "darwin" && arch == "arm64"
+//	env.CI != "true" || exists("/usr/local/cuda")
+func Parse(source string) (*Expr, error) {
+	p := &parser{source: source}
+	if err := p.tokenize(); err != nil {
+		return nil, err
+	}
+	if len(p.tokens) == 1 {
+		return nil, fmt.Errorf("empty expression")
+	}
+	root, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEnd {
+		return nil, p.unexpected(t)
+	}
+	return &Expr{source: source, root: root}, nil
+}
+
+// tokenKind classifies tokens
+type tokenKind int
+
+const (
+	tokenEnd tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenOp
+)
+
+// token is a lexical element and where it starts in the source
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// parser is a recursive descent parser over the tokens of an expression
+type parser struct {
+	source string
+	tokens []token
+	next   int
+}
+
+// operators are the punctuation tokens, longest first
+var operators = []string{"&&", "||", "==", "!=", "!", "(", ")"}
+
+func (p *parser) tokenize() error {
+	for i := 0; i < len(p.source); {
+		c := rune(p.source[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '"' || c == '\'':
+			end := i + 1
+			for end < len(p.source) && rune(p.source[end]) != c {
+				if c == '"' && p.source[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(p.source) {
+				return fmt.Errorf("unterminated string at position %d", i+1)
+			}
+			raw := p.source[i : end+1]
+			text := raw[1 : len(raw)-1]
+			if c == '"' {
+				unquoted, err := strconv.Unquote(raw)
+				if err != nil {
+					return fmt.Errorf("invalid string %s at position %d", raw, i+1)
+				}
+				text = unquoted
+			}
+			p.tokens = append(p.tokens, token{kind: tokenString, text: text, pos: i})
+			i += len(raw)
+		case c == '_' || unicode.IsLetter(c):
+			start := i
+			for i < len(p.source) && isIdentChar(rune(p.source[i])) {
+				i++
+			}
+			p.tokens = append(p.tokens, token{kind: tokenIdent, text: p.source[start:i], pos: start})
+		default:
+			matched := false
+			for _, op := range operators {
+				if strings.HasPrefix(p.source[i:], op) {
+					p.tokens = append(p.tokens, token{kind: tokenOp, text: op, pos: i})
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return fmt.Errorf("unexpected character %q at position %d", c, i+1)
+			}
+		}
+	}
+	p.tokens = append(p.tokens, token{kind: tokenEnd, pos: len(p.source)})
+	return nil
+}
+
+// isIdentChar reports whether c may appear in a name such as env.MY_VAR
+func isIdentChar(c rune) bool {
+	return c == '_' || c == '.' || unicode.IsLetter(c) || unicode.IsDigit(c)
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.next]
+}
+
+func (p *parser) take() token {
+	t := p.tokens[p.next]
+	if t.kind != tokenEnd {
+		p.next++
+	}
+	return t
+}
+
+// accept takes the next token if it is the given operator
+func (p *parser) accept(op string) bool {
+	if t := p.peek(); t.kind == tokenOp && t.text == op {
+		p.next++
+		return true
+	}
+	return false
+}
+
+func (p *parser) unexpected(t token) error {
+	if t.kind == tokenEnd {
+		return fmt.Errorf("unexpected end of expression")
+	}
+	return fmt.Errorf("unexpected %q at position %d", t.text, t.pos+1)
+}
+
+// or := and ("||" and)*
+func (p *parser) or() (node, error) {
+	left, err := p.and()
+	for err == nil && p.accept("||") {
+		var right node
+		if right, err = p.and(); err == nil {
+			left = binary{op: "||", l: left, r: right}
+		}
+	}
+	return left, err
+}
+
+// and := unary ("&&" unary)*
+func (p *parser) and() (node, error) {
+	left, err := p.unary()
+	for err == nil && p.accept("&&") {
+		var right node
+		if right, err = p.unary(); err == nil {
+			left = binary{op: "&&", l: left, r: right}
+		}
+	}
+	return left, err
+}
+
+// unary := "!" unary | comparison
+func (p *parser) unary() (node, error) {
+	if p.accept("!") {
+		x, err := p.unary()
+		return not{x: x}, err
+	}
+	return p.comparison()
+}
+
+// comparison := primary (("==" | "!=") primary)?
+func (p *parser) comparison() (node, error) {
+	left, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"==", "!="} {
+		if p.accept(op) {
+			right, err := p.primary()
+			return binary{op: op, l: left, r: right}, err
+		}
+	}
+	return left, nil
+}
+
+// primary := "(" or ")" | string | true | false | name | name "(" or ")"
+func (p *parser) primary() (node, error) {
+	t := p.take()
+	switch {
+	case t.kind == tokenOp && t.text == "(":
+		x, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, p.unexpected(p.peek())
+		}
+		return x, nil
+	case t.kind == tokenString:
+		return literal{v: value{kind: kindString, s: t.text}}, nil
+	case t.kind == tokenIdent && (t.text == "true" || t.text == "false"):
+		return literal{v: value{kind: kindBool, b: t.text == "true"}}, nil
+	case t.kind == tokenIdent:
+		if !p.accept("(") {
+			return variable{name: t.text}, nil
+		}
+		arg, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, p.unexpected(p.peek())
+		}
+		return call{name: t.text, arg: arg}, nil
+	default:
+		return nil, p.unexpected(t)
+	}
+}
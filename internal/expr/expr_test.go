@@ -0,0 +1,137 @@
+package expr
+
+import (
+	"strings"
+	"testing"
+)
+
+func testEnv() Env {
+	return Env{
+		Vars: map[string]string{"platform": "darwin", "arch": "arm64"},
+		Lookup: func(name string) string {
+			if name == "CI" {
+				return "true"
+			}
+			return ""
+		},
+		Funcs: map[string]func(string) bool{
+			"exists": func(path string) bool { return path == "/usr/local/cuda" },
+		},
+	}
+}
+
+func TestEval(t *testing.T) {
+	testCases := []struct {
+		source   string
+		expected bool
+	}{
+		{source: `platform == "darwin"`, expected: true},
+		{source: `platform == 'linux'`, expected: false},
+		{source: `platform == "darwin" && arch == "arm64"`, expected: true},
+		{source: `platform == "darwin" && arch != "arm64"`, expected: false},
+		{source: `platform == "linux" || arch == "arm64"`, expected: true},
+		{source: `!(platform == "linux")`, expected: true},
+		{source: `env.CI != "true"`, expected: false},
+		{source: `env.UNSET == ""`, expected: true},
+		{source: `exists("/usr/local/cuda")`, expected: true},
+		{source: `!exists("/opt/missing")`, expected: true},
+		{source: `true && !false`, expected: true},
+		{source: `platform == "linux" || platform == "darwin" && arch == "amd64"`, expected: false},
+		{source: `(platform == "linux" || platform == "darwin") && arch == "arm64"`, expected: true},
+		{source: `"a\"b" == 'a"b'`, expected: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.source, func(t *testing.T) {
+			e, err := Parse(tc.source)
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			got, err := e.Eval(testEnv())
+			if err != nil {
+				t.Fatalf("Eval failed: %v", err)
+			}
+			if got != tc.expected {
+				t.Errorf("Expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestEvalShortCircuits(t *testing.T) {
+	called := false
+	env := testEnv()
+	env.Funcs["exists"] = func(string) bool {
+		called = true
+		return true
+	}
+
+	e, err := Parse(`platform == "linux" && exists("/x")`)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if got, err := e.Eval(env); err != nil || got {
+		t.Fatalf("Expected false, got %v, %v", got, err)
+	}
+	if called {
+		t.Error("Expected exists() not to be called")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	testCases := []struct {
+		source   string
+		expected string
+	}{
+		{source: ``, expected: "empty expression"},
+		{source: `platform ==`, expected: "unexpected end"},
+		{source: `platform == "darwin`, expected: "unterminated string"},
+		{source: `(platform == "darwin"`, expected: "unexpected end"},
+		{source: `platform = "darwin"`, expected: "unexpected character"},
+		{source: `platform == "darwin" arch`, expected: `unexpected "arch"`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.source, func(t *testing.T) {
+			_, err := Parse(tc.source)
+			if err == nil || !strings.Contains(err.Error(), tc.expected) {
+				t.Errorf("Expected error containing %q, got %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	testCases := []struct {
+		source   string
+		expected string
+	}{
+		{source: `platform`, expected: "not a boolean"},
+		{source: `os == "linux"`, expected: "unknown variable 'os' (variables: arch, platform)"},
+		{source: `which("go")`, expected: "unknown function 'which'"},
+		{source: `platform == true`, expected: "cannot compare"},
+		{source: `platform && true`, expected: "needs booleans"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.source, func(t *testing.T) {
+			e, err := Parse(tc.source)
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			if _, err := e.Eval(testEnv()); err == nil || !strings.Contains(err.Error(), tc.expected) {
+				t.Errorf("Expected error containing %q, got %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	e, err := Parse(`platform == "linux" && exists(os)`)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if err := e.Check(testEnv()); err == nil || !strings.Contains(err.Error(), "unknown variable 'os'") {
+		t.Errorf("Expected unknown variable error, got %v", err)
+	}
+}
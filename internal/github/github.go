@@ -0,0 +1,140 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/devnadeemashraf/depman/internal/downloader"
+)
+
+// APIURL is the base URL of the GitHub REST API; replaced in tests and for GitHub Enterprise
+var APIURL = "https://api.github.com"
+
+// Asset is a file attached to a release
+type Asset struct {
+	Name        string `json:"name"`
+	DownloadURL string `json:"browser_download_url"`
+	Size        int64  `json:"size"`
+}
+
+// Release is a published GitHub release
+type Release struct {
+	TagName    string  `json:"tag_name"`
+	Draft      bool    `json:"draft"`
+	Prerelease bool    `json:"prerelease"`
+	Assets     []Asset `json:"assets"`
+}
+
+// RateLimitError is returned when the API rate limit has been exhausted
+type RateLimitError struct {
+	Reset time.Time // When the rate limit resets
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("GitHub API rate limit exceeded until %s; set GITHUB_TOKEN to raise the limit",
+		e.Reset.Format(time.RFC3339))
+}
+
+// Token returns the API token from the named environment variable,
+// falling back to GITHUB_TOKEN and GH_TOKEN
+func Token(envVar string) string {
+	for _, name := range []string{envVar, "GITHUB_TOKEN", "GH_TOKEN"} {
+		if name == "" {
+			continue
+		}
+		if token := os.Getenv(name); token != "" {
+			return token
+		}
+	}
+	return ""
+}
+
+// ListReleases returns the most recent releases of a repository ("owner/name"),
+// querying the API with client (http.DefaultClient if nil)
+func ListReleases(ctx context.Context, client *http.Client, repo, token string) ([]Release, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/repos/%s/releases?per_page=100", APIURL, repo), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query releases of %s: %w", repo, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests {
+		if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+			reset, _ := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+			return nil, &RateLimitError{Reset: time.Unix(reset, 0)}
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to query releases of %s: %w", repo,
+			&downloader.StatusError{URL: req.URL.String(), StatusCode: resp.StatusCode, Status: resp.Status})
+	}
+
+	var releases []Release
+	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+		return nil, fmt.Errorf("failed to parse releases of %s: %w", repo, err)
+	}
+
+	return releases, nil
+}
+
+// RepositoryLicense returns the SPDX identifier of the license GitHub detected
+// for a repository ("owner/name"), or "" if it found none it could identify
+func RepositoryLicense(ctx context.Context, client *http.Client, repo, token string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/repos/%s/license", APIURL, repo), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to query license of %s: %w", repo, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to query license of %s: %w", repo,
+			&downloader.StatusError{URL: req.URL.String(), StatusCode: resp.StatusCode, Status: resp.Status})
+	}
+
+	var body struct {
+		License struct {
+			SPDXID string `json:"spdx_id"`
+		} `json:"license"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to parse license of %s: %w", repo, err)
+	}
+
+	// GitHub reports licenses it cannot classify as NOASSERTION
+	if body.License.SPDXID == "NOASSERTION" {
+		return "", nil
+	}
+	return body.License.SPDXID, nil
+}
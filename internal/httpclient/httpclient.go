@@ -0,0 +1,91 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// Options configures the HTTP client used for every download and API call
+type Options struct {
+	// PEM file with additional CA certificates trusted on top of the system pool
+	CABundle string
+
+	// PEM files with a client certificate and its key for mutual TLS
+	ClientCert string
+	ClientKey  string
+
+	// Skip TLS certificate verification; only for debugging broken proxies
+	InsecureSkipVerify bool
+
+	// Proxy URL for every request, instead of the one from the environment
+	Proxy string
+}
+
+// New returns a client that honors HTTPS_PROXY, HTTP_PROXY and NO_PROXY, or
+// opts.Proxy if set, and applies the TLS settings in opts
+func New(opts Options) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	if opts.Proxy != "" {
+		proxy, err := url.Parse(opts.Proxy)
+		if err != nil || proxy.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL '%s'", opts.Proxy)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12, InsecureSkipVerify: opts.InsecureSkipVerify}
+
+	if opts.CABundle != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		data, err := os.ReadFile(opts.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", opts.CABundle)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if opts.ClientCert != "" || opts.ClientKey != "" {
+		if opts.ClientCert == "" || opts.ClientKey == "" {
+			return nil, fmt.Errorf("a client certificate and its key must be given together")
+		}
+		cert, err := tls.LoadX509KeyPair(opts.ClientCert, opts.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	transport.TLSClientConfig = tlsConfig
+	return &http.Client{Transport: transport}, nil
+}
+
+// Default returns a client with no extra TLS settings
+func Default() *http.Client {
+	client, _ := New(Options{})
+	return client
+}
+
+// ProxyFor returns the proxy that requests to rawURL go through, or nil if
+// they connect directly
+func ProxyFor(rawURL string) *url.URL {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil
+	}
+	proxy, err := http.ProxyFromEnvironment(&http.Request{URL: parsed})
+	if err != nil {
+		return nil
+	}
+	return proxy
+}
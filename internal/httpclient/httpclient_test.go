@@ -0,0 +1,70 @@
+package httpclient
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "httpclient-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	caBundle := filepath.Join(tempDir, "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caBundle, certPEM, 0644); err != nil {
+		t.Fatalf("Failed to write CA bundle: %v", err)
+	}
+	empty := filepath.Join(tempDir, "empty.pem")
+	if err := os.WriteFile(empty, []byte("not a certificate"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	testCases := []struct {
+		name        string
+		opts        Options
+		expectError bool
+		reachable   bool
+	}{
+		{"Default rejects unknown CA", Options{}, false, false},
+		{"Trusts custom CA bundle", Options{CABundle: caBundle}, false, true},
+		{"Insecure skips verification", Options{InsecureSkipVerify: true}, false, true},
+		{"Missing CA bundle", Options{CABundle: filepath.Join(tempDir, "missing.pem")}, true, false},
+		{"CA bundle without certificates", Options{CABundle: empty}, true, false},
+		{"Certificate without key", Options{ClientCert: caBundle}, true, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client, err := New(tc.opts)
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("Expected an error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Did not expect an error but got: %v", err)
+			}
+
+			resp, err := client.Get(server.URL)
+			if err == nil {
+				resp.Body.Close()
+			}
+			if reachable := err == nil; reachable != tc.reachable {
+				t.Errorf("Expected reachable %v but got error %v", tc.reachable, err)
+			}
+		})
+	}
+}
@@ -1,9 +1,11 @@
 package logger
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"strings"
 	"time"
 )
 
@@ -34,14 +36,87 @@ func (l Level) String() string {
 	}
 }
 
+// ParseLevel parses a level name such as "debug" or "WARN"
+func ParseLevel(name string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return LevelDebug, nil
+	case "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	default:
+		return LevelInfo, fmt.Errorf("invalid log level '%s' (expected debug, info, warn or error)", name)
+	}
+}
+
+// ParseLevels parses a default level optionally followed by per-component
+// levels, e.g. "info,download=debug,hooks=warn"
+func ParseLevels(spec string) (Level, map[string]Level, error) {
+	level := LevelInfo
+	components := make(map[string]Level)
+
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		component, name, ok := strings.Cut(part, "=")
+		if !ok {
+			parsed, err := ParseLevel(part)
+			if err != nil {
+				return level, nil, err
+			}
+			level = parsed
+			continue
+		}
+
+		parsed, err := ParseLevel(name)
+		if err != nil {
+			return level, nil, fmt.Errorf("component '%s': %w", component, err)
+		}
+		components[strings.TrimSpace(component)] = parsed
+	}
+
+	return level, components, nil
+}
+
+// Format selects how log entries are encoded
+type Format string
+
+// Log formats
+const (
+	FormatText Format = "text" // Human-readable lines
+	FormatJSON Format = "json" // One JSON object per line
+)
+
+// ParseFormat parses a log format name
+func ParseFormat(name string) (Format, error) {
+	switch format := Format(strings.ToLower(name)); format {
+	case FormatText, FormatJSON:
+		return format, nil
+	default:
+		return "", fmt.Errorf("invalid log format '%s' (expected text or json)", name)
+	}
+}
+
 // Options configures the logger
 type Options struct {
 	// Minimum level to log
 	Level Level
 
+	// Minimum levels for individual components, overriding Level
+	ComponentLevels map[string]Level
+
 	// Output writer (defaults to os.Stdout)
 	Output io.Writer
 
+	// Encoding of log entries (defaults to FormatText)
+	Format Format
+
 	// Whether to show timestamps
 	ShowTimestamp bool
 
@@ -51,7 +126,9 @@ type Options struct {
 
 // Logger provides logging functionality
 type Logger struct {
-	opts Options
+	opts      Options
+	component string        // Part of depman the entries come from
+	fields    []interface{} // Key/value pairs added to every entry
 }
 
 // New creates a new logger with the given options
@@ -60,6 +137,9 @@ func New(opts Options) *Logger {
 	if opts.Output == nil {
 		opts.Output = os.Stdout
 	}
+	if opts.Format == "" {
+		opts.Format = FormatText
+	}
 
 	return &Logger{
 		opts: opts,
@@ -76,13 +156,37 @@ func Default() *Logger {
 	})
 }
 
-// log logs a message at the specified level
-func (l *Logger) log(level Level, format string, args ...interface{}) {
+// enabled reports whether entries at level are written for this logger's component
+func (l *Logger) enabled(level Level) bool {
+	minimum := l.opts.Level
+	if componentLevel, ok := l.opts.ComponentLevels[l.component]; ok && l.component != "" {
+		minimum = componentLevel
+	}
+	return level >= minimum
+}
+
+// log logs a message with key/value pairs at the specified level
+func (l *Logger) log(level Level, message string, keysAndValues []interface{}) {
 	// Skip logging if level is below minimum
-	if level < l.opts.Level {
+	if !l.enabled(level) {
 		return
 	}
 
+	fields := append(append([]interface{}{}, l.fields...), keysAndValues...)
+
+	var entry string
+	if l.opts.Format == FormatJSON {
+		entry = l.encodeJSON(level, message, fields)
+	} else {
+		entry = l.encodeText(level, message, fields)
+	}
+
+	// Write log entry in a single call so concurrent entries do not interleave
+	io.WriteString(l.opts.Output, entry)
+}
+
+// encodeText renders an entry as a human-readable line
+func (l *Logger) encodeText(level Level, message string, fields []interface{}) string {
 	// Format timestamp
 	timestamp := ""
 	if l.opts.ShowTimestamp {
@@ -104,43 +208,149 @@ func (l *Logger) log(level Level, format string, args ...interface{}) {
 		}
 	}
 
-	// Format message
-	message := fmt.Sprintf(format, args...)
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s[%s] ", timestamp, levelStr)
+	if l.component != "" {
+		fmt.Fprintf(&b, "%s: ", l.component)
+	}
+	b.WriteString(message)
+	for i := 0; i < len(fields); i += 2 {
+		key, value := fieldAt(fields, i)
+		fmt.Fprintf(&b, " %s=%s", key, quoteValue(fmt.Sprint(value)))
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// encodeJSON renders an entry as a JSON object on one line
+func (l *Logger) encodeJSON(level Level, message string, fields []interface{}) string {
+	entry := map[string]interface{}{
+		"time":  time.Now().Format(time.RFC3339Nano),
+		"level": strings.ToLower(level.String()),
+		"msg":   message,
+	}
+	if l.component != "" {
+		entry["component"] = l.component
+	}
+	for i := 0; i < len(fields); i += 2 {
+		key, value := fieldAt(fields, i)
+		if err, ok := value.(error); ok {
+			value = err.Error()
+		}
+		entry[key] = value
+	}
 
-	// Write log entry
-	fmt.Fprintf(l.opts.Output, "%s[%s] %s\n", timestamp, levelStr, message)
+	data, err := json.Marshal(entry)
+	if err != nil {
+		data, _ = json.Marshal(map[string]interface{}{
+			"time":  entry["time"],
+			"level": entry["level"],
+			"msg":   message,
+			"error": fmt.Sprintf("failed to encode fields: %v", err),
+		})
+	}
+	return string(data) + "\n"
+}
+
+// fieldAt returns the key/value pair starting at index i
+func fieldAt(fields []interface{}, i int) (string, interface{}) {
+	key := fmt.Sprint(fields[i])
+	if i+1 >= len(fields) {
+		return key, "(missing)"
+	}
+	return key, fields[i+1]
+}
+
+// quoteValue quotes text values that contain spaces or quotes
+func quoteValue(s string) string {
+	if s == "" || strings.ContainsAny(s, " \t\n\"=") {
+		return fmt.Sprintf("%q", s)
+	}
+	return s
 }
 
 // Debugf logs a debug message
 func (l *Logger) Debugf(format string, args ...interface{}) {
-	l.log(LevelDebug, format, args...)
+	l.log(LevelDebug, fmt.Sprintf(format, args...), nil)
 }
 
 // Infof logs an info message
 func (l *Logger) Infof(format string, args ...interface{}) {
-	l.log(LevelInfo, format, args...)
+	l.log(LevelInfo, fmt.Sprintf(format, args...), nil)
 }
 
 // Warnf logs a warning message
 func (l *Logger) Warnf(format string, args ...interface{}) {
-	l.log(LevelWarn, format, args...)
+	l.log(LevelWarn, fmt.Sprintf(format, args...), nil)
 }
 
 // Errorf logs an error message
 func (l *Logger) Errorf(format string, args ...interface{}) {
-	l.log(LevelError, format, args...)
+	l.log(LevelError, fmt.Sprintf(format, args...), nil)
+}
+
+// Debug logs a debug message with alternating keys and values
+func (l *Logger) Debug(message string, keysAndValues ...interface{}) {
+	l.log(LevelDebug, message, keysAndValues)
+}
+
+// Info logs an info message with alternating keys and values
+func (l *Logger) Info(message string, keysAndValues ...interface{}) {
+	l.log(LevelInfo, message, keysAndValues)
+}
+
+// Warn logs a warning message with alternating keys and values
+func (l *Logger) Warn(message string, keysAndValues ...interface{}) {
+	l.log(LevelWarn, message, keysAndValues)
+}
+
+// Error logs an error message with alternating keys and values
+func (l *Logger) Error(message string, keysAndValues ...interface{}) {
+	l.log(LevelError, message, keysAndValues)
+}
+
+// With creates a new logger that adds the key/value pairs to every entry
+func (l *Logger) With(keysAndValues ...interface{}) *Logger {
+	derived := *l
+	derived.fields = append(append([]interface{}{}, l.fields...), keysAndValues...)
+	return &derived
+}
+
+// Named creates a new logger for a component, which may have its own level
+func (l *Logger) Named(component string) *Logger {
+	derived := *l
+	derived.component = component
+	return &derived
 }
 
 // WithLevel creates a new logger with the specified minimum level
 func (l *Logger) WithLevel(level Level) *Logger {
-	opts := l.opts
-	opts.Level = level
-	return New(opts)
+	derived := *l
+	derived.opts.Level = level
+	return &derived
+}
+
+// WithComponentLevels creates a new logger with per-component minimum levels
+func (l *Logger) WithComponentLevels(levels map[string]Level) *Logger {
+	derived := *l
+	derived.opts.ComponentLevels = levels
+	return &derived
 }
 
 // WithOutput creates a new logger with the specified output
 func (l *Logger) WithOutput(output io.Writer) *Logger {
-	opts := l.opts
-	opts.Output = output
-	return New(opts)
+	derived := *l
+	derived.opts.Output = output
+	return &derived
+}
+
+// WithFormat creates a new logger with the specified encoding. JSON entries
+// never contain color codes.
+func (l *Logger) WithFormat(format Format) *Logger {
+	derived := *l
+	derived.opts.Format = format
+	if format == FormatJSON {
+		derived.opts.ShowColors = false
+	}
+	return &derived
 }
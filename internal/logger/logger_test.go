@@ -0,0 +1,115 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestJSONFormat(t *testing.T) {
+	var buf bytes.Buffer
+	log := New(Options{Level: LevelInfo, Output: &buf, Format: FormatJSON}).Named("download").With("dependency", "node")
+
+	log.Info("Downloaded", "bytes", 42, "error", errors.New("none"))
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Expected a JSON line but got %q: %v", buf.String(), err)
+	}
+
+	expected := map[string]interface{}{
+		"level":      "info",
+		"msg":        "Downloaded",
+		"component":  "download",
+		"dependency": "node",
+		"bytes":      float64(42),
+		"error":      "none",
+	}
+	for key, value := range expected {
+		if entry[key] != value {
+			t.Errorf("Expected %s=%v but got %v", key, value, entry[key])
+		}
+	}
+}
+
+func TestTextFormatFields(t *testing.T) {
+	var buf bytes.Buffer
+	log := New(Options{Level: LevelInfo, Output: &buf}).Named("hooks")
+
+	log.Warn("Hook failed", "stage", "post_install", "output", "exit status 1")
+
+	expected := "[WARN] hooks: Hook failed stage=post_install output=\"exit status 1\"\n"
+	if buf.String() != expected {
+		t.Errorf("Expected %q but got %q", expected, buf.String())
+	}
+}
+
+func TestComponentLevels(t *testing.T) {
+	level, components, err := ParseLevels("warn, download=debug")
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+
+	var buf bytes.Buffer
+	log := New(Options{Level: level, ComponentLevels: components, Output: &buf})
+
+	log.Infof("dropped")
+	log.Named("hooks").Infof("dropped")
+	log.Named("download").Debugf("kept")
+
+	if strings.Count(buf.String(), "\n") != 1 || !strings.Contains(buf.String(), "kept") {
+		t.Errorf("Expected only the download debug entry but got %q", buf.String())
+	}
+
+	if _, _, err := ParseLevels("info,download=loud"); err == nil {
+		t.Errorf("Expected an error but got none")
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	dir, err := os.MkdirTemp("", "depman-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "logs", "depman.log")
+	file, err := OpenRotatingFile(path, 10, 2)
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	defer file.Close()
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := file.Write([]byte(line)); err != nil {
+			t.Fatalf("Did not expect an error but got: %v", err)
+		}
+	}
+
+	testCases := []struct {
+		path     string
+		expected string
+	}{
+		{path, "fourth\n"},
+		{path + ".1", "third\n"},
+		{path + ".2", "second\n"},
+	}
+
+	for _, tc := range testCases {
+		data, err := os.ReadFile(tc.path)
+		if err != nil {
+			t.Errorf("Expected %s to exist: %v", tc.path, err)
+			continue
+		}
+		if string(data) != tc.expected {
+			t.Errorf("Expected %s to contain %q but got %q", tc.path, tc.expected, string(data))
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("Expected only two backups to be kept")
+	}
+}
@@ -0,0 +1,287 @@
+package oci
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Media types accepted for manifests
+const (
+	MediaTypeManifest       = "application/vnd.oci.image.manifest.v1+json"
+	MediaTypeDockerManifest = "application/vnd.docker.distribution.manifest.v2+json"
+)
+
+// titleAnnotation names the file a layer holds
+const titleAnnotation = "org.opencontainers.image.title"
+
+// Reference identifies an artifact in a registry, e.g.
+// ghcr.io/org/configs:v2 or ghcr.io/org/configs@sha256:...
+type Reference struct {
+	Registry   string // Registry host, optionally with a port
+	Repository string // Repository path within the registry
+	Tag        string // Tag, if the reference is not by digest
+	Digest     string // Manifest digest ("sha256:..."), if pinned
+}
+
+// ParseReference parses a registry/repository[:tag][@digest] reference,
+// defaulting to the "latest" tag
+func ParseReference(ref string) (Reference, error) {
+	slash := strings.Index(ref, "/")
+	if slash <= 0 {
+		return Reference{}, fmt.Errorf("invalid OCI reference '%s': expected registry/repository[:tag]", ref)
+	}
+
+	r := Reference{Registry: ref[:slash]}
+	rest := ref[slash+1:]
+	if at := strings.Index(rest, "@"); at >= 0 {
+		r.Digest = rest[at+1:]
+		rest = rest[:at]
+		if !strings.HasPrefix(r.Digest, "sha256:") {
+			return Reference{}, fmt.Errorf("invalid OCI reference '%s': only sha256 digests are supported", ref)
+		}
+	}
+	if colon := strings.LastIndex(rest, ":"); colon >= 0 && !strings.Contains(rest[colon:], "/") {
+		r.Tag = rest[colon+1:]
+		rest = rest[:colon]
+	}
+	if rest == "" {
+		return Reference{}, fmt.Errorf("invalid OCI reference '%s': missing repository", ref)
+	}
+	r.Repository = rest
+	if r.Tag == "" && r.Digest == "" {
+		r.Tag = "latest"
+	}
+	return r, nil
+}
+
+// String formats the reference
+func (r Reference) String() string {
+	s := r.Registry + "/" + r.Repository
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+	return s
+}
+
+// manifest is the part of an image manifest needed to find the file layer
+type manifest struct {
+	Layers []descriptor `json:"layers"`
+}
+
+// descriptor points at a blob
+type descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+// Pull downloads the file an artifact holds and returns its content and
+// name. Artifacts with several layers must name one with the
+// org.opencontainers.image.title annotation matching accept; accept may be
+// nil to take the only layer.
+func Pull(ctx context.Context, client *http.Client, ref Reference, accept func(name string) bool) ([]byte, string, error) {
+	r := &registry{client: client, ref: ref}
+
+	reference := ref.Digest
+	if reference == "" {
+		reference = ref.Tag
+	}
+	data, err := r.get(ctx, "manifests/"+reference, MediaTypeManifest+", "+MediaTypeDockerManifest)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch manifest of %s: %w", ref, err)
+	}
+	if ref.Digest != "" {
+		if err := checkDigest(data, ref.Digest); err != nil {
+			return nil, "", fmt.Errorf("manifest of %s: %w", ref, err)
+		}
+	}
+
+	var m manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, "", fmt.Errorf("failed to parse manifest of %s: %w", ref, err)
+	}
+
+	layer, err := selectLayer(m.Layers, accept)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", ref, err)
+	}
+
+	blob, err := r.get(ctx, "blobs/"+layer.Digest, "*/*")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch %s from %s: %w", layer.Digest, ref, err)
+	}
+	if err := checkDigest(blob, layer.Digest); err != nil {
+		return nil, "", fmt.Errorf("layer of %s: %w", ref, err)
+	}
+
+	return blob, layer.Annotations[titleAnnotation], nil
+}
+
+// selectLayer picks the layer holding the file
+func selectLayer(layers []descriptor, accept func(name string) bool) (descriptor, error) {
+	if accept != nil {
+		for _, layer := range layers {
+			if name := layer.Annotations[titleAnnotation]; name != "" && accept(name) {
+				return layer, nil
+			}
+		}
+	}
+	if len(layers) == 1 {
+		return layers[0], nil
+	}
+	return descriptor{}, fmt.Errorf("expected one layer or a layer titled with a recognized file name, found %d layers", len(layers))
+}
+
+// checkDigest verifies content against a sha256 digest
+func checkDigest(data []byte, digest string) error {
+	sum := sha256.Sum256(data)
+	actual := "sha256:" + hex.EncodeToString(sum[:])
+	if !strings.EqualFold(actual, digest) {
+		return fmt.Errorf("digest mismatch: expected %s, got %s", digest, actual)
+	}
+	return nil
+}
+
+// registry talks to the distribution API of one repository, fetching an
+// anonymous or credentialed bearer token when the registry asks for one
+type registry struct {
+	client *http.Client
+	ref    Reference
+	token  string
+}
+
+// get fetches a path under /v2/<repository>/
+func (r *registry) get(ctx context.Context, path, accept string) ([]byte, error) {
+	endpoint := fmt.Sprintf("%s://%s/v2/%s/%s", scheme(r.ref.Registry), r.ref.Registry, r.ref.Repository, path)
+
+	resp, err := r.do(ctx, endpoint, accept)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized && r.token == "" {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+		if r.token, err = r.fetchToken(ctx, challenge); err != nil {
+			return nil, err
+		}
+		if resp, err = r.do(ctx, endpoint, accept); err != nil {
+			return nil, err
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("bad status: %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// do sends a GET request with the current token, if any
+func (r *registry) do(ctx context.Context, endpoint, accept string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", accept)
+	if r.token != "" {
+		req.Header.Set("Authorization", "Bearer "+r.token)
+	}
+	return r.client.Do(req)
+}
+
+// fetchToken answers a Bearer challenge by requesting a token from its realm.
+// Credentials for the realm's host are added by the client's transport.
+func (r *registry) fetchToken(ctx context.Context, challenge string) (string, error) {
+	scheme, params := parseChallenge(challenge)
+	if !strings.EqualFold(scheme, "bearer") || params["realm"] == "" {
+		return "", fmt.Errorf("registry requires authentication (%s)", challenge)
+	}
+
+	realm, err := url.Parse(params["realm"])
+	if err != nil {
+		return "", fmt.Errorf("invalid token realm: %w", err)
+	}
+	query := realm.Query()
+	if params["service"] != "" {
+		query.Set("service", params["service"])
+	}
+	scope := params["scope"]
+	if scope == "" {
+		scope = "repository:" + r.ref.Repository + ":pull"
+	}
+	query.Set("scope", scope)
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch registry token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to fetch registry token: bad status: %s", resp.Status)
+	}
+
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to parse registry token: %w", err)
+	}
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	if body.AccessToken != "" {
+		return body.AccessToken, nil
+	}
+	return "", fmt.Errorf("registry token response held no token")
+}
+
+// parseChallenge splits a WWW-Authenticate header into its scheme and parameters
+func parseChallenge(header string) (string, map[string]string) {
+	params := make(map[string]string)
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(header), " ")
+	for rest != "" {
+		var key, value string
+		key, rest, _ = strings.Cut(strings.TrimLeft(rest, " ,"), "=")
+		if strings.HasPrefix(rest, `"`) {
+			value, rest, _ = strings.Cut(rest[1:], `"`)
+		} else {
+			value, rest, _ = strings.Cut(rest, ",")
+		}
+		if key != "" {
+			params[strings.ToLower(strings.TrimSpace(key))] = value
+		}
+	}
+	return scheme, params
+}
+
+// scheme returns http for registries on loopback addresses and https otherwise
+func scheme(registry string) string {
+	host := registry
+	if h, _, err := net.SplitHostPort(registry); err == nil {
+		host = h
+	}
+	if host == "localhost" {
+		return "http"
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return "http"
+	}
+	return "https"
+}
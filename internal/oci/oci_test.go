@@ -0,0 +1,103 @@
+package oci
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseReference(t *testing.T) {
+	testCases := []struct {
+		ref       string
+		expected  Reference
+		expectErr bool
+	}{
+		{ref: "ghcr.io/org/configs:v2", expected: Reference{Registry: "ghcr.io", Repository: "org/configs", Tag: "v2"}},
+		{ref: "localhost:5000/configs", expected: Reference{Registry: "localhost:5000", Repository: "configs", Tag: "latest"}},
+		{ref: "ghcr.io/org/configs@sha256:abc", expected: Reference{Registry: "ghcr.io", Repository: "org/configs", Digest: "sha256:abc"}},
+		{ref: "configs", expectErr: true},
+		{ref: "ghcr.io/org/configs@md5:abc", expectErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.ref, func(t *testing.T) {
+			ref, err := ParseReference(tc.ref)
+			if tc.expectErr {
+				if err == nil {
+					t.Errorf("Expected an error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Did not expect an error but got: %v", err)
+			}
+			if ref != tc.expected {
+				t.Errorf("Expected %+v but got %+v", tc.expected, ref)
+			}
+		})
+	}
+}
+
+func TestPull(t *testing.T) {
+	content := []byte("dependencies: []\n")
+	sum := sha256.Sum256(content)
+	layerDigest := "sha256:" + hex.EncodeToString(sum[:])
+
+	manifestData, _ := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     MediaTypeManifest,
+		"layers": []map[string]interface{}{
+			{"mediaType": "application/yaml", "digest": "sha256:0000", "annotations": map[string]string{titleAnnotation: "README.md"}},
+			{"mediaType": "application/yaml", "digest": layerDigest, "annotations": map[string]string{titleAnnotation: "depman.yaml"}},
+		},
+	})
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			if r.URL.Query().Get("scope") != "repository:org/configs:pull" {
+				http.Error(w, "bad scope", http.StatusBadRequest)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]string{"token": "secret"})
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="test"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/v2/org/configs/manifests/v2":
+			w.Write(manifestData)
+		case "/v2/org/configs/blobs/" + layerDigest:
+			w.Write(content)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	ref := Reference{Registry: strings.TrimPrefix(server.URL, "http://"), Repository: "org/configs", Tag: "v2"}
+	data, name, err := Pull(context.Background(), server.Client(), ref, func(name string) bool {
+		return strings.HasSuffix(name, ".yaml")
+	})
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if string(data) != string(content) || name != "depman.yaml" {
+		t.Errorf("Expected depman.yaml with the layer content but got %s: %q", name, data)
+	}
+
+	manifestSum := sha256.Sum256(manifestData)
+	ref.Digest = "sha256:" + hex.EncodeToString(manifestSum[:1]) // Wrong digest
+	if _, _, err := Pull(context.Background(), server.Client(), ref, nil); err == nil {
+		t.Errorf("Expected a digest mismatch but got none")
+	}
+}
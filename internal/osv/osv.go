@@ -0,0 +1,259 @@
+package osv
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+
+	"github.com/devnadeemashraf/depman/internal/downloader"
+)
+
+// APIURL is the base URL of the OSV API; replaced in tests and for mirrors
+var APIURL = "https://api.osv.dev"
+
+// Vulnerability is an OSV advisory affecting a package version
+type Vulnerability struct {
+	ID       string   // OSV identifier, e.g. GHSA-xxxx-xxxx-xxxx
+	Aliases  []string // Other identifiers, such as CVE numbers
+	Summary  string   // One-line description
+	Severity string   // Severity label: low, medium, high, critical, or "" if unknown
+	Score    float64  // CVSS v3 base score, or 0 if none was published
+	Fixed    []string // Versions that fix the vulnerability for the queried package
+}
+
+// record is the part of an OSV vulnerability record used here
+type record struct {
+	ID       string   `json:"id"`
+	Aliases  []string `json:"aliases"`
+	Summary  string   `json:"summary"`
+	Details  string   `json:"details"`
+	Severity []struct {
+		Type  string `json:"type"`
+		Score string `json:"score"`
+	} `json:"severity"`
+	Affected []struct {
+		Package struct {
+			Ecosystem string `json:"ecosystem"`
+			Name      string `json:"name"`
+		} `json:"package"`
+		Ranges []struct {
+			Events []map[string]string `json:"events"`
+		} `json:"ranges"`
+		EcosystemSpecific struct {
+			Severity string `json:"severity"`
+		} `json:"ecosystem_specific"`
+	} `json:"affected"`
+	DatabaseSpecific struct {
+		Severity string `json:"severity"`
+	} `json:"database_specific"`
+}
+
+// Query returns the vulnerabilities OSV knows for a package version,
+// querying the API with client (http.DefaultClient if nil)
+func Query(ctx context.Context, client *http.Client, ecosystem, name, version string) ([]Vulnerability, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	var vulns []Vulnerability
+	pageToken := ""
+	for {
+		query := map[string]interface{}{
+			"version": version,
+			"package": map[string]string{"ecosystem": ecosystem, "name": name},
+		}
+		if pageToken != "" {
+			query["page_token"] = pageToken
+		}
+		body, err := json.Marshal(query)
+		if err != nil {
+			return nil, err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, APIURL+"/v1/query", bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query OSV for %s: %w", name, err)
+		}
+		var result struct {
+			Vulns         []record `json:"vulns"`
+			NextPageToken string   `json:"next_page_token"`
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to query OSV for %s: %w", name,
+				&downloader.StatusError{URL: req.URL.String(), StatusCode: resp.StatusCode, Status: resp.Status})
+		}
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse OSV response for %s: %w", name, err)
+		}
+
+		for _, r := range result.Vulns {
+			vulns = append(vulns, r.vulnerability(ecosystem, name))
+		}
+		if result.NextPageToken == "" {
+			return vulns, nil
+		}
+		pageToken = result.NextPageToken
+	}
+}
+
+// vulnerability summarizes a record for the queried package
+func (r record) vulnerability(ecosystem, name string) Vulnerability {
+	v := Vulnerability{ID: r.ID, Aliases: r.Aliases, Summary: r.Summary}
+	if v.Summary == "" {
+		v.Summary, _, _ = strings.Cut(r.Details, "\n")
+	}
+
+	// A CVSS v3 vector is the most precise severity; labels are the fallback
+	for _, severity := range r.Severity {
+		if severity.Type != "CVSS_V3" {
+			continue
+		}
+		if score, err := BaseScore(severity.Score); err == nil {
+			v.Score = score
+			v.Severity = ScoreSeverity(score)
+		}
+	}
+
+	label := r.DatabaseSpecific.Severity
+	for _, affected := range r.Affected {
+		if !strings.EqualFold(affected.Package.Ecosystem, ecosystem) || affected.Package.Name != name {
+			continue
+		}
+		if label == "" {
+			label = affected.EcosystemSpecific.Severity
+		}
+		for _, rng := range affected.Ranges {
+			for _, event := range rng.Events {
+				if fixed := event["fixed"]; fixed != "" {
+					v.Fixed = append(v.Fixed, fixed)
+				}
+			}
+		}
+	}
+	if v.Severity == "" {
+		v.Severity = normalizeLabel(label)
+	}
+	return v
+}
+
+// normalizeLabel maps advisory severity labels to low, medium, high or critical
+func normalizeLabel(label string) string {
+	switch strings.ToLower(label) {
+	case "low":
+		return "low"
+	case "medium", "moderate":
+		return "medium"
+	case "high", "important":
+		return "high"
+	case "critical":
+		return "critical"
+	default:
+		return ""
+	}
+}
+
+// ScoreSeverity returns the CVSS v3 qualitative rating of a base score
+func ScoreSeverity(score float64) string {
+	switch {
+	case score >= 9:
+		return "critical"
+	case score >= 7:
+		return "high"
+	case score >= 4:
+		return "medium"
+	case score > 0:
+		return "low"
+	default:
+		return ""
+	}
+}
+
+// cvssWeights are the CVSS v3 metric weights; PR has separate weights when the scope changes
+var cvssWeights = map[string]map[string]float64{
+	"AV": {"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2},
+	"AC": {"L": 0.77, "H": 0.44},
+	"PR": {"N": 0.85, "L": 0.62, "H": 0.27},
+	"UI": {"N": 0.85, "R": 0.62},
+	"C":  {"H": 0.56, "L": 0.22, "N": 0},
+	"I":  {"H": 0.56, "L": 0.22, "N": 0},
+	"A":  {"H": 0.56, "L": 0.22, "N": 0},
+}
+
+// BaseScore computes the base score of a CVSS v3.0 or v3.1 vector such as
+// "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"
+func BaseScore(vector string) (float64, error) {
+	parts := strings.Split(vector, "/")
+	if len(parts) == 0 || !strings.HasPrefix(parts[0], "CVSS:3.") {
+		return 0, fmt.Errorf("unsupported CVSS vector: %s", vector)
+	}
+
+	metrics := make(map[string]string)
+	for _, part := range parts[1:] {
+		key, value, ok := strings.Cut(part, ":")
+		if !ok {
+			return 0, fmt.Errorf("invalid CVSS metric '%s'", part)
+		}
+		metrics[key] = value
+	}
+
+	changed := metrics["S"] == "C"
+	if metrics["S"] != "U" && !changed {
+		return 0, fmt.Errorf("invalid CVSS scope in %s", vector)
+	}
+
+	w := make(map[string]float64)
+	for key, weights := range cvssWeights {
+		weight, ok := weights[metrics[key]]
+		if !ok {
+			return 0, fmt.Errorf("missing or invalid CVSS metric %s in %s", key, vector)
+		}
+		w[key] = weight
+	}
+	if changed {
+		switch metrics["PR"] {
+		case "L":
+			w["PR"] = 0.68
+		case "H":
+			w["PR"] = 0.5
+		}
+	}
+
+	iss := 1 - (1-w["C"])*(1-w["I"])*(1-w["A"])
+	impact := 6.42 * iss
+	if changed {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	}
+	if impact <= 0 {
+		return 0, nil
+	}
+
+	exploitability := 8.22 * w["AV"] * w["AC"] * w["PR"] * w["UI"]
+	score := impact + exploitability
+	if changed {
+		score *= 1.08
+	}
+	return roundUp(math.Min(score, 10)), nil
+}
+
+// roundUp rounds up to one decimal as defined by CVSS v3.1, avoiding
+// floating-point artifacts
+func roundUp(value float64) float64 {
+	scaled := int(math.Round(value * 100000))
+	if scaled%10000 == 0 {
+		return float64(scaled) / 100000
+	}
+	return float64(scaled/10000+1) / 10
+}
@@ -0,0 +1,102 @@
+package osv
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBaseScore(t *testing.T) {
+	testCases := []struct {
+		vector    string
+		expected  float64
+		expectErr bool
+	}{
+		{vector: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", expected: 9.8},
+		{vector: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H", expected: 10.0},
+		{vector: "CVSS:3.1/AV:L/AC:L/PR:L/UI:N/S:U/C:H/I:N/A:N", expected: 5.5},
+		{vector: "CVSS:3.0/AV:N/AC:H/PR:N/UI:R/S:U/C:L/I:N/A:N", expected: 3.1},
+		{vector: "CVSS:3.1/AV:N/AC:L/PR:L/UI:R/S:C/C:L/I:L/A:N", expected: 5.4},
+		{vector: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:N", expected: 0},
+		{vector: "CVSS:2.0/AV:N/AC:L/Au:N/C:P/I:P/A:P", expectErr: true},
+		{vector: "CVSS:3.1/AV:N/AC:L", expectErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.vector, func(t *testing.T) {
+			score, err := BaseScore(tc.vector)
+			if tc.expectErr {
+				if err == nil {
+					t.Errorf("Expected an error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Did not expect an error but got: %v", err)
+			}
+			if score != tc.expected {
+				t.Errorf("Expected %.1f but got %.1f", tc.expected, score)
+			}
+		})
+	}
+}
+
+func TestQuery(t *testing.T) {
+	pages := map[string]string{
+		"": `{"vulns": [{
+			"id": "GHSA-1111",
+			"aliases": ["CVE-2024-0001"],
+			"summary": "Remote code execution",
+			"severity": [{"type": "CVSS_V3", "score": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}],
+			"affected": [
+				{"package": {"ecosystem": "Go", "name": "example.com/other"}, "ranges": [{"events": [{"introduced": "0"}, {"fixed": "9.9.9"}]}]},
+				{"package": {"ecosystem": "Go", "name": "example.com/tool"}, "ranges": [{"events": [{"introduced": "0"}, {"fixed": "1.2.4"}]}]}
+			]
+		}], "next_page_token": "page2"}`,
+		"page2": `{"vulns": [{
+			"id": "GO-2222",
+			"details": "Denial of service\nwith a long explanation",
+			"database_specific": {"severity": "MODERATE"},
+			"affected": [{"package": {"ecosystem": "Go", "name": "example.com/tool"}}]
+		}]}`,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var query struct {
+			Version   string `json:"version"`
+			PageToken string `json:"page_token"`
+			Package   struct {
+				Ecosystem string `json:"ecosystem"`
+				Name      string `json:"name"`
+			} `json:"package"`
+		}
+		json.NewDecoder(r.Body).Decode(&query)
+		if r.URL.Path != "/v1/query" || query.Version != "1.2.3" || query.Package.Name != "example.com/tool" {
+			http.Error(w, "unexpected query", http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(pages[query.PageToken]))
+	}))
+	defer server.Close()
+
+	originalURL := APIURL
+	APIURL = server.URL
+	defer func() { APIURL = originalURL }()
+
+	vulns, err := Query(context.Background(), nil, "Go", "example.com/tool", "1.2.3")
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if len(vulns) != 2 {
+		t.Fatalf("Expected 2 vulnerabilities across both pages but got %d", len(vulns))
+	}
+
+	if v := vulns[0]; v.Severity != "critical" || v.Score != 9.8 || len(v.Fixed) != 1 || v.Fixed[0] != "1.2.4" {
+		t.Errorf("Expected a critical vulnerability fixed in 1.2.4 but got %+v", v)
+	}
+	if v := vulns[1]; v.Severity != "medium" || v.Summary != "Denial of service" {
+		t.Errorf("Expected a medium vulnerability summarized from its details but got %+v", v)
+	}
+}
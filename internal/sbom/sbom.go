@@ -0,0 +1,268 @@
+package sbom
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// noAssertion is the SPDX value for information that was not determined
+const noAssertion = "NOASSERTION"
+
+// Document is a format-independent bill of materials
+type Document struct {
+	Name        string      // Name of the application the dependencies belong to
+	Tool        string      // Name of the generating tool
+	ToolVersion string      // Version of the generating tool
+	Created     time.Time   // When the document was generated
+	Components  []Component // Components, in a stable order
+}
+
+// Component is a single piece of software in the bill of materials
+type Component struct {
+	Name        string   // Name of the component
+	Version     string   // Resolved version
+	Description string   // Human-readable description
+	Purl        string   // Package URL identifying the component
+	DownloadURL string   // Where the component was downloaded from, if known
+	Checksum    string   // Checksum of the downloaded artifact ("algorithm:hash"), if known
+	License     string   // SPDX license expression, if known
+	DependsOn   []string // Names of components this one requires
+}
+
+// uuid returns a random RFC 4122 version 4 UUID
+func uuid() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// splitChecksum splits an "algorithm:hash" checksum
+func splitChecksum(checksum string) (string, string, bool) {
+	algorithm, digest, ok := strings.Cut(checksum, ":")
+	return strings.ToLower(algorithm), strings.ToLower(digest), ok && digest != ""
+}
+
+// encode writes v as indented JSON
+func encode(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// cycloneDX is a CycloneDX 1.5 JSON document
+type cycloneDX struct {
+	BOMFormat    string                `json:"bomFormat"`
+	SpecVersion  string                `json:"specVersion"`
+	SerialNumber string                `json:"serialNumber"`
+	Version      int                   `json:"version"`
+	Metadata     cycloneDXMetadata     `json:"metadata"`
+	Components   []cycloneDXComponent  `json:"components"`
+	Dependencies []cycloneDXDependency `json:"dependencies"`
+}
+
+type cycloneDXMetadata struct {
+	Timestamp string `json:"timestamp"`
+	Tools     struct {
+		Components []cycloneDXComponent `json:"components"`
+	} `json:"tools"`
+	Component *cycloneDXComponent `json:"component,omitempty"`
+}
+
+type cycloneDXComponent struct {
+	Type               string              `json:"type"`
+	BOMRef             string              `json:"bom-ref,omitempty"`
+	Name               string              `json:"name"`
+	Version            string              `json:"version,omitempty"`
+	Description        string              `json:"description,omitempty"`
+	Purl               string              `json:"purl,omitempty"`
+	Hashes             []cycloneDXHash     `json:"hashes,omitempty"`
+	Licenses           []cycloneDXLicense  `json:"licenses,omitempty"`
+	ExternalReferences []cycloneDXExternal `json:"externalReferences,omitempty"`
+}
+
+type cycloneDXHash struct {
+	Algorithm string `json:"alg"`
+	Content   string `json:"content"`
+}
+
+type cycloneDXLicense struct {
+	Expression string `json:"expression"`
+}
+
+type cycloneDXExternal struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+type cycloneDXDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn"`
+}
+
+// cycloneDXAlgorithms maps checksum algorithms to CycloneDX hash names
+var cycloneDXAlgorithms = map[string]string{"sha256": "SHA-256", "sha512": "SHA-512"}
+
+// WriteCycloneDX writes the document as CycloneDX 1.5 JSON
+func WriteCycloneDX(w io.Writer, doc Document) error {
+	bom := cycloneDX{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: "urn:uuid:" + uuid(),
+		Version:      1,
+		Components:   []cycloneDXComponent{},
+		Dependencies: []cycloneDXDependency{},
+	}
+	bom.Metadata.Timestamp = doc.Created.UTC().Format(time.RFC3339)
+	bom.Metadata.Tools.Components = []cycloneDXComponent{{Type: "application", Name: doc.Tool, Version: doc.ToolVersion}}
+	if doc.Name != "" {
+		bom.Metadata.Component = &cycloneDXComponent{Type: "application", Name: doc.Name}
+	}
+
+	for _, c := range doc.Components {
+		component := cycloneDXComponent{
+			Type:        "application",
+			BOMRef:      c.Name,
+			Name:        c.Name,
+			Version:     c.Version,
+			Description: c.Description,
+			Purl:        c.Purl,
+		}
+		if algorithm, digest, ok := splitChecksum(c.Checksum); ok && cycloneDXAlgorithms[algorithm] != "" {
+			component.Hashes = []cycloneDXHash{{Algorithm: cycloneDXAlgorithms[algorithm], Content: digest}}
+		}
+		if c.License != "" {
+			component.Licenses = []cycloneDXLicense{{Expression: c.License}}
+		}
+		if c.DownloadURL != "" {
+			component.ExternalReferences = []cycloneDXExternal{{Type: "distribution", URL: c.DownloadURL}}
+		}
+		bom.Components = append(bom.Components, component)
+
+		dependsOn := append([]string{}, c.DependsOn...)
+		bom.Dependencies = append(bom.Dependencies, cycloneDXDependency{Ref: c.Name, DependsOn: dependsOn})
+	}
+
+	return encode(w, bom)
+}
+
+// spdx is an SPDX 2.3 JSON document
+type spdx struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	Name             string            `json:"name"`
+	SPDXID           string            `json:"SPDXID"`
+	VersionInfo      string            `json:"versionInfo,omitempty"`
+	Description      string            `json:"description,omitempty"`
+	DownloadLocation string            `json:"downloadLocation"`
+	FilesAnalyzed    bool              `json:"filesAnalyzed"`
+	Checksums        []spdxChecksum    `json:"checksums,omitempty"`
+	LicenseConcluded string            `json:"licenseConcluded"`
+	LicenseDeclared  string            `json:"licenseDeclared"`
+	CopyrightText    string            `json:"copyrightText"`
+	ExternalRefs     []spdxExternalRef `json:"externalRefs,omitempty"`
+}
+
+type spdxChecksum struct {
+	Algorithm string `json:"algorithm"`
+	Value     string `json:"checksumValue"`
+}
+
+type spdxExternalRef struct {
+	Category string `json:"referenceCategory"`
+	Type     string `json:"referenceType"`
+	Locator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	Element string `json:"spdxElementId"`
+	Type    string `json:"relationshipType"`
+	Related string `json:"relatedSpdxElement"`
+}
+
+// spdxInvalid matches characters not allowed in SPDX identifiers
+var spdxInvalid = regexp.MustCompile(`[^A-Za-z0-9.-]`)
+
+// spdxID returns the SPDX identifier of a component
+func spdxID(name string) string {
+	return "SPDXRef-Package-" + spdxInvalid.ReplaceAllString(name, "-")
+}
+
+// WriteSPDX writes the document as SPDX 2.3 JSON
+func WriteSPDX(w io.Writer, doc Document) error {
+	name := doc.Name
+	if name == "" {
+		name = "dependencies"
+	}
+	tool := doc.Tool
+	if doc.ToolVersion != "" {
+		tool += "-" + doc.ToolVersion
+	}
+
+	bom := spdx{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              name,
+		DocumentNamespace: fmt.Sprintf("https://spdx.org/spdxdocs/%s-%s", spdxInvalid.ReplaceAllString(name, "-"), uuid()),
+		CreationInfo: spdxCreationInfo{
+			Created:  doc.Created.UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: " + tool},
+		},
+		Packages:      []spdxPackage{},
+		Relationships: []spdxRelationship{},
+	}
+
+	for _, c := range doc.Components {
+		pkg := spdxPackage{
+			Name:             c.Name,
+			SPDXID:           spdxID(c.Name),
+			VersionInfo:      c.Version,
+			Description:      c.Description,
+			DownloadLocation: noAssertion,
+			LicenseConcluded: noAssertion,
+			LicenseDeclared:  noAssertion,
+			CopyrightText:    noAssertion,
+		}
+		if c.DownloadURL != "" {
+			pkg.DownloadLocation = c.DownloadURL
+		}
+		if algorithm, digest, ok := splitChecksum(c.Checksum); ok {
+			pkg.Checksums = []spdxChecksum{{Algorithm: strings.ToUpper(algorithm), Value: digest}}
+		}
+		if c.License != "" {
+			pkg.LicenseDeclared = c.License
+		}
+		if c.Purl != "" {
+			pkg.ExternalRefs = []spdxExternalRef{{Category: "PACKAGE-MANAGER", Type: "purl", Locator: c.Purl}}
+		}
+		bom.Packages = append(bom.Packages, pkg)
+
+		bom.Relationships = append(bom.Relationships, spdxRelationship{Element: "SPDXRef-DOCUMENT", Type: "DESCRIBES", Related: pkg.SPDXID})
+		for _, dep := range c.DependsOn {
+			bom.Relationships = append(bom.Relationships, spdxRelationship{Element: pkg.SPDXID, Type: "DEPENDS_ON", Related: spdxID(dep)})
+		}
+	}
+
+	return encode(w, bom)
+}
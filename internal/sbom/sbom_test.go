@@ -0,0 +1,87 @@
+package sbom
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func testDocument() Document {
+	return Document{
+		Name:    "Test App",
+		Tool:    "depman",
+		Created: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Components: []Component{
+			{Name: "runtime", Version: "1.0.0", Purl: "pkg:generic/runtime@1.0.0"},
+			{
+				Name:        "tool",
+				Version:     "2.1.0",
+				Purl:        "pkg:github/owner/tool@2.1.0",
+				DownloadURL: "https://example.com/tool.tar.gz",
+				Checksum:    "sha256:ABC123",
+				License:     "MIT OR Apache-2.0",
+				DependsOn:   []string{"runtime"},
+			},
+		},
+	}
+}
+
+func TestWriteCycloneDX(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCycloneDX(&buf, testDocument()); err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+
+	var bom cycloneDX
+	if err := json.Unmarshal(buf.Bytes(), &bom); err != nil {
+		t.Fatalf("Failed to parse output: %v", err)
+	}
+	if bom.BOMFormat != "CycloneDX" || bom.SpecVersion != "1.5" || len(bom.Components) != 2 {
+		t.Fatalf("Expected a CycloneDX 1.5 document with 2 components but got %s", buf.String())
+	}
+
+	tool := bom.Components[1]
+	if len(tool.Hashes) != 1 || tool.Hashes[0].Algorithm != "SHA-256" || tool.Hashes[0].Content != "abc123" {
+		t.Errorf("Expected a SHA-256 hash but got %+v", tool.Hashes)
+	}
+	if len(tool.Licenses) != 1 || tool.Licenses[0].Expression != "MIT OR Apache-2.0" {
+		t.Errorf("Expected the license expression but got %+v", tool.Licenses)
+	}
+	if len(bom.Dependencies) != 2 || len(bom.Dependencies[1].DependsOn) != 1 || bom.Dependencies[1].DependsOn[0] != "runtime" {
+		t.Errorf("Expected tool to depend on runtime but got %+v", bom.Dependencies)
+	}
+}
+
+func TestWriteSPDX(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteSPDX(&buf, testDocument()); err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+
+	var bom spdx
+	if err := json.Unmarshal(buf.Bytes(), &bom); err != nil {
+		t.Fatalf("Failed to parse output: %v", err)
+	}
+	if bom.SPDXVersion != "SPDX-2.3" || len(bom.Packages) != 2 {
+		t.Fatalf("Expected an SPDX 2.3 document with 2 packages but got %s", buf.String())
+	}
+
+	runtime, tool := bom.Packages[0], bom.Packages[1]
+	if runtime.DownloadLocation != noAssertion || runtime.LicenseDeclared != noAssertion {
+		t.Errorf("Expected unknown fields to be NOASSERTION but got %+v", runtime)
+	}
+	if tool.LicenseDeclared != "MIT OR Apache-2.0" || len(tool.Checksums) != 1 || tool.Checksums[0].Algorithm != "SHA256" {
+		t.Errorf("Expected the license and checksum of tool but got %+v", tool)
+	}
+
+	found := false
+	for _, rel := range bom.Relationships {
+		if rel.Element == "SPDXRef-Package-tool" && rel.Type == "DEPENDS_ON" && rel.Related == "SPDXRef-Package-runtime" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected a DEPENDS_ON relationship but got %+v", bom.Relationships)
+	}
+}
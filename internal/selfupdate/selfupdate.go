@@ -0,0 +1,175 @@
+package selfupdate
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/devnadeemashraf/depman/internal/github"
+)
+
+// Repo is the GitHub repository depman releases are published to
+const Repo = "devnadeemashraf/depman"
+
+// signatureSuffix is appended to a binary's asset name for its signature asset
+const signatureSuffix = ".sig"
+
+// Channel selects which releases an update may install
+type Channel string
+
+const (
+	ChannelStable Channel = "stable" // Published releases only
+	ChannelBeta   Channel = "beta"   // Prereleases too
+)
+
+// ParseChannel parses a release channel name
+func ParseChannel(name string) (Channel, error) {
+	switch channel := Channel(strings.ToLower(name)); channel {
+	case ChannelStable, ChannelBeta:
+		return channel, nil
+	default:
+		return "", fmt.Errorf("invalid channel '%s' (expected stable or beta)", name)
+	}
+}
+
+// ErrNoRelease is returned when the channel has no release for the platform
+var ErrNoRelease = errors.New("no release found")
+
+// Release is a depman release the current platform can update to
+type Release struct {
+	Version      *semver.Version // Version of the release
+	Tag          string          // Git tag of the release
+	BinaryURL    string          // Download URL of the binary for the platform
+	SignatureURL string          // Download URL of the binary's signature
+}
+
+// AssetName returns the name of the release asset holding the binary for a
+// platform, e.g. depman_linux_amd64 or depman_windows_amd64.exe
+func AssetName(goos, goarch string) string {
+	name := fmt.Sprintf("depman_%s_%s", goos, goarch)
+	if goos == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// Latest returns the newest release on a channel that has a signed binary for
+// the platform, querying the API with client (http.DefaultClient if nil)
+func Latest(ctx context.Context, client *http.Client, channel Channel, goos, goarch, token string) (*Release, error) {
+	releases, err := github.ListReleases(ctx, client, Repo, token)
+	if err != nil {
+		return nil, err
+	}
+
+	asset := AssetName(goos, goarch)
+	var latest *Release
+	for _, r := range releases {
+		if r.Draft || (r.Prerelease && channel != ChannelBeta) {
+			continue
+		}
+		version, err := semver.NewVersion(r.TagName)
+		if err != nil || (version.Prerelease() != "" && channel != ChannelBeta) {
+			continue
+		}
+		if latest != nil && !version.GreaterThan(latest.Version) {
+			continue
+		}
+
+		candidate := &Release{Version: version, Tag: r.TagName}
+		for _, a := range r.Assets {
+			switch a.Name {
+			case asset:
+				candidate.BinaryURL = a.DownloadURL
+			case asset + signatureSuffix:
+				candidate.SignatureURL = a.DownloadURL
+			}
+		}
+		if candidate.BinaryURL != "" && candidate.SignatureURL != "" {
+			latest = candidate
+		}
+	}
+
+	if latest == nil {
+		return nil, fmt.Errorf("%w on the %s channel for %s", ErrNoRelease, channel, asset)
+	}
+	return latest, nil
+}
+
+// ParsePublicKey decodes a base64-encoded Ed25519 public key
+func ParsePublicKey(encoded string) (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("invalid release signing key: %w", err)
+	}
+	if len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid release signing key: expected %d bytes but got %d", ed25519.PublicKeySize, len(key))
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// VerifyFile checks the Ed25519 signature over a file. The signature may be
+// raw or base64-encoded.
+func VerifyFile(path string, signature []byte, key ed25519.PublicKey) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	if len(signature) != ed25519.SignatureSize {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+		if err != nil {
+			return fmt.Errorf("invalid signature: %w", err)
+		}
+		signature = decoded
+	}
+	if len(signature) != ed25519.SignatureSize || !ed25519.Verify(key, data, signature) {
+		return errors.New("signature verification failed: the release was not signed with the depman release key")
+	}
+	return nil
+}
+
+// Replace atomically swaps the executable at exe for the file at path, which
+// must be on the same file system. A running executable cannot be
+// overwritten on Windows, so it is moved aside first and removed on the next
+// update.
+func Replace(exe, path string) error {
+	if err := os.Chmod(path, 0755); err != nil {
+		return fmt.Errorf("failed to make the new binary executable: %w", err)
+	}
+
+	if runtime.GOOS != "windows" {
+		if err := os.Rename(path, exe); err != nil {
+			return fmt.Errorf("failed to replace %s: %w", exe, err)
+		}
+		return nil
+	}
+
+	old := exe + ".old"
+	os.Remove(old)
+	if err := os.Rename(exe, old); err != nil {
+		return fmt.Errorf("failed to move %s aside: %w", exe, err)
+	}
+	if err := os.Rename(path, exe); err != nil {
+		os.Rename(old, exe)
+		return fmt.Errorf("failed to replace %s: %w", exe, err)
+	}
+	return nil
+}
+
+// StagingDir creates a temporary directory next to exe, so the new binary can
+// be renamed over it atomically
+func StagingDir(exe string) (string, error) {
+	dir, err := os.MkdirTemp(filepath.Dir(exe), ".depman-update-*")
+	if err != nil {
+		return "", fmt.Errorf("cannot write to %s (re-run with the permissions that installed depman): %w", filepath.Dir(exe), err)
+	}
+	return dir, nil
+}
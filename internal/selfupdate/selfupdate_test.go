@@ -0,0 +1,155 @@
+package selfupdate
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/devnadeemashraf/depman/internal/github"
+)
+
+func TestLatest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `[
+			{"tag_name": "v1.3.0-beta.1", "prerelease": true, "assets": [
+				{"name": "depman_linux_amd64", "browser_download_url": "https://example.com/beta"},
+				{"name": "depman_linux_amd64.sig", "browser_download_url": "https://example.com/beta.sig"}]},
+			{"tag_name": "v1.2.0", "assets": [
+				{"name": "depman_linux_amd64", "browser_download_url": "https://example.com/1.2.0"},
+				{"name": "depman_linux_amd64.sig", "browser_download_url": "https://example.com/1.2.0.sig"}]},
+			{"tag_name": "v1.2.1", "assets": [
+				{"name": "depman_linux_amd64", "browser_download_url": "https://example.com/unsigned"}]},
+			{"tag_name": "v2.0.0", "draft": true, "assets": [
+				{"name": "depman_linux_amd64", "browser_download_url": "https://example.com/draft"},
+				{"name": "depman_linux_amd64.sig", "browser_download_url": "https://example.com/draft.sig"}]}
+		]`)
+	}))
+	defer server.Close()
+
+	originalURL := github.APIURL
+	github.APIURL = server.URL
+	defer func() { github.APIURL = originalURL }()
+
+	testCases := []struct {
+		name     string
+		channel  Channel
+		goos     string
+		expected string
+	}{
+		{name: "Stable skips prereleases, drafts and unsigned releases", channel: ChannelStable, goos: "linux", expected: "v1.2.0"},
+		{name: "Beta includes prereleases", channel: ChannelBeta, goos: "linux", expected: "v1.3.0-beta.1"},
+		{name: "No binary for the platform", channel: ChannelStable, goos: "windows"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			release, err := Latest(context.Background(), nil, tc.channel, tc.goos, "amd64", "")
+			if tc.expected == "" {
+				if !errors.Is(err, ErrNoRelease) {
+					t.Errorf("Expected ErrNoRelease but got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Did not expect an error but got: %v", err)
+			}
+			if release.Tag != tc.expected || release.SignatureURL == "" {
+				t.Errorf("Expected signed release %s but got %+v", tc.expected, release)
+			}
+		})
+	}
+}
+
+func TestVerifyFile(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "depman-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	otherKey, _, _ := ed25519.GenerateKey(rand.Reader)
+
+	data := []byte("binary")
+	path := filepath.Join(tempDir, "depman")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("Failed to write binary: %v", err)
+	}
+	signature := ed25519.Sign(privateKey, data)
+
+	key, err := ParsePublicKey(base64.StdEncoding.EncodeToString(publicKey))
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+
+	testCases := []struct {
+		name        string
+		signature   []byte
+		key         ed25519.PublicKey
+		expectError bool
+	}{
+		{name: "Raw signature", signature: signature, key: key},
+		{name: "Base64 signature", signature: []byte(base64.StdEncoding.EncodeToString(signature) + "\n"), key: key},
+		{name: "Other key", signature: signature, key: otherKey, expectError: true},
+		{name: "Garbage", signature: []byte("not a signature"), key: key, expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := VerifyFile(path, tc.signature, tc.key)
+			if tc.expectError && err == nil {
+				t.Error("Expected an error but got none")
+			}
+			if !tc.expectError && err != nil {
+				t.Errorf("Did not expect an error but got: %v", err)
+			}
+		})
+	}
+
+	if _, err := ParsePublicKey("c2hvcnQ="); err == nil {
+		t.Error("Expected an error for a short key but got none")
+	}
+}
+
+func TestReplace(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "depman-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	exe := filepath.Join(tempDir, "depman")
+	if err := os.WriteFile(exe, []byte("old"), 0755); err != nil {
+		t.Fatalf("Failed to write executable: %v", err)
+	}
+
+	dir, err := StagingDir(exe)
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	staged := filepath.Join(dir, "depman.new")
+	if err := os.WriteFile(staged, []byte("new"), 0644); err != nil {
+		t.Fatalf("Failed to write new binary: %v", err)
+	}
+
+	if err := Replace(exe, staged); err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if data, _ := os.ReadFile(exe); string(data) != "new" {
+		t.Errorf("Expected the executable to be replaced but got %q", data)
+	}
+	if info, err := os.Stat(exe); err != nil || info.Mode().Perm()&0100 == 0 {
+		t.Errorf("Expected the new executable to be executable")
+	}
+}
@@ -0,0 +1,532 @@
+// Package telemetry records trace spans and metrics and exports them to an
+// OpenTelemetry collector with OTLP over HTTP, using the JSON encoding.
+// Everything is safe to call on a nil *Provider, which records nothing.
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// scopeName identifies depman as the instrumentation scope of exported data
+const scopeName = "github.com/devnadeemashraf/depman"
+
+// defaultTimeout limits each export unless OTEL_EXPORTER_OTLP_TIMEOUT is set
+const defaultTimeout = 10 * time.Second
+
+// Attribute is a key and string value attached to a span, metric point or resource
+type Attribute struct {
+	Key   string
+	Value string
+}
+
+// String returns an attribute
+func String(key, value string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Config says where and how telemetry is exported
+type Config struct {
+	TracesURL  string            // OTLP/HTTP traces endpoint, or "" to not export traces
+	MetricsURL string            // OTLP/HTTP metrics endpoint, or "" to not export metrics
+	Headers    map[string]string // Headers sent with every export, e.g. an API key
+	Timeout    time.Duration     // Maximum time per export (default 10s)
+	Resource   []Attribute       // Attributes describing the process, e.g. service.name
+	Parent     string            // W3C traceparent the recorded spans continue, if any
+}
+
+// ConfigFromEnv reads the standard OpenTelemetry exporter variables using
+// getenv. It returns nil if no OTLP endpoint is configured or the SDK is
+// disabled, and an error for settings depman cannot honor.
+func ConfigFromEnv(getenv func(string) string) (*Config, error) {
+	if strings.EqualFold(getenv("OTEL_SDK_DISABLED"), "true") {
+		return nil, nil
+	}
+	switch protocol := getenv("OTEL_EXPORTER_OTLP_PROTOCOL"); protocol {
+	case "", "http/json", "http/protobuf":
+		// Collectors accept JSON on the OTLP/HTTP endpoints either way
+	default:
+		return nil, fmt.Errorf("unsupported OTLP protocol %s (only OTLP over HTTP is supported)", protocol)
+	}
+
+	config := &Config{
+		TracesURL:  signalURL(getenv, "TRACES", "/v1/traces"),
+		MetricsURL: signalURL(getenv, "METRICS", "/v1/metrics"),
+		Timeout:    defaultTimeout,
+		Parent:     getenv("TRACEPARENT"),
+	}
+	if config.TracesURL == "" && config.MetricsURL == "" {
+		return nil, nil
+	}
+
+	headers, err := parseList(getenv("OTEL_EXPORTER_OTLP_HEADERS"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid OTEL_EXPORTER_OTLP_HEADERS: %w", err)
+	}
+	config.Headers = make(map[string]string, len(headers))
+	for _, header := range headers {
+		config.Headers[header.Key] = header.Value
+	}
+
+	if timeout := getenv("OTEL_EXPORTER_OTLP_TIMEOUT"); timeout != "" {
+		ms, err := strconv.Atoi(timeout)
+		if err != nil || ms <= 0 {
+			return nil, fmt.Errorf("invalid OTEL_EXPORTER_OTLP_TIMEOUT: %s", timeout)
+		}
+		config.Timeout = time.Duration(ms) * time.Millisecond
+	}
+
+	if config.Resource, err = parseList(getenv("OTEL_RESOURCE_ATTRIBUTES")); err != nil {
+		return nil, fmt.Errorf("invalid OTEL_RESOURCE_ATTRIBUTES: %w", err)
+	}
+	service := getenv("OTEL_SERVICE_NAME")
+	if service == "" {
+		service = "depman"
+	}
+	config.Resource = append(config.Resource, String("service.name", service))
+
+	return config, nil
+}
+
+// signalURL returns the endpoint for one signal: the signal's own endpoint,
+// else path appended to the shared one, or "" if the signal's exporter is "none"
+func signalURL(getenv func(string) string, signal, path string) string {
+	if getenv("OTEL_"+signal+"_EXPORTER") == "none" {
+		return ""
+	}
+	if endpoint := getenv("OTEL_EXPORTER_OTLP_" + signal + "_ENDPOINT"); endpoint != "" {
+		return endpoint
+	}
+	if endpoint := getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); endpoint != "" {
+		return strings.TrimSuffix(endpoint, "/") + path
+	}
+	return ""
+}
+
+// parseList parses a comma-separated list of URL-encoded key=value pairs
+func parseList(list string) ([]Attribute, error) {
+	var attrs []Attribute
+	for _, pair := range strings.Split(list, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("missing '=' in '%s'", pair)
+		}
+		decoded, err := url.PathUnescape(strings.TrimSpace(value))
+		if err != nil {
+			return nil, err
+		}
+		attrs = append(attrs, String(strings.TrimSpace(key), decoded))
+	}
+	return attrs, nil
+}
+
+// Provider collects spans and metric values until they are flushed
+type Provider struct {
+	config  Config
+	traceID string // Trace continued from Config.Parent, if any
+	parent  string // Span of Config.Parent root spans are children of
+
+	mu      sync.Mutex
+	spans   []*Span
+	points  map[string]*point
+	started time.Time // Start of the current metrics interval
+}
+
+// New returns a provider exporting as config says
+func New(config Config) *Provider {
+	if config.Timeout == 0 {
+		config.Timeout = defaultTimeout
+	}
+	p := &Provider{config: config, points: make(map[string]*point), started: time.Now()}
+
+	// traceparent is version-traceid-parentid-flags
+	if parts := strings.Split(config.Parent, "-"); len(parts) == 4 && len(parts[1]) == 32 && len(parts[2]) == 16 {
+		p.traceID, p.parent = parts[1], parts[2]
+	}
+	return p
+}
+
+// Span is an operation being timed; its children are the spans started with
+// the context Start returned for it
+type Span struct {
+	provider *Provider
+	traceID  string
+	spanID   string
+	parentID string
+	name     string
+	start    time.Time
+	end      time.Time
+	attrs    []Attribute
+	err      error
+}
+
+type spanKey struct{}
+
+// Start begins a span that is a child of the span in ctx, if any, and
+// returns a context carrying the new span
+func (p *Provider) Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, *Span) {
+	if p == nil {
+		return ctx, nil
+	}
+
+	span := &Span{provider: p, spanID: randomID(8), name: name, start: time.Now(), attrs: attrs}
+	if parent := FromContext(ctx); parent != nil {
+		span.traceID, span.parentID = parent.traceID, parent.spanID
+	} else if p.traceID != "" {
+		span.traceID, span.parentID = p.traceID, p.parent
+	} else {
+		span.traceID = randomID(16)
+	}
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// FromContext returns the span ctx carries, or nil
+func FromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// SetAttributes adds attributes to the span
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	if s == nil {
+		return
+	}
+	s.provider.mu.Lock()
+	defer s.provider.mu.Unlock()
+	s.attrs = append(s.attrs, attrs...)
+}
+
+// End finishes the span, marking it failed if err is not nil
+func (s *Span) End(err error) {
+	if s == nil {
+		return
+	}
+	s.provider.mu.Lock()
+	defer s.provider.mu.Unlock()
+	s.end, s.err = time.Now(), err
+	s.provider.spans = append(s.provider.spans, s)
+}
+
+// randomID returns n random bytes, hex encoded
+func randomID(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Metric describes a counter or histogram
+type Metric struct {
+	Name        string    // Metric name, e.g. "depman.install.duration"
+	Unit        string    // UCUM unit, e.g. "s"
+	Description string    // What the metric measures
+	Bounds      []float64 // Histogram bucket boundaries, or nil for a counter
+}
+
+// Counter describes a metric whose recorded values are summed
+func Counter(name, unit, description string) Metric {
+	return Metric{Name: name, Unit: unit, Description: description}
+}
+
+// Histogram describes a metric whose recorded values are counted into buckets
+func Histogram(name, unit, description string, bounds ...float64) Metric {
+	return Metric{Name: name, Unit: unit, Description: description, Bounds: bounds}
+}
+
+// point aggregates the values recorded for one metric and attribute set
+type point struct {
+	metric  Metric
+	attrs   []Attribute
+	count   uint64
+	sum     float64
+	min     float64
+	max     float64
+	buckets []uint64
+}
+
+// Record adds a value to a metric
+func (p *Provider) Record(metric Metric, value float64, attrs ...Attribute) {
+	if p == nil {
+		return
+	}
+	attrs = append([]Attribute(nil), attrs...)
+	sort.Slice(attrs, func(i, j int) bool { return attrs[i].Key < attrs[j].Key })
+	key := metric.Name
+	for _, attr := range attrs {
+		key += "\x00" + attr.Key + "=" + attr.Value
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	pt, ok := p.points[key]
+	if !ok {
+		pt = &point{metric: metric, attrs: attrs, min: value, max: value}
+		if metric.Bounds != nil {
+			pt.buckets = make([]uint64, len(metric.Bounds)+1)
+		}
+		p.points[key] = pt
+	}
+	pt.count++
+	pt.sum += value
+	pt.min = min(pt.min, value)
+	pt.max = max(pt.max, value)
+	if pt.buckets != nil {
+		pt.buckets[sort.SearchFloat64s(metric.Bounds, value)]++
+	}
+}
+
+// Flush exports the spans ended and metric values recorded since the last
+// flush, posting them with client (http.DefaultClient if nil)
+func (p *Provider) Flush(ctx context.Context, client *http.Client) error {
+	if p == nil {
+		return nil
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	p.mu.Lock()
+	spans, points, started := p.spans, p.points, p.started
+	p.spans, p.points, p.started = nil, make(map[string]*point), time.Now()
+	p.mu.Unlock()
+
+	var errs []string
+	if p.config.TracesURL != "" && len(spans) > 0 {
+		if err := p.post(ctx, client, p.config.TracesURL, p.traces(spans)); err != nil {
+			errs = append(errs, fmt.Sprintf("traces: %v", err))
+		}
+	}
+	if p.config.MetricsURL != "" && len(points) > 0 {
+		if err := p.post(ctx, client, p.config.MetricsURL, p.metrics(points, started)); err != nil {
+			errs = append(errs, fmt.Sprintf("metrics: %v", err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to export telemetry: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// post sends one OTLP/HTTP export request
+func (p *Provider) post(ctx context.Context, client *http.Client, url string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.config.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range p.config.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("collector answered %s", resp.Status)
+	}
+	return nil
+}
+
+// OTLP JSON encoding. Trace and span IDs are hex strings and 64-bit
+// integers are decimal strings.
+
+type keyValue struct {
+	Key   string `json:"key"`
+	Value struct {
+		StringValue string `json:"stringValue"`
+	} `json:"value"`
+}
+
+func keyValues(attrs []Attribute) []keyValue {
+	kvs := make([]keyValue, len(attrs))
+	for i, attr := range attrs {
+		kvs[i].Key = attr.Key
+		kvs[i].Value.StringValue = attr.Value
+	}
+	return kvs
+}
+
+func nanos(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+type resource struct {
+	Attributes []keyValue `json:"attributes"`
+}
+
+type scope struct {
+	Name string `json:"name"`
+}
+
+// Span kind and status codes from the OTLP protocol
+const (
+	spanKindInternal = 1
+	statusOK         = 1
+	statusError      = 2
+)
+
+type otlpSpan struct {
+	TraceID           string     `json:"traceId"`
+	SpanID            string     `json:"spanId"`
+	ParentSpanID      string     `json:"parentSpanId,omitempty"`
+	Name              string     `json:"name"`
+	Kind              int        `json:"kind"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string     `json:"endTimeUnixNano"`
+	Attributes        []keyValue `json:"attributes"`
+	Status            struct {
+		Code    int    `json:"code"`
+		Message string `json:"message,omitempty"`
+	} `json:"status"`
+}
+
+// traces encodes spans as an ExportTraceServiceRequest
+func (p *Provider) traces(spans []*Span) interface{} {
+	encoded := make([]otlpSpan, len(spans))
+	for i, span := range spans {
+		encoded[i] = otlpSpan{
+			TraceID:           span.traceID,
+			SpanID:            span.spanID,
+			ParentSpanID:      span.parentID,
+			Name:              span.name,
+			Kind:              spanKindInternal,
+			StartTimeUnixNano: nanos(span.start),
+			EndTimeUnixNano:   nanos(span.end),
+			Attributes:        keyValues(span.attrs),
+		}
+		encoded[i].Status.Code = statusOK
+		if span.err != nil {
+			encoded[i].Status.Code = statusError
+			encoded[i].Status.Message = span.err.Error()
+		}
+	}
+
+	type scopeSpans struct {
+		Scope scope      `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+	type resourceSpans struct {
+		Resource   resource     `json:"resource"`
+		ScopeSpans []scopeSpans `json:"scopeSpans"`
+	}
+	return map[string][]resourceSpans{"resourceSpans": {{
+		Resource:   resource{Attributes: keyValues(p.config.Resource)},
+		ScopeSpans: []scopeSpans{{Scope: scope{Name: scopeName}, Spans: encoded}},
+	}}}
+}
+
+// aggregationDelta marks points that cover only the interval since the last export
+const aggregationDelta = 1
+
+type dataPoint struct {
+	Attributes        []keyValue `json:"attributes"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano"`
+	TimeUnixNano      string     `json:"timeUnixNano"`
+	AsDouble          *float64   `json:"asDouble,omitempty"`
+	Count             string     `json:"count,omitempty"`
+	Sum               *float64   `json:"sum,omitempty"`
+	Min               *float64   `json:"min,omitempty"`
+	Max               *float64   `json:"max,omitempty"`
+	BucketCounts      []string   `json:"bucketCounts,omitempty"`
+	ExplicitBounds    []float64  `json:"explicitBounds,omitempty"`
+}
+
+type sumData struct {
+	DataPoints             []dataPoint `json:"dataPoints"`
+	AggregationTemporality int         `json:"aggregationTemporality"`
+	IsMonotonic            bool        `json:"isMonotonic"`
+}
+
+type histogramData struct {
+	DataPoints             []dataPoint `json:"dataPoints"`
+	AggregationTemporality int         `json:"aggregationTemporality"`
+}
+
+type otlpMetric struct {
+	Name        string         `json:"name"`
+	Unit        string         `json:"unit,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Sum         *sumData       `json:"sum,omitempty"`
+	Histogram   *histogramData `json:"histogram,omitempty"`
+}
+
+// metrics encodes metric points as an ExportMetricsServiceRequest
+func (p *Provider) metrics(points map[string]*point, started time.Time) interface{} {
+	keys := make([]string, 0, len(points))
+	for key := range points {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	now := time.Now()
+	index := make(map[string]int)
+	var metrics []otlpMetric
+	for _, key := range keys {
+		pt := points[key]
+		i, ok := index[pt.metric.Name]
+		if !ok {
+			i = len(metrics)
+			index[pt.metric.Name] = i
+			metric := otlpMetric{Name: pt.metric.Name, Unit: pt.metric.Unit, Description: pt.metric.Description}
+			if pt.buckets != nil {
+				metric.Histogram = &histogramData{AggregationTemporality: aggregationDelta}
+			} else {
+				metric.Sum = &sumData{AggregationTemporality: aggregationDelta, IsMonotonic: true}
+			}
+			metrics = append(metrics, metric)
+		}
+
+		dp := dataPoint{Attributes: keyValues(pt.attrs), StartTimeUnixNano: nanos(started), TimeUnixNano: nanos(now)}
+		if pt.buckets == nil {
+			sum := pt.sum
+			dp.AsDouble = &sum
+			metrics[i].Sum.DataPoints = append(metrics[i].Sum.DataPoints, dp)
+			continue
+		}
+		sum, lo, hi := pt.sum, pt.min, pt.max
+		dp.Count = strconv.FormatUint(pt.count, 10)
+		dp.Sum, dp.Min, dp.Max = &sum, &lo, &hi
+		dp.ExplicitBounds = pt.metric.Bounds
+		for _, n := range pt.buckets {
+			dp.BucketCounts = append(dp.BucketCounts, strconv.FormatUint(n, 10))
+		}
+		metrics[i].Histogram.DataPoints = append(metrics[i].Histogram.DataPoints, dp)
+	}
+
+	type scopeMetrics struct {
+		Scope   scope        `json:"scope"`
+		Metrics []otlpMetric `json:"metrics"`
+	}
+	type resourceMetrics struct {
+		Resource     resource       `json:"resource"`
+		ScopeMetrics []scopeMetrics `json:"scopeMetrics"`
+	}
+	return map[string][]resourceMetrics{"resourceMetrics": {{
+		Resource:     resource{Attributes: keyValues(p.config.Resource)},
+		ScopeMetrics: []scopeMetrics{{Scope: scope{Name: scopeName}, Metrics: metrics}},
+	}}}
+}
@@ -0,0 +1,133 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	env := func(vars map[string]string) func(string) string {
+		return func(name string) string { return vars[name] }
+	}
+
+	config, err := ConfigFromEnv(env(map[string]string{}))
+	if err != nil || config != nil {
+		t.Fatalf("Expected no configuration without an endpoint but got %+v, %v", config, err)
+	}
+
+	config, err = ConfigFromEnv(env(map[string]string{
+		"OTEL_EXPORTER_OTLP_ENDPOINT":         "http://collector:4318/",
+		"OTEL_EXPORTER_OTLP_METRICS_ENDPOINT": "http://metrics:4318/custom",
+		"OTEL_EXPORTER_OTLP_HEADERS":          "x-api-key=abc%3D,team=ci",
+		"OTEL_RESOURCE_ATTRIBUTES":            "deployment.environment=ci",
+	}))
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if config.TracesURL != "http://collector:4318/v1/traces" || config.MetricsURL != "http://metrics:4318/custom" {
+		t.Errorf("Unexpected endpoints %s and %s", config.TracesURL, config.MetricsURL)
+	}
+	if config.Headers["x-api-key"] != "abc=" || config.Headers["team"] != "ci" {
+		t.Errorf("Unexpected headers %v", config.Headers)
+	}
+	if len(config.Resource) != 2 || config.Resource[1] != String("service.name", "depman") {
+		t.Errorf("Unexpected resource %v", config.Resource)
+	}
+
+	config, _ = ConfigFromEnv(env(map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "http://collector:4318", "OTEL_TRACES_EXPORTER": "none"}))
+	if config.TracesURL != "" || config.MetricsURL == "" {
+		t.Errorf("Expected only metrics to be exported but got %+v", config)
+	}
+
+	if _, err := ConfigFromEnv(env(map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "http://collector:4317", "OTEL_EXPORTER_OTLP_PROTOCOL": "grpc"})); err == nil {
+		t.Error("Expected an error for the grpc protocol but got none")
+	}
+	if config, _ := ConfigFromEnv(env(map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "http://collector:4318", "OTEL_SDK_DISABLED": "true"})); config != nil {
+		t.Errorf("Expected no configuration when disabled but got %+v", config)
+	}
+}
+
+func TestProviderFlush(t *testing.T) {
+	bodies := make(map[string]map[string]interface{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var decoded map[string]interface{}
+		if err := json.Unmarshal(body, &decoded); err != nil {
+			t.Errorf("Invalid JSON posted to %s: %v", r.URL.Path, err)
+		}
+		if r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("Expected the configured header on %s", r.URL.Path)
+		}
+		bodies[r.URL.Path] = decoded
+	}))
+	defer server.Close()
+
+	provider := New(Config{
+		TracesURL:  server.URL + "/v1/traces",
+		MetricsURL: server.URL + "/v1/metrics",
+		Headers:    map[string]string{"Authorization": "Bearer token"},
+		Parent:     "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+	})
+
+	ctx, root := provider.Start(context.Background(), "depman.ensure")
+	_, child := provider.Start(ctx, "depman.install", String("depman.dependency", "jq"))
+	child.End(errors.New("download failed"))
+	root.End(nil)
+
+	if root.traceID != "0af7651916cd43dd8448eb211c80319c" || root.parentID != "b7ad6b7169203331" {
+		t.Errorf("Expected the root span to continue TRACEPARENT but got %s/%s", root.traceID, root.parentID)
+	}
+	if child.traceID != root.traceID || child.parentID != root.spanID {
+		t.Errorf("Expected the install span to be a child of the root span")
+	}
+
+	duration := Histogram("depman.install.duration", "s", "Install time", 1, 10)
+	provider.Record(duration, 0.5, String("depman.dependency", "jq"))
+	provider.Record(duration, 4, String("depman.dependency", "jq"))
+	provider.Record(Counter("depman.install.failures", "{failure}", "Failed installs"), 1)
+
+	if err := provider.Flush(context.Background(), nil); err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+
+	spans := bodies["/v1/traces"]["resourceSpans"].([]interface{})[0].(map[string]interface{})["scopeSpans"].([]interface{})[0].(map[string]interface{})["spans"].([]interface{})
+	if len(spans) != 2 || spans[0].(map[string]interface{})["status"].(map[string]interface{})["code"].(float64) != statusError {
+		t.Errorf("Expected two spans, the first failed, but got %v", spans)
+	}
+
+	metrics := bodies["/v1/metrics"]["resourceMetrics"].([]interface{})[0].(map[string]interface{})["scopeMetrics"].([]interface{})[0].(map[string]interface{})["metrics"].([]interface{})
+	if len(metrics) != 2 {
+		t.Fatalf("Expected two metrics but got %v", metrics)
+	}
+	histogram := metrics[0].(map[string]interface{})["histogram"].(map[string]interface{})["dataPoints"].([]interface{})[0].(map[string]interface{})
+	if histogram["count"] != "2" || histogram["sum"].(float64) != 4.5 {
+		t.Errorf("Unexpected histogram point %v", histogram)
+	}
+	buckets := histogram["bucketCounts"].([]interface{})
+	if len(buckets) != 3 || buckets[0] != "1" || buckets[1] != "1" || buckets[2] != "0" {
+		t.Errorf("Unexpected bucket counts %v", buckets)
+	}
+
+	// Everything exported is forgotten
+	bodies = make(map[string]map[string]interface{})
+	provider.Flush(context.Background(), nil)
+	if len(bodies) != 0 {
+		t.Errorf("Expected nothing to be exported twice but got %v", bodies)
+	}
+}
+
+func TestNilProvider(t *testing.T) {
+	var provider *Provider
+	ctx, span := provider.Start(context.Background(), "depman.check")
+	span.SetAttributes(String("depman.dependency", "jq"))
+	span.End(nil)
+	provider.Record(Counter("depman.install.failures", "{failure}", ""), 1)
+	if ctx == nil || provider.Flush(ctx, nil) != nil {
+		t.Error("Expected a nil provider to do nothing")
+	}
+}
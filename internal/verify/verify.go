@@ -0,0 +1,171 @@
+package verify
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// ChecksumError is returned when a file does not match its expected checksum
+type ChecksumError struct {
+	Algorithm string // Hash algorithm used
+	Expected  string // Expected hex digest
+	Actual    string // Actual hex digest
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("checksum verification failed: expected %s:%s, got %s:%s",
+		e.Algorithm, e.Expected, e.Algorithm, e.Actual)
+}
+
+// NewHash returns a hash for a supported checksum algorithm (sha256 or sha512)
+func NewHash(algorithm string) (hash.Hash, error) {
+	switch strings.ToLower(algorithm) {
+	case "sha256":
+		return sha256.New(), nil
+	case "sha512":
+		return sha512.New(), nil
+	default:
+		return nil, fmt.Errorf("unsupported checksum algorithm: %s", algorithm)
+	}
+}
+
+// ParseChecksum splits a checksum in "algorithm:hash" form
+func ParseChecksum(checksum string) (algorithm, digest string, err error) {
+	parts := strings.Split(checksum, ":")
+	if len(parts) != 2 || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid checksum format, expected 'algorithm:hash'")
+	}
+
+	algorithm = strings.ToLower(parts[0])
+	if _, err := NewHash(algorithm); err != nil {
+		return "", "", err
+	}
+
+	return algorithm, strings.ToLower(parts[1]), nil
+}
+
+// Checksum verifies that a file matches a checksum in "algorithm:hash" form
+func Checksum(path, checksum string) error {
+	algorithm, expected, err := ParseChecksum(checksum)
+	if err != nil {
+		return err
+	}
+
+	actual, err := FileDigest(path, algorithm)
+	if err != nil {
+		return err
+	}
+
+	if actual != expected {
+		return &ChecksumError{Algorithm: algorithm, Expected: expected, Actual: actual}
+	}
+
+	return nil
+}
+
+// FileDigest returns the hex digest of a file
+func FileDigest(path, algorithm string) (string, error) {
+	h, err := NewHash(algorithm)
+	if err != nil {
+		return "", err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(h, file); err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// FindInChecksumsFile looks up the checksum of a file name in a checksums file
+// using either the coreutils format ("<hash>  <name>") or the BSD format
+// ("SHA256 (<name>) = <hash>"), returning it in "algorithm:hash" form
+func FindInChecksumsFile(data, filename string) (string, error) {
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// BSD format
+		if open := strings.Index(line, " ("); open > 0 && strings.Contains(line, ") = ") {
+			name := line[open+2 : strings.Index(line, ") = ")]
+			if filepath.Base(name) == filename {
+				digest := strings.TrimSpace(line[strings.Index(line, ") = ")+4:])
+				return strings.ToLower(line[:open]) + ":" + strings.ToLower(digest), nil
+			}
+			continue
+		}
+
+		// Coreutils format, with an optional "*" marking binary mode
+		fields := strings.Fields(line)
+		if len(fields) == 1 {
+			// A file containing only the digest belongs to the requested file
+			return algorithmForDigest(fields[0])
+		}
+		if len(fields) >= 2 && filepath.Base(strings.TrimPrefix(fields[1], "*")) == filename {
+			return algorithmForDigest(fields[0])
+		}
+	}
+
+	return "", fmt.Errorf("no checksum found for %s", filename)
+}
+
+// algorithmForDigest infers the algorithm of a hex digest from its length
+func algorithmForDigest(digest string) (string, error) {
+	digest = strings.ToLower(digest)
+	switch len(digest) {
+	case sha256.Size * 2:
+		return "sha256:" + digest, nil
+	case sha512.Size * 2:
+		return "sha512:" + digest, nil
+	default:
+		return "", fmt.Errorf("unrecognized checksum %s", digest)
+	}
+}
+
+// Signature verifies a detached signature over a file using an external tool.
+// kind is "gpg" (key is a keyring or armored public key file) or "minisign"
+// (key is a public key string or key file).
+func Signature(ctx context.Context, kind, path, signaturePath, key string) error {
+	var cmd *exec.Cmd
+	switch strings.ToLower(kind) {
+	case "gpg", "pgp":
+		args := []string{"--batch", "--verify"}
+		if key != "" {
+			args = append([]string{"--no-default-keyring", "--keyring", key}, args...)
+		}
+		cmd = exec.CommandContext(ctx, "gpg", append(args, signaturePath, path)...)
+	case "minisign":
+		keyFlag := "-P"
+		if _, err := os.Stat(key); err == nil {
+			keyFlag = "-p"
+		}
+		cmd = exec.CommandContext(ctx, "minisign", "-V", "-m", path, "-x", signaturePath, keyFlag, key)
+	default:
+		return fmt.Errorf("unsupported signature type: %s", kind)
+	}
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("signature verification failed: %w, output: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
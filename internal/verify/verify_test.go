@@ -0,0 +1,127 @@
+package verify
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestChecksum(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "verify-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	path := filepath.Join(tempDir, "file")
+	if err := os.WriteFile(path, []byte("hello\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	testCases := []struct {
+		name        string
+		checksum    string
+		expectError bool
+	}{
+		{
+			name:        "Matching sha256",
+			checksum:    "sha256:5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03",
+			expectError: false,
+		},
+		{
+			name:        "Matching sha512",
+			checksum:    "SHA512:e7c22b994c59d9cf2b48e549b1e24666636045930d3da7c1acb299d1c3b7f931f94aae41edda2c2b207a36e10f8bcb8d45223e54878f5b316e7ce3b6bc019629",
+			expectError: false,
+		},
+		{
+			name:        "Mismatch",
+			checksum:    "sha256:0000000000000000000000000000000000000000000000000000000000000000",
+			expectError: true,
+		},
+		{
+			name:        "Unsupported algorithm",
+			checksum:    "md5:b1946ac92492d2347c6235b4d2611184",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Checksum(path, tc.checksum)
+			if tc.expectError && err == nil {
+				t.Errorf("Expected an error but got none")
+			}
+			if !tc.expectError && err != nil {
+				t.Errorf("Did not expect an error but got: %v", err)
+			}
+		})
+	}
+
+	var mismatch *ChecksumError
+	if err := Checksum(path, testCases[2].checksum); !errors.As(err, &mismatch) {
+		t.Errorf("Expected a ChecksumError but got %v", err)
+	}
+}
+
+func TestFindInChecksumsFile(t *testing.T) {
+	sha256Hex := strings.Repeat("a", 64)
+	sha512Hex := strings.Repeat("b", 128)
+
+	testCases := []struct {
+		name     string
+		data     string
+		filename string
+		expected string
+	}{
+		{
+			name:     "Coreutils format",
+			data:     strings.Repeat("c", 64) + "  other.tar.gz\n" + sha256Hex + "  tool.tar.gz\n",
+			filename: "tool.tar.gz",
+			expected: "sha256:" + sha256Hex,
+		},
+		{
+			name:     "Binary mode marker",
+			data:     sha512Hex + " *dist/tool.zip\n",
+			filename: "tool.zip",
+			expected: "sha512:" + sha512Hex,
+		},
+		{
+			name:     "BSD format",
+			data:     "SHA256 (tool.tar.gz) = " + sha256Hex + "\n",
+			filename: "tool.tar.gz",
+			expected: "sha256:" + sha256Hex,
+		},
+		{
+			name:     "Digest only",
+			data:     sha256Hex + "\n",
+			filename: "tool.tar.gz",
+			expected: "sha256:" + sha256Hex,
+		},
+		{
+			name:     "Not listed",
+			data:     sha256Hex + "  other.tar.gz\n",
+			filename: "tool.tar.gz",
+			expected: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := FindInChecksumsFile(tc.data, tc.filename)
+			if tc.expected == "" {
+				if err == nil {
+					t.Errorf("Expected an error but got %s", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Did not expect an error but got: %v", err)
+			}
+			if got != tc.expected {
+				t.Errorf("Expected %s but got %s", tc.expected, got)
+			}
+		})
+	}
+}
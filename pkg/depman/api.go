@@ -1,71 +1,511 @@
 package depman
 
 import (
+	"context"
 	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/devnadeemashraf/depman/internal/telemetry"
 )
 
-// EnsureDependencies checks and installs all dependencies if needed
-// This is the main function that most applications should use
-func (m *Manager) EnsureDependencies() (map[string]*DependencyStatus, error) {
-	// First check if dependencies are properly configured
+// EnsureDependencies checks and installs all dependencies if needed, returning
+// their statuses by name. Ensure returns the same as a Result.
+func (m *Manager) EnsureDependencies(ctx context.Context) (map[string]*DependencyStatus, error) {
+	result, err := m.Ensure(ctx)
+	if result == nil {
+		return nil, err
+	}
+	return result.byName, err
+}
+
+// ensureDependencies does the work of Ensure, adding the artifacts it
+// installs to artifacts
+func (m *Manager) ensureDependencies(ctx context.Context, artifacts map[string]LockedArtifact) (map[string]*DependencyStatus, error) {
+	// Work out what needs to happen before touching anything
+	plan, err := m.PlanEnsure(ctx)
+	if err != nil {
+		return nil, err
+	}
+	statuses := plan.Statuses()
+
+	// Install or update dependencies as needed, dependencies before their dependents
+	var names []string
+	for _, action := range plan.Actions {
+		names = append(names, action.Name)
+	}
+	order, err := m.resolveInstallOrder(names)
+	if err != nil {
+		return statuses, err
+	}
+
+	// Dependencies violating the license policy fail the run before anything is installed
+	licenses, err := m.enforceLicensePolicy(ctx, order, statuses)
+	if err != nil {
+		return statuses, err
+	}
+
+	actions := make(map[string]*PlannedAction, len(plan.Actions))
+	for _, action := range plan.Actions {
+		actions[action.Name] = action
+	}
+
+	var pending []*Dependency
+	for _, dep := range order {
+		// Skip if already installed and compatible
+		if action, ok := actions[dep.Name]; ok && action.Action != ActionSkip {
+			pending = append(pending, dep)
+		}
+	}
+
+	// Conflicting tools are never installed side by side
+	if err := m.checkConflicts(ctx, pending, statuses); err != nil {
+		return statuses, err
+	}
+
+	// In interactive mode, only what the user agrees to is installed
+	if pending, err = m.confirmActions(ctx, pending, actions); err != nil {
+		return statuses, err
+	}
+
+	var mu sync.Mutex
+	err = m.installGraph(ctx, pending, func(ctx context.Context, dep *Dependency) error {
+		// Install, configure and re-verify the dependency
+		updatedStatus, artifact, err := m.installAndVerify(ctx, dep)
+		attempts, output, log := m.takeAttempts(dep.Name), m.takeOutput(dep.Name), m.takeLog(dep.Name)
+
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			if updatedStatus != nil && updatedStatus.Unhealthy {
+				// Installed, but not working
+				updatedStatus.Error = err
+				updatedStatus.Attempts = attempts
+				updatedStatus.InstallOutput = output
+				updatedStatus.InstallLog = log
+				statuses[dep.Name] = updatedStatus
+				return err
+			}
+			action := actions[dep.Name]
+			action.Status.Error = err
+			action.Status.Installed = false
+			action.Status.Attempts = attempts
+			action.Status.InstallOutput = output
+			action.Status.InstallLog = log
+			return err
+		}
+		artifacts[dep.Name] = artifact
+		updatedStatus.Attempts = attempts
+		updatedStatus.InstallOutput = output
+		updatedStatus.InstallLog = log
+		recordChange(actions[dep.Name].Status, updatedStatus)
+
+		// Update the status in our results
+		statuses[dep.Name] = updatedStatus
+		return nil
+	})
+	if err != nil {
+		return statuses, err
+	}
+
+	// Alternatives that were not installed are satisfied by the one that was
+	m.applyProviders(statuses)
+
+	// Apply environment changes to the current process
+	if err := m.envManager.ApplyToCurrentProcess(); err != nil {
+		m.logger.Warnf("Failed to apply environment changes: %v", err)
+	}
+
+	// A frozen install must end up at exactly the locked versions
+	if m.lock != nil {
+		for name, status := range statuses {
+			if entry := m.lock.Find(name); entry != nil && status.CurrentVersion != entry.Version {
+				return statuses, fmt.Errorf("dependency '%s' is at version %s but the lockfile requires %s",
+					name, status.CurrentVersion, entry.Version)
+			}
+		}
+		return statuses, m.runPostEnsure(ctx, artifacts)
+	}
+
+	// Record the resolved state for reproducible installs
+	m.recordLicenses(ctx, pending, artifacts, licenses)
+	if err := m.updateLockfile(statuses, artifacts, licenses); err != nil {
+		m.logger.Warnf("Failed to update lockfile: %v", err)
+	}
+
+	return statuses, m.runPostEnsure(ctx, artifacts)
+}
+
+// InstallDependencies installs the named dependencies together with everything
+// they transitively depend on, dependencies first. Dependencies that are already
+// installed and up to date are skipped unless force is set. A name may pin a
+// version with "name@version"; tools installed side by side keep their other
+// versions, and pinned installs do not change the lockfile.
+func (m *Manager) InstallDependencies(ctx context.Context, names []string, force bool) (_ map[string]*DependencyStatus, err error) {
+	ctx, done := m.operation(ctx, "depman.install")
+	defer func() { done(err) }()
+
 	if err := m.validateConfiguration(); err != nil {
 		return nil, fmt.Errorf("invalid dependency configuration: %w", err)
 	}
+	if err := m.loadBundle(); err != nil {
+		return nil, err
+	}
+
+	pinned := make(map[string]string)
+	plain := make([]string, len(names))
+	for i, spec := range names {
+		name, version := splitVersionSpec(spec)
+		if version != "" {
+			pinned[name] = version
+		}
+		plain[i] = name
+	}
+
+	order, err := m.resolveInstallOrder(plain)
+	if err != nil {
+		return nil, err
+	}
 
-	// Check current status of all dependencies
-	statuses, err := m.CheckAllDependencies()
+	statuses := make(map[string]*DependencyStatus)
+	licenses, err := m.enforceLicensePolicy(ctx, order, statuses)
 	if err != nil {
 		return statuses, err
 	}
+	artifacts := make(map[string]LockedArtifact)
+	if err := conflictWithin(order); err != nil {
+		return statuses, err
+	}
+
+	var mu sync.Mutex
+	err = m.installGraph(ctx, order, func(ctx context.Context, dep *Dependency) error {
+		if version, ok := pinned[dep.Name]; ok {
+			copied := *dep
+			copied.Version = Version{Required: version}
+			dep = &copied
+
+			if !force && m.isToolVersionInstalled(dep, version) {
+				m.logger.Infof("Dependency %s %s is already installed; run 'depman use %s@%s' to switch to it", dep.Name, version, dep.Name, version)
+				status, _ := m.checkWithHooks(ctx, dep)
+				mu.Lock()
+				statuses[dep.Name] = status
+				mu.Unlock()
+				return nil
+			}
+		}
+
+		status, _ := m.checkWithHooks(ctx, dep)
+
+		if !force && status.Installed && status.Compatible && status.RequiredUpdate == NoUpdate {
+			m.logger.Infof("Dependency %s is already installed (v%s)", dep.Name, status.CurrentVersion)
+			mu.Lock()
+			statuses[dep.Name] = status
+			mu.Unlock()
+			return nil
+		}
+
+		if err := m.checkConflicts(ctx, []*Dependency{dep}, nil); err != nil {
+			status.Error = err
+			mu.Lock()
+			statuses[dep.Name] = status
+			mu.Unlock()
+			return err
+		}
 
-	// Install or update dependencies as needed
+		updatedStatus, artifact, err := m.installAndVerify(ctx, dep)
+		attempts, output, log := m.takeAttempts(dep.Name), m.takeOutput(dep.Name), m.takeLog(dep.Name)
+
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			if updatedStatus != nil && updatedStatus.Unhealthy {
+				// Installed, but not working
+				status = updatedStatus
+			} else {
+				status.Installed = false
+			}
+			status.Error = err
+			status.Attempts = attempts
+			status.InstallOutput = output
+			status.InstallLog = log
+			statuses[dep.Name] = status
+			return err
+		}
+		artifacts[dep.Name] = artifact
+		updatedStatus.Attempts = attempts
+		updatedStatus.InstallOutput = output
+		updatedStatus.InstallLog = log
+		recordChange(status, updatedStatus)
+		statuses[dep.Name] = updatedStatus
+		return nil
+	})
+	if err != nil {
+		return statuses, err
+	}
+
+	if err := m.envManager.ApplyToCurrentProcess(); err != nil {
+		m.logger.Warnf("Failed to apply environment changes: %v", err)
+	}
+
+	// Pinned versions are one-off installs; the lockfile keeps following the configuration
+	locked := make(map[string]*DependencyStatus)
+	lockedArtifacts := make(map[string]LockedArtifact)
 	for name, status := range statuses {
-		// Skip if already installed and compatible
-		if status.Installed && status.Compatible && status.RequiredUpdate == NoUpdate {
-			continue
+		if _, ok := pinned[name]; !ok {
+			locked[name] = status
+			if artifact, ok := artifacts[name]; ok {
+				lockedArtifacts[name] = artifact
+			}
+		}
+	}
+	m.recordLicenses(ctx, order, lockedArtifacts, licenses)
+	if err := m.updateLockfile(locked, lockedArtifacts, licenses); err != nil {
+		m.logger.Warnf("Failed to update lockfile: %v", err)
+	}
+
+	return statuses, m.runPostEnsure(ctx, artifacts)
+}
+
+// RemoveDependency uninstalls a dependency using the reverse of its install
+// strategy and removes the environment entries and lockfile entry depman
+// created for it. Removal is refused while other configured dependencies
+// still depend on it, unless force is set.
+func (m *Manager) RemoveDependency(ctx context.Context, name string, force bool) error {
+	dep := m.FindDependency(name)
+	if dep == nil {
+		return fmt.Errorf("dependency '%s' not found in configuration", name)
+	}
+
+	if dependents := m.dependentsOf(name); len(dependents) > 0 && !force {
+		return fmt.Errorf("dependency '%s' is required by: %s", name, strings.Join(dependents, ", "))
+	}
+
+	platformConfig, err := m.GetPlatformConfig(dep)
+	if err != nil {
+		return err
+	}
+
+	strategy, err := m.strategyFor(platformConfig)
+	if err != nil {
+		return err
+	}
+
+	install, err := m.findInstall(name)
+	if err != nil {
+		m.logger.Warnf("Failed to read install state: %v", err)
+	}
+
+	// Files depman recorded creating can be removed even without an uninstaller
+	remover, ok := strategy.(uninstaller)
+	if !ok && (install == nil || len(install.Files) == 0) {
+		return fmt.Errorf("install method '%s' does not support uninstalling", installMethod(platformConfig))
+	}
+
+	environments := m.environments(dep)
+	if ok {
+		if err := remover.uninstall(environmentContext(ctx, environments[0]), m, dep, platformConfig); err != nil {
+			return err
+		}
+	}
+	// Dependencies installed in both WSL environments are removed from the Windows host too
+	for _, env := range environments[1:] {
+		if err := m.uninstallIn(ctx, dep, env); err != nil {
+			return err
+		}
+	}
+	if install != nil {
+		if err := removeInstalledFiles(install); err != nil {
+			return err
+		}
+		if err := m.forgetInstall(name); err != nil {
+			m.logger.Warnf("Failed to update install state: %v", err)
 		}
+	}
+
+	m.teardownDependencyEnvironment(dep)
+
+	if err := m.removeFromLockfile(name); err != nil {
+		m.logger.Warnf("Failed to update lockfile: %v", err)
+	}
+
+	m.logger.Infof("Successfully removed %s", dep.Name)
+	return nil
+}
 
-		// Find the dependency definition
-		var dep *Dependency
-		for i := range m.Config.Dependencies {
-			if m.Config.Dependencies[i].Name == name {
-				dep = &m.Config.Dependencies[i]
+// dependentsOf returns the names of configured dependencies that directly depend on name
+func (m *Manager) dependentsOf(name string) []string {
+	var dependents []string
+	for _, dep := range m.Config.Dependencies {
+		for _, child := range dep.Dependencies {
+			if child == name {
+				dependents = append(dependents, dep.Name)
 				break
 			}
 		}
+	}
+	return dependents
+}
 
-		if dep == nil {
-			return statuses, fmt.Errorf("dependency '%s' not found in configuration", name)
+// FindDependency returns the configured dependency with the given name, or nil if there is none
+func (m *Manager) FindDependency(name string) *Dependency {
+	for i := range m.Config.Dependencies {
+		if m.Config.Dependencies[i].Name == name {
+			return &m.Config.Dependencies[i]
 		}
+	}
+	return nil
+}
 
-		// Install or update the dependency
-		if err := m.installDependency(dep); err != nil {
-			status.Error = err
-			status.Installed = false
-			return statuses, err
+// uninstallIn removes a dependency from a secondary environment it was installed in
+func (m *Manager) uninstallIn(ctx context.Context, dep *Dependency, env WSLTarget) error {
+	platformConfig, err := m.platformConfigIn(dep, env)
+	if err != nil {
+		return err
+	}
+	strategy, err := m.strategyFor(platformConfig)
+	if err != nil {
+		return err
+	}
+	remover, ok := strategy.(uninstaller)
+	if !ok {
+		m.logger.Warnf("Install method '%s' does not support uninstalling; remove %s from the Windows host by hand", installMethod(platformConfig), dep.Name)
+		return nil
+	}
+	return remover.uninstall(environmentContext(ctx, env), m, dep, platformConfig)
+}
+
+// installAndVerify installs a dependency, sets up its environment and checks
+// the result, recording how long it took and whether it failed
+func (m *Manager) installAndVerify(ctx context.Context, dep *Dependency) (*DependencyStatus, LockedArtifact, error) {
+	attrs := m.dependencyAttributes(dep)
+	ctx, span := m.telemetry.Start(ctx, "depman.dependency", attrs...)
+	start := time.Now()
+
+	// Every command the install runs is logged in full
+	log := m.installLogFor(dep)
+	status, artifact, err := m.installAndVerifyTraced(withInstallLog(ctx, log), dep)
+	if path := log.written(); path != "" {
+		m.recordLog(dep.Name, path)
+		if err != nil {
+			err = fmt.Errorf("%w (log: %s)", err, path)
 		}
+	}
+
+	outcome := "success"
+	if err != nil {
+		outcome = "failure"
+		m.telemetry.Record(metricInstallFailures, 1, attrs...)
+	}
+	m.telemetry.Record(metricInstallDuration, time.Since(start).Seconds(), append(attrs, telemetry.String("depman.outcome", outcome))...)
+	span.End(err)
+	return status, artifact, err
+}
+
+// installAndVerifyTraced does the work of installAndVerify
+func (m *Manager) installAndVerifyTraced(ctx context.Context, dep *Dependency) (*DependencyStatus, LockedArtifact, error) {
+	ctx, cancel := m.dependencyContext(ctx, dep)
+	defer cancel()
+
+	// Attempts, script output and created files are counted afresh for every install
+	m.takeAttempts(dep.Name)
+	m.takeOutput(dep.Name)
+	m.takeFiles(dep.Name)
 
-		// Set up environment for the dependency
-		if err := m.setupDependencyEnvironment(dep); err != nil {
-			m.logger.Warnf("Failed to set up environment for dependency %s: %v", dep.Name, err)
+	if err := m.runDependencyHooks(ctx, hookPreInstall, dep, nil); err != nil {
+		return nil, LockedArtifact{}, err
+	}
+
+	// Tools kept side by side go back to the previous version if anything below fails
+	snapshot := m.snapshotTool(dep)
+
+	// Install or update the dependency
+	var artifact LockedArtifact
+	err := m.step(ctx, dep, StepInstall, func(ctx context.Context) error {
+		var err error
+		artifact, err = m.installDependency(ctx, dep)
+		return timeoutError(ctx, dep, err)
+	})
+	if err != nil {
+		return nil, artifact, m.rollbackFailedInstall(snapshot, err)
+	}
+
+	// Set up environment for the dependency; installs may run in parallel
+	m.envMu.Lock()
+	if err := m.setupDependencyEnvironment(dep); err != nil {
+		m.logger.Warnf("Failed to set up environment for dependency %s: %v", dep.Name, err)
+	}
+	m.envMu.Unlock()
+
+	if err := m.runDependencyHooks(ctx, hookPostInstall, dep, nil); err != nil {
+		return nil, artifact, m.rollbackFailedInstall(snapshot, err)
+	}
+
+	// Verify the installation worked
+	var status *DependencyStatus
+	err = m.step(ctx, dep, StepVerify, func(ctx context.Context) error {
+		var err error
+		status, err = m.CheckDependency(ctx, dep)
+		if err != nil || !status.Installed {
+			return err
 		}
 
-		// Verify the installation worked
-		updatedStatus, err := m.CheckDependency(dep)
-		if err != nil {
-			return statuses, err
+		// A dependency can install fine and still not work
+		if err := m.runHealthCheck(ctx, dep); err != nil {
+			status.Unhealthy = true
+			status.Error = err
+			return err
 		}
+		return nil
+	})
+	if err != nil {
+		return status, artifact, m.rollbackFailedInstall(snapshot, err)
+	}
 
-		// Update the status in our results
-		statuses[name] = updatedStatus
+	if err := m.recordInstall(dep, status, artifact); err != nil {
+		m.logger.Warnf("Failed to record the install of %s: %v", dep.Name, err)
 	}
 
-	// Apply environment changes to the current process
-	if err := m.envManager.ApplyToCurrentProcess(); err != nil {
-		m.logger.Warnf("Failed to apply environment changes: %v", err)
+	return status, artifact, nil
+}
+
+// checkWithHooks runs the dependency's pre_check hooks and then checks it
+func (m *Manager) checkWithHooks(ctx context.Context, dep *Dependency) (*DependencyStatus, error) {
+	ctx, cancel := m.dependencyContext(ctx, dep)
+	defer cancel()
+
+	if err := m.runDependencyHooks(ctx, hookPreCheck, dep, nil); err != nil {
+		return &DependencyStatus{Name: dep.Name, Error: err, Projects: dep.Projects}, err
 	}
 
-	return statuses, nil
+	var status *DependencyStatus
+	err := m.step(ctx, dep, StepCheck, func(ctx context.Context) error {
+		var err error
+		status, err = m.CheckDependency(ctx, dep)
+		return err
+	})
+	if err = timeoutError(ctx, dep, err); err != nil && status != nil {
+		status.Error = err
+	}
+	if err == nil && status != nil && status.Installed {
+		m.detectShadowing(ctx, dep, status)
+	}
+	if status != nil {
+		status.Override = m.override(dep.Name)
+	}
+	return status, err
+}
+
+// runPostEnsure runs the post_ensure hooks with the names installed during the run
+func (m *Manager) runPostEnsure(ctx context.Context, artifacts map[string]LockedArtifact) error {
+	var installed []string
+	for _, dep := range m.Config.Dependencies {
+		if _, ok := artifacts[dep.Name]; ok {
+			installed = append(installed, dep.Name)
+		}
+	}
+	return m.runRunHooks(ctx, hookPostEnsure, installed)
 }
 
 // Add a method to get the updated environment
@@ -73,9 +513,12 @@ func (m *Manager) GetUpdatedEnvironment() []string {
 	return m.envManager.GetUpdatedEnvironment()
 }
 
-// CheckAllDependencies checks the status of all dependencies without installing
-// Use this to inspect what would be installed/updated
-func (m *Manager) CheckAllDependencies() (map[string]*DependencyStatus, error) {
+// CheckAllDependencies checks the status of all dependencies without installing,
+// returning them by name. Check returns the same as a Result.
+func (m *Manager) CheckAllDependencies(ctx context.Context) (_ map[string]*DependencyStatus, err error) {
+	ctx, done := m.operation(ctx, "depman.check")
+	defer func() { done(err) }()
+
 	results := make(map[string]*DependencyStatus)
 
 	// Validate dependencies configuration
@@ -84,15 +527,63 @@ func (m *Manager) CheckAllDependencies() (map[string]*DependencyStatus, error) {
 		return nil, fmt.Errorf("dependency configuration errors: %v", errors)
 	}
 
-	// Check each dependency
-	for _, dep := range m.Config.Dependencies {
-		status, _ := m.CheckDependency(&dep) // We still want to return status even if there's an error
-		results[dep.Name] = status
+	deps, err := m.SelectedDependencies()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.runRunHooks(ctx, hookPreCheck, nil); err != nil {
+		return nil, err
 	}
 
+	// Check dependencies with a bounded worker pool. Each worker writes to its
+	// own slot so the collected results follow configuration order.
+	statuses := make([]*DependencyStatus, len(deps))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < m.workerCount(len(deps)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if err := ctx.Err(); err != nil {
+					statuses[i] = &DependencyStatus{Name: deps[i].Name, Error: err, Projects: deps[i].Projects}
+					continue
+				}
+				statuses[i], _ = m.checkWithHooks(ctx, deps[i]) // We still want to return status even if there's an error
+			}
+		}()
+	}
+
+	for i := range deps {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for i, dep := range deps {
+		results[dep.Name] = statuses[i]
+	}
+
+	// One alternative satisfies all dependencies providing the same name
+	m.applyProviders(results)
+
 	return results, nil
 }
 
+// workerCount returns the number of check workers to start for n dependencies
+func (m *Manager) workerCount(n int) int {
+	workers := m.concurrency
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+	if workers > n {
+		workers = n
+	}
+	return workers
+}
+
 // validateConfiguration performs overall configuration validation
 func (m *Manager) validateConfiguration() error {
 	// Check if config is loaded
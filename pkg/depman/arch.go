@@ -0,0 +1,152 @@
+package depman
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// knownArchs are the CPU architectures accepted in "os/arch" platform keys
+var knownArchs = []string{"amd64", "arm64", "386", "arm", "riscv64", "ppc64le", "s390x"}
+
+// emulatedArchs lists, per native os/arch, the architectures whose binaries
+// also run there through emulation, preferred in order
+var emulatedArchs = map[string][]string{
+	"darwin/arm64":  {"amd64"},        // Rosetta 2
+	"windows/arm64": {"amd64", "386"}, // x64 and x86 emulation
+	"windows/amd64": {"386"},          // WOW64
+}
+
+// WithArch sets a specific CPU architecture to use instead of auto-detecting
+func WithArch(arch string) Option {
+	return func(m *Manager) {
+		m.Arch = arch
+	}
+}
+
+// DetectArch returns the native CPU architecture. It differs from
+// runtime.GOARCH when depman itself runs emulated: an amd64 build under
+// Rosetta 2 on Apple silicon, or a 386 or amd64 build on 64-bit or ARM Windows.
+func DetectArch() string {
+	switch runtime.GOOS {
+	case "darwin":
+		if runtime.GOARCH == "amd64" {
+			out, err := exec.Command("sysctl", "-n", "sysctl.proc_translated").Output()
+			if err == nil && strings.TrimSpace(string(out)) == "1" {
+				return "arm64"
+			}
+		}
+	case "windows":
+		if arch := windowsArch(os.Getenv("PROCESSOR_ARCHITEW6432"), os.Getenv("PROCESSOR_ARCHITECTURE"), os.Getenv("PROCESSOR_IDENTIFIER")); arch != "" {
+			return arch
+		}
+	}
+	return runtime.GOARCH
+}
+
+// windowsArch maps the Windows processor environment variables to a native
+// architecture, or "" if they are not recognized. WOW64 reports the native
+// architecture of 32-bit processes in PROCESSOR_ARCHITEW6432; x64 emulation
+// on ARM reports AMD64 but leaves the ARM processor identifier.
+func windowsArch(wow64, arch, identifier string) string {
+	if strings.HasPrefix(strings.ToUpper(identifier), "ARM") {
+		return "arm64"
+	}
+	if wow64 != "" {
+		arch = wow64
+	}
+	switch strings.ToUpper(arch) {
+	case "AMD64":
+		return "amd64"
+	case "ARM64":
+		return "arm64"
+	case "X86":
+		return "386"
+	default:
+		return ""
+	}
+}
+
+// targetArch returns the architecture tools are installed for
+func (m *Manager) targetArch() string {
+	if m.Arch == "" {
+		return runtime.GOARCH
+	}
+	return m.Arch
+}
+
+// PlatformTarget returns the platform and architecture tools are installed
+// for, e.g. "linux/arm64"
+func (m *Manager) PlatformTarget() string {
+	return m.Platform + "/" + m.targetArch()
+}
+
+// platformCandidates returns the platform keys that apply to an os and arch,
+// in order of preference: the exact "os/arch", then the plain os, whose URLs
+// can use {{arch}}, then architectures the os runs through emulation
+func platformCandidates(goos, arch string) []string {
+	candidates := []string{goos + "/" + arch, goos}
+	for _, emulated := range emulatedArchs[goos+"/"+arch] {
+		candidates = append(candidates, goos+"/"+emulated)
+	}
+	return candidates
+}
+
+// matchPlatform returns the key of the platform entry that applies to an os and arch
+func matchPlatform(platforms map[string]PlatformConfig, goos, arch string) (string, bool) {
+	for _, key := range platformCandidates(goos, arch) {
+		if _, ok := platforms[key]; ok {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// platformKey returns the key of the dependency's platform entry for the
+// current platform and architecture, in its primary environment
+func (m *Manager) platformKey(dep *Dependency) (string, bool) {
+	return m.platformKeyIn(dep, m.environments(dep)[0])
+}
+
+// platformKeyIn returns the key of the dependency's platform entry for an
+// environment. On Linux, entries naming the distribution win over plain os
+// and "os/arch" ones; the Windows host from WSL uses the windows entries.
+func (m *Manager) platformKeyIn(dep *Dependency, env WSLTarget) (string, bool) {
+	if env == WSLWindows {
+		return matchPlatform(dep.Platforms, "windows", m.targetArch())
+	}
+	if key, ok := matchDistro(dep.Platforms, m.Distro()); ok {
+		return key, true
+	}
+	return matchPlatform(dep.Platforms, m.Platform, m.targetArch())
+}
+
+// supportsPlatform reports whether a dependency has a platform entry for the
+// current platform and architecture in every environment it is installed in
+func (m *Manager) supportsPlatform(dep *Dependency) bool {
+	_, missing := m.missingPlatform(dep)
+	return !missing
+}
+
+// lockPlatform returns the key artifacts of a dependency are locked under:
+// the platform entry that applies, or the plain platform if none does
+func (m *Manager) lockPlatform(dep *Dependency) string {
+	if key, ok := m.platformKey(dep); ok {
+		return key
+	}
+	return m.Platform
+}
+
+// isKnownPlatformKey reports whether a platforms key is a known os, "os/arch"
+// or Linux distribution selector
+func isKnownPlatformKey(key string) bool {
+	if _, ok := parseDistroSelector(key); ok {
+		return true
+	}
+	goos, arch, hasArch := strings.Cut(key, "/")
+	if !hasArch {
+		return isKnownPlatform(goos)
+	}
+	return isKnownPlatform(goos) && containsString(knownArchs, arch)
+}
@@ -0,0 +1,146 @@
+package depman
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMatchPlatform(t *testing.T) {
+	testCases := []struct {
+		name      string
+		platforms []string
+		goos      string
+		arch      string
+		expected  string
+	}{
+		{"Exact match", []string{"linux", "linux/arm64"}, "linux", "arm64", "linux/arm64"},
+		{"Plain os", []string{"linux", "linux/amd64"}, "linux", "arm64", "linux"},
+		{"Rosetta", []string{"darwin/amd64"}, "darwin", "arm64", "darwin/amd64"},
+		{"Native before emulated", []string{"darwin", "darwin/amd64"}, "darwin", "arm64", "darwin"},
+		{"Windows on ARM", []string{"windows/386", "windows/amd64"}, "windows", "arm64", "windows/amd64"},
+		{"WOW64", []string{"windows/386"}, "windows", "amd64", "windows/386"},
+		{"No emulation", []string{"linux/amd64"}, "linux", "arm64", ""},
+		{"Other os", []string{"darwin"}, "linux", "amd64", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			platforms := make(map[string]PlatformConfig)
+			for _, key := range tc.platforms {
+				platforms[key] = PlatformConfig{}
+			}
+			got, _ := matchPlatform(platforms, tc.goos, tc.arch)
+			if got != tc.expected {
+				t.Errorf("Expected %q but got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestWindowsArch(t *testing.T) {
+	testCases := []struct {
+		name       string
+		wow64      string
+		arch       string
+		identifier string
+		expected   string
+	}{
+		{"Native x64", "", "AMD64", "Intel64 Family 6 Model 158 Stepping 10, GenuineIntel", "amd64"},
+		{"x86 on x64", "AMD64", "x86", "Intel64 Family 6 Model 158 Stepping 10, GenuineIntel", "amd64"},
+		{"Native ARM", "", "ARM64", "ARMv8 (64-bit) Family 8 Model D4B Revision 0, Qualcomm", "arm64"},
+		{"x64 on ARM", "", "AMD64", "ARMv8 (64-bit) Family 8 Model D4B Revision 0, Qualcomm", "arm64"},
+		{"Unknown", "", "", "", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := windowsArch(tc.wow64, tc.arch, tc.identifier); got != tc.expected {
+				t.Errorf("Expected %q but got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestGetPlatformConfigArch(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "depman-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	configPath := filepath.Join(tempDir, "app-dependencies.yml")
+	config := `
+dependencies:
+  - name: "tool"
+    version:
+      required: "1.0.0"
+    platforms:
+      darwin:
+        installer: {method: "archive", url: "https://example.com/tool_{{os}}_{{arch}}.tar.gz"}
+      darwin/arm64:
+        installer: {method: "archive", url: "https://example.com/tool_apple_silicon.tar.gz"}
+      windows/amd64:
+        installer: {method: "archive", url: "https://example.com/tool_x64.zip"}
+`
+	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	testCases := []struct {
+		platform string
+		expected string
+	}{
+		{"darwin/arm64", "https://example.com/tool_apple_silicon.tar.gz"},
+		{"darwin/amd64", "https://example.com/tool_darwin_amd64.tar.gz"},
+		{"windows/arm64", "https://example.com/tool_x64.zip"},
+		{"windows/386", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.platform, func(t *testing.T) {
+			manager, err := NewManager(configPath, WithPlatform(tc.platform), WithHomeDir(tempDir), WithLogOutput(io.Discard))
+			if err != nil {
+				t.Fatalf("Failed to create manager: %v", err)
+			}
+			dep := manager.FindDependency("tool")
+
+			platformConfig, err := manager.GetPlatformConfig(dep)
+			if tc.expected == "" {
+				if err == nil {
+					t.Errorf("Expected an error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Did not expect an error but got: %v", err)
+			}
+			url := expandURLTemplate(platformConfig.Installer.URL, dep, manager.Platform, manager.targetArch())
+			if url != tc.expected {
+				t.Errorf("Expected %s but got %s", tc.expected, url)
+			}
+		})
+	}
+}
+
+func TestLockfileVerifyArch(t *testing.T) {
+	config := &DependencyConfig{Dependencies: []Dependency{{
+		Name:      "tool",
+		Version:   Version{Required: "1.0.0"},
+		Platforms: map[string]PlatformConfig{"linux": {}, "linux/arm64": {}},
+	}}}
+	lock := &Lockfile{Dependencies: []LockedDependency{{
+		Name:      "tool",
+		Version:   "1.0.0",
+		Digest:    dependencyDigest(&config.Dependencies[0]),
+		Platforms: map[string]LockedArtifact{"linux": {URL: "https://example.com/tool_amd64"}},
+	}}}
+
+	if err := lock.Verify(config, "linux/amd64"); err != nil {
+		t.Errorf("Did not expect an error but got: %v", err)
+	}
+	if err := lock.Verify(config, "linux/arm64"); err == nil {
+		t.Errorf("Expected the arm64 entry to be missing but got no error")
+	}
+}
@@ -0,0 +1,166 @@
+package depman
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/devnadeemashraf/depman/internal/osv"
+)
+
+// AuditConfig names a dependency in the OSV vulnerability database
+type AuditConfig struct {
+	Ecosystem string `yaml:"ecosystem"` // OSV ecosystem, e.g. "Go", "npm" or "PyPI"
+	Package   string `yaml:"package"`   // Package name in the ecosystem (defaults to the dependency name)
+}
+
+// VulnerabilitySeverity ranks how serious a vulnerability is
+type VulnerabilitySeverity int
+
+const (
+	VulnerabilityUnknown  VulnerabilitySeverity = iota // No severity was published
+	VulnerabilityLow                                   // CVSS 0.1-3.9
+	VulnerabilityMedium                                // CVSS 4.0-6.9
+	VulnerabilityHigh                                  // CVSS 7.0-8.9
+	VulnerabilityCritical                              // CVSS 9.0-10.0
+)
+
+var vulnerabilitySeverities = []string{"unknown", "low", "medium", "high", "critical"}
+
+// String returns the lowercase name of the severity
+func (s VulnerabilitySeverity) String() string {
+	if s < 0 || int(s) >= len(vulnerabilitySeverities) {
+		return vulnerabilitySeverities[VulnerabilityUnknown]
+	}
+	return vulnerabilitySeverities[s]
+}
+
+// MarshalText encodes the severity as its name, e.g. in JSON output
+func (s VulnerabilitySeverity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// ParseVulnerabilitySeverity parses a severity name
+func ParseVulnerabilitySeverity(name string) (VulnerabilitySeverity, error) {
+	for i, n := range vulnerabilitySeverities {
+		if strings.EqualFold(name, n) {
+			return VulnerabilitySeverity(i), nil
+		}
+	}
+	return VulnerabilityUnknown, fmt.Errorf("invalid severity '%s' (expected low, medium, high or critical)", name)
+}
+
+// Vulnerability is a known vulnerability affecting an installed version
+type Vulnerability struct {
+	ID       string                `json:"id"`              // OSV identifier
+	Aliases  []string              `json:"aliases"`         // Other identifiers, such as CVE numbers
+	Summary  string                `json:"summary"`         // One-line description
+	Severity VulnerabilitySeverity `json:"severity"`        // How serious the vulnerability is
+	Score    float64               `json:"score,omitempty"` // CVSS v3 base score, if published
+	Fixed    []string              `json:"fixed"`           // Versions that fix the vulnerability
+}
+
+// AuditResult lists the vulnerabilities found for one dependency
+type AuditResult struct {
+	Name            string          `json:"name"`              // Name of the dependency
+	Version         string          `json:"version"`           // Version that was audited
+	Ecosystem       string          `json:"ecosystem"`         // OSV ecosystem queried
+	Package         string          `json:"package"`           // Package name queried
+	Vulnerabilities []Vulnerability `json:"vulnerabilities"`   // Known vulnerabilities of the version
+	Skipped         string          `json:"skipped,omitempty"` // Why the dependency was not audited
+	Error           string          `json:"error,omitempty"`   // Why the lookup failed
+}
+
+// Highest returns the most severe vulnerability severity, or VulnerabilityUnknown if there are none
+func (r *AuditResult) Highest() VulnerabilitySeverity {
+	highest := VulnerabilityUnknown
+	for _, v := range r.Vulnerabilities {
+		if v.Severity > highest {
+			highest = v.Severity
+		}
+	}
+	return highest
+}
+
+// Exceeds reports whether any vulnerability is at least as severe as
+// threshold. Vulnerabilities of unknown severity always count.
+func (r *AuditResult) Exceeds(threshold VulnerabilitySeverity) bool {
+	for _, v := range r.Vulnerabilities {
+		if v.Severity == VulnerabilityUnknown || v.Severity >= threshold {
+			return true
+		}
+	}
+	return false
+}
+
+// Audit looks up known vulnerabilities of each dependency's installed (or
+// else pinned) version in the OSV database. Dependencies without an
+// audit.ecosystem are skipped. The versions come from statuses, which are
+// checked first if nil; their Vulnerabilities are filled in.
+func (m *Manager) Audit(ctx context.Context, statuses map[string]*DependencyStatus) ([]*AuditResult, error) {
+	if statuses == nil {
+		var err error
+		if statuses, err = m.CheckAllDependencies(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	deps, err := m.SelectedDependencies()
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*AuditResult
+	for _, dep := range deps {
+		result := &AuditResult{Name: dep.Name, Ecosystem: dep.Audit.Ecosystem, Package: dep.Audit.Package}
+		results = append(results, result)
+		if result.Package == "" {
+			result.Package = dep.Name
+		}
+
+		status := statuses[dep.Name]
+		if status != nil && status.Installed {
+			result.Version = status.CurrentVersion
+		} else {
+			result.Version = dep.Version.Required
+		}
+
+		switch {
+		case result.Ecosystem == "":
+			result.Skipped = "no audit.ecosystem configured"
+			continue
+		case result.Version == "":
+			result.Skipped = "not installed and no version pinned"
+			continue
+		}
+
+		var found []osv.Vulnerability
+		err := m.retry(ctx, dep, "Vulnerability lookup", func() error {
+			var err error
+			found, err = osv.Query(ctx, m.client(), result.Ecosystem, result.Package, result.Version)
+			return err
+		})
+		if err != nil {
+			result.Error = err.Error()
+			continue
+		}
+
+		result.Vulnerabilities = []Vulnerability{}
+		for _, v := range found {
+			severity, _ := ParseVulnerabilitySeverity(v.Severity)
+			result.Vulnerabilities = append(result.Vulnerabilities, Vulnerability{
+				ID:       v.ID,
+				Aliases:  v.Aliases,
+				Summary:  v.Summary,
+				Severity: severity,
+				Score:    v.Score,
+				Fixed:    v.Fixed,
+			})
+		}
+		if status != nil {
+			status.Vulnerabilities = result.Vulnerabilities
+		}
+	}
+
+	return results, nil
+}
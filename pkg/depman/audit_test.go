@@ -0,0 +1,82 @@
+package depman
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/devnadeemashraf/depman/internal/osv"
+)
+
+func TestAudit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"vulns": [{"id": "GHSA-1111", "database_specific": {"severity": "HIGH"},
+			"affected": [{"package": {"ecosystem": "Go", "name": "github.com/owner/tool"}, "ranges": [{"events": [{"fixed": "1.2.4"}]}]}]}]}`)
+	}))
+	defer server.Close()
+
+	originalURL := osv.APIURL
+	osv.APIURL = server.URL
+	defer func() { osv.APIURL = originalURL }()
+
+	tempDir, err := os.MkdirTemp("", "depman-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	configPath := filepath.Join(tempDir, "app-dependencies.yml")
+	config := `
+dependencies:
+  - name: "tool"
+    version:
+      required: "1.2.3"
+    audit:
+      ecosystem: "Go"
+      package: "github.com/owner/tool"
+    platforms:
+      linux:
+        installer: {method: "system"}
+  - name: "jq"
+    version:
+      required: "1.7.1"
+    platforms:
+      linux:
+        installer: {method: "system"}
+`
+	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	manager, err := NewManager(configPath, WithPlatform("linux"), WithHomeDir(tempDir), WithLogOutput(io.Discard))
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+
+	statuses := map[string]*DependencyStatus{"tool": {Name: "tool"}, "jq": {Name: "jq"}}
+	results, err := manager.Audit(context.Background(), statuses)
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results but got %d", len(results))
+	}
+
+	tool, jq := results[0], results[1]
+	if tool.Version != "1.2.3" || len(tool.Vulnerabilities) != 1 || tool.Vulnerabilities[0].Fixed[0] != "1.2.4" {
+		t.Errorf("Expected one vulnerability of the pinned version but got %+v", tool)
+	}
+	if tool.Highest() != VulnerabilityHigh || !tool.Exceeds(VulnerabilityMedium) || tool.Exceeds(VulnerabilityCritical) {
+		t.Errorf("Expected the highest severity to be high but got %s", tool.Highest())
+	}
+	if len(statuses["tool"].Vulnerabilities) != 1 {
+		t.Errorf("Expected the status to carry the vulnerabilities")
+	}
+	if jq.Skipped == "" {
+		t.Errorf("Expected jq without an ecosystem to be skipped")
+	}
+}
@@ -0,0 +1,165 @@
+package depman
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// codeConfigFile names configurations built in code in validation issues
+const codeConfigFile = "<code>"
+
+// NewConfig returns a configuration holding deps, for embedders that build
+// their dependencies in Go code instead of loading a file. Pass it to
+// NewManager with WithConfig.
+func NewConfig(name string, deps ...Dependency) *DependencyConfig {
+	return &DependencyConfig{Version: "1.0", Name: name, Dependencies: deps}
+}
+
+// Add appends dependencies to the configuration and returns it
+func (c *DependencyConfig) Add(deps ...Dependency) *DependencyConfig {
+	c.Dependencies = append(c.Dependencies, deps...)
+	return c
+}
+
+// NewDependency returns a tool dependency satisfied by versions matching the
+// constraint, e.g. "^1.6" or ">=2.0.0". Add how to install it on each
+// platform with OnPlatform.
+func NewDependency(name, constraint string) Dependency {
+	return Dependency{Name: name, Version: Version{Constraint: constraint}}
+}
+
+// OnPlatform returns the dependency with the platform configuration for a
+// platform key such as "linux" or "darwin/arm64"
+func (d Dependency) OnPlatform(platform string, config PlatformConfig) Dependency {
+	platforms := make(map[string]PlatformConfig, len(d.Platforms)+1)
+	for key, value := range d.Platforms {
+		platforms[key] = value
+	}
+	platforms[platform] = config
+	d.Platforms = platforms
+	return d
+}
+
+// DependsOn returns the dependency installed after the named dependencies
+func (d Dependency) DependsOn(names ...string) Dependency {
+	d.Dependencies = append(append([]string(nil), d.Dependencies...), names...)
+	return d
+}
+
+// Tagged returns the dependency with tags added, e.g. "build" or "docs"
+func (d Dependency) Tagged(tags ...string) Dependency {
+	d.Tags = append(append([]string(nil), d.Tags...), tags...)
+	return d
+}
+
+// OnlyWhen returns the dependency applied only on machines where the
+// condition holds, e.g. `platform == "darwin" && arch == "arm64"`
+func (d Dependency) OnlyWhen(condition string) Dependency {
+	d.When = condition
+	return d
+}
+
+// WithConfig makes the manager use a configuration built in code instead of
+// loading a file. NewManager validates it like a file; the lockfile and
+// project-scoped installs are placed in the working directory.
+func WithConfig(config *DependencyConfig) Option {
+	return func(m *Manager) {
+		m.Config = config
+	}
+}
+
+// Validate checks the configuration against the same schema and rules as
+// configuration files. Problems are returned as a *ValidationError.
+func (c *DependencyConfig) Validate() error {
+	var root yaml.Node
+	if err := root.Encode(c); err != nil {
+		return fmt.Errorf("failed to encode configuration: %w", err)
+	}
+
+	// Unset fields would otherwise read as set to empty values
+	pruneEmpty(&root)
+
+	if err := checkRequirements(codeConfigFile, &root); err != nil {
+		return err
+	}
+
+	v := &schemaValidator{file: codeConfigFile}
+	if len(c.Includes) > 0 {
+		v.addIssue(nil, "includes", "includes are only supported in configuration files")
+	}
+	v.validateNode(&root, reflect.TypeOf(DependencyConfig{}), "")
+	v.validateSemantics(&root)
+	if len(v.issues) > 0 {
+		return &ValidationError{Issues: v.issues}
+	}
+
+	// Every profile must apply cleanly, as in ValidateConfigFile
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, err := applyProfile(c, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Encode returns the configuration as YAML, leaving out unset fields
+func (c *DependencyConfig) Encode() ([]byte, error) {
+	return encodePruned(c)
+}
+
+// Encode returns the dependency as YAML, leaving out unset fields
+func (d Dependency) Encode() ([]byte, error) {
+	return encodePruned(d)
+}
+
+// encodePruned encodes v as YAML without its zero values and empty collections
+func encodePruned(v interface{}) ([]byte, error) {
+	var root yaml.Node
+	if err := root.Encode(v); err != nil {
+		return nil, fmt.Errorf("failed to encode configuration: %w", err)
+	}
+	pruneEmpty(&root)
+	return yaml.Marshal(&root)
+}
+
+// pruneEmpty removes zero values and empty collections from an encoded
+// mapping, reporting whether anything is left of the node
+func pruneEmpty(node *yaml.Node) bool {
+	switch node.Kind {
+	case yaml.MappingNode:
+		kept := node.Content[:0]
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if pruneEmpty(node.Content[i+1]) {
+				kept = append(kept, node.Content[i], node.Content[i+1])
+			}
+		}
+		node.Content = kept
+		return len(kept) > 0
+	case yaml.SequenceNode:
+		// Items are kept so list positions stay meaningful
+		for _, item := range node.Content {
+			pruneEmpty(item)
+		}
+		return len(node.Content) > 0
+	case yaml.ScalarNode:
+		switch node.Tag {
+		case "!!null":
+			return false
+		case "!!str":
+			return node.Value != ""
+		case "!!bool":
+			return node.Value != "false"
+		case "!!int", "!!float":
+			return node.Value != "0"
+		}
+	}
+	return true
+}
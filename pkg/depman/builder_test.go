@@ -0,0 +1,63 @@
+package depman
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestBuildConfig(t *testing.T) {
+	linux := PlatformConfig{Commands: Commands{
+		Install: []string{"apt-get", "install", "-y", "jq"},
+		Verify:  []string{"jq", "--version"},
+	}}
+	config := NewConfig("Generated App", NewDependency("jq", "^1.6").OnPlatform("linux", linux).Tagged("build"))
+	config.Add(NewDependency("yq", ">=4.0.0").OnPlatform("linux", linux).DependsOn("jq"))
+
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+
+	manager, err := NewManager("", WithConfig(config), WithPlatform("linux"), WithHomeDir(t.TempDir()))
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if dep := manager.FindDependency("yq"); dep == nil || dep.Dependencies[0] != "jq" {
+		t.Errorf("Expected yq to depend on jq but got %+v", dep)
+	}
+	if manager.ConfigPath != "" {
+		t.Errorf("Expected no configuration path but got %s", manager.ConfigPath)
+	}
+
+	// Builders copy rather than share the lists they extend
+	base := NewDependency("go", "^1.22").Tagged("build")
+	docs := base.Tagged("docs")
+	if len(base.Tags) != 1 || len(docs.Tags) != 2 {
+		t.Errorf("Expected tags to be copied but got %v and %v", base.Tags, docs.Tags)
+	}
+}
+
+func TestValidateBuiltConfig(t *testing.T) {
+	config := NewConfig("Generated App",
+		NewDependency("jq", "not a constraint"),
+		Dependency{Name: "jq", Timeout: "soon", Platforms: map[string]PlatformConfig{"plan9": {}}},
+	)
+
+	err := config.Validate()
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Expected a validation error but got: %v", err)
+	}
+	for _, expected := range []string{"version.constraint", "missing required field 'platforms'", "duplicate dependency 'jq'", "invalid timeout", "missing required field 'version.required'"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected %q in:\n%v", expected, err)
+		}
+	}
+	if verr.Issues[0].File != codeConfigFile || verr.Issues[0].Line != 0 {
+		t.Errorf("Expected issues without positions but got %+v", verr.Issues[0])
+	}
+
+	if _, err := NewManager("", WithConfig(config)); !errors.As(err, &verr) {
+		t.Errorf("Expected NewManager to reject the configuration but got: %v", err)
+	}
+}
@@ -0,0 +1,284 @@
+package depman
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/devnadeemashraf/depman/internal/archive"
+	"github.com/devnadeemashraf/depman/internal/cache"
+	"github.com/devnadeemashraf/depman/internal/downloader"
+	"github.com/devnadeemashraf/depman/internal/verify"
+)
+
+// bundleManifestFile is the name of the manifest inside a bundle
+const bundleManifestFile = "manifest.json"
+
+// BundleManifest describes the contents of an offline bundle
+type BundleManifest struct {
+	Platform  string           `json:"platform"`          // Platform the artifacts were selected for
+	Arch      string           `json:"arch"`              // Architecture the artifacts were selected for
+	Artifacts []BundleArtifact `json:"artifacts"`         // Bundled artifacts
+	Skipped   []string         `json:"skipped,omitempty"` // Dependencies whose install method cannot be bundled
+}
+
+// BundleArtifact is a single file in an offline bundle
+type BundleArtifact struct {
+	Dependency string `json:"dependency"`          // Dependency the artifact belongs to
+	Version    string `json:"version"`             // Version the artifact installs
+	URL        string `json:"url"`                 // URL the artifact was downloaded from
+	Checksum   string `json:"checksum"`            // Checksum of the artifact ("algorithm:hash")
+	File       string `json:"file"`                // Path of the artifact inside the bundle
+	Signature  bool   `json:"signature,omitempty"` // Whether the artifact is a detached signature
+}
+
+// bundleMethods lists the install methods whose artifacts can be bundled
+var bundleMethods = map[string]bool{
+	"archive":        true,
+	"github-release": true,
+	"command":        true,
+	"native":         true,
+}
+
+// WithOfflineBundle installs exclusively from the artifacts in an offline
+// bundle created by CreateBundle, without touching the network
+func WithOfflineBundle(path string) Option {
+	return func(m *Manager) {
+		m.bundlePath = path
+	}
+}
+
+// CreateBundle downloads and verifies every artifact the configuration needs
+// on the current platform and packs them into a gzip-compressed tarball at
+// path. Dependencies installed through package managers or plugins are listed
+// as skipped.
+func (m *Manager) CreateBundle(ctx context.Context, path string) (*BundleManifest, error) {
+	if err := m.validateConfiguration(); err != nil {
+		return nil, fmt.Errorf("invalid dependency configuration: %w", err)
+	}
+
+	stageDir, err := os.MkdirTemp("", "depman-bundle-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(stageDir)
+
+	deps, err := m.SelectedDependencies()
+	if err != nil {
+		return nil, err
+	}
+	manifest := &BundleManifest{Platform: m.Platform, Arch: m.targetArch()}
+	for _, dep := range deps {
+		platformConfig, err := m.resolvedPlatformConfig(dep)
+		if err != nil {
+			return nil, fmt.Errorf("failed to bundle %s: %w", dep.Name, err)
+		}
+
+		method := installMethod(platformConfig)
+		if !bundleMethods[method] {
+			manifest.Skipped = append(manifest.Skipped, fmt.Sprintf("%s (%s)", dep.Name, method))
+			continue
+		}
+
+		url, version, err := m.artifactSource(ctx, dep, platformConfig, method)
+		if err != nil {
+			return nil, fmt.Errorf("failed to bundle %s: %w", dep.Name, err)
+		}
+		if url == "" {
+			continue
+		}
+
+		artifacts, err := m.bundleArtifact(ctx, dep, platformConfig, url, version, stageDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to bundle %s: %w", dep.Name, err)
+		}
+		manifest.Artifacts = append(manifest.Artifacts, artifacts...)
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(stageDir, bundleManifestFile), data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write bundle manifest: %w", err)
+	}
+
+	if err := archive.CreateTarGz(path, stageDir); err != nil {
+		return nil, err
+	}
+
+	m.logger.Infof("Bundled %d artifacts into %s", len(manifest.Artifacts), path)
+	return manifest, nil
+}
+
+// artifactSource returns the URL and version of the artifact a dependency
+// installs from, or an empty URL if it downloads nothing
+func (m *Manager) artifactSource(ctx context.Context, dep *Dependency, platformConfig *PlatformConfig, method string) (string, string, error) {
+	switch method {
+	case "archive", "native":
+		return expandURLTemplate(platformConfig.Installer.URL, dep, m.Platform, m.targetArch()), dep.Version.Required, nil
+	case "github-release":
+		return githubReleaseInstaller{}.resolve(ctx, m, dep, platformConfig)
+	default:
+		return platformConfig.Installer.URL, dep.Version.Required, nil
+	}
+}
+
+// bundleArtifact downloads and verifies an artifact, and its signature if one
+// is configured, into stageDir
+func (m *Manager) bundleArtifact(ctx context.Context, dep *Dependency, platformConfig *PlatformConfig, url, version, stageDir string) ([]BundleArtifact, error) {
+	tempDir, err := os.MkdirTemp("", "depman-download-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	result, err := m.downloadArtifact(ctx, dep, platformConfig, url, platformConfig.Installer.Checksum, tempDir)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []BundleArtifact{{Dependency: dep.Name, Version: version, URL: url, Checksum: result.Checksum}}
+	paths := []string{result.FilePath}
+
+	if platformConfig.Installer.SignatureURL != "" {
+		signatureURL := m.expandArtifactURL(platformConfig.Installer.SignatureURL, dep, url)
+		source, err := m.fetchURL(signatureURL, dep)
+		if err != nil {
+			return nil, err
+		}
+		signature, err := downloader.Download(ctx, downloader.DownloadOptions{
+			URL:     source,
+			DestDir: filepath.Join(tempDir, "signature"),
+			Client:  m.client(),
+			Limiter: m.limiter,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to download signature: %w", err)
+		}
+		files = append(files, BundleArtifact{Dependency: dep.Name, Version: version, URL: signatureURL, Checksum: signature.Checksum, Signature: true})
+		paths = append(paths, signature.FilePath)
+	}
+
+	for i := range files {
+		files[i].File = filepath.ToSlash(filepath.Join("artifacts", cache.Key(files[i].URL, files[i].Checksum), filepath.Base(paths[i])))
+		target := filepath.Join(stageDir, filepath.FromSlash(files[i].File))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return nil, fmt.Errorf("failed to stage artifact: %w", err)
+		}
+		if err := os.Rename(paths[i], target); err != nil {
+			return nil, fmt.Errorf("failed to stage artifact: %w", err)
+		}
+	}
+	return files, nil
+}
+
+// loadBundle unpacks the offline bundle, if one is configured, and adds its
+// artifacts to the download cache so installs can find them by URL
+func (m *Manager) loadBundle() error {
+	if m.bundlePath == "" || m.bundle != nil {
+		return nil
+	}
+
+	extractDir, err := os.MkdirTemp("", "depman-bundle-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(extractDir)
+
+	if err := archive.ExtractAs(m.bundlePath, extractDir, archive.FormatTarGz); err != nil {
+		return fmt.Errorf("failed to open bundle %s: %w", m.bundlePath, err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(extractDir, bundleManifestFile))
+	if err != nil {
+		return fmt.Errorf("failed to read bundle manifest: %w", err)
+	}
+	var manifest BundleManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return fmt.Errorf("failed to parse bundle manifest: %w", err)
+	}
+	if manifest.Platform != m.Platform || manifest.Arch != m.targetArch() {
+		return fmt.Errorf("bundle %s was created for %s/%s, not %s",
+			m.bundlePath, manifest.Platform, manifest.Arch, m.PlatformTarget())
+	}
+
+	downloads := cache.New(m.CacheDir())
+	for _, artifact := range manifest.Artifacts {
+		path := filepath.Join(extractDir, filepath.FromSlash(artifact.File))
+		if err := verify.Checksum(path, artifact.Checksum); err != nil {
+			return fmt.Errorf("bundled artifact %s is corrupt: %w", artifact.URL, err)
+		}
+		if err := downloads.Store(artifact.URL, artifact.Checksum, path); err != nil {
+			return err
+		}
+	}
+
+	m.bundle = &manifest
+	m.logger.Infof("Loaded %d artifacts from bundle %s", len(manifest.Artifacts), m.bundlePath)
+	return nil
+}
+
+// bundledArtifact returns the bundle entry for an artifact URL
+func (m *Manager) bundledArtifact(url string) *BundleArtifact {
+	for i := range m.bundle.Artifacts {
+		if m.bundle.Artifacts[i].URL == url {
+			return &m.bundle.Artifacts[i]
+		}
+	}
+	return nil
+}
+
+// bundledDependency returns the bundle entry of a dependency's main artifact
+func (m *Manager) bundledDependency(name string) *BundleArtifact {
+	for i := range m.bundle.Artifacts {
+		if artifact := &m.bundle.Artifacts[i]; artifact.Dependency == name && !artifact.Signature {
+			return artifact
+		}
+	}
+	return nil
+}
+
+// offlineArtifact copies a bundled artifact into destDir in place of a
+// download, checking it against checksum or, if none is configured, the
+// checksum recorded when the bundle was created
+func (m *Manager) offlineArtifact(dep *Dependency, url, checksum, destDir string) (*downloader.Result, error) {
+	artifact := m.bundledArtifact(url)
+	if artifact == nil {
+		return nil, fmt.Errorf("%s is not in the offline bundle", url)
+	}
+	if checksum == "" {
+		checksum = artifact.Checksum
+	}
+
+	path, ok := cache.New(m.CacheDir()).Lookup(url, checksum, destDir)
+	if !ok {
+		return nil, &VerificationError{Dependency: dep.Name, URL: url, Kind: "checksum",
+			Err: fmt.Errorf("bundled artifact does not match checksum %s", checksum)}
+	}
+
+	result := &downloader.Result{FilePath: path, Checksum: checksum}
+	if info, err := os.Stat(path); err == nil {
+		result.Size = info.Size()
+	}
+	m.componentLogger("download", dep).Infof("Using bundled %s from %s", dep.Name, url)
+	return result, nil
+}
+
+// pinnedVersion returns the version a frozen lockfile or offline bundle
+// records for a dependency, falling back to the required version
+func (m *Manager) pinnedVersion(dep *Dependency) string {
+	if m.lock != nil {
+		if entry := m.lock.Find(dep.Name); entry != nil {
+			return entry.Version
+		}
+	}
+	if m.bundle != nil {
+		if artifact := m.bundledDependency(dep.Name); artifact != nil {
+			return artifact.Version
+		}
+	}
+	return dep.Version.Required
+}
@@ -0,0 +1,106 @@
+package depman
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// TestOfflineBundle tests that a bundle created online installs without network access
+func TestOfflineBundle(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses shell shims")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("#!/bin/sh\necho tool 1.2.3\n"))
+	}))
+
+	tempDir, err := os.MkdirTemp("", "depman-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	configPath := filepath.Join(tempDir, "app-dependencies.yml")
+	config := `
+name: "Test App"
+dependencies:
+  - name: "tool"
+    version:
+      required: "1.2.3"
+    platforms:
+      linux:
+        installer:
+          method: "archive"
+          url: "` + server.URL + `/{{version}}/tool"
+  - name: "git"
+    version:
+      constraint: ">=2.0.0"
+    platforms:
+      linux:
+        installer:
+          method: "apt"
+`
+	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	online, err := NewManager(configPath, WithPlatform("linux"), WithHomeDir(filepath.Join(tempDir, "online")), WithLogOutput(io.Discard))
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+
+	bundlePath := filepath.Join(tempDir, "bundle.tar.gz")
+	manifest, err := online.CreateBundle(context.Background(), bundlePath)
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if len(manifest.Artifacts) != 1 || manifest.Artifacts[0].Dependency != "tool" || manifest.Artifacts[0].Version != "1.2.3" {
+		t.Errorf("Expected the tool artifact to be bundled but got %+v", manifest.Artifacts)
+	}
+	if len(manifest.Skipped) != 1 || !strings.HasPrefix(manifest.Skipped[0], "git") {
+		t.Errorf("Expected git to be skipped but got %v", manifest.Skipped)
+	}
+
+	// From here on nothing may be downloaded
+	server.Close()
+
+	offline, err := NewManager(configPath, WithPlatform("linux"), WithHomeDir(filepath.Join(tempDir, "offline")),
+		WithLogOutput(io.Discard), WithOfflineBundle(bundlePath))
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+
+	statuses, err := offline.InstallDependencies(context.Background(), []string{"tool"}, false)
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if status := statuses["tool"]; !status.Installed || status.CurrentVersion != "1.2.3" {
+		t.Errorf("Expected tool 1.2.3 to be installed from the bundle but got %+v", status)
+	}
+
+	t.Run("Package managers are refused", func(t *testing.T) {
+		_, err := offline.installDependency(context.Background(), offline.FindDependency("git"))
+		if err == nil || !strings.Contains(err.Error(), "cannot be used offline") {
+			t.Errorf("Expected an offline error but got %v", err)
+		}
+	})
+
+	t.Run("Bundle for another platform", func(t *testing.T) {
+		other, err := NewManager(configPath, WithPlatform("darwin"), WithHomeDir(filepath.Join(tempDir, "other")),
+			WithLogOutput(io.Discard), WithOfflineBundle(bundlePath))
+		if err != nil {
+			t.Fatalf("Failed to create manager: %v", err)
+		}
+		if err := other.loadBundle(); err == nil || !strings.Contains(err.Error(), "was created for linux") {
+			t.Errorf("Expected a platform mismatch but got %v", err)
+		}
+	})
+}
@@ -0,0 +1,145 @@
+package depman
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"gopkg.in/yaml.v3"
+)
+
+// ToolVersion is the version of depman that configurations' requires_depman
+// is checked against. The CLI sets it to its release version. Development
+// builds, whose version is not a semantic version, satisfy every requirement.
+var ToolVersion = "dev"
+
+// supportedFeatures are the names a configuration can list under features,
+// each with what it enables
+var supportedFeatures = map[string]string{
+	"includes":    "includes and local overlays",
+	"profiles":    "profiles",
+	"kinds":       "image, service and requirement dependencies",
+	"healthcheck": "post-install health checks",
+	"when":        "conditional dependencies",
+	"provides":    "provides and conflicts_with",
+	"webhooks":    "webhooks",
+	"credentials": "credentials for private artifact hosts",
+	"mirrors":     "mirrors rewriting artifact URLs",
+	"registry":    "dependencies based on registry recipes",
+	"tasks":       "tasks run by depman run",
+}
+
+// SupportedFeatures returns the feature names this build of depman supports, sorted
+func SupportedFeatures() []string {
+	names := make([]string, 0, len(supportedFeatures))
+	for name := range supportedFeatures {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CompatibilityError reports a configuration that needs a newer depman than
+// the one loading it
+type CompatibilityError struct {
+	File     string   // Configuration file
+	Requires string   // Constraint on the depman version it is not satisfied by, if any
+	Version  string   // Version of this depman
+	Missing  []string // Features it needs that this depman does not support
+}
+
+func (e *CompatibilityError) Error() string {
+	if e.Requires != "" {
+		return fmt.Sprintf("%s requires depman %s, but this is depman %s; upgrade with 'depman self-update'",
+			e.File, e.Requires, e.Version)
+	}
+	return fmt.Sprintf("%s needs features depman %s does not support (%s); upgrade with 'depman self-update'",
+		e.File, e.Version, strings.Join(e.Missing, ", "))
+}
+
+// checkRequirements fails fast if the configuration in root needs a newer
+// depman, before fields this version may not understand are looked at
+func checkRequirements(file string, root *yaml.Node) error {
+	if root == nil || root.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	if requires := mappingValue(root, "requires_depman"); requires != nil && requires.Value != "" {
+		constraint, err := ParseConstraint(requires.Value)
+		version, versionErr := semver.NewVersion(ToolVersion)
+		// Invalid constraints are reported by the schema check
+		if err == nil && versionErr == nil && !constraint.Check(version) {
+			return &CompatibilityError{File: file, Requires: requires.Value, Version: ToolVersion}
+		}
+	}
+
+	if features := mappingValue(root, "features"); features != nil && features.Kind == yaml.SequenceNode {
+		var missing []string
+		for _, feature := range features.Content {
+			if _, ok := supportedFeatures[feature.Value]; !ok {
+				missing = append(missing, feature.Value)
+			}
+		}
+		if len(missing) > 0 {
+			return &CompatibilityError{File: file, Version: ToolVersion, Missing: missing}
+		}
+	}
+	return nil
+}
+
+// Compatibility describes which versions of depman can load a configuration
+type Compatibility struct {
+	RequiresDepman string   // Constraint on the depman version, or "" if there is none
+	Version        string   // Version of this depman
+	Declared       []string // Features the configuration lists under features
+	Used           []string // Features the configuration uses
+	Undeclared     []string // Used features it does not list; depman versions without them fail with unclear errors
+}
+
+// CheckCompatibility reports what a configuration needs from depman. Loading
+// already rejects configurations this version cannot satisfy; the report
+// shows what older versions need and which features are worth declaring.
+func CheckCompatibility(config *DependencyConfig) *Compatibility {
+	report := &Compatibility{
+		RequiresDepman: config.RequiresDepman,
+		Version:        ToolVersion,
+		Declared:       append([]string(nil), config.Features...),
+		Used:           usedFeatures(config),
+	}
+	sort.Strings(report.Declared)
+	for _, feature := range report.Used {
+		if !slices.Contains(config.Features, feature) {
+			report.Undeclared = append(report.Undeclared, feature)
+		}
+	}
+	return report
+}
+
+// usedFeatures returns the features a configuration uses, sorted
+func usedFeatures(config *DependencyConfig) []string {
+	used := make(map[string]bool)
+	used["includes"] = len(config.Includes) > 0
+	used["profiles"] = len(config.Profiles) > 0
+	used["webhooks"] = len(config.Webhooks) > 0
+	used["credentials"] = len(config.Credentials) > 0
+	used["mirrors"] = len(config.Mirrors) > 0
+	used["tasks"] = len(config.Tasks) > 0
+	for _, dep := range config.Dependencies {
+		used["kinds"] = used["kinds"] || (dep.Kind != "" && dep.Kind != string(KindTool))
+		used["healthcheck"] = used["healthcheck"] || dep.Healthcheck.isSet()
+		used["when"] = used["when"] || dep.When != ""
+		used["provides"] = used["provides"] || len(dep.Provides) > 0 || len(dep.ConflictsWith) > 0
+		used["registry"] = used["registry"] || dep.From != ""
+	}
+
+	var names []string
+	for name, ok := range used {
+		if ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
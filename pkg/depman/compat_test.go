@@ -0,0 +1,92 @@
+package depman
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRequiresDepman(t *testing.T) {
+	config := `
+version: "1.0"
+name: "Test App"
+requires_depman: ">=0.5"
+features: ["when"]
+dependencies:
+  - name: "jq"
+    when: 'platform == "linux"'
+    future_field: true
+    version:
+      constraint: "^1.6"
+    platforms:
+      linux:
+        installer:
+          method: "system"
+`
+	path := filepath.Join(t.TempDir(), "app-dependencies.yml")
+	if err := os.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	testCases := []struct {
+		version  string
+		expected string
+	}{
+		{version: "0.4.2", expected: "requires depman >=0.5, but this is depman 0.4.2"},
+		// Newer and development builds get past the gate to the schema check
+		{version: "0.5.0", expected: "unknown field 'future_field'"},
+		{version: "dev", expected: "unknown field 'future_field'"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.version, func(t *testing.T) {
+			previous := ToolVersion
+			ToolVersion = tc.version
+			defer func() { ToolVersion = previous }()
+
+			_, err := LoadDependencyConfig(path)
+			if err == nil || !strings.Contains(err.Error(), tc.expected) {
+				t.Errorf("Expected an error containing %q but got %v", tc.expected, err)
+			}
+
+			var compatErr *CompatibilityError
+			if errors.As(err, &compatErr) != strings.Contains(tc.expected, "requires depman") {
+				t.Errorf("Expected a *CompatibilityError only for a too old depman but got %T", err)
+			}
+		})
+	}
+}
+
+func TestUnsupportedFeatures(t *testing.T) {
+	config := NewConfig("Test App", NewDependency("jq", "^1.6"))
+	config.Features = []string{"when", "teleport"}
+
+	err := config.Validate()
+	var compatErr *CompatibilityError
+	if !errors.As(err, &compatErr) || strings.Join(compatErr.Missing, ",") != "teleport" {
+		t.Fatalf("Expected teleport to be unsupported but got %v", err)
+	}
+}
+
+func TestCheckCompatibility(t *testing.T) {
+	config := NewConfig("Test App",
+		NewDependency("jq", "^1.6").OnlyWhen(`platform == "linux"`),
+		NewDependency("docker", "*"),
+	)
+	config.RequiresDepman = ">=0.5"
+	config.Features = []string{"when"}
+	config.Dependencies[1].Healthcheck = HealthCheck{Command: []string{"docker", "info"}}
+
+	report := CheckCompatibility(config)
+	if report.RequiresDepman != ">=0.5" {
+		t.Errorf("Expected the version constraint to be reported but got %q", report.RequiresDepman)
+	}
+	if strings.Join(report.Used, ",") != "healthcheck,when" {
+		t.Errorf("Expected healthcheck and when to be used but got %v", report.Used)
+	}
+	if strings.Join(report.Undeclared, ",") != "healthcheck" {
+		t.Errorf("Expected healthcheck to be undeclared but got %v", report.Undeclared)
+	}
+}
@@ -4,13 +4,31 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"strings"
 
+	"github.com/BurntSushi/toml"
 	"github.com/Masterminds/semver/v3"
 	"gopkg.in/yaml.v3"
 )
 
+// ConfigFormat identifies the syntax of a dependency configuration file
+type ConfigFormat string
+
+// Supported configuration formats
+const (
+	FormatYAML ConfigFormat = "yaml"
+	FormatTOML ConfigFormat = "toml"
+	FormatJSON ConfigFormat = "json"
+)
+
+// configBaseName is the file name of the dependency configuration without extension
+const configBaseName = "app-dependencies"
+
+// configExtensions are the recognized configuration file extensions, in lookup order
+var configExtensions = []string{".yml", ".yaml", ".toml", ".json"}
+
 // LoadDependencyConfig loads and parses the dependency configuration file
 func LoadDependencyConfig(path string) (*DependencyConfig, error) {
 	// Find the file if path is not provided
@@ -22,56 +40,132 @@ func LoadDependencyConfig(path string) (*DependencyConfig, error) {
 		}
 	}
 
-	// Read the file
-	data, err := os.ReadFile(path)
+	// Load the file together with its includes and local overlay
+	config, err := loadConfigFile(path)
+	if err != nil || len(config.Workspace.Projects) == 0 {
+		return config, err
+	}
+
+	// A workspace brings in the dependencies of its projects
+	return loadWorkspace(config, path)
+}
+
+// ParseDependencyConfig parses configuration data in the given format without
+// schema validation. All formats share the YAML schema.
+func ParseDependencyConfig(data []byte, format ConfigFormat) (*DependencyConfig, error) {
+	converted, err := toYAML(data, format)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read dependency file: %w", err)
+		return nil, err
 	}
 
-	// Parse YAML
 	var config DependencyConfig
-	if err := yaml.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse dependency file: %w", err)
+	if err := yaml.Unmarshal(converted, &config); err != nil {
+		return nil, err
 	}
 
 	return &config, nil
 }
 
-// FindDependencyFile looks for the app-dependencies.yml file in standard locations
+// toYAML converts configuration data to YAML. TOML documents are decoded
+// generically and re-encoded; JSON is a subset of YAML and is returned as-is.
+func toYAML(data []byte, format ConfigFormat) ([]byte, error) {
+	if format != FormatTOML {
+		return data, nil
+	}
+
+	var doc map[string]interface{}
+	if _, err := toml.Decode(string(data), &doc); err != nil {
+		return nil, fmt.Errorf("invalid TOML: %w", err)
+	}
+
+	converted, err := yaml.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert TOML: %w", err)
+	}
+
+	return converted, nil
+}
+
+// DetectConfigFormat determines the format of a configuration file from its
+// extension, falling back to sniffing the content
+func DetectConfigFormat(path string, data []byte) ConfigFormat {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yml", ".yaml":
+		return FormatYAML
+	case ".toml":
+		return FormatTOML
+	case ".json":
+		return FormatJSON
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line, "{"):
+			return FormatJSON
+		case strings.HasPrefix(line, "["), tomlAssignment.MatchString(line):
+			return FormatTOML
+		default:
+			return FormatYAML
+		}
+	}
+
+	return FormatYAML
+}
+
+// tomlAssignment matches a TOML "key = value" line
+var tomlAssignment = regexp.MustCompile(`^[A-Za-z0-9_."-]+\s*=`)
+
+// FindDependencyFile looks for the dependency configuration file in standard
+// locations. A custom path may name the file itself, the file without its
+// extension, or a directory containing it.
 func FindDependencyFile(customPath string) (string, error) {
-	// If a custom path is provided, check it first
+	// If a custom path is provided, it must resolve; there is no fallback search
 	if customPath != "" {
-		if _, err := os.Stat(customPath); err == nil {
-			return customPath, nil
+		if info, err := os.Stat(customPath); err == nil {
+			if !info.IsDir() {
+				return customPath, nil
+			}
+			if path, ok := findConfigIn(customPath); ok {
+				return path, nil
+			}
+			return "", fmt.Errorf("dependency configuration file not found in %s", customPath)
 		}
-		// If custom path has no extension, try with .yml extension
-		if !strings.HasSuffix(customPath, ".yml") && !strings.HasSuffix(customPath, ".yaml") {
-			withExt := customPath + ".yml"
-			if _, err := os.Stat(withExt); err == nil {
-				return withExt, nil
+
+		// If custom path has no extension, try the known extensions
+		if filepath.Ext(customPath) == "" {
+			for _, ext := range configExtensions {
+				if _, err := os.Stat(customPath + ext); err == nil {
+					return customPath + ext, nil
+				}
 			}
 		}
+
+		return "", fmt.Errorf("dependency configuration file not found: %s", customPath)
 	}
 
 	// Standard locations to check
-	searchPaths := []string{
-		"app-dependencies.yml",           // Current directory
-		"config/app-dependencies.yml",    // Config subdirectory
-		"../app-dependencies.yml",        // Parent directory
-		"../config/app-dependencies.yml", // Parent's config subdirectory
-		filepath.Join(os.Getenv("HOME"), ".config/depman/app-dependencies.yml"), // User config directory
+	searchDirs := []string{
+		".",         // Current directory
+		"config",    // Config subdirectory
+		"..",        // Parent directory
+		"../config", // Parent's config subdirectory
+		filepath.Join(os.Getenv("HOME"), ".config", "depman"), // User config directory
 	}
 
 	// On Windows, also check AppData
 	if runtime.GOOS == "windows" {
 		if appData := os.Getenv("APPDATA"); appData != "" {
-			searchPaths = append(searchPaths, filepath.Join(appData, "depman", "app-dependencies.yml"))
+			searchDirs = append(searchDirs, filepath.Join(appData, "depman"))
 		}
 	}
 
-	// Check each path
-	for _, path := range searchPaths {
-		if _, err := os.Stat(path); err == nil {
+	// Check each location
+	for _, dir := range searchDirs {
+		if path, ok := findConfigIn(dir); ok {
 			return path, nil
 		}
 	}
@@ -79,6 +173,17 @@ func FindDependencyFile(customPath string) (string, error) {
 	return "", fmt.Errorf("dependency configuration file not found")
 }
 
+// findConfigIn returns the configuration file in dir, trying each known extension
+func findConfigIn(dir string) (string, bool) {
+	for _, ext := range configExtensions {
+		path := filepath.Join(dir, configBaseName+ext)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, true
+		}
+	}
+	return "", false
+}
+
 // CheckVersionUpdate determines if and what type of update is needed
 func CheckVersionUpdate(currentVersion, requiredVersion string) (UpdateType, error) {
 	// Parse versions
@@ -119,9 +224,9 @@ func IsVersionCompatible(currentVersion, constraintStr string) (bool, error) {
 	}
 
 	// Parse constraint
-	constraint, err := semver.NewConstraint(constraintStr)
+	constraint, err := ParseConstraint(constraintStr)
 	if err != nil {
-		return false, fmt.Errorf("invalid constraint '%s': %w", constraintStr, err)
+		return false, err
 	}
 
 	// Check if version satisfies constraint
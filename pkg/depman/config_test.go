@@ -201,3 +201,81 @@ dependencies:
 		})
 	}
 }
+
+func TestLoadDependencyConfigFormats(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "depman-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	tomlConfig := `
+version = "1.0"
+name = "TOML App"
+
+[[dependencies]]
+name = "test-dep"
+version = { required = "1.0.0", constraint = "^1.0.0" }
+
+[dependencies.platforms.linux.installer]
+method = "archive"
+url = "https://example.com/test.tar.gz"
+`
+	jsonConfig := `{
+  "version": "1.0",
+  "name": "JSON App",
+  "dependencies": [
+    {
+      "name": "test-dep",
+      "version": {"required": "1.0.0", "constraint": "^1.0.0"},
+      "platforms": {"linux": {"installer": {"method": "archive", "url": "https://example.com/test.tar.gz"}}}
+    }
+  ]
+}`
+
+	testCases := []struct {
+		name     string
+		file     string
+		content  string
+		format   ConfigFormat
+		expected string
+	}{
+		{name: "TOML by extension", file: "app-dependencies.toml", content: tomlConfig, format: FormatTOML, expected: "TOML App"},
+		{name: "JSON by extension", file: "app-dependencies.json", content: jsonConfig, format: FormatJSON, expected: "JSON App"},
+		{name: "TOML by content", file: "deps-toml", content: tomlConfig, format: FormatTOML, expected: "TOML App"},
+		{name: "JSON by content", file: "deps-json", content: jsonConfig, format: FormatJSON, expected: "JSON App"},
+		{name: "YAML by content", file: "deps-yaml", content: "# comment\nversion: \"1.0\"\nname: \"YAML App\"\n", format: FormatYAML, expected: "YAML App"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(tempDir, tc.file)
+			if err := os.WriteFile(path, []byte(tc.content), 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+
+			if format := DetectConfigFormat(path, []byte(tc.content)); format != tc.format {
+				t.Errorf("Expected format %s but got %s", tc.format, format)
+			}
+
+			config, err := LoadDependencyConfig(path)
+			if err != nil {
+				t.Fatalf("Did not expect an error but got: %v", err)
+			}
+			if config.Name != tc.expected {
+				t.Errorf("Expected app name %s but got %s", tc.expected, config.Name)
+			}
+			if tc.expected == "YAML App" {
+				return
+			}
+
+			if len(config.Dependencies) != 1 {
+				t.Fatalf("Expected 1 dependency but got %d", len(config.Dependencies))
+			}
+			dep := config.Dependencies[0]
+			if dep.Version.Constraint != "^1.0.0" || dep.Platforms["linux"].Installer.Method != "archive" {
+				t.Errorf("Expected nested fields to be decoded but got %+v", dep)
+			}
+		})
+	}
+}
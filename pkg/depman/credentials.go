@@ -0,0 +1,118 @@
+package depman
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/devnadeemashraf/depman/internal/credentials"
+)
+
+// keychainService is the keychain service name secrets are stored under
+const keychainService = "depman"
+
+// Credential describes how to authenticate to a host that serves artifacts.
+// It never holds the secret itself; that is looked up in the credential
+// helper, DEPMAN_AUTH_<HOST>_* environment variables, the netrc file and,
+// if enabled, the OS keychain, in that order.
+type Credential struct {
+	Host     string   `yaml:"host"`     // Host name the credential applies to
+	Type     string   `yaml:"type"`     // basic (default if a username is known), bearer or api-key
+	Username string   `yaml:"username"` // Username for basic auth when the secret's source has none
+	Helper   []string `yaml:"helper"`   // Credential helper command, run with "get" appended
+	Keychain bool     `yaml:"keychain"` // Whether to look the secret up in the OS keychain
+}
+
+// WithCredentials replaces the built-in credential lookup with provider
+func WithCredentials(provider credentials.Provider) Option {
+	return func(m *Manager) {
+		m.credentials = provider
+	}
+}
+
+// hostCredentials looks up credentials using the sources configured for
+// each host. Hosts without a credentials entry are looked up in the
+// environment and the netrc file only.
+type hostCredentials struct {
+	configs map[string]Credential
+	logger  Logger
+}
+
+// Lookup implements credentials.Provider
+func (h *hostCredentials) Lookup(ctx context.Context, host string) (*credentials.Credential, error) {
+	config := h.configs[strings.ToLower(host)]
+
+	var chain credentials.Chain
+	if len(config.Helper) > 0 {
+		chain = append(chain, credentials.Helper{Command: config.Helper})
+	}
+	chain = append(chain, credentials.Env{}, credentials.Netrc{})
+	if config.Keychain {
+		chain = append(chain, credentials.Keychain{Service: keychainService})
+	}
+
+	cred, err := chain.Lookup(ctx, host)
+	if err != nil || cred == nil {
+		return cred, err
+	}
+
+	// Sources like netrc and the keychain only hold a secret, so the
+	// configuration says how to send it
+	if config.Type != "" && cred.Kind == "" {
+		cred.Kind, _ = credentials.ParseKind(config.Type)
+	}
+	if cred.Username == "" {
+		cred.Username = config.Username
+	}
+	if cred.Kind == "" {
+		cred.Kind = credentials.KindBearer
+		if cred.Username != "" {
+			cred.Kind = credentials.KindBasic
+		}
+	}
+
+	h.logger.Debugf("Using %s credentials for %s from %s", cred.Kind, host, cred.Source)
+	return cred, nil
+}
+
+// credentialProvider returns the provider used to authenticate requests
+func (m *Manager) credentialProvider() credentials.Provider {
+	if m.credentials != nil {
+		return m.credentials
+	}
+
+	// The configuration's credentials for a host replace those of the settings
+	provider := &hostCredentials{configs: make(map[string]Credential), logger: m.logger}
+	if m.settings != nil {
+		for _, config := range m.settings.Credentials {
+			provider.configs[strings.ToLower(config.Host)] = config
+		}
+	}
+	if m.Config != nil {
+		for _, config := range m.Config.Credentials {
+			provider.configs[strings.ToLower(config.Host)] = config
+		}
+	}
+	return provider
+}
+
+// client returns the client for downloads and API calls, which adds
+// credentials to requests for hosts that have them
+func (m *Manager) client() *http.Client {
+	m.clientOnce.Do(func() {
+		m.authClient = m.newClient()
+	})
+	return m.authClient
+}
+
+// newClient returns a copy of the base client that adds credentials from the
+// currently loaded configuration
+func (m *Manager) newClient() *http.Client {
+	base := defaultHTTPClient
+	if m.httpClient != nil {
+		base = m.httpClient
+	}
+	client := *base
+	client.Transport = &credentials.Transport{Base: base.Transport, Provider: m.credentialProvider()}
+	return &client
+}
@@ -0,0 +1,55 @@
+package depman
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestCredentials tests that downloads authenticate with the configured credential type
+func TestCredentials(t *testing.T) {
+	var apiKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiKey = r.Header.Get("X-JFrog-Art-Api")
+		if apiKey == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte("binary"))
+	}))
+	defer server.Close()
+
+	tempDir, err := os.MkdirTemp("", "depman-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	serverURL, _ := url.Parse(server.URL)
+	netrc := filepath.Join(tempDir, "netrc")
+	if err := os.WriteFile(netrc, []byte("machine "+serverURL.Hostname()+" password key123\n"), 0600); err != nil {
+		t.Fatalf("Failed to write netrc: %v", err)
+	}
+	t.Setenv("NETRC", netrc)
+
+	manager := &Manager{
+		logger:  &mockLogger{},
+		homeDir: tempDir,
+		Config: &DependencyConfig{Credentials: []Credential{
+			{Host: serverURL.Hostname(), Type: "api-key"},
+		}},
+	}
+	WithRetryPolicy(RetryPolicy{MaxAttempts: 1})(manager)
+
+	dep := &Dependency{Name: "tool"}
+	if _, err := manager.downloadArtifact(context.Background(), dep, &PlatformConfig{}, server.URL+"/tool", "", tempDir); err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if apiKey != "key123" {
+		t.Errorf("Expected the API key from netrc to be sent but got %q", apiKey)
+	}
+}
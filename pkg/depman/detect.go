@@ -0,0 +1,191 @@
+package depman
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Detection is a dependency suggested by a file found in a repository
+type Detection struct {
+	Dependency Dependency // Suggested dependency
+	Source     string     // File that suggested it, relative to the repository, e.g. "go.mod"
+}
+
+// detectDepth is how many directories below the repository root DetectStack looks
+const detectDepth = 2
+
+// detectSkipDirs are directories that hold other people's code or build output
+var detectSkipDirs = map[string]bool{"node_modules": true, "vendor": true, "dist": true, "build": true, "target": true}
+
+var (
+	goDirective       = regexp.MustCompile(`(?m)^go\s+(\d+\.\d+)`)
+	terraformRequired = regexp.MustCompile(`required_version\s*=\s*"([^"]+)"`)
+	nodeVersionFile   = regexp.MustCompile(`^v?(\d+)(\.\d+)*$`)
+	packageManagerRef = regexp.MustCompile(`^(npm|pnpm|yarn)@(\d+\.\d+\.\d+)`)
+)
+
+// DetectStack inspects a repository for the toolchains it uses (go.mod,
+// package.json, Dockerfiles, Makefiles and Terraform files) and suggests a
+// dependency for each. Constraints come from the files where they state one,
+// such as the go directive or engines.node, and are minimum versions otherwise.
+func DetectStack(dir string) ([]Detection, error) {
+	files, err := repositoryFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var detections []Detection
+	found := make(map[string]bool)
+	terraformStated := false
+	add := func(source string, dep Dependency) {
+		if !found[dep.Name] {
+			found[dep.Name] = true
+			detections = append(detections, Detection{Dependency: dep, Source: source})
+		}
+	}
+
+	for _, file := range files {
+		name := filepath.Base(file)
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(file)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+
+		switch {
+		case name == "go.mod":
+			constraint := ">=1.21"
+			if match := goDirective.FindSubmatch(data); match != nil {
+				constraint = ">=" + string(match[1])
+			}
+			add(file, fromRegistry("go", constraint))
+		case name == "package.json":
+			for _, dep := range nodeDependencies(dir, file, data) {
+				add(file, dep)
+			}
+		case isDockerFile(name):
+			add(file, fromRegistry("docker", ">=20.10"))
+		case name == "Makefile" || name == "makefile" || name == "GNUmakefile":
+			add(file, fromRegistry("make", ">=3.81"))
+		case strings.HasSuffix(name, ".tf"):
+			match := terraformRequired.FindSubmatch(data)
+			if match == nil || !validConstraint(string(match[1])) {
+				add(file, fromRegistry("terraform", ">=1.0"))
+				continue
+			}
+			// Any file of a module may state the required version, which
+			// replaces the default taken from an earlier file
+			if !terraformStated {
+				terraformStated = true
+				delete(found, "terraform")
+				detections = slices.DeleteFunc(detections, func(d Detection) bool { return d.Dependency.Name == "terraform" })
+				add(file, fromRegistry("terraform", string(match[1])))
+			}
+		}
+	}
+
+	return detections, nil
+}
+
+// repositoryFiles lists the files DetectStack looks at, relative to dir with
+// forward slashes, shallowest first. Hidden directories and directories of
+// vendored code or build output are skipped.
+func repositoryFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if entry.IsDir() {
+			if strings.HasPrefix(entry.Name(), ".") || detectSkipDirs[entry.Name()] || strings.Count(rel, "/") >= detectDepth {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		files = append(files, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect %s: %w", dir, err)
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		return strings.Count(files[i], "/") < strings.Count(files[j], "/")
+	})
+	return files, nil
+}
+
+// isDockerFile reports whether a file name is a Dockerfile or Compose file
+func isDockerFile(name string) bool {
+	switch name {
+	case "Dockerfile", "docker-compose.yml", "docker-compose.yaml", "compose.yml", "compose.yaml":
+		return true
+	}
+	return strings.HasPrefix(name, "Dockerfile.") || strings.HasSuffix(name, ".Dockerfile")
+}
+
+// nodeDependencies suggests Node.js and the project's package manager from a package.json
+func nodeDependencies(dir, file string, data []byte) []Dependency {
+	var manifest struct {
+		Engines struct {
+			Node string `json:"node"`
+		} `json:"engines"`
+		PackageManager string `json:"packageManager"`
+	}
+	// A malformed manifest still says the project uses Node.js
+	_ = json.Unmarshal(data, &manifest)
+
+	constraint := ">=18"
+	if validConstraint(manifest.Engines.Node) {
+		constraint = manifest.Engines.Node
+	} else {
+		projectDir := filepath.Join(dir, filepath.FromSlash(filepath.Dir(file)))
+		for _, versionFile := range []string{".nvmrc", ".node-version"} {
+			content, err := os.ReadFile(filepath.Join(projectDir, versionFile))
+			if match := nodeVersionFile.FindStringSubmatch(strings.TrimSpace(string(content))); err == nil && match != nil {
+				constraint = "^" + match[1]
+				break
+			}
+		}
+	}
+	deps := []Dependency{fromRegistry("node", constraint)}
+
+	// Corepack's packageManager field names the exact version
+	if match := packageManagerRef.FindStringSubmatch(manifest.PackageManager); match != nil && match[1] != "npm" {
+		deps = append(deps, fromRegistry(match[1], "^"+match[2]))
+		return deps
+	}
+	projectDir := filepath.Join(dir, filepath.FromSlash(filepath.Dir(file)))
+	if _, err := os.Stat(filepath.Join(projectDir, "pnpm-lock.yaml")); err == nil {
+		deps = append(deps, fromRegistry("pnpm", ">=8"))
+	} else if _, err := os.Stat(filepath.Join(projectDir, "yarn.lock")); err == nil {
+		deps = append(deps, fromRegistry("yarn", ">=1.22"))
+	}
+	return deps
+}
+
+// validConstraint reports whether a version constraint is usable as is
+func validConstraint(constraint string) bool {
+	if constraint == "" {
+		return false
+	}
+	_, err := ParseConstraint(constraint)
+	return err == nil
+}
+
+// fromRegistry returns a dependency using the registry recipe for name
+func fromRegistry(name, constraint string) Dependency {
+	dep := Dependency{Name: name, From: anyRegistry + "/" + name}
+	dep.Version.Constraint = constraint
+	return dep
+}
@@ -0,0 +1,133 @@
+package depman
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeRepository creates the files of a repository under a temporary directory
+func writeRepository(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestDetectStack(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected map[string]string // Dependency name to constraint
+	}{
+		{
+			name:     "go directive",
+			files:    map[string]string{"go.mod": "module example.com/x\n\ngo 1.22.3\n"},
+			expected: map[string]string{"go": ">=1.22"},
+		},
+		{
+			name:     "node engines and packageManager",
+			files:    map[string]string{"package.json": `{"engines": {"node": ">=20 <23"}, "packageManager": "pnpm@9.1.0+sha512.abc"}`},
+			expected: map[string]string{"node": ">=20 <23", "pnpm": "^9.1.0"},
+		},
+		{
+			name:     "nvmrc and yarn lock",
+			files:    map[string]string{"web/package.json": `{}`, "web/.nvmrc": "v20.11.0\n", "web/yarn.lock": ""},
+			expected: map[string]string{"node": "^20", "yarn": ">=1.22"},
+		},
+		{
+			name:     "node defaults",
+			files:    map[string]string{"package.json": `{"engines": {"node": "lts"}}`},
+			expected: map[string]string{"node": ">=18"},
+		},
+		{
+			name:     "docker and make",
+			files:    map[string]string{"compose.yaml": "services: {}\n", "GNUmakefile": "all:\n"},
+			expected: map[string]string{"docker": ">=20.10", "make": ">=3.81"},
+		},
+		{
+			name: "terraform required_version in a later file",
+			files: map[string]string{
+				"infra/main.tf":     "resource \"null_resource\" \"x\" {}\n",
+				"infra/versions.tf": "terraform {\n  required_version = \"~> 1.6\"\n}\n",
+			},
+			expected: map[string]string{"terraform": "~> 1.6"},
+		},
+		{
+			name: "skipped directories",
+			files: map[string]string{
+				"node_modules/x/package.json": `{}`,
+				".github/Dockerfile":          "FROM alpine\n",
+				"a/b/c/go.mod":                "module x\n",
+			},
+			expected: map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detections, err := DetectStack(writeRepository(t, tt.files))
+			if err != nil {
+				t.Fatalf("Expected no error but got %v", err)
+			}
+			got := make(map[string]string)
+			for _, detection := range detections {
+				got[detection.Dependency.Name] = detection.Dependency.Version.Constraint
+			}
+			if len(got) != len(tt.expected) {
+				t.Errorf("Expected %v but got %v", tt.expected, got)
+			}
+			for name, constraint := range tt.expected {
+				if got[name] != constraint {
+					t.Errorf("Expected %s constraint %q but got %q", name, constraint, got[name])
+				}
+			}
+		})
+	}
+}
+
+func TestDetectStackConfigIsValid(t *testing.T) {
+	dir := writeRepository(t, map[string]string{
+		"go.mod":       "module x\n\ngo 1.22\n",
+		"package.json": `{"packageManager": "yarn@4.1.0"}`,
+		"Dockerfile":   "FROM alpine\n",
+		"Makefile":     "all:\n",
+		"main.tf":      "",
+	})
+	detections, err := DetectStack(dir)
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	config := NewConfig("repo")
+	for _, detection := range detections {
+		config.Add(detection.Dependency)
+	}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Expected the detected configuration to be valid but got %v", err)
+	}
+
+	data, err := config.Encode()
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	path := filepath.Join(dir, "app-dependencies.yml")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadDependencyConfig(path)
+	if err != nil {
+		t.Fatalf("Expected the encoded configuration to load but got %v", err)
+	}
+	if len(loaded.Dependencies) != 6 {
+		t.Errorf("Expected 6 dependencies but got %d", len(loaded.Dependencies))
+	}
+}
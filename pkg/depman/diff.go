@@ -0,0 +1,186 @@
+package depman
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DiffKind is a kind of difference between the desired and the installed state
+type DiffKind string
+
+// Kinds of differences, in the order they are listed
+const (
+	DiffMissing DiffKind = "missing" // Configured but not installed
+	DiffDrift   DiffKind = "drift"   // Installed at another version than desired
+	DiffSource  DiffKind = "source"  // Installed from another source than configured
+	DiffExtra   DiffKind = "extra"   // Installed by depman for this configuration, which no longer has it
+)
+
+// DiffEntry is one difference between the desired and the installed state
+type DiffEntry struct {
+	Name            string   `json:"name"`                       // Name of the dependency
+	Kind            DiffKind `json:"kind"`                       // Kind of difference
+	Desired         string   `json:"desired,omitempty"`          // Version the configuration or lockfile asks for
+	Installed       string   `json:"installed,omitempty"`        // Version installed, if any
+	DesiredSource   string   `json:"desired_source,omitempty"`   // Where the configuration or lockfile installs it from
+	InstalledSource string   `json:"installed_source,omitempty"` // Where depman installed it from
+	Reason          string   `json:"reason,omitempty"`           // Why the versions differ
+}
+
+// Diff lists the differences between the desired and the installed state
+type Diff struct {
+	Entries   []*DiffEntry `json:"entries"`   // Differences, by kind and then name
+	Unchanged int          `json:"unchanged"` // Dependencies installed as desired
+}
+
+// Empty reports whether the machine matches the desired state
+func (d *Diff) Empty() bool {
+	return len(d.Entries) == 0
+}
+
+// Diff compares the desired state, the configuration with versions and
+// sources from the lockfile where it has them, with what is installed:
+// missing dependencies, installed versions other than desired, installs from
+// other sources than configured, and dependencies depman installed for this
+// configuration that it no longer defines. Dependencies whose installed
+// version cannot be checked, or that an alternative provides, are left out.
+func (m *Manager) Diff(ctx context.Context) (*Diff, error) {
+	if err := m.validateConfiguration(); err != nil {
+		return nil, fmt.Errorf("invalid dependency configuration: %w", err)
+	}
+
+	statuses, err := m.CheckAllDependencies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	deps, err := m.SelectedDependencies()
+	if err != nil {
+		return nil, err
+	}
+	lock, err := LoadLockfile(m.LockfilePath())
+	if err != nil && !errors.Is(err, ErrLockfileNotFound) {
+		return nil, err
+	}
+	installs, err := m.ManagedInstalls()
+	if err != nil {
+		return nil, err
+	}
+	home, config := m.HomeDir(), m.configIdentity()
+	recorded := make(map[string]*ManagedInstall)
+	for _, install := range installs {
+		if install.Home == home {
+			recorded[install.Name] = install
+		}
+	}
+
+	diff := &Diff{}
+	for _, dep := range deps {
+		status := statuses[dep.Name]
+		if status == nil || status.ProvidedBy != "" || status.Problem() == ProblemError {
+			continue
+		}
+		desired, desiredSource := m.desiredState(dep, lock)
+
+		entry := &DiffEntry{Name: dep.Name, Desired: desired, Installed: status.CurrentVersion, DesiredSource: desiredSource}
+		if install := recorded[dep.Name]; install != nil {
+			entry.InstalledSource = install.Source
+		}
+		switch {
+		case !status.Installed:
+			entry.Kind, entry.Installed, entry.InstalledSource = DiffMissing, "", ""
+		case !status.Compatible:
+			entry.Kind, entry.Reason = DiffDrift, fmt.Sprintf("violates %s", dep.Version.Constraint)
+		case status.RequiredUpdate != NoUpdate:
+			entry.Kind, entry.Reason = DiffDrift, fmt.Sprintf("%s to %s required", strings.ToLower(status.RequiredUpdate.String()), dep.Version.Required)
+		case lockedVersion(lock, dep) != "" && !sameVersion(status.CurrentVersion, desired):
+			entry.Kind, entry.Reason = DiffDrift, "differs from the lockfile"
+		case comparableSources(entry.InstalledSource, desiredSource) && entry.InstalledSource != desiredSource:
+			entry.Kind = DiffSource
+		default:
+			diff.Unchanged++
+			continue
+		}
+		diff.Entries = append(diff.Entries, entry)
+	}
+
+	// Installs this configuration made of dependencies it no longer defines
+	for _, install := range installs {
+		if install.Home != home || !containsString(install.Configs, config) || configDefines(m.Config, install.Name) {
+			continue
+		}
+		diff.Entries = append(diff.Entries, &DiffEntry{
+			Name:            install.Name,
+			Kind:            DiffExtra,
+			Installed:       install.Version,
+			InstalledSource: install.Source,
+			Reason:          "no longer in the configuration",
+		})
+	}
+
+	order := map[DiffKind]int{DiffMissing: 0, DiffDrift: 1, DiffSource: 2, DiffExtra: 3}
+	sort.SliceStable(diff.Entries, func(i, j int) bool {
+		a, b := diff.Entries[i], diff.Entries[j]
+		if a.Kind != b.Kind {
+			return order[a.Kind] < order[b.Kind]
+		}
+		return a.Name < b.Name
+	})
+	return diff, nil
+}
+
+// desiredState returns the version and source a dependency should be
+// installed at: those in the lockfile if its entry matches the current
+// definition, else those of the configuration
+func (m *Manager) desiredState(dep *Dependency, lock *Lockfile) (string, string) {
+	version := dep.Version.Target()
+	var source string
+	if platformConfig, err := m.resolvedPlatformConfig(dep); err == nil {
+		source = platformConfig.Installer.URL
+		if source == "" {
+			source = installSource(dep, platformConfig)
+		} else if dep.Version.Required != "" {
+			source = expandURLTemplate(source, dep, m.Platform, m.targetArch())
+		}
+	}
+	if locked := lockedVersion(lock, dep); locked != "" {
+		version = locked
+		if artifact, ok := lock.Find(dep.Name).Platforms[m.lockPlatform(dep)]; ok && artifact.URL != "" {
+			source = artifact.URL
+		}
+	}
+
+	// Unexpanded templates can not be compared with a recorded source
+	if strings.Contains(source, "{{") {
+		source = ""
+	}
+	return version, source
+}
+
+// lockedVersion returns the version the lockfile records for a dependency,
+// or "" if it has none for its current definition
+func lockedVersion(lock *Lockfile, dep *Dependency) string {
+	if lock == nil {
+		return ""
+	}
+	entry := lock.Find(dep.Name)
+	if entry == nil || entry.Digest != dependencyDigest(dep) {
+		return ""
+	}
+	return entry.Version
+}
+
+// sameVersion reports whether two version strings name the same version,
+// ignoring a "v" prefix
+func sameVersion(a, b string) bool {
+	return strings.TrimPrefix(a, "v") == strings.TrimPrefix(b, "v")
+}
+
+// comparableSources reports whether two sources can be compared: both are
+// set and both are URLs or both are names, since a release installed from a
+// repository records the URL of the asset it downloaded
+func comparableSources(a, b string) bool {
+	return a != "" && b != "" && strings.Contains(a, "://") == strings.Contains(b, "://")
+}
@@ -0,0 +1,106 @@
+package depman
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	dir := t.TempDir()
+	homeDir := filepath.Join(dir, "home")
+	configPath := filepath.Join(dir, "deps.yml")
+	os.WriteFile(configPath, []byte(`version: "1.0"
+dependencies:
+  - name: locked
+    version:
+      required: "1.2.3"
+    platforms:
+      linux:
+        commands:
+          verify: ["sh", "-c", "echo 1.2.3"]
+  - name: missing
+    version:
+      required: "1.0.0"
+    platforms:
+      linux:
+        commands:
+          verify: ["depman-test-missing", "--version"]
+  - name: moved
+    version:
+      required: "2.0.0"
+    platforms:
+      linux:
+        installer:
+          method: archive
+          url: "https://example.com/moved-{{version}}"
+        commands:
+          verify: ["sh", "-c", "echo 2.0.0"]
+  - name: ok
+    version:
+      constraint: ">=1.0"
+    platforms:
+      linux:
+        commands:
+          verify: ["sh", "-c", "echo 1.5.0"]
+`), 0644)
+
+	manager, err := NewManager(configPath, WithPlatform("linux/amd64"), WithHomeDir(homeDir), WithSettings(Settings{}), WithLogOutput(io.Discard))
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+
+	// The lockfile pins an older version of locked than is installed
+	lock := &Lockfile{Dependencies: []LockedDependency{
+		{Name: "locked", Version: "1.2.2", Digest: dependencyDigest(manager.FindDependency("locked"))},
+	}}
+	if err := lock.Save(manager.LockfilePath()); err != nil {
+		t.Fatal(err)
+	}
+
+	// moved was installed from an old URL, and retired is no longer configured
+	config, _ := filepath.Abs(configPath)
+	state := installState{Version: stateVersion, Installs: []*ManagedInstall{
+		{Name: "moved", Version: "2.0.0", Method: "archive", Source: "https://old.example.com/moved-2.0.0", Home: homeDir, Configs: []string{config}},
+		{Name: "retired", Version: "0.9.0", Method: "archive", Source: "https://example.com/retired", Home: homeDir, Configs: []string{config}},
+		{Name: "elsewhere", Version: "1.0.0", Home: homeDir, Configs: []string{"/other/deps.yml"}},
+	}}
+	data, _ := json.Marshal(state)
+	os.MkdirAll(homeDir, 0755)
+	os.WriteFile(manager.StatePath(), data, 0644)
+
+	diff, err := manager.Diff(context.Background())
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+
+	expected := []struct {
+		name string
+		kind DiffKind
+	}{
+		{"missing", DiffMissing},
+		{"locked", DiffDrift},
+		{"moved", DiffSource},
+		{"retired", DiffExtra},
+	}
+	if len(diff.Entries) != len(expected) {
+		t.Fatalf("Expected %d differences but got %d: %+v", len(expected), len(diff.Entries), diff.Entries)
+	}
+	for i, entry := range diff.Entries {
+		if entry.Name != expected[i].name || entry.Kind != expected[i].kind {
+			t.Errorf("Expected %s to be %s but got %s %s", expected[i].name, expected[i].kind, entry.Name, entry.Kind)
+		}
+	}
+	if drift := diff.Entries[1]; drift.Desired != "1.2.2" || drift.Installed != "1.2.3" {
+		t.Errorf("Expected the locked version to be desired but got %+v", drift)
+	}
+	if moved := diff.Entries[2]; moved.DesiredSource != "https://example.com/moved-2.0.0" {
+		t.Errorf("Expected the configured URL to be desired but got %s", moved.DesiredSource)
+	}
+	if diff.Unchanged != 1 {
+		t.Errorf("Expected 1 unchanged dependency but got %d", diff.Unchanged)
+	}
+}
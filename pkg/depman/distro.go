@@ -0,0 +1,205 @@
+package depman
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Distro identifies a Linux distribution from its os-release file
+type Distro struct {
+	ID      string   // Lowercase distribution ID, e.g. "ubuntu" or "rhel"
+	Like    []string // IDs of the distributions it derives from, closest first (ID_LIKE)
+	Version string   // Release version, e.g. "22.04", or "" for rolling releases (VERSION_ID)
+}
+
+// String returns the distribution as "id" or "id version"
+func (d *Distro) String() string {
+	if d.Version == "" {
+		return d.ID
+	}
+	return d.ID + " " + d.Version
+}
+
+// family returns the distribution's ID followed by the IDs it derives from
+func (d *Distro) family() []string {
+	return append([]string{d.ID}, d.Like...)
+}
+
+// DetectDistro reads the running distribution from /etc/os-release, or
+// returns nil if it is missing or names no distribution
+func DetectDistro() *Distro {
+	release := readOSRelease(osReleasePath)
+	if release["ID"] == "" {
+		return nil
+	}
+	return &Distro{
+		ID:      strings.ToLower(release["ID"]),
+		Like:    strings.Fields(strings.ToLower(release["ID_LIKE"])),
+		Version: release["VERSION_ID"],
+	}
+}
+
+// ParseDistro parses a distribution given as "id" or "id@version", e.g. "ubuntu@22.04"
+func ParseDistro(value string) (*Distro, error) {
+	id, version, _ := strings.Cut(strings.TrimSpace(value), "@")
+	if !distroIDPattern.MatchString(id) {
+		return nil, fmt.Errorf("invalid distribution '%s' (expected id or id@version, e.g. ubuntu@22.04)", value)
+	}
+	distro := &Distro{ID: strings.ToLower(id), Version: version}
+	// Known derivatives keep their family so package names and managers resolve
+	if like, ok := distroFamilies[distro.ID]; ok {
+		distro.Like = like
+	}
+	return distro, nil
+}
+
+// WithDistro sets the Linux distribution used to select platform entries and
+// package managers instead of reading /etc/os-release
+func WithDistro(distro *Distro) Option {
+	return func(m *Manager) {
+		m.distro = distro
+	}
+}
+
+// Distro returns the Linux distribution tools are installed for, or nil if
+// the platform is not Linux or the distribution is unknown
+func (m *Manager) Distro() *Distro {
+	if m.Platform != "linux" {
+		return nil
+	}
+	return m.distro
+}
+
+// distroFamilies lists the ID_LIKE of common distributions, for distributions given with --distro
+var distroFamilies = map[string][]string{
+	"ubuntu":      {"debian"},
+	"linuxmint":   {"ubuntu", "debian"},
+	"pop":         {"ubuntu", "debian"},
+	"raspbian":    {"debian"},
+	"kali":        {"debian"},
+	"centos":      {"rhel", "fedora"},
+	"rocky":       {"rhel", "centos", "fedora"},
+	"almalinux":   {"rhel", "centos", "fedora"},
+	"ol":          {"rhel", "fedora"},
+	"rhel":        {"fedora"},
+	"amzn":        {"centos", "rhel", "fedora"},
+	"manjaro":     {"arch"},
+	"endeavouros": {"arch"},
+}
+
+// distroIDPattern matches os-release distribution IDs
+var distroIDPattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9._-]*$`)
+
+// distroSelectorPattern matches platform keys naming a distribution: an ID
+// with an optional comparison ("ubuntu>=22.04") or major version ("rhel8")
+var distroSelectorPattern = regexp.MustCompile(`^([a-z][a-z_-]*?)(?:(>=|<=|==|=|>|<)?([0-9]+(?:\.[0-9]+)*))?$`)
+
+// distroSelector is a parsed distribution platform key
+type distroSelector struct {
+	id       string // Distribution ID or family
+	operator string // Comparison with version, or "" for a version prefix
+	version  string // Version to compare with, or "" to match any version
+}
+
+// parseDistroSelector parses a platform key naming a known distribution
+func parseDistroSelector(key string) (distroSelector, bool) {
+	match := distroSelectorPattern.FindStringSubmatch(key)
+	if match == nil {
+		return distroSelector{}, false
+	}
+	if _, known := distroPackageManagers[match[1]]; !known {
+		return distroSelector{}, false
+	}
+	return distroSelector{id: match[1], operator: match[2], version: match[3]}, true
+}
+
+// score rates how well the selector matches a distribution: 0 if it does not,
+// higher for the distribution's own ID than for a family it derives from,
+// and higher again when a version is required
+func (s distroSelector) score(distro *Distro) int {
+	family := distro.family()
+	rank := -1
+	for i, id := range family {
+		if id == s.id {
+			rank = i
+			break
+		}
+	}
+	if rank < 0 {
+		return 0
+	}
+
+	score := 2 * (len(family) - rank)
+	if s.version == "" {
+		return score
+	}
+	if distro.Version == "" || !s.versionMatches(distro.Version) {
+		return 0
+	}
+	return score + 1
+}
+
+// versionMatches compares a distribution version with the selector's
+func (s distroSelector) versionMatches(version string) bool {
+	if s.operator == "" {
+		// "rhel8" matches 8, 8.9 and so on
+		return version == s.version || strings.HasPrefix(version, s.version+".")
+	}
+	cmp := compareDistroVersions(version, s.version)
+	switch s.operator {
+	case ">=":
+		return cmp >= 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case "<":
+		return cmp < 0
+	default:
+		return cmp == 0
+	}
+}
+
+// compareDistroVersions compares dotted numeric versions such as "22.04",
+// treating missing components as zero; non-numeric components compare as zero
+func compareDistroVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// matchDistro returns the key of the platform entry naming the distribution
+// that matches best, if any does
+func matchDistro(platforms map[string]PlatformConfig, distro *Distro) (string, bool) {
+	if distro == nil {
+		return "", false
+	}
+	best, bestScore := "", 0
+	for key := range platforms {
+		selector, ok := parseDistroSelector(key)
+		if !ok {
+			continue
+		}
+		// Ties go to the lexically first key so the choice is stable
+		if score := selector.score(distro); score > bestScore || (score == bestScore && score > 0 && key < best) {
+			best, bestScore = key, score
+		}
+	}
+	return best, bestScore > 0
+}
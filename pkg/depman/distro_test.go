@@ -0,0 +1,142 @@
+package depman
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectDistro(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "depman-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	originalPath := osReleasePath
+	defer func() { osReleasePath = originalPath }()
+
+	osReleasePath = filepath.Join(tempDir, "os-release")
+	content := "NAME=\"Ubuntu\"\nVERSION_ID=\"22.04\"\nID=ubuntu\nID_LIKE=debian\n"
+	if err := os.WriteFile(osReleasePath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write os-release: %v", err)
+	}
+
+	distro := DetectDistro()
+	if distro == nil {
+		t.Fatalf("Expected a distribution but got none")
+	}
+	if distro.ID != "ubuntu" || distro.Version != "22.04" || len(distro.Like) != 1 || distro.Like[0] != "debian" {
+		t.Errorf("Expected ubuntu 22.04 like debian but got %+v", distro)
+	}
+
+	osReleasePath = filepath.Join(tempDir, "missing")
+	if distro := DetectDistro(); distro != nil {
+		t.Errorf("Expected no distribution but got %s", distro)
+	}
+}
+
+func TestParseDistro(t *testing.T) {
+	distro, err := ParseDistro("rocky@9.3")
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if distro.ID != "rocky" || distro.Version != "9.3" || len(distro.Like) == 0 || distro.Like[0] != "rhel" {
+		t.Errorf("Expected rocky 9.3 like rhel but got %+v", distro)
+	}
+
+	if _, err := ParseDistro("@22.04"); err == nil {
+		t.Errorf("Expected an error but got none")
+	}
+}
+
+func TestMatchDistro(t *testing.T) {
+	ubuntu2204 := &Distro{ID: "ubuntu", Like: []string{"debian"}, Version: "22.04"}
+	ubuntu2004 := &Distro{ID: "ubuntu", Like: []string{"debian"}, Version: "20.04"}
+	rhel8 := &Distro{ID: "rhel", Like: []string{"fedora"}, Version: "8.9"}
+	rocky9 := &Distro{ID: "rocky", Like: []string{"rhel", "centos", "fedora"}, Version: "9.3"}
+	arch := &Distro{ID: "arch"}
+
+	testCases := []struct {
+		name      string
+		platforms []string
+		distro    *Distro
+		expected  string
+	}{
+		{"Exact ID", []string{"linux", "ubuntu", "debian"}, ubuntu2204, "ubuntu"},
+		{"Family", []string{"linux", "debian"}, ubuntu2204, "debian"},
+		{"Version constraint", []string{"ubuntu", "ubuntu>=22.04"}, ubuntu2204, "ubuntu>=22.04"},
+		{"Version constraint not met", []string{"ubuntu", "ubuntu>=22.04"}, ubuntu2004, "ubuntu"},
+		{"Major version", []string{"rhel7", "rhel8"}, rhel8, "rhel8"},
+		{"Family major version", []string{"rhel8", "rhel9"}, rocky9, "rhel9"},
+		{"Rolling release", []string{"arch", "arch>=1"}, arch, "arch"},
+		{"No match", []string{"linux", "alpine"}, ubuntu2204, ""},
+		{"No distribution", []string{"ubuntu"}, nil, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			platforms := make(map[string]PlatformConfig)
+			for _, key := range tc.platforms {
+				platforms[key] = PlatformConfig{}
+			}
+			got, _ := matchDistro(platforms, tc.distro)
+			if got != tc.expected {
+				t.Errorf("Expected %q but got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestPlatformKeyDistro(t *testing.T) {
+	dep := &Dependency{Name: "fd", Platforms: map[string]PlatformConfig{
+		"linux":  {Installer: Installer{Method: "archive"}},
+		"debian": {Installer: Installer{Method: "apt"}},
+	}}
+
+	manager := &Manager{Platform: "linux", distro: &Distro{ID: "ubuntu", Like: []string{"debian"}}, logger: &mockLogger{}}
+	if key, _ := manager.platformKey(dep); key != "debian" {
+		t.Errorf("Expected the debian entry but got %q", key)
+	}
+
+	manager.distro = &Distro{ID: "alpine"}
+	if key, _ := manager.platformKey(dep); key != "linux" {
+		t.Errorf("Expected the linux entry but got %q", key)
+	}
+
+	// Distributions only apply to Linux
+	manager.Platform = "darwin"
+	if _, ok := manager.platformKey(dep); ok {
+		t.Errorf("Did not expect an entry for darwin")
+	}
+}
+
+func TestSystemPackageNameDistro(t *testing.T) {
+	dep := &Dependency{Name: "fd"}
+	platformConfig := &PlatformConfig{Installer: Installer{Packages: map[string]string{
+		"apt":    "fd-find",
+		"ubuntu": "fd-find-ubuntu",
+		"rhel":   "fd-rhel",
+	}}}
+
+	testCases := []struct {
+		name     string
+		manager  string
+		distro   *Distro
+		expected string
+	}{
+		{"Distribution ID", "apt", &Distro{ID: "ubuntu", Like: []string{"debian"}}, "fd-find-ubuntu"},
+		{"Family", "dnf", &Distro{ID: "rocky", Like: []string{"rhel", "fedora"}}, "fd-rhel"},
+		{"Package manager", "apt", &Distro{ID: "debian"}, "fd-find"},
+		{"Default", "pacman", &Distro{ID: "arch"}, "fd"},
+		{"No distribution", "apt", nil, "fd-find"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := systemPackageName(tc.manager, tc.distro, dep, platformConfig); got != tc.expected {
+				t.Errorf("Expected %s but got %s", tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,516 @@
+package depman
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/devnadeemashraf/depman/internal/httpclient"
+)
+
+// Severity grades a doctor finding
+type Severity string
+
+const (
+	SeverityOK      Severity = "ok"      // Nothing to do
+	SeverityWarning Severity = "warning" // Works, but may cause surprises
+	SeverityError   Severity = "error"   // Will make checks or installs fail
+)
+
+// Doctor check names
+const (
+	CheckPlatform        = "platform"
+	CheckPath            = "path"
+	CheckPackageManagers = "package-managers"
+	CheckNetwork         = "network"
+	CheckPermissions     = "permissions"
+	CheckCache           = "cache"
+)
+
+// Finding is the result of one diagnostic check
+type Finding struct {
+	Check    string   `json:"check"`         // Area the finding belongs to, e.g. "network"
+	Severity Severity `json:"severity"`      // How serious the finding is
+	Message  string   `json:"message"`       // What was found
+	Fix      string   `json:"fix,omitempty"` // Suggested fix, if any
+}
+
+// staleDownloadAge is how old a leftover download directory must be to be reported
+const staleDownloadAge = time.Hour
+
+// dialHost checks that a TCP connection to host:port can be opened; replaced in tests
+var dialHost = func(address string) error {
+	conn, err := net.DialTimeout("tcp", address, 5*time.Second)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// packageManagerBinaries maps package-manager install methods to their executables
+var packageManagerBinaries = map[string]string{
+	"brew":    "brew",
+	"winget":  "winget",
+	"choco":   "choco",
+	"docker":  "docker",
+	"podman":  "podman",
+	"nerdctl": "nerdctl",
+	"asdf":    "asdf",
+	"mise":    "mise",
+	"pyenv":   "pyenv",
+	"rbenv":   "rbenv",
+	"go":      "go",
+	"pipx":    "pipx",
+	"npm":     "npm",
+	"cargo":   "cargo",
+	"nix":     "nix",
+	"scoop":   "scoop",
+}
+
+// Diagnose inspects the environment depman runs in and returns its findings
+// in check order: platform detection, PATH, package managers, network
+// reachability of configured hosts, install directory permissions and stale
+// files left behind by earlier runs
+func (m *Manager) Diagnose() []Finding {
+	var findings []Finding
+	findings = append(findings, m.diagnosePlatform()...)
+	findings = append(findings, m.diagnosePath()...)
+	findings = append(findings, m.diagnosePackageManagers()...)
+	findings = append(findings, m.diagnoseNetwork()...)
+	findings = append(findings, m.diagnosePermissions()...)
+	findings = append(findings, m.diagnoseCache()...)
+	return findings
+}
+
+// diagnosePlatform reports the detected platform and dependencies without configuration for it
+func (m *Manager) diagnosePlatform() []Finding {
+	message := fmt.Sprintf("Platform %s", m.PlatformTarget())
+	if distro := m.Distro(); distro != nil {
+		message += fmt.Sprintf(" (%s)", distro)
+	}
+	if m.InWSL() {
+		message += " in WSL"
+	}
+	findings := []Finding{{
+		Check:    CheckPlatform,
+		Severity: SeverityOK,
+		Message:  message,
+	}}
+
+	if !isKnownPlatform(m.Platform) {
+		findings = append(findings, Finding{
+			Check:    CheckPlatform,
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("Unknown platform '%s'", m.Platform),
+			Fix:      fmt.Sprintf("Pass --platform with one of %s", strings.Join(knownPlatforms, ", ")),
+		})
+	} else if m.Platform != runtime.GOOS {
+		findings = append(findings, Finding{
+			Check:    CheckPlatform,
+			Severity: SeverityWarning,
+			Message:  fmt.Sprintf("Platform is overridden to %s but depman runs on %s", m.Platform, runtime.GOOS),
+			Fix:      "Remove --platform unless you are inspecting another platform's configuration",
+		})
+	}
+	if m.targetArch() != runtime.GOARCH && m.Platform == runtime.GOOS {
+		findings = append(findings, Finding{
+			Check:    CheckPlatform,
+			Severity: SeverityOK,
+			Message:  fmt.Sprintf("depman is a %s build; tools are installed for %s", runtime.GOARCH, m.targetArch()),
+		})
+	}
+
+	for _, dep := range m.Config.Dependencies {
+		if target, missing := m.missingPlatform(&dep); missing {
+			platform, _, _ := strings.Cut(target, " ")
+			goos, _, _ := strings.Cut(platform, "/")
+			findings = append(findings, Finding{
+				Check:    CheckPlatform,
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("Dependency %s has no configuration for %s", dep.Name, target),
+				Fix:      fmt.Sprintf("Add a platforms.%s or platforms.%s entry for %s", goos, platform, dep.Name),
+			})
+		}
+	}
+	findings = append(findings, m.diagnoseInterop()...)
+
+	return findings
+}
+
+// diagnoseInterop reports whether Windows commands can be run from WSL when
+// dependencies are installed on the Windows host
+func (m *Manager) diagnoseInterop() []Finding {
+	var hosted []string
+	for _, dep := range m.Config.Dependencies {
+		if envs := m.environments(&dep); envs[len(envs)-1] == WSLWindows {
+			hosted = append(hosted, dep.Name)
+		}
+	}
+	if len(hosted) == 0 {
+		return nil
+	}
+
+	if _, err := lookPath("cmd.exe"); err != nil {
+		if _, err := os.Stat(windowsShellPath); err != nil {
+			return []Finding{{
+				Check:    CheckPlatform,
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("Windows interop is unavailable but %s install on the Windows host", strings.Join(hosted, ", ")),
+				Fix:      "Enable interop in /etc/wsl.conf ([interop] enabled=true) and restart WSL",
+			}}
+		}
+	}
+	return []Finding{{
+		Check:    CheckPlatform,
+		Severity: SeverityOK,
+		Message:  fmt.Sprintf("%s install on the Windows host through interop", strings.Join(hosted, ", ")),
+	}}
+}
+
+// diagnosePath reports missing and duplicate PATH entries and managed bin
+// directories that are not on PATH
+func (m *Manager) diagnosePath() []Finding {
+	var findings []Finding
+
+	entries := filepath.SplitList(os.Getenv("PATH"))
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		if entry == "" {
+			continue
+		}
+		clean := filepath.Clean(entry)
+		if seen[clean] {
+			findings = append(findings, Finding{
+				Check:    CheckPath,
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("%s appears on PATH more than once", entry),
+				Fix:      "Remove the duplicate from your shell profile",
+			})
+			continue
+		}
+		seen[clean] = true
+
+		if info, err := os.Stat(entry); err != nil || !info.IsDir() {
+			findings = append(findings, Finding{
+				Check:    CheckPath,
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("PATH entry %s does not exist", entry),
+				Fix:      "Remove it from your shell profile",
+			})
+		}
+	}
+
+	for _, dir := range m.binDirs() {
+		if _, err := os.Stat(dir); err != nil && dir != m.BinDir() {
+			continue
+		}
+		if !seen[filepath.Clean(dir)] {
+			findings = append(findings, Finding{
+				Check:    CheckPath,
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("Managed bin directory %s is not on PATH", dir),
+				Fix:      `Add eval "$(depman env)" to your shell profile`,
+			})
+		}
+	}
+
+	if len(findings) == 0 {
+		findings = append(findings, Finding{Check: CheckPath, Severity: SeverityOK, Message: "PATH includes the managed bin directory"})
+	}
+	return findings
+}
+
+// diagnosePackageManagers reports which package managers are available and
+// whether the install methods used by the configuration can run
+func (m *Manager) diagnosePackageManagers() []Finding {
+	var findings []Finding
+
+	required := make(map[string][]string)
+	for i := range m.Config.Dependencies {
+		dep := &m.Config.Dependencies[i]
+		if dep.kind() == KindImage {
+			runtime, err := findImageRuntime(context.Background(), dep.Image.Runtime)
+			if err != nil {
+				findings = append(findings, Finding{
+					Check:    CheckPackageManagers,
+					Severity: SeverityError,
+					Message:  fmt.Sprintf("Dependency %s is a container image but %v", dep.Name, err),
+					Fix:      "Install docker, podman or nerdctl, or set image.runtime to one that is installed",
+				})
+				continue
+			}
+			required[runtime] = append(required[runtime], dep.Name)
+			continue
+		}
+		key, ok := m.platformKey(dep)
+		if !ok {
+			continue
+		}
+		platformConfig := dep.Platforms[key]
+		method := installMethod(&platformConfig)
+		if method == "system" {
+			detected, err := detectSystemPackageManager(m.Distro())
+			if err != nil {
+				findings = append(findings, Finding{
+					Check:    CheckPackageManagers,
+					Severity: SeverityError,
+					Message:  fmt.Sprintf("Dependency %s uses the system package manager but none was found", dep.Name),
+					Fix:      "Install one of apt, dnf, yum, zypper, pacman or apk, or pick another install method",
+				})
+				continue
+			}
+			method = detected
+		}
+		if _, ok := packageManagerBinaries[method]; ok {
+			required[method] = append(required[method], dep.Name)
+		} else if _, ok := systemPackageManagers[method]; ok {
+			required[method] = append(required[method], dep.Name)
+		}
+	}
+
+	var methods []string
+	for method := range required {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+
+	for _, method := range methods {
+		binary, ok := packageManagerBinaries[method]
+		if !ok {
+			binary = systemPackageManagers[method].binary
+		}
+		if path, err := lookPath(binary); err == nil {
+			findings = append(findings, Finding{
+				Check:    CheckPackageManagers,
+				Severity: SeverityOK,
+				Message:  fmt.Sprintf("%s is available at %s", method, path),
+			})
+			continue
+		}
+		findings = append(findings, Finding{
+			Check:    CheckPackageManagers,
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("%s is not on PATH but is needed by %s", method, strings.Join(required[method], ", ")),
+			Fix:      fmt.Sprintf("Install %s or choose another install method for these dependencies", method),
+		})
+	}
+
+	if len(findings) == 0 {
+		findings = append(findings, Finding{Check: CheckPackageManagers, Severity: SeverityOK, Message: "No package managers are needed on this platform"})
+	}
+	return findings
+}
+
+// diagnoseNetwork checks that every host the configuration downloads from can be reached
+func (m *Manager) diagnoseNetwork() []Finding {
+	hosts := m.configuredHosts()
+	if len(hosts) == 0 {
+		return []Finding{{Check: CheckNetwork, Severity: SeverityOK, Message: "No download hosts are configured"}}
+	}
+
+	findings := make([]Finding, len(hosts))
+	var wg sync.WaitGroup
+	for i, host := range hosts {
+		wg.Add(1)
+		go func(i int, host string) {
+			defer wg.Done()
+
+			// Behind a proxy only the proxy itself has to be reachable
+			address, via := host, ""
+			if proxy := proxyAddress(host); proxy != "" {
+				address, via = proxy, " through proxy "+proxy
+			}
+
+			if err := dialHost(address); err != nil {
+				findings[i] = Finding{
+					Check:    CheckNetwork,
+					Severity: SeverityError,
+					Message:  fmt.Sprintf("Cannot reach %s%s: %v", host, via, err),
+					Fix:      "Check your network connection, proxy settings and firewall",
+				}
+				return
+			}
+			findings[i] = Finding{Check: CheckNetwork, Severity: SeverityOK, Message: fmt.Sprintf("%s is reachable%s", host, via)}
+		}(i, host)
+	}
+	wg.Wait()
+
+	return findings
+}
+
+// proxyAddress returns the host:port of the proxy that connections to a
+// host:port address go through, or "" if they connect directly
+func proxyAddress(host string) string {
+	scheme := "https"
+	if strings.HasSuffix(host, ":80") {
+		scheme = "http"
+	}
+
+	proxy := httpclient.ProxyFor(scheme + "://" + host)
+	if proxy == nil {
+		return ""
+	}
+	port := proxy.Port()
+	if port == "" {
+		port = "80"
+		if proxy.Scheme == "https" {
+			port = "443"
+		}
+	}
+	return net.JoinHostPort(proxy.Hostname(), port)
+}
+
+// configuredHosts returns the sorted host:port addresses the configuration downloads from
+func (m *Manager) configuredHosts() []string {
+	set := make(map[string]bool)
+	add := func(raw string) {
+		parsed, err := url.Parse(raw)
+		if err != nil || parsed.Host == "" || strings.Contains(parsed.Host, "{{") {
+			return
+		}
+		port := parsed.Port()
+		if port == "" {
+			port = "443"
+			if parsed.Scheme == "http" {
+				port = "80"
+			}
+		}
+		set[net.JoinHostPort(parsed.Hostname(), port)] = true
+	}
+
+	for i := range m.Config.Dependencies {
+		dep := &m.Config.Dependencies[i]
+		if !m.supportsPlatform(dep) {
+			continue
+		}
+		platformConfig, err := m.GetPlatformConfig(dep)
+		if err != nil {
+			continue
+		}
+		installer := platformConfig.Installer
+		for _, u := range []string{installer.URL, installer.ChecksumURL, installer.SignatureURL} {
+			expanded, _ := m.expandURL(u, dep)
+			add(expanded)
+		}
+		if installMethod(platformConfig) == "github-release" && installer.URL == "" {
+			add("https://api.github.com")
+		}
+	}
+
+	var hosts []string
+	for host := range set {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+// diagnosePermissions checks that depman can write to its install directories
+func (m *Manager) diagnosePermissions() []Finding {
+	var findings []Finding
+
+	for _, dir := range []string{m.HomeDir(), m.BinDir(), m.ToolsDir()} {
+		if err := checkWritable(dir); err != nil {
+			findings = append(findings, Finding{
+				Check:    CheckPermissions,
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("Cannot write to %s: %v", dir, err),
+				Fix:      "Fix the directory's ownership, or choose another location with --bin-dir or --scope",
+			})
+			continue
+		}
+		findings = append(findings, Finding{Check: CheckPermissions, Severity: SeverityOK, Message: fmt.Sprintf("%s is writable", dir)})
+	}
+
+	return findings
+}
+
+// checkWritable reports whether files can be created in dir, or in its
+// nearest existing parent if dir does not exist yet
+func checkWritable(dir string) error {
+	for {
+		info, err := os.Stat(dir)
+		if err == nil {
+			if !info.IsDir() {
+				return fmt.Errorf("%s is not a directory", dir)
+			}
+			break
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return err
+		}
+		dir = parent
+	}
+
+	file, err := os.CreateTemp(dir, ".depman-doctor-*")
+	if err != nil {
+		return err
+	}
+	file.Close()
+	return os.Remove(file.Name())
+}
+
+// diagnoseCache reports files left behind by interrupted runs and lockfile
+// entries for dependencies that are no longer configured
+func (m *Manager) diagnoseCache() []Finding {
+	var findings []Finding
+
+	downloads, _ := filepath.Glob(filepath.Join(os.TempDir(), "depman-download-*"))
+	var stale []string
+	for _, path := range downloads {
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > staleDownloadAge {
+			stale = append(stale, path)
+		}
+	}
+	if len(stale) > 0 {
+		findings = append(findings, Finding{
+			Check:    CheckCache,
+			Severity: SeverityWarning,
+			Message:  fmt.Sprintf("%d download directories were left behind by interrupted runs", len(stale)),
+			Fix:      fmt.Sprintf("Remove %s", filepath.Join(os.TempDir(), "depman-download-*")),
+		})
+	}
+
+	for _, dir := range m.binDirs() {
+		leftovers, _ := filepath.Glob(filepath.Join(dir, "*.tmp"))
+		for _, path := range leftovers {
+			findings = append(findings, Finding{
+				Check:    CheckCache,
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("Partial file %s was left behind by an interrupted install", path),
+				Fix:      fmt.Sprintf("Remove %s", path),
+			})
+		}
+	}
+
+	if lock, err := LoadLockfile(m.LockfilePath()); err == nil {
+		for _, entry := range lock.Dependencies {
+			if m.FindDependency(entry.Name) == nil {
+				findings = append(findings, Finding{
+					Check:    CheckCache,
+					Severity: SeverityWarning,
+					Message:  fmt.Sprintf("Lockfile entry %s is no longer in the configuration", entry.Name),
+					Fix:      "Run 'depman ensure' to refresh " + LockfileName,
+				})
+			}
+		}
+	}
+
+	if len(findings) == 0 {
+		findings = append(findings, Finding{Check: CheckCache, Severity: SeverityOK, Message: "No stale files found"})
+	}
+	return findings
+}
@@ -0,0 +1,83 @@
+package depman
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestDiagnose(t *testing.T) {
+	root, err := os.MkdirTemp("", "depman-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	// Use a PATH with a duplicate and a missing entry, but without the bin directory
+	existing := filepath.Join(root, "usr-bin")
+	os.MkdirAll(existing, 0755)
+	missing := filepath.Join(root, "missing")
+	t.Setenv("PATH", strings.Join([]string{existing, existing, missing}, string(os.PathListSeparator)))
+
+	originalDial, originalLook := dialHost, lookPath
+	dialHost = func(address string) error {
+		if strings.HasPrefix(address, "down.example.com") {
+			return fmt.Errorf("connection refused")
+		}
+		return nil
+	}
+	lookPath = func(file string) (string, error) {
+		if file == "brew" {
+			return "", fmt.Errorf("not found")
+		}
+		return "/usr/bin/" + file, nil
+	}
+	defer func() { dialHost, lookPath = originalDial, originalLook }()
+
+	configPath := filepath.Join(root, "app-dependencies.yml")
+	lock := &Lockfile{Dependencies: []LockedDependency{{Name: "removed"}}}
+	if err := lock.Save(filepath.Join(root, LockfileName)); err != nil {
+		t.Fatalf("Failed to write lockfile: %v", err)
+	}
+
+	manager := &Manager{
+		Platform:   runtime.GOOS,
+		ConfigPath: configPath,
+		homeDir:    filepath.Join(root, "home"),
+		logger:     &mockLogger{},
+		Config: &DependencyConfig{Dependencies: []Dependency{
+			{Name: "tool", Platforms: map[string]PlatformConfig{runtime.GOOS: {Installer: Installer{Method: "archive", URL: "https://up.example.com/tool.tar.gz"}}}},
+			{Name: "down", Platforms: map[string]PlatformConfig{runtime.GOOS: {Installer: Installer{Method: "archive", URL: "http://down.example.com/down.zip"}}}},
+			{Name: "jq", Platforms: map[string]PlatformConfig{runtime.GOOS: {Installer: Installer{Method: "brew"}}}},
+			{Name: "elsewhere", Platforms: map[string]PlatformConfig{"plan9": {}}},
+		}},
+	}
+
+	findings := manager.Diagnose()
+
+	var got []string
+	for _, finding := range findings {
+		got = append(got, fmt.Sprintf("%s %s %s", finding.Check, finding.Severity, finding.Message))
+	}
+	report := strings.Join(got, "\n")
+
+	expected := []string{
+		"platform warning Dependency elsewhere has no configuration for " + runtime.GOOS,
+		"path warning " + existing + " appears on PATH more than once",
+		"path warning PATH entry " + missing + " does not exist",
+		"path warning Managed bin directory " + manager.BinDir() + " is not on PATH",
+		"package-managers error brew is not on PATH but is needed by jq",
+		"network error Cannot reach down.example.com:80: connection refused",
+		"network ok up.example.com:443 is reachable",
+		"permissions ok " + manager.HomeDir() + " is writable",
+		"cache warning Lockfile entry removed is no longer in the configuration",
+	}
+	for _, line := range expected {
+		if !strings.Contains(report, line) {
+			t.Errorf("Expected finding %q in:\n%s", line, report)
+		}
+	}
+}
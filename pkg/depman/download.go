@@ -0,0 +1,41 @@
+package depman
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/devnadeemashraf/depman/internal/downloader"
+)
+
+// DownloadLimits caps the artifact downloads a manager runs while installing
+// dependencies in parallel. Zero values mean no limit.
+type DownloadLimits struct {
+	MaxParallel       int   // Downloads in flight at once, below the install concurrency
+	MaxPerHost        int   // Downloads in flight to the same host, e.g. an internal mirror
+	MaxBytesPerSecond int64 // Combined rate of every download
+}
+
+// WithDownloadLimits caps the number and combined rate of downloads
+func WithDownloadLimits(limits DownloadLimits) Option {
+	return func(m *Manager) {
+		m.limiter = downloader.NewLimiter(downloader.Limits{
+			Parallel:       limits.MaxParallel,
+			PerHost:        limits.MaxPerHost,
+			BytesPerSecond: limits.MaxBytesPerSecond,
+		})
+	}
+}
+
+// ParseByteRate parses a rate such as "10MB/s", "512KiB" or "1G" into bytes
+// per second
+func ParseByteRate(value string) (int64, error) {
+	trimmed := strings.TrimSpace(value)
+	for _, suffix := range []string{"/s", "/S"} {
+		trimmed = strings.TrimSuffix(trimmed, suffix)
+	}
+	rate, err := parseByteSize(trimmed)
+	if err != nil || rate <= 0 {
+		return 0, fmt.Errorf("invalid rate '%s' (expected a size per second, e.g. 10MB/s)", value)
+	}
+	return rate, nil
+}
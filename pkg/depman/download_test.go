@@ -0,0 +1,32 @@
+package depman
+
+import "testing"
+
+func TestParseByteRate(t *testing.T) {
+	tests := []struct {
+		value     string
+		expected  int64
+		expectErr bool
+	}{
+		{value: "10MB/s", expected: 10_000_000},
+		{value: "512KiB", expected: 512 * 1024},
+		{value: "1G", expected: 1_000_000_000},
+		{value: "0", expectErr: true},
+		{value: "fast", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			rate, err := ParseByteRate(tt.value)
+			if tt.expectErr {
+				if err == nil {
+					t.Errorf("Expected an error but got %d", rate)
+				}
+				return
+			}
+			if err != nil || rate != tt.expected {
+				t.Errorf("Expected %d but got %d (%v)", tt.expected, rate, err)
+			}
+		})
+	}
+}
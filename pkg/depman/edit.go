@@ -0,0 +1,198 @@
+package depman
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// editableConfig is a YAML configuration file parsed for editing, keeping
+// its comments and key order
+type editableConfig struct {
+	path   string
+	data   []byte // Original content, restored if the edited file does not load
+	doc    yaml.Node
+	indent int
+}
+
+// openConfigForEdit parses a configuration file for editing. Only YAML files
+// can be edited, as re-encoding TOML or JSON would lose their formatting.
+func openConfigForEdit(path string) (*editableConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dependency file: %w", err)
+	}
+	if format := DetectConfigFormat(path, data); format != FormatYAML {
+		return nil, fmt.Errorf("%s is not YAML; only YAML configurations can be edited", path)
+	}
+
+	config := &editableConfig{path: path, data: data, indent: detectIndent(data)}
+	if err := yaml.Unmarshal(data, &config.doc); err != nil {
+		return nil, fmt.Errorf("failed to parse dependency file: %w", err)
+	}
+	if len(config.doc.Content) == 0 || config.doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s does not hold a configuration mapping", path)
+	}
+	return config, nil
+}
+
+// detectIndent returns the indentation width a YAML file uses, or 2
+func detectIndent(data []byte) int {
+	for _, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if indent := len(line) - len(trimmed); indent > 0 && trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			return indent
+		}
+	}
+	return 2
+}
+
+// dependencies returns the dependencies sequence, creating it if create is set
+func (c *editableConfig) dependencies(create bool) *yaml.Node {
+	root := c.doc.Content[0]
+	deps := mappingValue(root, "dependencies")
+	if deps == nil && create {
+		deps = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "dependencies"}, deps)
+	}
+	if deps != nil && deps.Kind == yaml.SequenceNode && deps.Style == yaml.FlowStyle && len(deps.Content) == 0 {
+		// "dependencies: []" grows into a block list
+		deps.Style = 0
+	}
+	return deps
+}
+
+// save writes the edited file and loads it, restoring the original content if
+// the edit left a configuration that does not load
+func (c *editableConfig) save() error {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(c.indent)
+	if err := encoder.Encode(&c.doc); err != nil {
+		return fmt.Errorf("failed to encode configuration: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("failed to encode configuration: %w", err)
+	}
+
+	if err := os.WriteFile(c.path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write dependency file: %w", err)
+	}
+	if _, err := LoadDependencyConfig(c.path); err != nil {
+		if restoreErr := os.WriteFile(c.path, c.data, 0644); restoreErr != nil {
+			return fmt.Errorf("failed to restore %s after an invalid edit: %w", c.path, restoreErr)
+		}
+		return fmt.Errorf("the edited configuration is invalid and was not saved: %w", err)
+	}
+	return nil
+}
+
+// quoteValues gives the string values under node the quoting style the file
+// uses for its existing dependencies
+func quoteValues(node *yaml.Node, style yaml.Style) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			quoteValues(node.Content[i], style)
+		}
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			quoteValues(item, style)
+		}
+	case yaml.ScalarNode:
+		if node.Tag == "!!str" {
+			node.Style = style
+		}
+	}
+}
+
+// AddToConfig appends a dependency to the configuration file at path,
+// keeping the file's comments and key order. The file must not define the
+// dependency already, and is left unchanged if the result does not load.
+func AddToConfig(path string, dep Dependency) error {
+	config, err := openConfigForEdit(path)
+	if err != nil {
+		return err
+	}
+
+	deps := config.dependencies(true)
+	if deps.Kind != yaml.SequenceNode {
+		return fmt.Errorf("dependencies in %s is not a list", path)
+	}
+	for _, item := range deps.Content {
+		if name := mappingValue(item, "name"); name != nil && name.Value == dep.Name {
+			return fmt.Errorf("dependency '%s' is already defined in %s", dep.Name, path)
+		}
+	}
+
+	var node yaml.Node
+	if err := node.Encode(dep); err != nil {
+		return fmt.Errorf("failed to encode dependency: %w", err)
+	}
+	pruneEmpty(&node)
+	if len(deps.Content) > 0 {
+		if name := mappingValue(deps.Content[0], "name"); name != nil {
+			quoteValues(&node, name.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle))
+		}
+	}
+	deps.Content = append(deps.Content, &node)
+
+	return config.save()
+}
+
+// RemoveFromConfig deletes a dependency from the configuration file at path,
+// keeping the file's comments and key order. Dependencies still depending on
+// it are refused unless force is set, which drops those references from the
+// file too.
+func RemoveFromConfig(path, name string, force bool) error {
+	loaded, err := LoadDependencyConfig(path)
+	if err != nil {
+		return err
+	}
+	var dependents []string
+	for _, dep := range loaded.Dependencies {
+		if containsString(dep.Dependencies, name) {
+			dependents = append(dependents, dep.Name)
+		}
+	}
+	if len(dependents) > 0 && !force {
+		return fmt.Errorf("dependency '%s' is required by %s", name, strings.Join(dependents, ", "))
+	}
+
+	config, err := openConfigForEdit(path)
+	if err != nil {
+		return err
+	}
+
+	deps := config.dependencies(false)
+	if deps == nil || deps.Kind != yaml.SequenceNode {
+		return fmt.Errorf("dependency '%s' is not defined in %s", name, path)
+	}
+	removed := false
+	kept := deps.Content[:0]
+	for _, item := range deps.Content {
+		if field := mappingValue(item, "name"); field != nil && field.Value == name {
+			removed = true
+			continue
+		}
+		if refs := mappingValue(item, "dependencies"); refs != nil && refs.Kind == yaml.SequenceNode {
+			remaining := refs.Content[:0]
+			for _, ref := range refs.Content {
+				if ref.Value != name {
+					remaining = append(remaining, ref)
+				}
+			}
+			refs.Content = remaining
+		}
+		kept = append(kept, item)
+	}
+	if !removed {
+		return fmt.Errorf("dependency '%s' is not defined in %s", name, path)
+	}
+	deps.Content = kept
+
+	return config.save()
+}
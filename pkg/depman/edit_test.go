@@ -0,0 +1,167 @@
+package depman
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const editableConfigFile = `# Tools for the build
+version: "1.0"
+name: "example"
+dependencies:
+  # Needed by the release scripts
+  - name: "jq"
+    version:
+      constraint: ">=1.6"
+    platforms:
+      linux:
+        installer:
+          method: "github-release"
+          repo: "jqlang/jq"
+  - name: "yq"
+    version:
+      constraint: ">=4.0"
+    dependencies: ["jq"]
+    platforms:
+      linux:
+        installer:
+          method: "github-release"
+          repo: "mikefarah/yq"
+`
+
+// writeEditableConfig writes the example configuration to a temporary file
+func writeEditableConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "app-dependencies.yml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestAddToConfig(t *testing.T) {
+	path := writeEditableConfig(t, editableConfigFile)
+
+	registry, err := LoadRegistry(t.TempDir())
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	dep, ok := registry.Dependency("gh", "")
+	if !ok {
+		t.Fatal("Expected gh to be in the registry")
+	}
+	if err := AddToConfig(path, dep); err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	for _, expected := range []string{"# Tools for the build", "# Needed by the release scripts", `name: "gh"`, `from: "registry/gh"`} {
+		if !strings.Contains(string(data), expected) {
+			t.Errorf("Expected the edited file to contain %q but got:\n%s", expected, data)
+		}
+	}
+
+	config, err := LoadDependencyConfig(path)
+	if err != nil {
+		t.Fatalf("Expected the edited file to load but got %v", err)
+	}
+	if len(config.Dependencies) != 3 || config.Dependencies[2].Version.Constraint != ">=2.0" {
+		t.Errorf("Expected gh to be appended with the recipe constraint but got %+v", config.Dependencies)
+	}
+
+	if err := AddToConfig(path, dep); err == nil || !strings.Contains(err.Error(), "already defined") {
+		t.Errorf("Expected an already defined error but got %v", err)
+	}
+}
+
+func TestAddToConfigCreatesList(t *testing.T) {
+	path := writeEditableConfig(t, "version: \"1.0\"\nname: empty\ndependencies: []\n")
+
+	dep := NewDependency("jq", ">=1.7").OnPlatform("linux", PlatformConfig{Installer: Installer{Method: "github-release", Repo: "jqlang/jq"}})
+	if err := AddToConfig(path, dep); err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	config, err := LoadDependencyConfig(path)
+	if err != nil {
+		t.Fatalf("Expected the edited file to load but got %v", err)
+	}
+	if len(config.Dependencies) != 1 || config.Dependencies[0].Name != "jq" {
+		t.Errorf("Expected jq to be added but got %+v", config.Dependencies)
+	}
+}
+
+func TestAddToConfigRestoresInvalidEdit(t *testing.T) {
+	path := writeEditableConfig(t, editableConfigFile)
+
+	// A dependency without a constraint or pinned version is invalid
+	if err := AddToConfig(path, Dependency{Name: "broken"}); err == nil {
+		t.Fatal("Expected an error for an invalid dependency")
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != editableConfigFile {
+		t.Errorf("Expected the file to be restored but got:\n%s", data)
+	}
+}
+
+func TestRemoveFromConfig(t *testing.T) {
+	tests := []struct {
+		name      string
+		remove    string
+		force     bool
+		expectErr string
+		remaining []string
+	}{
+		{name: "unused dependency", remove: "yq", remaining: []string{"jq"}},
+		{name: "required dependency", remove: "jq", expectErr: "required by yq"},
+		{name: "forced", remove: "jq", force: true, remaining: []string{"yq"}},
+		{name: "missing dependency", remove: "gh", expectErr: "not defined"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeEditableConfig(t, editableConfigFile)
+			err := RemoveFromConfig(path, tt.remove, tt.force)
+			if tt.expectErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectErr) {
+					t.Errorf("Expected error containing %q but got %v", tt.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error but got %v", err)
+			}
+
+			config, err := LoadDependencyConfig(path)
+			if err != nil {
+				t.Fatalf("Expected the edited file to load but got %v", err)
+			}
+			var names []string
+			for _, dep := range config.Dependencies {
+				names = append(names, dep.Name)
+				if containsString(dep.Dependencies, tt.remove) {
+					t.Errorf("Expected %s to no longer depend on %s", dep.Name, tt.remove)
+				}
+			}
+			if strings.Join(names, ",") != strings.Join(tt.remaining, ",") {
+				t.Errorf("Expected dependencies %v but got %v", tt.remaining, names)
+			}
+
+			data, _ := os.ReadFile(path)
+			if !strings.Contains(string(data), "# Tools for the build") {
+				t.Errorf("Expected comments to be kept but got:\n%s", data)
+			}
+		})
+	}
+}
+
+func TestEditRefusesOtherFormats(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app-dependencies.json")
+	if err := os.WriteFile(path, []byte(`{"version": "1.0", "name": "x", "dependencies": []}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddToConfig(path, NewDependency("jq", ">=1.6")); err == nil || !strings.Contains(err.Error(), "only YAML") {
+		t.Errorf("Expected an only YAML error but got %v", err)
+	}
+}
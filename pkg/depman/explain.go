@@ -0,0 +1,287 @@
+package depman
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Explanation describes how ensure arrives at what it does with a dependency
+type Explanation struct {
+	Name           string   `json:"name"`                      // Name of the dependency
+	Selected       bool     `json:"selected"`                  // Whether the dependency is in the plan
+	Reasons        []string `json:"reasons"`                   // Why the dependency is, or is not, in the plan
+	RequiredBy     []string `json:"required_by,omitempty"`     // Dependencies that list it in their dependencies
+	Requires       []string `json:"requires,omitempty"`        // Dependencies it lists, installed before it
+	Recipe         string   `json:"recipe,omitempty"`          // Registry recipe it is based on, e.g. "registry/jq"
+	RecipeRegistry string   `json:"recipe_registry,omitempty"` // Registry the recipe came from
+	Required       string   `json:"required,omitempty"`        // Exact version the configuration pins
+	Constraint     string   `json:"constraint,omitempty"`      // Version constraint that applies
+	Target         string   `json:"target"`                    // Platform it is installed for, e.g. "linux/amd64"
+	Platforms      []string `json:"platforms,omitempty"`       // Platform entries the dependency defines
+	Platform       string   `json:"platform,omitempty"`        // Platform entry that was selected
+	PlatformReason string   `json:"platform_reason,omitempty"` // Why that entry was selected
+	Method         string   `json:"method,omitempty"`          // Install method of the selected entry
+	Source         string   `json:"source,omitempty"`          // Where the dependency is installed from
+	Mirror         string   `json:"mirror,omitempty"`          // Source rewritten by a mirror, if one applies
+	Resolved       string   `json:"resolved,omitempty"`        // Version an install would pick
+	ResolvedFrom   string   `json:"resolved_from,omitempty"`   // How that version was chosen
+	Locked         string   `json:"locked,omitempty"`          // Version recorded in the lockfile, if any
+	Installed      string   `json:"installed,omitempty"`       // Version currently installed, if any
+	Action         Action   `json:"action"`                    // What ensure would do
+	ActionReason   string   `json:"action_reason,omitempty"`   // Why ensure would do it
+	Error          string   `json:"error,omitempty"`           // Why part of the explanation could not be worked out
+}
+
+// Explain runs the planning pipeline of ensure and reports each decision it
+// made for one dependency: why it is in the plan, which platform entry,
+// install method and version apply, and which dependencies require it.
+// Versions are looked up from the install source when nothing pins them.
+func (m *Manager) Explain(ctx context.Context, name string) (*Explanation, error) {
+	dep := m.FindDependency(name)
+	if dep == nil {
+		return nil, fmt.Errorf("dependency '%s' not found in configuration", name)
+	}
+
+	plan, err := m.PlanEnsure(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Explanation{
+		Name:       dep.Name,
+		RequiredBy: m.dependentsOf(dep.Name),
+		Requires:   dep.Dependencies,
+		Recipe:     dep.From,
+		Required:   dep.Version.Required,
+		Constraint: dep.Version.Constraint,
+		Target:     m.environmentTarget(m.environments(dep)[0]),
+	}
+	for key := range dep.Platforms {
+		e.Platforms = append(e.Platforms, key)
+	}
+	sort.Strings(e.Platforms)
+
+	if err := m.explainSelection(e, dep); err != nil {
+		return nil, err
+	}
+	m.explainRecipe(e, dep)
+	m.explainPlatform(e, dep)
+
+	for _, action := range plan.Actions {
+		if action.Name != dep.Name {
+			continue
+		}
+		e.Action, e.ActionReason = action.Action, action.Reason
+		if action.Status != nil {
+			e.Installed = action.Status.CurrentVersion
+		}
+	}
+	if !e.Selected {
+		e.ActionReason = "not in the plan"
+	}
+
+	m.explainVersion(ctx, e, dep)
+	return e, nil
+}
+
+// explainSelection records why the dependency is in the plan
+func (m *Manager) explainSelection(e *Explanation, dep *Dependency) error {
+	switch {
+	case len(dep.Projects) > 0:
+		e.Reasons = append(e.Reasons, fmt.Sprintf("declared by workspace projects %s", strings.Join(dep.Projects, ", ")))
+	case m.ConfigPath != "":
+		e.Reasons = append(e.Reasons, fmt.Sprintf("declared in %s", m.ConfigPath))
+	default:
+		e.Reasons = append(e.Reasons, "declared in the configuration")
+	}
+
+	if m.profile != "" {
+		for _, changed := range m.Config.Profiles[m.profile].Dependencies {
+			if changed.Name == dep.Name {
+				e.Reasons = append(e.Reasons, fmt.Sprintf("added or changed by profile %s", m.profile))
+				break
+			}
+		}
+	}
+	if dep.When != "" {
+		e.Reasons = append(e.Reasons, fmt.Sprintf("condition %s holds on this machine", dep.When))
+	}
+
+	if m.selection.empty() {
+		e.Selected = true
+		return nil
+	}
+	roots, err := m.selectionRoots()
+	if err != nil {
+		return err
+	}
+	if containsString(roots, dep.Name) {
+		e.Selected = true
+		e.Reasons = append(e.Reasons, "picked by the selection (--only, --tag and --skip)")
+		return nil
+	}
+	if chain := m.requirementChain(roots, dep.Name); chain != nil {
+		e.Selected = true
+		e.Reasons = append(e.Reasons, fmt.Sprintf("required by selected dependency %s", strings.Join(chain, " -> ")))
+		return nil
+	}
+	e.Reasons = append(e.Reasons, "left out by the selection (--only, --tag and --skip), and no selected dependency requires it")
+	return nil
+}
+
+// requirementChain returns the shortest chain of dependencies from one of
+// roots to name, or nil if none of them requires it
+func (m *Manager) requirementChain(roots []string, name string) []string {
+	parent := make(map[string]string)
+	queue := append([]string(nil), roots...)
+	for _, root := range roots {
+		parent[root] = ""
+	}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current == name {
+			var chain []string
+			for step := current; step != ""; step = parent[step] {
+				chain = append([]string{step}, chain...)
+			}
+			return chain
+		}
+		if dep := m.FindDependency(current); dep != nil {
+			for _, child := range dep.Dependencies {
+				if _, seen := parent[child]; !seen {
+					parent[child] = current
+					queue = append(queue, child)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// explainRecipe records which registry the dependency's recipe came from
+func (m *Manager) explainRecipe(e *Explanation, dep *Dependency) {
+	if dep.From == "" {
+		return
+	}
+	source, name, err := parseFrom(dep.From)
+	if err != nil {
+		return
+	}
+	if source != anyRegistry {
+		e.RecipeRegistry = source
+		return
+	}
+	if registry, err := LoadRegistry(DefaultHomeDir()); err == nil {
+		e.RecipeRegistry = registry.Origin(name)
+	}
+}
+
+// explainPlatform records the platform entry, install method and source
+// that apply
+func (m *Manager) explainPlatform(e *Explanation, dep *Dependency) {
+	if _, ok := kindPlatformConfig(dep); ok {
+		e.PlatformReason = fmt.Sprintf("%s dependencies are installed the same way on every platform", dep.kind())
+	} else if key, ok := m.platformKey(dep); ok {
+		e.Platform = key
+		goos, arch := m.Platform, m.targetArch()
+		if env := m.environments(dep)[0]; env == WSLWindows {
+			goos = "windows"
+		}
+		switch {
+		case isDistroKey(key):
+			e.PlatformReason = fmt.Sprintf("the entry for the %s distribution wins over %s entries", m.Distro(), goos)
+		case key == goos+"/"+arch:
+			e.PlatformReason = "exact match for the platform and architecture"
+		case key == goos:
+			e.PlatformReason = fmt.Sprintf("no %s/%s entry, so the %s entry applies to every architecture", goos, arch, goos)
+		default:
+			e.PlatformReason = fmt.Sprintf("no native entry for %s/%s, so %s runs through emulation", goos, arch, key)
+		}
+	}
+
+	platformConfig, err := m.resolvedPlatformConfig(dep)
+	if err != nil {
+		e.Error = err.Error()
+		return
+	}
+	e.Method = installMethod(platformConfig)
+	if e.Source = platformConfig.Installer.URL; e.Source != "" {
+		// Without a pinned version, {{version}} is only known at install time
+		if dep.Version.Required != "" {
+			e.Source = expandURLTemplate(e.Source, dep, m.Platform, m.targetArch())
+		}
+		if mirrored := m.mirrorURL(e.Source); mirrored != e.Source {
+			e.Mirror = redactURL(mirrored)
+		}
+		e.Source = redactURL(e.Source)
+	} else {
+		e.Source = installSource(dep, platformConfig)
+	}
+}
+
+// isDistroKey reports whether a platforms key selects a Linux distribution
+func isDistroKey(key string) bool {
+	_, ok := parseDistroSelector(key)
+	return ok
+}
+
+// explainVersion records the version an install would pick and why
+func (m *Manager) explainVersion(ctx context.Context, e *Explanation, dep *Dependency) {
+	lock, err := LoadLockfile(m.LockfilePath())
+	if err != nil && !errors.Is(err, ErrLockfileNotFound) {
+		e.Error = err.Error()
+	}
+	if lock != nil {
+		if entry := lock.Find(dep.Name); entry != nil {
+			e.Locked = entry.Version
+		}
+	}
+
+	switch {
+	case m.frozen && e.Locked != "":
+		e.Resolved, e.ResolvedFrom = e.Locked, fmt.Sprintf("pinned by the lockfile %s (frozen)", m.LockfilePath())
+	case m.override(dep.Name) != nil && dep.Version.Required != "":
+		e.Resolved, e.ResolvedFrom = dep.Version.Required, "pinned locally by "+m.override(dep.Name).Source
+	case dep.Version.Required != "":
+		e.Resolved, e.ResolvedFrom = dep.Version.Required, "pinned by version.required"
+	case e.Error == "":
+		if !m.listsVersions(dep) {
+			e.ResolvedFrom = fmt.Sprintf("decided by the %s install method at install time", e.Method)
+			return
+		}
+		versions, err := m.sourceVersions(ctx, dep)
+		if err != nil {
+			e.Error = err.Error()
+			return
+		}
+		resolved, err := newestVersion(versions, dep.Version.Constraint)
+		if err != nil {
+			e.Error = err.Error()
+			return
+		}
+		e.Resolved = resolved
+		e.ResolvedFrom = fmt.Sprintf("newest of %d versions from %s", len(versions), e.Source)
+		if dep.Version.Constraint != "" {
+			e.ResolvedFrom = fmt.Sprintf("newest of %d versions from %s satisfying %s", len(versions), e.Source, dep.Version.Constraint)
+		}
+	}
+}
+
+// listsVersions reports whether the install method of a dependency can list
+// the versions its source offers
+func (m *Manager) listsVersions(dep *Dependency) bool {
+	platformConfig, err := m.resolvedPlatformConfig(dep)
+	if err != nil {
+		return false
+	}
+	strategy, err := m.strategyFor(platformConfig)
+	if err != nil {
+		return false
+	}
+	_, ok := strategy.(versionLister)
+	return ok
+}
@@ -0,0 +1,100 @@
+package depman
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "deps.yml")
+	os.WriteFile(configPath, []byte(`version: "1.0"
+mirrors:
+  - prefix: "https://example.com/"
+    replace: "https://mirror.example.com/"
+dependencies:
+  - name: api
+    version:
+      required: "1.0.0"
+    dependencies: [jq]
+    platforms:
+      linux:
+        installer:
+          method: archive
+          url: "https://example.com/api"
+        commands:
+          verify: ["depman-test-missing-api", "--version"]
+  - name: jq
+    version:
+      required: "1.7.1"
+    platforms:
+      linux:
+        installer:
+          method: archive
+          url: "https://example.com/jq-{{version}}"
+        commands:
+          verify: ["depman-test-missing-jq", "--version"]
+      linux/arm64:
+        installer:
+          method: archive
+          url: "https://example.com/jq-arm64"
+  - name: docs
+    version:
+      required: "2.0.0"
+    tags: [docs]
+    platforms:
+      linux:
+        commands:
+          verify: ["depman-test-missing-docs", "--version"]
+`), 0644)
+
+	manager, err := NewManager(configPath,
+		WithPlatform("linux/amd64"),
+		WithHomeDir(filepath.Join(dir, "home")),
+		WithSettings(Settings{}),
+		WithSelection(Selection{Only: []string{"api"}}),
+		WithLogOutput(io.Discard),
+	)
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+
+	e, err := manager.Explain(context.Background(), "jq")
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if !e.Selected || !strings.Contains(strings.Join(e.Reasons, "; "), "api -> jq") {
+		t.Errorf("Expected jq to be in the plan because api requires it but got %v", e.Reasons)
+	}
+	if len(e.RequiredBy) != 1 || e.RequiredBy[0] != "api" {
+		t.Errorf("Expected jq to be required by api but got %v", e.RequiredBy)
+	}
+	if e.Platform != "linux" || !strings.Contains(e.PlatformReason, "every architecture") {
+		t.Errorf("Expected the linux entry to apply to amd64 but got %s (%s)", e.Platform, e.PlatformReason)
+	}
+	if e.Method != "archive" || e.Source != "https://example.com/jq-1.7.1" || e.Mirror != "https://mirror.example.com/jq-1.7.1" {
+		t.Errorf("Unexpected method and source: %s %s %s", e.Method, e.Source, e.Mirror)
+	}
+	if e.Resolved != "1.7.1" || e.ResolvedFrom != "pinned by version.required" {
+		t.Errorf("Expected the pinned version but got %s (%s)", e.Resolved, e.ResolvedFrom)
+	}
+	if e.Action != ActionInstall {
+		t.Errorf("Expected ensure to install jq but got %s", e.Action)
+	}
+
+	e, err = manager.Explain(context.Background(), "docs")
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if e.Selected || e.ActionReason != "not in the plan" {
+		t.Errorf("Expected docs to be left out of the plan but got %+v", e)
+	}
+
+	if _, err := manager.Explain(context.Background(), "missing"); err == nil {
+		t.Error("Expected an error for an unknown dependency but got none")
+	}
+}
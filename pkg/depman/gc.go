@@ -0,0 +1,286 @@
+package depman
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/devnadeemashraf/depman/internal/cache"
+)
+
+// GCOptions controls what CollectGarbage removes
+type GCOptions struct {
+	Keep     int      // Most recent versions of each tool kept even when nothing references them
+	DryRun   bool     // Whether to only report what would be removed
+	Configs  []string // Configuration files to treat as known in addition to those in the state file
+	CacheDir string   // Download cache to collect (defaults to the cache directory under homeDir)
+}
+
+// GCItemKind tells what kind of file garbage collection removed
+type GCItemKind string
+
+// Kinds of collected items
+const (
+	GCToolVersion GCItemKind = "version"  // An installed version of a managed tool
+	GCArtifact    GCItemKind = "artifact" // A cached download
+)
+
+// GCItem is an installed version or cached download that garbage collection removed
+type GCItem struct {
+	Kind    GCItemKind `json:"kind"`              // What was removed
+	Name    string     `json:"name"`              // Tool name, or URL of the cached artifact
+	Version string     `json:"version,omitempty"` // Tool version
+	Path    string     `json:"path"`              // File or directory removed
+	Size    int64      `json:"size"`              // Bytes freed
+}
+
+// GCResult lists what garbage collection removed
+type GCResult struct {
+	Removed []GCItem `json:"removed"` // Removed versions and artifacts
+	Freed   int64    `json:"freed"`   // Total bytes freed
+	DryRun  bool     `json:"dry_run"` // Whether nothing was actually removed
+}
+
+// gcReferences are the tool versions and artifacts some known configuration still needs
+type gcReferences struct {
+	versions  map[string]map[string]bool // Versions by tool name
+	artifacts map[string]bool            // Cache keys
+	pinned    map[string]bool            // Tools of remote configurations, whose versions are all kept
+}
+
+// CollectGarbage removes tool versions under homeDir and cached downloads in
+// opts.CacheDir that no known configuration or its lockfile references. Known
+// configurations are the ones recorded in the state file plus opts.Configs;
+// every scope recorded there is collected. A configuration that exists but
+// fails to load stops collection, since what it needs is unknown.
+func CollectGarbage(homeDir string, opts GCOptions) (*GCResult, error) {
+	state, err := loadState(filepath.Join(homeDir, stateFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	refs, err := collectReferences(state, opts.Configs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &GCResult{Removed: []GCItem{}, DryRun: opts.DryRun}
+	homes := []string{homeDir}
+	for _, install := range state.Installs {
+		if !containsString(homes, install.Home) {
+			homes = append(homes, install.Home)
+		}
+	}
+	for _, home := range homes {
+		if err := collectToolVersions(home, refs, opts, state, result); err != nil {
+			return result, err
+		}
+	}
+	cacheDir := opts.CacheDir
+	if cacheDir == "" {
+		cacheDir = filepath.Join(homeDir, "cache")
+	}
+	if err := collectArtifacts(cacheDir, refs, opts, result); err != nil {
+		return result, err
+	}
+
+	if !opts.DryRun {
+		if err := state.save(filepath.Join(homeDir, stateFileName)); err != nil {
+			return result, err
+		}
+	}
+	for _, item := range result.Removed {
+		result.Freed += item.Size
+	}
+	return result, nil
+}
+
+// collectReferences gathers what the known configurations and their lockfiles need
+func collectReferences(state *installState, extra []string) (*gcReferences, error) {
+	refs := &gcReferences{
+		versions:  make(map[string]map[string]bool),
+		artifacts: make(map[string]bool),
+		pinned:    make(map[string]bool),
+	}
+	reference := func(name, version string) {
+		if refs.versions[name] == nil {
+			refs.versions[name] = make(map[string]bool)
+		}
+		refs.versions[name][toolVersion(version)] = true
+	}
+
+	var configs []string
+	for _, install := range state.Installs {
+		// The version depman last installed is the one the shim runs
+		reference(install.Name, install.Version)
+		for _, config := range install.Configs {
+			if IsRemoteConfig(config) {
+				refs.pinned[install.Name] = true
+			} else if !containsString(configs, config) {
+				configs = append(configs, config)
+			}
+		}
+	}
+	for _, config := range extra {
+		if abs, err := filepath.Abs(config); err == nil && !containsString(configs, abs) {
+			configs = append(configs, abs)
+		}
+	}
+
+	for _, path := range configs {
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		config, err := LoadDependencyConfig(path)
+		if err != nil {
+			return nil, fmt.Errorf("cannot tell what %s needs: %w", path, err)
+		}
+		for _, dep := range config.Dependencies {
+			if dep.Version.Required != "" {
+				reference(dep.Name, dep.Version.Required)
+			}
+			for _, platformConfig := range dep.Platforms {
+				installer := platformConfig.Installer
+				if installer.URL != "" && installer.Checksum != "" && !strings.Contains(installer.URL, "{{") {
+					refs.artifacts[cache.Key(installer.URL, installer.Checksum)] = true
+				}
+			}
+		}
+
+		lock, err := LoadLockfile(filepath.Join(filepath.Dir(path), LockfileName))
+		if err != nil {
+			continue
+		}
+		for _, entry := range lock.Dependencies {
+			reference(entry.Name, entry.Version)
+			for _, artifact := range entry.Platforms {
+				if artifact.URL != "" && artifact.Checksum != "" {
+					refs.artifacts[cache.Key(artifact.URL, artifact.Checksum)] = true
+				}
+			}
+		}
+	}
+	return refs, nil
+}
+
+// collectToolVersions removes unreferenced versions from a home directory's
+// tools, keeping the opts.Keep most recent of each tool. Tools left without
+// any version lose their recorded files, such as the shim, and their record.
+func collectToolVersions(home string, refs *gcReferences, opts GCOptions, state *installState, result *GCResult) error {
+	toolsDir := filepath.Join(home, "tools")
+	tools, err := os.ReadDir(toolsDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", toolsDir, err)
+	}
+
+	for _, tool := range tools {
+		if !tool.IsDir() || refs.pinned[tool.Name()] {
+			continue
+		}
+		toolDir := filepath.Join(toolsDir, tool.Name())
+		entries, err := os.ReadDir(toolDir)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", toolDir, err)
+		}
+
+		var versions []string
+		for _, entry := range entries {
+			if entry.IsDir() {
+				versions = append(versions, entry.Name())
+			}
+		}
+		sortVersionsNewestFirst(versions)
+
+		kept := 0
+		for i, version := range versions {
+			if i < opts.Keep || refs.versions[tool.Name()][version] {
+				kept++
+				continue
+			}
+			dir := filepath.Join(toolDir, version)
+			item := GCItem{Kind: GCToolVersion, Name: tool.Name(), Version: version, Path: dir, Size: diskUsage(dir)}
+			if !opts.DryRun {
+				if err := os.RemoveAll(dir); err != nil {
+					return fmt.Errorf("failed to remove %s: %w", dir, err)
+				}
+			}
+			result.Removed = append(result.Removed, item)
+		}
+
+		if kept == 0 && !opts.DryRun {
+			os.Remove(toolDir)
+			if install := state.find(tool.Name(), home); install != nil {
+				if err := removeInstalledFiles(install); err != nil {
+					return err
+				}
+				state.remove(tool.Name(), home)
+			}
+		}
+	}
+	return nil
+}
+
+// sortVersionsNewestFirst orders version directory names by semantic version,
+// newest first, with names that are not versions last in reverse lexical order
+func sortVersionsNewestFirst(versions []string) {
+	sort.SliceStable(versions, func(i, j int) bool {
+		vi, errI := semver.NewVersion(versions[i])
+		vj, errJ := semver.NewVersion(versions[j])
+		switch {
+		case errI == nil && errJ == nil:
+			return vi.GreaterThan(vj)
+		case errI == nil || errJ == nil:
+			return errI == nil
+		default:
+			return versions[i] > versions[j]
+		}
+	})
+}
+
+// collectArtifacts removes cached downloads no lockfile or configuration references
+func collectArtifacts(dir string, refs *gcReferences, opts GCOptions, result *GCResult) error {
+	c := cache.New(dir)
+	entries, err := c.List()
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if refs.artifacts[entry.Key] {
+			continue
+		}
+		if !opts.DryRun {
+			if err := c.Remove(entry.Key); err != nil {
+				return fmt.Errorf("failed to remove cache entry %s: %w", entry.Key, err)
+			}
+		}
+		result.Removed = append(result.Removed, GCItem{
+			Kind: GCArtifact,
+			Name: entry.URL,
+			Path: filepath.Join(dir, entry.Key),
+			Size: entry.Size,
+		})
+	}
+	return nil
+}
+
+// diskUsage returns the total size of the files under path
+func diskUsage(path string) int64 {
+	var size int64
+	filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
@@ -0,0 +1,189 @@
+package depman
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/devnadeemashraf/depman/internal/cache"
+)
+
+// newGCTestHome creates a home directory with installed tool versions, cached
+// artifacts and a configuration that needs tool 1.2.3 and one artifact
+func newGCTestHome(t *testing.T) string {
+	t.Helper()
+
+	homeDir, err := os.MkdirTemp("", "depman-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(homeDir) })
+
+	for _, dir := range []string{"tool/1.0.0", "tool/1.1.0", "tool/1.2.3", "gone/0.1.0"} {
+		path := filepath.Join(homeDir, "tools", dir, "binary")
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create tool directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("binary"), 0755); err != nil {
+			t.Fatalf("Failed to write binary: %v", err)
+		}
+	}
+
+	projectDir := filepath.Join(homeDir, "project")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatalf("Failed to create project directory: %v", err)
+	}
+	configPath := filepath.Join(projectDir, "app-dependencies.yml")
+	config := `
+dependencies:
+  - name: "tool"
+    version:
+      required: "1.2.3"
+    platforms:
+      linux:
+        installer: {method: "archive", url: "https://example.com/tool-{{version}}.tar.gz"}
+`
+	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	lock := &Lockfile{Dependencies: []LockedDependency{{
+		Name:      "tool",
+		Version:   "1.2.3",
+		Platforms: map[string]LockedArtifact{"linux": {URL: "https://example.com/tool-1.2.3.tar.gz", Checksum: "sha256:aaaa"}},
+	}}}
+	if err := lock.Save(filepath.Join(projectDir, LockfileName)); err != nil {
+		t.Fatalf("Failed to write lockfile: %v", err)
+	}
+
+	artifact := filepath.Join(homeDir, "tool.tar.gz")
+	if err := os.WriteFile(artifact, []byte("artifact"), 0644); err != nil {
+		t.Fatalf("Failed to write artifact: %v", err)
+	}
+	c := cache.New(filepath.Join(homeDir, "cache"))
+	for _, url := range []string{"https://example.com/tool-1.2.3.tar.gz", "https://example.com/tool-1.0.0.tar.gz"} {
+		if err := c.Store(url, "sha256:aaaa", artifact); err != nil {
+			t.Fatalf("Failed to store artifact: %v", err)
+		}
+	}
+
+	state := &installState{Installs: []*ManagedInstall{{Name: "tool", Version: "1.2.3", Home: homeDir, Configs: []string{configPath}}}}
+	if err := state.save(filepath.Join(homeDir, stateFileName)); err != nil {
+		t.Fatalf("Failed to write state: %v", err)
+	}
+	return homeDir
+}
+
+func TestCollectGarbage(t *testing.T) {
+	testCases := []struct {
+		name     string
+		opts     GCOptions
+		expected []string
+	}{
+		{
+			name:     "Unreferenced",
+			opts:     GCOptions{},
+			expected: []string{"artifact https://example.com/tool-1.0.0.tar.gz", "version gone 0.1.0", "version tool 1.0.0", "version tool 1.1.0"},
+		},
+		{
+			name:     "Keep latest",
+			opts:     GCOptions{Keep: 1},
+			expected: []string{"artifact https://example.com/tool-1.0.0.tar.gz", "version tool 1.0.0", "version tool 1.1.0"},
+		},
+		{
+			name:     "Keep two",
+			opts:     GCOptions{Keep: 2},
+			expected: []string{"artifact https://example.com/tool-1.0.0.tar.gz", "version tool 1.0.0"},
+		},
+		{
+			name:     "Dry run",
+			opts:     GCOptions{DryRun: true},
+			expected: []string{"artifact https://example.com/tool-1.0.0.tar.gz", "version gone 0.1.0", "version tool 1.0.0", "version tool 1.1.0"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			homeDir := newGCTestHome(t)
+
+			result, err := CollectGarbage(homeDir, tc.opts)
+			if err != nil {
+				t.Fatalf("Did not expect an error but got: %v", err)
+			}
+
+			var removed []string
+			var freed int64
+			for _, item := range result.Removed {
+				removed = append(removed, strings.TrimSpace(string(item.Kind)+" "+item.Name+" "+item.Version))
+				freed += item.Size
+				if _, err := os.Stat(item.Path); tc.opts.DryRun == os.IsNotExist(err) {
+					t.Errorf("Expected %s to exist only in a dry run", item.Path)
+				}
+			}
+			sort.Strings(removed)
+			if strings.Join(removed, ", ") != strings.Join(tc.expected, ", ") {
+				t.Errorf("Expected %v to be removed but got %v", tc.expected, removed)
+			}
+			if result.Freed != freed || freed == 0 {
+				t.Errorf("Expected %d bytes freed but got %d", freed, result.Freed)
+			}
+
+			if _, err := os.Stat(filepath.Join(homeDir, "tools", "tool", "1.2.3")); err != nil {
+				t.Errorf("Expected the referenced version to be kept: %v", err)
+			}
+		})
+	}
+}
+
+func TestCollectGarbageCacheDir(t *testing.T) {
+	homeDir := newGCTestHome(t)
+
+	// The cache_dir setting moves downloads out of the home directory
+	cacheDir := filepath.Join(t.TempDir(), "cache")
+	if err := os.Rename(filepath.Join(homeDir, "cache"), cacheDir); err != nil {
+		t.Fatalf("Failed to move the cache: %v", err)
+	}
+
+	result, err := CollectGarbage(homeDir, GCOptions{Keep: 2, CacheDir: cacheDir})
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+
+	var artifacts []string
+	for _, item := range result.Removed {
+		if item.Kind == GCArtifact {
+			artifacts = append(artifacts, item.Name)
+			if !strings.HasPrefix(item.Path, cacheDir) {
+				t.Errorf("Expected %s to be in %s", item.Path, cacheDir)
+			}
+		}
+	}
+	if strings.Join(artifacts, ", ") != "https://example.com/tool-1.0.0.tar.gz" {
+		t.Errorf("Expected the unreferenced artifact to be removed from the cache directory but got %v", artifacts)
+	}
+
+	entries, err := cache.New(cacheDir).List()
+	if err != nil {
+		t.Fatalf("Failed to list the cache: %v", err)
+	}
+	if len(entries) != 1 || entries[0].URL != "https://example.com/tool-1.2.3.tar.gz" {
+		t.Errorf("Expected only the referenced artifact to be kept but got %v", entries)
+	}
+}
+
+func TestCollectGarbageInvalidConfig(t *testing.T) {
+	homeDir := newGCTestHome(t)
+
+	configPath := filepath.Join(homeDir, "project", "app-dependencies.yml")
+	if err := os.WriteFile(configPath, []byte("dependencies: [\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	if _, err := CollectGarbage(homeDir, GCOptions{}); err == nil {
+		t.Errorf("Expected an error but got none")
+	}
+	if _, err := os.Stat(filepath.Join(homeDir, "tools", "tool", "1.0.0")); err != nil {
+		t.Errorf("Expected nothing to be removed: %v", err)
+	}
+}
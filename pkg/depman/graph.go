@@ -0,0 +1,183 @@
+package depman
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// CycleError reports a cycle in the dependency graph
+type CycleError struct {
+	Cycle []string // Names along the cycle, starting and ending with the same dependency
+}
+
+func (e *CycleError) Error() string {
+	return fmt.Sprintf("dependency cycle detected: %s", strings.Join(e.Cycle, " -> "))
+}
+
+// resolveInstallOrder expands the named dependencies with their transitive
+// dependencies and orders them topologically so every dependency comes before
+// its dependents
+func (m *Manager) resolveInstallOrder(names []string) ([]*Dependency, error) {
+	var order []*Dependency
+	visited := make(map[string]bool)
+	var stack []string
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		if visited[name] {
+			return nil
+		}
+		for i, onStack := range stack {
+			if onStack == name {
+				return &CycleError{Cycle: append(append([]string{}, stack[i:]...), name)}
+			}
+		}
+
+		dep := m.resolveReference(name)
+		if dep == nil {
+			if len(stack) > 0 {
+				return fmt.Errorf("dependency '%s' required by '%s' not found in configuration", name, stack[len(stack)-1])
+			}
+			return fmt.Errorf("dependency '%s' not found in configuration", name)
+		}
+
+		if dep.Name != name {
+			// A provided name stands for the dependency providing it
+			if visited[dep.Name] {
+				return nil
+			}
+			name = dep.Name
+		}
+
+		stack = append(stack, name)
+		for _, child := range dep.Dependencies {
+			if err := visit(child); err != nil {
+				return err
+			}
+		}
+		stack = stack[:len(stack)-1]
+		visited[name] = true
+
+		order = append(order, dep)
+		return nil
+	}
+
+	for _, name := range names {
+		if err := visit(name); err != nil {
+			return nil, err
+		}
+	}
+
+	return order, nil
+}
+
+// allDependencyNames returns the names of all configured dependencies in configuration order
+func (m *Manager) allDependencyNames() []string {
+	names := make([]string, len(m.Config.Dependencies))
+	for i, dep := range m.Config.Dependencies {
+		names[i] = dep.Name
+	}
+	return names
+}
+
+// concurrentInstaller is implemented by install strategies whose installs
+// may safely run at the same time as others. Package managers hold global
+// locks, so every other strategy installs one dependency at a time.
+type concurrentInstaller interface {
+	concurrent() bool
+}
+
+// installGraph runs install for each dependency once all of its dependencies
+// in deps have been installed, so independent subtrees install in parallel.
+// deps must be in topological order. A dependency whose prerequisite failed
+// is not attempted, and none are started once ctx is done. The first error
+// in order is returned.
+func (m *Manager) installGraph(ctx context.Context, deps []*Dependency, install func(ctx context.Context, dep *Dependency) error) error {
+	done := make(map[string]chan struct{}, len(deps))
+	for _, dep := range deps {
+		done[dep.Name] = make(chan struct{})
+	}
+
+	errs := make([]error, len(deps))
+	failed := make(map[string]bool)
+	var failedMu sync.Mutex
+
+	workers := make(chan struct{}, m.workerCount(len(deps)))
+	var exclusive sync.Mutex
+
+	var wg sync.WaitGroup
+	for i, dep := range deps {
+		wg.Add(1)
+		go func(i int, dep *Dependency) {
+			defer wg.Done()
+			defer close(done[dep.Name])
+
+			// Wait for prerequisites that are part of this run
+			for _, child := range m.prerequisites(dep, done) {
+				ch, ok := done[child]
+				if !ok {
+					continue
+				}
+				<-ch
+
+				failedMu.Lock()
+				childFailed := failed[child]
+				failedMu.Unlock()
+				if childFailed {
+					errs[i] = fmt.Errorf("skipped %s because its dependency %s failed", dep.Name, child)
+					failedMu.Lock()
+					failed[dep.Name] = true
+					failedMu.Unlock()
+					return
+				}
+			}
+
+			workers <- struct{}{}
+			defer func() { <-workers }()
+
+			if !m.installsConcurrently(dep) {
+				exclusive.Lock()
+				defer exclusive.Unlock()
+			}
+
+			if err := ctx.Err(); err != nil {
+				errs[i] = fmt.Errorf("skipped %s: %w", dep.Name, err)
+				failedMu.Lock()
+				failed[dep.Name] = true
+				failedMu.Unlock()
+				return
+			}
+
+			if err := install(ctx, dep); err != nil {
+				errs[i] = err
+				failedMu.Lock()
+				failed[dep.Name] = true
+				failedMu.Unlock()
+			}
+		}(i, dep)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// installsConcurrently reports whether a dependency's install may overlap with others
+func (m *Manager) installsConcurrently(dep *Dependency) bool {
+	platformConfig, err := m.GetPlatformConfig(dep)
+	if err != nil {
+		return false
+	}
+	strategy, err := m.strategyFor(platformConfig)
+	if err != nil {
+		return false
+	}
+	c, ok := strategy.(concurrentInstaller)
+	return ok && c.concurrent()
+}
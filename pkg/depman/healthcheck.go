@@ -0,0 +1,175 @@
+package depman
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// defaultHealthCheckTimeout bounds a health check that sets no timeout
+const defaultHealthCheckTimeout = defaultProbeTimeout
+
+// HealthCheck verifies that an installed dependency actually works, for tools
+// that install fine but need more setup before they can be used. Exactly one
+// of Command and HTTP is set.
+type HealthCheck struct {
+	Command  []string `yaml:"command"`                // Command that must exit with ExitCode, e.g. ["docker", "info"]
+	ExitCode int      `yaml:"exit_code"`              // Exit code the command must return (default 0)
+	Output   string   `yaml:"output"`                 // Regex the command's output or the HTTP response body must match
+	HTTP     string   `yaml:"http" interpolate:"url"` // URL that must answer with Status
+	Status   int      `yaml:"status"`                 // HTTP status the URL must answer with (default any 2xx or 3xx)
+	Timeout  string   `yaml:"timeout"`                // How long the check may take (default 5s)
+}
+
+// HealthCheckError reports a failed post-install health check
+type HealthCheckError struct {
+	Dependency string // Name of the dependency
+	Check      string // The check that failed, e.g. "command docker info"
+	Err        error  // Why it failed
+}
+
+func (e *HealthCheckError) Error() string {
+	return fmt.Sprintf("health check of %s failed (%s): %v", e.Dependency, e.Check, e.Err)
+}
+
+func (e *HealthCheckError) Unwrap() error {
+	return e.Err
+}
+
+// isSet reports whether the dependency declares a health check
+func (h HealthCheck) isSet() bool {
+	return len(h.Command) > 0 || h.HTTP != ""
+}
+
+// validate checks that exactly one check is set and that it is well formed
+func (h HealthCheck) validate() error {
+	hasCommand, hasHTTP := len(h.Command) > 0, h.HTTP != ""
+	if hasCommand == hasHTTP {
+		return fmt.Errorf("expected exactly one of command or http")
+	}
+	if h.HTTP != "" && !strings.Contains(h.HTTP, "${") {
+		if u, err := url.Parse(h.HTTP); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid http URL '%s'", h.HTTP)
+		}
+	}
+	if len(h.Command) > 0 && h.Status != 0 {
+		return fmt.Errorf("status only applies to http checks")
+	}
+	if h.HTTP != "" && h.ExitCode != 0 {
+		return fmt.Errorf("exit_code only applies to command checks")
+	}
+	if h.Output != "" {
+		if _, err := regexp.Compile(h.Output); err != nil {
+			return fmt.Errorf("invalid output pattern: %w", err)
+		}
+	}
+	if h.Timeout != "" {
+		if _, err := parseTimeout(h.Timeout); err != nil {
+			return fmt.Errorf("invalid timeout: %w", err)
+		}
+	}
+	return nil
+}
+
+// String describes the check, e.g. "http http://localhost:8080/health", with
+// the credentials of its URL hidden
+func (h HealthCheck) String() string {
+	if h.HTTP != "" {
+		return "http " + redactURL(h.HTTP)
+	}
+	return "command " + strings.Join(h.Command, " ")
+}
+
+// runHealthCheck runs the dependency's health check, if it declares one,
+// returning a *HealthCheckError if it fails
+func (m *Manager) runHealthCheck(ctx context.Context, dep *Dependency) error {
+	if !dep.Healthcheck.isSet() {
+		return nil
+	}
+	check, err := interpolated(m, dep.Healthcheck, dep)
+	if err != nil {
+		return err
+	}
+	if err := check.validate(); err != nil {
+		return fmt.Errorf("dependency '%s': invalid healthcheck: %w", dep.Name, err)
+	}
+
+	timeout := defaultHealthCheckTimeout
+	if d, err := parseTimeout(check.Timeout); err == nil && d > 0 {
+		timeout = d
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var output string
+	if check.HTTP != "" {
+		output, err = check.probeHTTP(ctx, m, dep)
+	} else {
+		output, err = check.runCommand(ctx)
+	}
+	if err == nil && check.Output != "" && !regexp.MustCompile(check.Output).MatchString(output) {
+		err = fmt.Errorf("output does not match %q", check.Output)
+	}
+	if err != nil {
+		return &HealthCheckError{Dependency: dep.Name, Check: check.String(), Err: err}
+	}
+
+	m.logger.Debugf("Health check of %s passed", dep.Name)
+	return nil
+}
+
+// runCommand runs the check's command and compares its exit code
+func (h HealthCheck) runCommand(ctx context.Context) (string, error) {
+	output, err := runCommand(ctx, h.Command[0], h.Command[1:]...)
+	code := 0
+	if err != nil {
+		if code = exitCode(err); code < 0 {
+			return output, err
+		}
+	}
+	if code != h.ExitCode {
+		return output, fmt.Errorf("exit code %d, expected %d: %s", code, h.ExitCode, output)
+	}
+	return output, nil
+}
+
+// maxHealthCheckBody caps how much of a response body is matched against Output
+const maxHealthCheckBody = 1 << 20
+
+// probeHTTP requests the check's URL, with its ${VAR} references resolved
+// only now, and compares the response status
+func (h HealthCheck) probeHTTP(ctx context.Context, m *Manager, dep *Dependency) (string, error) {
+	target, err := m.expandURL(h.HTTP, dep)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		// The error would show the expanded URL
+		return "", fmt.Errorf("invalid http URL '%s'", redactURL(h.HTTP))
+	}
+	resp, err := m.client().Do(req)
+	if err != nil {
+		return "", redactURLError(err)
+	}
+	defer resp.Body.Close()
+
+	var body strings.Builder
+	if h.Output != "" {
+		if _, err := io.Copy(&body, io.LimitReader(resp.Body, maxHealthCheckBody)); err != nil {
+			return "", fmt.Errorf("failed to read response: %w", err)
+		}
+	}
+
+	if h.Status != 0 && resp.StatusCode != h.Status {
+		return body.String(), fmt.Errorf("status %s, expected %d", resp.Status, h.Status)
+	}
+	if h.Status == 0 && resp.StatusCode >= http.StatusBadRequest {
+		return body.String(), fmt.Errorf("status %s", resp.Status)
+	}
+	return body.String(), nil
+}
@@ -0,0 +1,139 @@
+package depman
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/devnadeemashraf/depman/internal/environment"
+)
+
+func TestHealthCheckValidate(t *testing.T) {
+	testCases := []struct {
+		name  string
+		check HealthCheck
+		valid bool
+	}{
+		{"Command", HealthCheck{Command: []string{"docker", "info"}, ExitCode: 0, Output: "Server Version"}, true},
+		{"HTTP", HealthCheck{HTTP: "http://localhost:8080/health", Status: 204, Timeout: "2s"}, true},
+		{"Interpolated URL", HealthCheck{HTTP: "${TOOL_URL}/health"}, true},
+		{"No check", HealthCheck{}, false},
+		{"Both checks", HealthCheck{Command: []string{"tool"}, HTTP: "http://localhost"}, false},
+		{"Status on a command", HealthCheck{Command: []string{"tool"}, Status: 200}, false},
+		{"Exit code on HTTP", HealthCheck{HTTP: "http://localhost", ExitCode: 1}, false},
+		{"Invalid pattern", HealthCheck{Command: []string{"tool"}, Output: "("}, false},
+		{"Invalid timeout", HealthCheck{Command: []string{"tool"}, Timeout: "soon"}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.check.validate(); (err == nil) != tc.valid {
+				t.Errorf("Expected valid to be %v but got error: %v", tc.valid, err)
+			}
+		})
+	}
+}
+
+func TestRunHealthCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ready" || (r.URL.Path == "/secure" && r.URL.Query().Get("token") == "s3cret") {
+			w.Write([]byte(`{"status": "ok"}`))
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	manager := &Manager{Platform: "linux", logger: &mockLogger{}}
+	testCases := []struct {
+		name     string
+		check    HealthCheck
+		expected string // Substring of the error, or "" if healthy
+	}{
+		{"No check", HealthCheck{}, ""},
+		{"Command", HealthCheck{Command: []string{"tool", "doctor"}, Output: "all good"}, ""},
+		{"Command failure", HealthCheck{Command: []string{"tool", "missing"}}, "tool failed"},
+		{"Output mismatch", HealthCheck{Command: []string{"tool", "doctor"}, Output: "^ready"}, "output does not match"},
+		{"HTTP", HealthCheck{HTTP: server.URL + "/ready", Output: `"ok"`}, ""},
+		{"HTTP error status", HealthCheck{HTTP: server.URL + "/live"}, "503"},
+		{"Unexpected status", HealthCheck{HTTP: server.URL + "/ready", Status: http.StatusNoContent}, "expected 204"},
+		{"URL credentials", HealthCheck{HTTP: strings.Replace(server.URL, "://", "://admin:s3cret@", 1) + "/live"}, "admin:xxxxx@"},
+		{"URL credentials from the environment", HealthCheck{HTTP: server.URL + "/live?token=${DEPMAN_TEST_TOKEN}"}, "token=xxxxx"},
+		{"URL expanded for the request", HealthCheck{HTTP: server.URL + "/secure?token=${DEPMAN_TEST_TOKEN}"}, ""},
+	}
+
+	t.Setenv("DEPMAN_TEST_TOKEN", "s3cret")
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeCommands(t, map[string]string{"tool doctor": "all good"})
+			dep := &Dependency{Name: "tool", Healthcheck: tc.check}
+
+			err := manager.runHealthCheck(context.Background(), dep)
+			if tc.expected == "" {
+				if err != nil {
+					t.Errorf("Expected the check to pass but got: %v", err)
+				}
+				return
+			}
+			var herr *HealthCheckError
+			if !errors.As(err, &herr) || !strings.Contains(err.Error(), tc.expected) {
+				t.Errorf("Expected a health check error containing %q but got: %v", tc.expected, err)
+			}
+			if strings.Contains(err.Error(), "s3cret") {
+				t.Errorf("Expected the credentials to be hidden but got: %v", err)
+			}
+		})
+	}
+}
+
+func TestHealthCheckExitCode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	manager := &Manager{Platform: "linux", logger: &mockLogger{}}
+	dep := &Dependency{Name: "tool", Healthcheck: HealthCheck{Command: []string{"sh", "-c", "exit 3"}, ExitCode: 3}}
+	if err := manager.runHealthCheck(context.Background(), dep); err != nil {
+		t.Errorf("Expected exit code 3 to pass but got: %v", err)
+	}
+
+	dep.Healthcheck.ExitCode = 0
+	if err := manager.runHealthCheck(context.Background(), dep); err == nil || !strings.Contains(err.Error(), "exit code 3, expected 0") {
+		t.Errorf("Expected the exit code to be reported but got: %v", err)
+	}
+}
+
+func TestInstallUnhealthy(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	manager := &Manager{
+		Platform:   "linux",
+		homeDir:    t.TempDir(),
+		logger:     &mockLogger{},
+		progress:   noProgress{},
+		envManager: environment.NewManager(),
+	}
+	dep := &Dependency{
+		Name:        "tool",
+		Version:     Version{Required: "1.0.0"},
+		Healthcheck: HealthCheck{Command: []string{"sh", "-c", "echo missing config; exit 1"}},
+		Platforms: map[string]PlatformConfig{"linux": {Commands: Commands{
+			Install: []string{"true"},
+			Verify:  []string{"sh", "-c", "echo tool 1.0.0"},
+		}}},
+	}
+
+	status, _, err := manager.installAndVerify(context.Background(), dep)
+	if err == nil || status == nil {
+		t.Fatalf("Expected the failed health check to fail the install but got %+v, %v", status, err)
+	}
+	if !status.Installed || !status.Unhealthy || status.Problem() != ProblemUnhealthy {
+		t.Errorf("Expected the dependency to be installed but unhealthy but got %+v", status)
+	}
+}
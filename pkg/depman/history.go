@@ -0,0 +1,171 @@
+package depman
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maxHistory is how many runs the state file remembers
+const maxHistory = 100
+
+// Run is the record of one check or ensure, as kept in the state file
+type Run struct {
+	ID         string        `json:"id"`              // Identifies the run; its install logs are under LogsDir/<ID>
+	Command    string        `json:"command"`         // "check" or "ensure"
+	Config     string        `json:"config"`          // Configuration file or remote reference the run used
+	ConfigHash string        `json:"config_hash"`     // Digest of the loaded configuration, to tell edits apart
+	StartedAt  time.Time     `json:"started_at"`      // When the run started
+	Duration   time.Duration `json:"duration"`        // How long the run took, in nanoseconds
+	Error      string        `json:"error,omitempty"` // Why the run failed, if it did
+	Results    []RunResult   `json:"results"`         // Outcome for each dependency, in configuration order
+}
+
+// RunResult is what a run found or did for one dependency
+type RunResult struct {
+	Name            string        `json:"name"`                       // Name of the dependency
+	Version         string        `json:"version,omitempty"`          // Version installed after the run
+	PreviousVersion string        `json:"previous_version,omitempty"` // Version the run replaced, if it changed it
+	Change          Change        `json:"change,omitempty"`           // What the run changed, or omitted if nothing
+	Problem         Problem       `json:"problem,omitempty"`          // Most severe problem left, or omitted if none
+	Error           string        `json:"error,omitempty"`            // Why checking or installing it failed
+	Duration        time.Duration `json:"duration"`                   // Time spent checking and installing it, in nanoseconds
+}
+
+// Result returns the outcome of the dependency named name, or nil if the run
+// did not include it
+func (r *Run) Result(name string) *RunResult {
+	for i := range r.Results {
+		if r.Results[i].Name == name {
+			return &r.Results[i]
+		}
+	}
+	return nil
+}
+
+// History returns the runs recorded in the state file, newest first
+func (m *Manager) History() ([]*Run, error) {
+	m.stateMu.Lock()
+	defer m.stateMu.Unlock()
+	return LoadHistory(m.StatePath())
+}
+
+// LoadHistory reads the runs recorded in a state file, newest first
+func LoadHistory(path string) ([]*Run, error) {
+	state, err := loadState(path)
+	if err != nil {
+		return nil, err
+	}
+	runs := make([]*Run, len(state.Runs))
+	for i, run := range state.Runs {
+		runs[len(runs)-1-i] = run
+	}
+	return runs, nil
+}
+
+// startRun starts a new run: install logs and the history entry of
+// everything up to the next one are filed under its ID
+func (m *Manager) startRun() {
+	m.runMu.Lock()
+	defer m.runMu.Unlock()
+	m.newRun()
+}
+
+// currentRun returns the ID of the run in progress, starting one if there is
+// none, e.g. for installs outside of Ensure
+func (m *Manager) currentRun() string {
+	m.runMu.Lock()
+	defer m.runMu.Unlock()
+	if m.runID == "" {
+		m.newRun()
+	}
+	return m.runID
+}
+
+// newRun names a run after the time it starts, resets the time spent on each
+// dependency and prunes old install logs. The caller holds runMu.
+func (m *Manager) newRun() {
+	suffix := make([]byte, 2)
+	rand.Read(suffix)
+	m.runID = time.Now().UTC().Format("20060102T150405.000Z") + "-" + hex.EncodeToString(suffix)
+	m.stepTimes = nil
+	m.pruneInstallLogs()
+}
+
+// recordStepTime adds time spent on a step to a dependency's total for the run
+func (m *Manager) recordStepTime(name string, elapsed time.Duration) {
+	m.runMu.Lock()
+	defer m.runMu.Unlock()
+	if m.stepTimes == nil {
+		m.stepTimes = make(map[string]time.Duration)
+	}
+	m.stepTimes[name] += elapsed
+}
+
+// recordRun adds the run in progress to the history in the state file,
+// forgetting the oldest runs beyond maxHistory. Failing to record it only
+// logs a warning.
+func (m *Manager) recordRun(command string, start time.Time, result *Result, runErr error) {
+	run := &Run{
+		ID:         m.currentRun(),
+		Command:    command,
+		Config:     m.configIdentity(),
+		ConfigHash: configDigest(m.Config),
+		StartedAt:  start.UTC(),
+		Duration:   time.Since(start),
+		Results:    []RunResult{},
+	}
+	if runErr != nil {
+		run.Error = runErr.Error()
+	}
+	if result != nil {
+		m.runMu.Lock()
+		for _, status := range result.Statuses {
+			entry := RunResult{
+				Name:            status.Name,
+				Version:         status.CurrentVersion,
+				PreviousVersion: status.PreviousVersion,
+				Change:          status.Change,
+				Problem:         status.Problem(),
+				Duration:        m.stepTimes[status.Name],
+			}
+			if status.Error != nil {
+				entry.Error = status.Error.Error()
+			}
+			run.Results = append(run.Results, entry)
+		}
+		m.runMu.Unlock()
+	}
+
+	m.stateMu.Lock()
+	defer m.stateMu.Unlock()
+	path := m.StatePath()
+	state, err := loadState(path)
+	if err == nil {
+		state.Runs = append(state.Runs, run)
+		if len(state.Runs) > maxHistory {
+			state.Runs = state.Runs[len(state.Runs)-maxHistory:]
+		}
+		err = state.save(path)
+	}
+	if err != nil {
+		m.logger.Warnf("Failed to record the run in the history: %v", err)
+	}
+}
+
+// configDigest identifies the content of a loaded configuration
+func configDigest(config *DependencyConfig) string {
+	if config == nil {
+		return ""
+	}
+	// yaml.Marshal sorts map keys, so the encoding is deterministic
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,60 @@
+package depman
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHistory(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "deps.yml")
+	os.WriteFile(configPath, []byte(`version: "1.0"
+dependencies:
+  - name: present
+    version:
+      constraint: ">=1.0"
+    platforms:
+      linux:
+        commands:
+          verify: ["sh", "-c", "echo 1.5.0"]
+  - name: missing
+    version:
+      required: "1.0.0"
+    platforms:
+      linux:
+        commands:
+          verify: ["depman-test-missing", "--version"]
+`), 0644)
+
+	manager, err := NewManager(configPath, WithPlatform("linux/amd64"), WithHomeDir(filepath.Join(dir, "home")), WithSettings(Settings{}), WithLogOutput(io.Discard))
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := manager.Check(context.Background()); err != nil {
+			t.Fatalf("Did not expect an error but got: %v", err)
+		}
+	}
+
+	runs, err := manager.History()
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if len(runs) != 2 || runs[0].ID == runs[1].ID || runs[0].StartedAt.Before(runs[1].StartedAt) {
+		t.Fatalf("Expected two runs, newest first, but got %+v", runs)
+	}
+	run := runs[0]
+	if run.Command != "check" || run.ConfigHash != configDigest(manager.Config) || run.ConfigHash != runs[1].ConfigHash {
+		t.Errorf("Unexpected run: %+v", run)
+	}
+	if present := run.Result("present"); present == nil || present.Version != "1.5.0" || present.Problem != ProblemNone || present.Duration <= 0 {
+		t.Errorf("Expected present to be recorded as installed but got %+v", present)
+	}
+	if missing := run.Result("missing"); missing == nil || missing.Problem != ProblemMissing {
+		t.Errorf("Expected missing to be recorded as missing but got %+v", missing)
+	}
+}
@@ -0,0 +1,152 @@
+package depman
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Hooks are shell commands run at points in the dependency lifecycle. On a
+// dependency they run for that dependency only; at the top level of the
+// configuration pre_check and post_ensure run once per run, and pre_install
+// and post_install run around every install.
+type Hooks struct {
+	PreCheck    []string `yaml:"pre_check"`    // Before the installed version is checked
+	PreInstall  []string `yaml:"pre_install"`  // Before the dependency is installed or upgraded
+	PostInstall []string `yaml:"post_install"` // After a successful install, before re-verification
+	PostEnsure  []string `yaml:"post_ensure"`  // After every dependency is in place (top level only)
+}
+
+// Hook stages, exported to hook commands as DEPMAN_HOOK
+const (
+	hookPreCheck    = "pre_check"
+	hookPreInstall  = "pre_install"
+	hookPostInstall = "post_install"
+	hookPostEnsure  = "post_ensure"
+)
+
+// hookShell returns the shell used to run a hook command line
+func hookShell(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
+
+// runDependencyHooks runs the top-level and then the dependency's own hooks
+// for a stage, exporting the dependency's resolved metadata
+func (m *Manager) runDependencyHooks(ctx context.Context, stage string, dep *Dependency, status *DependencyStatus) error {
+	var commands []string
+	if m.Config != nil {
+		commands = append(commands, m.Config.Hooks.forStage(stage)...)
+	}
+	commands = append(commands, dep.Hooks.forStage(stage)...)
+	if len(commands) == 0 {
+		return nil
+	}
+
+	vars := map[string]string{
+		"DEPMAN_DEPENDENCY": dep.Name,
+		"DEPMAN_VERSION":    dep.Version.Required,
+		"DEPMAN_CONSTRAINT": dep.Version.Constraint,
+	}
+	if status != nil {
+		vars["DEPMAN_CURRENT_VERSION"] = status.CurrentVersion
+	}
+	if platformConfig, err := m.GetPlatformConfig(dep); err == nil {
+		vars["DEPMAN_METHOD"] = installMethod(platformConfig)
+		// The URL as downloaded, but without credentials
+		if url, err := m.fetchURL(platformConfig.Installer.URL, dep); err == nil {
+			vars["DEPMAN_URL"] = redactURL(url)
+		}
+	}
+
+	return m.runHooks(ctx, stage, commands, dep, vars)
+}
+
+// runRunHooks runs the top-level hooks for a once-per-run stage
+func (m *Manager) runRunHooks(ctx context.Context, stage string, installed []string) error {
+	if m.Config == nil {
+		return nil
+	}
+	commands := m.Config.Hooks.forStage(stage)
+	if len(commands) == 0 {
+		return nil
+	}
+
+	return m.runHooks(ctx, stage, commands, &Dependency{Name: m.Config.Name}, map[string]string{
+		"DEPMAN_INSTALLED": strings.Join(installed, ","),
+	})
+}
+
+// runHooks runs hook commands in order, stopping at the first failure. The
+// commands are passed to the shell unchanged, so it expands ${VAR} references,
+// including those to the DEPMAN_* variables, itself.
+func (m *Manager) runHooks(ctx context.Context, stage string, commands []string, dep *Dependency, vars map[string]string) error {
+	m.envMu.Lock()
+	env := m.envManager.GetUpdatedEnvironment()
+	m.envMu.Unlock()
+
+	env = append(env,
+		"DEPMAN_HOOK="+stage,
+		"DEPMAN_PLATFORM="+m.Platform,
+		"DEPMAN_ARCH="+m.targetArch(),
+		"DEPMAN_HOME="+m.HomeDir(),
+		"DEPMAN_BIN_DIR="+m.BinDir(),
+	)
+	for key, value := range vars {
+		env = append(env, key+"="+value)
+	}
+
+	log := m.componentLogger("hooks", dep)
+	for _, command := range commands {
+		log.Infof("Running %s hook for %s: %s", stage, dep.Name, command)
+
+		cmd := hookShell(ctx, command)
+		cmd.Env = env
+		output, err := cmd.CombinedOutput()
+		installLogOf(ctx).record(cmd.Args[0], cmd.Args[1:], output, err)
+		if len(output) > 0 {
+			log.Debugf("%s hook output: %s", stage, strings.TrimSpace(string(output)))
+		}
+		if err != nil {
+			return fmt.Errorf("%s hook for %s failed: %w, output: %s", stage, dep.Name, err, strings.TrimSpace(string(output)))
+		}
+	}
+
+	return nil
+}
+
+// forStage returns the hook commands for a stage
+func (h Hooks) forStage(stage string) []string {
+	switch stage {
+	case hookPreCheck:
+		return h.PreCheck
+	case hookPreInstall:
+		return h.PreInstall
+	case hookPostInstall:
+		return h.PostInstall
+	case hookPostEnsure:
+		return h.PostEnsure
+	}
+	return nil
+}
+
+// merge lays overlay hooks on top, replacing each stage that is set
+func (h Hooks) merge(overlay Hooks) Hooks {
+	if overlay.PreCheck != nil {
+		h.PreCheck = overlay.PreCheck
+	}
+	if overlay.PreInstall != nil {
+		h.PreInstall = overlay.PreInstall
+	}
+	if overlay.PostInstall != nil {
+		h.PostInstall = overlay.PostInstall
+	}
+	if overlay.PostEnsure != nil {
+		h.PostEnsure = overlay.PostEnsure
+	}
+	return h
+}
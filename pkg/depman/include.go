@@ -0,0 +1,332 @@
+package depman
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// localOverlaySuffix marks the optional per-checkout overlay of a configuration
+// file, e.g. app-dependencies.local.yml next to app-dependencies.yml
+const localOverlaySuffix = ".local"
+
+// loadConfigFile loads a configuration file with its includes merged
+// underneath it and its local overlay, if present, merged on top. Registry
+// recipes are merged underneath the dependencies based on them last.
+func loadConfigFile(path string) (*DependencyConfig, error) {
+	config, err := loadConfigLayer(path, map[string]bool{}, map[string]bool{})
+	if err != nil {
+		return nil, err
+	}
+
+	overlayPath := localOverlayPath(path)
+	if _, err := os.Stat(overlayPath); err == nil {
+		overlay, err := loadConfigLayer(overlayPath, dependencyNames(config), map[string]bool{})
+		if err != nil {
+			return nil, err
+		}
+		config = mergeConfigs(config, overlay)
+	}
+
+	return resolveRecipes(config)
+}
+
+// loadConfigLayer loads a single file after recursively loading its includes
+func loadConfigLayer(path string, known, visiting map[string]bool) (*DependencyConfig, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+	if visiting[absPath] {
+		return nil, fmt.Errorf("include cycle detected at %s", path)
+	}
+	visiting[absPath] = true
+	defer delete(visiting, absPath)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dependency file: %w", err)
+	}
+
+	// Read the includes first so this file is validated against what they define
+	includes, err := readIncludes(path, data)
+	if err != nil {
+		return nil, err
+	}
+
+	base := &DependencyConfig{}
+	for _, include := range includes {
+		layerKnown := dependencyNames(base)
+		for name := range known {
+			layerKnown[name] = true
+		}
+
+		layer, err := loadConfigLayer(resolveInclude(path, include), layerKnown, visiting)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load include %s: %w", include, err)
+		}
+		base = mergeConfigs(base, layer)
+	}
+
+	layerKnown := dependencyNames(base)
+	for name := range known {
+		layerKnown[name] = true
+	}
+	config, err := parseAndValidate(path, data, layerKnown)
+	if err != nil {
+		return nil, err
+	}
+
+	return mergeConfigs(base, config), nil
+}
+
+// ConfigFiles returns the files a configuration is loaded from: the file
+// itself, its includes, its local overlay and its override file. Files that do not exist yet or
+// cannot be parsed are listed too, so they can be watched for changes.
+func ConfigFiles(path string) []string {
+	var files []string
+	seen := make(map[string]bool)
+
+	var walk func(path string)
+	walk = func(path string) {
+		if absPath, err := filepath.Abs(path); err == nil {
+			path = absPath
+		}
+		if seen[path] {
+			return
+		}
+		seen[path] = true
+		files = append(files, path)
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return
+		}
+		includes, _ := readIncludes(path, data)
+		for _, include := range includes {
+			walk(resolveInclude(path, include))
+		}
+	}
+
+	walk(path)
+	walk(localOverlayPath(path))
+	walk(filepath.Join(filepath.Dir(path), OverrideFileName))
+
+	// Projects of a workspace are part of its configuration
+	if data, err := os.ReadFile(path); err == nil {
+		for _, file := range workspaceFiles(path, data) {
+			walk(file)
+			walk(localOverlayPath(file))
+		}
+	}
+	return files
+}
+
+// readIncludes returns the includes listed in configuration data
+func readIncludes(path string, data []byte) ([]string, error) {
+	converted, err := toYAML(data, DetectConfigFormat(path, data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse dependency file: %w", err)
+	}
+
+	var header struct {
+		Includes []string `yaml:"includes"`
+	}
+	if err := yaml.Unmarshal(converted, &header); err != nil {
+		return nil, fmt.Errorf("failed to parse dependency file: %w", err)
+	}
+	return header.Includes, nil
+}
+
+// resolveInclude resolves an include relative to the including file, expanding a leading ~
+func resolveInclude(from, include string) string {
+	if strings.HasPrefix(include, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, include[2:])
+		}
+	}
+	if filepath.IsAbs(include) {
+		return include
+	}
+	return filepath.Join(filepath.Dir(from), include)
+}
+
+// localOverlayPath returns the local overlay file for a configuration file
+func localOverlayPath(path string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + localOverlaySuffix + ext
+}
+
+// dependencyNames returns the set of dependency names defined in a configuration
+func dependencyNames(config *DependencyConfig) map[string]bool {
+	names := make(map[string]bool, len(config.Dependencies))
+	for _, dep := range config.Dependencies {
+		names[dep.Name] = true
+	}
+	return names
+}
+
+// mergeConfigs lays overlay on top of base. Top-level fields set in the
+// overlay win. Dependencies are matched by name: new ones are appended in
+// overlay order and existing ones are merged with mergeDependency. Profiles
+// of the same name are merged the same way.
+func mergeConfigs(base, overlay *DependencyConfig) *DependencyConfig {
+	merged := *base
+
+	if overlay.Version != "" {
+		merged.Version = overlay.Version
+	}
+	if overlay.Name != "" {
+		merged.Name = overlay.Name
+	}
+	if overlay.Description != "" {
+		merged.Description = overlay.Description
+	}
+	if overlay.Scope != "" {
+		merged.Scope = overlay.Scope
+	}
+	if overlay.RequiresDepman != "" {
+		merged.RequiresDepman = overlay.RequiresDepman
+	}
+	for _, feature := range overlay.Features {
+		if !slices.Contains(merged.Features, feature) {
+			merged.Features = append(append([]string(nil), merged.Features...), feature)
+		}
+	}
+	merged.Includes = overlay.Includes
+	if len(overlay.Workspace.Projects) > 0 {
+		merged.Workspace = overlay.Workspace
+	}
+	merged.Hooks = base.Hooks.merge(overlay.Hooks)
+	merged.Credentials = mergeCredentials(base.Credentials, overlay.Credentials)
+	merged.Mirrors = append(append([]Mirror(nil), overlay.Mirrors...), base.Mirrors...)
+	merged.Policy = base.Policy.merge(overlay.Policy)
+	merged.Webhooks = append(append([]Webhook(nil), base.Webhooks...), overlay.Webhooks...)
+	merged.Profiles = mergeProfiles(base.Profiles, overlay.Profiles)
+	merged.Tasks = mergeTasks(base.Tasks, overlay.Tasks)
+	merged.Dependencies = mergeDependencies(base.Dependencies, overlay.Dependencies)
+
+	return &merged
+}
+
+// mergeDependencies merges overlay dependencies into base ones of the same
+// name with mergeDependency and appends the rest in overlay order
+func mergeDependencies(base, overlay []Dependency) []Dependency {
+	merged := append([]Dependency(nil), base...)
+
+	index := make(map[string]int, len(merged))
+	for i, dep := range merged {
+		index[dep.Name] = i
+	}
+
+	for _, dep := range overlay {
+		if i, ok := index[dep.Name]; ok {
+			merged[i] = mergeDependency(merged[i], dep)
+			continue
+		}
+		index[dep.Name] = len(merged)
+		merged = append(merged, dep)
+	}
+
+	return merged
+}
+
+// mergeCredentials replaces base credential entries with overlay entries for
+// the same host and appends the rest
+func mergeCredentials(base, overlay []Credential) []Credential {
+	merged := append([]Credential(nil), base...)
+	for _, cred := range overlay {
+		replaced := false
+		for i := range merged {
+			if strings.EqualFold(merged[i].Host, cred.Host) {
+				merged[i], replaced = cred, true
+			}
+		}
+		if !replaced {
+			merged = append(merged, cred)
+		}
+	}
+	return merged
+}
+
+// mergeDependency lays an overriding definition of a dependency on top of the
+// base one. Scalar fields, the version and audit blocks and each hook stage are replaced when set,
+// platform entries are replaced per platform, environment paths are appended,
+// environment variables are merged by key and the dependency and tag lists
+// are replaced when set.
+func mergeDependency(base, overlay Dependency) Dependency {
+	merged := base
+
+	if overlay.From != "" {
+		merged.From = overlay.From
+	}
+	if overlay.Description != "" {
+		merged.Description = overlay.Description
+	}
+	if overlay.Version != (Version{}) {
+		merged.Version = overlay.Version
+	}
+	if len(overlay.VersionCommand) > 0 {
+		merged.VersionCommand = overlay.VersionCommand
+	}
+	if overlay.VersionRegex != "" {
+		merged.VersionRegex = overlay.VersionRegex
+	}
+	if overlay.Timeout != "" {
+		merged.Timeout = overlay.Timeout
+	}
+	if overlay.License != "" {
+		merged.License = overlay.License
+	}
+	if overlay.WSL != "" {
+		merged.WSL = overlay.WSL
+	}
+	if overlay.When != "" {
+		merged.When = overlay.When
+	}
+	if overlay.Audit != (AuditConfig{}) {
+		merged.Audit = overlay.Audit
+	}
+	if overlay.Dependencies != nil {
+		merged.Dependencies = overlay.Dependencies
+	}
+	if overlay.Tags != nil {
+		merged.Tags = overlay.Tags
+	}
+	if overlay.Provides != nil {
+		merged.Provides = overlay.Provides
+	}
+	if overlay.ConflictsWith != nil {
+		merged.ConflictsWith = overlay.ConflictsWith
+	}
+	merged.Hooks = base.Hooks.merge(overlay.Hooks)
+
+	if len(overlay.Platforms) > 0 {
+		merged.Platforms = make(map[string]PlatformConfig, len(base.Platforms)+len(overlay.Platforms))
+		for platform, config := range base.Platforms {
+			merged.Platforms[platform] = config
+		}
+		for platform, config := range overlay.Platforms {
+			merged.Platforms[platform] = config
+		}
+	}
+
+	if len(overlay.Environment.Path) > 0 {
+		merged.Environment.Path = append(append([]string(nil), base.Environment.Path...), overlay.Environment.Path...)
+	}
+	if len(overlay.Environment.Variables) > 0 {
+		merged.Environment.Variables = make(map[string]string, len(base.Environment.Variables)+len(overlay.Environment.Variables))
+		for key, value := range base.Environment.Variables {
+			merged.Environment.Variables[key] = value
+		}
+		for key, value := range overlay.Environment.Variables {
+			merged.Environment.Variables[key] = value
+		}
+	}
+
+	return merged
+}
@@ -0,0 +1,156 @@
+package depman
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadDependencyConfigIncludes(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "depman-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	files := map[string]string{
+		"shared/base.yml": `
+version: "1.0"
+name: "Shared"
+dependencies:
+  - name: "node"
+    version:
+      required: "18.0.0"
+    platforms:
+      linux:
+        installer:
+          method: "system"
+    environment:
+      path: ["/opt/node/bin"]
+  - name: "jq"
+    version:
+      required: "1.6.0"
+    platforms:
+      linux:
+        installer:
+          method: "system"
+`,
+		"app-dependencies.yml": `
+name: "Project"
+includes: ["shared/base.yml"]
+dependencies:
+  - name: "node"
+    version:
+      required: "20.1.0"
+  - name: "go"
+    version:
+      constraint: "^1.22"
+    platforms:
+      linux:
+        installer:
+          method: "archive"
+          url: "https://example.com/go.tar.gz"
+`,
+		"app-dependencies.local.yml": `
+dependencies:
+  - name: "node"
+    environment:
+      variables:
+        NODE_ENV: "development"
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(tempDir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	config, err := LoadDependencyConfig(filepath.Join(tempDir, "app-dependencies.yml"))
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+
+	if config.Name != "Project" || config.Version != "1.0" {
+		t.Errorf("Expected top-level fields to be overlaid but got name=%s version=%s", config.Name, config.Version)
+	}
+
+	var names []string
+	for _, dep := range config.Dependencies {
+		names = append(names, dep.Name)
+	}
+	if len(names) != 3 || names[0] != "node" || names[1] != "jq" || names[2] != "go" {
+		t.Fatalf("Expected dependencies [node jq go] but got %v", names)
+	}
+
+	node := config.Dependencies[0]
+	if node.Version.Required != "20.1.0" {
+		t.Errorf("Expected the overridden version 20.1.0 but got %s", node.Version.Required)
+	}
+	if node.Platforms["linux"].Installer.Method != "system" {
+		t.Errorf("Expected platforms to be inherited from the include")
+	}
+	if len(node.Environment.Path) != 1 || node.Environment.Variables["NODE_ENV"] != "development" {
+		t.Errorf("Expected the local overlay to add environment variables but got %+v", node.Environment)
+	}
+
+	t.Run("Include cycle", func(t *testing.T) {
+		a := filepath.Join(tempDir, "a.yml")
+		b := filepath.Join(tempDir, "b.yml")
+		os.WriteFile(a, []byte("includes: [\"b.yml\"]\n"), 0644)
+		os.WriteFile(b, []byte("includes: [\"a.yml\"]\n"), 0644)
+
+		if _, err := LoadDependencyConfig(a); err == nil {
+			t.Errorf("Expected an error but got none")
+		}
+	})
+
+	t.Run("Override of unknown dependency must be complete", func(t *testing.T) {
+		path := filepath.Join(tempDir, "partial.yml")
+		os.WriteFile(path, []byte("dependencies:\n  - name: \"ruby\"\n    version:\n      required: \"3.3.0\"\n"), 0644)
+
+		if _, err := LoadDependencyConfig(path); err == nil {
+			t.Errorf("Expected an error but got none")
+		}
+	})
+}
+
+func TestConfigFiles(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "depman-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	files := map[string]string{
+		"app-dependencies.yml": `includes: ["shared/base.yml", "missing.yml"]`,
+		"shared/base.yml":      `includes: ["../app-dependencies.yml", "tools.yml"]`,
+		"shared/tools.yml":     `dependencies: []`,
+	}
+	for name, content := range files {
+		path := filepath.Join(tempDir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	got := ConfigFiles(filepath.Join(tempDir, "app-dependencies.yml"))
+	expected := []string{
+		filepath.Join(tempDir, "app-dependencies.yml"),
+		filepath.Join(tempDir, "shared", "base.yml"),
+		filepath.Join(tempDir, "shared", "tools.yml"),
+		filepath.Join(tempDir, "missing.yml"),
+		filepath.Join(tempDir, "app-dependencies.local.yml"),
+		filepath.Join(tempDir, OverrideFileName),
+	}
+	if len(got) != len(expected) {
+		t.Fatalf("Expected files %v but got %v", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("Expected file %d to be %s but got %s", i, expected[i], got[i])
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package depman
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/devnadeemashraf/depman/internal/archive"
+)
+
+// archiveInstaller downloads a tarball, zip or plain binary, verifies its
+// checksum, keeps the named binary in a per-version tools directory and
+// points a shim in the managed bin directory at it
+type archiveInstaller struct{}
+
+func init() {
+	installStrategies["archive"] = archiveInstaller{}
+}
+
+// install downloads and extracts the archive and installs the binary
+func (archiveInstaller) install(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) (LockedArtifact, error) {
+	if platformConfig.Installer.URL == "" {
+		return LockedArtifact{}, fmt.Errorf("no download URL provided for dependency: %s", dep.Name)
+	}
+
+	url := expandURLTemplate(platformConfig.Installer.URL, dep, m.Platform, m.targetArch())
+	return m.installArchive(ctx, dep, platformConfig, url, platformConfig.Installer.Checksum, dep.Version.Required)
+}
+
+// installArchive downloads an archive or plain binary from url, installs the
+// dependency's binary from it as the given version and makes it the active one
+func (m *Manager) installArchive(ctx context.Context, dep *Dependency, platformConfig *PlatformConfig, url, checksum, version string) (LockedArtifact, error) {
+	var artifact LockedArtifact
+
+	tempDir, err := os.MkdirTemp("", "depman-download-*")
+	if err != nil {
+		return artifact, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	result, err := m.downloadArtifact(ctx, dep, platformConfig, url, checksum, tempDir)
+	if err != nil {
+		return artifact, err
+	}
+	artifact.URL = url
+	artifact.Checksum = result.Checksum
+
+	binary := archiveBinaryName(dep, platformConfig, m.Platform)
+
+	// Plain binaries are installed as-is, archives are unpacked first
+	source := result.FilePath
+	if archive.DetectFormat(result.FilePath) != archive.FormatNone {
+		extractDir := filepath.Join(tempDir, "extracted")
+		if err := archive.Extract(result.FilePath, extractDir); err != nil {
+			return artifact, fmt.Errorf("failed to extract dependency: %w", err)
+		}
+		if source, err = archive.FindFile(extractDir, binary); err != nil {
+			return artifact, err
+		}
+	}
+
+	version = toolVersion(version)
+	target := filepath.Join(m.toolVersionDir(dep.Name, version), binary)
+	if err := installBinary(source, target); err != nil {
+		return artifact, err
+	}
+
+	shim, err := m.writeShim(dep, binary, version)
+	if err != nil {
+		return artifact, err
+	}
+	m.trackFiles(dep.Name, target, shim)
+
+	m.logger.Infof("Successfully installed %s %s to %s (shim: %s)", dep.Name, version, target, shim)
+	return artifact, nil
+}
+
+// concurrent reports that archive installs only touch their own files
+func (archiveInstaller) concurrent() bool {
+	return true
+}
+
+// binaryName returns the name of the binary kept for each installed version
+func (archiveInstaller) binaryName(m *Manager, dep *Dependency, platformConfig *PlatformConfig) string {
+	return archiveBinaryName(dep, platformConfig, m.Platform)
+}
+
+// uninstall deletes the shim and every installed version of the tool
+func (archiveInstaller) uninstall(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) error {
+	return m.removeTool(dep, archiveBinaryName(dep, platformConfig, m.Platform))
+}
+
+// detectVersion runs the tool's shim with --version, preferring a project-scoped install
+func (archiveInstaller) detectVersion(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) (string, error) {
+	target := m.findShim(archiveBinaryName(dep, platformConfig, m.Platform))
+	if _, err := os.Stat(target); err != nil {
+		return "", fmt.Errorf("%s is not installed in %s", dep.Name, m.BinDir())
+	}
+	return runCommand(ctx, target, "--version")
+}
+
+// archiveBinaryName returns the binary to install, adding .exe on Windows
+func archiveBinaryName(dep *Dependency, platformConfig *PlatformConfig, platform string) string {
+	binary := platformConfig.Installer.Binary
+	if binary == "" {
+		binary = dep.Name
+	}
+	if platform == "windows" && filepath.Ext(binary) == "" {
+		binary += ".exe"
+	}
+	return binary
+}
+
+// expandURLTemplate substitutes {{version}}, {{os}} and {{arch}} placeholders in a download URL
+func expandURLTemplate(url string, dep *Dependency, platform, arch string) string {
+	replacer := strings.NewReplacer(
+		"{{version}}", strings.TrimPrefix(dep.Version.Required, "v"),
+		"{{os}}", platform,
+		"{{arch}}", arch,
+	)
+	return replacer.Replace(url)
+}
+
+// installBinary copies a file into place and marks it executable
+func installBinary(source, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", target, err)
+	}
+
+	in, err := os.Open(source)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", source, err)
+	}
+	defer in.Close()
+
+	// Write to a temporary file first so a running binary is replaced atomically
+	tmp := target + ".tmp"
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0755)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", tmp, err)
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write %s: %w", target, err)
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write %s: %w", target, err)
+	}
+
+	if err := os.Rename(tmp, target); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to install %s: %w", target, err)
+	}
+
+	return os.Chmod(target, 0755)
+}
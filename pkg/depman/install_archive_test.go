@@ -0,0 +1,233 @@
+package depman
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestExpandURLTemplate(t *testing.T) {
+	dep := &Dependency{Name: "tool", Version: Version{Required: "v1.2.3"}}
+
+	got := expandURLTemplate("https://example.com/{{version}}/tool_{{os}}_{{arch}}.tar.gz", dep, "linux", "arm64")
+	expected := "https://example.com/1.2.3/tool_linux_arm64.tar.gz"
+	if got != expected {
+		t.Errorf("Expected %s but got %s", expected, got)
+	}
+}
+
+func TestArchiveInstaller(t *testing.T) {
+	// Build a tarball containing the binary in a nested directory
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	content := []byte("#!/bin/sh\necho tool 1.2.3\n")
+	tw.WriteHeader(&tar.Header{Name: "tool-1.2.3/bin/tool", Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+	tw.Write(content)
+	tw.Close()
+	gz.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(buf.Bytes())
+	}))
+	defer server.Close()
+
+	homeDir, err := os.MkdirTemp("", "depman-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(homeDir)
+
+	dep := &Dependency{Name: "tool", Version: Version{Required: "1.2.3"}}
+	platformConfig := &PlatformConfig{Installer: Installer{
+		Method: "archive",
+		URL:    server.URL + "/tool-{{version}}.tar.gz",
+	}}
+	manager := &Manager{Platform: "linux", homeDir: homeDir, logger: &mockLogger{}}
+
+	artifact, err := (archiveInstaller{}).install(context.Background(), manager, dep, platformConfig)
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if artifact.URL != server.URL+"/tool-1.2.3.tar.gz" {
+		t.Errorf("Expected the expanded URL to be recorded but got %s", artifact.URL)
+	}
+
+	installed := filepath.Join(manager.ToolsDir(), "tool", "1.2.3", "tool")
+	if _, err := os.Stat(installed); err != nil {
+		t.Errorf("Expected versioned binary at %s: %v", installed, err)
+	}
+
+	target := filepath.Join(manager.BinDir(), "tool")
+	info, err := os.Stat(target)
+	if err != nil {
+		t.Fatalf("Expected binary at %s: %v", target, err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0111 == 0 {
+		t.Errorf("Expected binary to be executable but mode is %v", info.Mode())
+	}
+	if got := manager.resolveExecutable("tool"); got != target {
+		t.Errorf("Expected managed binary to be preferred but got %s", got)
+	}
+
+	if err := (archiveInstaller{}).uninstall(context.Background(), manager, dep, platformConfig); err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if _, err := os.Stat(target); !os.IsNotExist(err) {
+		t.Errorf("Expected shim to be removed")
+	}
+	if _, err := os.Stat(installed); !os.IsNotExist(err) {
+		t.Errorf("Expected versioned binary to be removed")
+	}
+
+	t.Run("Checksum mismatch", func(t *testing.T) {
+		platformConfig.Installer.Checksum = "sha256:0000"
+		if _, err := (archiveInstaller{}).install(context.Background(), manager, dep, platformConfig); err == nil {
+			t.Errorf("Expected an error but got none")
+		}
+	})
+}
+
+func TestArchiveInstallerVerification(t *testing.T) {
+	content := []byte("#!/bin/sh\necho tool 1.2.3\n")
+	sum := sha256.Sum256(content)
+
+	checksums := hex.EncodeToString(sum[:]) + "  tool\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/SHA256SUMS":
+			w.Write([]byte(checksums))
+		default:
+			w.Write(content)
+		}
+	}))
+	defer server.Close()
+
+	homeDir, err := os.MkdirTemp("", "depman-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(homeDir)
+
+	dep := &Dependency{Name: "tool", Version: Version{Required: "1.2.3"}}
+	platformConfig := &PlatformConfig{Installer: Installer{
+		Method:      "archive",
+		URL:         server.URL + "/tool",
+		ChecksumURL: server.URL + "/SHA256SUMS",
+	}}
+	manager := &Manager{Platform: "linux", homeDir: homeDir, logger: &mockLogger{}}
+
+	artifact, err := (archiveInstaller{}).install(context.Background(), manager, dep, platformConfig)
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if expected := "sha256:" + hex.EncodeToString(sum[:]); artifact.Checksum != expected {
+		t.Errorf("Expected checksum %s but got %s", expected, artifact.Checksum)
+	}
+
+	t.Run("Checksum mismatch", func(t *testing.T) {
+		checksums = strings.Repeat("0", 64) + "  tool\n"
+
+		_, err := (archiveInstaller{}).install(context.Background(), manager, dep, platformConfig)
+		var verr *VerificationError
+		if !errors.As(err, &verr) {
+			t.Fatalf("Expected a VerificationError but got %v", err)
+		}
+		if verr.Kind != "checksum" {
+			t.Errorf("Expected a checksum failure but got %s", verr.Kind)
+		}
+
+		status := &DependencyStatus{Name: dep.Name, Error: err}
+		if !status.VerificationFailed() {
+			t.Errorf("Expected status to report a verification failure")
+		}
+	})
+}
+
+func TestArchiveInstallerCache(t *testing.T) {
+	content := []byte("#!/bin/sh\necho tool 1.2.3\n")
+	sum := sha256.Sum256(content)
+	checksum := "sha256:" + hex.EncodeToString(sum[:])
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write(content)
+	}))
+	defer server.Close()
+
+	homeDir, err := os.MkdirTemp("", "depman-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(homeDir)
+
+	dep := &Dependency{Name: "tool", Version: Version{Required: "1.2.3"}}
+	manager := &Manager{Platform: "linux", homeDir: homeDir, logger: &mockLogger{}}
+
+	t.Run("Known checksum is served from the cache", func(t *testing.T) {
+		platformConfig := &PlatformConfig{Installer: Installer{Method: "archive", URL: server.URL + "/tool", Checksum: checksum}}
+		for i := 0; i < 2; i++ {
+			if _, err := (archiveInstaller{}).install(context.Background(), manager, dep, platformConfig); err != nil {
+				t.Fatalf("Did not expect an error but got: %v", err)
+			}
+		}
+		if requests != 1 {
+			t.Errorf("Expected 1 request but got %d", requests)
+		}
+	})
+
+	t.Run("Unknown checksum is downloaded again", func(t *testing.T) {
+		requests = 0
+		platformConfig := &PlatformConfig{Installer: Installer{Method: "archive", URL: server.URL + "/tool"}}
+		if _, err := (archiveInstaller{}).install(context.Background(), manager, dep, platformConfig); err != nil {
+			t.Fatalf("Did not expect an error but got: %v", err)
+		}
+		if requests != 1 {
+			t.Errorf("Expected 1 request but got %d", requests)
+		}
+	})
+	t.Run("Unchanged latest download is revalidated", func(t *testing.T) {
+		etag := `"v1"`
+		downloads, notModified := 0, 0
+		latest := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("If-None-Match") == etag {
+				notModified++
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			downloads++
+			w.Header().Set("ETag", etag)
+			w.Write([]byte("#!/bin/sh\necho tool " + etag + "\n"))
+		}))
+		defer latest.Close()
+
+		platformConfig := &PlatformConfig{Installer: Installer{Method: "archive", URL: latest.URL + "/latest/tool"}}
+		var checksums []string
+		for _, version := range []string{`"v1"`, `"v1"`, `"v2"`} {
+			etag = version
+			artifact, err := (archiveInstaller{}).install(context.Background(), manager, dep, platformConfig)
+			if err != nil {
+				t.Fatalf("Did not expect an error but got: %v", err)
+			}
+			checksums = append(checksums, artifact.Checksum)
+		}
+		if downloads != 2 || notModified != 1 {
+			t.Errorf("Expected 2 downloads and 1 revalidation but got %d and %d", downloads, notModified)
+		}
+		if checksums[0] != checksums[1] || checksums[1] == checksums[2] {
+			t.Errorf("Expected the revalidated artifact to keep its checksum and the changed one to get a new one but got %v", checksums)
+		}
+	})
+}
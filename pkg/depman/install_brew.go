@@ -0,0 +1,222 @@
+package depman
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// brewLocations are the standard Homebrew prefixes checked when brew is not on PATH
+var brewLocations = []string{
+	"/opt/homebrew/bin/brew",              // Apple Silicon
+	"/usr/local/bin/brew",                 // Intel macOS
+	"/home/linuxbrew/.linuxbrew/bin/brew", // Linuxbrew
+}
+
+// brewInstaller installs formulae and casks with Homebrew on macOS and Linux
+type brewInstaller struct{}
+
+func init() {
+	installStrategies["brew"] = brewInstaller{}
+}
+
+// install installs the formula, or upgrades it if an older version is present
+func (b brewInstaller) install(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) (LockedArtifact, error) {
+	var artifact LockedArtifact
+
+	brew, err := findBrew()
+	if err != nil {
+		return artifact, err
+	}
+
+	formula := brewFormula(dep, platformConfig)
+
+	// Upgrade if the formula is already present, install otherwise
+	verb := "install"
+	if _, err := b.detectVersion(ctx, m, dep, platformConfig); err == nil {
+		verb = "upgrade"
+		if platformConfig.Installer.Pin {
+			// Pinned formulae are skipped by upgrade, so release the pin first
+			runCommand(ctx, brew, "unpin", formula)
+		}
+	}
+
+	m.logger.Infof("Running brew %s %s", verb, formula)
+	if _, err := m.retryCommand(ctx, dep, brew, brewArgs(verb, platformConfig, formula)...); err != nil {
+		return artifact, fmt.Errorf("installation failed: %w", err)
+	}
+
+	if platformConfig.Installer.Pin && !platformConfig.Installer.Cask {
+		if _, err := runCommand(ctx, brew, "pin", formula); err != nil {
+			m.logger.Warnf("Failed to pin %s: %v", formula, err)
+		}
+	}
+
+	m.logger.Infof("Successfully installed %s", dep.Name)
+	artifact.URL = "brew:" + formula
+	return artifact, nil
+}
+
+// uninstall removes the formula
+func (brewInstaller) uninstall(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) error {
+	brew, err := findBrew()
+	if err != nil {
+		return err
+	}
+
+	formula := brewFormula(dep, platformConfig)
+	if platformConfig.Installer.Pin && !platformConfig.Installer.Cask {
+		runCommand(ctx, brew, "unpin", formula)
+	}
+
+	m.logger.Infof("Running brew uninstall %s", formula)
+	if _, err := runCommand(ctx, brew, brewArgs("uninstall", platformConfig, formula)...); err != nil {
+		return fmt.Errorf("uninstallation failed: %w", err)
+	}
+
+	return nil
+}
+
+// detectVersion parses `brew list --versions` for the installed version
+func (brewInstaller) detectVersion(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) (string, error) {
+	brew, err := findBrew()
+	if err != nil {
+		return "", err
+	}
+
+	formula := brewFormula(dep, platformConfig)
+	output, err := runCommand(ctx, brew, brewArgs("list", platformConfig, "--versions", formula)...)
+	if err != nil {
+		return "", fmt.Errorf("%s is not installed: %w", formula, err)
+	}
+
+	version := parseBrewVersions(output)
+	if version == "" {
+		return "", fmt.Errorf("%s is not installed", formula)
+	}
+
+	return version, nil
+}
+
+// availableVersions reads the current stable version from `brew info`
+func (brewInstaller) availableVersions(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) ([]string, error) {
+	brew, err := findBrew()
+	if err != nil {
+		return nil, err
+	}
+
+	formula := brewFormula(dep, platformConfig)
+	output, err := runCommand(ctx, brew, brewArgs("info", platformConfig, "--json=v2", formula)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up %s: %w", formula, err)
+	}
+
+	var info struct {
+		Formulae []struct {
+			Versions struct {
+				Stable string `json:"stable"`
+			} `json:"versions"`
+		} `json:"formulae"`
+		Casks []struct {
+			Version string `json:"version"`
+		} `json:"casks"`
+	}
+	if err := json.Unmarshal([]byte(output), &info); err != nil {
+		return nil, fmt.Errorf("failed to parse brew info for %s: %w", formula, err)
+	}
+
+	var versions []string
+	for _, f := range info.Formulae {
+		versions = append(versions, f.Versions.Stable)
+	}
+	for _, c := range info.Casks {
+		versions = append(versions, strings.SplitN(c.Version, ",", 2)[0])
+	}
+	return versions, nil
+}
+
+// detectLicense reads the formula's license from `brew info`; casks do not declare one
+func (brewInstaller) detectLicense(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) (string, error) {
+	if platformConfig.Installer.Cask {
+		return "", nil
+	}
+	brew, err := findBrew()
+	if err != nil {
+		return "", err
+	}
+
+	formula := brewFormula(dep, platformConfig)
+	output, err := runCommand(ctx, brew, brewArgs("info", platformConfig, "--json=v2", formula)...)
+	if err != nil {
+		return "", fmt.Errorf("failed to look up %s: %w", formula, err)
+	}
+
+	var info struct {
+		Formulae []struct {
+			License string `json:"license"`
+		} `json:"formulae"`
+	}
+	if err := json.Unmarshal([]byte(output), &info); err != nil {
+		return "", fmt.Errorf("failed to parse brew info for %s: %w", formula, err)
+	}
+	if len(info.Formulae) == 0 {
+		return "", nil
+	}
+	return info.Formulae[0].License, nil
+}
+
+// findBrew locates the brew executable
+func findBrew() (string, error) {
+	if path, err := lookPath("brew"); err == nil {
+		return path, nil
+	}
+
+	for _, path := range brewLocations {
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+
+	return "", fmt.Errorf("homebrew is not installed")
+}
+
+// brewFormula returns the formula to install, defaulting to the dependency name
+func brewFormula(dep *Dependency, platformConfig *PlatformConfig) string {
+	if platformConfig.Installer.Formula != "" {
+		return platformConfig.Installer.Formula
+	}
+	return dep.Name
+}
+
+// brewArgs builds the arguments for a brew subcommand, adding --cask when needed
+func brewArgs(verb string, platformConfig *PlatformConfig, args ...string) []string {
+	result := []string{verb}
+	if platformConfig.Installer.Cask {
+		result = append(result, "--cask")
+	}
+	return append(result, args...)
+}
+
+// parseBrewVersions returns the newest version from `brew list --versions`
+// output such as "jq 1.6 1.7.1"
+func parseBrewVersions(output string) string {
+	fields := strings.Fields(output)
+	if len(fields) < 2 {
+		return ""
+	}
+
+	// Strip Homebrew revision suffixes like "1.7.1_1"
+	candidates := make([]string, len(fields)-1)
+	for i, field := range fields[1:] {
+		candidates[i] = strings.SplitN(field, "_", 2)[0]
+	}
+
+	best, _, err := Version{}.Resolve(candidates)
+	if err != nil {
+		return fields[1]
+	}
+
+	return candidates[best]
+}
@@ -0,0 +1,107 @@
+package depman
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// fakeCommands replaces runCommand and lookPath for the duration of a test,
+// answering commands from a map keyed by the joined command line
+func fakeCommands(t *testing.T, responses map[string]string) *[]string {
+	t.Helper()
+
+	var calls []string
+	originalRun, originalLook := runCommand, lookPath
+	runCommand = func(ctx context.Context, name string, args ...string) (string, error) {
+		line := strings.Join(append([]string{name}, args...), " ")
+		calls = append(calls, line)
+		if output, ok := responses[line]; ok {
+			return output, nil
+		}
+		return "", fmt.Errorf("%s failed", name)
+	}
+	lookPath = func(file string) (string, error) {
+		return file, nil
+	}
+	t.Cleanup(func() {
+		runCommand, lookPath = originalRun, originalLook
+	})
+
+	return &calls
+}
+
+func TestParseBrewVersions(t *testing.T) {
+	testCases := []struct {
+		output   string
+		expected string
+	}{
+		{output: "jq 1.7.1", expected: "1.7.1"},
+		{output: "jq 1.6 1.7.1_1", expected: "1.7.1"},
+		{output: "google-chrome latest", expected: "latest"},
+		{output: "", expected: ""},
+	}
+
+	for _, tc := range testCases {
+		if got := parseBrewVersions(tc.output); got != tc.expected {
+			t.Errorf("parseBrewVersions(%q) = %q, expected %q", tc.output, got, tc.expected)
+		}
+	}
+}
+
+func TestBrewInstaller(t *testing.T) {
+	dep := &Dependency{Name: "jq", Version: Version{Required: "1.7.1"}}
+	manager := &Manager{Platform: "darwin", logger: &mockLogger{}}
+
+	t.Run("Install missing formula", func(t *testing.T) {
+		platformConfig := &PlatformConfig{Installer: Installer{Method: "brew", Pin: true}}
+		calls := fakeCommands(t, map[string]string{
+			"brew install jq": "",
+			"brew pin jq":     "",
+		})
+
+		if _, err := (brewInstaller{}).install(context.Background(), manager, dep, platformConfig); err != nil {
+			t.Fatalf("Did not expect an error but got: %v", err)
+		}
+
+		expected := []string{"brew list --versions jq", "brew install jq", "brew pin jq"}
+		if strings.Join(*calls, "|") != strings.Join(expected, "|") {
+			t.Errorf("Expected calls %v but got %v", expected, *calls)
+		}
+	})
+
+	t.Run("Upgrade installed cask", func(t *testing.T) {
+		platformConfig := &PlatformConfig{Installer: Installer{Method: "brew", Formula: "iterm2", Cask: true}}
+		calls := fakeCommands(t, map[string]string{
+			"brew list --cask --versions iterm2": "iterm2 3.4.0",
+			"brew upgrade --cask iterm2":         "",
+		})
+
+		if _, err := (brewInstaller{}).install(context.Background(), manager, dep, platformConfig); err != nil {
+			t.Fatalf("Did not expect an error but got: %v", err)
+		}
+
+		if last := (*calls)[len(*calls)-1]; last != "brew upgrade --cask iterm2" {
+			t.Errorf("Expected an upgrade but last call was %s", last)
+		}
+	})
+
+	t.Run("Detect version for check phase", func(t *testing.T) {
+		platformConfig := &PlatformConfig{Installer: Installer{Method: "brew"}}
+		fakeCommands(t, map[string]string{"brew list --versions jq": "jq 1.7.1"})
+		manager.Config = &DependencyConfig{Dependencies: []Dependency{{
+			Name:      "jq",
+			Version:   Version{Required: "1.7.1"},
+			Platforms: map[string]PlatformConfig{"darwin": *platformConfig},
+		}}}
+
+		status, err := manager.VerifyDependency(context.Background(), &manager.Config.Dependencies[0])
+		if err != nil {
+			t.Fatalf("Did not expect an error but got: %v", err)
+		}
+		if !status.Installed || status.CurrentVersion != "1.7.1" {
+			t.Errorf("Expected jq 1.7.1 to be installed but got %+v", status)
+		}
+	})
+}
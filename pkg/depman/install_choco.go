@@ -0,0 +1,111 @@
+package depman
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Chocolatey exit codes that indicate success with a pending reboot
+const (
+	chocoRebootInitiated = 1641
+	chocoRebootRequired  = 3010
+)
+
+// chocoInstaller installs packages with Chocolatey
+type chocoInstaller struct{}
+
+func init() {
+	installStrategies["choco"] = chocoInstaller{}
+}
+
+// install installs or upgrades the package unattended, pinned to the required version
+func (chocoInstaller) install(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) (LockedArtifact, error) {
+	var artifact LockedArtifact
+
+	choco, err := hostLookPath(ctx, "choco")
+	if err != nil {
+		return artifact, fmt.Errorf("chocolatey is not installed")
+	}
+
+	name := packageName(dep, platformConfig)
+
+	// Upgrade handles both fresh installs and version changes
+	args := []string{"upgrade", name, "--yes", "--no-progress", "--limit-output"}
+	if dep.Version.Required != "" {
+		args = append(args, "--version", dep.Version.Required, "--allow-downgrade")
+	}
+	args = append(args, platformConfig.Installer.Args...)
+
+	m.logger.Infof("Running choco upgrade %s", name)
+	if _, err := m.retryCommand(ctx, dep, choco, args...); err != nil {
+		if err := chocoResult(m, name, err); err != nil {
+			return artifact, fmt.Errorf("installation failed: %w", err)
+		}
+	}
+
+	m.logger.Infof("Successfully installed %s", dep.Name)
+	artifact.URL = "choco:" + name
+	return artifact, nil
+}
+
+// uninstall removes the package unattended
+func (chocoInstaller) uninstall(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) error {
+	choco, err := hostLookPath(ctx, "choco")
+	if err != nil {
+		return fmt.Errorf("chocolatey is not installed")
+	}
+
+	name := packageName(dep, platformConfig)
+	m.logger.Infof("Running choco uninstall %s", name)
+	if _, err := runCommand(ctx, choco, "uninstall", name, "--yes", "--no-progress", "--limit-output"); err != nil {
+		if err := chocoResult(m, name, err); err != nil {
+			return fmt.Errorf("uninstallation failed: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// detectVersion reads the installed version from `choco list`
+func (chocoInstaller) detectVersion(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) (string, error) {
+	choco, err := hostLookPath(ctx, "choco")
+	if err != nil {
+		return "", fmt.Errorf("chocolatey is not installed")
+	}
+
+	name := packageName(dep, platformConfig)
+	output, err := runCommand(ctx, choco, "list", name, "--exact", "--limit-output")
+	if err != nil {
+		return "", fmt.Errorf("%s is not installed: %w", name, err)
+	}
+
+	version := parseChocoList(output, name)
+	if version == "" {
+		return "", fmt.Errorf("%s is not installed", name)
+	}
+
+	return version, nil
+}
+
+// chocoResult maps Chocolatey exit codes to success or the original error
+func chocoResult(m *Manager, name string, err error) error {
+	switch exitCode(err) {
+	case chocoRebootInitiated, chocoRebootRequired:
+		m.logger.Warnf("%s was installed but Windows must be restarted to finish", name)
+		return nil
+	default:
+		return err
+	}
+}
+
+// parseChocoList finds a package version in `choco list --limit-output` output ("name|version" lines)
+func parseChocoList(output, name string) string {
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.SplitN(strings.TrimSpace(line), "|", 2)
+		if len(parts) == 2 && strings.EqualFold(parts[0], name) {
+			return parts[1]
+		}
+	}
+	return ""
+}
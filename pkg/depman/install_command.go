@@ -0,0 +1,94 @@
+package depman
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// commandInstaller downloads the configured installer (if any) and runs the
+// platform's install and uninstall commands
+type commandInstaller struct{}
+
+// install downloads the installer and runs the install command
+func (commandInstaller) install(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) (LockedArtifact, error) {
+	var artifact LockedArtifact
+
+	if len(platformConfig.Commands.Install) == 0 {
+		return artifact, fmt.Errorf("no install command provided for dependency: %s", dep.Name)
+	}
+
+	// Create a temporary directory for downloads
+	tempDir, err := os.MkdirTemp("", "depman-download-*")
+	if err != nil {
+		return artifact, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir) // Clean up when done
+
+	// Download dependency if URL is specified
+	downloadPath := ""
+	if platformConfig.Installer.URL != "" {
+		result, err := m.downloadArtifact(ctx, dep, platformConfig, platformConfig.Installer.URL, platformConfig.Installer.Checksum, tempDir)
+		if err != nil {
+			return artifact, err
+		}
+
+		artifact.URL = platformConfig.Installer.URL
+		artifact.Checksum = result.Checksum
+		downloadPath = result.FilePath
+	}
+
+	// Prepare install command with replacements
+	installCmd := make([]string, len(platformConfig.Commands.Install))
+	for i, arg := range platformConfig.Commands.Install {
+		// Replace placeholders in command arguments
+		arg = strings.ReplaceAll(arg, "{download_path}", downloadPath)
+
+		// Add more replacements as needed:
+		// - {install_dir} for installation directory
+		// - {product_id} for product ID
+		// - etc.
+
+		installCmd[i] = arg
+	}
+
+	m.logger.Infof("Installing %s using command: %s", dep.Name, strings.Join(installCmd, " "))
+
+	// Execute installation command
+	cmd := hostCommand(ctx, installCmd[0], installCmd[1:]...)
+	output, full := &tailBuffer{limit: maxScriptOutput}, &bytes.Buffer{}
+	cmd.Stdout = io.MultiWriter(output, full)
+	cmd.Stderr = cmd.Stdout
+	err = cmd.Run()
+	installLogOf(ctx).record(installCmd[0], installCmd[1:], full.Bytes(), err)
+	m.recordOutput(dep.Name, output.String())
+	if err != nil {
+		return artifact, fmt.Errorf("installation failed: %w, output: %s", err, output.String())
+	}
+
+	m.logger.Infof("Successfully installed %s", dep.Name)
+	return artifact, nil
+}
+
+// uninstall runs the platform's uninstall command
+func (commandInstaller) uninstall(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) error {
+	if len(platformConfig.Commands.Uninstall) == 0 {
+		return fmt.Errorf("no uninstall command provided for dependency: %s", dep.Name)
+	}
+
+	uninstallCmd := platformConfig.Commands.Uninstall
+
+	m.logger.Infof("Uninstalling %s using command: %s", dep.Name, strings.Join(uninstallCmd, " "))
+
+	cmd := hostCommand(ctx, uninstallCmd[0], uninstallCmd[1:]...)
+	output, err := cmd.CombinedOutput()
+	installLogOf(ctx).record(uninstallCmd[0], uninstallCmd[1:], output, err)
+	if err != nil {
+		return fmt.Errorf("uninstallation failed: %w, output: %s", err, output)
+	}
+
+	return nil
+}
@@ -0,0 +1,27 @@
+package depman
+
+import (
+	"context"
+	"runtime"
+	"testing"
+)
+
+func TestCommandInstallerOutput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("install commands run with sh in this test")
+	}
+
+	manager := &Manager{Platform: "linux", homeDir: t.TempDir(), logger: &mockLogger{}}
+	dep := &Dependency{Name: "tool"}
+	platformConfig := &PlatformConfig{Commands: Commands{
+		Install: []string{"sh", "-c", "echo unpacking; echo 'disk full' >&2; exit 1"},
+	}}
+
+	// Failures keep the output for the status
+	if _, err := (commandInstaller{}).install(context.Background(), manager, dep, platformConfig); err == nil {
+		t.Error("Expected an error when the command fails but got none")
+	}
+	if output := manager.takeOutput("tool"); output != "unpacking\ndisk full" {
+		t.Errorf("Expected the output of the failed command but got %q", output)
+	}
+}
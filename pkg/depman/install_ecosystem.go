@@ -0,0 +1,324 @@
+package depman
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/devnadeemashraf/depman/internal/downloader"
+)
+
+// packageEcosystem describes how to drive one language package manager that
+// installs commands into a prefix directory
+type packageEcosystem struct {
+	binary   string                                                                               // Executable installing the packages
+	install  func(prefix, pkg, version string) ([]string, []string)                               // Command and extra environment installing a version into prefix
+	command  func(binary, platform string) string                                                 // Slash-separated path of an installed command inside the prefix
+	versions func(ctx context.Context, m *Manager, dep *Dependency, pkg string) ([]string, error) // Lists the published versions
+}
+
+// packageEcosystems lists the supported language package managers
+var packageEcosystems = map[string]packageEcosystem{
+	"pipx": {
+		binary: "pipx",
+		install: func(prefix, pkg, version string) ([]string, []string) {
+			// PIPX_HOME holds the virtual environment, PIPX_BIN_DIR the entry points
+			return []string{"pipx", "install", "--force", pkg + "==" + version}, []string{
+				"PIPX_HOME=" + filepath.Join(prefix, "pipx"),
+				"PIPX_BIN_DIR=" + filepath.Join(prefix, "bin"),
+				"PIPX_MAN_DIR=" + filepath.Join(prefix, "man"),
+			}
+		},
+		command: func(binary, platform string) string {
+			return "bin/" + executableName(binary, platform, ".exe")
+		},
+		versions: pipVersions,
+	},
+	"npm": {
+		binary: "npm",
+		install: func(prefix, pkg, version string) ([]string, []string) {
+			return []string{"npm", "install", "--global", "--prefix", prefix, pkg + "@" + version}, nil
+		},
+		command: func(binary, platform string) string {
+			// npm links commands into the prefix itself on Windows
+			if platform == "windows" {
+				return executableName(binary, platform, ".cmd")
+			}
+			return "bin/" + binary
+		},
+		versions: npmVersions,
+	},
+	"cargo": {
+		binary: "cargo",
+		install: func(prefix, pkg, version string) ([]string, []string) {
+			return []string{"cargo", "install", "--root", prefix, "--version", "=" + version, pkg}, nil
+		},
+		command: func(binary, platform string) string {
+			return "bin/" + executableName(binary, platform, ".exe")
+		},
+		versions: crateVersions,
+	},
+}
+
+// executableName adds the platform's executable extension to a binary name
+// that has none
+func executableName(binary, platform, ext string) string {
+	if platform == "windows" && filepath.Ext(binary) == "" {
+		return binary + ext
+	}
+	return binary
+}
+
+// ecosystemInstaller installs commands distributed through a language package
+// manager into a prefix per version under the tools directory, so they stay
+// out of the user's global packages, and points a shim at them
+type ecosystemInstaller struct {
+	name string // Key in packageEcosystems
+}
+
+func init() {
+	for name := range packageEcosystems {
+		installStrategies[name] = ecosystemInstaller{name: name}
+	}
+}
+
+// ecosystem returns the package manager, or an error if it is not installed
+func (e ecosystemInstaller) ecosystem() (packageEcosystem, error) {
+	ecosystem := packageEcosystems[e.name]
+	if _, err := lookPath(ecosystem.binary); err != nil {
+		return ecosystem, fmt.Errorf("%s is not installed", ecosystem.binary)
+	}
+	return ecosystem, nil
+}
+
+// resolveVersion returns the required version, or the newest published one
+// satisfying the constraint
+func (e ecosystemInstaller) resolveVersion(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) (string, error) {
+	if dep.Version.Required != "" && dep.Version.Constraint == "" {
+		return strings.TrimPrefix(dep.Version.Required, "v"), nil
+	}
+
+	candidates, err := e.availableVersions(ctx, m, dep, platformConfig)
+	if err != nil {
+		return "", err
+	}
+	i, _, err := dep.Version.Resolve(candidates)
+	if err != nil {
+		return "", fmt.Errorf("%s %s: %w", e.name, packageName(dep, platformConfig), err)
+	}
+	return candidates[i], nil
+}
+
+// install installs the pinned version into its own prefix and makes it the
+// active one
+func (e ecosystemInstaller) install(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) (LockedArtifact, error) {
+	var artifact LockedArtifact
+
+	ecosystem, err := e.ecosystem()
+	if err != nil {
+		return artifact, err
+	}
+	version, err := e.resolveVersion(ctx, m, dep, platformConfig)
+	if err != nil {
+		return artifact, err
+	}
+
+	pkg := packageName(dep, platformConfig)
+	prefix := m.toolVersionDir(dep.Name, version)
+	_, statErr := os.Stat(prefix)
+	fresh := os.IsNotExist(statErr)
+
+	args, env := ecosystem.install(prefix, pkg, version)
+	args = append(args, platformConfig.Installer.Args...)
+	m.logger.Infof("Installing %s using command: %s", dep.Name, strings.Join(args, " "))
+	if _, err := m.retryCommand(withCommandEnv(ctx, env...), dep, args[0], args[1:]...); err != nil {
+		if fresh {
+			os.RemoveAll(prefix)
+		}
+		return artifact, fmt.Errorf("installation failed: %w", err)
+	}
+
+	binary := e.binaryName(m, dep, platformConfig)
+	target := filepath.Join(prefix, filepath.FromSlash(binary))
+	if _, err := os.Stat(target); err != nil {
+		return artifact, fmt.Errorf("%s %s did not install %s", pkg, version, path.Base(binary))
+	}
+	shim, err := m.writeShim(dep, binary, version)
+	if err != nil {
+		return artifact, err
+	}
+	m.trackFiles(dep.Name, prefix, shim)
+
+	m.logger.Infof("Successfully installed %s %s to %s (shim: %s)", dep.Name, version, prefix, shim)
+	artifact.URL = e.name + ":" + pkg + "@" + version
+	return artifact, nil
+}
+
+// concurrent reports that installs only touch their own prefix
+func (ecosystemInstaller) concurrent() bool {
+	return true
+}
+
+// binaryName returns the path of the command inside each version's prefix
+func (e ecosystemInstaller) binaryName(m *Manager, dep *Dependency, platformConfig *PlatformConfig) string {
+	binary := platformConfig.Installer.Binary
+	if binary == "" {
+		binary = dep.Name
+	}
+	return packageEcosystems[e.name].command(binary, m.Platform)
+}
+
+// uninstall deletes the shim and every installed version's prefix
+func (e ecosystemInstaller) uninstall(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) error {
+	return m.removeTool(dep, e.binaryName(m, dep, platformConfig))
+}
+
+// detectVersion reports the version whose prefix the shim runs; installs are
+// pinned exactly, so the prefix records the version
+func (e ecosystemInstaller) detectVersion(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) (string, error) {
+	_, active, err := m.InstalledVersions(dep.Name)
+	if err != nil {
+		return "", err
+	}
+	if active == "" {
+		return "", fmt.Errorf("%s is not installed in %s", dep.Name, m.ToolsDir())
+	}
+	if override := os.Getenv(versionOverrideVar(dep.Name)); override != "" {
+		active = override
+	}
+
+	file := filepath.Join(m.toolVersionDir(dep.Name, active), filepath.FromSlash(e.binaryName(m, dep, platformConfig)))
+	if _, err := os.Stat(file); err != nil {
+		return "", fmt.Errorf("%s %s is not installed in %s", dep.Name, active, m.ToolsDir())
+	}
+	return active, nil
+}
+
+// availableVersions lists the versions published for the package
+func (e ecosystemInstaller) availableVersions(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) ([]string, error) {
+	ecosystem, err := e.ecosystem()
+	if err != nil {
+		return nil, err
+	}
+	pkg := packageName(dep, platformConfig)
+	versions, err := ecosystem.versions(ctx, m, dep, pkg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list versions of %s: %w", pkg, err)
+	}
+	return versions, nil
+}
+
+// pipVersions lists a Python package's versions with `pip index versions`
+func pipVersions(ctx context.Context, m *Manager, dep *Dependency, pkg string) ([]string, error) {
+	python := "python3"
+	if m.Platform == "windows" {
+		python = "python"
+	}
+	output, err := m.retryCommand(ctx, dep, python, "-m", "pip", "index", "versions", pkg)
+	if err != nil {
+		return nil, err
+	}
+	return parsePipVersions(output), nil
+}
+
+// parsePipVersions extracts the versions from `pip index versions` output:
+// "Available versions: 2.1.0, 2.0.1, ..."
+func parsePipVersions(output string) []string {
+	for _, line := range strings.Split(output, "\n") {
+		list, ok := strings.CutPrefix(strings.TrimSpace(line), "Available versions:")
+		if !ok {
+			continue
+		}
+		var versions []string
+		for _, version := range strings.Split(list, ",") {
+			if version = strings.TrimSpace(version); version != "" {
+				versions = append(versions, version)
+			}
+		}
+		return versions
+	}
+	return nil
+}
+
+// npmVersions lists a package's versions with `npm view`
+func npmVersions(ctx context.Context, m *Manager, dep *Dependency, pkg string) ([]string, error) {
+	output, err := m.retryCommand(ctx, dep, "npm", "view", pkg, "versions", "--json")
+	if err != nil {
+		return nil, err
+	}
+	return parseNpmVersions(output)
+}
+
+// parseNpmVersions decodes `npm view <pkg> versions --json` output, which is
+// a single string rather than a list for packages with one version
+func parseNpmVersions(output string) ([]string, error) {
+	var versions []string
+	if err := json.Unmarshal([]byte(output), &versions); err == nil {
+		return versions, nil
+	}
+	var version string
+	if err := json.Unmarshal([]byte(output), &version); err != nil {
+		return nil, fmt.Errorf("failed to parse npm output: %w", err)
+	}
+	return []string{version}, nil
+}
+
+// cratesIndexURL is the crates.io sparse registry index; replaced in tests
+var cratesIndexURL = "https://index.crates.io"
+
+// crateIndexPath returns the path of a crate's file in the sparse index
+func crateIndexPath(name string) string {
+	name = strings.ToLower(name)
+	switch len(name) {
+	case 1, 2:
+		return fmt.Sprintf("%d/%s", len(name), name)
+	case 3:
+		return "3/" + name[:1] + "/" + name
+	default:
+		return name[:2] + "/" + name[2:4] + "/" + name
+	}
+}
+
+// crateVersions lists a crate's versions that are not yanked from the
+// crates.io index, as `cargo search` only reports the newest one
+func crateVersions(ctx context.Context, m *Manager, dep *Dependency, pkg string) ([]string, error) {
+	url := cratesIndexURL + "/" + crateIndexPath(pkg)
+	var versions []string
+	err := m.retry(ctx, dep, "Version lookup", func() error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := m.client().Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return &downloader.StatusError{URL: url, StatusCode: resp.StatusCode, Status: resp.Status}
+		}
+
+		// Each line describes one published version
+		versions = nil
+		scanner := bufio.NewScanner(resp.Body)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			var entry struct {
+				Version string `json:"vers"`
+				Yanked  bool   `json:"yanked"`
+			}
+			if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil || entry.Yanked {
+				continue
+			}
+			versions = append(versions, entry.Version)
+		}
+		return scanner.Err()
+	})
+	return versions, err
+}
@@ -0,0 +1,196 @@
+package depman
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParsePipVersions(t *testing.T) {
+	output := "black (24.8.0)\nAvailable versions: 24.8.0, 24.4.2, 23.12.1\n  INSTALLED: 24.4.2\n"
+	expected := []string{"24.8.0", "24.4.2", "23.12.1"}
+	if got := parsePipVersions(output); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v but got %v", expected, got)
+	}
+	if got := parsePipVersions("ERROR: No matching distribution found"); got != nil {
+		t.Errorf("Expected no versions but got %v", got)
+	}
+}
+
+func TestParseNpmVersions(t *testing.T) {
+	testCases := []struct {
+		name     string
+		output   string
+		expected []string
+	}{
+		{"List", `["1.0.0", "1.1.0"]`, []string{"1.0.0", "1.1.0"}},
+		{"Single version", `"1.0.0"`, []string{"1.0.0"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseNpmVersions(tc.output)
+			if err != nil {
+				t.Fatalf("Did not expect an error but got: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("Expected %v but got %v", tc.expected, got)
+			}
+		})
+	}
+
+	if _, err := parseNpmVersions("npm ERR! 404"); err == nil {
+		t.Error("Expected an error for invalid output but got none")
+	}
+}
+
+func TestCrateIndexPath(t *testing.T) {
+	testCases := map[string]string{
+		"a":       "1/a",
+		"cc":      "2/cc",
+		"fd":      "2/fd",
+		"bat":     "3/b/bat",
+		"RipGrep": "ri/pg/ripgrep",
+	}
+	for name, expected := range testCases {
+		if got := crateIndexPath(name); got != expected {
+			t.Errorf("Expected index path %s for %s but got %s", expected, name, got)
+		}
+	}
+}
+
+func TestCrateVersions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ri/pg/ripgrep" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintln(w, `{"name":"ripgrep","vers":"13.0.0","yanked":false}`)
+		fmt.Fprintln(w, `{"name":"ripgrep","vers":"14.0.0","yanked":true}`)
+		fmt.Fprintln(w, `{"name":"ripgrep","vers":"14.1.0","yanked":false}`)
+	}))
+	defer server.Close()
+
+	original := cratesIndexURL
+	cratesIndexURL = server.URL
+	defer func() { cratesIndexURL = original }()
+
+	manager := &Manager{logger: &mockLogger{}}
+	versions, err := crateVersions(context.Background(), manager, &Dependency{Name: "rg"}, "ripgrep")
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if expected := []string{"13.0.0", "14.1.0"}; !reflect.DeepEqual(versions, expected) {
+		t.Errorf("Expected versions %v but got %v", expected, versions)
+	}
+
+	if _, err := crateVersions(context.Background(), manager, &Dependency{Name: "missing"}, "missing"); err == nil {
+		t.Error("Expected an error for an unknown crate but got none")
+	}
+}
+
+func TestEcosystemInstall(t *testing.T) {
+	homeDir := t.TempDir()
+
+	var calls, env []string
+	originalRun, originalLook := runCommand, lookPath
+	runCommand = func(ctx context.Context, name string, args ...string) (string, error) {
+		line := strings.Join(append([]string{name}, args...), " ")
+		calls = append(calls, line)
+		switch {
+		case line == "npm view prettier versions --json":
+			return `["2.8.8", "3.3.2", "3.3.3"]`, nil
+		case strings.HasPrefix(line, "npm install --global --prefix "):
+			// Stand in for npm linking the package's command into the prefix
+			prefix := args[3]
+			if err := os.MkdirAll(filepath.Join(prefix, "bin"), 0755); err != nil {
+				return "", err
+			}
+			return "", os.WriteFile(filepath.Join(prefix, "bin", "prettier"), []byte("#!/bin/sh\n"), 0755)
+		case strings.HasPrefix(line, "pipx install "):
+			env = commandEnv(ctx)
+		}
+		return "", fmt.Errorf("%s failed", name)
+	}
+	lookPath = func(file string) (string, error) {
+		return file, nil
+	}
+	defer func() { runCommand, lookPath = originalRun, originalLook }()
+
+	manager := &Manager{Platform: "linux", homeDir: homeDir, logger: &mockLogger{}}
+	dep := &Dependency{Name: "prettier", Version: Version{Constraint: "^3"}}
+	platformConfig := &PlatformConfig{Installer: Installer{Method: "npm"}}
+
+	artifact, err := (ecosystemInstaller{name: "npm"}).install(context.Background(), manager, dep, platformConfig)
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if artifact.URL != "npm:prettier@3.3.3" {
+		t.Errorf("Expected artifact npm:prettier@3.3.3 but got %s", artifact.URL)
+	}
+	prefix := filepath.Join(manager.ToolsDir(), "prettier", "3.3.3")
+	if expected := "npm install --global --prefix " + prefix + " prettier@3.3.3"; calls[1] != expected {
+		t.Errorf("Expected install command %q but got %q", expected, calls[1])
+	}
+	if _, err := os.Stat(filepath.Join(manager.BinDir(), "prettier")); err != nil {
+		t.Errorf("Expected a shim in the bin directory: %v", err)
+	}
+
+	version, err := (ecosystemInstaller{name: "npm"}).detectVersion(context.Background(), manager, dep, platformConfig)
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if version != "3.3.3" {
+		t.Errorf("Expected version 3.3.3 but got %s", version)
+	}
+
+	// A failed install leaves no prefix behind and isolates pipx's directories
+	dep = &Dependency{Name: "black", Version: Version{Required: "24.8.0"}}
+	if _, err := (ecosystemInstaller{name: "pipx"}).install(context.Background(), manager, dep, &PlatformConfig{}); err == nil {
+		t.Error("Expected an error when pipx fails but got none")
+	}
+	prefix = filepath.Join(manager.ToolsDir(), "black", "24.8.0")
+	if _, err := os.Stat(prefix); !os.IsNotExist(err) {
+		t.Errorf("Expected the prefix of a failed install to be removed")
+	}
+	expected := []string{
+		"PIPX_HOME=" + filepath.Join(prefix, "pipx"),
+		"PIPX_BIN_DIR=" + filepath.Join(prefix, "bin"),
+		"PIPX_MAN_DIR=" + filepath.Join(prefix, "man"),
+	}
+	if !reflect.DeepEqual(env, expected) {
+		t.Errorf("Expected environment %v but got %v", expected, env)
+	}
+}
+
+func TestEcosystemBinaryName(t *testing.T) {
+	testCases := []struct {
+		method   string
+		platform string
+		expected string
+	}{
+		{"npm", "linux", "bin/tool"},
+		{"npm", "windows", "tool.cmd"},
+		{"cargo", "windows", "bin/tool.exe"},
+		{"pipx", "darwin", "bin/tool"},
+	}
+
+	for _, tc := range testCases {
+		manager := &Manager{Platform: tc.platform}
+		got := (ecosystemInstaller{name: tc.method}).binaryName(manager, &Dependency{Name: "tool"}, &PlatformConfig{})
+		if got != tc.expected {
+			t.Errorf("Expected %s binary %s on %s but got %s", tc.method, tc.expected, tc.platform, got)
+		}
+	}
+
+	manager := &Manager{Platform: "windows", homeDir: "home"}
+	if shim := manager.shimPath("tool.cmd"); filepath.Base(shim) != "tool.cmd" {
+		t.Errorf("Expected shim tool.cmd but got %s", shim)
+	}
+}
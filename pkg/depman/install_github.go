@@ -0,0 +1,235 @@
+package depman
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/devnadeemashraf/depman/internal/github"
+)
+
+// osAliases lists the names release assets commonly use for each platform,
+// matched against whole tokens of the asset name
+var osAliases = map[string][]string{
+	"linux":   {"linux"},
+	"darwin":  {"darwin", "macos", "apple", "osx", "mac"},
+	"windows": {"windows", "win64", "win32", "win"},
+}
+
+// archAliases lists the names release assets commonly use for each
+// architecture, matched against whole tokens of the asset name
+var archAliases = map[string][]string{
+	"amd64": {"amd64", "x86_64", "x64"},
+	"arm64": {"arm64", "aarch64"},
+	"386":   {"386", "i386", "i686", "x86"},
+	"arm":   {"armv7", "armv7l", "armhf", "arm"},
+}
+
+// assetSeparator splits asset names into tokens
+var assetSeparator = regexp.MustCompile(`[-_. ]+`)
+
+// nonBinaryAsset matches checksum, signature and metadata files attached to releases
+var nonBinaryAsset = regexp.MustCompile(`(?i)(\.(sha\d*|sha\d+sum|md5|asc|sig|pem|sbom|json|txt|deb|rpm|apk|msi|pkg)$|checksums)`)
+
+// tagVersion extracts the semantic version from a release tag like "v1.2.3" or "jq-1.7.1"
+var tagVersion = regexp.MustCompile(`v?(\d+(\.\d+){0,2}([-+][0-9A-Za-z.-]+)?)$`)
+
+// githubReleaseInstaller installs binaries from assets of GitHub releases
+type githubReleaseInstaller struct{}
+
+func init() {
+	installStrategies["github-release"] = githubReleaseInstaller{}
+}
+
+// install picks the release matching the version requirements and installs its platform asset
+func (g githubReleaseInstaller) install(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) (LockedArtifact, error) {
+	url, version, err := g.resolve(ctx, m, dep, platformConfig)
+	if err != nil {
+		return LockedArtifact{}, err
+	}
+	return m.installArchive(ctx, dep, platformConfig, url, platformConfig.Installer.Checksum, version)
+}
+
+// resolve returns the asset URL and version an install would use
+func (githubReleaseInstaller) resolve(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) (string, string, error) {
+	installer := platformConfig.Installer
+	if installer.Repo == "" {
+		return "", "", fmt.Errorf("no repository provided for dependency: %s", dep.Name)
+	}
+
+	// A frozen lockfile or offline bundle already pins the exact asset URL
+	if (m.lock != nil || m.bundle != nil) && installer.URL != "" {
+		return installer.URL, m.pinnedVersion(dep), nil
+	}
+
+	releases, err := listReleases(ctx, m, dep, installer)
+	if err != nil {
+		return "", "", err
+	}
+
+	release, version, err := selectRelease(releases, dep.Version, installer.Prerelease)
+	if err != nil {
+		return "", "", fmt.Errorf("%s: %w", installer.Repo, err)
+	}
+
+	asset, err := selectAsset(release.Assets, installer.Asset, version, m.Platform, m.targetArch())
+	if err != nil {
+		return "", "", fmt.Errorf("%s %s: %w", installer.Repo, release.TagName, err)
+	}
+
+	m.logger.Infof("Selected %s from %s release %s", asset.Name, installer.Repo, release.TagName)
+	return asset.DownloadURL, version, nil
+}
+
+// availableVersions lists the versions of the published releases
+func (githubReleaseInstaller) availableVersions(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) ([]string, error) {
+	if platformConfig.Installer.Repo == "" {
+		return nil, fmt.Errorf("no repository provided for dependency: %s", dep.Name)
+	}
+	releases, err := listReleases(ctx, m, dep, platformConfig.Installer)
+	if err != nil {
+		return nil, err
+	}
+	_, versions := releaseVersions(releases, platformConfig.Installer.Prerelease)
+	return versions, nil
+}
+
+// listReleases fetches the releases of the installer's repository under the retry policy
+func listReleases(ctx context.Context, m *Manager, dep *Dependency, installer Installer) ([]github.Release, error) {
+	var releases []github.Release
+	err := m.retry(ctx, dep, "Release lookup", func() error {
+		var err error
+		releases, err = github.ListReleases(ctx, m.client(), installer.Repo, github.Token(installer.TokenEnv))
+		return err
+	})
+	return releases, err
+}
+
+// concurrent reports that archive installs only touch their own files
+func (githubReleaseInstaller) concurrent() bool {
+	return true
+}
+
+// binaryName returns the name of the binary kept for each installed version
+func (githubReleaseInstaller) binaryName(m *Manager, dep *Dependency, platformConfig *PlatformConfig) string {
+	return archiveBinaryName(dep, platformConfig, m.Platform)
+}
+
+// uninstall deletes the shim and every installed version of the tool
+func (githubReleaseInstaller) uninstall(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) error {
+	return archiveInstaller{}.uninstall(ctx, m, dep, platformConfig)
+}
+
+// detectVersion runs the managed binary with --version
+func (githubReleaseInstaller) detectVersion(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) (string, error) {
+	return archiveInstaller{}.detectVersion(ctx, m, dep, platformConfig)
+}
+
+// detectLicense looks up the license GitHub detected for the repository
+func (githubReleaseInstaller) detectLicense(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) (string, error) {
+	installer := platformConfig.Installer
+	if installer.Repo == "" {
+		return "", nil
+	}
+	var license string
+	err := m.retry(ctx, dep, "License lookup", func() error {
+		var err error
+		license, err = github.RepositoryLicense(ctx, m.client(), installer.Repo, github.Token(installer.TokenEnv))
+		return err
+	})
+	return license, err
+}
+
+// selectRelease returns the release to install: the exact required version if
+// it was published, otherwise the newest release satisfying the constraint
+func selectRelease(releases []github.Release, required Version, prerelease bool) (*github.Release, string, error) {
+	candidates, versions := releaseVersions(releases, prerelease)
+
+	i, version, err := required.Resolve(versions)
+	if err != nil {
+		return nil, "", fmt.Errorf("no matching release: %w", err)
+	}
+
+	return candidates[i], version.String(), nil
+}
+
+// releaseVersions returns the releases that may be installed along with the
+// version in each tag, skipping drafts, untagged releases and, unless
+// allowed, prereleases
+func releaseVersions(releases []github.Release, prerelease bool) ([]*github.Release, []string) {
+	var candidates []*github.Release
+	var versions []string
+	for i := range releases {
+		release := &releases[i]
+		if release.Draft || (release.Prerelease && !prerelease) {
+			continue
+		}
+
+		match := tagVersion.FindStringSubmatch(release.TagName)
+		if match == nil {
+			continue
+		}
+		candidates = append(candidates, release)
+		versions = append(versions, match[1])
+	}
+	return candidates, versions
+}
+
+// selectAsset picks the release asset for a platform, either by the configured
+// glob pattern or by matching well-known os and architecture names
+func selectAsset(assets []github.Asset, pattern, version, platform, arch string) (*github.Asset, error) {
+	if pattern != "" {
+		for _, archName := range append([]string{arch}, archAliases[arch]...) {
+			expanded := strings.NewReplacer("{{version}}", version, "{{os}}", platform, "{{arch}}", archName).Replace(pattern)
+			for i := range assets {
+				if ok, _ := path.Match(expanded, assets[i].Name); ok {
+					return &assets[i], nil
+				}
+			}
+		}
+		return nil, fmt.Errorf("no asset matches pattern %s", pattern)
+	}
+
+	for i := range assets {
+		name := strings.ToLower(assets[i].Name)
+		if nonBinaryAsset.MatchString(name) {
+			continue
+		}
+		tokens := assetTokens(name)
+		if containsToken(tokens, osAliases[platform]) && containsToken(tokens, archAliases[arch]) {
+			return &assets[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("no asset found for %s/%s; set installer.asset to select one", platform, arch)
+}
+
+// assetTokens splits an asset name into the tokens between separators, so
+// "win" does not match "darwin" nor "arm" match "arm64". The "x86_64" and
+// "x86-64" architecture names are kept as the single token "x86_64".
+func assetTokens(name string) []string {
+	parts := assetSeparator.Split(name, -1)
+	tokens := make([]string, 0, len(parts))
+	for i := 0; i < len(parts); i++ {
+		if parts[i] == "x86" && i+1 < len(parts) && parts[i+1] == "64" {
+			tokens = append(tokens, "x86_64")
+			i++
+			continue
+		}
+		tokens = append(tokens, parts[i])
+	}
+	return tokens
+}
+
+// containsToken reports whether any of the names is one of the tokens
+func containsToken(tokens, names []string) bool {
+	for _, name := range names {
+		if slices.Contains(tokens, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,175 @@
+package depman
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/devnadeemashraf/depman/internal/github"
+)
+
+func TestSelectRelease(t *testing.T) {
+	releases := []github.Release{
+		{TagName: "v2.0.0-rc.1", Prerelease: true},
+		{TagName: "v1.8.0"},
+		{TagName: "jq-1.7.1"},
+		{TagName: "v1.6.0"},
+		{TagName: "nightly"},
+		{TagName: "v3.0.0", Draft: true},
+	}
+
+	testCases := []struct {
+		name        string
+		version     Version
+		prerelease  bool
+		expected    string
+		expectError bool
+	}{
+		{name: "Exact version", version: Version{Required: "1.7.1"}, expected: "jq-1.7.1"},
+		{name: "Newest satisfying constraint", version: Version{Constraint: "<1.8.0"}, expected: "jq-1.7.1"},
+		{name: "Exact wins over constraint", version: Version{Required: "1.6.0", Constraint: "^1.0.0"}, expected: "v1.6.0"},
+		{name: "Latest stable", version: Version{}, expected: "v1.8.0"},
+		{name: "Prereleases allowed", version: Version{}, prerelease: true, expected: "v2.0.0-rc.1"},
+		{name: "Missing exact version", version: Version{Required: "1.9.0"}, expectError: true},
+		{name: "Unsatisfiable constraint", version: Version{Constraint: ">=4.0.0"}, expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			release, _, err := selectRelease(releases, tc.version, tc.prerelease)
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("Expected an error but got release %s", release.TagName)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Did not expect an error but got: %v", err)
+			}
+			if release.TagName != tc.expected {
+				t.Errorf("Expected release %s but got %s", tc.expected, release.TagName)
+			}
+		})
+	}
+}
+
+func TestSelectAsset(t *testing.T) {
+	assets := []github.Asset{
+		{Name: "checksums.txt"},
+		{Name: "tool_1.2.3_linux_x86_64.tar.gz.sha256"},
+		{Name: "tool_1.2.3_linux_x86_64.tar.gz"},
+		{Name: "tool_1.2.3_linux_arm64.tar.gz"},
+		{Name: "tool_1.2.3_macOS_arm64.zip"},
+	}
+
+	testCases := []struct {
+		name        string
+		pattern     string
+		platform    string
+		arch        string
+		expected    string
+		expectError bool
+	}{
+		{name: "Auto linux amd64", platform: "linux", arch: "amd64", expected: "tool_1.2.3_linux_x86_64.tar.gz"},
+		{name: "Auto darwin arm64", platform: "darwin", arch: "arm64", expected: "tool_1.2.3_macOS_arm64.zip"},
+		{name: "Pattern with arch alias", pattern: "tool_{{version}}_{{os}}_{{arch}}.tar.gz", platform: "linux", arch: "amd64", expected: "tool_1.2.3_linux_x86_64.tar.gz"},
+		{name: "No asset for platform", platform: "windows", arch: "amd64", expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			asset, err := selectAsset(assets, tc.pattern, "1.2.3", tc.platform, tc.arch)
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("Expected an error but got asset %s", asset.Name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Did not expect an error but got: %v", err)
+			}
+			if asset.Name != tc.expected {
+				t.Errorf("Expected asset %s but got %s", tc.expected, asset.Name)
+			}
+		})
+	}
+}
+
+func TestSelectAssetMultiPlatform(t *testing.T) {
+	// Sorted by name, as GitHub lists them
+	assets := []github.Asset{
+		{Name: "tool_1.2.3_checksums.txt"},
+		{Name: "tool_1.2.3_darwin_amd64.tar.gz"},
+		{Name: "tool_1.2.3_darwin_arm64.tar.gz"},
+		{Name: "tool_1.2.3_linux_386.tar.gz"},
+		{Name: "tool_1.2.3_linux_amd64.tar.gz"},
+		{Name: "tool_1.2.3_linux_arm64.tar.gz"},
+		{Name: "tool_1.2.3_linux_armv7.tar.gz"},
+		{Name: "tool_1.2.3_windows_386.zip"},
+		{Name: "tool_1.2.3_windows_amd64.zip"},
+		{Name: "tool_1.2.3_windows_arm64.zip"},
+	}
+	rustAssets := []github.Asset{
+		{Name: "tool-v1.2.3-aarch64-apple-darwin.tar.gz"},
+		{Name: "tool-v1.2.3-arm-unknown-linux-gnueabihf.tar.gz"},
+		{Name: "tool-v1.2.3-i686-pc-windows-msvc.zip"},
+		{Name: "tool-v1.2.3-x86_64-apple-darwin.tar.gz"},
+		{Name: "tool-v1.2.3-x86_64-pc-windows-msvc.zip"},
+		{Name: "tool-v1.2.3-x86_64-unknown-linux-musl.tar.gz"},
+	}
+
+	testCases := []struct {
+		name     string
+		assets   []github.Asset
+		platform string
+		arch     string
+		expected string
+	}{
+		{name: "Windows is not darwin", assets: assets, platform: "windows", arch: "amd64", expected: "tool_1.2.3_windows_amd64.zip"},
+		{name: "Arm is not arm64", assets: assets, platform: "linux", arch: "arm", expected: "tool_1.2.3_linux_armv7.tar.gz"},
+		{name: "Linux 386", assets: assets, platform: "linux", arch: "386", expected: "tool_1.2.3_linux_386.tar.gz"},
+		{name: "Darwin arm64", assets: assets, platform: "darwin", arch: "arm64", expected: "tool_1.2.3_darwin_arm64.tar.gz"},
+		{name: "Target triple darwin amd64", assets: rustAssets, platform: "darwin", arch: "amd64", expected: "tool-v1.2.3-x86_64-apple-darwin.tar.gz"},
+		{name: "Target triple windows 386", assets: rustAssets, platform: "windows", arch: "386", expected: "tool-v1.2.3-i686-pc-windows-msvc.zip"},
+		{name: "Target triple windows amd64", assets: rustAssets, platform: "windows", arch: "amd64", expected: "tool-v1.2.3-x86_64-pc-windows-msvc.zip"},
+		{name: "Target triple linux amd64", assets: rustAssets, platform: "linux", arch: "amd64", expected: "tool-v1.2.3-x86_64-unknown-linux-musl.tar.gz"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			asset, err := selectAsset(tc.assets, "", "1.2.3", tc.platform, tc.arch)
+			if err != nil {
+				t.Fatalf("Did not expect an error but got: %v", err)
+			}
+			if asset.Name != tc.expected {
+				t.Errorf("Expected asset %s but got %s", tc.expected, asset.Name)
+			}
+		})
+	}
+
+	// An x86_64 asset is not one for 386
+	if asset, err := selectAsset(rustAssets, "", "1.2.3", "linux", "386"); err == nil {
+		t.Errorf("Expected an error but got asset %s", asset.Name)
+	}
+}
+
+func TestListReleasesRateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", "1700000000")
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	originalURL := github.APIURL
+	github.APIURL = server.URL
+	defer func() { github.APIURL = originalURL }()
+
+	_, err := github.ListReleases(context.Background(), nil, "owner/tool", "")
+	var rateLimitErr *github.RateLimitError
+	if !errors.As(err, &rateLimitErr) {
+		t.Fatalf("Expected a rate limit error but got %v", err)
+	}
+}
@@ -0,0 +1,219 @@
+package depman
+
+import (
+	"context"
+	"debug/buildinfo"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// goInstaller builds Go commands with `go install` into an isolated GOBIN,
+// keeping each version in the tools directory behind a shim like archive
+// installs do
+type goInstaller struct{}
+
+func init() {
+	installStrategies["go"] = goInstaller{}
+}
+
+// majorVersionSuffix matches the "/v2"-style suffix of major version module paths
+var majorVersionSuffix = regexp.MustCompile(`^v[0-9]+$`)
+
+// goModule returns the package path to install, defaulting to the package name
+func goModule(dep *Dependency, platformConfig *PlatformConfig) string {
+	if platformConfig.Installer.Module != "" {
+		return platformConfig.Installer.Module
+	}
+	return packageName(dep, platformConfig)
+}
+
+// goBinaryName returns the binary `go install` produces: the last element of
+// the package path that is not a major version suffix
+func goBinaryName(dep *Dependency, platformConfig *PlatformConfig, platform string) string {
+	binary := platformConfig.Installer.Binary
+	if binary == "" {
+		module := strings.TrimSuffix(goModule(dep, platformConfig), "/")
+		binary = path.Base(module)
+		if majorVersionSuffix.MatchString(binary) && path.Dir(module) != "." {
+			binary = path.Base(path.Dir(module))
+		}
+	}
+	if platform == "windows" && filepath.Ext(binary) == "" {
+		binary += ".exe"
+	}
+	return binary
+}
+
+// goEnv returns the environment of go commands, adding the configured GOFLAGS
+func goEnv(platformConfig *PlatformConfig, env ...string) []string {
+	if platformConfig.Installer.GoFlags != "" {
+		env = append(env, "GOFLAGS="+platformConfig.Installer.GoFlags)
+	}
+	return env
+}
+
+// resolveVersion returns the module version to request: the required one,
+// the newest one satisfying the constraint, or "latest"
+func (g goInstaller) resolveVersion(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) (string, error) {
+	if dep.Version.Required != "" && dep.Version.Constraint == "" {
+		return "v" + strings.TrimPrefix(dep.Version.Required, "v"), nil
+	}
+	if dep.Version.Required == "" && dep.Version.Constraint == "" {
+		return "latest", nil
+	}
+
+	candidates, err := g.availableVersions(ctx, m, dep, platformConfig)
+	if err != nil {
+		return "", err
+	}
+	i, _, err := dep.Version.Resolve(candidates)
+	if err != nil {
+		return "", err
+	}
+	return "v" + candidates[i], nil
+}
+
+// install runs `go install` into a temporary GOBIN and moves the binary into
+// the tools directory under the version embedded in it
+func (g goInstaller) install(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) (LockedArtifact, error) {
+	var artifact LockedArtifact
+
+	if _, err := lookPath("go"); err != nil {
+		return artifact, fmt.Errorf("go is not installed")
+	}
+	requested, err := g.resolveVersion(ctx, m, dep, platformConfig)
+	if err != nil {
+		return artifact, err
+	}
+
+	gobin, err := os.MkdirTemp("", "depman-gobin-*")
+	if err != nil {
+		return artifact, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(gobin)
+
+	module := goModule(dep, platformConfig) + "@" + requested
+	m.logger.Infof("Installing %s using command: go install %s", dep.Name, module)
+	if _, err := m.retryCommand(withCommandEnv(ctx, goEnv(platformConfig, "GOBIN="+gobin)...), dep, "go", "install", module); err != nil {
+		return artifact, fmt.Errorf("installation failed: %w", err)
+	}
+
+	binary := goBinaryName(dep, platformConfig, m.Platform)
+	source := filepath.Join(gobin, binary)
+	version, err := goBinaryVersion(source)
+	if err != nil {
+		return artifact, err
+	}
+	artifact.URL = goModule(dep, platformConfig) + "@v" + version
+	if version == "" {
+		artifact.URL = module
+		if requested != "latest" {
+			version = requested
+		}
+	}
+	version = toolVersion(version)
+
+	target := filepath.Join(m.toolVersionDir(dep.Name, version), binary)
+	if err := installBinary(source, target); err != nil {
+		return artifact, err
+	}
+	shim, err := m.writeShim(dep, binary, version)
+	if err != nil {
+		return artifact, err
+	}
+	m.trackFiles(dep.Name, target, shim)
+
+	m.logger.Infof("Successfully installed %s %s to %s (shim: %s)", dep.Name, version, target, shim)
+	return artifact, nil
+}
+
+// goBinaryVersion reads the main module version embedded in a Go binary,
+// or "" for binaries built from a local checkout
+func goBinaryVersion(file string) (string, error) {
+	info, err := buildinfo.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to read build information of %s: %w", file, err)
+	}
+	if info.Main.Version == "(devel)" {
+		return "", nil
+	}
+	return strings.TrimPrefix(info.Main.Version, "v"), nil
+}
+
+// concurrent reports that go installs only touch their own files
+func (goInstaller) concurrent() bool {
+	return true
+}
+
+// binaryName returns the name of the binary kept for each installed version
+func (goInstaller) binaryName(m *Manager, dep *Dependency, platformConfig *PlatformConfig) string {
+	return goBinaryName(dep, platformConfig, m.Platform)
+}
+
+// uninstall deletes the shim and every installed version of the tool
+func (goInstaller) uninstall(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) error {
+	return m.removeTool(dep, goBinaryName(dep, platformConfig, m.Platform))
+}
+
+// detectVersion reads the module version embedded in the binary the shim
+// runs, without executing it
+func (goInstaller) detectVersion(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) (string, error) {
+	_, active, err := m.InstalledVersions(dep.Name)
+	if err != nil {
+		return "", err
+	}
+	if active == "" {
+		return "", fmt.Errorf("%s is not installed in %s", dep.Name, m.ToolsDir())
+	}
+	if override := os.Getenv(versionOverrideVar(dep.Name)); override != "" {
+		active = override
+	}
+
+	file := filepath.Join(m.toolVersionDir(dep.Name, active), goBinaryName(dep, platformConfig, m.Platform))
+	if _, err := os.Stat(file); err != nil {
+		return "", fmt.Errorf("%s %s is not installed in %s", dep.Name, active, m.ToolsDir())
+	}
+	version, err := goBinaryVersion(file)
+	if err != nil {
+		return "", err
+	}
+	if version == "" {
+		// Binaries without a module version are reported by their directory
+		return active, nil
+	}
+	return version, nil
+}
+
+// availableVersions lists the module's tagged versions with `go list -m`,
+// trying the package path and then its parents to find the module root
+func (goInstaller) availableVersions(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) ([]string, error) {
+	if _, err := lookPath("go"); err != nil {
+		return nil, fmt.Errorf("go is not installed")
+	}
+
+	ctx = withCommandEnv(ctx, goEnv(platformConfig)...)
+	var lastErr error
+	for module := goModule(dep, platformConfig); module != "." && module != "/"; module = path.Dir(module) {
+		output, err := m.retryCommand(ctx, dep, "go", "list", "-m", "-versions", module)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		// Output: module v1.0.0 v1.1.0 ...
+		fields := strings.Fields(output)
+		if len(fields) < 2 {
+			lastErr = fmt.Errorf("module %s has no tagged versions", module)
+			continue
+		}
+		versions := make([]string, 0, len(fields)-1)
+		for _, version := range fields[1:] {
+			versions = append(versions, strings.TrimPrefix(version, "v"))
+		}
+		return versions, nil
+	}
+	return nil, fmt.Errorf("failed to list versions of %s: %w", goModule(dep, platformConfig), lastErr)
+}
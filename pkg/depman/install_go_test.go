@@ -0,0 +1,116 @@
+package depman
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGoBinaryName(t *testing.T) {
+	testCases := []struct {
+		module   string
+		binary   string
+		platform string
+		expected string
+	}{
+		{"golang.org/x/tools/gopls", "", "linux", "gopls"},
+		{"github.com/example/tool/v2", "", "linux", "tool"},
+		{"github.com/example/tool/v2/cmd/tool-cli", "", "windows", "tool-cli.exe"},
+		{"github.com/example/tool", "tl", "linux", "tl"},
+	}
+
+	for _, tc := range testCases {
+		platformConfig := &PlatformConfig{Installer: Installer{Module: tc.module, Binary: tc.binary}}
+		if got := goBinaryName(&Dependency{Name: "tool"}, platformConfig, tc.platform); got != tc.expected {
+			t.Errorf("Expected binary %s for %s but got %s", tc.expected, tc.module, got)
+		}
+	}
+}
+
+func TestGoInstaller(t *testing.T) {
+	// The test binary stands in for the built command, as it embeds build information
+	executable, err := os.Executable()
+	if err != nil {
+		t.Fatalf("Failed to find the test binary: %v", err)
+	}
+	embedded, err := goBinaryVersion(executable)
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+
+	homeDir, err := os.MkdirTemp("", "depman-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(homeDir)
+
+	var env []string
+	originalRun, originalLook := runCommand, lookPath
+	runCommand = func(ctx context.Context, name string, args ...string) (string, error) {
+		line := strings.Join(append([]string{name}, args...), " ")
+		if line != "go install example.com/tool/cmd/tool@v1.2.3" {
+			return "", fmt.Errorf("unexpected command: %s", line)
+		}
+		env = commandEnv(ctx)
+		gobin := strings.TrimPrefix(env[0], "GOBIN=")
+		return "", installBinary(executable, filepath.Join(gobin, "tool"))
+	}
+	lookPath = func(file string) (string, error) {
+		return file, nil
+	}
+	defer func() { runCommand, lookPath = originalRun, originalLook }()
+
+	dep := &Dependency{Name: "tool", Version: Version{Required: "1.2.3"}}
+	platformConfig := &PlatformConfig{Installer: Installer{Method: "go", Module: "example.com/tool/cmd/tool", GoFlags: "-trimpath"}}
+	manager := &Manager{Platform: "linux", homeDir: homeDir, logger: &mockLogger{}}
+
+	if _, err := (goInstaller{}).install(context.Background(), manager, dep, platformConfig); err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if len(env) != 2 || !strings.HasPrefix(env[0], "GOBIN=") || env[1] != "GOFLAGS=-trimpath" {
+		t.Errorf("Expected an isolated GOBIN and GOFLAGS but got %v", env)
+	}
+
+	expected := embedded
+	if expected == "" {
+		expected = "1.2.3"
+	}
+	installed := filepath.Join(manager.ToolsDir(), "tool", toolVersion(expected), "tool")
+	if _, err := os.Stat(installed); err != nil {
+		t.Errorf("Expected versioned binary at %s: %v", installed, err)
+	}
+	if _, err := os.Stat(filepath.Join(manager.BinDir(), "tool")); err != nil {
+		t.Errorf("Expected a shim in the bin directory: %v", err)
+	}
+
+	version, err := (goInstaller{}).detectVersion(context.Background(), manager, dep, platformConfig)
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if version != toolVersion(expected) {
+		t.Errorf("Expected version %s but got %s", toolVersion(expected), version)
+	}
+}
+
+func TestGoAvailableVersions(t *testing.T) {
+	calls := fakeCommands(t, map[string]string{
+		"go list -m -versions example.com/tool": "example.com/tool v1.0.0 v1.2.0",
+	})
+	manager := &Manager{Platform: "linux", logger: &mockLogger{}}
+	platformConfig := &PlatformConfig{Installer: Installer{Module: "example.com/tool/cmd/tool"}}
+
+	versions, err := (goInstaller{}).availableVersions(context.Background(), manager, &Dependency{Name: "tool"}, platformConfig)
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if expected := []string{"1.0.0", "1.2.0"}; !reflect.DeepEqual(versions, expected) {
+		t.Errorf("Expected versions %v but got %v", expected, versions)
+	}
+	if len(*calls) != 3 {
+		t.Errorf("Expected the package path and its parents to be tried but got %v", *calls)
+	}
+}
@@ -0,0 +1,155 @@
+package depman
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// imageMethod is the install method of image dependencies
+const imageMethod = "image"
+
+// imageRuntimes are the container runtimes images can be pulled with, in order of preference
+var imageRuntimes = []string{"docker", "podman", "nerdctl"}
+
+// imageDigestPattern matches pinned image manifest digests
+var imageDigestPattern = regexp.MustCompile(`^sha256:[0-9a-f]{64}$`)
+
+// imageInstaller pulls container images at their pinned digest
+type imageInstaller struct{}
+
+func init() {
+	installStrategies[imageMethod] = imageInstaller{}
+}
+
+// validate checks that an image names a reference, a valid digest and a known runtime
+func (s ImageSpec) validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("missing image name")
+	}
+	if strings.Contains(s.Name, "@") {
+		return fmt.Errorf("image name '%s' must not contain a digest; set image.digest instead", s.Name)
+	}
+	if !imageDigestPattern.MatchString(s.Digest) {
+		return fmt.Errorf("invalid image digest '%s' (expected sha256:<64 hex digits>)", s.Digest)
+	}
+	if s.Runtime != "" && !containsString(imageRuntimes, s.Runtime) {
+		return fmt.Errorf("unknown container runtime '%s' (expected one of %s)", s.Runtime, strings.Join(imageRuntimes, ", "))
+	}
+	return nil
+}
+
+// repository returns the image name without its tag
+func (s ImageSpec) repository() string {
+	// A colon after the last slash starts the tag; earlier ones belong to a registry port
+	if i := strings.LastIndex(s.Name, ":"); i > strings.LastIndex(s.Name, "/") {
+		return s.Name[:i]
+	}
+	return s.Name
+}
+
+// pinnedRef returns the reference pulling exactly the pinned image, e.g. "postgres@sha256:..."
+func (s ImageSpec) pinnedRef() string {
+	return s.repository() + "@" + s.Digest
+}
+
+// findImageRuntime returns the container runtime to use: the configured one,
+// or the first of imageRuntimes that is installed
+func findImageRuntime(ctx context.Context, runtime string) (string, error) {
+	if runtime != "" {
+		if _, err := hostLookPath(ctx, runtime); err != nil {
+			return "", fmt.Errorf("container runtime %s is not installed", runtime)
+		}
+		return runtime, nil
+	}
+	for _, candidate := range imageRuntimes {
+		if _, err := hostLookPath(ctx, candidate); err == nil {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("no container runtime found (install one of %s)", strings.Join(imageRuntimes, ", "))
+}
+
+// install pulls the image at its digest and points its tag at it
+func (imageInstaller) install(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) (LockedArtifact, error) {
+	var artifact LockedArtifact
+
+	spec := dep.Image
+	if err := spec.validate(); err != nil {
+		return artifact, err
+	}
+	runtime, err := findImageRuntime(ctx, spec.Runtime)
+	if err != nil {
+		return artifact, err
+	}
+
+	ref := spec.pinnedRef()
+	m.logger.Infof("Running %s pull %s", runtime, ref)
+	if _, err := m.retryCommand(ctx, dep, runtime, "pull", ref); err != nil {
+		return artifact, fmt.Errorf("pull failed: %w", err)
+	}
+
+	// Images pulled by digest are untagged; tools refer to them by tag
+	if spec.Name != spec.repository() {
+		if _, err := runCommand(ctx, runtime, "tag", ref, spec.Name); err != nil {
+			return artifact, fmt.Errorf("failed to tag %s: %w", spec.Name, err)
+		}
+	}
+
+	m.logger.Infof("Successfully pulled %s", dep.Name)
+	artifact.URL = "image:" + ref
+	return artifact, nil
+}
+
+// uninstall removes the pinned image and its tag
+func (imageInstaller) uninstall(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) error {
+	spec := dep.Image
+	runtime, err := findImageRuntime(ctx, spec.Runtime)
+	if err != nil {
+		return err
+	}
+
+	m.logger.Infof("Running %s rmi %s", runtime, spec.Name)
+	if _, err := runCommand(ctx, runtime, "rmi", spec.Name, spec.pinnedRef()); err != nil {
+		return fmt.Errorf("uninstallation failed: %w", err)
+	}
+	return nil
+}
+
+// detectVersion reports the pinned digest if the image's tag points at it
+func (imageInstaller) detectVersion(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) (string, error) {
+	spec := dep.Image
+	if err := spec.validate(); err != nil {
+		return "", err
+	}
+	runtime, err := findImageRuntime(ctx, spec.Runtime)
+	if err != nil {
+		return "", err
+	}
+
+	output, err := runCommand(ctx, runtime, "image", "inspect", "--format", "{{range .RepoDigests}}{{println .}}{{end}}", spec.Name)
+	if err != nil {
+		return "", fmt.Errorf("%s is not pulled: %w", spec.Name, err)
+	}
+
+	digests := parseRepoDigests(output)
+	if containsString(digests, spec.Digest) {
+		return spec.Digest, nil
+	}
+	if len(digests) == 0 {
+		return "", fmt.Errorf("%s has no registry digest; it was built locally", spec.Name)
+	}
+	return "", fmt.Errorf("%s is at %s, expected %s", spec.Name, strings.Join(digests, ", "), spec.Digest)
+}
+
+// parseRepoDigests returns the digests of "repository@digest" lines printed by `image inspect`
+func parseRepoDigests(output string) []string {
+	var digests []string
+	for _, line := range strings.Split(output, "\n") {
+		if _, digest, ok := strings.Cut(strings.TrimSpace(line), "@"); ok {
+			digests = append(digests, digest)
+		}
+	}
+	return digests
+}
@@ -0,0 +1,83 @@
+package depman
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+const testImageDigest = "sha256:4aea012537edfad80f98d870a36e6b90b4c09b27be7f4b4759d72db863baeebb"
+
+func TestImageSpecRefs(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected string
+	}{
+		{"postgres:16.2", "postgres@" + testImageDigest},
+		{"postgres", "postgres@" + testImageDigest},
+		{"localhost:5000/team/tool:1.0", "localhost:5000/team/tool@" + testImageDigest},
+		{"localhost:5000/team/tool", "localhost:5000/team/tool@" + testImageDigest},
+	}
+
+	for _, tc := range testCases {
+		spec := ImageSpec{Name: tc.name, Digest: testImageDigest}
+		if got := spec.pinnedRef(); got != tc.expected {
+			t.Errorf("Expected %s but got %s", tc.expected, got)
+		}
+	}
+}
+
+func TestImageInstaller(t *testing.T) {
+	dep := &Dependency{Name: "postgres", Kind: "image", Image: ImageSpec{Name: "postgres:16.2", Digest: testImageDigest}}
+	manager := &Manager{Platform: "linux", logger: &mockLogger{}}
+	platformConfig, _ := kindPlatformConfig(dep)
+	inspect := "docker image inspect --format {{range .RepoDigests}}{{println .}}{{end}} postgres:16.2"
+
+	t.Run("Pull and tag", func(t *testing.T) {
+		calls := fakeCommands(t, map[string]string{
+			"docker pull postgres@" + testImageDigest:                   "",
+			"docker tag postgres@" + testImageDigest + " postgres:16.2": "",
+		})
+
+		artifact, err := (imageInstaller{}).install(context.Background(), manager, dep, platformConfig)
+		if err != nil {
+			t.Fatalf("Did not expect an error but got: %v", err)
+		}
+		if artifact.URL != "image:postgres@"+testImageDigest {
+			t.Errorf("Expected the pinned reference to be recorded but got %s", artifact.URL)
+		}
+		if len(*calls) != 2 {
+			t.Errorf("Expected a pull and a tag but got %v", *calls)
+		}
+	})
+
+	t.Run("Digest matches", func(t *testing.T) {
+		fakeCommands(t, map[string]string{inspect: "postgres@sha256:0000\npostgres@" + testImageDigest})
+
+		version, err := (imageInstaller{}).detectVersion(context.Background(), manager, dep, platformConfig)
+		if err != nil {
+			t.Fatalf("Did not expect an error but got: %v", err)
+		}
+		if version != testImageDigest {
+			t.Errorf("Expected %s but got %s", testImageDigest, version)
+		}
+	})
+
+	t.Run("Digest differs", func(t *testing.T) {
+		fakeCommands(t, map[string]string{inspect: "postgres@sha256:0000"})
+
+		_, err := (imageInstaller{}).detectVersion(context.Background(), manager, dep, platformConfig)
+		if err == nil || !strings.Contains(err.Error(), "expected "+testImageDigest) {
+			t.Errorf("Expected a digest mismatch but got: %v", err)
+		}
+	})
+
+	t.Run("Not pulled", func(t *testing.T) {
+		fakeCommands(t, map[string]string{})
+
+		status, err := manager.VerifyDependency(context.Background(), dep)
+		if err == nil || status.Installed {
+			t.Errorf("Expected the image to be missing but got %+v", status)
+		}
+	})
+}
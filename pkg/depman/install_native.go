@@ -0,0 +1,293 @@
+package depman
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// Windows Installer exit codes that do not indicate a failed install
+const (
+	msiRebootInitiated    = 1641 // Install succeeded and a restart was started
+	msiRebootRequired     = 3010 // Install succeeded, restart required
+	msiInstallInProgress  = 1618 // Another installation is already running
+	msiElevationRequired  = 1625 // Policy prevents the install without elevation
+	msiAlreadyUninstalled = 1605 // The product is not installed
+)
+
+// nativeInstaller downloads a vendor's MSI, pkg or dmg installer and runs it
+// unattended, then checks that the install path exists with the right version
+type nativeInstaller struct{}
+
+func init() {
+	installStrategies["native"] = nativeInstaller{}
+}
+
+// nativeFormat returns the installer format, defaulting to the extension of
+// the download URL
+func nativeFormat(platformConfig *PlatformConfig, url string) (string, error) {
+	format := strings.ToLower(platformConfig.Installer.Format)
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(path.Ext(strings.SplitN(url, "?", 2)[0])), ".")
+	}
+	switch format {
+	case "msi", "pkg", "dmg":
+		return format, nil
+	case "":
+		return "", fmt.Errorf("cannot tell the installer format of %s; set installer.format", url)
+	default:
+		return "", fmt.Errorf("unsupported native installer format: %s (expected msi, pkg or dmg)", format)
+	}
+}
+
+// install downloads the installer, runs it for its format and verifies the result
+func (n nativeInstaller) install(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) (LockedArtifact, error) {
+	var artifact LockedArtifact
+
+	if platformConfig.Installer.URL == "" {
+		return artifact, fmt.Errorf("no download URL provided for dependency: %s", dep.Name)
+	}
+	url := expandURLTemplate(platformConfig.Installer.URL, dep, m.Platform, m.targetArch())
+	format, err := nativeFormat(platformConfig, url)
+	if err != nil {
+		return artifact, err
+	}
+
+	tempDir, err := os.MkdirTemp("", "depman-download-*")
+	if err != nil {
+		return artifact, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	result, err := m.downloadArtifact(ctx, dep, platformConfig, url, platformConfig.Installer.Checksum, tempDir)
+	if err != nil {
+		return artifact, err
+	}
+	artifact.URL = url
+	artifact.Checksum = result.Checksum
+
+	switch format {
+	case "msi":
+		err = installMSI(ctx, m, dep, platformConfig, result.FilePath)
+	case "pkg":
+		err = installPKG(ctx, m, dep, platformConfig, result.FilePath)
+	case "dmg":
+		err = installDMG(ctx, m, dep, platformConfig, result.FilePath)
+	}
+	if err != nil {
+		return artifact, fmt.Errorf("installation failed: %w", err)
+	}
+
+	if err := n.verifyInstall(ctx, m, dep, platformConfig); err != nil {
+		return artifact, err
+	}
+
+	m.logger.Infof("Successfully installed %s", dep.Name)
+	return artifact, nil
+}
+
+// verifyInstall checks that the installer created the install path and that
+// the version it reports meets the dependency's requirements
+func (n nativeInstaller) verifyInstall(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) error {
+	installPath := platformConfig.Installer.InstallPath
+	if installPath == "" {
+		return nil
+	}
+	if _, err := os.Stat(installPath); err != nil {
+		return fmt.Errorf("installer for %s finished but %s does not exist", dep.Name, installPath)
+	}
+	if dep.Version.Required == "" && dep.Version.Constraint == "" {
+		return nil
+	}
+
+	version, err := n.detectVersion(ctx, m, dep, platformConfig)
+	if err != nil {
+		m.logger.Debugf("Skipping version verification of %s: %v", dep.Name, err)
+		return nil
+	}
+	if _, err := semver.NewVersion(version); err != nil {
+		m.logger.Warnf("Cannot compare version %s of %s with its requirements", version, dep.Name)
+		return nil
+	}
+	if _, _, err := dep.Version.Resolve([]string{version}); err != nil {
+		return fmt.Errorf("installer for %s installed version %s, which does not meet %s", dep.Name, version, dep.Version.Target())
+	}
+	return nil
+}
+
+// installMSI runs the package with msiexec silently, without restarting
+func installMSI(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig, file string) error {
+	args := append([]string{"/i", file, "/qn", "/norestart"}, platformConfig.Installer.Args...)
+	m.logger.Infof("Running msiexec %s", strings.Join(args, " "))
+	if _, err := m.retryCommand(ctx, dep, "msiexec", args...); err != nil {
+		return msiResult(m, dep.Name, err)
+	}
+	return nil
+}
+
+// msiResult maps Windows Installer exit codes to success or a descriptive error
+func msiResult(m *Manager, name string, err error) error {
+	switch exitCode(err) {
+	case msiRebootInitiated, msiRebootRequired:
+		m.logger.Warnf("%s was installed but Windows must be restarted to finish", name)
+		return nil
+	case msiInstallInProgress:
+		return fmt.Errorf("another installation is in progress; try again once it finishes: %w", err)
+	case msiElevationRequired:
+		return fmt.Errorf("the installer needs administrator rights; run depman from an elevated prompt: %w", err)
+	default:
+		return err
+	}
+}
+
+// installPKG runs the package with installer(8) on the boot volume, which
+// needs root privileges
+func installPKG(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig, file string) error {
+	command, err := m.elevate(append([]string{"installer", "-pkg", file, "-target", "/"}, platformConfig.Installer.Args...))
+	if err != nil {
+		return err
+	}
+	m.logger.Infof("Running %s", strings.Join(command, " "))
+	_, err = m.retryCommand(ctx, dep, command[0], command[1:]...)
+	return err
+}
+
+// installDMG mounts the disk image and copies the install path's bundle out
+// of it, or runs the single pkg it contains
+func installDMG(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig, file string) error {
+	mountPoint, err := os.MkdirTemp("", "depman-dmg-*")
+	if err != nil {
+		return fmt.Errorf("failed to create mount point: %w", err)
+	}
+	defer os.Remove(mountPoint)
+
+	if _, err := runCommand(ctx, "hdiutil", "attach", file, "-nobrowse", "-readonly", "-noautoopen", "-mountpoint", mountPoint); err != nil {
+		return fmt.Errorf("failed to mount %s: %w", filepath.Base(file), err)
+	}
+	defer func() {
+		if _, err := runCommand(ctx, "hdiutil", "detach", mountPoint, "-force"); err != nil {
+			m.logger.Warnf("Failed to unmount %s: %v", mountPoint, err)
+		}
+	}()
+
+	installPath := platformConfig.Installer.InstallPath
+	if installPath != "" {
+		source := filepath.Join(mountPoint, filepath.Base(installPath))
+		if _, err := os.Stat(source); err == nil {
+			// Replace an older copy rather than merging into it
+			if err := os.RemoveAll(installPath); err != nil {
+				return fmt.Errorf("failed to remove %s: %w", installPath, err)
+			}
+			m.logger.Infof("Copying %s to %s", filepath.Base(installPath), installPath)
+			if _, err := runCommand(ctx, "ditto", source, installPath); err != nil {
+				return fmt.Errorf("failed to copy %s: %w", filepath.Base(installPath), err)
+			}
+			return nil
+		}
+	}
+
+	packages, _ := filepath.Glob(filepath.Join(mountPoint, "*.pkg"))
+	if len(packages) != 1 {
+		return fmt.Errorf("disk image contains neither %s nor a single installer package", filepath.Base(installPath))
+	}
+	return installPKG(ctx, m, dep, platformConfig, packages[0])
+}
+
+// uninstall runs the platform's uninstall command if one is configured, and
+// otherwise removes the MSI product, the pkg receipt and the install path
+func (nativeInstaller) uninstall(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) error {
+	if len(platformConfig.Commands.Uninstall) > 0 {
+		return commandInstaller{}.uninstall(ctx, m, dep, platformConfig)
+	}
+
+	format, err := nativeFormat(platformConfig, expandURLTemplate(platformConfig.Installer.URL, dep, m.Platform, m.targetArch()))
+	if err != nil {
+		return err
+	}
+	product := platformConfig.Installer.Package
+	installPath := platformConfig.Installer.InstallPath
+
+	switch {
+	case format == "msi" && product != "":
+		m.logger.Infof("Running msiexec /x %s", product)
+		if _, err := runCommand(ctx, "msiexec", "/x", product, "/qn", "/norestart"); err != nil {
+			if exitCode(err) == msiAlreadyUninstalled {
+				return nil
+			}
+			if err := msiResult(m, dep.Name, err); err != nil {
+				return fmt.Errorf("uninstallation failed: %w", err)
+			}
+		}
+		return nil
+	case format != "msi" && installPath != "":
+		command := []string{"rm", "-rf", installPath}
+		if format == "pkg" {
+			// Packages install as root, so their files need root to remove
+			if command, err = m.elevate(command); err != nil {
+				return err
+			}
+		}
+		m.logger.Infof("Removing %s", installPath)
+		if _, err := runCommand(ctx, command[0], command[1:]...); err != nil {
+			return fmt.Errorf("uninstallation failed: %w", err)
+		}
+		if format == "pkg" && product != "" {
+			if forget, err := m.elevate([]string{"pkgutil", "--forget", product}); err == nil {
+				runCommand(ctx, forget[0], forget[1:]...)
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("no uninstall command provided for dependency: %s", dep.Name)
+	}
+}
+
+// detectVersion reads the version of the install path: an app bundle's
+// Info.plist, a Windows file's version resource, or the pkg receipt
+func (nativeInstaller) detectVersion(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) (string, error) {
+	installPath := platformConfig.Installer.InstallPath
+	if installPath == "" {
+		return "", fmt.Errorf("no verification command or install path provided for dependency: %s", dep.Name)
+	}
+	if _, err := os.Stat(installPath); err != nil {
+		return "", fmt.Errorf("%s is not installed: %s does not exist", dep.Name, installPath)
+	}
+
+	var output string
+	var err error
+	switch {
+	case strings.HasSuffix(installPath, ".app"):
+		plist := filepath.Join(installPath, "Contents", "Info.plist")
+		output, err = runCommand(ctx, "plutil", "-extract", "CFBundleShortVersionString", "raw", "-o", "-", plist)
+	case m.Platform == "windows":
+		script := fmt.Sprintf("(Get-Item -LiteralPath '%s').VersionInfo.ProductVersion", strings.ReplaceAll(installPath, "'", "''"))
+		output, err = runCommand(ctx, "powershell", "-NoProfile", "-NonInteractive", "-Command", script)
+	case platformConfig.Installer.Package != "":
+		output, err = runCommand(ctx, "pkgutil", "--pkg-info", platformConfig.Installer.Package)
+		output = parsePkgInfoVersion(output)
+	default:
+		return "", fmt.Errorf("cannot read the version of %s; add a verify command", installPath)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read the version of %s: %w", installPath, err)
+	}
+	if output = strings.TrimSpace(output); output == "" {
+		return "", fmt.Errorf("%s has no version information", installPath)
+	}
+	return output, nil
+}
+
+// parsePkgInfoVersion reads the "version: 1.2.3" line of `pkgutil --pkg-info` output
+func parsePkgInfoVersion(output string) string {
+	for _, line := range strings.Split(output, "\n") {
+		if version, ok := strings.CutPrefix(strings.TrimSpace(line), "version:"); ok {
+			return strings.TrimSpace(version)
+		}
+	}
+	return ""
+}
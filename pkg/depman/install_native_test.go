@@ -0,0 +1,105 @@
+package depman
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNativeFormat(t *testing.T) {
+	testCases := []struct {
+		url      string
+		format   string
+		expected string
+		err      bool
+	}{
+		{url: "https://example.com/Tool-1.2.3.msi", expected: "msi"},
+		{url: "https://example.com/Tool.PKG?token=abc", expected: "pkg"},
+		{url: "https://example.com/download", format: "dmg", expected: "dmg"},
+		{url: "https://example.com/download", err: true},
+		{url: "https://example.com/tool.exe", err: true},
+	}
+
+	for _, tc := range testCases {
+		got, err := nativeFormat(&PlatformConfig{Installer: Installer{Format: tc.format}}, tc.url)
+		if tc.err {
+			if err == nil {
+				t.Errorf("Expected an error for %s but got format %s", tc.url, got)
+			}
+			continue
+		}
+		if err != nil || got != tc.expected {
+			t.Errorf("Expected format %s for %s but got %s (%v)", tc.expected, tc.url, got, err)
+		}
+	}
+}
+
+func TestParsePkgInfoVersion(t *testing.T) {
+	output := "package-id: com.example.tool\nversion: 2.4.1\nvolume: /\nlocation: /\ninstall-time: 1718000000\n"
+	if got := parsePkgInfoVersion(output); got != "2.4.1" {
+		t.Errorf("Expected version 2.4.1 but got %s", got)
+	}
+}
+
+func TestNativeInstallerPKG(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("xar!"))
+	}))
+	defer server.Close()
+
+	homeDir := t.TempDir()
+	installPath := filepath.Join(t.TempDir(), "tool")
+
+	var calls []string
+	originalRun, originalRoot := runCommand, isRoot
+	runCommand = func(ctx context.Context, name string, args ...string) (string, error) {
+		calls = append(calls, strings.Join(append([]string{name}, args...), " "))
+		switch name {
+		case "installer":
+			// Stand in for the package placing its files
+			return "installer: The install was successful.", os.WriteFile(installPath, nil, 0755)
+		case "pkgutil":
+			return "package-id: com.example.tool\nversion: 2.4.1\n", nil
+		}
+		return "", nil
+	}
+	isRoot = func() bool { return true }
+	defer func() { runCommand, isRoot = originalRun, originalRoot }()
+
+	manager := &Manager{Platform: "darwin", homeDir: homeDir, logger: &mockLogger{}}
+	platformConfig := &PlatformConfig{Installer: Installer{
+		Method:      "native",
+		URL:         server.URL + "/tool-{{version}}.pkg",
+		Package:     "com.example.tool",
+		InstallPath: installPath,
+	}}
+
+	dep := &Dependency{Name: "tool", Version: Version{Required: "2.4.1"}}
+	artifact, err := (nativeInstaller{}).install(context.Background(), manager, dep, platformConfig)
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if artifact.URL != server.URL+"/tool-2.4.1.pkg" {
+		t.Errorf("Expected the expanded URL to be recorded but got %s", artifact.URL)
+	}
+	if len(calls) == 0 || !strings.HasPrefix(calls[0], "installer -pkg ") || !strings.HasSuffix(calls[0], " -target /") {
+		t.Errorf("Expected installer(8) to run on the boot volume but got %v", calls)
+	}
+
+	// The installed version must meet the requirements
+	dep = &Dependency{Name: "tool", Version: Version{Required: "2.5.0"}}
+	if _, err := (nativeInstaller{}).install(context.Background(), manager, dep, platformConfig); err == nil {
+		t.Error("Expected an error when the installed version does not match but got none")
+	}
+
+	// The install path must exist afterwards
+	os.Remove(installPath)
+	platformConfig.Installer.InstallPath = filepath.Join(filepath.Dir(installPath), "missing")
+	if _, err := (nativeInstaller{}).install(context.Background(), manager, &Dependency{Name: "tool"}, platformConfig); err == nil {
+		t.Error("Expected an error when the install path is missing but got none")
+	}
+}
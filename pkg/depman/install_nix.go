@@ -0,0 +1,252 @@
+package depman
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// nixLocations are the standard nix executables checked when nix is not on PATH
+var nixLocations = []string{
+	"/nix/var/nix/profiles/default/bin/nix", // Multi-user installs
+	"/run/current-system/sw/bin/nix",        // NixOS
+}
+
+// nixFeatures enables the commands used here on installs that have not
+// turned them on in nix.conf
+var nixFeatures = []string{"--extra-experimental-features", "nix-command flakes"}
+
+// nixInstaller installs packages into the user's nix profile from nixpkgs or
+// a pinned flake reference
+type nixInstaller struct{}
+
+func init() {
+	installStrategies["nix"] = nixInstaller{}
+}
+
+// install adds the flake reference to the profile, replacing an element for
+// the same package so a changed pin takes effect
+func (n nixInstaller) install(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) (LockedArtifact, error) {
+	var artifact LockedArtifact
+
+	nix, err := findNix()
+	if err != nil {
+		return artifact, err
+	}
+
+	ref := nixFlakeRef(dep, platformConfig)
+	if element, err := findNixElement(nixFlakeAttr(ref)); err == nil {
+		m.logger.Infof("Removing %s from the nix profile before installing %s", element.name, ref)
+		if _, err := runCommand(ctx, nix, nixArgs("profile", "remove", element.name)...); err != nil {
+			return artifact, fmt.Errorf("failed to replace %s in the nix profile: %w", element.name, err)
+		}
+	}
+
+	m.logger.Infof("Running nix profile install %s", ref)
+	args := append(nixArgs("profile", "install", ref), platformConfig.Installer.Args...)
+	if _, err := m.retryCommand(ctx, dep, nix, args...); err != nil {
+		return artifact, fmt.Errorf("installation failed: %w", err)
+	}
+
+	m.logger.Infof("Successfully installed %s", dep.Name)
+	artifact.URL = "nix:" + ref
+	if element, err := findNixElement(nixFlakeAttr(ref)); err == nil && element.URL != "" {
+		// Record the locked flake the profile resolved the reference to
+		artifact.URL = "nix:" + element.URL + "#" + element.AttrPath
+	}
+	return artifact, nil
+}
+
+// uninstall removes the package's element from the profile
+func (nixInstaller) uninstall(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) error {
+	nix, err := findNix()
+	if err != nil {
+		return err
+	}
+
+	element, err := findNixElement(nixFlakeAttr(nixFlakeRef(dep, platformConfig)))
+	if err != nil {
+		return err
+	}
+
+	m.logger.Infof("Running nix profile remove %s", element.name)
+	if _, err := runCommand(ctx, nix, nixArgs("profile", "remove", element.name)...); err != nil {
+		return fmt.Errorf("uninstallation failed: %w", err)
+	}
+	return nil
+}
+
+// detectVersion reads the installed version from the profile manifest
+// instead of running nix, which is slow to start
+func (nixInstaller) detectVersion(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) (string, error) {
+	element, err := findNixElement(nixFlakeAttr(nixFlakeRef(dep, platformConfig)))
+	if err != nil {
+		return "", err
+	}
+	for _, storePath := range element.StorePaths {
+		if version := nixStorePathVersion(storePath); version != "" {
+			return version, nil
+		}
+	}
+	return "", fmt.Errorf("could not determine the version of %s from its store paths", element.name)
+}
+
+// availableVersions evaluates the version the flake reference currently provides
+func (nixInstaller) availableVersions(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) ([]string, error) {
+	nix, err := findNix()
+	if err != nil {
+		return nil, err
+	}
+
+	ref := nixFlakeRef(dep, platformConfig)
+	output, err := m.retryCommand(ctx, dep, nix, nixArgs("eval", "--raw", ref+".version")...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate %s: %w", ref, err)
+	}
+	return []string{strings.TrimSpace(output)}, nil
+}
+
+// findNix locates the nix executable
+func findNix() (string, error) {
+	if path, err := lookPath("nix"); err == nil {
+		return path, nil
+	}
+
+	for _, path := range nixLocations {
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+
+	return "", fmt.Errorf("nix is not installed")
+}
+
+// nixArgs builds the arguments for a nix subcommand with flakes enabled
+func nixArgs(args ...string) []string {
+	return append(append([]string{}, nixFeatures...), args...)
+}
+
+// nixFlakeRef returns the flake reference to install, defaulting to the
+// package in the registry's nixpkgs
+func nixFlakeRef(dep *Dependency, platformConfig *PlatformConfig) string {
+	if platformConfig.Installer.Flake != "" {
+		return platformConfig.Installer.Flake
+	}
+	return "nixpkgs#" + packageName(dep, platformConfig)
+}
+
+// nixFlakeAttr returns the attribute a flake reference selects, e.g. "jq" for
+// "nixpkgs#jq" or "ripgrep" for "github:org/flake#packages.x86_64-linux.ripgrep"
+func nixFlakeAttr(ref string) string {
+	_, attr, found := strings.Cut(ref, "#")
+	if !found || attr == "" {
+		return "default"
+	}
+	return attr[strings.LastIndex(attr, ".")+1:]
+}
+
+// nixElement is one package installed in a nix profile
+type nixElement struct {
+	name       string   // Name or index that `nix profile remove` accepts
+	AttrPath   string   `json:"attrPath"`
+	URL        string   `json:"url"`
+	StorePaths []string `json:"storePaths"`
+}
+
+// nixManifestPaths returns the locations of the user's profile manifest, in
+// the order nix prefers them
+func nixManifestPaths() []string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	stateDir := os.Getenv("XDG_STATE_HOME")
+	if stateDir == "" {
+		stateDir = filepath.Join(home, ".local", "state")
+	}
+	return []string{
+		filepath.Join(home, ".nix-profile", "manifest.json"),
+		filepath.Join(stateDir, "nix", "profiles", "profile", "manifest.json"),
+	}
+}
+
+// findNixElement returns the profile element installing the given attribute
+func findNixElement(attr string) (nixElement, error) {
+	for _, path := range nixManifestPaths() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		elements, err := parseNixManifest(data)
+		if err != nil {
+			return nixElement{}, fmt.Errorf("failed to read nix profile %s: %w", path, err)
+		}
+		for _, element := range elements {
+			if element.name == attr || nixFlakeAttr("#"+element.AttrPath) == attr {
+				return element, nil
+			}
+		}
+		return nixElement{}, fmt.Errorf("%s is not installed in the nix profile", attr)
+	}
+	return nixElement{}, fmt.Errorf("%s is not installed: no nix profile found", attr)
+}
+
+// parseNixManifest reads the elements of a profile manifest. Version 3
+// manifests key elements by name; older ones list them, and nix refers to
+// them by index.
+func parseNixManifest(data []byte) ([]nixElement, error) {
+	var manifest struct {
+		Version  int             `json:"version"`
+		Elements json.RawMessage `json:"elements"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, err
+	}
+
+	if manifest.Version >= 3 {
+		var named map[string]nixElement
+		if err := json.Unmarshal(manifest.Elements, &named); err != nil {
+			return nil, err
+		}
+		elements := make([]nixElement, 0, len(named))
+		for name, element := range named {
+			element.name = name
+			elements = append(elements, element)
+		}
+		sort.Slice(elements, func(i, j int) bool { return elements[i].name < elements[j].name })
+		return elements, nil
+	}
+
+	var elements []nixElement
+	if err := json.Unmarshal(manifest.Elements, &elements); err != nil {
+		return nil, err
+	}
+	for i := range elements {
+		elements[i].name = strconv.Itoa(i)
+	}
+	return elements, nil
+}
+
+// nixStoreVersion matches the version in a store path name such as
+// "jq-1.7.1-bin": the first dash-separated part starting with a digit
+var nixStoreVersion = regexp.MustCompile(`-([0-9][^-]*)`)
+
+// nixStorePathVersion returns the version of a store path like
+// "/nix/store/<hash>-jq-1.7.1-bin", or "" if its name has none
+func nixStorePathVersion(storePath string) string {
+	base := filepath.Base(storePath)
+	// Drop the 32-character hash prefix
+	if _, name, found := strings.Cut(base, "-"); found {
+		base = name
+	}
+	if match := nixStoreVersion.FindStringSubmatch(base); match != nil {
+		return match[1]
+	}
+	return ""
+}
@@ -0,0 +1,111 @@
+package depman
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestNixFlakeAttr(t *testing.T) {
+	testCases := map[string]string{
+		"nixpkgs#jq": "jq",
+		"github:NixOS/nixpkgs/0123abc#python3Packages.black": "black",
+		"github:org/tools#packages.x86_64-linux.ripgrep":     "ripgrep",
+		"github:org/tools": "default",
+	}
+	for ref, expected := range testCases {
+		if got := nixFlakeAttr(ref); got != expected {
+			t.Errorf("Expected attribute %s for %s but got %s", expected, ref, got)
+		}
+	}
+}
+
+func TestNixStorePathVersion(t *testing.T) {
+	testCases := map[string]string{
+		"/nix/store/1m6lsrhs0ndz2q6mw6il6w8r1gw0j8cp-jq-1.7.1-bin":            "1.7.1",
+		"/nix/store/8z1c3lb7bj4lcd6jvqrpjsfvw6cq8c5x-ripgrep-14.1.0":          "14.1.0",
+		"/nix/store/a4hn0xa3n4abp0slzwlvlmh7cxgc4ifp-python3.12-black-24.8.0": "24.8.0",
+		"/nix/store/x9q6m4ljw9c2pdiv9pkhhm7ybgwzb5ca-hello":                   "",
+	}
+	for storePath, expected := range testCases {
+		if got := nixStorePathVersion(storePath); got != expected {
+			t.Errorf("Expected version %q for %s but got %q", expected, storePath, got)
+		}
+	}
+}
+
+func TestParseNixManifest(t *testing.T) {
+	v2 := `{"version": 2, "elements": [
+		{"attrPath": "legacyPackages.x86_64-linux.jq", "url": "github:NixOS/nixpkgs/0123abc", "storePaths": ["/nix/store/h-jq-1.7.1-bin"]}
+	]}`
+	elements, err := parseNixManifest([]byte(v2))
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	expected := []nixElement{{name: "0", AttrPath: "legacyPackages.x86_64-linux.jq", URL: "github:NixOS/nixpkgs/0123abc", StorePaths: []string{"/nix/store/h-jq-1.7.1-bin"}}}
+	if !reflect.DeepEqual(elements, expected) {
+		t.Errorf("Expected %+v but got %+v", expected, elements)
+	}
+
+	v3 := `{"version": 3, "elements": {
+		"ripgrep": {"attrPath": "legacyPackages.x86_64-linux.ripgrep", "storePaths": ["/nix/store/h-ripgrep-14.1.0"]}
+	}}`
+	elements, err = parseNixManifest([]byte(v3))
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if len(elements) != 1 || elements[0].name != "ripgrep" {
+		t.Errorf("Expected a ripgrep element but got %+v", elements)
+	}
+}
+
+func TestNixInstaller(t *testing.T) {
+	homeDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+	t.Setenv("XDG_STATE_HOME", "")
+
+	manifest := `{"version": 3, "elements": {
+		"jq": {"attrPath": "legacyPackages.x86_64-linux.jq", "url": "github:NixOS/nixpkgs/0123abc", "storePaths": ["/nix/store/h-jq-1.7.1-bin"]}
+	}}`
+	profileDir := filepath.Join(homeDir, ".nix-profile")
+	if err := os.MkdirAll(profileDir, 0755); err != nil {
+		t.Fatalf("Failed to create profile: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(profileDir, "manifest.json"), []byte(manifest), 0644); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+
+	calls := fakeCommands(t, map[string]string{
+		"nix --extra-experimental-features nix-command flakes profile remove jq":                               "",
+		"nix --extra-experimental-features nix-command flakes profile install github:NixOS/nixpkgs/4567def#jq": "",
+	})
+	manager := &Manager{Platform: "linux", logger: &mockLogger{}}
+	dep := &Dependency{Name: "jq"}
+	platformConfig := &PlatformConfig{Installer: Installer{Method: "nix", Flake: "github:NixOS/nixpkgs/4567def#jq"}}
+
+	version, err := (nixInstaller{}).detectVersion(context.Background(), manager, dep, platformConfig)
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if version != "1.7.1" {
+		t.Errorf("Expected version 1.7.1 but got %s", version)
+	}
+
+	// An installed element is replaced so the new pin takes effect
+	artifact, err := (nixInstaller{}).install(context.Background(), manager, dep, platformConfig)
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if len(*calls) != 2 {
+		t.Errorf("Expected the element to be removed and reinstalled but got %v", *calls)
+	}
+	if artifact.URL != "nix:github:NixOS/nixpkgs/0123abc#legacyPackages.x86_64-linux.jq" {
+		t.Errorf("Expected the locked flake as the artifact but got %s", artifact.URL)
+	}
+
+	if _, err := (nixInstaller{}).detectVersion(context.Background(), manager, &Dependency{Name: "fd"}, &PlatformConfig{}); err == nil {
+		t.Error("Expected an error for a package missing from the profile but got none")
+	}
+}
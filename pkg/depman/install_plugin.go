@@ -0,0 +1,89 @@
+package depman
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/devnadeemashraf/depman/pkg/plugin"
+)
+
+// pluginTimeout bounds a single plugin call; installs may download large artifacts
+const pluginTimeout = 30 * time.Minute
+
+// pluginInstaller delegates installs to an external plugin executable
+type pluginInstaller struct {
+	name string // Plugin name, matching the install method
+	path string // Path of the plugin executable
+}
+
+// install asks the plugin to install the dependency
+func (p pluginInstaller) install(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) (LockedArtifact, error) {
+	m.componentLogger("plugin", dep).Infof("Installing %s with plugin %s", dep.Name, p.name)
+
+	resp, err := p.call(ctx, m, plugin.MethodInstall, dep, platformConfig)
+	if err != nil {
+		return LockedArtifact{}, err
+	}
+
+	m.componentLogger("plugin", dep).Infof("Successfully installed %s", dep.Name)
+	return LockedArtifact{URL: resp.URL, Checksum: resp.Checksum}, nil
+}
+
+// uninstall asks the plugin to remove the dependency
+func (p pluginInstaller) uninstall(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) error {
+	_, err := p.call(ctx, m, plugin.MethodUninstall, dep, platformConfig)
+	return err
+}
+
+// detectVersion asks the plugin whether the dependency is installed and at which version
+func (p pluginInstaller) detectVersion(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) (string, error) {
+	resp, err := p.call(ctx, m, plugin.MethodCheck, dep, platformConfig)
+	if err != nil {
+		return "", err
+	}
+	if !resp.Installed {
+		return "", fmt.Errorf("%s is not installed", dep.Name)
+	}
+
+	resp, err = p.call(ctx, m, plugin.MethodVersion, dep, platformConfig)
+	if err != nil {
+		return "", err
+	}
+	return resp.Version, nil
+}
+
+// call sends a single request to the plugin
+func (p pluginInstaller) call(ctx context.Context, m *Manager, method string, dep *Dependency, platformConfig *PlatformConfig) (*plugin.Response, error) {
+	ctx, cancel := context.WithTimeout(ctx, pluginTimeout)
+	defer cancel()
+
+	// Plugin diagnostics are logged rather than written to the terminal, and
+	// kept for the install log
+	stderr := &logWriter{log: m.componentLogger("plugin", dep).Infof}
+	defer stderr.Close()
+	diagnostics := &bytes.Buffer{}
+
+	url, err := m.expandURL(platformConfig.Installer.URL, dep)
+	if err != nil {
+		return nil, err
+	}
+	response, err := plugin.Call(ctx, p.path, &plugin.Request{
+		Method:     method,
+		Name:       dep.Name,
+		Version:    dep.Version.Required,
+		Constraint: dep.Version.Constraint,
+		Platform:   m.Platform,
+		Arch:       m.targetArch(),
+		Package:    platformConfig.Installer.Package,
+		URL:        url,
+		Args:       platformConfig.Installer.Args,
+		Options:    platformConfig.Installer.Options,
+		HomeDir:    m.HomeDir(),
+		BinDir:     m.BinDir(),
+	}, io.MultiWriter(stderr, diagnostics))
+	installLogOf(ctx).record(p.path, []string{method}, diagnostics.Bytes(), err)
+	return response, err
+}
@@ -0,0 +1,356 @@
+package depman
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// requirementMethod is the install method of requirement dependencies
+const requirementMethod = "requirement"
+
+// Files describing the Linux machine; replaced in tests
+var (
+	meminfoPath = "/proc/meminfo"
+	cpuinfoPath = "/proc/cpuinfo"
+	kvmPath     = "/dev/kvm"
+)
+
+// numCPU reports the number of logical CPUs; replaced in tests
+var numCPU = runtime.NumCPU
+
+// requirementInstaller checks machine preconditions; they cannot be installed
+type requirementInstaller struct{}
+
+func init() {
+	installStrategies[requirementMethod] = requirementInstaller{}
+}
+
+// validate checks that a requirement sets at least one well-formed check
+func (s RequirementSpec) validate() error {
+	if s.DiskSpace == "" && s.Memory == "" && s.CPUs == 0 && s.OSVersion == "" && !s.Virtualization {
+		return fmt.Errorf("missing requirement checks (expected disk_space, memory, cpus, os_version or virtualization)")
+	}
+	if s.DiskSpace != "" {
+		if _, err := parseByteSize(s.DiskSpace); err != nil {
+			return fmt.Errorf("invalid disk_space: %w", err)
+		}
+	}
+	if s.Memory != "" {
+		if _, err := parseByteSize(s.Memory); err != nil {
+			return fmt.Errorf("invalid memory: %w", err)
+		}
+	}
+	if s.CPUs < 0 {
+		return fmt.Errorf("invalid cpus %d (expected a positive count)", s.CPUs)
+	}
+	if s.OSVersion != "" {
+		if _, err := ParseConstraint(s.OSVersion); err != nil {
+			return fmt.Errorf("invalid os_version: %w", err)
+		}
+	}
+	return nil
+}
+
+// byteSizePattern matches sizes such as "20GB", "1.5 TiB" or "512M"
+var byteSizePattern = regexp.MustCompile(`^(\d+(?:\.\d+)?)\s*([KMGT]?)(I?)B?$`)
+
+// parseByteSize parses a size with an optional decimal (GB) or binary (GiB) unit
+func parseByteSize(value string) (int64, error) {
+	match := byteSizePattern.FindStringSubmatch(strings.ToUpper(strings.TrimSpace(value)))
+	if match == nil {
+		return 0, fmt.Errorf("invalid size '%s' (expected a number with an optional unit, e.g. 20GB)", value)
+	}
+	number, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size '%s': %w", value, err)
+	}
+	base := 1000.0
+	if match[3] != "" {
+		base = 1024
+	}
+	if match[2] != "" {
+		for i := 0; i <= strings.Index("KMGT", match[2]); i++ {
+			number *= base
+		}
+	}
+	return int64(number), nil
+}
+
+// formatByteSize formats a size in decimal units, e.g. "20.0 GB"
+func formatByteSize(size int64) string {
+	value, units := float64(size), []string{"B", "KB", "MB", "GB", "TB"}
+	i := 0
+	for value >= 1000 && i < len(units)-1 {
+		value /= 1000
+		i++
+	}
+	if i == 0 {
+		return fmt.Sprintf("%d B", size)
+	}
+	return fmt.Sprintf("%.1f %s", value, units[i])
+}
+
+// install fails with the checks that are not met; requirements are checked, never installed
+func (requirementInstaller) install(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) (LockedArtifact, error) {
+	if err := checkRequirement(ctx, m, dep); err != nil {
+		return LockedArtifact{}, fmt.Errorf("requirement cannot be installed: %w", err)
+	}
+	return LockedArtifact{}, nil
+}
+
+// detectVersion reports "met" if every check passes
+func (requirementInstaller) detectVersion(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) (string, error) {
+	if err := checkRequirement(ctx, m, dep); err != nil {
+		return "", err
+	}
+	return "met", nil
+}
+
+// checkRequirement runs the requirement's checks and returns the failures,
+// each followed by its remediation
+func checkRequirement(ctx context.Context, m *Manager, dep *Dependency) error {
+	spec := dep.Requirement
+	if err := spec.validate(); err != nil {
+		return err
+	}
+
+	var failures []error
+	fail := func(fix string, format string, args ...any) {
+		if spec.Fix != "" {
+			fix = spec.Fix
+		}
+		failures = append(failures, fmt.Errorf("%s (fix: %s)", fmt.Sprintf(format, args...), fix))
+	}
+
+	if spec.DiskSpace != "" {
+		need, _ := parseByteSize(spec.DiskSpace)
+		path := spec.DiskPath
+		if path == "" {
+			path = m.HomeDir()
+		}
+		free, err := freeDiskSpace(ctx, m.Platform, path)
+		switch {
+		case err != nil:
+			failures = append(failures, fmt.Errorf("free disk space on %s could not be read: %w", path, err))
+		case free < need:
+			fail("free up space on "+path, "%s free on %s, need %s", formatByteSize(free), path, formatByteSize(need))
+		}
+	}
+
+	if spec.Memory != "" {
+		need, _ := parseByteSize(spec.Memory)
+		total, err := totalMemory(ctx, m.Platform)
+		switch {
+		case err != nil:
+			failures = append(failures, fmt.Errorf("memory could not be read: %w", err))
+		case total < need:
+			fail("use a machine or VM with more memory", "%s of memory, need %s", formatByteSize(total), formatByteSize(need))
+		}
+	}
+
+	if spec.CPUs > 0 {
+		if cpus := numCPU(); cpus < spec.CPUs {
+			fail("use a machine or VM with more CPUs", "%d CPUs, need %d", cpus, spec.CPUs)
+		}
+	}
+
+	if spec.OSVersion != "" {
+		version, err := osVersion(ctx, m.Platform)
+		if err != nil {
+			failures = append(failures, fmt.Errorf("OS version could not be read: %w", err))
+		} else if ok, err := osVersionSatisfies(version, spec.OSVersion); err != nil {
+			failures = append(failures, err)
+		} else if !ok {
+			fail("upgrade the operating system", "%s version %s does not satisfy %s", osName(m.Platform), version, spec.OSVersion)
+		}
+	}
+
+	if spec.Virtualization {
+		enabled, err := virtualizationEnabled(ctx, m.Platform)
+		switch {
+		case err != nil:
+			failures = append(failures, fmt.Errorf("virtualization support could not be read: %w", err))
+		case !enabled:
+			fail("enable virtualization (Intel VT-x or AMD-V) in the firmware settings", "hardware virtualization is not enabled")
+		}
+	}
+
+	if len(failures) == 0 {
+		return nil
+	}
+	messages := make([]string, len(failures))
+	for i, failure := range failures {
+		messages[i] = failure.Error()
+	}
+	return errors.New(strings.Join(messages, "; "))
+}
+
+// freeDiskSpace returns the bytes available to the user on the filesystem
+// holding path, or its nearest existing parent
+func freeDiskSpace(ctx context.Context, platform, path string) (int64, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return 0, err
+	}
+	for {
+		if _, err := os.Stat(path); err == nil || filepath.Dir(path) == path {
+			break
+		}
+		path = filepath.Dir(path)
+	}
+
+	if platform == "windows" {
+		drive := strings.TrimSuffix(filepath.VolumeName(path), ":")
+		output, err := runCommand(ctx, "powershell", "-NoProfile", "-Command", fmt.Sprintf("(Get-PSDrive -Name '%s').Free", drive))
+		if err != nil {
+			return 0, err
+		}
+		return strconv.ParseInt(strings.TrimSpace(output), 10, 64)
+	}
+
+	// POSIX output: Filesystem 1024-blocks Used Available Capacity Mounted-on
+	output, err := runCommand(ctx, "df", "-Pk", path)
+	if err != nil {
+		return 0, err
+	}
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	fields := strings.Fields(lines[len(lines)-1])
+	if len(lines) < 2 || len(fields) < 4 {
+		return 0, fmt.Errorf("unexpected df output: %s", output)
+	}
+	available, err := strconv.ParseInt(fields[3], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected df output: %s", output)
+	}
+	return available * 1024, nil
+}
+
+// totalMemory returns the machine's physical memory in bytes
+func totalMemory(ctx context.Context, platform string) (int64, error) {
+	switch platform {
+	case "linux":
+		data, err := os.ReadFile(meminfoPath)
+		if err != nil {
+			return 0, err
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if fields := strings.Fields(line); len(fields) >= 2 && fields[0] == "MemTotal:" {
+				kb, err := strconv.ParseInt(fields[1], 10, 64)
+				return kb * 1024, err
+			}
+		}
+		return 0, fmt.Errorf("MemTotal not found in %s", meminfoPath)
+	case "darwin":
+		output, err := runCommand(ctx, "sysctl", "-n", "hw.memsize")
+		if err != nil {
+			return 0, err
+		}
+		return strconv.ParseInt(output, 10, 64)
+	case "windows":
+		output, err := runCommand(ctx, "powershell", "-NoProfile", "-Command", "(Get-CimInstance Win32_ComputerSystem).TotalPhysicalMemory")
+		if err != nil {
+			return 0, err
+		}
+		return strconv.ParseInt(output, 10, 64)
+	default:
+		return 0, fmt.Errorf("not supported on %s", platform)
+	}
+}
+
+// osName names the version os_version is compared with on a platform
+func osName(platform string) string {
+	switch platform {
+	case "linux":
+		return "Linux kernel"
+	case "darwin":
+		return "macOS"
+	case "windows":
+		return "Windows"
+	default:
+		return platform
+	}
+}
+
+// osVersion returns the Linux kernel, macOS or Windows version
+func osVersion(ctx context.Context, platform string) (string, error) {
+	switch platform {
+	case "linux":
+		return runCommand(ctx, "uname", "-r")
+	case "darwin":
+		return runCommand(ctx, "sw_vers", "-productVersion")
+	case "windows":
+		return runCommand(ctx, "powershell", "-NoProfile", "-Command", "[System.Environment]::OSVersion.Version.ToString()")
+	default:
+		return "", fmt.Errorf("not supported on %s", platform)
+	}
+}
+
+// osVersionPattern matches the numeric start of OS versions such as
+// "6.8.0-45-generic" or "10.0.22631.0"
+var osVersionPattern = regexp.MustCompile(`^\d+(?:\.\d+){0,2}`)
+
+// osVersionSatisfies compares the numeric part of an OS version with a constraint
+func osVersionSatisfies(version, constraint string) (bool, error) {
+	numeric := osVersionPattern.FindString(strings.TrimSpace(version))
+	if numeric == "" {
+		return false, fmt.Errorf("unrecognized OS version '%s'", version)
+	}
+	v, err := semver.NewVersion(numeric)
+	if err != nil {
+		return false, fmt.Errorf("unrecognized OS version '%s': %w", version, err)
+	}
+	c, err := ParseConstraint(constraint)
+	if err != nil {
+		return false, err
+	}
+	return c.Check(v), nil
+}
+
+// virtualizationEnabled reports whether hardware virtualization is available
+func virtualizationEnabled(ctx context.Context, platform string) (bool, error) {
+	switch platform {
+	case "linux":
+		if _, err := os.Stat(kvmPath); err == nil {
+			return true, nil
+		}
+		data, err := os.ReadFile(cpuinfoPath)
+		if err != nil {
+			return false, err
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if name, flags, ok := strings.Cut(line, ":"); ok && strings.TrimSpace(name) == "flags" {
+				for _, flag := range strings.Fields(flags) {
+					if flag == "vmx" || flag == "svm" {
+						return true, nil
+					}
+				}
+			}
+		}
+		return false, nil
+	case "darwin":
+		output, err := runCommand(ctx, "sysctl", "-n", "kern.hv_support")
+		if err != nil {
+			return false, err
+		}
+		return output == "1", nil
+	case "windows":
+		// A running hypervisor hides the firmware flag, so either one counts
+		output, err := runCommand(ctx, "powershell", "-NoProfile", "-Command",
+			"(Get-CimInstance Win32_ComputerSystem).HypervisorPresent -or (Get-CimInstance Win32_Processor | Select-Object -First 1).VirtualizationFirmwareEnabled")
+		if err != nil {
+			return false, err
+		}
+		return strings.EqualFold(output, "True"), nil
+	default:
+		return false, fmt.Errorf("not supported on %s", platform)
+	}
+}
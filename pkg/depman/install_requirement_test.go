@@ -0,0 +1,130 @@
+package depman
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseByteSize(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected int64
+	}{
+		{"512", 512},
+		{"20GB", 20_000_000_000},
+		{"20 gb", 20_000_000_000},
+		{"1.5TiB", 1_649_267_441_664},
+		{"512M", 512_000_000},
+		{"8GiB", 8_589_934_592},
+	}
+
+	for _, tc := range testCases {
+		got, err := parseByteSize(tc.value)
+		if err != nil {
+			t.Errorf("Did not expect an error for %q but got: %v", tc.value, err)
+		} else if got != tc.expected {
+			t.Errorf("Expected %d for %q but got %d", tc.expected, tc.value, got)
+		}
+	}
+
+	if _, err := parseByteSize("lots"); err == nil {
+		t.Errorf("Expected an error but got none")
+	}
+}
+
+func TestOSVersionSatisfies(t *testing.T) {
+	testCases := []struct {
+		version    string
+		constraint string
+		expected   bool
+	}{
+		{"6.8.0-45-generic", ">=5.10", true},
+		{"5.4.0-150-generic", ">=5.10", false},
+		{"14.4.1", ">=13.0", true},
+		{"10.0.22631.0", ">=10.0.22000", true},
+		{"10.0.19045.0", ">=10.0.22000", false},
+	}
+
+	for _, tc := range testCases {
+		got, err := osVersionSatisfies(tc.version, tc.constraint)
+		if err != nil {
+			t.Errorf("Did not expect an error for %s but got: %v", tc.version, err)
+		} else if got != tc.expected {
+			t.Errorf("Expected %s %s to be %v but got %v", tc.version, tc.constraint, tc.expected, got)
+		}
+	}
+}
+
+func TestCheckRequirement(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "depman-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	originalMeminfo, originalCpuinfo, originalKVM, originalCPU := meminfoPath, cpuinfoPath, kvmPath, numCPU
+	defer func() {
+		meminfoPath, cpuinfoPath, kvmPath, numCPU = originalMeminfo, originalCpuinfo, originalKVM, originalCPU
+	}()
+	meminfoPath = filepath.Join(tempDir, "meminfo")
+	cpuinfoPath = filepath.Join(tempDir, "cpuinfo")
+	kvmPath = filepath.Join(tempDir, "kvm")
+	numCPU = func() int { return 4 }
+	if err := os.WriteFile(meminfoPath, []byte("MemTotal:       16303976 kB\nMemFree:         1024 kB\n"), 0644); err != nil {
+		t.Fatalf("Failed to write meminfo: %v", err)
+	}
+	if err := os.WriteFile(cpuinfoPath, []byte("processor\t: 0\nflags\t\t: fpu vme sse2 vmx\n"), 0644); err != nil {
+		t.Fatalf("Failed to write cpuinfo: %v", err)
+	}
+
+	fakeCommands(t, map[string]string{
+		"df -Pk " + tempDir: "Filesystem 1024-blocks Used Available Capacity Mounted on\n/dev/sda1 100000000 90000000 10000000 90% /",
+		"uname -r":          "6.8.0-45-generic",
+	})
+	manager := &Manager{Platform: "linux", logger: &mockLogger{}}
+
+	testCases := []struct {
+		name     string
+		spec     RequirementSpec
+		expected []string // Substrings of the error, or none if met
+	}{
+		{"Met", RequirementSpec{DiskSpace: "10GB", DiskPath: tempDir, Memory: "16GB", CPUs: 4, OSVersion: ">=5.10", Virtualization: true}, nil},
+		{"Disk space", RequirementSpec{DiskSpace: "20GB", DiskPath: filepath.Join(tempDir, "missing")}, []string{"10.2 GB free on", "need 20.0 GB", "fix: free up space"}},
+		{"Memory and CPUs", RequirementSpec{Memory: "32GB", CPUs: 8}, []string{"16.7 GB of memory, need 32.0 GB", "4 CPUs, need 8"}},
+		{"Kernel", RequirementSpec{OSVersion: ">=6.10"}, []string{"Linux kernel version 6.8.0-45-generic does not satisfy >=6.10"}},
+		{"Custom fix", RequirementSpec{CPUs: 16, Fix: "ask IT for a bigger laptop"}, []string{"fix: ask IT for a bigger laptop"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dep := &Dependency{Name: "machine", Kind: "requirement", Requirement: tc.spec}
+			err := checkRequirement(context.Background(), manager, dep)
+			if len(tc.expected) == 0 {
+				if err != nil {
+					t.Errorf("Did not expect an error but got: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Expected an error but got none")
+			}
+			for _, expected := range tc.expected {
+				if !strings.Contains(err.Error(), expected) {
+					t.Errorf("Expected the error to contain %q but got: %v", expected, err)
+				}
+			}
+		})
+	}
+
+	// Without vmx or svm flags or /dev/kvm, virtualization is reported as disabled
+	if err := os.WriteFile(cpuinfoPath, []byte("flags\t\t: fpu vme sse2\n"), 0644); err != nil {
+		t.Fatalf("Failed to write cpuinfo: %v", err)
+	}
+	dep := &Dependency{Name: "vt", Kind: "requirement", Requirement: RequirementSpec{Virtualization: true}}
+	if err := checkRequirement(context.Background(), manager, dep); err == nil || !strings.Contains(err.Error(), "not enabled") {
+		t.Errorf("Expected virtualization to be disabled but got: %v", err)
+	}
+}
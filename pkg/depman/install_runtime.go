@@ -0,0 +1,304 @@
+package depman
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// runtimeManager describes how to drive one language runtime version manager
+type runtimeManager struct {
+	binary      string                                // Executable used to detect the manager
+	tool        string                                // Runtime the manager is limited to, or "" if the package names it
+	versionFile string                                // Project file pinning the version; ".tool-versions" lists every tool
+	list        func(tool string) []string            // Command printing the installed versions
+	listRemote  func(tool string) []string            // Command printing the installable versions, one per line
+	install     func(tool, version string) [][]string // Commands installing a version; all but the last may fail harmlessly
+	useGlobal   func(tool, version string) [][]string // Alternative commands making a version the user's default, tried in order
+	parse       func(tool, output string) []string    // Extracts the versions from list output
+}
+
+// nvmCommand runs nvm, which is a shell function rather than an executable
+func nvmCommand(args ...string) []string {
+	return append([]string{"bash", "-c", `. "${NVM_DIR:-$HOME/.nvm}/nvm.sh" && nvm "$@"`, "nvm"}, args...)
+}
+
+// runtimeManagers lists the supported runtime version managers
+var runtimeManagers = map[string]runtimeManager{
+	"asdf": {
+		binary:      "asdf",
+		versionFile: ".tool-versions",
+		list:        func(tool string) []string { return []string{"asdf", "list", tool} },
+		listRemote:  func(tool string) []string { return []string{"asdf", "list", "all", tool} },
+		install: func(tool, version string) [][]string {
+			// Adding a plugin that is already present fails harmlessly
+			return [][]string{{"asdf", "plugin", "add", tool}, {"asdf", "install", tool, version}}
+		},
+		useGlobal: func(tool, version string) [][]string {
+			// asdf 0.16 replaced `global` with `set -u`
+			return [][]string{{"asdf", "set", "-u", tool, version}, {"asdf", "global", tool, version}}
+		},
+		parse: parseVersionLines,
+	},
+	"mise": {
+		binary:      "mise",
+		versionFile: ".tool-versions",
+		list:        func(tool string) []string { return []string{"mise", "ls", "--installed", "--json", tool} },
+		listRemote:  func(tool string) []string { return []string{"mise", "ls-remote", tool} },
+		install: func(tool, version string) [][]string {
+			return [][]string{{"mise", "install", tool + "@" + version}}
+		},
+		useGlobal: func(tool, version string) [][]string {
+			return [][]string{{"mise", "use", "--global", tool + "@" + version}}
+		},
+		parse: parseMiseList,
+	},
+	"nvm": {
+		tool:        "node",
+		versionFile: ".nvmrc",
+		list:        func(tool string) []string { return nvmCommand("ls", "--no-colors", "--no-alias") },
+		listRemote:  func(tool string) []string { return nvmCommand("ls-remote", "--no-colors") },
+		install: func(tool, version string) [][]string {
+			return [][]string{nvmCommand("install", version)}
+		},
+		useGlobal: func(tool, version string) [][]string {
+			return [][]string{nvmCommand("alias", "default", version)}
+		},
+		parse: parseVersionLines,
+	},
+	"pyenv": {
+		binary:      "pyenv",
+		tool:        "python",
+		versionFile: ".python-version",
+		list:        func(tool string) []string { return []string{"pyenv", "versions", "--bare"} },
+		listRemote:  func(tool string) []string { return []string{"pyenv", "install", "--list"} },
+		install: func(tool, version string) [][]string {
+			return [][]string{{"pyenv", "install", "--skip-existing", version}}
+		},
+		useGlobal: func(tool, version string) [][]string {
+			return [][]string{{"pyenv", "global", version}}
+		},
+		parse: parseVersionLines,
+	},
+	"rbenv": {
+		binary:      "rbenv",
+		tool:        "ruby",
+		versionFile: ".ruby-version",
+		list:        func(tool string) []string { return []string{"rbenv", "versions", "--bare"} },
+		listRemote:  func(tool string) []string { return []string{"rbenv", "install", "--list-all"} },
+		install: func(tool, version string) [][]string {
+			return [][]string{{"rbenv", "install", "--skip-existing", version}}
+		},
+		useGlobal: func(tool, version string) [][]string {
+			return [][]string{{"rbenv", "global", version}}
+		},
+		parse: parseVersionLines,
+	},
+}
+
+// parseVersionLines extracts versions from one-per-line listings, dropping
+// current-version markers, "v" prefixes and trailing notes, and skipping
+// lines that do not start with a version number
+func parseVersionLines(tool, output string) []string {
+	var versions []string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "*->"))
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		version := strings.TrimPrefix(fields[0], "v")
+		if version != "" && version[0] >= '0' && version[0] <= '9' {
+			versions = append(versions, version)
+		}
+	}
+	return versions
+}
+
+// parseMiseList extracts the versions from `mise ls --json <tool>` output
+func parseMiseList(tool, output string) []string {
+	var installed []struct {
+		Version string `json:"version"`
+	}
+	if err := json.Unmarshal([]byte(output), &installed); err != nil {
+		return nil
+	}
+	versions := make([]string, 0, len(installed))
+	for _, entry := range installed {
+		versions = append(versions, entry.Version)
+	}
+	return versions
+}
+
+// runtimeInstaller installs language runtimes with a version manager
+type runtimeInstaller struct {
+	name string // Key in runtimeManagers
+}
+
+func init() {
+	for name := range runtimeManagers {
+		installStrategies[name] = runtimeInstaller{name: name}
+	}
+}
+
+// manager returns the version manager and the runtime it manages for a dependency
+func (r runtimeInstaller) manager(ctx context.Context, dep *Dependency, platformConfig *PlatformConfig) (runtimeManager, string, error) {
+	manager := runtimeManagers[r.name]
+	if manager.binary != "" {
+		if _, err := hostLookPath(ctx, manager.binary); err != nil {
+			return manager, "", fmt.Errorf("%s is not installed", r.name)
+		}
+	} else {
+		args := nvmCommand("--version")
+		if _, err := runCommand(ctx, args[0], args[1:]...); err != nil {
+			return manager, "", fmt.Errorf("%s is not installed", r.name)
+		}
+	}
+
+	tool := manager.tool
+	if tool == "" {
+		tool = packageName(dep, platformConfig)
+	}
+	return manager, tool, nil
+}
+
+// resolveVersion translates the dependency's version requirements into a
+// concrete version the manager can install
+func (r runtimeInstaller) resolveVersion(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig, tool string) (string, error) {
+	if dep.Version.Required != "" && dep.Version.Constraint == "" {
+		return dep.Version.Required, nil
+	}
+
+	candidates, err := r.availableVersions(ctx, m, dep, platformConfig)
+	if err != nil {
+		return "", err
+	}
+	i, _, err := dep.Version.Resolve(candidates)
+	if err != nil {
+		return "", fmt.Errorf("%s %s: %w", r.name, tool, err)
+	}
+	return candidates[i], nil
+}
+
+// install installs the resolved version and makes it the project's or the
+// user's version, following the install scope
+func (r runtimeInstaller) install(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) (LockedArtifact, error) {
+	var artifact LockedArtifact
+
+	manager, tool, err := r.manager(ctx, dep, platformConfig)
+	if err != nil {
+		return artifact, err
+	}
+	version, err := r.resolveVersion(ctx, m, dep, platformConfig, tool)
+	if err != nil {
+		return artifact, err
+	}
+
+	m.logger.Infof("Installing %s %s with %s", tool, version, r.name)
+	commands := manager.install(tool, version)
+	for _, args := range commands[:len(commands)-1] {
+		if _, err := runCommand(ctx, args[0], args[1:]...); err != nil {
+			m.logger.Debugf("%s: %v", strings.Join(args, " "), err)
+		}
+	}
+	last := commands[len(commands)-1]
+	if _, err := m.retryCommand(ctx, dep, last[0], last[1:]...); err != nil {
+		return artifact, fmt.Errorf("installation failed: %w", err)
+	}
+
+	if err := r.use(ctx, m, manager, tool, version); err != nil {
+		return artifact, err
+	}
+
+	m.logger.Infof("Successfully installed %s", dep.Name)
+	artifact.URL = r.name + ":" + tool + "@" + version
+	return artifact, nil
+}
+
+// use selects the version: in the project's version file for the project
+// scope, or as the user's default otherwise
+func (r runtimeInstaller) use(ctx context.Context, m *Manager, manager runtimeManager, tool, version string) error {
+	if m.Scope() == ScopeProject {
+		path := filepath.Join(m.configDir(), manager.versionFile)
+		m.logger.Infof("Pinning %s %s in %s", tool, version, path)
+		return writeVersionFile(path, tool, version)
+	}
+
+	var err error
+	for _, args := range manager.useGlobal(tool, version) {
+		if _, err = runCommand(ctx, args[0], args[1:]...); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("failed to select %s %s: %w", tool, version, err)
+}
+
+// writeVersionFile pins a version in a project version file. .tool-versions
+// files list one "tool version" line per tool; the line for the tool is
+// replaced and the others are kept. Other files hold just the version.
+func writeVersionFile(path, tool, version string) error {
+	if filepath.Base(path) != ".tool-versions" {
+		return os.WriteFile(path, []byte(version+"\n"), 0644)
+	}
+
+	var lines []string
+	if data, err := os.ReadFile(path); err == nil {
+		for _, line := range strings.Split(strings.TrimRight(string(data), "\n"), "\n") {
+			if fields := strings.Fields(line); len(fields) > 0 && fields[0] == tool {
+				continue
+			}
+			if line != "" {
+				lines = append(lines, line)
+			}
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	lines = append(lines, tool+" "+version)
+	return os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+}
+
+// detectVersion reports the installed version that best meets the
+// dependency's requirements, so a required version the manager has installed
+// counts even if another one is newer
+func (r runtimeInstaller) detectVersion(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) (string, error) {
+	manager, tool, err := r.manager(ctx, dep, platformConfig)
+	if err != nil {
+		return "", err
+	}
+
+	args := manager.list(tool)
+	output, err := runCommand(ctx, args[0], args[1:]...)
+	if err != nil {
+		return "", fmt.Errorf("%s is not installed: %w", tool, err)
+	}
+	installed := manager.parse(tool, output)
+	if len(installed) == 0 {
+		return "", fmt.Errorf("%s is not installed with %s", tool, r.name)
+	}
+	if i, _, err := dep.Version.Resolve(installed); err == nil {
+		return installed[i], nil
+	}
+	// Report the newest version so the check can say how far off it is
+	if i, _, err := (Version{}).Resolve(installed); err == nil {
+		return installed[i], nil
+	}
+	return installed[len(installed)-1], nil
+}
+
+// availableVersions lists the versions the manager can install
+func (r runtimeInstaller) availableVersions(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) ([]string, error) {
+	manager, tool, err := r.manager(ctx, dep, platformConfig)
+	if err != nil {
+		return nil, err
+	}
+	args := manager.listRemote(tool)
+	output, err := m.retryCommand(ctx, dep, args[0], args[1:]...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s versions: %w", tool, err)
+	}
+	return parseVersionLines(tool, output), nil
+}
@@ -0,0 +1,149 @@
+package depman
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseVersionLines(t *testing.T) {
+	testCases := []struct {
+		name     string
+		output   string
+		expected []string
+	}{
+		{"asdf", "  18.20.4\n *20.17.0\n", []string{"18.20.4", "20.17.0"}},
+		{"nvm", "->     v20.1.0\n       v18.19.0 *\nsystem\n", []string{"20.1.0", "18.19.0"}},
+		{"pyenv list", "Available versions:\n  3.11.9\n  3.12.4\n  pypy3.10-7.3.16\n", []string{"3.11.9", "3.12.4"}},
+		{"Empty", "", nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := parseVersionLines("", tc.output); !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("Expected %v but got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseMiseList(t *testing.T) {
+	output := `[{"version": "20.17.0", "active": true}, {"version": "22.9.0"}]`
+	expected := []string{"20.17.0", "22.9.0"}
+	if got := parseMiseList("node", output); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v but got %v", expected, got)
+	}
+	if got := parseMiseList("node", "not json"); got != nil {
+		t.Errorf("Expected no versions but got %v", got)
+	}
+}
+
+func TestRuntimeInstall(t *testing.T) {
+	calls := fakeCommands(t, map[string]string{
+		"asdf list all nodejs":                 "18.20.4\n20.16.0\n20.17.0\n22.9.0\n",
+		"asdf install nodejs 20.17.0":          "",
+		"asdf global nodejs 20.17.0":           "",
+		"pyenv install --skip-existing 3.12.4": "",
+	})
+	manager := &Manager{Platform: "linux", logger: &mockLogger{}, scope: ScopeGlobal}
+	dep := &Dependency{Name: "node", Version: Version{Constraint: "^20"}}
+
+	artifact, err := (runtimeInstaller{name: "asdf"}).install(context.Background(), manager, dep, &PlatformConfig{Installer: Installer{Package: "nodejs"}})
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if artifact.URL != "asdf:nodejs@20.17.0" {
+		t.Errorf("Expected artifact asdf:nodejs@20.17.0 but got %s", artifact.URL)
+	}
+	expected := []string{
+		"asdf list all nodejs",
+		"asdf plugin add nodejs",
+		"asdf install nodejs 20.17.0",
+		"asdf set -u nodejs 20.17.0",
+		"asdf global nodejs 20.17.0",
+	}
+	if !reflect.DeepEqual(*calls, expected) {
+		t.Errorf("Expected calls %v but got %v", expected, *calls)
+	}
+
+	// A required version is installed as is, without listing versions
+	*calls = nil
+	dep = &Dependency{Name: "python", Version: Version{Required: "3.12.4"}}
+	if _, err := (runtimeInstaller{name: "pyenv"}).install(context.Background(), manager, dep, &PlatformConfig{}); err == nil {
+		t.Error("Expected an error when pyenv global fails but got none")
+	}
+	if len(*calls) == 0 || (*calls)[0] != "pyenv install --skip-existing 3.12.4" {
+		t.Errorf("Expected pyenv to install 3.12.4 first but got %v", *calls)
+	}
+}
+
+func TestRuntimeInstallProjectScope(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "depman-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	versionFile := filepath.Join(tempDir, ".tool-versions")
+	if err := os.WriteFile(versionFile, []byte("python 3.12.4\nnodejs 18.20.4\n"), 0644); err != nil {
+		t.Fatalf("Failed to write version file: %v", err)
+	}
+
+	fakeCommands(t, map[string]string{"mise install nodejs@20.17.0": ""})
+	manager := &Manager{
+		Platform:   "linux",
+		logger:     &mockLogger{},
+		scope:      ScopeProject,
+		ConfigPath: filepath.Join(tempDir, "dependencies.yml"),
+	}
+	dep := &Dependency{Name: "node", Version: Version{Required: "20.17.0"}}
+
+	if _, err := (runtimeInstaller{name: "mise"}).install(context.Background(), manager, dep, &PlatformConfig{Installer: Installer{Package: "nodejs"}}); err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	data, err := os.ReadFile(versionFile)
+	if err != nil {
+		t.Fatalf("Failed to read version file: %v", err)
+	}
+	if expected := "python 3.12.4\nnodejs 20.17.0\n"; string(data) != expected {
+		t.Errorf("Expected version file %q but got %q", expected, string(data))
+	}
+}
+
+func TestRuntimeDetectVersion(t *testing.T) {
+	fakeCommands(t, map[string]string{
+		"rbenv versions --bare": "3.2.5\n3.3.4\n",
+	})
+	manager := &Manager{Platform: "linux", logger: &mockLogger{}}
+	installer := runtimeInstaller{name: "rbenv"}
+
+	testCases := []struct {
+		version  Version
+		expected string
+	}{
+		{Version{Required: "3.2.5"}, "3.2.5"},
+		{Version{Constraint: "~3.3"}, "3.3.4"},
+		{Version{Required: "3.4.0"}, "3.3.4"},
+		{Version{}, "3.3.4"},
+	}
+
+	for _, tc := range testCases {
+		dep := &Dependency{Name: "ruby", Version: tc.version}
+		version, err := installer.detectVersion(context.Background(), manager, dep, &PlatformConfig{})
+		if err != nil {
+			t.Fatalf("Did not expect an error but got: %v", err)
+		}
+		if version != tc.expected {
+			t.Errorf("Expected version %s but got %s", tc.expected, version)
+		}
+	}
+
+	// nvm is a shell function; a failing probe means it is not installed
+	_, err := (runtimeInstaller{name: "nvm"}).detectVersion(context.Background(), manager, &Dependency{Name: "node"}, &PlatformConfig{})
+	if err == nil || !strings.Contains(err.Error(), "nvm is not installed") {
+		t.Errorf("Expected an error containing %q but got: %v", "nvm is not installed", err)
+	}
+}
@@ -0,0 +1,224 @@
+package depman
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// scoopInstaller installs apps per user with Scoop, adding the app's bucket
+// when it is missing
+type scoopInstaller struct{}
+
+func init() {
+	installStrategies["scoop"] = scoopInstaller{}
+}
+
+// findScoop locates the scoop executable
+func findScoop(ctx context.Context) (string, error) {
+	scoop, err := hostLookPath(ctx, "scoop")
+	if err != nil {
+		return "", fmt.Errorf("scoop is not installed")
+	}
+	return scoop, nil
+}
+
+// scoopApp returns the name scoop lists the app under
+func scoopApp(dep *Dependency, platformConfig *PlatformConfig) string {
+	return packageName(dep, platformConfig)
+}
+
+// scoopSource returns what to pass to `scoop install`: the pinned manifest,
+// or the app qualified by its bucket and required version
+func scoopSource(dep *Dependency, platformConfig *PlatformConfig) string {
+	if platformConfig.Installer.Manifest != "" {
+		return platformConfig.Installer.Manifest
+	}
+	source := scoopApp(dep, platformConfig)
+	if platformConfig.Installer.Bucket != "" {
+		source = platformConfig.Installer.Bucket + "/" + source
+	}
+	if dep.Version.Required != "" {
+		source += "@" + dep.Version.Required
+	}
+	return source
+}
+
+// install adds the bucket if needed, then installs the app, replacing an
+// installed version that does not match the required one
+func (s scoopInstaller) install(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) (LockedArtifact, error) {
+	var artifact LockedArtifact
+
+	scoop, err := findScoop(ctx)
+	if err != nil {
+		return artifact, err
+	}
+	if err := ensureScoopBucket(ctx, m, scoop, platformConfig); err != nil {
+		return artifact, err
+	}
+
+	app := scoopApp(dep, platformConfig)
+	source := scoopSource(dep, platformConfig)
+	args := append([]string{"install", source}, platformConfig.Installer.Args...)
+
+	if installed, err := s.detectVersion(ctx, m, dep, platformConfig); err == nil {
+		if platformConfig.Installer.Pin {
+			// Held apps are skipped by update, so release the hold first
+			runCommand(ctx, scoop, "unhold", app)
+		}
+		switch {
+		case platformConfig.Installer.Manifest != "" || (dep.Version.Required != "" && installed != dep.Version.Required):
+			// scoop refuses to install over an installed app
+			m.logger.Infof("Running scoop uninstall %s to replace %s", app, installed)
+			if _, err := runCommand(ctx, scoop, "uninstall", app); err != nil {
+				return artifact, fmt.Errorf("failed to replace %s %s: %w", app, installed, err)
+			}
+		case dep.Version.Required == "":
+			args = append([]string{"update", app}, platformConfig.Installer.Args...)
+		default:
+			args = nil
+		}
+	}
+
+	if args != nil {
+		m.logger.Infof("Running scoop %s %s", args[0], args[1])
+		if _, err := m.retryCommand(ctx, dep, scoop, args...); err != nil {
+			return artifact, fmt.Errorf("installation failed: %w", err)
+		}
+	}
+
+	if platformConfig.Installer.Pin {
+		if _, err := runCommand(ctx, scoop, "hold", app); err != nil {
+			m.logger.Warnf("Failed to hold %s: %v", app, err)
+		}
+	}
+
+	m.logger.Infof("Successfully installed %s", dep.Name)
+	artifact.URL = "scoop:" + source
+	return artifact, nil
+}
+
+// ensureScoopBucket adds the configured bucket unless scoop already knows it
+func ensureScoopBucket(ctx context.Context, m *Manager, scoop string, platformConfig *PlatformConfig) error {
+	bucket := platformConfig.Installer.Bucket
+	if bucket == "" {
+		return nil
+	}
+
+	output, err := runCommand(ctx, scoop, "bucket", "list")
+	if err == nil && hasScoopBucket(output, bucket) {
+		return nil
+	}
+
+	args := []string{"bucket", "add", bucket}
+	if platformConfig.Installer.BucketURL != "" {
+		args = append(args, platformConfig.Installer.BucketURL)
+	}
+	m.logger.Infof("Adding scoop bucket %s", bucket)
+	if _, err := runCommand(ctx, scoop, args...); err != nil {
+		return fmt.Errorf("failed to add scoop bucket %s: %w", bucket, err)
+	}
+	return nil
+}
+
+// hasScoopBucket reports whether `scoop bucket list` output names a bucket
+func hasScoopBucket(output, bucket string) bool {
+	for _, line := range strings.Split(output, "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 && strings.EqualFold(fields[0], bucket) {
+			return true
+		}
+	}
+	return false
+}
+
+// uninstall releases any hold and removes the app
+func (scoopInstaller) uninstall(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) error {
+	scoop, err := findScoop(ctx)
+	if err != nil {
+		return err
+	}
+
+	app := scoopApp(dep, platformConfig)
+	if platformConfig.Installer.Pin {
+		runCommand(ctx, scoop, "unhold", app)
+	}
+
+	m.logger.Infof("Running scoop uninstall %s", app)
+	if _, err := runCommand(ctx, scoop, "uninstall", app); err != nil {
+		return fmt.Errorf("uninstallation failed: %w", err)
+	}
+	return nil
+}
+
+// detectVersion reads the installed version from `scoop list`
+func (scoopInstaller) detectVersion(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) (string, error) {
+	scoop, err := findScoop(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	app := scoopApp(dep, platformConfig)
+	output, err := runCommand(ctx, scoop, "list", app)
+	if err != nil {
+		return "", fmt.Errorf("%s is not installed: %w", app, err)
+	}
+
+	version := parseScoopList(output, app)
+	if version == "" {
+		return "", fmt.Errorf("%s is not installed", app)
+	}
+	return version, nil
+}
+
+// availableVersions reads the version the bucket's manifest offers from `scoop info`
+func (scoopInstaller) availableVersions(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) ([]string, error) {
+	scoop, err := findScoop(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	app := scoopApp(dep, platformConfig)
+	if platformConfig.Installer.Bucket != "" {
+		app = platformConfig.Installer.Bucket + "/" + app
+	}
+	output, err := runCommand(ctx, scoop, "info", app)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up %s: %w", app, err)
+	}
+
+	version := parseScoopInfo(output)
+	if version == "" {
+		return nil, fmt.Errorf("no version found for %s", app)
+	}
+	return []string{version}, nil
+}
+
+// parseScoopList finds the version column for an app in `scoop list` table output
+func parseScoopList(output, app string) string {
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && strings.EqualFold(fields[0], app) {
+			return fields[1]
+		}
+	}
+	return ""
+}
+
+// parseScoopInfo reads the newest version from `scoop info` "Key : Value"
+// output; installed apps report it as "Latest Version"
+func parseScoopInfo(output string) string {
+	var version string
+	for _, line := range strings.Split(output, "\n") {
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "Latest Version":
+			return strings.TrimSpace(value)
+		case "Version":
+			version = strings.TrimSpace(value)
+		}
+	}
+	return version
+}
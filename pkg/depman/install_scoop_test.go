@@ -0,0 +1,72 @@
+package depman
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestParseScoopList(t *testing.T) {
+	output := "Installed apps:\n\nName Version Source Updated             Info\n---- ------- ------ -------             ----\ngit  2.46.0  main   2024-08-01 10:00:00\njq   1.7.1   main   2024-08-01 10:00:00 Held package\n"
+	if got := parseScoopList(output, "jq"); got != "1.7.1" {
+		t.Errorf("Expected version 1.7.1 but got %s", got)
+	}
+	if got := parseScoopList(output, "fd"); got != "" {
+		t.Errorf("Expected no version but got %s", got)
+	}
+}
+
+func TestParseScoopInfo(t *testing.T) {
+	testCases := []struct {
+		name     string
+		output   string
+		expected string
+	}{
+		{"Not installed", "Name        : jq\nDescription : JSON processor\nVersion     : 1.7.1\nWebsite     : https://jqlang.github.io/jq\n", "1.7.1"},
+		{"Installed", "Name           : jq\nInstalled      : 1.6\nLatest Version : 1.7.1\nVersion        : 1.6\n", "1.7.1"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := parseScoopInfo(tc.output); got != tc.expected {
+				t.Errorf("Expected %s but got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestScoopInstall(t *testing.T) {
+	calls := fakeCommands(t, map[string]string{
+		"scoop bucket list":                  "Name Source\n---- ------\nmain https://github.com/ScoopInstaller/Main\n",
+		"scoop bucket add extras":            "",
+		"scoop list vscode":                  "Name   Version Source\n----   ------- ------\nvscode 1.91.0  extras\n",
+		"scoop unhold vscode":                "",
+		"scoop uninstall vscode":             "",
+		"scoop install extras/vscode@1.92.0": "",
+		"scoop hold vscode":                  "",
+	})
+	manager := &Manager{Platform: "windows", logger: &mockLogger{}}
+	dep := &Dependency{Name: "vscode", Version: Version{Required: "1.92.0"}}
+	platformConfig := &PlatformConfig{Installer: Installer{Method: "scoop", Bucket: "extras", Pin: true}}
+
+	artifact, err := (scoopInstaller{}).install(context.Background(), manager, dep, platformConfig)
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if artifact.URL != "scoop:extras/vscode@1.92.0" {
+		t.Errorf("Expected artifact scoop:extras/vscode@1.92.0 but got %s", artifact.URL)
+	}
+
+	expected := []string{
+		"scoop bucket list",
+		"scoop bucket add extras",
+		"scoop list vscode",
+		"scoop unhold vscode",
+		"scoop uninstall vscode",
+		"scoop install extras/vscode@1.92.0",
+		"scoop hold vscode",
+	}
+	if !reflect.DeepEqual(*calls, expected) {
+		t.Errorf("Expected calls %v but got %v", expected, *calls)
+	}
+}
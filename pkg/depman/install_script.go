@@ -0,0 +1,207 @@
+package depman
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// defaultScriptTimeout limits install scripts that do not set a timeout
+const defaultScriptTimeout = 10 * time.Minute
+
+// maxScriptOutput is how much of the output of an install script or command
+// is kept, from the end
+const maxScriptOutput = 64 * 1024
+
+// scriptBaseEnv are the variables every script gets so its shell and the
+// tools it runs work; everything else must be allowlisted
+var scriptBaseEnv = []string{
+	"PATH", "HOME", "USER", "LANG", "TMPDIR",
+	"SYSTEMROOT", "WINDIR", "COMSPEC", "PATHEXT", "TEMP", "TMP", "USERPROFILE",
+}
+
+// scriptShell describes how to run a script file with one interpreter
+type scriptShell struct {
+	ext  string                     // Extension the script file needs
+	args func(file string) []string // Command line running the file
+}
+
+// scriptShells lists the supported script interpreters
+var scriptShells = map[string]scriptShell{
+	"sh":   {ext: ".sh", args: func(file string) []string { return []string{"sh", "-e", file} }},
+	"bash": {ext: ".sh", args: func(file string) []string { return []string{"bash", "-e", file} }},
+	"pwsh": {ext: ".ps1", args: func(file string) []string {
+		return []string{"pwsh", "-NoProfile", "-NonInteractive", "-ExecutionPolicy", "Bypass", "-File", file}
+	}},
+	"powershell": {ext: ".ps1", args: func(file string) []string {
+		return []string{"powershell", "-NoProfile", "-NonInteractive", "-ExecutionPolicy", "Bypass", "-File", file}
+	}},
+	"cmd": {ext: ".cmd", args: func(file string) []string { return []string{"cmd", "/C", file} }},
+}
+
+// WithScriptsDisabled makes installs with the script method fail instead of
+// running configuration-supplied scripts
+func WithScriptsDisabled(disabled bool) Option {
+	return func(m *Manager) {
+		m.noScripts = disabled
+	}
+}
+
+// scriptInstaller runs an install script from the configuration with only
+// allowlisted environment variables, in a chosen working directory and under
+// a time limit, keeping its output for the dependency's status
+type scriptInstaller struct{}
+
+func init() {
+	installStrategies["script"] = scriptInstaller{}
+}
+
+// scriptShellName returns the interpreter for a platform's script
+func scriptShellName(platformConfig *PlatformConfig, platform string) string {
+	if platformConfig.Installer.Shell != "" {
+		return strings.ToLower(platformConfig.Installer.Shell)
+	}
+	if platform == "windows" {
+		return "powershell"
+	}
+	return "sh"
+}
+
+// install writes or locates the script and runs it
+func (scriptInstaller) install(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) (LockedArtifact, error) {
+	var artifact LockedArtifact
+
+	if m.noScripts {
+		return artifact, fmt.Errorf("script installs are disabled; install %s another way", dep.Name)
+	}
+	installer := platformConfig.Installer
+	if (installer.Script == "") == (installer.ScriptFile == "") {
+		return artifact, fmt.Errorf("exactly one of script and script_file must be provided for dependency: %s", dep.Name)
+	}
+	shellName := scriptShellName(platformConfig, m.Platform)
+	shell, ok := scriptShells[shellName]
+	if !ok {
+		return artifact, fmt.Errorf("unsupported script shell: %s", shellName)
+	}
+	timeout, err := parseTimeout(installer.Timeout)
+	if err != nil {
+		return artifact, fmt.Errorf("invalid script timeout: %w", err)
+	}
+	if timeout == 0 {
+		timeout = defaultScriptTimeout
+	}
+
+	tempDir, err := os.MkdirTemp("", "depman-script-*")
+	if err != nil {
+		return artifact, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	file := installer.ScriptFile
+	if file != "" {
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(m.configDir(), file)
+		}
+		artifact.URL = "script:" + installer.ScriptFile
+	} else {
+		file = filepath.Join(tempDir, "install"+shell.ext)
+		if err := os.WriteFile(file, []byte(installer.Script), 0700); err != nil {
+			return artifact, fmt.Errorf("failed to write install script: %w", err)
+		}
+	}
+
+	workDir := filepath.Join(tempDir, "work")
+	if installer.WorkDir != "" {
+		workDir = installer.WorkDir
+		if !filepath.IsAbs(workDir) {
+			workDir = filepath.Join(m.configDir(), workDir)
+		}
+	} else if err := os.Mkdir(workDir, 0700); err != nil {
+		return artifact, fmt.Errorf("failed to create working directory: %w", err)
+	}
+
+	scriptCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	args := shell.args(file)
+	m.logger.Infof("Running install script for %s with %s", dep.Name, shellName)
+	cmd := exec.CommandContext(scriptCtx, args[0], args[1:]...)
+	cmd.Dir = workDir
+	cmd.Env = m.scriptEnv(dep, platformConfig)
+	// Do not wait on children that keep the output open past a timeout
+	cmd.WaitDelay = 5 * time.Second
+
+	// The log gets all of the output, the status its end
+	output, full := &tailBuffer{limit: maxScriptOutput}, &bytes.Buffer{}
+	cmd.Stdout = io.MultiWriter(output, full)
+	cmd.Stderr = cmd.Stdout
+	err = cmd.Run()
+	installLogOf(ctx).record(args[0], args[1:], full.Bytes(), err)
+	m.recordOutput(dep.Name, output.String())
+	if err != nil {
+		if scriptCtx.Err() == context.DeadlineExceeded {
+			return artifact, fmt.Errorf("install script for %s timed out after %s", dep.Name, timeout)
+		}
+		return artifact, fmt.Errorf("install script failed: %w, output: %s", err, output.String())
+	}
+
+	m.logger.Debugf("Install script output for %s: %s", dep.Name, output.String())
+	m.logger.Infof("Successfully installed %s", dep.Name)
+	return artifact, nil
+}
+
+// scriptEnv returns the environment of an install script: the base and
+// allowlisted variables that are set, and depman's description of the install
+func (m *Manager) scriptEnv(dep *Dependency, platformConfig *PlatformConfig) []string {
+	var env []string
+	for _, name := range append(append([]string{}, scriptBaseEnv...), platformConfig.Installer.Env...) {
+		if value, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+value)
+		}
+	}
+	return append(env,
+		"DEPMAN_DEPENDENCY="+dep.Name,
+		"DEPMAN_VERSION="+dep.Version.Required,
+		"DEPMAN_CONSTRAINT="+dep.Version.Constraint,
+		"DEPMAN_PLATFORM="+m.Platform,
+		"DEPMAN_ARCH="+m.targetArch(),
+		"DEPMAN_HOME="+m.HomeDir(),
+		"DEPMAN_BIN_DIR="+m.BinDir(),
+	)
+}
+
+// uninstall runs the platform's uninstall command
+func (scriptInstaller) uninstall(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) error {
+	return commandInstaller{}.uninstall(ctx, m, dep, platformConfig)
+}
+
+// tailBuffer keeps the last limit bytes written to it
+type tailBuffer struct {
+	limit     int
+	data      []byte
+	truncated bool
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.data = append(b.data, p...)
+	if over := len(b.data) - b.limit; over > 0 {
+		b.data = append(b.data[:0], b.data[over:]...)
+		b.truncated = true
+	}
+	return len(p), nil
+}
+
+// String returns the kept output, marking where earlier output was dropped
+func (b *tailBuffer) String() string {
+	output := strings.TrimSpace(string(b.data))
+	if b.truncated {
+		return "[earlier output truncated]\n" + output
+	}
+	return output
+}
@@ -0,0 +1,88 @@
+package depman
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestScriptInstaller(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("install scripts run with sh in this test")
+	}
+
+	configDir := t.TempDir()
+	t.Setenv("DEPMAN_TEST_ALLOWED", "allowed")
+	t.Setenv("DEPMAN_TEST_SECRET", "secret")
+
+	manager := &Manager{Platform: "linux", ConfigPath: filepath.Join(configDir, "deps.yml"), homeDir: t.TempDir(), logger: &mockLogger{}}
+	dep := &Dependency{Name: "tool", Version: Version{Required: "1.2.3"}}
+	platformConfig := &PlatformConfig{Installer: Installer{
+		Method:  "script",
+		Script:  "echo \"$DEPMAN_DEPENDENCY $DEPMAN_VERSION ${DEPMAN_TEST_ALLOWED:-} ${DEPMAN_TEST_SECRET:-withheld}\"\npwd > where\n",
+		Env:     []string{"DEPMAN_TEST_ALLOWED"},
+		WorkDir: "work",
+	}}
+	if err := os.Mkdir(filepath.Join(configDir, "work"), 0755); err != nil {
+		t.Fatalf("Failed to create working directory: %v", err)
+	}
+
+	if _, err := (scriptInstaller{}).install(context.Background(), manager, dep, platformConfig); err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if output := manager.takeOutput("tool"); output != "tool 1.2.3 allowed withheld" {
+		t.Errorf("Expected only allowlisted variables in the output but got %q", output)
+	}
+	if _, err := os.Stat(filepath.Join(configDir, "work", "where")); err != nil {
+		t.Errorf("Expected the script to run in the configured working directory: %v", err)
+	}
+
+	// The configuration's interpolation leaves inline scripts alone, so only
+	// their environment reaches them
+	dep.Platforms = map[string]PlatformConfig{"linux": {Installer: Installer{
+		Method: "script",
+		Script: "for f in a; do echo \"${f} ${DEPMAN_VERSION} ${DEPMAN_TEST_SECRET:-withheld}\"; done\n",
+	}}}
+	interpolatedConfig, err := manager.GetPlatformConfig(dep)
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if _, err := (scriptInstaller{}).install(context.Background(), manager, dep, interpolatedConfig); err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if output := manager.takeOutput("tool"); output != "a 1.2.3 withheld" {
+		t.Errorf("Expected the script to expand its own variables but got %q", output)
+	}
+
+	// Failures keep the output for the status
+	platformConfig.Installer.Script = "echo downloading\nexit 3\n"
+	if _, err := (scriptInstaller{}).install(context.Background(), manager, dep, platformConfig); err == nil {
+		t.Error("Expected an error when the script fails but got none")
+	}
+	if output := manager.takeOutput("tool"); output != "downloading" {
+		t.Errorf("Expected the output of the failed script but got %q", output)
+	}
+
+	platformConfig.Installer.Script = "exec sleep 5\n"
+	platformConfig.Installer.Timeout = "100ms"
+	if _, err := (scriptInstaller{}).install(context.Background(), manager, dep, platformConfig); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("Expected the script to time out but got: %v", err)
+	}
+
+	WithScriptsDisabled(true)(manager)
+	if _, err := (scriptInstaller{}).install(context.Background(), manager, dep, platformConfig); err == nil || !strings.Contains(err.Error(), "disabled") {
+		t.Errorf("Expected script installs to be refused but got: %v", err)
+	}
+}
+
+func TestTailBuffer(t *testing.T) {
+	buffer := &tailBuffer{limit: 8}
+	buffer.Write([]byte("0123456789"))
+	buffer.Write([]byte("ab"))
+	if got, expected := buffer.String(), "[earlier output truncated]\n456789ab"; got != expected {
+		t.Errorf("Expected %q but got %q", expected, got)
+	}
+}
@@ -0,0 +1,203 @@
+package depman
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// serviceMethod is the install method of service dependencies
+const serviceMethod = "service"
+
+// Default limits of service health checks
+const (
+	defaultProbeTimeout = 5 * time.Second
+	defaultServiceWait  = 30 * time.Second
+)
+
+// serviceProbeInterval is how often a started service is probed until it is healthy; replaced in tests
+var serviceProbeInterval = time.Second
+
+// serviceInstaller starts services that are not running and waits for them to become healthy
+type serviceInstaller struct{}
+
+func init() {
+	installStrategies[serviceMethod] = serviceInstaller{}
+}
+
+// validate checks that a service has probes that each set one valid check
+func (s ServiceSpec) validate() error {
+	if len(s.Probes) == 0 {
+		return fmt.Errorf("missing service probes")
+	}
+	for i, probe := range s.Probes {
+		if err := probe.validate(); err != nil {
+			return fmt.Errorf("service probe %d: %w", i+1, err)
+		}
+	}
+	if s.Wait != "" {
+		if _, err := parseTimeout(s.Wait); err != nil {
+			return fmt.Errorf("invalid service wait: %w", err)
+		}
+	}
+	return nil
+}
+
+// validate checks that a probe sets exactly one check and that it is well formed
+func (p ServiceProbe) validate() error {
+	checks := 0
+	for _, set := range []bool{p.TCP != "", p.HTTP != "", len(p.Command) > 0, p.Systemd != ""} {
+		if set {
+			checks++
+		}
+	}
+	if checks != 1 {
+		return fmt.Errorf("expected exactly one of tcp, http, command or systemd")
+	}
+
+	if p.TCP != "" {
+		if _, _, err := net.SplitHostPort(p.TCP); err != nil {
+			return fmt.Errorf("invalid tcp address '%s': %w", p.TCP, err)
+		}
+	}
+	if p.HTTP != "" && !strings.Contains(p.HTTP, "${") {
+		if u, err := url.Parse(p.HTTP); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid http URL '%s'", redactURL(p.HTTP))
+		}
+	}
+	if p.Timeout != "" {
+		if _, err := parseTimeout(p.Timeout); err != nil {
+			return fmt.Errorf("invalid timeout: %w", err)
+		}
+	}
+	return nil
+}
+
+// String describes the probe's check, e.g. "tcp localhost:5432", with the
+// credentials of its URL hidden
+func (p ServiceProbe) String() string {
+	switch {
+	case p.TCP != "":
+		return "tcp " + p.TCP
+	case p.HTTP != "":
+		return "http " + redactURL(p.HTTP)
+	case len(p.Command) > 0:
+		return "command " + strings.Join(p.Command, " ")
+	default:
+		return "systemd " + p.Systemd
+	}
+}
+
+// run performs the check, bounded by the probe's timeout. ${VAR} references
+// in the URL are resolved only for the request.
+func (p ServiceProbe) run(ctx context.Context, m *Manager, dep *Dependency) error {
+	timeout := defaultProbeTimeout
+	if d, err := parseTimeout(p.Timeout); err == nil && d > 0 {
+		timeout = d
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	switch {
+	case p.TCP != "":
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", p.TCP)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	case p.HTTP != "":
+		target, err := m.expandURL(p.HTTP, dep)
+		if err != nil {
+			return err
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+		if err != nil {
+			// The error would show the expanded URL
+			return fmt.Errorf("invalid http URL '%s'", redactURL(p.HTTP))
+		}
+		resp, err := m.client().Do(req)
+		if err != nil {
+			return redactURLError(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= http.StatusBadRequest {
+			return fmt.Errorf("status %s", resp.Status)
+		}
+		return nil
+	case len(p.Command) > 0:
+		_, err := runCommand(ctx, p.Command[0], p.Command[1:]...)
+		return err
+	default:
+		_, err := runCommand(ctx, "systemctl", "is-active", "--quiet", p.Systemd)
+		return err
+	}
+}
+
+// checkService runs the probes of a service dependency and returns the first failure
+func checkService(ctx context.Context, m *Manager, dep *Dependency) error {
+	for _, probe := range dep.Service.Probes {
+		if err := probe.run(ctx, m, dep); err != nil {
+			return fmt.Errorf("%s failed: %w", probe, err)
+		}
+	}
+	return nil
+}
+
+// install starts the service if it is not healthy and waits until it is
+func (serviceInstaller) install(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) (LockedArtifact, error) {
+	var artifact LockedArtifact
+
+	spec := dep.Service
+	if err := spec.validate(); err != nil {
+		return artifact, err
+	}
+	err := checkService(ctx, m, dep)
+	if err == nil {
+		return artifact, nil
+	}
+	if len(spec.Start) == 0 {
+		return artifact, fmt.Errorf("service %s is not running and has no start command: %w", dep.Name, err)
+	}
+
+	m.logger.Infof("Starting %s using command: %s", dep.Name, strings.Join(spec.Start, " "))
+	if _, err := runCommand(ctx, spec.Start[0], spec.Start[1:]...); err != nil {
+		return artifact, fmt.Errorf("failed to start %s: %w", dep.Name, err)
+	}
+
+	wait := defaultServiceWait
+	if d, err := parseTimeout(spec.Wait); err == nil && d > 0 {
+		wait = d
+	}
+	deadline := time.Now().Add(wait)
+	for {
+		err := checkService(ctx, m, dep)
+		if err == nil {
+			m.logger.Infof("Service %s is healthy", dep.Name)
+			return artifact, nil
+		}
+		if time.Now().After(deadline) {
+			return artifact, fmt.Errorf("service %s did not become healthy within %s: %w", dep.Name, wait, err)
+		}
+		select {
+		case <-ctx.Done():
+			return artifact, ctx.Err()
+		case <-time.After(serviceProbeInterval):
+		}
+	}
+}
+
+// detectVersion reports "running" if every probe passes; services have no version
+func (serviceInstaller) detectVersion(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) (string, error) {
+	if err := dep.Service.validate(); err != nil {
+		return "", err
+	}
+	if err := checkService(ctx, m, dep); err != nil {
+		return "", fmt.Errorf("service %s is not healthy: %w", dep.Name, err)
+	}
+	return "running", nil
+}
@@ -0,0 +1,152 @@
+package depman
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServiceProbeValidate(t *testing.T) {
+	testCases := []struct {
+		name  string
+		probe ServiceProbe
+		valid bool
+	}{
+		{"TCP", ServiceProbe{TCP: "localhost:5432"}, true},
+		{"HTTP", ServiceProbe{HTTP: "http://localhost:8080/health", Timeout: "2s"}, true},
+		{"Command", ServiceProbe{Command: []string{"docker", "info"}}, true},
+		{"Systemd", ServiceProbe{Systemd: "docker.service"}, true},
+		{"No check", ServiceProbe{}, false},
+		{"Two checks", ServiceProbe{TCP: "localhost:5432", Systemd: "postgresql"}, false},
+		{"TCP without port", ServiceProbe{TCP: "localhost"}, false},
+		{"HTTP without scheme", ServiceProbe{HTTP: "localhost:8080"}, false},
+		{"Interpolated URL", ServiceProbe{HTTP: "${DB_URL}/health"}, true},
+		{"Invalid timeout", ServiceProbe{TCP: "localhost:5432", Timeout: "soon"}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.probe.validate(); (err == nil) != tc.valid {
+				t.Errorf("Expected valid to be %v but got error: %v", tc.valid, err)
+			}
+		})
+	}
+}
+
+func TestServiceHealth(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer healthy.Close()
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+	secure := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("token") != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer secure.Close()
+
+	manager := &Manager{Platform: "linux", logger: &mockLogger{}}
+	testCases := []struct {
+		name     string
+		probes   []ServiceProbe
+		expected string // Substring of the error, or "" if healthy
+	}{
+		{"Healthy", []ServiceProbe{{TCP: listener.Addr().String()}, {HTTP: healthy.URL}}, ""},
+		{"HTTP error status", []ServiceProbe{{HTTP: failing.URL}}, "503"},
+		{"Command failure", []ServiceProbe{{Command: []string{"pg_isready"}}}, "command pg_isready failed"},
+		{"URL expanded for the request", []ServiceProbe{{HTTP: secure.URL + "?token=${DEPMAN_TEST_TOKEN}"}}, ""},
+		{"URL credentials", []ServiceProbe{{HTTP: strings.Replace(failing.URL, "://", "://admin:s3cret@", 1)}}, "admin:xxxxx@"},
+		{"URL credentials from the environment", []ServiceProbe{{HTTP: failing.URL + "?token=${DEPMAN_TEST_TOKEN}"}}, "token=xxxxx"},
+	}
+
+	t.Setenv("DEPMAN_TEST_TOKEN", "s3cret")
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeCommands(t, map[string]string{})
+			dep := &Dependency{Name: "db", Kind: "service", Service: ServiceSpec{Probes: tc.probes}}
+
+			version, err := (serviceInstaller{}).detectVersion(context.Background(), manager, dep, nil)
+			if tc.expected == "" {
+				if err != nil || version != "running" {
+					t.Errorf("Expected the service to be running but got %q, %v", version, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.expected) {
+				t.Errorf("Expected an error containing %q but got: %v", tc.expected, err)
+			}
+			if err != nil && strings.Contains(err.Error(), "s3cret") {
+				t.Errorf("Expected the credentials to be hidden but got: %v", err)
+			}
+		})
+	}
+}
+
+func TestServiceInstallerStart(t *testing.T) {
+	originalInterval := serviceProbeInterval
+	serviceProbeInterval = time.Millisecond
+	defer func() { serviceProbeInterval = originalInterval }()
+
+	manager := &Manager{Platform: "linux", logger: &mockLogger{}}
+	dep := &Dependency{Name: "docker", Kind: "service", Service: ServiceSpec{
+		Probes: []ServiceProbe{{Systemd: "docker"}},
+		Start:  []string{"systemctl", "start", "docker"},
+		Wait:   "1s",
+	}}
+
+	t.Run("Starts and waits", func(t *testing.T) {
+		calls := fakeCommands(t, map[string]string{"systemctl start docker": ""})
+		// The unit reports active once it has been started
+		run := runCommand
+		runCommand = func(ctx context.Context, name string, args ...string) (string, error) {
+			line := strings.Join(append([]string{name}, args...), " ")
+			if line == "systemctl is-active --quiet docker" && strings.Contains(strings.Join(*calls, "|"), "systemctl start docker") {
+				*calls = append(*calls, line)
+				return "", nil
+			}
+			return run(ctx, name, args...)
+		}
+
+		if _, err := (serviceInstaller{}).install(context.Background(), manager, dep, nil); err != nil {
+			t.Fatalf("Did not expect an error but got: %v", err)
+		}
+		expected := []string{"systemctl is-active --quiet docker", "systemctl start docker", "systemctl is-active --quiet docker"}
+		if strings.Join(*calls, "|") != strings.Join(expected, "|") {
+			t.Errorf("Expected calls %v but got %v", expected, *calls)
+		}
+	})
+
+	t.Run("Never healthy", func(t *testing.T) {
+		fakeCommands(t, map[string]string{"systemctl start docker": ""})
+		dep := *dep
+		dep.Service.Wait = "10ms"
+
+		_, err := (serviceInstaller{}).install(context.Background(), manager, &dep, nil)
+		if err == nil || !strings.Contains(err.Error(), "did not become healthy within 10ms") {
+			t.Errorf("Expected the wait to time out but got: %v", err)
+		}
+	})
+
+	t.Run("No start command", func(t *testing.T) {
+		fakeCommands(t, map[string]string{})
+		dep := *dep
+		dep.Service.Start = nil
+
+		_, err := (serviceInstaller{}).install(context.Background(), manager, &dep, nil)
+		if err == nil || !strings.Contains(err.Error(), "has no start command") {
+			t.Errorf("Expected a missing start command error but got: %v", err)
+		}
+	})
+}
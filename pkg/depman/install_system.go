@@ -0,0 +1,331 @@
+package depman
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// systemPackageManager describes how to drive one Linux distribution package manager
+type systemPackageManager struct {
+	binary  string                             // Executable used to detect the package manager
+	refresh []string                           // Command that refreshes package indexes before installing
+	install func(pkg, version string) []string // Command that installs a package, optionally pinned
+	remove  func(pkg string) []string          // Command that removes a package
+	query   func(pkg string) []string          // Command that prints the installed version
+	parse   func(pkg, output string) string    // Extracts the version from query output
+}
+
+// rpmQuery queries the installed version of an RPM package
+func rpmQuery(pkg string) []string {
+	return []string{"rpm", "-q", "--qf", "%{VERSION}", pkg}
+}
+
+// rawVersion returns query output unchanged
+func rawVersion(pkg, output string) string {
+	return strings.TrimSpace(output)
+}
+
+// systemPackageManagers lists the supported distribution package managers
+var systemPackageManagers = map[string]systemPackageManager{
+	"apt": {
+		binary:  "apt-get",
+		refresh: []string{"env", "DEBIAN_FRONTEND=noninteractive", "apt-get", "update", "-q"},
+		install: func(pkg, version string) []string {
+			if version != "" {
+				pkg += "=" + version
+			}
+			return []string{"env", "DEBIAN_FRONTEND=noninteractive", "apt-get", "install", "-y", "-q", "--no-install-recommends", pkg}
+		},
+		remove: func(pkg string) []string {
+			return []string{"env", "DEBIAN_FRONTEND=noninteractive", "apt-get", "remove", "-y", "-q", pkg}
+		},
+		query: func(pkg string) []string {
+			return []string{"dpkg-query", "-W", "-f=${Version}", pkg}
+		},
+		parse: rawVersion,
+	},
+	"dnf": {
+		binary: "dnf",
+		install: func(pkg, version string) []string {
+			if version != "" {
+				pkg += "-" + version
+			}
+			return []string{"dnf", "install", "-y", "-q", pkg}
+		},
+		remove: func(pkg string) []string { return []string{"dnf", "remove", "-y", "-q", pkg} },
+		query:  rpmQuery,
+		parse:  rawVersion,
+	},
+	"yum": {
+		binary: "yum",
+		install: func(pkg, version string) []string {
+			if version != "" {
+				pkg += "-" + version
+			}
+			return []string{"yum", "install", "-y", "-q", pkg}
+		},
+		remove: func(pkg string) []string { return []string{"yum", "remove", "-y", "-q", pkg} },
+		query:  rpmQuery,
+		parse:  rawVersion,
+	},
+	"zypper": {
+		binary: "zypper",
+		install: func(pkg, version string) []string {
+			if version != "" {
+				pkg += "=" + version
+			}
+			return []string{"zypper", "--non-interactive", "install", pkg}
+		},
+		remove: func(pkg string) []string { return []string{"zypper", "--non-interactive", "remove", pkg} },
+		query:  rpmQuery,
+		parse:  rawVersion,
+	},
+	"pacman": {
+		binary: "pacman",
+		// pacman only offers the repository version, so pins are not supported
+		install: func(pkg, version string) []string {
+			return []string{"pacman", "-Sy", "--noconfirm", "--needed", pkg}
+		},
+		remove: func(pkg string) []string { return []string{"pacman", "-R", "--noconfirm", pkg} },
+		query:  func(pkg string) []string { return []string{"pacman", "-Q", pkg} },
+		parse: func(pkg, output string) string {
+			// Output looks like "jq 1.7.1-1"
+			fields := strings.Fields(output)
+			if len(fields) < 2 {
+				return ""
+			}
+			return fields[1]
+		},
+	},
+	"apk": {
+		binary: "apk",
+		install: func(pkg, version string) []string {
+			if version != "" {
+				pkg += "=" + version
+			}
+			return []string{"apk", "add", "--no-cache", pkg}
+		},
+		remove: func(pkg string) []string { return []string{"apk", "del", pkg} },
+		query:  func(pkg string) []string { return []string{"apk", "info", "-e", "-v", pkg} },
+		parse: func(pkg, output string) string {
+			// Output looks like "jq-1.7.1-r0"
+			return strings.TrimPrefix(strings.TrimSpace(output), pkg+"-")
+		},
+	},
+}
+
+// distroPackageManagers maps os-release IDs (and ID_LIKE entries) to package managers
+var distroPackageManagers = map[string]string{
+	"debian":              "apt",
+	"ubuntu":              "apt",
+	"linuxmint":           "apt",
+	"pop":                 "apt",
+	"raspbian":            "apt",
+	"kali":                "apt",
+	"fedora":              "dnf",
+	"rhel":                "dnf",
+	"centos":              "dnf",
+	"rocky":               "dnf",
+	"almalinux":           "dnf",
+	"ol":                  "dnf",
+	"amzn":                "yum",
+	"opensuse":            "zypper",
+	"opensuse-leap":       "zypper",
+	"opensuse-tumbleweed": "zypper",
+	"suse":                "zypper",
+	"sles":                "zypper",
+	"arch":                "pacman",
+	"manjaro":             "pacman",
+	"endeavouros":         "pacman",
+	"alpine":              "apk",
+}
+
+// yumReleases are the distributions whose releases before 8 ship yum instead of dnf
+var yumReleases = []string{"rhel", "centos", "ol"}
+
+// osReleasePath is the location of the os-release file; replaced in tests
+var osReleasePath = "/etc/os-release"
+
+// systemInstaller installs packages with a Linux distribution package manager.
+// An empty manager name selects the package manager from the detected distribution.
+type systemInstaller struct {
+	manager string
+}
+
+func init() {
+	installStrategies["system"] = systemInstaller{}
+	for name := range systemPackageManagers {
+		installStrategies[name] = systemInstaller{manager: name}
+	}
+}
+
+// install refreshes package indexes and installs the package with root privileges
+func (s systemInstaller) install(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) (LockedArtifact, error) {
+	var artifact LockedArtifact
+
+	name, pm, err := s.resolve(m)
+	if err != nil {
+		return artifact, err
+	}
+
+	pkg := systemPackageName(name, m.Distro(), dep, platformConfig)
+
+	if pm.refresh != nil {
+		refresh, err := m.elevate(pm.refresh)
+		if err != nil {
+			return artifact, err
+		}
+		if _, err := m.retryCommand(ctx, dep, refresh[0], refresh[1:]...); err != nil {
+			m.logger.Warnf("Failed to refresh %s package indexes: %v", name, err)
+		}
+	}
+
+	install, err := m.elevate(append(pm.install(pkg, platformConfig.Installer.Version), platformConfig.Installer.Args...))
+	if err != nil {
+		return artifact, err
+	}
+
+	m.logger.Infof("Installing %s using %s", pkg, name)
+	if _, err := m.retryCommand(ctx, dep, install[0], install[1:]...); err != nil {
+		return artifact, fmt.Errorf("installation failed: %w", err)
+	}
+
+	m.logger.Infof("Successfully installed %s", dep.Name)
+	artifact.URL = name + ":" + pkg
+	return artifact, nil
+}
+
+// uninstall removes the package with root privileges
+func (s systemInstaller) uninstall(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) error {
+	name, pm, err := s.resolve(m)
+	if err != nil {
+		return err
+	}
+
+	pkg := systemPackageName(name, m.Distro(), dep, platformConfig)
+	remove, err := m.elevate(pm.remove(pkg))
+	if err != nil {
+		return err
+	}
+
+	m.logger.Infof("Removing %s using %s", pkg, name)
+	if _, err := runCommand(ctx, remove[0], remove[1:]...); err != nil {
+		return fmt.Errorf("uninstallation failed: %w", err)
+	}
+
+	return nil
+}
+
+// detectVersion queries the package database for the installed version
+func (s systemInstaller) detectVersion(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) (string, error) {
+	name, pm, err := s.resolve(m)
+	if err != nil {
+		return "", err
+	}
+
+	pkg := systemPackageName(name, m.Distro(), dep, platformConfig)
+	query := pm.query(pkg)
+	output, err := runCommand(ctx, query[0], query[1:]...)
+	if err != nil {
+		return "", fmt.Errorf("%s is not installed: %w", pkg, err)
+	}
+
+	version := pm.parse(pkg, output)
+	if version == "" {
+		return "", fmt.Errorf("%s is not installed", pkg)
+	}
+
+	return version, nil
+}
+
+// resolve returns the package manager to use, detecting it if none was configured
+func (s systemInstaller) resolve(m *Manager) (string, systemPackageManager, error) {
+	name := s.manager
+	if name == "" {
+		detected, err := detectSystemPackageManager(m.Distro())
+		if err != nil {
+			return "", systemPackageManager{}, err
+		}
+		name = detected
+	}
+
+	pm := systemPackageManagers[name]
+	if _, err := lookPath(pm.binary); err != nil {
+		return "", systemPackageManager{}, fmt.Errorf("package manager %s is not available", name)
+	}
+
+	return name, pm, nil
+}
+
+// detectSystemPackageManager picks the package manager for a distribution
+// (the running one if nil) by its ID and family, falling back to the first
+// package manager found on PATH
+func detectSystemPackageManager(distro *Distro) (string, error) {
+	if distro == nil {
+		distro = DetectDistro()
+	}
+	if distro != nil {
+		for _, id := range distro.family() {
+			name, ok := distroPackageManagers[id]
+			if !ok {
+				continue
+			}
+			if name == "dnf" && containsString(yumReleases, distro.ID) && distro.Version != "" && compareDistroVersions(distro.Version, "8") < 0 {
+				name = "yum"
+			}
+			return name, nil
+		}
+	}
+
+	for _, name := range []string{"apt", "dnf", "yum", "zypper", "pacman", "apk"} {
+		if _, err := lookPath(systemPackageManagers[name].binary); err == nil {
+			return name, nil
+		}
+	}
+
+	return "", fmt.Errorf("no supported system package manager found")
+}
+
+// readOSRelease parses an os-release file into key/value pairs
+func readOSRelease(path string) map[string]string {
+	values := make(map[string]string)
+
+	file, err := os.Open(path)
+	if err != nil {
+		return values
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || strings.HasPrefix(line, "#") {
+			continue
+		}
+		values[key] = strings.Trim(value, `"'`)
+	}
+
+	return values
+}
+
+// systemPackageName returns the package name for a package manager, honoring
+// overrides for the distribution, then the distributions it derives from, then
+// the package manager
+func systemPackageName(manager string, distro *Distro, dep *Dependency, platformConfig *PlatformConfig) string {
+	packages := platformConfig.Installer.Packages
+	if distro != nil {
+		for _, id := range distro.family() {
+			if pkg, ok := packages[id]; ok {
+				return pkg
+			}
+		}
+	}
+	if pkg, ok := packages[manager]; ok {
+		return pkg
+	}
+	return packageName(dep, platformConfig)
+}
@@ -0,0 +1,154 @@
+package depman
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDetectSystemPackageManager(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "depman-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	originalPath := osReleasePath
+	defer func() { osReleasePath = originalPath }()
+
+	testCases := []struct {
+		name      string
+		osRelease string
+		expected  string
+	}{
+		{name: "Ubuntu", osRelease: "ID=ubuntu\nID_LIKE=debian\n", expected: "apt"},
+		{name: "Rocky via ID_LIKE", osRelease: "ID=\"rocky-custom\"\nID_LIKE=\"rhel centos fedora\"\n", expected: "dnf"},
+		{name: "Alpine", osRelease: "ID=alpine\n", expected: "apk"},
+		{name: "Arch", osRelease: "# comment\nID=arch\n", expected: "pacman"},
+		{name: "CentOS 7", osRelease: "ID=\"centos\"\nID_LIKE=\"rhel fedora\"\nVERSION_ID=\"7\"\n", expected: "yum"},
+		{name: "Rocky 9", osRelease: "ID=\"rocky\"\nID_LIKE=\"rhel centos fedora\"\nVERSION_ID=\"9.3\"\n", expected: "dnf"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			osReleasePath = filepath.Join(tempDir, strings.ReplaceAll(tc.name, " ", "-"))
+			if err := os.WriteFile(osReleasePath, []byte(tc.osRelease), 0644); err != nil {
+				t.Fatalf("Failed to write os-release: %v", err)
+			}
+
+			name, err := detectSystemPackageManager(nil)
+			if err != nil {
+				t.Fatalf("Did not expect an error but got: %v", err)
+			}
+			if name != tc.expected {
+				t.Errorf("Expected %s but got %s", tc.expected, name)
+			}
+		})
+	}
+}
+
+// confirmReporter answers every question the same way
+type confirmReporter struct {
+	noProgress
+	answer bool
+}
+
+func (r confirmReporter) Confirm(string) (bool, error) {
+	return r.answer, nil
+}
+
+func TestElevate(t *testing.T) {
+	originalRoot := isRoot
+	defer func() { isRoot = originalRoot }()
+	isRoot = func() bool { return false }
+
+	command := []string{"apt-get", "install", "-y", "jq"}
+
+	testCases := []struct {
+		name        string
+		policy      PrivilegePolicy
+		confirmed   bool
+		expected    string
+		expectError bool
+	}{
+		{name: "Default sudo", policy: "", expected: "sudo apt-get install -y jq"},
+		{name: "Doas", policy: PrivilegeDoas, expected: "doas apt-get install -y jq"},
+		{name: "Fail", policy: PrivilegeFail, expectError: true},
+		{name: "Prompt accepted", policy: PrivilegePrompt, confirmed: true, expected: "sudo apt-get install -y jq"},
+		{name: "Prompt declined", policy: PrivilegePrompt, confirmed: false, expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			manager := &Manager{privilege: tc.policy}
+			WithReporter(confirmReporter{answer: tc.confirmed})(manager)
+
+			elevated, err := manager.elevate(command)
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("Expected an error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Did not expect an error but got: %v", err)
+			}
+			if got := strings.Join(elevated, " "); got != tc.expected {
+				t.Errorf("Expected %q but got %q", tc.expected, got)
+			}
+		})
+	}
+
+	t.Run("Prompt without a reporter", func(t *testing.T) {
+		manager := &Manager{privilege: PrivilegePrompt}
+		if _, err := manager.elevate(command); !errors.Is(err, errNoReporter) {
+			t.Errorf("Expected the prompt to fail without a reporter but got: %v", err)
+		}
+	})
+
+	t.Run("Root needs no escalation", func(t *testing.T) {
+		isRoot = func() bool { return true }
+		manager := &Manager{privilege: PrivilegeFail}
+		elevated, err := manager.elevate(command)
+		if err != nil || strings.Join(elevated, " ") != strings.Join(command, " ") {
+			t.Errorf("Expected the command unchanged but got %v, %v", elevated, err)
+		}
+	})
+}
+
+func TestSystemInstallerApt(t *testing.T) {
+	originalRoot := isRoot
+	defer func() { isRoot = originalRoot }()
+	isRoot = func() bool { return true }
+
+	dep := &Dependency{Name: "fd"}
+	platformConfig := &PlatformConfig{Installer: Installer{
+		Method:   "apt",
+		Packages: map[string]string{"apt": "fd-find"},
+		Version:  "8.7.0-3",
+	}}
+	manager := &Manager{Platform: "linux", logger: &mockLogger{}}
+
+	install := "env DEBIAN_FRONTEND=noninteractive apt-get install -y -q --no-install-recommends fd-find=8.7.0-3"
+	calls := fakeCommands(t, map[string]string{
+		"env DEBIAN_FRONTEND=noninteractive apt-get update -q": "",
+		install:                               "",
+		"dpkg-query -W -f=${Version} fd-find": "8.7.0-3",
+	})
+
+	strategy := installStrategies["apt"]
+	if _, err := strategy.install(context.Background(), manager, dep, platformConfig); err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if (*calls)[len(*calls)-1] != install {
+		t.Errorf("Expected install command %q but got %v", install, *calls)
+	}
+
+	version, err := strategy.(versionDetector).detectVersion(context.Background(), manager, dep, platformConfig)
+	if err != nil || version != "8.7.0-3" {
+		t.Errorf("Expected version 8.7.0-3 but got %q, %v", version, err)
+	}
+}
@@ -0,0 +1,116 @@
+package depman
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Winget exit codes that do not indicate a failed install
+const (
+	wingetUpdateNotApplicable = 0x8A15002B // No applicable update found
+	wingetAlreadyInstalled    = 0x8A150061 // Package is already installed
+	wingetNoPackageFound      = 0x8A150014 // No package matched the query
+	wingetRebootRequired      = 0x8A150109 // Install succeeded, restart required
+)
+
+// wingetInstaller installs packages with the Windows Package Manager
+type wingetInstaller struct{}
+
+func init() {
+	installStrategies["winget"] = wingetInstaller{}
+}
+
+// install installs the package silently, pinned to the required version
+func (wingetInstaller) install(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) (LockedArtifact, error) {
+	var artifact LockedArtifact
+
+	winget, err := hostLookPath(ctx, "winget")
+	if err != nil {
+		return artifact, fmt.Errorf("winget is not installed")
+	}
+
+	id := packageName(dep, platformConfig)
+	args := []string{"install", "--id", id, "--exact", "--silent", "--disable-interactivity",
+		"--accept-package-agreements", "--accept-source-agreements"}
+	if dep.Version.Required != "" {
+		args = append(args, "--version", dep.Version.Required)
+	}
+	args = append(args, platformConfig.Installer.Args...)
+
+	m.logger.Infof("Running winget install %s", id)
+	if _, err := m.retryCommand(ctx, dep, winget, args...); err != nil {
+		if err := wingetResult(m, id, err); err != nil {
+			return artifact, fmt.Errorf("installation failed: %w", err)
+		}
+	}
+
+	m.logger.Infof("Successfully installed %s", dep.Name)
+	artifact.URL = "winget:" + id
+	return artifact, nil
+}
+
+// uninstall removes the package silently
+func (wingetInstaller) uninstall(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) error {
+	winget, err := hostLookPath(ctx, "winget")
+	if err != nil {
+		return fmt.Errorf("winget is not installed")
+	}
+
+	id := packageName(dep, platformConfig)
+	m.logger.Infof("Running winget uninstall %s", id)
+	if _, err := runCommand(ctx, winget, "uninstall", "--id", id, "--exact", "--silent", "--disable-interactivity"); err != nil {
+		return fmt.Errorf("uninstallation failed: %w", err)
+	}
+
+	return nil
+}
+
+// detectVersion reads the installed version from `winget list`
+func (wingetInstaller) detectVersion(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) (string, error) {
+	winget, err := hostLookPath(ctx, "winget")
+	if err != nil {
+		return "", fmt.Errorf("winget is not installed")
+	}
+
+	id := packageName(dep, platformConfig)
+	output, err := runCommand(ctx, winget, "list", "--id", id, "--exact", "--accept-source-agreements", "--disable-interactivity")
+	if err != nil {
+		return "", fmt.Errorf("%s is not installed: %w", id, err)
+	}
+
+	version := parseWingetList(output, id)
+	if version == "" {
+		return "", fmt.Errorf("%s is not installed", id)
+	}
+
+	return version, nil
+}
+
+// wingetResult maps winget exit codes to success or a descriptive error
+func wingetResult(m *Manager, id string, err error) error {
+	switch uint32(exitCode(err)) {
+	case wingetUpdateNotApplicable, wingetAlreadyInstalled:
+		return nil
+	case wingetRebootRequired:
+		m.logger.Warnf("%s was installed but Windows must be restarted to finish", id)
+		return nil
+	case wingetNoPackageFound:
+		return fmt.Errorf("no winget package found with id %s", id)
+	default:
+		return err
+	}
+}
+
+// parseWingetList finds the version column for a package in `winget list` table output
+func parseWingetList(output, id string) string {
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		for i, field := range fields {
+			if strings.EqualFold(field, id) && i+1 < len(fields) {
+				return fields[i+1]
+			}
+		}
+	}
+	return ""
+}
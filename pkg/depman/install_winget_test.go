@@ -0,0 +1,49 @@
+package depman
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParseWingetList(t *testing.T) {
+	output := `Name           Id                  Version  Available Source
+---------------------------------------------------------------
+Git            Git.Git             2.43.0   2.44.0    winget`
+
+	if got := parseWingetList(output, "Git.Git"); got != "2.43.0" {
+		t.Errorf("Expected version 2.43.0 but got %q", got)
+	}
+
+	if got := parseWingetList("No installed package found matching input criteria.", "Git.Git"); got != "" {
+		t.Errorf("Expected no version but got %q", got)
+	}
+}
+
+func TestParseChocoList(t *testing.T) {
+	output := "git|2.43.0\ngit.install|2.43.0"
+
+	if got := parseChocoList(output, "git.install"); got != "2.43.0" {
+		t.Errorf("Expected version 2.43.0 but got %q", got)
+	}
+
+	if got := parseChocoList("", "git"); got != "" {
+		t.Errorf("Expected no version but got %q", got)
+	}
+}
+
+func TestWingetInstallArgs(t *testing.T) {
+	dep := &Dependency{Name: "git", Version: Version{Required: "2.43.0"}}
+	platformConfig := &PlatformConfig{Installer: Installer{Method: "winget", Package: "Git.Git"}}
+	manager := &Manager{Platform: "windows", logger: &mockLogger{}}
+
+	expected := "winget install --id Git.Git --exact --silent --disable-interactivity " +
+		"--accept-package-agreements --accept-source-agreements --version 2.43.0"
+	calls := fakeCommands(t, map[string]string{expected: ""})
+
+	if _, err := (wingetInstaller{}).install(context.Background(), manager, dep, platformConfig); err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if len(*calls) != 1 || (*calls)[0] != expected {
+		t.Errorf("Expected call %q but got %v", expected, *calls)
+	}
+}
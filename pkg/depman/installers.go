@@ -0,0 +1,124 @@
+package depman
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/devnadeemashraf/depman/pkg/plugin"
+)
+
+// defaultInstallMethod is used when a platform does not declare an install method
+const defaultInstallMethod = "command"
+
+// installStrategy installs dependencies using a single install method
+type installStrategy interface {
+	// install installs the dependency and returns the artifact it used, if any
+	install(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) (LockedArtifact, error)
+}
+
+// uninstaller is implemented by install strategies that can reverse what they installed
+type uninstaller interface {
+	// uninstall removes a dependency previously installed by the strategy
+	uninstall(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) error
+}
+
+// versionDetector is implemented by install strategies that can report the
+// installed version themselves when no verify command is configured
+type versionDetector interface {
+	// detectVersion returns the installed version, or an error if the dependency is not installed
+	detectVersion(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) (string, error)
+}
+
+// versionLister is implemented by install strategies that can list the
+// versions their source offers, for update planning
+type versionLister interface {
+	// availableVersions returns the versions that could be installed, in any order
+	availableVersions(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) ([]string, error)
+}
+
+// licenseDetector is implemented by install strategies that can look up a
+// dependency's license in its package or release metadata
+type licenseDetector interface {
+	// detectLicense returns the SPDX license expression, or "" if none is published
+	detectLicense(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) (string, error)
+}
+
+// installStrategies maps install method names to their strategies, built in
+// or added with RegisterInstaller
+var installStrategies = map[string]installStrategy{
+	defaultInstallMethod: commandInstaller{},
+}
+
+// installMethod returns the install method configured for a platform
+func installMethod(platformConfig *PlatformConfig) string {
+	if platformConfig.Installer.Method == "" {
+		return defaultInstallMethod
+	}
+	return strings.ToLower(platformConfig.Installer.Method)
+}
+
+// strategyFor returns the install strategy for a platform configuration
+func (m *Manager) strategyFor(platformConfig *PlatformConfig) (installStrategy, error) {
+	method := installMethod(platformConfig)
+	strategiesMu.RLock()
+	strategy, ok := installStrategies[method]
+	strategiesMu.RUnlock()
+	if !ok {
+		// Methods that are not built in may be provided by a plugin
+		if path, found := plugin.Find(m.PluginDir(), method); found {
+			return pluginInstaller{name: method, path: path}, nil
+		}
+		return nil, fmt.Errorf("unsupported install method: %s", method)
+	}
+	return strategy, nil
+}
+
+// lookPath reports the location of an executable; replaced in tests
+var lookPath = exec.LookPath
+
+// runCommand runs a command and returns its trimmed combined output; replaced in tests
+var runCommand = func(ctx context.Context, name string, args ...string) (string, error) {
+	output, err := hostCommand(ctx, name, args...).CombinedOutput()
+	installLogOf(ctx).record(name, args, output, err)
+	outputStr := strings.TrimSpace(string(output))
+	if err != nil {
+		return outputStr, fmt.Errorf("%s failed: %w, output: %s", name, err, outputStr)
+	}
+	return outputStr, nil
+}
+
+// commandEnvKey marks contexts carrying extra environment variables for commands
+type commandEnvKey struct{}
+
+// withCommandEnv returns a context whose commands get the given "KEY=value"
+// variables on top of depman's environment
+func withCommandEnv(ctx context.Context, env ...string) context.Context {
+	return context.WithValue(ctx, commandEnvKey{}, append(commandEnv(ctx), env...))
+}
+
+// commandEnv returns the extra environment variables of commands run with ctx
+func commandEnv(ctx context.Context) []string {
+	env, _ := ctx.Value(commandEnvKey{}).([]string)
+	return env
+}
+
+// exitCode returns the exit code of a failed command, or -1 if the error did not come from a process exit
+func exitCode(err error) int {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
+// packageName returns the package identifier for package-manager methods,
+// defaulting to the dependency name
+func packageName(dep *Dependency, platformConfig *PlatformConfig) string {
+	if platformConfig.Installer.Package != "" {
+		return platformConfig.Installer.Package
+	}
+	return dep.Name
+}
@@ -0,0 +1,160 @@
+package depman
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxLogRuns is how many runs' install logs are kept
+const maxLogRuns = 20
+
+// logsDirName is the directory under the home directory holding install logs
+const logsDirName = "logs"
+
+// InstallLog is the log of everything one run installed for a dependency:
+// every command it ran, with its full output and result
+type InstallLog struct {
+	Run        string    `json:"run"`        // Run that wrote it, named after when it started
+	Dependency string    `json:"dependency"` // Name of the dependency
+	Path       string    `json:"path"`       // Log file
+	Time       time.Time `json:"time"`       // When it was last written to
+}
+
+// LogsDir returns the directory holding a subdirectory of install logs per run
+func (m *Manager) LogsDir() string {
+	return filepath.Join(m.globalHomeDir(), logsDirName)
+}
+
+// InstallLogs returns the install logs kept under homeDir for the dependency
+// named name, or for every dependency if name is "", newest run first
+func InstallLogs(homeDir, name string) ([]InstallLog, error) {
+	logsDir := filepath.Join(homeDir, logsDirName)
+	runs, err := os.ReadDir(logsDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read install logs: %w", err)
+	}
+
+	var logs []InstallLog
+	for i := len(runs) - 1; i >= 0; i-- {
+		if !runs[i].IsDir() {
+			continue
+		}
+		dir := filepath.Join(logsDir, runs[i].Name())
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			dep := strings.TrimSuffix(entry.Name(), ".log")
+			if entry.IsDir() || dep == entry.Name() || (name != "" && dep != name) {
+				continue
+			}
+			log := InstallLog{Run: runs[i].Name(), Dependency: dep, Path: filepath.Join(dir, entry.Name())}
+			if info, err := entry.Info(); err == nil {
+				log.Time = info.ModTime()
+			}
+			logs = append(logs, log)
+		}
+	}
+	return logs, nil
+}
+
+// installLogFor returns the log the current run writes a dependency's
+// install commands to
+func (m *Manager) installLogFor(dep *Dependency) *installLog {
+	return &installLog{path: filepath.Join(m.LogsDir(), m.currentRun(), dep.Name+".log")}
+}
+
+// pruneInstallLogs removes the logs of the oldest runs beyond maxLogRuns,
+// leaving room for the one starting
+func (m *Manager) pruneInstallLogs() {
+	runs, err := os.ReadDir(m.LogsDir())
+	if err != nil {
+		return
+	}
+	var dirs []string
+	for _, run := range runs {
+		if run.IsDir() {
+			dirs = append(dirs, run.Name())
+		}
+	}
+	sort.Strings(dirs)
+	for len(dirs) >= maxLogRuns {
+		if err := os.RemoveAll(filepath.Join(m.LogsDir(), dirs[0])); err != nil {
+			m.logger.Warnf("Failed to remove old install logs: %v", err)
+		}
+		dirs = dirs[1:]
+	}
+}
+
+// installLog appends the commands an install runs, with their output, to a
+// file created on the first one
+type installLog struct {
+	mu   sync.Mutex
+	path string
+}
+
+// installLogKey marks contexts of installs whose commands are logged
+type installLogKey struct{}
+
+// withInstallLog returns a context whose commands are recorded in log
+func withInstallLog(ctx context.Context, log *installLog) context.Context {
+	return context.WithValue(ctx, installLogKey{}, log)
+}
+
+// installLogOf returns the log commands run with ctx are recorded in, or nil
+func installLogOf(ctx context.Context) *installLog {
+	log, _ := ctx.Value(installLogKey{}).(*installLog)
+	return log
+}
+
+// record appends a command, its combined output and how it ended. Failing to
+// write the log never fails the install.
+func (l *installLog) record(name string, args []string, output []byte, err error) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if os.MkdirAll(filepath.Dir(l.path), 0755) != nil {
+		return
+	}
+	file, openErr := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if openErr != nil {
+		return
+	}
+	defer file.Close()
+
+	fmt.Fprintf(file, "$ %s\n", strings.Join(append([]string{name}, args...), " "))
+	if len(output) > 0 {
+		file.Write(output)
+		if output[len(output)-1] != '\n' {
+			fmt.Fprintln(file)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(file, "# failed: %v\n\n", err)
+	} else {
+		fmt.Fprintf(file, "# succeeded\n\n")
+	}
+}
+
+// written returns the log file if anything was recorded in it, else ""
+func (l *installLog) written() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err := os.Stat(l.path); err != nil {
+		return ""
+	}
+	return l.path
+}
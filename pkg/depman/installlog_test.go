@@ -0,0 +1,61 @@
+package depman
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInstallLog(t *testing.T) {
+	homeDir := t.TempDir()
+	manager := &Manager{homeDir: homeDir, logger: &mockLogger{}}
+
+	log := manager.installLogFor(&Dependency{Name: "tool"})
+	if log.written() != "" {
+		t.Error("Expected no log file before a command ran")
+	}
+	log.record("sh", []string{"-c", "install"}, []byte("downloading\n"), nil)
+	log.record("sh", []string{"-c", "verify"}, []byte("not found"), errors.New("exit status 1"))
+	manager.installLogFor(&Dependency{Name: "other"}).record("true", nil, nil, nil)
+
+	data, err := os.ReadFile(log.written())
+	if err != nil {
+		t.Fatalf("Expected the log to be written: %v", err)
+	}
+	expected := "$ sh -c install\ndownloading\n# succeeded\n\n$ sh -c verify\nnot found\n# failed: exit status 1\n\n"
+	if string(data) != expected {
+		t.Errorf("Expected log %q but got %q", expected, data)
+	}
+
+	logs, err := InstallLogs(homeDir, "")
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if len(logs) != 2 || logs[0].Run != logs[1].Run {
+		t.Errorf("Expected two logs of one run but got %+v", logs)
+	}
+	if logs, _ := InstallLogs(homeDir, "tool"); len(logs) != 1 || logs[0].Path != log.written() {
+		t.Errorf("Expected the log of tool but got %+v", logs)
+	}
+}
+
+func TestInstallLogPruning(t *testing.T) {
+	homeDir := t.TempDir()
+	for i := 0; i < maxLogRuns+5; i++ {
+		os.MkdirAll(filepath.Join(homeDir, logsDirName, fmt.Sprintf("20250101T0000%02d.000Z-0000", i)), 0755)
+	}
+
+	manager := &Manager{homeDir: homeDir, logger: &mockLogger{}}
+	manager.installLogFor(&Dependency{Name: "tool"}).record("true", nil, nil, nil)
+
+	runs, _ := os.ReadDir(manager.LogsDir())
+	if len(runs) != maxLogRuns {
+		t.Fatalf("Expected %d runs to be kept but got %d", maxLogRuns, len(runs))
+	}
+	if !strings.HasPrefix(runs[0].Name(), "20250101T000006") {
+		t.Errorf("Expected the oldest runs to be removed but the first left is %s", runs[0].Name())
+	}
+}
@@ -0,0 +1,165 @@
+package depman
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// choicesFileName is the file under the project directory remembering interactive answers
+const choicesFileName = "choices.yml"
+
+// Choice is an answer to an interactive ensure prompt
+type Choice int
+
+const (
+	ChoiceInstall Choice = iota // Carry out the action this time
+	ChoiceSkip                  // Leave the dependency alone this time
+	ChoiceAlways                // Carry out the action now and in later runs without asking
+	ChoiceNever                 // Leave the dependency alone now and in later runs without asking
+)
+
+var choiceNames = []string{"install", "skip", "always", "never"}
+
+func (c Choice) String() string {
+	return choiceNames[c]
+}
+
+// ParseChoice parses a prompt answer: install, skip, always, never or their first letter
+func ParseChoice(answer string) (Choice, error) {
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	for i, name := range choiceNames {
+		if answer != "" && (answer == name || answer == name[:1]) {
+			return Choice(i), nil
+		}
+	}
+	return ChoiceSkip, fmt.Errorf("invalid choice '%s' (expected install, skip, always or never)", answer)
+}
+
+// PromptFunc asks whether ensure should carry out a planned action
+type PromptFunc func(ctx context.Context, action *PlannedAction) (Choice, error)
+
+// WithPrompt makes ensure ask prompt before installing or upgrading each
+// dependency. Always and never answers are remembered in the choices file
+// and not asked again.
+func WithPrompt(prompt PromptFunc) Option {
+	return func(m *Manager) {
+		m.prompt = prompt
+	}
+}
+
+// ChoicesPath returns the file remembering always and never answers to ensure prompts
+func (m *Manager) ChoicesPath() string {
+	return filepath.Join(m.ProjectDir(), choicesFileName)
+}
+
+// savedChoices are the remembered answers, by dependency name
+type savedChoices struct {
+	Always []string `yaml:"always,omitempty"` // Dependencies installed and upgraded without asking
+	Never  []string `yaml:"never,omitempty"`  // Dependencies ensure leaves alone
+}
+
+// loadChoices reads the choices file, which may not exist
+func loadChoices(path string) (*savedChoices, error) {
+	choices := &savedChoices{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return choices, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read choices: %w", err)
+	}
+	if err := yaml.Unmarshal(data, choices); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return choices, nil
+}
+
+// save writes the choices file
+func (c *savedChoices) save(path string) error {
+	sort.Strings(c.Always)
+	sort.Strings(c.Never)
+
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("failed to encode choices: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to write choices: %w", err)
+	}
+	header := "# Answers remembered by depman ensure --interactive. Delete an entry to be asked again.\n"
+	if err := os.WriteFile(path, append([]byte(header), data...), 0644); err != nil {
+		return fmt.Errorf("failed to write choices: %w", err)
+	}
+	return nil
+}
+
+// confirmActions asks the prompt, if set, about each pending dependency and
+// returns the ones to install. Skipped dependencies are marked in their status.
+func (m *Manager) confirmActions(ctx context.Context, pending []*Dependency, actions map[string]*PlannedAction) ([]*Dependency, error) {
+	if m.prompt == nil {
+		return pending, nil
+	}
+
+	path := m.ChoicesPath()
+	choices, err := loadChoices(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var confirmed []*Dependency
+	changed := false
+	for _, dep := range pending {
+		action := actions[dep.Name]
+
+		var choice Choice
+		switch {
+		case containsString(choices.Always, dep.Name):
+			choice = ChoiceAlways
+		case containsString(choices.Never, dep.Name):
+			m.logger.Infof("Skipping %s, which is set to never be installed in %s", dep.Name, path)
+			choice = ChoiceNever
+		default:
+			if choice, err = m.prompt(ctx, action); err != nil {
+				return nil, err
+			}
+			switch choice {
+			case ChoiceAlways:
+				choices.Always = append(choices.Always, dep.Name)
+				changed = true
+			case ChoiceNever:
+				choices.Never = append(choices.Never, dep.Name)
+				changed = true
+			}
+		}
+
+		if choice == ChoiceInstall || choice == ChoiceAlways {
+			confirmed = append(confirmed, dep)
+		} else {
+			action.Status.Skipped = true
+		}
+	}
+
+	if changed {
+		if err := choices.save(path); err != nil {
+			return nil, err
+		}
+	}
+	return confirmed, nil
+}
+
+// containsString reports whether list contains s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
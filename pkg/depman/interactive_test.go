@@ -0,0 +1,104 @@
+package depman
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseChoice(t *testing.T) {
+	testCases := []struct {
+		answer      string
+		expected    Choice
+		expectError bool
+	}{
+		{answer: "install", expected: ChoiceInstall},
+		{answer: "S\n", expected: ChoiceSkip},
+		{answer: " always ", expected: ChoiceAlways},
+		{answer: "n", expected: ChoiceNever},
+		{answer: "", expectError: true},
+		{answer: "yes", expectError: true},
+	}
+
+	for _, tc := range testCases {
+		choice, err := ParseChoice(tc.answer)
+		if tc.expectError {
+			if err == nil {
+				t.Errorf("Expected an error for %q but got %s", tc.answer, choice)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Did not expect an error for %q but got: %v", tc.answer, err)
+		} else if choice != tc.expected {
+			t.Errorf("Expected %s for %q but got %s", tc.expected, tc.answer, choice)
+		}
+	}
+}
+
+func TestConfirmActions(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "depman-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	answers := map[string]Choice{"a": ChoiceInstall, "b": ChoiceSkip, "c": ChoiceAlways, "d": ChoiceNever}
+	var asked []string
+	manager := &Manager{
+		ConfigPath: filepath.Join(tempDir, "app-dependencies.yml"),
+		logger:     &mockLogger{},
+	}
+	WithPrompt(func(ctx context.Context, action *PlannedAction) (Choice, error) {
+		asked = append(asked, action.Name)
+		return answers[action.Name], nil
+	})(manager)
+
+	run := func() []string {
+		var pending []*Dependency
+		actions := make(map[string]*PlannedAction)
+		for _, name := range []string{"a", "b", "c", "d"} {
+			pending = append(pending, &Dependency{Name: name})
+			actions[name] = &PlannedAction{Name: name, Action: ActionInstall, Status: &DependencyStatus{Name: name}}
+		}
+
+		confirmed, err := manager.confirmActions(context.Background(), pending, actions)
+		if err != nil {
+			t.Fatalf("Did not expect an error but got: %v", err)
+		}
+		if !actions["b"].Status.Skipped || !actions["d"].Status.Skipped || actions["a"].Status.Skipped {
+			t.Errorf("Expected b and d to be marked as skipped")
+		}
+
+		var names []string
+		for _, dep := range confirmed {
+			names = append(names, dep.Name)
+		}
+		return names
+	}
+
+	if names := run(); len(names) != 2 || names[0] != "a" || names[1] != "c" {
+		t.Errorf("Expected a and c to be confirmed but got %v", names)
+	}
+	if len(asked) != 4 {
+		t.Errorf("Expected every dependency to be asked about but got %v", asked)
+	}
+
+	choices, err := loadChoices(manager.ChoicesPath())
+	if err != nil {
+		t.Fatalf("Failed to load choices: %v", err)
+	}
+	if len(choices.Always) != 1 || choices.Always[0] != "c" || len(choices.Never) != 1 || choices.Never[0] != "d" {
+		t.Errorf("Expected always [c] and never [d] but got %+v", choices)
+	}
+
+	// Remembered answers are not asked again
+	asked = nil
+	if names := run(); len(names) != 2 || names[0] != "a" || names[1] != "c" {
+		t.Errorf("Expected a and c to be confirmed but got %v", names)
+	}
+	if len(asked) != 2 || asked[0] != "a" || asked[1] != "b" {
+		t.Errorf("Expected only a and b to be asked about but got %v", asked)
+	}
+}
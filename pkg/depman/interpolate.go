@@ -0,0 +1,264 @@
+package depman
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// secretParameter matches query parameters of URLs that commonly carry credentials
+var secretParameter = regexp.MustCompile(`(?i)(token|secret|password|passwd|signature|sig|key|credential|auth)`)
+
+// envReference matches ${VAR} and ${VAR:-default} references
+var envReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// templateData is the data available to Go-template expressions in the configuration
+type templateData struct {
+	Platform string // Target platform (windows, linux, darwin)
+	Arch     string // Target architecture (amd64, arm64, ...)
+	Home     string // User home directory
+	Name     string // Name of the dependency being resolved
+	Version  string // Required version of the dependency
+
+	InstallDir string // Directory a download-based install of the required version goes to
+}
+
+// WithStrictInterpolation makes references to undefined environment variables
+// and template fields an error instead of expanding them to empty strings
+func WithStrictInterpolation(strict bool) Option {
+	return func(m *Manager) {
+		m.strict = strict
+	}
+}
+
+// interpolate expands ${VAR} references and Go-template expressions in s.
+// The legacy {{version}}, {{os}} and {{arch}} placeholders are left for the
+// installers that expand them.
+func (m *Manager) interpolate(s string, dep *Dependency) (string, error) {
+	if !strings.Contains(s, "${") && !strings.Contains(s, "{{") {
+		return s, nil
+	}
+	s, err := m.expandEnv(s, dep)
+	if err != nil {
+		return "", err
+	}
+	return m.expandTemplate(s, dep, false)
+}
+
+// interpolateURL expands the Go-template expressions in a download URL but
+// keeps its ${VAR} references, and turns {{ env "VAR" }} into one, so
+// credentials taken from the environment stay out of the lockfile, the
+// download cache and logs. expandURL resolves them just before the download.
+func (m *Manager) interpolateURL(s string, dep *Dependency) (string, error) {
+	if !strings.Contains(s, "{{") {
+		return s, nil
+	}
+	return m.expandTemplate(s, dep, true)
+}
+
+// expandURL resolves the ${VAR} references interpolateURL kept in a URL, for
+// fetching it
+func (m *Manager) expandURL(s string, dep *Dependency) (string, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+	expanded, err := m.expandEnv(s, dep)
+	if err != nil {
+		return "", fmt.Errorf("dependency '%s': %w", dep.Name, err)
+	}
+	return expanded, nil
+}
+
+// redactURL hides the credentials of a URL shown in logs and output: the
+// password of its userinfo and the values of query parameters that look
+// like secrets. Strings that do not parse as URLs are returned unchanged.
+func redactURL(raw string) string {
+	if !strings.Contains(raw, "@") && !strings.Contains(raw, "?") {
+		return raw
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	redacted := false
+	if parsed.User != nil {
+		if _, ok := parsed.User.Password(); ok {
+			parsed.User = url.UserPassword(parsed.User.Username(), "xxxxx")
+		} else {
+			parsed.User = url.User("xxxxx")
+		}
+		redacted = true
+	}
+	if parsed.RawQuery != "" {
+		query := parsed.Query()
+		for name := range query {
+			if secretParameter.MatchString(name) {
+				query[name] = []string{"xxxxx"}
+				redacted = true
+			}
+		}
+		parsed.RawQuery = query.Encode()
+	}
+	if !redacted {
+		return raw
+	}
+	return parsed.String()
+}
+
+// redactURLError hides the credentials of the URL an HTTP request error
+// names, which net/http only strips of its password
+func redactURLError(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		urlErr.URL = redactURL(urlErr.URL)
+	}
+	return err
+}
+
+// expandEnv expands the ${VAR} and ${VAR:-default} references in s
+func (m *Manager) expandEnv(s string, dep *Dependency) (string, error) {
+	var missing []string
+	s = envReference.ReplaceAllStringFunc(s, func(ref string) string {
+		match := envReference.FindStringSubmatch(ref)
+		if value, ok := os.LookupEnv(match[1]); ok {
+			return value
+		}
+		if match[2] != "" {
+			return match[3]
+		}
+		missing = append(missing, match[1])
+		return ""
+	})
+	if len(missing) > 0 {
+		if m.strict {
+			return "", fmt.Errorf("undefined environment variable %s", strings.Join(missing, ", "))
+		}
+		m.logger.Warnf("Undefined environment variable %s in configuration of %s", strings.Join(missing, ", "), dep.Name)
+	}
+	return s, nil
+}
+
+// expandTemplate executes the Go-template expressions in s. With deferEnv,
+// {{ env "VAR" }} becomes a ${VAR} reference instead of the variable's value.
+func (m *Manager) expandTemplate(s string, dep *Dependency, deferEnv bool) (string, error) {
+	if !strings.Contains(s, "{{") {
+		return s, nil
+	}
+
+	env := os.Getenv
+	if deferEnv {
+		env = func(name string) string { return "${" + name + "}" }
+	}
+	tmpl := template.New(dep.Name).Funcs(template.FuncMap{
+		// Legacy placeholders expanded later by the installers
+		"version": func() string { return "{{version}}" },
+		"os":      func() string { return "{{os}}" },
+		"arch":    func() string { return "{{arch}}" },
+		"env":     env,
+	})
+	if m.strict {
+		tmpl = tmpl.Option("missingkey=error")
+	}
+	tmpl, err := tmpl.Parse(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid template %q: %w", s, err)
+	}
+
+	home, _ := os.UserHomeDir()
+	data := templateData{
+		Platform: m.Platform,
+		Arch:     m.targetArch(),
+		Home:     home,
+		Name:     dep.Name,
+		Version:  strings.TrimPrefix(dep.Version.Required, "v"),
+
+		InstallDir: m.toolVersionDir(dep.Name, toolVersion(dep.Version.Required)),
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to expand template %q: %w", s, err)
+	}
+	return buf.String(), nil
+}
+
+// interpolateValue expands every string reachable from v in place. Slices
+// and maps are copied first so the loaded configuration is never modified.
+// String fields tagged `interpolate:"url"` go through interpolateURL, and
+// fields tagged `interpolate:"-"` are left as written.
+func (m *Manager) interpolateValue(v reflect.Value, dep *Dependency) error {
+	switch v.Kind() {
+	case reflect.String:
+		expanded, err := m.interpolate(v.String(), dep)
+		if err != nil {
+			return err
+		}
+		v.SetString(expanded)
+
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() || field.Tag.Get("interpolate") == "-" {
+				continue
+			}
+			if field.Tag.Get("interpolate") == "url" {
+				expanded, err := m.interpolateURL(v.Field(i).String(), dep)
+				if err != nil {
+					return err
+				}
+				v.Field(i).SetString(expanded)
+				continue
+			}
+			if err := m.interpolateValue(v.Field(i), dep); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+		copied := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(copied, v)
+		for i := 0; i < copied.Len(); i++ {
+			if err := m.interpolateValue(copied.Index(i), dep); err != nil {
+				return err
+			}
+		}
+		v.Set(copied)
+
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		copied := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			value := reflect.New(v.Type().Elem()).Elem()
+			value.Set(iter.Value())
+			if err := m.interpolateValue(value, dep); err != nil {
+				return err
+			}
+			copied.SetMapIndex(iter.Key(), value)
+		}
+		v.Set(copied)
+	}
+
+	return nil
+}
+
+// interpolated returns a copy of a configuration value with all strings expanded
+func interpolated[T any](m *Manager, value T, dep *Dependency) (T, error) {
+	v := reflect.ValueOf(&value).Elem()
+	if err := m.interpolateValue(v, dep); err != nil {
+		var zero T
+		return zero, fmt.Errorf("dependency '%s': %w", dep.Name, err)
+	}
+	return value, nil
+}
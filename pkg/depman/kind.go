@@ -0,0 +1,74 @@
+package depman
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DependencyKind is what a dependency provides: a tool installed per
+// platform, or something else depman ensures is present
+type DependencyKind string
+
+const (
+	KindTool        DependencyKind = "tool"        // A tool installed by the platform entries (default)
+	KindImage       DependencyKind = "image"       // A container image pulled at a pinned digest
+	KindService     DependencyKind = "service"     // A daemon that must be running and healthy
+	KindRequirement DependencyKind = "requirement" // A precondition of the machine, such as free disk space
+)
+
+// ParseDependencyKind parses a dependency's kind; an empty kind means tool
+func ParseDependencyKind(name string) (DependencyKind, error) {
+	switch kind := DependencyKind(strings.ToLower(name)); kind {
+	case "":
+		return KindTool, nil
+	case KindTool, KindImage, KindService, KindRequirement:
+		return kind, nil
+	default:
+		return "", fmt.Errorf("invalid kind '%s' (expected tool, image, service or requirement)", name)
+	}
+}
+
+// kind returns the dependency's kind, treating an invalid kind as tool
+func (d *Dependency) kind() DependencyKind {
+	kind, err := ParseDependencyKind(d.Kind)
+	if err != nil {
+		return KindTool
+	}
+	return kind
+}
+
+// kindPlatformConfig returns the platform configuration of dependencies that
+// are not installed per platform, whose kind decides how they are installed
+func kindPlatformConfig(dep *Dependency) (*PlatformConfig, bool) {
+	switch dep.kind() {
+	case KindImage:
+		return &PlatformConfig{Installer: Installer{Method: imageMethod}}, true
+	case KindService:
+		return &PlatformConfig{Installer: Installer{Method: serviceMethod}}, true
+	case KindRequirement:
+		return &PlatformConfig{Installer: Installer{Method: requirementMethod}}, true
+	default:
+		return nil, false
+	}
+}
+
+// validateKindSpec checks the block describing a dependency of a kind other than tool
+func validateKindSpec(dep *Dependency) error {
+	switch dep.kind() {
+	case KindImage:
+		return dep.Image.validate()
+	case KindService:
+		return dep.Service.validate()
+	case KindRequirement:
+		return dep.Requirement.validate()
+	default:
+		return nil
+	}
+}
+
+// installsNothing reports whether the dependency's kind is only checked, so
+// there is no install to record
+func (d *Dependency) installsNothing() bool {
+	kind := d.kind()
+	return kind == KindService || kind == KindRequirement
+}
@@ -0,0 +1,335 @@
+package depman
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Policy restricts which dependencies may be installed
+type Policy struct {
+	AllowedLicenses []string `yaml:"allowed_licenses"` // SPDX identifiers that may be installed; if set, all others are refused
+	DeniedLicenses  []string `yaml:"denied_licenses"`  // SPDX identifiers that may never be installed
+}
+
+// merge returns the policy with the license lists the overlay sets replacing these
+func (p Policy) merge(overlay Policy) Policy {
+	if overlay.AllowedLicenses != nil {
+		p.AllowedLicenses = overlay.AllowedLicenses
+	}
+	if overlay.DeniedLicenses != nil {
+		p.DeniedLicenses = overlay.DeniedLicenses
+	}
+	return p
+}
+
+// restrictsLicenses reports whether the policy has any license rules
+func (p Policy) restrictsLicenses() bool {
+	return len(p.AllowedLicenses) > 0 || len(p.DeniedLicenses) > 0
+}
+
+// LicenseError reports a dependency whose license the policy does not permit
+type LicenseError struct {
+	Dependency string // Name of the dependency
+	License    string // License expression of the dependency, or "" if unknown
+}
+
+func (e *LicenseError) Error() string {
+	if e.License == "" {
+		return fmt.Sprintf("dependency '%s' has no known license and the license policy only allows listed licenses", e.Dependency)
+	}
+	return fmt.Sprintf("license %s of dependency '%s' is not permitted by the license policy", e.License, e.Dependency)
+}
+
+// CheckLicense returns a *LicenseError if the policy does not permit a
+// dependency's SPDX license expression. An OR expression is permitted if any
+// alternative is, an AND expression if every part is; license exceptions
+// ("WITH ...") are ignored. Unknown licenses only violate an allow list.
+func (p Policy) CheckLicense(dependency, license string) error {
+	permitted := true
+	if isUnknownLicense(license) {
+		permitted = len(p.AllowedLicenses) == 0
+		license = ""
+	} else {
+		expr, err := parseLicenseExpression(license)
+		if err != nil {
+			return fmt.Errorf("invalid license of dependency '%s': %w", dependency, err)
+		}
+		permitted = expr.permitted(p)
+	}
+	if !permitted {
+		return &LicenseError{Dependency: dependency, License: license}
+	}
+	return nil
+}
+
+// permits reports whether a single license identifier may be installed
+func (p Policy) permits(id string) bool {
+	if containsFold(p.DeniedLicenses, id) {
+		return false
+	}
+	return len(p.AllowedLicenses) == 0 || containsFold(p.AllowedLicenses, id)
+}
+
+// containsFold reports whether list contains s, ignoring case
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// isUnknownLicense reports whether a license expression says nothing about the license
+func isUnknownLicense(license string) bool {
+	license = strings.TrimSpace(license)
+	return license == "" || strings.EqualFold(license, "NOASSERTION") || strings.EqualFold(license, "NONE")
+}
+
+// licenseExpression is a parsed SPDX license expression: a single license
+// identifier, or the alternatives (or) or combination (and) of its terms
+type licenseExpression struct {
+	id    string
+	or    bool
+	terms []*licenseExpression
+}
+
+// permitted evaluates the expression against a policy
+func (e *licenseExpression) permitted(p Policy) bool {
+	if e.terms == nil {
+		return p.permits(e.id)
+	}
+	for _, term := range e.terms {
+		if term.permitted(p) == e.or {
+			return e.or
+		}
+	}
+	return !e.or
+}
+
+// parseLicenseExpression parses an SPDX license expression such as
+// "(MIT OR Apache-2.0) AND GPL-2.0-only WITH Classpath-exception-2.0"
+func parseLicenseExpression(license string) (*licenseExpression, error) {
+	tokens := strings.Fields(strings.NewReplacer("(", " ( ", ")", " ) ").Replace(license))
+	parser := &licenseParser{tokens: tokens}
+	expr, err := parser.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("%w in '%s'", err, license)
+	}
+	if parser.pos < len(tokens) {
+		return nil, fmt.Errorf("unexpected '%s' in '%s'", tokens[parser.pos], license)
+	}
+	return expr, nil
+}
+
+// licenseParser is a recursive descent parser over license expression tokens;
+// AND binds tighter than OR
+type licenseParser struct {
+	tokens []string
+	pos    int
+}
+
+// errIncompleteLicense is returned for expressions that end early
+var errIncompleteLicense = errors.New("incomplete license expression")
+
+func (p *licenseParser) accept(keyword string) bool {
+	if p.pos < len(p.tokens) && strings.EqualFold(p.tokens[p.pos], keyword) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *licenseParser) parseOr() (*licenseExpression, error) {
+	return p.parseList(true, p.parseAnd)
+}
+
+func (p *licenseParser) parseAnd() (*licenseExpression, error) {
+	return p.parseList(false, p.parseTerm)
+}
+
+// parseList parses terms joined by OR (or) or AND (!or)
+func (p *licenseParser) parseList(or bool, parseTerm func() (*licenseExpression, error)) (*licenseExpression, error) {
+	keyword := "AND"
+	if or {
+		keyword = "OR"
+	}
+
+	term, err := parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	expr := &licenseExpression{or: or, terms: []*licenseExpression{term}}
+	for p.accept(keyword) {
+		if term, err = parseTerm(); err != nil {
+			return nil, err
+		}
+		expr.terms = append(expr.terms, term)
+	}
+	if len(expr.terms) == 1 {
+		return term, nil
+	}
+	return expr, nil
+}
+
+// parseTerm parses a parenthesized expression or a license identifier with an optional exception
+func (p *licenseParser) parseTerm() (*licenseExpression, error) {
+	if p.accept("(") {
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, errors.New("missing ')'")
+		}
+		return expr, nil
+	}
+
+	if p.pos >= len(p.tokens) {
+		return nil, errIncompleteLicense
+	}
+	id := p.tokens[p.pos]
+	switch strings.ToUpper(id) {
+	case "AND", "OR", "WITH", ")":
+		return nil, fmt.Errorf("unexpected '%s'", id)
+	}
+	p.pos++
+
+	if p.accept("WITH") {
+		if p.pos >= len(p.tokens) {
+			return nil, errIncompleteLicense
+		}
+		p.pos++
+	}
+	return &licenseExpression{id: id}, nil
+}
+
+// dependencyLicense returns the license of a dependency: the one declared in
+// the configuration, else the one recorded in the lockfile for the same
+// definition, else the one its install strategy finds in the package or
+// release metadata. It returns "" if none is known.
+func (m *Manager) dependencyLicense(ctx context.Context, dep *Dependency, lock *Lockfile) string {
+	if dep.License != "" {
+		return dep.License
+	}
+	if entry := lockEntry(lock, dep.Name); entry != nil && entry.License != "" && entry.Digest == dependencyDigest(dep) {
+		return entry.License
+	}
+
+	platformConfig, err := m.resolvedPlatformConfig(dep)
+	if err != nil {
+		return ""
+	}
+	strategy, err := m.strategyFor(platformConfig)
+	if err != nil {
+		return ""
+	}
+	detector, ok := strategy.(licenseDetector)
+	if !ok {
+		return ""
+	}
+	license, err := detector.detectLicense(ctx, m, dep, platformConfig)
+	if err != nil {
+		m.logger.Debugf("Failed to look up the license of %s: %v", dep.Name, err)
+		return ""
+	}
+	return license
+}
+
+// enforceLicensePolicy looks up the licenses of deps when the configuration
+// has a license policy and fails if any violates it, setting the error on the
+// dependency's status. It returns the licenses it found.
+func (m *Manager) enforceLicensePolicy(ctx context.Context, deps []*Dependency, statuses map[string]*DependencyStatus) (map[string]string, error) {
+	licenses := make(map[string]string)
+	policy := m.Config.Policy
+	if !policy.restrictsLicenses() {
+		return licenses, nil
+	}
+
+	lock, _ := LoadLockfile(m.LockfilePath())
+
+	var violations []string
+	for _, dep := range deps {
+		license := m.dependencyLicense(ctx, dep, lock)
+		licenses[dep.Name] = license
+		if err := policy.CheckLicense(dep.Name, license); err != nil {
+			if status := statuses[dep.Name]; status != nil {
+				status.Error = err
+			}
+			violations = append(violations, err.Error())
+		}
+	}
+	if len(violations) > 0 {
+		return licenses, fmt.Errorf("license policy violated: %s", strings.Join(violations, "; "))
+	}
+	return licenses, nil
+}
+
+// recordLicenses looks up the licenses of installed deps not yet in licenses
+func (m *Manager) recordLicenses(ctx context.Context, deps []*Dependency, artifacts map[string]LockedArtifact, licenses map[string]string) {
+	for _, dep := range deps {
+		if _, installed := artifacts[dep.Name]; !installed {
+			continue
+		}
+		if _, ok := licenses[dep.Name]; !ok {
+			licenses[dep.Name] = m.dependencyLicense(ctx, dep, nil)
+		}
+	}
+}
+
+// LicenseStatus tells whether a dependency's license passes the license policy
+type LicenseStatus string
+
+// License statuses
+const (
+	LicenseAllowed LicenseStatus = "allowed" // The policy permits the license
+	LicenseDenied  LicenseStatus = "denied"  // The policy does not permit the license
+	LicenseUnknown LicenseStatus = "unknown" // No license is known, which the policy permits
+)
+
+// LicenseResult is the license of one dependency and how the policy rates it
+type LicenseResult struct {
+	Name    string        `json:"name"`             // Name of the dependency
+	Version string        `json:"version"`          // Locked or required version
+	License string        `json:"license"`          // SPDX license expression, or "" if unknown
+	Status  LicenseStatus `json:"status"`           // Whether the policy permits the license
+	Reason  string        `json:"reason,omitempty"` // Why the license is denied
+}
+
+// Licenses reports the license of each dependency on the current platform
+// and whether the configured license policy permits it
+func (m *Manager) Licenses(ctx context.Context) ([]*LicenseResult, error) {
+	if err := m.validateConfiguration(); err != nil {
+		return nil, fmt.Errorf("invalid dependency configuration: %w", err)
+	}
+
+	deps, err := m.SelectedDependencies()
+	if err != nil {
+		return nil, err
+	}
+
+	lock, err := LoadLockfile(m.LockfilePath())
+	if err != nil && !errors.Is(err, ErrLockfileNotFound) {
+		return nil, err
+	}
+
+	var results []*LicenseResult
+	for _, dep := range deps {
+		result := &LicenseResult{Name: dep.Name, Version: dep.Version.Required, Status: LicenseAllowed}
+		if entry := lockEntry(lock, dep.Name); entry != nil {
+			result.Version = entry.Version
+		}
+		result.License = m.dependencyLicense(ctx, dep, lock)
+
+		if err := m.Config.Policy.CheckLicense(dep.Name, result.License); err != nil {
+			result.Status = LicenseDenied
+			result.Reason = err.Error()
+		} else if isUnknownLicense(result.License) {
+			result.Status = LicenseUnknown
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
@@ -0,0 +1,161 @@
+package depman
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckLicense(t *testing.T) {
+	policy := Policy{
+		AllowedLicenses: []string{"MIT", "Apache-2.0", "GPL-2.0-only"},
+		DeniedLicenses:  []string{"GPL-3.0-only"},
+	}
+
+	testCases := []struct {
+		name        string
+		policy      Policy
+		license     string
+		expectError bool
+	}{
+		{name: "Allowed license", policy: policy, license: "MIT"},
+		{name: "Case-insensitive match", policy: policy, license: "apache-2.0"},
+		{name: "License not allowed", policy: policy, license: "BSD-3-Clause", expectError: true},
+		{name: "Denied license", policy: Policy{DeniedLicenses: []string{"GPL-3.0-only"}}, license: "GPL-3.0-only", expectError: true},
+		{name: "OR with one allowed alternative", policy: policy, license: "GPL-3.0-only OR MIT"},
+		{name: "AND with a denied part", policy: policy, license: "MIT AND GPL-3.0-only", expectError: true},
+		{name: "Nested expression", policy: policy, license: "(BSD-3-Clause OR Apache-2.0) AND MIT"},
+		{name: "Exception is ignored", policy: policy, license: "GPL-2.0-only WITH Classpath-exception-2.0"},
+		{name: "Unknown license with allow list", policy: policy, license: "", expectError: true},
+		{name: "Unknown license with deny list", policy: Policy{DeniedLicenses: []string{"GPL-3.0-only"}}, license: "NOASSERTION"},
+		{name: "Invalid expression", policy: policy, license: "MIT OR", expectError: true},
+		{name: "Unbalanced parenthesis", policy: policy, license: "(MIT OR Apache-2.0", expectError: true},
+		{name: "No policy", policy: Policy{}, license: "GPL-3.0-only"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.policy.CheckLicense("dep", tc.license)
+			if tc.expectError && err == nil {
+				t.Errorf("Expected an error for license %q but got none", tc.license)
+			}
+			if !tc.expectError && err != nil {
+				t.Errorf("Did not expect an error but got: %v", err)
+			}
+		})
+	}
+}
+
+// newLicenseTestManager writes a configuration with the given policy block and
+// returns a manager for it
+func newLicenseTestManager(t *testing.T, policy string) *Manager {
+	t.Helper()
+
+	tempDir, err := os.MkdirTemp("", "depman-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(tempDir) })
+
+	configPath := filepath.Join(tempDir, "app-dependencies.yml")
+	config := policy + `
+dependencies:
+  - name: "jq"
+    version:
+      required: "1.7.1"
+    platforms:
+      linux:
+        installer: {method: "brew"}
+  - name: "tool"
+    version:
+      required: "1.0.0"
+    license: "MIT OR Apache-2.0"
+    platforms:
+      linux:
+        installer: {method: "brew"}
+  - name: "other"
+    version:
+      required: "2.0.0"
+    platforms:
+      linux:
+        installer: {method: "system"}
+`
+	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	manager, err := NewManager(configPath, WithPlatform("linux"), WithHomeDir(tempDir), WithLogOutput(io.Discard))
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	return manager
+}
+
+func TestLicenses(t *testing.T) {
+	fakeCommands(t, map[string]string{
+		"brew info --json=v2 jq": `{"formulae": [{"license": "MIT"}]}`,
+	})
+
+	testCases := []struct {
+		name     string
+		policy   string
+		expected map[string]LicenseStatus
+	}{
+		{
+			name:     "Allow list",
+			policy:   "policy:\n  allowed_licenses: [\"Apache-2.0\"]\n",
+			expected: map[string]LicenseStatus{"jq": LicenseDenied, "tool": LicenseAllowed, "other": LicenseDenied},
+		},
+		{
+			name:     "Deny list",
+			policy:   "policy:\n  denied_licenses: [\"MIT\"]\n",
+			expected: map[string]LicenseStatus{"jq": LicenseDenied, "tool": LicenseAllowed, "other": LicenseUnknown},
+		},
+		{
+			name:     "No policy",
+			expected: map[string]LicenseStatus{"jq": LicenseAllowed, "tool": LicenseAllowed, "other": LicenseUnknown},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			manager := newLicenseTestManager(t, tc.policy)
+
+			results, err := manager.Licenses(context.Background())
+			if err != nil {
+				t.Fatalf("Did not expect an error but got: %v", err)
+			}
+			for _, result := range results {
+				if result.Status != tc.expected[result.Name] {
+					t.Errorf("Expected %s to be %s but got %s (%s)", result.Name, tc.expected[result.Name], result.Status, result.Reason)
+				}
+			}
+		})
+	}
+}
+
+func TestEnsureLicensePolicy(t *testing.T) {
+	calls := fakeCommands(t, map[string]string{
+		"brew info --json=v2 jq": `{"formulae": [{"license": "GPL-3.0-or-later"}]}`,
+	})
+	manager := newLicenseTestManager(t, "policy:\n  denied_licenses: [\"GPL-3.0-or-later\"]\n")
+
+	statuses, err := manager.EnsureDependencies(context.Background())
+
+	var licenseErr *LicenseError
+	if !errors.As(statuses["jq"].Error, &licenseErr) || licenseErr.License != "GPL-3.0-or-later" {
+		t.Errorf("Expected a license error for jq but got: %v", statuses["jq"].Error)
+	}
+	if err == nil {
+		t.Fatal("Expected ensure to fail on the denied license")
+	}
+	for _, call := range *calls {
+		if strings.HasPrefix(call, "brew install") {
+			t.Errorf("Expected nothing to be installed but got call %s", call)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package depman
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LockfileName is the name of the lockfile written next to the dependency configuration
+const LockfileName = "depman.lock"
+
+// lockfileVersion is the current lockfile format version
+const lockfileVersion = "1"
+
+// ErrLockfileNotFound is returned when a lockfile is required but does not exist
+var ErrLockfileNotFound = errors.New("lockfile not found")
+
+// LockedArtifact records what was installed for a dependency on a single platform
+type LockedArtifact struct {
+	URL      string `yaml:"url,omitempty"`      // URL the dependency was downloaded from
+	Checksum string `yaml:"checksum,omitempty"` // Checksum of the downloaded artifact (format: "algorithm:hash")
+}
+
+// LockedDependency records the exact resolved state of a single dependency
+type LockedDependency struct {
+	Name      string                    `yaml:"name"`              // Name of the dependency
+	Version   string                    `yaml:"version"`           // Exact version verified after installation
+	Digest    string                    `yaml:"digest"`            // Hash of the dependency definition this entry was resolved from
+	License   string                    `yaml:"license,omitempty"` // SPDX license expression, if known
+	Platforms map[string]LockedArtifact `yaml:"platforms"`         // Resolved artifacts per platform
+}
+
+// Lockfile records the exact resolved versions of all dependencies
+type Lockfile struct {
+	Version      string             `yaml:"version"`      // Lockfile format version
+	Dependencies []LockedDependency `yaml:"dependencies"` // Locked dependencies, sorted by name
+}
+
+// LoadLockfile reads and parses a lockfile
+func LoadLockfile(path string) (*Lockfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrLockfileNotFound, path)
+		}
+		return nil, fmt.Errorf("failed to read lockfile: %w", err)
+	}
+
+	var lock Lockfile
+	if err := yaml.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse lockfile: %w", err)
+	}
+
+	return &lock, nil
+}
+
+// Save writes the lockfile to the given path
+func (l *Lockfile) Save(path string) error {
+	l.Version = lockfileVersion
+	sort.Slice(l.Dependencies, func(i, j int) bool {
+		return l.Dependencies[i].Name < l.Dependencies[j].Name
+	})
+
+	data, err := yaml.Marshal(l)
+	if err != nil {
+		return fmt.Errorf("failed to encode lockfile: %w", err)
+	}
+
+	header := "# This file is generated by depman. Do not edit it manually.\n"
+	if err := os.WriteFile(path, append([]byte(header), data...), 0644); err != nil {
+		return fmt.Errorf("failed to write lockfile: %w", err)
+	}
+
+	return nil
+}
+
+// Find returns the locked entry for a dependency, or nil if it is not locked
+func (l *Lockfile) Find(name string) *LockedDependency {
+	for i := range l.Dependencies {
+		if l.Dependencies[i].Name == name {
+			return &l.Dependencies[i]
+		}
+	}
+	return nil
+}
+
+// Verify checks that the lockfile is up to date with the given configuration
+// for a platform, either an os or "os/arch", and returns a descriptive error
+// listing every stale entry
+func (l *Lockfile) Verify(config *DependencyConfig, platform string) error {
+	goos, arch, hasArch := strings.Cut(platform, "/")
+	deps := make([]*Dependency, len(config.Dependencies))
+	for i := range config.Dependencies {
+		deps[i] = &config.Dependencies[i]
+	}
+	return l.verify(config, deps, func(dep *Dependency) string {
+		// Artifacts are locked under the platform entry that applies
+		if !hasArch {
+			return platform
+		}
+		if matched, ok := matchPlatform(dep.Platforms, goos, arch); ok {
+			return matched
+		}
+		return goos
+	})
+}
+
+// lockedAlternative reports whether another dependency that satisfies the
+// same name as dep is locked
+func (l *Lockfile) lockedAlternative(config *DependencyConfig, dep *Dependency) bool {
+	names := append([]string{dep.Name}, dep.Provides...)
+	for _, other := range config.Dependencies {
+		if other.Name == dep.Name || l.Find(other.Name) == nil {
+			continue
+		}
+		for _, name := range append([]string{other.Name}, other.Provides...) {
+			if slices.Contains(names, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// verify checks the lockfile against a configuration, limited to the given
+// dependencies, looking up each one's artifacts under the platform key
+// lockPlatform returns
+func (l *Lockfile) verify(config *DependencyConfig, deps []*Dependency, lockPlatform func(*Dependency) string) error {
+	var problems []string
+
+	known := make(map[string]bool)
+	for _, dep := range config.Dependencies {
+		known[dep.Name] = true
+	}
+
+	for _, dep := range deps {
+		entry := l.Find(dep.Name)
+		switch {
+		case entry == nil && l.lockedAlternative(config, dep):
+			// Another dependency providing the same name was installed instead
+		case entry == nil:
+			problems = append(problems, fmt.Sprintf("'%s' is not locked", dep.Name))
+		case entry.Digest != dependencyDigest(dep):
+			problems = append(problems, fmt.Sprintf("'%s' changed since it was locked", dep.Name))
+		default:
+			key := lockPlatform(dep)
+			if _, ok := entry.Platforms[key]; !ok {
+				problems = append(problems, fmt.Sprintf("'%s' is not locked for platform '%s'", dep.Name, key))
+			}
+		}
+	}
+
+	for _, entry := range l.Dependencies {
+		if !known[entry.Name] {
+			problems = append(problems, fmt.Sprintf("'%s' is locked but no longer configured", entry.Name))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("lockfile is out of date: %s", strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+// dependencyDigest returns a stable hash of a dependency definition
+func dependencyDigest(dep *Dependency) string {
+	// yaml.Marshal sorts map keys, so the encoding is deterministic
+	data, err := yaml.Marshal(dep)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// LockfilePath returns the path of the lockfile belonging to the loaded configuration
+func (m *Manager) LockfilePath() string {
+	return filepath.Join(m.configDir(), LockfileName)
+}
+
+// WithFrozenLockfile requires an up-to-date lockfile during ensure and
+// installs exactly the artifacts it records instead of updating it
+func WithFrozenLockfile(frozen bool) Option {
+	return func(m *Manager) {
+		m.frozen = frozen
+	}
+}
+
+// updateLockfile records the resolved state of dependencies after an install run,
+// keeping entries recorded for other platforms and for dependencies that were not part of the run
+func (m *Manager) updateLockfile(statuses map[string]*DependencyStatus, artifacts map[string]LockedArtifact, licenses map[string]string) error {
+	path := m.LockfilePath()
+
+	lock, err := LoadLockfile(path)
+	if err != nil {
+		if !errors.Is(err, ErrLockfileNotFound) {
+			return err
+		}
+		lock = &Lockfile{}
+	}
+
+	updated := &Lockfile{}
+	for i := range m.Config.Dependencies {
+		dep := &m.Config.Dependencies[i]
+		status, ok := statuses[dep.Name]
+
+		// Local overrides must not leak into the shared lockfile
+		if m.override(dep.Name) != nil {
+			if previous := lock.Find(dep.Name); previous != nil {
+				updated.Dependencies = append(updated.Dependencies, *previous)
+			}
+			continue
+		}
+		if !ok || !status.Installed {
+			if previous := lock.Find(dep.Name); previous != nil && !ok {
+				updated.Dependencies = append(updated.Dependencies, *previous)
+			}
+			continue
+		}
+
+		entry := LockedDependency{
+			Name:      dep.Name,
+			Version:   status.CurrentVersion,
+			Digest:    dependencyDigest(dep),
+			License:   licenses[dep.Name],
+			Platforms: make(map[string]LockedArtifact),
+		}
+
+		// Keep artifacts for other platforms and the license as long as the definition is unchanged
+		if previous := lock.Find(dep.Name); previous != nil && previous.Digest == entry.Digest {
+			for platform, artifact := range previous.Platforms {
+				entry.Platforms[platform] = artifact
+			}
+			if entry.License == "" {
+				entry.License = previous.License
+			}
+		}
+
+		artifact, ok := artifacts[dep.Name]
+		if !ok {
+			artifact = entry.Platforms[m.lockPlatform(dep)]
+			if platformConfig, err := m.GetPlatformConfig(dep); err == nil {
+				artifact.URL = platformConfig.Installer.URL
+				if platformConfig.Installer.Checksum != "" {
+					artifact.Checksum = platformConfig.Installer.Checksum
+				}
+			}
+		}
+		entry.Platforms[m.lockPlatform(dep)] = artifact
+
+		updated.Dependencies = append(updated.Dependencies, entry)
+	}
+
+	return updated.Save(path)
+}
+
+// removeFromLockfile drops a dependency from the lockfile, if one exists
+func (m *Manager) removeFromLockfile(name string) error {
+	path := m.LockfilePath()
+
+	lock, err := LoadLockfile(path)
+	if err != nil {
+		if errors.Is(err, ErrLockfileNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	kept := lock.Dependencies[:0]
+	for _, entry := range lock.Dependencies {
+		if entry.Name != name {
+			kept = append(kept, entry)
+		}
+	}
+	lock.Dependencies = kept
+
+	return lock.Save(path)
+}
@@ -0,0 +1,171 @@
+package depman
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLockfileRoundTrip(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "depman-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	path := filepath.Join(tempDir, LockfileName)
+	lock := &Lockfile{
+		Dependencies: []LockedDependency{
+			{Name: "zeta", Version: "2.0.0", Digest: "sha256:b"},
+			{Name: "alpha", Version: "1.0.0", Digest: "sha256:a", Platforms: map[string]LockedArtifact{
+				"linux": {URL: "https://example.com/alpha.tar.gz", Checksum: "sha256:abc"},
+			}},
+		},
+	}
+
+	if err := lock.Save(path); err != nil {
+		t.Fatalf("Failed to save lockfile: %v", err)
+	}
+
+	loaded, err := LoadLockfile(path)
+	if err != nil {
+		t.Fatalf("Failed to load lockfile: %v", err)
+	}
+
+	if loaded.Version != lockfileVersion {
+		t.Errorf("Expected lockfile version %s but got %s", lockfileVersion, loaded.Version)
+	}
+	if len(loaded.Dependencies) != 2 || loaded.Dependencies[0].Name != "alpha" {
+		t.Fatalf("Expected dependencies sorted by name but got %+v", loaded.Dependencies)
+	}
+	if got := loaded.Find("alpha").Platforms["linux"].Checksum; got != "sha256:abc" {
+		t.Errorf("Expected checksum sha256:abc but got %s", got)
+	}
+
+	t.Run("Missing lockfile", func(t *testing.T) {
+		_, err := LoadLockfile(filepath.Join(tempDir, "missing.lock"))
+		if !errors.Is(err, ErrLockfileNotFound) {
+			t.Errorf("Expected ErrLockfileNotFound but got %v", err)
+		}
+	})
+}
+
+func TestLockfileVerify(t *testing.T) {
+	dep := Dependency{
+		Name:    "test-dep",
+		Version: Version{Required: "1.0.0"},
+		Platforms: map[string]PlatformConfig{
+			"linux": {Installer: Installer{URL: "https://example.com/test.tar.gz"}},
+		},
+	}
+	config := &DependencyConfig{Dependencies: []Dependency{dep}}
+
+	upToDate := &Lockfile{Dependencies: []LockedDependency{{
+		Name:      "test-dep",
+		Version:   "1.0.0",
+		Digest:    dependencyDigest(&dep),
+		Platforms: map[string]LockedArtifact{"linux": {URL: "https://example.com/test.tar.gz"}},
+	}}}
+
+	changed := dep
+	changed.Version.Required = "1.1.0"
+
+	testCases := []struct {
+		name        string
+		lock        *Lockfile
+		config      *DependencyConfig
+		platform    string
+		expectError bool
+	}{
+		{
+			name:        "Up to date",
+			lock:        upToDate,
+			config:      config,
+			platform:    "linux",
+			expectError: false,
+		},
+		{
+			name:        "Dependency not locked",
+			lock:        &Lockfile{},
+			config:      config,
+			platform:    "linux",
+			expectError: true,
+		},
+		{
+			name:        "Definition changed",
+			lock:        upToDate,
+			config:      &DependencyConfig{Dependencies: []Dependency{changed}},
+			platform:    "linux",
+			expectError: true,
+		},
+		{
+			name:        "Platform not locked",
+			lock:        upToDate,
+			config:      config,
+			platform:    "darwin",
+			expectError: true,
+		},
+		{
+			name:        "Extra locked dependency",
+			lock:        upToDate,
+			config:      &DependencyConfig{},
+			platform:    "linux",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.lock.Verify(tc.config, tc.platform)
+			if tc.expectError && err == nil {
+				t.Errorf("Expected an error but got none")
+			}
+			if !tc.expectError && err != nil {
+				t.Errorf("Did not expect an error but got: %v", err)
+			}
+		})
+	}
+}
+
+// TestVerifyLockfileSelection tests that frozen mode only checks the selected
+// dependencies and what they require
+func TestVerifyLockfileSelection(t *testing.T) {
+	runtime := NewDependency("runtime", "1.0.0")
+	compiler := NewDependency("compiler", "1.0.0").DependsOn("runtime")
+	docs := NewDependency("docs", "1.0.0").Tagged("docs")
+	config := NewConfig("Selection", runtime, compiler, docs)
+
+	lock := &Lockfile{}
+	for _, dep := range []*Dependency{&runtime, &compiler} {
+		lock.Dependencies = append(lock.Dependencies, LockedDependency{
+			Name:      dep.Name,
+			Digest:    dependencyDigest(dep),
+			Platforms: map[string]LockedArtifact{"linux": {}},
+		})
+	}
+
+	testCases := []struct {
+		name        string
+		selection   Selection
+		expectError bool
+	}{
+		{name: "Everything", expectError: true},
+		{name: "Skipping the unlocked dependency", selection: Selection{Skip: []string{"docs"}}},
+		{name: "Requirements of the selection", selection: Selection{Only: []string{"compiler"}}},
+		{name: "Selecting the unlocked dependency", selection: Selection{Tags: []string{"docs"}}, expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			manager := &Manager{Config: config, Platform: "linux", logger: &mockLogger{}, selection: tc.selection}
+			err := manager.verifyLockfile(lock)
+			if tc.expectError && err == nil {
+				t.Errorf("Expected an error but got none")
+			}
+			if !tc.expectError && err != nil {
+				t.Errorf("Did not expect an error but got: %v", err)
+			}
+		})
+	}
+}
@@ -3,34 +3,26 @@ package depman
 import (
 	"context"
 	"fmt"
-	"os"
-	"os/exec"
+	"io"
 	"regexp"
 	"runtime"
 	"strings"
 	"time"
 
-	"github.com/Masterminds/semver/v3"
-
-	"github.com/devnadeemashraf/depman/internal/downloader"
 	"github.com/devnadeemashraf/depman/internal/environment"
 	"github.com/devnadeemashraf/depman/internal/logger"
 )
 
-// NewManager creates a new dependency manager with optional configuration
+// NewManager creates a new dependency manager with optional configuration.
+// The manager prints nothing unless WithLogOutput or WithLogger says where
+// its logs go, and asks nothing unless WithReporter sets who asks.
 func NewManager(configPath string, opts ...Option) (*Manager, error) {
-	// Load dependency configuration
-	config, err := LoadDependencyConfig(configPath)
-	if err != nil {
-		return nil, err
-	}
-
 	// Create a new manager with defaults
 	manager := &Manager{
-		Config:     config,
-		ConfigPath: configPath,
 		Platform:   runtime.GOOS, // "windows", "linux", or "darwin"
-		logger:     logger.Default(),
+		Arch:       DetectArch(),
+		wsl:        DetectWSL(),
+		logger:     logger.Default().WithOutput(io.Discard),
 		envManager: environment.NewManager(),
 	}
 
@@ -39,24 +31,197 @@ func NewManager(configPath string, opts ...Option) (*Manager, error) {
 		opt(manager)
 	}
 
+	// User-level settings fill in what no option set
+	if err := manager.loadSettings(); err != nil {
+		return nil, err
+	}
+
+	// Traces and metrics are exported if the environment configures an endpoint
+	manager.setupTelemetry()
+
+	// The organization policy applies to library and CLI use alike
+	if err := manager.loadOrgPolicy(); err != nil {
+		return nil, err
+	}
+
+	// Distribution-specific platform entries apply on the running Linux system
+	if manager.distro == nil && manager.Platform == "linux" && runtime.GOOS == "linux" {
+		manager.distro = DetectDistro()
+	}
+
+	if manager.Config != nil {
+		// Configurations built in code are checked like files
+		if err := manager.Config.Validate(); err != nil {
+			return nil, err
+		}
+		var err error
+		if manager.Config, err = resolveRecipes(manager.Config); err != nil {
+			return nil, err
+		}
+	} else if err := manager.loadConfig(configPath); err != nil {
+		return nil, err
+	}
+
+	// Adjust the configuration for the selected environment
+	if manager.profile != "" {
+		var err error
+		manager.Config, err = applyProfile(manager.Config, manager.profile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Local pins and unpins win over the configuration and its profile
+	if err := manager.applyOverrides(); err != nil {
+		return nil, err
+	}
+
+	// Drop dependencies whose when condition this machine does not meet
+	var err error
+	if manager.Config, err = manager.applyConditions(manager.Config); err != nil {
+		return nil, err
+	}
+
 	return manager, nil
 }
 
+// loadConfig finds, fetches if remote and loads the configuration file
+func (m *Manager) loadConfig(configPath string) error {
+	if IsRemoteConfig(configPath) {
+		// Remote configurations are fetched into the local cache first
+		cached, err := m.fetchRemoteConfig(context.Background(), configPath)
+		if err != nil {
+			return err
+		}
+		m.ConfigSource = configPath
+		configPath = cached
+	} else {
+		// Resolve the configuration path so related files (like the lockfile) can be found next to it
+		found, err := FindDependencyFile(configPath)
+		if err != nil {
+			return err
+		}
+		configPath = found
+	}
+
+	// Load dependency configuration
+	config, err := LoadDependencyConfig(configPath)
+	if err != nil {
+		return err
+	}
+	m.Config = config
+	m.ConfigPath = configPath
+	return nil
+}
+
 // GetPlatformConfig returns platform-specific configuration for a dependency
 func (m *Manager) GetPlatformConfig(dep *Dependency) (*PlatformConfig, error) {
-	// Check if we have configuration for current platform
-	platform, ok := dep.Platforms[m.Platform]
+	return m.platformConfigIn(dep, m.environments(dep)[0])
+}
+
+// InstallMethod returns the install method a dependency uses on the current
+// platform, or "" if it has no configuration for it
+func (m *Manager) InstallMethod(dep *Dependency) string {
+	platformConfig, err := m.GetPlatformConfig(dep)
+	if err != nil {
+		return ""
+	}
+	return installMethod(platformConfig)
+}
+
+// platformConfigIn returns the platform-specific configuration for a
+// dependency in one of the environments it is installed in
+func (m *Manager) platformConfigIn(dep *Dependency, env WSLTarget) (*PlatformConfig, error) {
+	// Kinds other than tools are installed the same way everywhere
+	if platformConfig, ok := kindPlatformConfig(dep); ok {
+		return platformConfig, nil
+	}
+
+	// Check if we have configuration for current platform and architecture
+	key, ok := m.platformKeyIn(dep, env)
 	if !ok {
-		return nil, fmt.Errorf("no configuration available for platform: %s", m.Platform)
+		return nil, fmt.Errorf("no configuration available for platform: %s", m.environmentTarget(env))
+	}
+	platform := dep.Platforms[key]
+
+	// Expand environment variables and templates in URLs, paths and commands
+	platform, err := interpolated(m, platform, dep)
+	if err != nil {
+		return nil, err
 	}
 
 	return &platform, nil
 }
 
+// resolvedPlatformConfig returns the platform configuration an install would
+// actually use, with artifacts pinned by a frozen lockfile applied
+func (m *Manager) resolvedPlatformConfig(dep *Dependency) (*PlatformConfig, error) {
+	platformConfig, err := m.GetPlatformConfig(dep)
+	if err != nil {
+		return nil, err
+	}
+
+	// In frozen mode, install exactly what the lockfile recorded
+	if m.lock != nil {
+		if entry := m.lock.Find(dep.Name); entry != nil {
+			if locked, ok := entry.Platforms[m.lockPlatform(dep)]; ok {
+				if locked.URL != "" {
+					platformConfig.Installer.URL = locked.URL
+				}
+				if locked.Checksum != "" {
+					platformConfig.Installer.Checksum = locked.Checksum
+				}
+			}
+		}
+	}
+
+	// Offline, releases resolved when the bundle was created stand in for API lookups
+	if m.bundle != nil && platformConfig.Installer.URL == "" {
+		if artifact := m.bundledDependency(dep.Name); artifact != nil {
+			platformConfig.Installer.URL = artifact.URL
+		}
+	}
+
+	return platformConfig, nil
+}
+
+// dependencyContext returns the context for working on a dependency, bounded
+// by the dependency's timeout if it has one
+func (m *Manager) dependencyContext(ctx context.Context, dep *Dependency) (context.Context, context.CancelFunc) {
+	timeout, err := parseTimeout(dep.Timeout)
+	if err != nil || timeout == 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// timeoutError names the dependency's timeout when err was caused by it
+func timeoutError(ctx context.Context, dep *Dependency, err error) error {
+	if err == nil || dep.Timeout == "" || ctx.Err() != context.DeadlineExceeded {
+		return err
+	}
+	return fmt.Errorf("%s timed out after %s: %w", dep.Name, dep.Timeout, err)
+}
+
+// parseTimeout parses a dependency timeout; an empty timeout means no limit
+func parseTimeout(timeout string) (time.Duration, error) {
+	if timeout == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(timeout)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("timeout must be positive, got %s", timeout)
+	}
+	return d, nil
+}
+
 // CheckDependency verifies if a dependency is installed and if it needs updating
-func (m *Manager) CheckDependency(dep *Dependency) (*DependencyStatus, error) {
+func (m *Manager) CheckDependency(ctx context.Context, dep *Dependency) (*DependencyStatus, error) {
 	// Use the more thorough verification
-	return m.VerifyDependency(dep)
+	return m.VerifyDependency(ctx, dep)
 }
 
 // validateDependencies checks if all dependencies are properly defined
@@ -69,25 +234,65 @@ func (m *Manager) validateDependencies() []error {
 		return errors
 	}
 
+	// The dependency graph must reference known dependencies and be acyclic
+	if _, err := m.resolveInstallOrder(m.allDependencyNames()); err != nil {
+		errors = append(errors, err)
+	}
+
 	// Validate each dependency
 	for _, dep := range m.Config.Dependencies {
+		// The kind decides what else the dependency must declare
+		kind, err := ParseDependencyKind(dep.Kind)
+		if err != nil {
+			errors = append(errors, fmt.Errorf("dependency '%s' has %w", dep.Name, err))
+			continue
+		}
+		if kind != KindTool {
+			if err := validateKindSpec(&dep); err != nil {
+				errors = append(errors, fmt.Errorf("dependency '%s' has %w", dep.Name, err))
+			}
+			if dep.Version != (Version{}) {
+				errors = append(errors, fmt.Errorf("dependency '%s' must not set a version: %s dependencies are not versioned", dep.Name, kind))
+			}
+			continue
+		}
+
 		// Check if platform-specific config exists
-		if _, ok := dep.Platforms[m.Platform]; !ok {
+		if target, missing := m.missingPlatform(&dep); missing {
 			errors = append(errors, fmt.Errorf("dependency '%s' has no configuration for platform '%s'",
-				dep.Name, m.Platform))
+				dep.Name, target))
 			continue
 		}
 
-		// Validate version information
-		if dep.Version.Required == "" {
-			errors = append(errors, fmt.Errorf("dependency '%s' has no required version", dep.Name))
+		// The WSL target must be one of the known environments
+		if _, err := ParseWSLTarget(dep.WSL); err != nil {
+			errors = append(errors, fmt.Errorf("dependency '%s' has %w", dep.Name, err))
+		}
+
+		// Validate version information; a constraint alone is enough
+		if dep.Version.Required == "" && dep.Version.Constraint == "" {
+			errors = append(errors, fmt.Errorf("dependency '%s' has no required version or constraint", dep.Name))
+		}
+
+		// A timeout must be a positive duration
+		if dep.Timeout != "" {
+			if _, err := parseTimeout(dep.Timeout); err != nil {
+				errors = append(errors, fmt.Errorf("dependency '%s' has invalid timeout: %w", dep.Name, err))
+			}
+		}
+
+		// A custom version pattern must compile
+		if dep.VersionRegex != "" {
+			if _, err := regexp.Compile(dep.VersionRegex); err != nil {
+				errors = append(errors, fmt.Errorf("dependency '%s' has invalid version_regex: %w", dep.Name, err))
+			}
 		}
 
 		// If constraint is provided, make sure it's valid
 		if dep.Version.Constraint != "" {
-			if _, err := semver.NewConstraint(dep.Version.Constraint); err != nil {
-				errors = append(errors, fmt.Errorf("dependency '%s' has invalid version constraint '%s': %w",
-					dep.Name, dep.Version.Constraint, err))
+			if _, err := ParseConstraint(dep.Version.Constraint); err != nil {
+				errors = append(errors, fmt.Errorf("dependency '%s' has invalid version constraint: %w",
+					dep.Name, err))
 			}
 		}
 	}
@@ -95,120 +300,111 @@ func (m *Manager) validateDependencies() []error {
 	return errors
 }
 
-// installDependency handles the actual installation of a dependency
+// installDependency handles the actual installation of a dependency and
+// returns the artifact it installed so it can be recorded in the lockfile
+func (m *Manager) installDependency(ctx context.Context, dep *Dependency) (LockedArtifact, error) {
+	var artifact LockedArtifact
 
-// installDependency handles the actual installation of a dependency
-func (m *Manager) installDependency(dep *Dependency) error {
-	// Get platform config
-	platformConfig, err := m.GetPlatformConfig(dep)
-	if err != nil {
-		return err
-	}
-
-	// Create a temporary directory for downloads
-	tempDir, err := os.MkdirTemp("", "depman-download-*")
-	if err != nil {
-		return fmt.Errorf("failed to create temporary directory: %w", err)
+	for i, env := range m.environments(dep) {
+		installed, err := m.installIn(ctx, dep, env, i == 0)
+		if err != nil {
+			return artifact, err
+		}
+		// The lockfile records what the primary environment installed
+		if i == 0 {
+			artifact = installed
+		}
 	}
-	defer os.RemoveAll(tempDir) // Clean up when done
 
-	// Download dependency if URL is specified
-	downloadPath := ""
-	if platformConfig.Installer.URL != "" {
-		m.logger.Infof("Downloading %s from %s", dep.Name, platformConfig.Installer.URL)
+	return artifact, nil
+}
 
-		// Set up download options
-		opts := downloader.DownloadOptions{
-			URL:          platformConfig.Installer.URL,
-			DestDir:      tempDir,
-			ShowProgress: true,
-		}
+// installIn installs a dependency in one environment. Only the primary
+// environment follows the lockfile and offline bundle.
+func (m *Manager) installIn(ctx context.Context, dep *Dependency, env WSLTarget, primary bool) (LockedArtifact, error) {
+	var artifact LockedArtifact
 
-		// Add checksum if provided
-		if platformConfig.Installer.Checksum != "" {
-			opts.Checksum = platformConfig.Installer.Checksum
-		}
+	// Get platform config
+	var platformConfig *PlatformConfig
+	var err error
+	if primary {
+		platformConfig, err = m.resolvedPlatformConfig(dep)
+	} else {
+		platformConfig, err = m.platformConfigIn(dep, env)
+	}
+	if err != nil {
+		return artifact, err
+	}
 
-		// Download the file
-		result, err := downloader.Download(opts)
-		if err != nil {
-			return fmt.Errorf("failed to download dependency: %w", err)
+	// From WSL, the Windows host is reached by running its commands through interop
+	if env == WSLWindows {
+		if !interopMethods[installMethod(platformConfig)] {
+			return artifact, fmt.Errorf("install method '%s' cannot install on the Windows host from WSL", installMethod(platformConfig))
 		}
-
-		downloadPath = result.FilePath
-		m.logger.Infof("Downloaded %s (%d bytes)", dep.Name, result.Size)
+		m.logger.Infof("Installing %s on the Windows host", dep.Name)
+		ctx = environmentContext(ctx, env)
 	}
 
-	// Prepare install command with replacements
-	installCmd := make([]string, len(platformConfig.Commands.Install))
-	for i, arg := range platformConfig.Commands.Install {
-		// Replace placeholders in command arguments
-		arg = strings.ReplaceAll(arg, "{download_path}", downloadPath)
-
-		// Add more replacements as needed:
-		// - {install_dir} for installation directory
-		// - {product_id} for product ID
-		// - etc.
-
-		installCmd[i] = arg
+	// Offline installs can only use artifacts from the bundle
+	if m.bundle != nil && !bundleMethods[installMethod(platformConfig)] {
+		return artifact, fmt.Errorf("install method '%s' needs network access and cannot be used offline", installMethod(platformConfig))
 	}
 
-	m.logger.Infof("Installing %s using command: %s", dep.Name, strings.Join(installCmd, " "))
+	if err := m.orgPolicy.checkMethod(dep, installMethod(platformConfig)); err != nil {
+		return artifact, err
+	}
 
-	// Execute installation command
-	cmd := exec.Command(installCmd[0], installCmd[1:]...)
-	output, err := cmd.CombinedOutput()
+	// Hand off to the strategy for the configured install method
+	strategy, err := m.strategyFor(platformConfig)
 	if err != nil {
-		return fmt.Errorf("installation failed: %w, output: %s", err, output)
+		return artifact, err
 	}
 
-	m.logger.Infof("Successfully installed %s", dep.Name)
-	return nil
+	return strategy.install(ctx, m, dep, platformConfig)
 }
 
 // VerifyDependency performs a thorough check of an installed dependency
-func (m *Manager) VerifyDependency(dep *Dependency) (*DependencyStatus, error) {
+func (m *Manager) VerifyDependency(ctx context.Context, dep *Dependency) (*DependencyStatus, error) {
+	var status *DependencyStatus
+	var err error
+	for _, env := range m.environments(dep) {
+		// Installed in both WSL environments, the first one that needs work decides
+		if status, err = m.verifyIn(ctx, dep, env); err != nil || status.Problem() != ProblemNone {
+			return status, err
+		}
+	}
+	return status, err
+}
+
+// verifyIn checks a dependency in one of the environments it is installed in
+func (m *Manager) verifyIn(ctx context.Context, dep *Dependency, env WSLTarget) (*DependencyStatus, error) {
 	status := &DependencyStatus{
 		Name:      dep.Name,
 		Installed: false,
+		Projects:  dep.Projects,
 	}
 
 	// Get platform-specific configuration
-	platformConfig, err := m.GetPlatformConfig(dep)
+	platformConfig, err := m.platformConfigIn(dep, env)
 	if err != nil {
 		status.Error = err
 		return status, err
 	}
 
-	// Check if verify command is provided
-	if len(platformConfig.Commands.Verify) == 0 {
-		status.Error = fmt.Errorf("no verification command provided for dependency: %s", dep.Name)
-		return status, status.Error
-	}
-
 	// Log the verification attempt
-	m.logger.Infof("Verifying dependency: %s", dep.Name)
-
-	// Run verify command with timeout to avoid hanging
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	// Create the command
-	cmd := exec.CommandContext(ctx, platformConfig.Commands.Verify[0], platformConfig.Commands.Verify[1:]...)
-
-	// Capture output
-	output, err := cmd.CombinedOutput()
-	outputStr := strings.TrimSpace(string(output))
-
-	// Handle timeout separately
-	if ctx.Err() == context.DeadlineExceeded {
-		status.Error = fmt.Errorf("verification command timed out after 30 seconds")
-		return status, status.Error
+	if env == WSLWindows {
+		m.logger.Infof("Verifying dependency: %s on the Windows host", dep.Name)
+	} else {
+		m.logger.Infof("Verifying dependency: %s", dep.Name)
 	}
 
-	// Handle command errors
+	// Ask the dependency for its installed version
+	outputStr, err := m.readInstalledVersion(environmentContext(ctx, env), dep, platformConfig)
 	if err != nil {
-		status.Error = fmt.Errorf("dependency verification failed: %w, output: %s", err, outputStr)
+		if env == WSLWindows {
+			err = fmt.Errorf("on the Windows host: %w", err)
+		}
+		status.Error = err
 		return status, status.Error
 	}
 
@@ -219,15 +415,21 @@ func (m *Manager) VerifyDependency(dep *Dependency) (*DependencyStatus, error) {
 	// Parse current version from command output
 	status.CurrentVersion = outputStr
 
-	// Check if we can extract a cleaner version
-	version := extractVersion(outputStr)
-	if version != "" {
+	// Extract the version with the dependency's own pattern, or guess a cleaner one
+	if dep.VersionRegex != "" {
+		version, err := extractVersionWith(dep.VersionRegex, outputStr)
+		if err != nil {
+			status.Error = err
+			return status, status.Error
+		}
+		status.CurrentVersion = version
+	} else if version := extractVersion(outputStr); version != "" {
 		status.CurrentVersion = version
 	}
 
 	// Check if update is needed
 	if dep.Version.Required != "" {
-		updateType, err := CheckVersionUpdate(status.CurrentVersion, dep.Version.Required)
+		updateType, err := dep.Version.Classify(status.CurrentVersion)
 		if err != nil {
 			status.Error = err
 			m.logger.Errorf("Failed to check version update: %v", err)
@@ -242,7 +444,7 @@ func (m *Manager) VerifyDependency(dep *Dependency) (*DependencyStatus, error) {
 
 	// Check if current version is compatible with constraint
 	if dep.Version.Constraint != "" {
-		compatible, err := IsVersionCompatible(status.CurrentVersion, dep.Version.Constraint)
+		compatible, err := dep.Version.Satisfied(status.CurrentVersion)
 		if err != nil {
 			status.Error = err
 			m.logger.Errorf("Failed to check version compatibility: %v", err)
@@ -261,6 +463,63 @@ func (m *Manager) VerifyDependency(dep *Dependency) (*DependencyStatus, error) {
 	return status, nil
 }
 
+// readInstalledVersion returns the raw version output of an installed dependency.
+// The platform's verify command takes precedence; otherwise install strategies
+// that know how to query their package manager are asked directly.
+func (m *Manager) readInstalledVersion(ctx context.Context, dep *Dependency, platformConfig *PlatformConfig) (string, error) {
+	verifyCmd := platformConfig.Commands.Verify
+	if len(dep.VersionCommand) > 0 {
+		var err error
+		if verifyCmd, err = interpolated(m, dep.VersionCommand, dep); err != nil {
+			return "", err
+		}
+	}
+
+	if len(verifyCmd) == 0 {
+		if strategy, err := m.strategyFor(platformConfig); err == nil {
+			if detector, ok := strategy.(versionDetector); ok {
+				version, err := detector.detectVersion(ctx, m, dep, platformConfig)
+				if err != nil {
+					return "", fmt.Errorf("dependency verification failed: %w", err)
+				}
+				return version, nil
+			}
+		}
+		return "", fmt.Errorf("no verification command provided for dependency: %s", dep.Name)
+	}
+
+	// Run verify command with timeout to avoid hanging
+	verifyCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	// Create the command; managed shims only exist inside the current environment
+	name := verifyCmd[0]
+	if !onWindowsHost(ctx) {
+		name = m.resolveExecutable(name)
+	}
+	cmd := hostCommand(verifyCtx, name, verifyCmd[1:]...)
+
+	// Capture output
+	output, err := cmd.CombinedOutput()
+	installLogOf(ctx).record(name, verifyCmd[1:], output, err)
+	outputStr := strings.TrimSpace(string(output))
+
+	// Handle cancellation by the caller and timeouts separately
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if verifyCtx.Err() == context.DeadlineExceeded {
+		return "", fmt.Errorf("verification command timed out after 30 seconds")
+	}
+
+	// Handle command errors
+	if err != nil {
+		return "", fmt.Errorf("dependency verification failed: %w, output: %s", err, outputStr)
+	}
+
+	return outputStr, nil
+}
+
 // extractVersion tries to extract a clean semantic version from output text
 // This helps with commands that return more than just a version number
 func extractVersion(output string) string {
@@ -287,8 +546,13 @@ func (m *Manager) setupDependencyEnvironment(dep *Dependency) error {
 		return nil // No environment to set up
 	}
 
+	environment, err := interpolated(m, dep.Environment, dep)
+	if err != nil {
+		return err
+	}
+
 	// Add paths to PATH
-	for _, path := range dep.Environment.Path {
+	for _, path := range environment.Path {
 		// Expand variables in path
 		expandedPath := m.envManager.ExpandVariables(path)
 		m.envManager.AddPath(expandedPath)
@@ -296,7 +560,10 @@ func (m *Manager) setupDependencyEnvironment(dep *Dependency) error {
 	}
 
 	// Add environment variables
-	for key, value := range dep.Environment.Variables {
+	for key, value := range environment.Variables {
+		if err := ValidateVariableName(key); err != nil {
+			return fmt.Errorf("dependency '%s': %w", dep.Name, err)
+		}
 		// Expand variables in value
 		expandedValue := m.envManager.ExpandVariables(value)
 		m.envManager.AddVariable(key, expandedValue)
@@ -305,3 +572,36 @@ func (m *Manager) setupDependencyEnvironment(dep *Dependency) error {
 
 	return nil
 }
+
+// teardownDependencyEnvironment removes the PATH entries and variables added for a dependency
+func (m *Manager) teardownDependencyEnvironment(dep *Dependency) {
+	for _, path := range dep.Environment.Path {
+		m.envManager.RemovePath(m.envManager.ExpandVariables(path))
+	}
+
+	for key := range dep.Environment.Variables {
+		m.envManager.RemoveVariable(key)
+	}
+}
+
+// extractVersionWith extracts the version from output using a custom pattern,
+// preferring a "version" named group, then the first group, then the whole match
+func extractVersionWith(pattern, output string) (string, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", fmt.Errorf("invalid version_regex '%s': %w", pattern, err)
+	}
+
+	match := re.FindStringSubmatch(output)
+	if match == nil {
+		return "", fmt.Errorf("version_regex '%s' did not match output: %s", pattern, output)
+	}
+
+	if i := re.SubexpIndex("version"); i > 0 {
+		return match[i], nil
+	}
+	if len(match) > 1 {
+		return match[1], nil
+	}
+	return match[0], nil
+}
@@ -1,13 +1,21 @@
 package depman
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
-// mockLogger is a simple logger for testing
+// mockLogger is a simple logger for testing, safe for concurrent use
 type mockLogger struct {
+	mu        sync.Mutex
 	infoLogs  []string
 	errorLogs []string
 	debugLogs []string
@@ -16,18 +24,26 @@ type mockLogger struct {
 
 func (l *mockLogger) Infof(format string, args ...interface{}) {
 	// No need to actually format for tests
+	l.mu.Lock()
+	defer l.mu.Unlock()
 	l.infoLogs = append(l.infoLogs, format)
 }
 
 func (l *mockLogger) Errorf(format string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
 	l.errorLogs = append(l.errorLogs, format)
 }
 
 func (l *mockLogger) Debugf(format string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
 	l.debugLogs = append(l.debugLogs, format)
 }
 
 func (l *mockLogger) Warnf(format string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
 	l.warnLogs = append(l.warnLogs, format)
 }
 
@@ -292,3 +308,285 @@ func TestValidateDependencies(t *testing.T) {
 		}
 	})
 }
+
+// TestCheckAllDependenciesConcurrent tests that parallel checking reports every dependency
+func TestCheckAllDependenciesConcurrent(t *testing.T) {
+	deps := make([]Dependency, 0, 10)
+	for i := 0; i < 10; i++ {
+		deps = append(deps, Dependency{
+			Name:    "missing-tool-" + string(rune('a'+i)),
+			Version: Version{Required: "1.0.0"},
+			Platforms: map[string]PlatformConfig{
+				"linux": {
+					Commands: Commands{
+						Verify: []string{"depman-test-missing-tool-" + string(rune('a'+i)), "--version"},
+					},
+				},
+			},
+		})
+	}
+
+	for _, workers := range []int{0, 1, 4, 32} {
+		manager := &Manager{
+			Config:   &DependencyConfig{Name: "Test App", Dependencies: deps},
+			Platform: "linux",
+			logger:   &mockLogger{},
+		}
+		WithConcurrency(workers)(manager)
+
+		statuses, err := manager.CheckAllDependencies(context.Background())
+		if err != nil {
+			t.Fatalf("Did not expect an error with %d workers but got: %v", workers, err)
+		}
+
+		if len(statuses) != len(deps) {
+			t.Fatalf("Expected %d statuses with %d workers but got %d", len(deps), workers, len(statuses))
+		}
+
+		for _, dep := range deps {
+			status, ok := statuses[dep.Name]
+			if !ok || status == nil {
+				t.Fatalf("Missing status for %s with %d workers", dep.Name, workers)
+			}
+			if status.Installed {
+				t.Errorf("Expected %s to be reported as not installed", dep.Name)
+			}
+		}
+	}
+}
+
+// TestDependencyTimeout tests that checks stop at the dependency's timeout or when the caller cancels
+func TestDependencyTimeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sleep as the verify command")
+	}
+
+	dep := Dependency{
+		Name:    "slow-tool",
+		Version: Version{Required: "1.0.0"},
+		Timeout: "100ms",
+		Platforms: map[string]PlatformConfig{
+			"linux": {Commands: Commands{Verify: []string{"sleep", "5"}}},
+		},
+	}
+	manager := &Manager{
+		Config:   &DependencyConfig{Name: "Test App", Dependencies: []Dependency{dep}},
+		Platform: "linux",
+		logger:   &mockLogger{},
+	}
+
+	t.Run("Dependency timeout", func(t *testing.T) {
+		start := time.Now()
+		status, err := manager.checkWithHooks(context.Background(), &manager.Config.Dependencies[0])
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("Expected a deadline error but got %v", err)
+		}
+		if !strings.Contains(err.Error(), "timed out after 100ms") {
+			t.Errorf("Expected the timeout to be named but got %v", err)
+		}
+		if status == nil || status.Error == nil {
+			t.Errorf("Expected the status to carry the error")
+		}
+		if elapsed := time.Since(start); elapsed > 3*time.Second {
+			t.Errorf("Expected the check to stop at the timeout but it took %s", elapsed)
+		}
+	})
+
+	t.Run("Cancelled by caller", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		statuses, err := manager.CheckAllDependencies(ctx)
+		if err != nil {
+			t.Fatalf("Did not expect an error but got: %v", err)
+		}
+		if status := statuses["slow-tool"]; status == nil || !errors.Is(status.Error, context.Canceled) {
+			t.Errorf("Expected the check to be cancelled but got %+v", status)
+		}
+	})
+}
+
+// TestResolveInstallOrder tests that transitive dependencies are installed first
+func TestResolveInstallOrder(t *testing.T) {
+	manager := &Manager{
+		Config: &DependencyConfig{
+			Dependencies: []Dependency{
+				{Name: "app", Dependencies: []string{"runtime", "cli"}},
+				{Name: "cli", Dependencies: []string{"runtime"}},
+				{Name: "runtime"},
+				{Name: "unrelated"},
+				{Name: "loop-a", Dependencies: []string{"loop-b"}},
+				{Name: "loop-b", Dependencies: []string{"loop-a"}},
+				{Name: "broken", Dependencies: []string{"missing"}},
+			},
+		},
+		logger: &mockLogger{},
+	}
+
+	t.Run("Transitive dependencies first", func(t *testing.T) {
+		order, err := manager.resolveInstallOrder([]string{"app"})
+		if err != nil {
+			t.Fatalf("Did not expect an error but got: %v", err)
+		}
+
+		var names []string
+		for _, dep := range order {
+			names = append(names, dep.Name)
+		}
+
+		expected := []string{"runtime", "cli", "app"}
+		if len(names) != len(expected) {
+			t.Fatalf("Expected order %v but got %v", expected, names)
+		}
+		for i := range expected {
+			if names[i] != expected[i] {
+				t.Fatalf("Expected order %v but got %v", expected, names)
+			}
+		}
+	})
+
+	t.Run("Error on cycle", func(t *testing.T) {
+		_, err := manager.resolveInstallOrder([]string{"loop-a"})
+		var cycle *CycleError
+		if !errors.As(err, &cycle) {
+			t.Fatalf("Expected a CycleError but got %v", err)
+		}
+		if got := strings.Join(cycle.Cycle, " -> "); got != "loop-a -> loop-b -> loop-a" {
+			t.Errorf("Expected the cycle to be named but got %s", got)
+		}
+	})
+
+	t.Run("Error on unknown dependency", func(t *testing.T) {
+		if _, err := manager.resolveInstallOrder([]string{"broken"}); err == nil {
+			t.Errorf("Expected an error but got none")
+		}
+	})
+}
+
+// TestInstallGraph tests that installs wait for their dependencies and independent ones overlap
+func TestInstallGraph(t *testing.T) {
+	deps := []*Dependency{
+		{Name: "runtime-a"},
+		{Name: "runtime-b"},
+		{Name: "app", Dependencies: []string{"runtime-a", "runtime-b"}},
+	}
+	archive := map[string]PlatformConfig{"linux": {Installer: Installer{Method: "archive"}}}
+	for _, dep := range deps {
+		dep.Platforms = archive
+	}
+	manager := &Manager{Platform: "linux", logger: &mockLogger{}, concurrency: 4}
+
+	t.Run("Parallel roots before dependents", func(t *testing.T) {
+		var mu sync.Mutex
+		var finished []string
+		started := make(chan struct{}, 2)
+		release := make(chan struct{})
+
+		go func() {
+			// Both roots must be running at the same time before either finishes
+			<-started
+			<-started
+			close(release)
+		}()
+
+		err := manager.installGraph(context.Background(), deps, func(ctx context.Context, dep *Dependency) error {
+			if dep.Name != "app" {
+				started <- struct{}{}
+				<-release
+			}
+			mu.Lock()
+			finished = append(finished, dep.Name)
+			mu.Unlock()
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Did not expect an error but got: %v", err)
+		}
+		if len(finished) != 3 || finished[2] != "app" {
+			t.Errorf("Expected app to install last but got %v", finished)
+		}
+	})
+
+	t.Run("Dependents of failures are skipped", func(t *testing.T) {
+		var attempted []string
+		var mu sync.Mutex
+
+		err := manager.installGraph(context.Background(), deps, func(ctx context.Context, dep *Dependency) error {
+			mu.Lock()
+			attempted = append(attempted, dep.Name)
+			mu.Unlock()
+			if dep.Name == "runtime-b" {
+				return fmt.Errorf("install failed")
+			}
+			return nil
+		})
+		if err == nil || err.Error() != "install failed" {
+			t.Errorf("Expected the install failure but got %v", err)
+		}
+		for _, name := range attempted {
+			if name == "app" {
+				t.Errorf("Expected app not to be attempted")
+			}
+		}
+	})
+}
+
+// TestExtractVersionWith tests custom version patterns against unusual tool output
+func TestExtractVersionWith(t *testing.T) {
+	testCases := []struct {
+		name        string
+		pattern     string
+		output      string
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "Named capture",
+			pattern:  `git version (?P<version>\d+\.\d+\.\d+)`,
+			output:   "git version 2.43.0",
+			expected: "2.43.0",
+		},
+		{
+			name:     "Named capture after other groups",
+			pattern:  `(openjdk|java) (?P<version>[\d.]+) (\d{4}-\d{2}-\d{2})`,
+			output:   "openjdk 21.0.2 2024-01-16\nOpenJDK Runtime Environment",
+			expected: "21.0.2",
+		},
+		{
+			name:     "First group",
+			pattern:  `go(\d+\.\d+(\.\d+)?)`,
+			output:   "go version go1.22.1 linux/amd64",
+			expected: "1.22.1",
+		},
+		{
+			name:        "No match",
+			pattern:     `version (\d+)`,
+			output:      "unknown",
+			expectError: true,
+		},
+		{
+			name:        "Invalid pattern",
+			pattern:     `(`,
+			output:      "1.0.0",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			version, err := extractVersionWith(tc.pattern, tc.output)
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("Expected an error but got %s", version)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Did not expect an error but got: %v", err)
+			}
+			if version != tc.expected {
+				t.Errorf("Expected version %s but got %s", tc.expected, version)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package depman
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Mirror rewrites artifact URLs so downloads come from another host, e.g. an
+// internal Artifactory remote repository proxying github.com. A mirror
+// matches either a URL prefix or a regular expression.
+type Mirror struct {
+	Prefix  string `yaml:"prefix,omitempty"` // URL prefix to replace, e.g. "https://github.com/"
+	Match   string `yaml:"match,omitempty"`  // Regular expression to replace instead of a prefix; Replace can refer to its groups as $1
+	Replace string `yaml:"replace"`          // What the matched part of the URL is replaced with
+}
+
+// validate checks a mirror has exactly one of prefix and match and that its
+// regular expression compiles
+func (mr Mirror) validate() error {
+	if (mr.Prefix == "") == (mr.Match == "") {
+		return fmt.Errorf("a mirror needs either 'prefix' or 'match'")
+	}
+	if mr.Replace == "" {
+		return fmt.Errorf("missing required field 'replace'")
+	}
+	if mr.Match != "" {
+		if _, err := regexp.Compile(mr.Match); err != nil {
+			return fmt.Errorf("invalid match: %w", err)
+		}
+	}
+	return nil
+}
+
+// rewrite returns url rewritten by the mirror and whether the mirror matched
+func (mr Mirror) rewrite(url string) (string, bool) {
+	if mr.Prefix != "" {
+		if !strings.HasPrefix(url, mr.Prefix) {
+			return url, false
+		}
+		return mr.Replace + strings.TrimPrefix(url, mr.Prefix), true
+	}
+
+	pattern, err := regexp.Compile(mr.Match)
+	if err != nil || !pattern.MatchString(url) {
+		return url, false
+	}
+	return pattern.ReplaceAllString(url, mr.Replace), true
+}
+
+// mirrorURL returns the URL an artifact is downloaded from: url rewritten by
+// the first mirror that matches it. Mirrors of the organization policy come
+// before those of the configuration, so a project cannot route around them.
+func (m *Manager) mirrorURL(url string) string {
+	var mirrors []Mirror
+	if m.orgPolicy != nil {
+		mirrors = append(mirrors, m.orgPolicy.Mirrors...)
+	}
+	if m.Config != nil {
+		mirrors = append(mirrors, m.Config.Mirrors...)
+	}
+
+	for _, mirror := range mirrors {
+		if rewritten, ok := mirror.rewrite(url); ok {
+			m.componentLogger("download", nil).Debugf("Using mirror %s for %s", redactURL(rewritten), redactURL(url))
+			return rewritten
+		}
+	}
+	return url
+}
@@ -0,0 +1,103 @@
+package depman
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMirrorURL(t *testing.T) {
+	manager := &Manager{
+		logger: &mockLogger{},
+		orgPolicy: &OrgPolicy{Mirrors: []Mirror{
+			{Prefix: "https://github.com/acme/", Replace: "https://git.acme.internal/acme/"},
+		}},
+		Config: &DependencyConfig{Mirrors: []Mirror{
+			{Prefix: "https://github.com/", Replace: "https://artifactory.acme.internal/github/"},
+			{Match: `^https://dl\.k8s\.io/release/(v[^/]+)/`, Replace: "https://mirror.acme.internal/k8s/$1/"},
+		}},
+	}
+
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{url: "https://github.com/jqlang/jq/releases/download/jq-1.7.1/jq-linux-amd64", expected: "https://artifactory.acme.internal/github/jqlang/jq/releases/download/jq-1.7.1/jq-linux-amd64"},
+		{url: "https://github.com/acme/tool/releases/download/v1/tool", expected: "https://git.acme.internal/acme/tool/releases/download/v1/tool"},
+		{url: "https://dl.k8s.io/release/v1.29.3/bin/linux/amd64/kubectl", expected: "https://mirror.acme.internal/k8s/v1.29.3/bin/linux/amd64/kubectl"},
+		{url: "https://example.com/tool.tar.gz", expected: "https://example.com/tool.tar.gz"},
+	}
+	for _, tt := range tests {
+		if got := manager.mirrorURL(tt.url); got != tt.expected {
+			t.Errorf("Expected %s to be rewritten to %s but got %s", tt.url, tt.expected, got)
+		}
+	}
+}
+
+func TestMirrorValidation(t *testing.T) {
+	tests := []struct {
+		name      string
+		mirror    string
+		expectErr string
+	}{
+		{name: "neither", mirror: `{replace: "https://mirror/"}`, expectErr: "either 'prefix' or 'match'"},
+		{name: "both", mirror: `{prefix: "https://a/", match: "a", replace: "https://mirror/"}`, expectErr: "either 'prefix' or 'match'"},
+		{name: "no replacement", mirror: `{prefix: "https://a/"}`, expectErr: "missing required field 'replace'"},
+		{name: "bad pattern", mirror: `{match: "(", replace: "https://mirror/"}`, expectErr: "invalid match"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app-dependencies.yml")
+			content := "version: \"1.0\"\nmirrors:\n  - " + tt.mirror + "\ndependencies: []\n"
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := LoadDependencyConfig(path)
+			if err == nil || !strings.Contains(err.Error(), tt.expectErr) || !strings.Contains(err.Error(), "mirrors[0]") {
+				t.Errorf("Expected error containing %q but got %v", tt.expectErr, err)
+			}
+
+			policy := filepath.Join(t.TempDir(), "policy.yml")
+			if err := os.WriteFile(policy, []byte("mirrors:\n  - "+tt.mirror+"\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := LoadOrgPolicy(policy); err == nil || !strings.Contains(err.Error(), tt.expectErr) {
+				t.Errorf("Expected the policy file to be rejected with %q but got %v", tt.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestArchiveInstallerMirror(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Write([]byte("#!/bin/sh\necho tool 1.2.3\n"))
+	}))
+	defer server.Close()
+
+	manager := &Manager{
+		Platform: "linux",
+		homeDir:  t.TempDir(),
+		logger:   &mockLogger{},
+		Config:   &DependencyConfig{Mirrors: []Mirror{{Prefix: "https://github.com/", Replace: server.URL + "/github/"}}},
+	}
+	dep := &Dependency{Name: "tool", Version: Version{Required: "1.2.3"}}
+	platformConfig := &PlatformConfig{Installer: Installer{Method: "archive", URL: "https://github.com/acme/tool/releases/download/v{{version}}/tool"}}
+
+	artifact, err := (archiveInstaller{}).install(context.Background(), manager, dep, platformConfig)
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if len(paths) != 1 || paths[0] != "/github/acme/tool/releases/download/v1.2.3/tool" {
+		t.Errorf("Expected the download to come from the mirror but got %v", paths)
+	}
+	if artifact.URL != "https://github.com/acme/tool/releases/download/v1.2.3/tool" {
+		t.Errorf("Expected the lockfile to record the original URL but got %s", artifact.URL)
+	}
+}
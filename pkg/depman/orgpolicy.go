@@ -0,0 +1,167 @@
+package depman
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// OrgPolicy restricts how dependencies may be installed on a machine. Unlike
+// the configuration's license Policy it is kept outside the project, so a
+// project cannot loosen it.
+type OrgPolicy struct {
+	AllowedMethods    []string `yaml:"allowed_methods"`    // Install methods that may be used; if set, all others are refused
+	DeniedMethods     []string `yaml:"denied_methods"`     // Install methods that may never be used, e.g. "script"
+	AllowedHosts      []string `yaml:"allowed_hosts"`      // Hosts artifacts may be downloaded from; "*.example.com" matches subdomains
+	RequireChecksums  bool     `yaml:"require_checksums"`  // Refuse downloads without a checksum or checksum_url
+	RequireSignatures bool     `yaml:"require_signatures"` // Refuse downloads without a signature_url
+	Mirrors           []Mirror `yaml:"mirrors"`            // Rewrite rules for artifact URLs, applied before the configuration's
+}
+
+// systemPolicyPath is the machine-wide policy file loaded when no other is given
+var systemPolicyPath = func() string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("ProgramData"), "depman", "policy.yml")
+	}
+	return "/etc/depman/policy.yml"
+}
+
+// WithOrgPolicy enforces a policy instead of the machine-wide policy file
+func WithOrgPolicy(policy OrgPolicy) Option {
+	return func(m *Manager) {
+		m.orgPolicy = &policy
+	}
+}
+
+// WithOrgPolicyFile enforces the policy in a file instead of the machine-wide
+// policy file. NewManager fails if the file cannot be read.
+func WithOrgPolicyFile(path string) Option {
+	return func(m *Manager) {
+		m.orgPolicyPath = path
+	}
+}
+
+// LoadOrgPolicy reads a policy file. Unknown keys are errors so a misspelled
+// rule is not silently ignored.
+func LoadOrgPolicy(path string) (*OrgPolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy file: %w", err)
+	}
+
+	var policy OrgPolicy
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&policy); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid policy file %s: %w", path, err)
+	}
+	for i, mirror := range policy.Mirrors {
+		if err := mirror.validate(); err != nil {
+			return nil, fmt.Errorf("invalid policy file %s: mirrors[%d]: %w", path, i, err)
+		}
+	}
+	return &policy, nil
+}
+
+// loadOrgPolicy loads the policy file set with WithOrgPolicyFile, or the
+// machine-wide one if it exists, unless WithOrgPolicy set a policy
+func (m *Manager) loadOrgPolicy() error {
+	if m.orgPolicy != nil {
+		return nil
+	}
+
+	path := m.orgPolicyPath
+	if path == "" {
+		path = systemPolicyPath()
+		if _, err := os.Stat(path); err != nil {
+			return nil
+		}
+	}
+
+	policy, err := LoadOrgPolicy(path)
+	if err != nil {
+		return err
+	}
+	m.orgPolicy = policy
+	return nil
+}
+
+// PolicyError reports an install the organization policy does not permit
+type PolicyError struct {
+	Dependency string // Name of the dependency
+	Rule       string // Rule that refused the install, e.g. "allowed_hosts"
+	Detail     string // What the rule refused
+}
+
+func (e *PolicyError) Error() string {
+	return fmt.Sprintf("policy forbids installing %s: %s (%s)", e.Dependency, e.Detail, e.Rule)
+}
+
+// checkMethod returns a *PolicyError if the policy does not permit an install method
+func (p *OrgPolicy) checkMethod(dep *Dependency, method string) error {
+	if p == nil {
+		return nil
+	}
+	if containsFold(p.DeniedMethods, method) {
+		return &PolicyError{Dependency: dep.Name, Rule: "denied_methods", Detail: fmt.Sprintf("install method '%s' is denied", method)}
+	}
+	if len(p.AllowedMethods) > 0 && !containsFold(p.AllowedMethods, method) {
+		return &PolicyError{Dependency: dep.Name, Rule: "allowed_methods", Detail: fmt.Sprintf("install method '%s' is not allowed", method)}
+	}
+	return nil
+}
+
+// checkHost returns a *PolicyError if a download URL's host is not allowed.
+// URLs without a host, such as local paths, are not downloads and pass.
+func (p *OrgPolicy) checkHost(dep *Dependency, rawURL string) error {
+	if p == nil || len(p.AllowedHosts) == 0 || rawURL == "" {
+		return nil
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return &PolicyError{Dependency: dep.Name, Rule: "allowed_hosts", Detail: fmt.Sprintf("cannot parse URL %s", redactURL(rawURL))}
+	}
+	host := strings.ToLower(parsed.Hostname())
+	if host == "" {
+		return nil
+	}
+	for _, allowed := range p.AllowedHosts {
+		allowed = strings.ToLower(allowed)
+		if host == allowed {
+			return nil
+		}
+		if suffix, ok := strings.CutPrefix(allowed, "*"); ok && strings.HasPrefix(suffix, ".") && strings.HasSuffix(host, suffix) {
+			return nil
+		}
+	}
+	return &PolicyError{Dependency: dep.Name, Rule: "allowed_hosts", Detail: fmt.Sprintf("host %s is not allowed", host)}
+}
+
+// checkArtifact returns a *PolicyError if the policy does not permit
+// downloading an artifact: its host and those of its checksums file and
+// signature must be allowed, and it must have what verification requires
+func (p *OrgPolicy) checkArtifact(dep *Dependency, installer Installer, url, checksumURL, signatureURL, checksum string) error {
+	if p == nil {
+		return nil
+	}
+	for _, u := range []string{url, checksumURL, signatureURL} {
+		if err := p.checkHost(dep, u); err != nil {
+			return err
+		}
+	}
+	if p.RequireChecksums && checksum == "" && installer.ChecksumURL == "" {
+		return &PolicyError{Dependency: dep.Name, Rule: "require_checksums", Detail: fmt.Sprintf("%s has no checksum", redactURL(url))}
+	}
+	if p.RequireSignatures && installer.SignatureURL == "" {
+		return &PolicyError{Dependency: dep.Name, Rule: "require_signatures", Detail: fmt.Sprintf("%s has no signature", redactURL(url))}
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package depman
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadOrgPolicy(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "policy.yml")
+	os.WriteFile(path, []byte("denied_methods: [script]\nallowed_hosts: [\"*.example.com\"]\nrequire_checksums: true\n"), 0644)
+
+	policy, err := LoadOrgPolicy(path)
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if len(policy.DeniedMethods) != 1 || !policy.RequireChecksums || policy.RequireSignatures {
+		t.Errorf("Unexpected policy: %+v", policy)
+	}
+
+	// Misspelled rules must not be ignored
+	os.WriteFile(path, []byte("require_checksum: true\n"), 0644)
+	if _, err := LoadOrgPolicy(path); err == nil {
+		t.Error("Expected an error for an unknown rule but got none")
+	}
+
+	configPath := filepath.Join(dir, "deps.yml")
+	os.WriteFile(configPath, []byte("version: \"1.0\"\n"), 0644)
+	if _, err := NewManager(configPath, WithOrgPolicyFile(filepath.Join(dir, "missing.yml")), WithLogOutput(io.Discard)); err == nil {
+		t.Error("Expected an error for a missing policy file but got none")
+	}
+}
+
+func TestOrgPolicyCheckMethod(t *testing.T) {
+	dep := &Dependency{Name: "tool"}
+	testCases := []struct {
+		name        string
+		policy      *OrgPolicy
+		method      string
+		expectError bool
+	}{
+		{name: "No policy", policy: nil, method: "script"},
+		{name: "Denied method", policy: &OrgPolicy{DeniedMethods: []string{"script"}}, method: "script", expectError: true},
+		{name: "Other method", policy: &OrgPolicy{DeniedMethods: []string{"script"}}, method: "archive"},
+		{name: "Allowed method", policy: &OrgPolicy{AllowedMethods: []string{"brew", "Archive"}}, method: "archive"},
+		{name: "Method not allowed", policy: &OrgPolicy{AllowedMethods: []string{"brew"}}, method: "archive", expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.policy.checkMethod(dep, tc.method)
+			if tc.expectError != (err != nil) {
+				t.Errorf("Expected error: %v, got: %v", tc.expectError, err)
+			}
+		})
+	}
+}
+
+func TestOrgPolicyCheckHost(t *testing.T) {
+	dep := &Dependency{Name: "tool"}
+	policy := &OrgPolicy{AllowedHosts: []string{"github.com", "*.example.com"}}
+	testCases := []struct {
+		url         string
+		expectError bool
+	}{
+		{url: "https://github.com/owner/repo/releases/download/v1/tool.tar.gz"},
+		{url: "https://GitHub.com:443/tool.tar.gz"},
+		{url: "https://downloads.example.com/tool.zip"},
+		{url: "https://example.com/tool.zip", expectError: true},
+		{url: "https://evilexample.com/tool.zip", expectError: true},
+		{url: "https://mirror.example.org/tool.zip", expectError: true},
+		{url: "/srv/mirror/tool.zip"},
+	}
+
+	for _, tc := range testCases {
+		err := policy.checkHost(dep, tc.url)
+		if tc.expectError != (err != nil) {
+			t.Errorf("%s: expected error: %v, got: %v", tc.url, tc.expectError, err)
+		}
+	}
+}
+
+func TestDownloadArtifactOrgPolicy(t *testing.T) {
+	manager := &Manager{Platform: "linux", homeDir: t.TempDir(), logger: &mockLogger{}}
+	dep := &Dependency{Name: "tool", Version: Version{Required: "1.0.0"}}
+	platformConfig := &PlatformConfig{Installer: Installer{Method: "archive"}}
+	url := "https://downloads.example.com/tool.tar.gz"
+
+	testCases := []struct {
+		name   string
+		policy OrgPolicy
+		rule   string
+	}{
+		{name: "Checksum required", policy: OrgPolicy{RequireChecksums: true}, rule: "require_checksums"},
+		{name: "Signature required", policy: OrgPolicy{RequireSignatures: true}, rule: "require_signatures"},
+		{name: "Host not allowed", policy: OrgPolicy{AllowedHosts: []string{"github.com"}}, rule: "allowed_hosts"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			WithOrgPolicy(tc.policy)(manager)
+			_, err := manager.downloadArtifact(context.Background(), dep, platformConfig, url, "", t.TempDir())
+			var perr *PolicyError
+			if !errors.As(err, &perr) || perr.Rule != tc.rule {
+				t.Errorf("Expected a %s policy error but got: %v", tc.rule, err)
+			}
+		})
+	}
+}
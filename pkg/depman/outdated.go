@@ -0,0 +1,78 @@
+package depman
+
+import (
+	"context"
+	"fmt"
+)
+
+// OutdatedDependency compares the installed version of a dependency with the
+// versions its source offers
+type OutdatedDependency struct {
+	Name     string `json:"name"`            // Name of the dependency
+	Current  string `json:"current"`         // Installed version (empty if not installed)
+	Wanted   string `json:"wanted"`          // Newest available version the configuration allows
+	Latest   string `json:"latest"`          // Newest available version
+	Outdated bool   `json:"outdated"`        // Whether the dependency is missing or older than Latest
+	Error    string `json:"error,omitempty"` // Why the available versions could not be listed
+}
+
+// Outdated compares every dependency's installed version against the newest
+// versions available from its source. Dependencies whose install method
+// cannot list versions are reported with an Error and are not counted as
+// outdated.
+func (m *Manager) Outdated(ctx context.Context) ([]*OutdatedDependency, error) {
+	if err := m.validateConfiguration(); err != nil {
+		return nil, fmt.Errorf("invalid dependency configuration: %w", err)
+	}
+
+	statuses, err := m.CheckAllDependencies(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deps, err := m.SelectedDependencies()
+	if err != nil {
+		return nil, err
+	}
+	var report []*OutdatedDependency
+	for _, dep := range deps {
+		status := statuses[dep.Name]
+
+		entry := &OutdatedDependency{Name: dep.Name}
+		if status.Installed {
+			entry.Current = status.CurrentVersion
+		}
+		report = append(report, entry)
+
+		versions, err := m.sourceVersions(ctx, dep)
+		if err == nil {
+			entry.Latest, err = newestVersion(versions, "")
+		}
+		if err == nil {
+			entry.Wanted, err = wantedVersion(dep, versions)
+		}
+		if err != nil {
+			entry.Error = err.Error()
+			continue
+		}
+
+		entry.Outdated = entry.Current == "" || newerVersion(entry.Latest, entry.Current)
+	}
+
+	return report, nil
+}
+
+// wantedVersion returns the version the configuration asks for: the newest
+// one satisfying the constraint, else the pinned version, else the newest
+func wantedVersion(dep *Dependency, versions []string) (string, error) {
+	if dep.Version.Constraint == "" && dep.Version.Required != "" {
+		return dep.Version.Required, nil
+	}
+	return newestVersion(versions, dep.Version.Constraint)
+}
+
+// newerVersion reports whether version a is newer than version b
+func newerVersion(a, b string) bool {
+	updateType, err := CheckVersionUpdate(b, a)
+	return err == nil && updateType != NoUpdate
+}
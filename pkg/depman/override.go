@@ -0,0 +1,169 @@
+package depman
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// OverrideFileName is the per-developer file next to the configuration that
+// pins or unpins versions locally. It is meant to be kept out of version
+// control, so the shared configuration stays untouched.
+const OverrideFileName = "depman.override.yaml"
+
+// pinSource names pins set with WithVersionPins in overrides
+const pinSource = "--pin"
+
+// VersionOverride is a local change to the version a dependency requires
+type VersionOverride struct {
+	Dependency string `json:"dependency"`       // Name of the dependency
+	Pinned     string `json:"pinned,omitempty"` // Exact version required instead, or "" if unpinned
+	Configured string `json:"configured"`       // Version or constraint the configuration asks for
+	Source     string `json:"source"`           // Override file or "--pin" flag it came from
+}
+
+// String describes the override, e.g. "pinned to 1.2.3 instead of ^1.2"
+func (o *VersionOverride) String() string {
+	if o.Pinned == "" {
+		return fmt.Sprintf("unpinned from %s", o.Configured)
+	}
+	return fmt.Sprintf("pinned to %s instead of %s", o.Pinned, o.Configured)
+}
+
+// overrideFile is the content of the override file
+type overrideFile struct {
+	Pin   map[string]string `yaml:"pin"`   // Exact versions to require, by dependency name
+	Unpin []string          `yaml:"unpin"` // Dependencies whose exact required version is dropped, keeping any constraint
+}
+
+// WithVersionPins pins dependencies to exact versions for this manager only,
+// by name. Pins win over the override file and the configuration.
+func WithVersionPins(pins map[string]string) Option {
+	return func(m *Manager) {
+		m.pins = pins
+	}
+}
+
+// OverridePath returns the override file of the configuration
+func (m *Manager) OverridePath() string {
+	return filepath.Join(m.configDir(), OverrideFileName)
+}
+
+// Overrides returns the local version overrides in effect, in configuration order
+func (m *Manager) Overrides() []VersionOverride {
+	var overrides []VersionOverride
+	for _, dep := range m.Config.Dependencies {
+		if override, ok := m.overrides[dep.Name]; ok {
+			overrides = append(overrides, *override)
+		}
+	}
+	return overrides
+}
+
+// override returns the local version override of a dependency, or nil
+func (m *Manager) override(name string) *VersionOverride {
+	return m.overrides[name]
+}
+
+// loadOverrideFile reads an override file, which may not exist. Unknown keys
+// are errors so a misspelled pin is not silently ignored.
+func loadOverrideFile(path string) (*overrideFile, error) {
+	file := &overrideFile{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return file, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read overrides: %w", err)
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid override file %s: %w", path, err)
+	}
+	return file, nil
+}
+
+// applyOverrides changes the versions of the configuration's dependencies as
+// the override file and WithVersionPins say, recording each change
+func (m *Manager) applyOverrides() error {
+	file := &overrideFile{}
+	if m.ConfigPath != "" {
+		var err error
+		if file, err = loadOverrideFile(m.OverridePath()); err != nil {
+			return err
+		}
+	}
+	if len(file.Pin) == 0 && len(file.Unpin) == 0 && len(m.pins) == 0 {
+		return nil
+	}
+	if m.frozen {
+		return fmt.Errorf("local version overrides (%s or --pin) cannot be used with a frozen lockfile", OverrideFileName)
+	}
+
+	// Unpins first, so pins of the same dependency win
+	changes := make(map[string]VersionOverride)
+	var names []string
+	change := func(name, version, source string) {
+		if _, ok := changes[name]; !ok {
+			names = append(names, name)
+		}
+		changes[name] = VersionOverride{Dependency: name, Pinned: strings.TrimSpace(version), Source: source}
+	}
+	for _, name := range file.Unpin {
+		change(name, "", m.OverridePath())
+	}
+	for _, name := range sortedKeys(file.Pin) {
+		if strings.TrimSpace(file.Pin[name]) == "" {
+			return fmt.Errorf("invalid override file %s: pin of '%s' has no version (list it under unpin instead)", m.OverridePath(), name)
+		}
+		change(name, file.Pin[name], m.OverridePath())
+	}
+	for _, name := range sortedKeys(m.pins) {
+		if strings.TrimSpace(m.pins[name]) == "" {
+			return fmt.Errorf("--pin %s has no version", name)
+		}
+		change(name, m.pins[name], pinSource)
+	}
+
+	// Work on a copy, so a configuration passed to WithConfig is left alone
+	config := *m.Config
+	config.Dependencies = slices.Clone(m.Config.Dependencies)
+	m.Config = &config
+
+	m.overrides = make(map[string]*VersionOverride, len(changes))
+	for _, name := range names {
+		dep := m.FindDependency(name)
+		override := changes[name]
+		if dep == nil {
+			return fmt.Errorf("%s overrides unknown dependency '%s'", override.Source, name)
+		}
+		override.Configured = dep.Version.Target()
+		if override.Pinned == "" {
+			dep.Version = Version{Constraint: dep.Version.Constraint}
+		} else {
+			dep.Version = Version{Required: override.Pinned}
+		}
+		m.overrides[name] = &override
+		m.logger.Infof("Dependency %s is %s (local override from %s)", name, override.String(), override.Source)
+	}
+	return nil
+}
+
+// sortedKeys returns the keys of a map in order
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,92 @@
+package depman
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVersionOverrides(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "deps.yml")
+	os.WriteFile(configPath, []byte(`version: "1.0"
+dependencies:
+  - name: node
+    version:
+      required: "20.11.0"
+    platforms:
+      linux:
+        installer:
+          method: command
+  - name: go
+    version:
+      required: "1.22.0"
+      constraint: ">=1.21"
+    platforms:
+      linux:
+        installer:
+          method: command
+  - name: jq
+    version:
+      constraint: "^1.6"
+    platforms:
+      linux:
+        installer:
+          method: command
+`), 0644)
+	os.WriteFile(filepath.Join(dir, OverrideFileName), []byte(`pin:
+  node: "18.19.0"
+  jq: "1.7.1"
+unpin:
+  - go
+  - jq
+`), 0644)
+
+	newManager := func(opts ...Option) (*Manager, error) {
+		return NewManager(configPath, append([]Option{WithPlatform("linux/amd64"), WithHomeDir(filepath.Join(dir, "home")), WithSettings(Settings{}), WithLogOutput(io.Discard)}, opts...)...)
+	}
+
+	manager, err := newManager(WithVersionPins(map[string]string{"node": "21.0.0"}))
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	expected := map[string]Version{
+		"node": {Required: "21.0.0"},   // --pin wins over the file
+		"go":   {Constraint: ">=1.21"}, // Unpinned, keeping the constraint
+		"jq":   {Required: "1.7.1"},    // A pin wins over an unpin
+	}
+	for name, version := range expected {
+		if got := manager.FindDependency(name).Version; got != version {
+			t.Errorf("Expected %s to require %+v but got %+v", name, version, got)
+		}
+	}
+
+	overrides := manager.Overrides()
+	if len(overrides) != 3 || overrides[0].Dependency != "node" || overrides[0].Source != "--pin" || overrides[0].Configured != "20.11.0" {
+		t.Fatalf("Unexpected overrides: %+v", overrides)
+	}
+	if got := overrides[1].String(); got != "unpinned from 1.22.0" {
+		t.Errorf("Expected the go override to read 'unpinned from 1.22.0' but got %q", got)
+	}
+	if got := overrides[2].String(); got != "pinned to 1.7.1 instead of ^1.6" {
+		t.Errorf("Expected the jq override to read 'pinned to 1.7.1 instead of ^1.6' but got %q", got)
+	}
+
+	// The shared configuration is left as it is
+	config, err := LoadDependencyConfig(configPath)
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if config.Dependencies[0].Version.Required != "20.11.0" {
+		t.Errorf("Expected the configuration file to be unchanged but got %+v", config.Dependencies[0].Version)
+	}
+
+	if _, err := newManager(WithVersionPins(map[string]string{"python": "3.12"})); err == nil || !strings.Contains(err.Error(), "unknown dependency 'python'") {
+		t.Errorf("Expected pinning an unknown dependency to fail but got: %v", err)
+	}
+	if _, err := newManager(WithFrozenLockfile(true)); err == nil {
+		t.Errorf("Expected overrides to be rejected with a frozen lockfile")
+	}
+}
@@ -0,0 +1,130 @@
+package depman
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// HomeEnvVar names the environment variable that relocates every file depman
+// manages: install state and history, the download cache, the bin and tools
+// directories, install logs, plugins and registries. It wins over home_dir and
+// cache_dir in the settings file, so each CI job or client can get its own.
+const HomeEnvVar = "DEPMAN_HOME"
+
+// envHomeDir returns the absolute directory HomeEnvVar names, or ""
+func envHomeDir() string {
+	dir := os.Getenv(HomeEnvVar)
+	if dir == "" {
+		return ""
+	}
+	dir = expandSettingsPath(dir)
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	return dir
+}
+
+// defaultHomeDir returns the default root directory for files managed by
+// depman: $DEPMAN_HOME, else home_dir of the settings file, else ~/.depman
+func defaultHomeDir() string {
+	if dir := envHomeDir(); dir != "" {
+		return dir
+	}
+	if dir := userSettings().HomeDir; dir != "" {
+		return expandSettingsPath(dir)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = os.TempDir()
+	}
+	return filepath.Join(home, ".depman")
+}
+
+// WithHomeDir sets the root directory for binaries and other files managed by depman
+func WithHomeDir(dir string) Option {
+	return func(m *Manager) {
+		m.homeDir = dir
+	}
+}
+
+// HomeDir returns the root directory for files managed by depman in the
+// current scope: the project directory for project-scoped installs
+func (m *Manager) HomeDir() string {
+	if m.Scope() == ScopeProject {
+		return m.ProjectDir()
+	}
+	return m.globalHomeDir()
+}
+
+// BinDir returns the managed directory holding shims for download-based installs
+func (m *Manager) BinDir() string {
+	if m.binDir != "" {
+		return m.binDir
+	}
+	return filepath.Join(m.HomeDir(), "bin")
+}
+
+// PluginDir returns the directory holding installed install-method plugins
+func (m *Manager) PluginDir() string {
+	return filepath.Join(m.globalHomeDir(), "plugins")
+}
+
+// CacheDir returns the directory holding downloaded artifacts shared by every
+// scope and project
+func (m *Manager) CacheDir() string {
+	if m.cacheDir != "" {
+		return m.cacheDir
+	}
+	return filepath.Join(m.globalHomeDir(), "cache")
+}
+
+// DefaultHomeDir returns the user-level root directory for files managed by depman
+func DefaultHomeDir() string {
+	return defaultHomeDir()
+}
+
+// DefaultCacheDir returns the cache directory used when no home directory is
+// configured: <$DEPMAN_HOME>/cache, else cache_dir of the settings file, else
+// <home>/cache
+func DefaultCacheDir() string {
+	if dir := userSettings().CacheDir; dir != "" && envHomeDir() == "" {
+		return expandSettingsPath(dir)
+	}
+	return filepath.Join(defaultHomeDir(), "cache")
+}
+
+// DefaultStatePath returns the install state file used when no home directory is configured
+func DefaultStatePath() string {
+	return filepath.Join(defaultHomeDir(), stateFileName)
+}
+
+// DefaultPluginDir returns the plugin directory used when no home directory is configured
+func DefaultPluginDir() string {
+	return filepath.Join(defaultHomeDir(), "plugins")
+}
+
+// resolveExecutable prefers a binary in the managed bin directories over PATH
+// lookup for bare command names, so freshly installed tools verify before PATH
+// is updated. Project-scoped binaries win over global ones.
+func (m *Manager) resolveExecutable(name string) string {
+	if strings.ContainsAny(name, `/\`) {
+		return name
+	}
+
+	var candidates []string
+	for _, dir := range m.binDirs() {
+		if m.Platform == "windows" && filepath.Ext(name) == "" {
+			candidates = append(candidates, filepath.Join(dir, name+".cmd"), filepath.Join(dir, name+".exe"))
+		}
+		candidates = append(candidates, filepath.Join(dir, name))
+	}
+
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate
+		}
+	}
+
+	return name
+}
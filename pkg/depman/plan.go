@@ -0,0 +1,148 @@
+package depman
+
+import (
+	"context"
+	"fmt"
+)
+
+// Action describes what ensure will do with a single dependency
+type Action int
+
+const (
+	ActionSkip Action = iota
+	ActionInstall
+	ActionUpgrade
+)
+
+func (a Action) String() string {
+	return [...]string{"skip", "install", "upgrade"}[a]
+}
+
+// MarshalText encodes the action by name for machine-readable output
+func (a Action) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// PlannedAction is a single step of an ensure plan
+type PlannedAction struct {
+	Name           string            `json:"name"`            // Name of the dependency
+	Action         Action            `json:"action"`          // What will be done
+	CurrentVersion string            `json:"current_version"` // Version currently installed, if any
+	TargetVersion  string            `json:"target_version"`  // Version that will be installed
+	Method         string            `json:"method"`          // Install method that will be used
+	Source         string            `json:"source"`          // Where the dependency will be installed from
+	Reason         string            `json:"reason"`          // Why this action was chosen
+	Status         *DependencyStatus `json:"-"`               // Status the decision was based on
+}
+
+// Plan lists the actions ensure would take, in install order
+type Plan struct {
+	Actions []*PlannedAction `json:"actions"`
+}
+
+// Statuses returns the dependency statuses the plan was based on, keyed by name
+func (p *Plan) Statuses() map[string]*DependencyStatus {
+	statuses := make(map[string]*DependencyStatus, len(p.Actions))
+	for _, action := range p.Actions {
+		statuses[action.Name] = action.Status
+	}
+	return statuses
+}
+
+// Pending returns the actions that would change the system
+func (p *Plan) Pending() []*PlannedAction {
+	var pending []*PlannedAction
+	for _, action := range p.Actions {
+		if action.Action != ActionSkip {
+			pending = append(pending, action)
+		}
+	}
+	return pending
+}
+
+// PlanEnsure runs the full check and resolution pipeline of EnsureDependencies
+// and returns what it would install, upgrade or skip without executing any installer
+func (m *Manager) PlanEnsure(ctx context.Context) (*Plan, error) {
+	// First check if dependencies are properly configured
+	if err := m.validateConfiguration(); err != nil {
+		return nil, fmt.Errorf("invalid dependency configuration: %w", err)
+	}
+
+	// Offline, every artifact comes from the bundle
+	if err := m.loadBundle(); err != nil {
+		return nil, err
+	}
+
+	// In frozen mode the lockfile must exist and match the configuration
+	if m.frozen {
+		lock, err := LoadLockfile(m.LockfilePath())
+		if err != nil {
+			return nil, err
+		}
+		if err := m.verifyLockfile(lock); err != nil {
+			return nil, err
+		}
+		m.lock = lock
+	}
+
+	// Check current status of all dependencies
+	statuses, err := m.CheckAllDependencies(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deps, err := m.SelectedDependencies()
+	if err != nil {
+		return nil, err
+	}
+	plan := &Plan{}
+	for _, dep := range deps {
+		status := statuses[dep.Name]
+
+		action := &PlannedAction{
+			Name:           dep.Name,
+			CurrentVersion: status.CurrentVersion,
+			TargetVersion:  dep.Version.Target(),
+			Status:         status,
+		}
+		action.Action, action.Reason = planAction(status)
+
+		if platformConfig, err := m.resolvedPlatformConfig(dep); err == nil {
+			action.Method = installMethod(platformConfig)
+			action.Source = redactURL(platformConfig.Installer.URL)
+		}
+
+		plan.Actions = append(plan.Actions, action)
+	}
+
+	// Of alternatives that provide the same name, one is enough
+	m.preferAlternatives(plan)
+
+	return plan, nil
+}
+
+// verifyLockfile checks the lockfile for the selected dependencies, with
+// what they require, so entries the selection leaves out may be stale
+func (m *Manager) verifyLockfile(lock *Lockfile) error {
+	deps, err := m.SelectedDependencies()
+	if err != nil {
+		return err
+	}
+	return lock.verify(m.Config, deps, m.lockPlatform)
+}
+
+// planAction decides what to do with a dependency based on its current status
+func planAction(status *DependencyStatus) (Action, string) {
+	switch {
+	case status.ProvidedBy != "":
+		return ActionSkip, fmt.Sprintf("provided by %s", status.ProvidedBy)
+	case !status.Installed:
+		return ActionInstall, "not installed"
+	case !status.Compatible:
+		return ActionUpgrade, fmt.Sprintf("version %s is incompatible", status.CurrentVersion)
+	case status.RequiredUpdate != NoUpdate:
+		return ActionUpgrade, fmt.Sprintf("%s required", status.RequiredUpdate)
+	default:
+		return ActionSkip, "already satisfied"
+	}
+}
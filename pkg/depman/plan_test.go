@@ -0,0 +1,60 @@
+package depman
+
+import "testing"
+
+func TestPlanAction(t *testing.T) {
+	testCases := []struct {
+		name     string
+		status   *DependencyStatus
+		expected Action
+	}{
+		{
+			name:     "Not installed",
+			status:   &DependencyStatus{Installed: false},
+			expected: ActionInstall,
+		},
+		{
+			name:     "Incompatible version",
+			status:   &DependencyStatus{Installed: true, Compatible: false, CurrentVersion: "2.0.0"},
+			expected: ActionUpgrade,
+		},
+		{
+			name:     "Update required",
+			status:   &DependencyStatus{Installed: true, Compatible: true, RequiredUpdate: MinorUpdate},
+			expected: ActionUpgrade,
+		},
+		{
+			name:     "Already satisfied",
+			status:   &DependencyStatus{Installed: true, Compatible: true, RequiredUpdate: NoUpdate},
+			expected: ActionSkip,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			action, reason := planAction(tc.status)
+			if action != tc.expected {
+				t.Errorf("Expected action %s but got %s", tc.expected, action)
+			}
+			if reason == "" {
+				t.Errorf("Expected a reason for action %s", action)
+			}
+		})
+	}
+}
+
+func TestPlanPending(t *testing.T) {
+	plan := &Plan{Actions: []*PlannedAction{
+		{Name: "a", Action: ActionSkip, Status: &DependencyStatus{Name: "a"}},
+		{Name: "b", Action: ActionInstall, Status: &DependencyStatus{Name: "b"}},
+		{Name: "c", Action: ActionUpgrade, Status: &DependencyStatus{Name: "c"}},
+	}}
+
+	if pending := plan.Pending(); len(pending) != 2 {
+		t.Errorf("Expected 2 pending actions but got %d", len(pending))
+	}
+
+	if statuses := plan.Statuses(); len(statuses) != 3 || statuses["b"].Name != "b" {
+		t.Errorf("Expected statuses keyed by name but got %v", statuses)
+	}
+}
@@ -0,0 +1,69 @@
+package depman
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+)
+
+// PrivilegePolicy controls how depman gains root privileges for system-wide installs
+type PrivilegePolicy string
+
+const (
+	PrivilegeSudo   PrivilegePolicy = "sudo"   // Prefix commands with sudo (default)
+	PrivilegeDoas   PrivilegePolicy = "doas"   // Prefix commands with doas
+	PrivilegeFail   PrivilegePolicy = "fail"   // Refuse to run commands that need root
+	PrivilegePrompt PrivilegePolicy = "prompt" // Ask before running commands with sudo
+)
+
+// ParsePrivilegePolicy parses a privilege policy name
+func ParsePrivilegePolicy(name string) (PrivilegePolicy, error) {
+	switch policy := PrivilegePolicy(strings.ToLower(name)); policy {
+	case PrivilegeSudo, PrivilegeDoas, PrivilegeFail, PrivilegePrompt:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("invalid privilege policy '%s' (expected sudo, doas, fail or prompt)", name)
+	}
+}
+
+// WithPrivilegePolicy sets how depman gains root privileges for system package managers
+func WithPrivilegePolicy(policy PrivilegePolicy) Option {
+	return func(m *Manager) {
+		m.privilege = policy
+	}
+}
+
+// isRoot reports whether the current process already runs with root privileges; replaced in tests
+var isRoot = func() bool {
+	return runtime.GOOS != "windows" && os.Geteuid() == 0
+}
+
+// elevate returns the command line to run a command with root privileges according to the policy
+func (m *Manager) elevate(command []string) ([]string, error) {
+	if isRoot() {
+		return command, nil
+	}
+
+	policy := m.privilege
+	if policy == "" {
+		policy = PrivilegeSudo
+	}
+
+	switch policy {
+	case PrivilegeDoas:
+		return append([]string{"doas"}, command...), nil
+	case PrivilegeFail:
+		return nil, fmt.Errorf("root privileges are required to run '%s'", strings.Join(command, " "))
+	case PrivilegePrompt:
+		confirmed, err := m.confirm(fmt.Sprintf("Run '%s' with sudo?", strings.Join(command, " ")))
+		if err != nil {
+			return nil, fmt.Errorf("cannot ask to run '%s' with root privileges: %w", strings.Join(command, " "), err)
+		}
+		if !confirmed {
+			return nil, fmt.Errorf("declined to run '%s' with root privileges", strings.Join(command, " "))
+		}
+	}
+
+	return append([]string{"sudo"}, command...), nil
+}
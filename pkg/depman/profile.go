@@ -0,0 +1,91 @@
+package depman
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Profile adjusts the configuration for one environment, such as ci or prod
+type Profile struct {
+	Dependencies []Dependency `yaml:"dependencies"` // Dependencies added, or merged into those of the same name
+	Remove       []string     `yaml:"remove"`       // Dependencies left out
+}
+
+// WithProfile applies a named profile from the configuration's profiles
+func WithProfile(name string) Option {
+	return func(m *Manager) {
+		m.profile = name
+	}
+}
+
+// Profile returns the name of the applied profile, or "" if none is
+func (m *Manager) Profile() string {
+	return m.profile
+}
+
+// applyProfile returns the configuration with a profile applied: its removed
+// dependencies are dropped, then its dependencies are merged in by name
+func applyProfile(config *DependencyConfig, name string) (*DependencyConfig, error) {
+	profile, ok := config.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown profile '%s' (defined: %s)", name, profileNames(config))
+	}
+
+	applied := *config
+	applied.Dependencies = nil
+
+	remove := make(map[string]bool, len(profile.Remove))
+	for _, removed := range profile.Remove {
+		remove[removed] = true
+	}
+	for _, dep := range config.Dependencies {
+		if remove[dep.Name] {
+			delete(remove, dep.Name)
+			continue
+		}
+		applied.Dependencies = append(applied.Dependencies, dep)
+	}
+	for _, removed := range profile.Remove {
+		if !remove[removed] {
+			continue
+		}
+		return nil, fmt.Errorf("profile '%s' removes unknown dependency '%s'", name, removed)
+	}
+
+	applied.Dependencies = mergeDependencies(applied.Dependencies, profile.Dependencies)
+	return &applied, nil
+}
+
+// profileNames lists the profiles a configuration defines
+func profileNames(config *DependencyConfig) string {
+	if len(config.Profiles) == 0 {
+		return "none"
+	}
+	names := make([]string, 0, len(config.Profiles))
+	for name := range config.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// mergeProfiles merges overlay profiles into base profiles of the same name:
+// their dependencies are merged by name and their removals are combined
+func mergeProfiles(base, overlay map[string]Profile) map[string]Profile {
+	if len(overlay) == 0 {
+		return base
+	}
+	merged := make(map[string]Profile, len(base)+len(overlay))
+	for name, profile := range base {
+		merged[name] = profile
+	}
+	for name, profile := range overlay {
+		existing := merged[name]
+		merged[name] = Profile{
+			Dependencies: mergeDependencies(existing.Dependencies, profile.Dependencies),
+			Remove:       append(append([]string(nil), existing.Remove...), profile.Remove...),
+		}
+	}
+	return merged
+}
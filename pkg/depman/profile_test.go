@@ -0,0 +1,91 @@
+package depman
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProfiles(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "depman-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	configPath := filepath.Join(tempDir, "app-dependencies.yml")
+	config := `
+dependencies:
+  - name: "go"
+    version:
+      required: "1.22.0"
+    platforms:
+      linux:
+        installer: {method: "system"}
+  - name: "mkdocs"
+    version:
+      required: "1.5.0"
+    platforms:
+      linux:
+        installer: {method: "system"}
+profiles:
+  ci:
+    remove: ["mkdocs"]
+    dependencies:
+      - name: "go"
+        version:
+          required: "1.21.5"
+      - name: "golangci-lint"
+        version:
+          required: "1.59.0"
+        platforms:
+          linux:
+            installer: {method: "system"}
+  broken:
+    remove: ["missing"]
+`
+	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	t.Run("Profile adds, removes and overrides", func(t *testing.T) {
+		manager, err := NewManager(configPath, WithHomeDir(tempDir), WithLogOutput(io.Discard), WithProfile("ci"))
+		if err != nil {
+			t.Fatalf("Failed to create manager: %v", err)
+		}
+
+		if manager.FindDependency("mkdocs") != nil {
+			t.Errorf("Expected mkdocs to be removed")
+		}
+		if dep := manager.FindDependency("go"); dep == nil || dep.Version.Required != "1.21.5" {
+			t.Errorf("Expected go to be overridden to 1.21.5 but got %+v", dep)
+		} else if _, ok := dep.Platforms["linux"]; !ok {
+			t.Errorf("Expected the override to keep the platforms of go")
+		}
+		if manager.FindDependency("golangci-lint") == nil {
+			t.Errorf("Expected golangci-lint to be added")
+		}
+	})
+
+	t.Run("No profile", func(t *testing.T) {
+		manager, err := NewManager(configPath, WithHomeDir(tempDir), WithLogOutput(io.Discard))
+		if err != nil {
+			t.Fatalf("Failed to create manager: %v", err)
+		}
+		if len(manager.Config.Dependencies) != 2 {
+			t.Errorf("Expected 2 dependencies but got %d", len(manager.Config.Dependencies))
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		for _, name := range []string{"unknown", "broken"} {
+			if _, err := NewManager(configPath, WithHomeDir(tempDir), WithLogOutput(io.Discard), WithProfile(name)); err == nil {
+				t.Errorf("Expected an error for profile %s but got none", name)
+			}
+		}
+		if err := ValidateConfigFile(configPath); err == nil {
+			t.Errorf("Expected validation to reject the broken profile")
+		}
+	})
+}
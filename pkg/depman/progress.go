@@ -0,0 +1,69 @@
+package depman
+
+import (
+	"context"
+	"time"
+
+	"github.com/devnadeemashraf/depman/internal/telemetry"
+)
+
+// Step identifies a phase of work on a dependency reported to a ProgressReporter
+type Step string
+
+const (
+	StepCheck    Step = "check"    // Detecting the installed version
+	StepDownload Step = "download" // Downloading an installer artifact
+	StepInstall  Step = "install"  // Running the install method
+	StepVerify   Step = "verify"   // Checking the installed version afterwards
+)
+
+// ProgressReporter receives events while dependencies are checked and
+// installed. Dependencies are processed in parallel, so implementations must
+// be safe for concurrent use.
+type ProgressReporter interface {
+	// OnStepStart is called when a step begins for a dependency
+	OnStepStart(dependency string, step Step)
+
+	// OnStepEnd is called when a step finishes, with its error if it failed
+	OnStepEnd(dependency string, step Step, err error)
+
+	// OnDownloadProgress is called as an artifact downloads; total is -1 when unknown
+	OnDownloadProgress(dependency string, downloaded, total int64)
+}
+
+// WithProgress sets a reporter that receives progress events during check,
+// ensure and install
+func WithProgress(reporter ProgressReporter) Option {
+	return func(m *Manager) {
+		m.progress = reporter
+	}
+}
+
+// noProgress discards progress events
+type noProgress struct{}
+
+func (noProgress) OnStepStart(string, Step)                {}
+func (noProgress) OnStepEnd(string, Step, error)           {}
+func (noProgress) OnDownloadProgress(string, int64, int64) {}
+
+// reporter returns the configured progress reporter, or one that discards events
+func (m *Manager) reporter() ProgressReporter {
+	if m.progress == nil {
+		return noProgress{}
+	}
+	return m.progress
+}
+
+// step reports a step around fn, tracing it in a span whose context fn
+// receives, and returns fn's error
+func (m *Manager) step(ctx context.Context, dep *Dependency, step Step, fn func(ctx context.Context) error) error {
+	ctx, span := m.telemetry.Start(ctx, "depman.step."+string(step),
+		telemetry.String("depman.dependency", dep.Name), telemetry.String("depman.step", string(step)))
+	m.reporter().OnStepStart(dep.Name, step)
+	start := time.Now()
+	err := fn(ctx)
+	m.recordStepTime(dep.Name, time.Since(start))
+	m.reporter().OnStepEnd(dep.Name, step, err)
+	span.End(err)
+	return err
+}
@@ -0,0 +1,79 @@
+package depman
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recordingReporter records progress events as strings
+type recordingReporter struct {
+	mu         sync.Mutex
+	events     []string
+	downloaded int64
+	total      int64
+}
+
+func (r *recordingReporter) OnStepStart(dependency string, step Step) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, "start "+dependency+" "+string(step))
+}
+
+func (r *recordingReporter) OnStepEnd(dependency string, step Step, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result := "ok"
+	if err != nil {
+		result = "failed"
+	}
+	r.events = append(r.events, "end "+dependency+" "+string(step)+" "+result)
+}
+
+func (r *recordingReporter) OnDownloadProgress(dependency string, downloaded, total int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.downloaded, r.total = downloaded, total
+}
+
+func TestDownloadProgress(t *testing.T) {
+	payload := strings.Repeat("x", 100000)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(payload)))
+		w.Write([]byte(payload))
+	}))
+	defer server.Close()
+
+	tempDir, err := os.MkdirTemp("", "depman-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	reporter := &recordingReporter{}
+	manager := &Manager{logger: &mockLogger{}, homeDir: tempDir, progress: reporter}
+	dep := &Dependency{Name: "tool"}
+
+	if _, err := manager.downloadArtifact(context.Background(), dep, &PlatformConfig{}, server.URL+"/tool", "", tempDir); err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if reporter.downloaded != int64(len(payload)) || reporter.total != int64(len(payload)) {
+		t.Errorf("Expected final progress %d/%d but got %d/%d", len(payload), len(payload), reporter.downloaded, reporter.total)
+	}
+
+	manager.downloadArtifact(context.Background(), dep, &PlatformConfig{}, server.URL+"/missing", "", tempDir)
+
+	expected := "start tool download,end tool download ok,start tool download,end tool download failed"
+	if got := strings.Join(reporter.events, ","); got != expected {
+		t.Errorf("Expected events %s but got %s", expected, got)
+	}
+}
@@ -0,0 +1,193 @@
+package depman
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// ConflictError reports an install refused because it conflicts with a
+// dependency that is installed or about to be
+type ConflictError struct {
+	Dependency string // Dependency that was about to be installed
+	Conflict   string // Dependency it conflicts with
+	Installed  bool   // Whether Conflict is installed, rather than also about to be
+}
+
+func (e *ConflictError) Error() string {
+	if e.Installed {
+		return fmt.Sprintf("cannot install %s: it conflicts with %s, which is installed", e.Dependency, e.Conflict)
+	}
+	return fmt.Sprintf("cannot install %s: it conflicts with %s, which is also to be installed", e.Dependency, e.Conflict)
+}
+
+// providers returns the dependencies that satisfy name in configuration
+// order: the dependency called name and those that provide it
+func (m *Manager) providers(name string) []*Dependency {
+	var deps []*Dependency
+	for i := range m.Config.Dependencies {
+		dep := &m.Config.Dependencies[i]
+		if dep.Name == name || slices.Contains(dep.Provides, name) {
+			deps = append(deps, dep)
+		}
+	}
+	return deps
+}
+
+// resolveReference returns the dependency a name in a dependencies list
+// refers to: the dependency called name, or else the first that provides it
+func (m *Manager) resolveReference(name string) *Dependency {
+	if dep := m.FindDependency(name); dep != nil {
+		return dep
+	}
+	if providers := m.providers(name); len(providers) > 0 {
+		return providers[0]
+	}
+	return nil
+}
+
+// prerequisites returns the names of the dependencies in a run that dep
+// must wait for, with provided names expanded to every dependency that
+// provides them
+func (m *Manager) prerequisites(dep *Dependency, run map[string]chan struct{}) []string {
+	var names []string
+	for _, name := range dep.Dependencies {
+		if _, ok := run[name]; ok {
+			names = append(names, name)
+			continue
+		}
+		for _, provider := range m.providers(name) {
+			names = append(names, provider.Name)
+		}
+	}
+	return names
+}
+
+// alternatives groups the named dependencies that satisfy the same name,
+// each group in configuration order. Dependencies outside names are ignored.
+func (m *Manager) alternatives(names map[string]bool) [][]*Dependency {
+	var groups [][]*Dependency
+	seen := make(map[string]bool)
+	for _, dep := range m.Config.Dependencies {
+		for _, name := range append([]string{dep.Name}, dep.Provides...) {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+
+			var group []*Dependency
+			for _, provider := range m.providers(name) {
+				if names[provider.Name] {
+					group = append(group, provider)
+				}
+			}
+			if len(group) > 1 {
+				groups = append(groups, group)
+			}
+		}
+	}
+	return groups
+}
+
+// applyProviders marks dependencies satisfied by an alternative: once one
+// dependency providing a name is installed and up to date, the others
+// providing the same name need not be
+func (m *Manager) applyProviders(statuses map[string]*DependencyStatus) {
+	names := make(map[string]bool, len(statuses))
+	for name := range statuses {
+		names[name] = true
+	}
+	for _, group := range m.alternatives(names) {
+		var provider string
+		for _, dep := range group {
+			if status := statuses[dep.Name]; status.ProvidedBy == "" && status.Problem() == ProblemNone {
+				provider = dep.Name
+				break
+			}
+		}
+		if provider == "" {
+			continue
+		}
+		for _, dep := range group {
+			if status := statuses[dep.Name]; dep.Name != provider && status.ProvidedBy == "" && status.Problem() != ProblemNone {
+				status.ProvidedBy = provider
+			}
+		}
+	}
+}
+
+// preferAlternatives plans to install only one of a group of alternatives
+// that none satisfies: the first already installed, or else the first configured
+func (m *Manager) preferAlternatives(plan *Plan) {
+	actions := make(map[string]*PlannedAction, len(plan.Actions))
+	names := make(map[string]bool, len(plan.Actions))
+	for _, action := range plan.Actions {
+		actions[action.Name] = action
+		names[action.Name] = true
+	}
+
+	for _, group := range m.alternatives(names) {
+		if slices.ContainsFunc(group, func(dep *Dependency) bool { return actions[dep.Name].Action == ActionSkip }) {
+			continue
+		}
+		preferred := group[0]
+		for _, dep := range group {
+			if actions[dep.Name].Status.Installed {
+				preferred = dep
+				break
+			}
+		}
+		for _, dep := range group {
+			if dep != preferred {
+				actions[dep.Name].Action = ActionSkip
+				actions[dep.Name].Reason = fmt.Sprintf("alternative %s is installed instead", preferred.Name)
+			}
+		}
+	}
+}
+
+// conflicts reports whether either dependency declares it conflicts with the other
+func conflicts(a, b *Dependency) bool {
+	return a.Name != b.Name && (slices.Contains(a.ConflictsWith, b.Name) || slices.Contains(b.ConflictsWith, a.Name))
+}
+
+// conflictWithin refuses to install dependencies that conflict with each other
+func conflictWithin(installing []*Dependency) error {
+	for i, dep := range installing {
+		for _, other := range installing[i+1:] {
+			if conflicts(dep, other) {
+				return &ConflictError{Dependency: other.Name, Conflict: dep.Name}
+			}
+		}
+	}
+	return nil
+}
+
+// checkConflicts refuses to install dependencies that conflict with an
+// installed dependency or with each other. statuses holds known statuses;
+// other conflicting dependencies are checked.
+func (m *Manager) checkConflicts(ctx context.Context, installing []*Dependency, statuses map[string]*DependencyStatus) error {
+	if err := conflictWithin(installing); err != nil {
+		return err
+	}
+
+	for _, dep := range installing {
+		for i := range m.Config.Dependencies {
+			other := &m.Config.Dependencies[i]
+			if !conflicts(dep, other) {
+				continue
+			}
+			status := statuses[other.Name]
+			if status == nil {
+				var err error
+				if status, err = m.CheckDependency(ctx, other); err != nil && status == nil {
+					return fmt.Errorf("failed to check %s, which conflicts with %s: %w", other.Name, dep.Name, err)
+				}
+			}
+			if status.Installed {
+				return &ConflictError{Dependency: dep.Name, Conflict: other.Name, Installed: true}
+			}
+		}
+	}
+	return nil
+}
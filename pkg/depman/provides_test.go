@@ -0,0 +1,143 @@
+package depman
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// containerRuntimes configures Docker Desktop and colima as alternatives for docker
+func containerRuntimes() *Manager {
+	desktop := NewDependency("docker", "*")
+	desktop.ConflictsWith = []string{"colima"}
+	colima := NewDependency("colima", "*")
+	colima.Provides = []string{"docker"}
+	app := NewDependency("app", "*").DependsOn("docker")
+	return &Manager{Config: NewConfig("Containers", desktop, colima, app)}
+}
+
+func TestApplyProviders(t *testing.T) {
+	m := containerRuntimes()
+	statuses := map[string]*DependencyStatus{
+		"docker": {Name: "docker"},
+		"colima": {Name: "colima", Installed: true, Compatible: true},
+		"app":    {Name: "app", Installed: true, Compatible: true},
+	}
+
+	m.applyProviders(statuses)
+
+	if statuses["docker"].ProvidedBy != "colima" || statuses["docker"].Problem() != ProblemNone {
+		t.Errorf("Expected docker to be provided by colima but got %+v", statuses["docker"])
+	}
+	if statuses["colima"].ProvidedBy != "" {
+		t.Errorf("Expected colima to satisfy itself but got %+v", statuses["colima"])
+	}
+	if action, _ := planAction(statuses["docker"]); action != ActionSkip {
+		t.Errorf("Expected docker to be skipped but got %s", action)
+	}
+}
+
+func TestPreferAlternatives(t *testing.T) {
+	testCases := []struct {
+		name      string
+		installed string
+		expected  string
+	}{
+		{name: "First configured", expected: "docker"},
+		{name: "Installed alternative", installed: "colima", expected: "colima"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := containerRuntimes()
+			plan := &Plan{}
+			for _, name := range []string{"docker", "colima"} {
+				status := &DependencyStatus{Name: name, Installed: name == tc.installed}
+				action := &PlannedAction{Name: name, Status: status}
+				action.Action, action.Reason = planAction(status)
+				plan.Actions = append(plan.Actions, action)
+			}
+
+			m.preferAlternatives(plan)
+
+			pending := plan.Pending()
+			if len(pending) != 1 || pending[0].Name != tc.expected {
+				t.Errorf("Expected only %s to be installed but got %+v", tc.expected, pending)
+			}
+		})
+	}
+}
+
+func TestCheckConflicts(t *testing.T) {
+	m := containerRuntimes()
+	docker, colima := m.FindDependency("docker"), m.FindDependency("colima")
+
+	statuses := map[string]*DependencyStatus{"colima": {Name: "colima", Installed: true}}
+	err := m.checkConflicts(context.Background(), []*Dependency{docker}, statuses)
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) || conflict.Conflict != "colima" || !conflict.Installed {
+		t.Errorf("Expected docker to conflict with the installed colima but got %v", err)
+	}
+
+	// Conflicts are declared once but apply both ways
+	err = m.checkConflicts(context.Background(), []*Dependency{docker, colima}, nil)
+	if !errors.As(err, &conflict) || conflict.Installed {
+		t.Errorf("Expected docker and colima to conflict with each other but got %v", err)
+	}
+
+	statuses = map[string]*DependencyStatus{"colima": {Name: "colima"}}
+	if err := m.checkConflicts(context.Background(), []*Dependency{docker}, statuses); err != nil {
+		t.Errorf("Did not expect an error but got: %v", err)
+	}
+}
+
+func TestResolveProvidedName(t *testing.T) {
+	m := containerRuntimes()
+	m.Config.Add(NewDependency("compose", "*").DependsOn("container-runtime"))
+	m.Config.Dependencies[1].Provides = append(m.Config.Dependencies[1].Provides, "container-runtime")
+
+	order, err := m.resolveInstallOrder([]string{"compose", "colima"})
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	var names []string
+	for _, dep := range order {
+		names = append(names, dep.Name)
+	}
+	if strings.Join(names, ",") != "colima,compose" {
+		t.Errorf("Expected colima to stand for container-runtime but got %v", names)
+	}
+}
+
+func TestLockedAlternative(t *testing.T) {
+	m := containerRuntimes()
+	m.Platform = "linux"
+	lock := &Lockfile{}
+	for _, name := range []string{"colima", "app"} {
+		dep := m.FindDependency(name)
+		lock.Dependencies = append(lock.Dependencies, LockedDependency{
+			Name:      name,
+			Digest:    dependencyDigest(dep),
+			Platforms: map[string]LockedArtifact{"linux": {}},
+		})
+	}
+
+	if err := m.verifyLockfile(lock); err != nil {
+		t.Errorf("Expected docker to be satisfied by the locked colima but got: %v", err)
+	}
+}
+
+func TestValidateProvidesAndConflicts(t *testing.T) {
+	dep := NewDependency("docker", "*").DependsOn("colima")
+	dep.Provides = []string{"docker"}
+	dep.ConflictsWith = []string{"colima"}
+	config := NewConfig("Containers", dep, NewDependency("colima", "*"))
+
+	err := config.Validate()
+	for _, expected := range []string{"cannot list itself", "conflicts with 'colima', which it depends on"} {
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected a validation error containing %q but got %v", expected, err)
+		}
+	}
+}
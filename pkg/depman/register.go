@@ -0,0 +1,125 @@
+package depman
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// InstallStrategy installs dependencies using an install method added by a
+// Go program embedding depman; see RegisterInstaller. Dependencies select it
+// by setting installer.method to the name it was registered under.
+type InstallStrategy interface {
+	// Install installs the dependency and returns the artifact it used, if
+	// any, to record in the lockfile
+	Install(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) (LockedArtifact, error)
+
+	// Uninstall removes a dependency previously installed by the strategy,
+	// or returns an error if it cannot
+	Uninstall(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) error
+}
+
+// Checker is implemented by install strategies that can report the installed
+// version themselves, for dependencies without a verify command
+type Checker interface {
+	// InstalledVersion returns the installed version, or an error if the
+	// dependency is not installed
+	InstalledVersion(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) (string, error)
+}
+
+// Resolver is implemented by install strategies that can list the versions
+// their source offers, for 'depman outdated' and update planning
+type Resolver interface {
+	// AvailableVersions returns the versions that could be installed, in any order
+	AvailableVersions(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) ([]string, error)
+}
+
+// strategiesMu guards installStrategies against registrations racing with
+// installs. Built-in strategies are added by init functions, before any
+// registration or install can run.
+var strategiesMu sync.RWMutex
+
+// RegisterInstaller makes an install strategy available under the install
+// method name, at compile time and without the external plugin machinery.
+// If the strategy also implements Checker or Resolver, depman uses it to
+// read installed versions and list available ones. It is meant to be called
+// from an init function, and panics if name is empty, strategy is nil, or
+// the method is already built in or registered.
+func RegisterInstaller(name string, strategy InstallStrategy) {
+	name = strings.ToLower(name)
+	if name == "" || strategy == nil {
+		panic("depman: RegisterInstaller needs a name and a strategy")
+	}
+
+	strategiesMu.Lock()
+	defer strategiesMu.Unlock()
+	if _, exists := installStrategies[name]; exists {
+		panic(fmt.Sprintf("depman: install method %q is already registered", name))
+	}
+	installStrategies[name] = adaptStrategy(strategy)
+}
+
+// adaptStrategy wraps a registered strategy as an installStrategy that has
+// exactly the optional capabilities the strategy implements, so checks such
+// as strategy.(versionLister) stay truthful
+func adaptStrategy(strategy InstallStrategy) installStrategy {
+	base := registeredStrategy{strategy}
+	checker, canCheck := strategy.(Checker)
+	resolver, canResolve := strategy.(Resolver)
+	switch {
+	case canCheck && canResolve:
+		return struct {
+			registeredStrategy
+			registeredChecker
+			registeredResolver
+		}{base, registeredChecker{checker}, registeredResolver{resolver}}
+	case canCheck:
+		return struct {
+			registeredStrategy
+			registeredChecker
+		}{base, registeredChecker{checker}}
+	case canResolve:
+		return struct {
+			registeredStrategy
+			registeredResolver
+		}{base, registeredResolver{resolver}}
+	default:
+		return base
+	}
+}
+
+// registeredStrategy adapts a registered InstallStrategy
+type registeredStrategy struct {
+	strategy InstallStrategy
+}
+
+// install runs the strategy's Install
+func (r registeredStrategy) install(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) (LockedArtifact, error) {
+	return r.strategy.Install(ctx, m, dep, platformConfig)
+}
+
+// uninstall runs the strategy's Uninstall
+func (r registeredStrategy) uninstall(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) error {
+	return r.strategy.Uninstall(ctx, m, dep, platformConfig)
+}
+
+// registeredChecker adapts the Checker of a registered strategy
+type registeredChecker struct {
+	checker Checker
+}
+
+// detectVersion runs the strategy's InstalledVersion
+func (r registeredChecker) detectVersion(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) (string, error) {
+	return r.checker.InstalledVersion(ctx, m, dep, platformConfig)
+}
+
+// registeredResolver adapts the Resolver of a registered strategy
+type registeredResolver struct {
+	resolver Resolver
+}
+
+// availableVersions runs the strategy's AvailableVersions
+func (r registeredResolver) availableVersions(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) ([]string, error) {
+	return r.resolver.AvailableVersions(ctx, m, dep, platformConfig)
+}
@@ -0,0 +1,83 @@
+package depman
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakeStrategy is an install strategy an embedder might register
+type fakeStrategy struct {
+	installed *string
+}
+
+func (f fakeStrategy) Install(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) (LockedArtifact, error) {
+	*f.installed = dep.Version.Required
+	return LockedArtifact{URL: "fake://" + dep.Name}, nil
+}
+
+func (f fakeStrategy) Uninstall(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) error {
+	*f.installed = ""
+	return nil
+}
+
+func (f fakeStrategy) InstalledVersion(ctx context.Context, m *Manager, dep *Dependency, platformConfig *PlatformConfig) (string, error) {
+	if *f.installed == "" {
+		return "", os.ErrNotExist
+	}
+	return *f.installed, nil
+}
+
+func TestRegisterInstaller(t *testing.T) {
+	installed := ""
+	RegisterInstaller("Test-Fake", fakeStrategy{installed: &installed})
+	defer func() {
+		strategiesMu.Lock()
+		delete(installStrategies, "test-fake")
+		strategiesMu.Unlock()
+	}()
+
+	// Only the capabilities the strategy implements are advertised
+	strategy := installStrategies["test-fake"]
+	if _, ok := strategy.(versionDetector); !ok {
+		t.Errorf("Expected the strategy to detect versions")
+	}
+	if _, ok := strategy.(versionLister); ok {
+		t.Errorf("Did not expect the strategy to list versions")
+	}
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "deps.yml")
+	os.WriteFile(configPath, []byte(`version: "1.0"
+dependencies:
+  - name: tool
+    version:
+      required: "2.1.0"
+    platforms:
+      linux:
+        installer:
+          method: test-fake
+`), 0644)
+
+	manager, err := NewManager(configPath, WithPlatform("linux/amd64"), WithHomeDir(filepath.Join(dir, "home")), WithSettings(Settings{}), WithLogOutput(io.Discard))
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	result, err := manager.Ensure(context.Background())
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	status := result.Status("tool")
+	if installed != "2.1.0" || status == nil || status.CurrentVersion != "2.1.0" || status.Change != ChangeInstalled {
+		t.Errorf("Expected tool 2.1.0 to be installed by the registered strategy but got %+v", status)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected registering a method twice to panic")
+		}
+	}()
+	RegisterInstaller("test-fake", fakeStrategy{installed: &installed})
+}
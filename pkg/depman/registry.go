@@ -0,0 +1,533 @@
+package depman
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/devnadeemashraf/depman/internal/logger"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed registry.yml
+var builtinRegistry []byte
+
+// DefaultRegistryURL is where the public registry is fetched from
+const DefaultRegistryURL = "https://raw.githubusercontent.com/devnadeemashraf/depman/main/pkg/depman/registry.yml"
+
+const (
+	registryFormat     = 1                // Newest registry file format this depman reads
+	registriesFileName = "registries.yml" // Configured registries under the depman home directory
+	registryCacheDir   = "registries"     // Fetched registries under the depman home directory, one file each
+	publicRegistry     = "depman"         // Registry depman ships with, fetched from DefaultRegistryURL
+	anyRegistry        = "registry"       // Registry name in from references meaning whichever registry has the recipe
+)
+
+// registryName matches the names registries can be configured under
+var registryName = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+// Registry holds install recipes for well-known tools. A configuration uses
+// a recipe with "from: registry/<name>" and only pins a version.
+type Registry struct {
+	Version int          `yaml:"version"` // Format version of the registry file
+	Recipes []Dependency `yaml:"recipes"` // Recipes, each a dependency definition whose version is the default constraint
+
+	origins map[string]string    // Registry each recipe was taken from, by recipe name
+	sources map[string]*Registry // Recipes of each registry, by registry name
+	order   []string             // Names of the registries in sources, highest priority first
+}
+
+// RecipeMatch is a recipe found by Search and the registry it is in
+type RecipeMatch struct {
+	Registry string     // Name of the registry
+	Recipe   Dependency // The recipe
+	Shadowed bool       // Whether a registry with a higher priority has a recipe of the same name
+}
+
+// From returns the from reference that uses the recipe: "registry/<name>",
+// or "<registry>/<name>" for a shadowed recipe
+func (m RecipeMatch) From() string {
+	if m.Shadowed {
+		return m.Registry + "/" + m.Recipe.Name
+	}
+	return anyRegistry + "/" + m.Recipe.Name
+}
+
+// RegistrySource is a registry of recipes and where it is fetched from
+type RegistrySource struct {
+	Name       string      `yaml:"name"`                 // Name used in from references, e.g. "acme" for "from: acme/<tool>"
+	URL        string      `yaml:"url"`                  // http(s) URL, git::<repo>//<file>?ref=<ref> or oci:// reference of the registry file
+	Priority   int         `yaml:"priority"`             // Registries with a higher priority win when several have a recipe; the public one has 0
+	Credential *Credential `yaml:"credential,omitempty"` // How to authenticate to the URL's host beyond DEPMAN_AUTH_* variables and netrc
+}
+
+// RegistryStatus describes a configured registry and its fetched copy
+type RegistryStatus struct {
+	RegistrySource
+	Recipes int       // Recipes of the registry; the built-in ones for the public registry until it is fetched
+	Updated time.Time // When the registry was last fetched; zero if it never was
+}
+
+// registriesFile is the file listing the configured registries
+type registriesFile struct {
+	Registries []RegistrySource `yaml:"registries"`
+}
+
+// ParseRegistry parses and validates a registry file
+func ParseRegistry(data []byte) (*Registry, error) {
+	var registry Registry
+	if err := yaml.Unmarshal(data, &registry); err != nil {
+		return nil, fmt.Errorf("failed to parse registry: %w", err)
+	}
+	if registry.Version < 1 || registry.Version > registryFormat {
+		return nil, fmt.Errorf("registry format %d is not supported by this depman (expected 1 to %d); upgrade with 'depman self-update'",
+			registry.Version, registryFormat)
+	}
+
+	// Recipes follow the rules of configuration files
+	config := NewConfig("registry", registry.Recipes...)
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid registry: %w", err)
+	}
+	for _, recipe := range registry.Recipes {
+		if recipe.From != "" {
+			return nil, fmt.Errorf("invalid registry: recipe '%s' cannot use from", recipe.Name)
+		}
+	}
+	return &registry, nil
+}
+
+// RegistrySources returns the configured registries, highest priority first.
+// The public registry is included unless a registry of its name replaces it,
+// e.g. to use a mirror.
+func RegistrySources(homeDir string) ([]RegistrySource, error) {
+	file, err := readRegistriesFile(homeDir)
+	if err != nil {
+		return nil, err
+	}
+	sources := file.Registries
+
+	// Registries of the settings file come after those added with 'depman
+	// registry add', which win over them by name
+	settings, err := LoadSettings(SettingsPath())
+	if err != nil {
+		return nil, err
+	}
+	for _, source := range settings.Registries {
+		if containsSource(sources, source.Name) {
+			continue
+		}
+		if source.Credential != nil && source.Credential.Host == "" {
+			cred := *source.Credential
+			cred.Host = registryHost(source.URL)
+			source.Credential = &cred
+		}
+		sources = append(sources, source)
+	}
+
+	if !containsSource(sources, publicRegistry) {
+		sources = append(sources, RegistrySource{Name: publicRegistry, URL: DefaultRegistryURL})
+	}
+	sort.SliceStable(sources, func(i, j int) bool {
+		return sources[i].Priority > sources[j].Priority
+	})
+	return sources, nil
+}
+
+// AddRegistrySource configures an additional registry. Its recipes are
+// available once it is fetched with UpdateRegistry.
+func AddRegistrySource(homeDir string, source RegistrySource) error {
+	if !registryName.MatchString(source.Name) || source.Name == anyRegistry {
+		return fmt.Errorf("invalid registry name '%s' (expected lowercase letters, digits, '.', '_' and '-', other than '%s')", source.Name, anyRegistry)
+	}
+	if !IsRemoteConfig(source.URL) {
+		return fmt.Errorf("invalid registry URL '%s' (expected https://..., git::<repo>//<file>?ref=... or oci://...)", source.URL)
+	}
+
+	file, err := readRegistriesFile(homeDir)
+	if err != nil {
+		return err
+	}
+	if containsSource(file.Registries, source.Name) {
+		return fmt.Errorf("registry '%s' is already configured; remove it first to change it", source.Name)
+	}
+	if source.Credential != nil {
+		source.Credential.Host = registryHost(source.URL)
+	}
+	file.Registries = append(file.Registries, source)
+	return writeRegistriesFile(homeDir, file)
+}
+
+// RemoveRegistrySource removes a configured registry and its fetched copy
+func RemoveRegistrySource(homeDir, name string) error {
+	file, err := readRegistriesFile(homeDir)
+	if err != nil {
+		return err
+	}
+	if !containsSource(file.Registries, name) {
+		if settings, err := LoadSettings(SettingsPath()); err == nil && containsSource(settings.Registries, name) {
+			return fmt.Errorf("registry '%s' is configured in %s; remove it with 'depman config set registries'", name, SettingsPath())
+		}
+		return fmt.Errorf("registry '%s' is not configured", name)
+	}
+
+	kept := file.Registries[:0]
+	for _, source := range file.Registries {
+		if source.Name != name {
+			kept = append(kept, source)
+		}
+	}
+	file.Registries = kept
+	if err := writeRegistriesFile(homeDir, file); err != nil {
+		return err
+	}
+	if err := os.Remove(registryCachePath(homeDir, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove fetched registry: %w", err)
+	}
+	return nil
+}
+
+// readRegistriesFile reads the configured registries, which may not exist
+func readRegistriesFile(homeDir string) (*registriesFile, error) {
+	file := &registriesFile{}
+	path := filepath.Join(homeDir, registriesFileName)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return file, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read registries: %w", err)
+	}
+	if err := yaml.Unmarshal(data, file); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return file, nil
+}
+
+// writeRegistriesFile writes the configured registries
+func writeRegistriesFile(homeDir string, file *registriesFile) error {
+	data, err := yaml.Marshal(file)
+	if err != nil {
+		return fmt.Errorf("failed to encode registries: %w", err)
+	}
+	if err := os.MkdirAll(homeDir, 0755); err != nil {
+		return fmt.Errorf("failed to write registries: %w", err)
+	}
+	header := "# Recipe registries used by depman, managed with 'depman registry add' and 'depman registry remove'.\n"
+	if err := os.WriteFile(filepath.Join(homeDir, registriesFileName), append([]byte(header), data...), 0644); err != nil {
+		return fmt.Errorf("failed to write registries: %w", err)
+	}
+	return nil
+}
+
+// containsSource reports whether a registry of the given name is in sources
+func containsSource(sources []RegistrySource, name string) bool {
+	for _, source := range sources {
+		if source.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// registryHost returns the host of an http(s) registry URL, or ""
+func registryHost(rawURL string) string {
+	if u, err := url.Parse(rawURL); err == nil && (u.Scheme == "https" || u.Scheme == "http") {
+		return u.Hostname()
+	}
+	return ""
+}
+
+// registryCachePath returns where the fetched copy of a registry is stored
+func registryCachePath(homeDir, name string) string {
+	return filepath.Join(homeDir, registryCacheDir, name+".yml")
+}
+
+// LoadRegistry returns the recipes of every configured registry that has
+// been fetched, and the built-in recipes of the public registry. When
+// several registries have a recipe, the one with the highest priority wins.
+func LoadRegistry(homeDir string) (*Registry, error) {
+	sources, err := RegistrySources(homeDir)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := &Registry{Version: registryFormat, origins: make(map[string]string), sources: make(map[string]*Registry)}
+	for _, source := range sources {
+		registry, err := loadRegistrySource(homeDir, source.Name)
+		if err != nil {
+			return nil, err
+		}
+		if registry == nil {
+			continue
+		}
+		merged.sources[source.Name] = registry
+		merged.order = append(merged.order, source.Name)
+		for _, recipe := range registry.Recipes {
+			if _, ok := merged.origins[recipe.Name]; !ok {
+				merged.origins[recipe.Name] = source.Name
+				merged.Recipes = append(merged.Recipes, recipe)
+			}
+		}
+	}
+	return merged, nil
+}
+
+// loadRegistrySource returns the fetched copy of a registry, or nil if it has
+// not been fetched. The public registry falls back to the built-in recipes,
+// which also fill in recipes its fetched copy lacks.
+func loadRegistrySource(homeDir, name string) (*Registry, error) {
+	var registry *Registry
+	path := registryCachePath(homeDir, name)
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if registry, err = ParseRegistry(data); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return nil, fmt.Errorf("failed to read registry: %w", err)
+	}
+	if name != publicRegistry {
+		return registry, nil
+	}
+
+	builtin, err := ParseRegistry(builtinRegistry)
+	if err != nil {
+		return nil, fmt.Errorf("built-in registry: %w", err)
+	}
+	if registry == nil {
+		return builtin, nil
+	}
+	for _, recipe := range builtin.Recipes {
+		if _, ok := registry.Recipe(recipe.Name); !ok {
+			registry.Recipes = append(registry.Recipes, recipe)
+		}
+	}
+	return registry, nil
+}
+
+// RegistryStatuses returns the configured registries with what was fetched of each
+func RegistryStatuses(homeDir string) ([]RegistryStatus, error) {
+	sources, err := RegistrySources(homeDir)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]RegistryStatus, 0, len(sources))
+	for _, source := range sources {
+		status := RegistryStatus{RegistrySource: source}
+		registry, err := loadRegistrySource(homeDir, source.Name)
+		if err != nil {
+			return nil, err
+		}
+		if registry != nil {
+			status.Recipes = len(registry.Recipes)
+		}
+		if info, err := os.Stat(registryCachePath(homeDir, source.Name)); err == nil {
+			status.Updated = info.ModTime()
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// UpdateRegistry fetches a configured registry and stores it under homeDir,
+// where LoadRegistry finds it. Registries that do not parse are not stored.
+// Options such as WithHTTPClient and WithCredentials apply to the fetch.
+func UpdateRegistry(ctx context.Context, homeDir, name string, opts ...Option) (*Registry, error) {
+	sources, err := RegistrySources(homeDir)
+	if err != nil {
+		return nil, err
+	}
+	var source *RegistrySource
+	for i := range sources {
+		if sources[i].Name == name {
+			source = &sources[i]
+		}
+	}
+	if source == nil {
+		return nil, fmt.Errorf("registry '%s' is not configured", name)
+	}
+
+	// The fetch authenticates like downloads, with the registry's credential if it has one
+	manager := &Manager{logger: logger.Default().WithOutput(io.Discard), Config: &DependencyConfig{}}
+	for _, opt := range opts {
+		opt(manager)
+	}
+	if source.Credential != nil {
+		manager.Config.Credentials = []Credential{*source.Credential}
+	}
+	data, _, err := manager.fetchConfigData(ctx, source.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch registry %s from %s: %w", name, source.URL, err)
+	}
+	registry, err := ParseRegistry(data)
+	if err != nil {
+		return nil, fmt.Errorf("registry %s from %s: %w", name, source.URL, err)
+	}
+
+	path := registryCachePath(homeDir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to store registry: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to store registry: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return nil, fmt.Errorf("failed to store registry: %w", err)
+	}
+	return registry, nil
+}
+
+// Recipe returns the recipe for a tool
+func (r *Registry) Recipe(name string) (Dependency, bool) {
+	for _, recipe := range r.Recipes {
+		if recipe.Name == name {
+			return recipe, true
+		}
+	}
+	return Dependency{}, false
+}
+
+// Origin returns the registry the recipe for a tool comes from, or ""
+func (r *Registry) Origin(name string) string {
+	return r.origins[name]
+}
+
+// Names returns the names of the tools with a recipe, sorted
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.Recipes))
+	for _, recipe := range r.Recipes {
+		names = append(names, recipe.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Search returns the recipes of every registry whose name, description or
+// tags contain term, ignoring case, sorted by name and then by registry
+// priority. An empty term matches every recipe.
+func (r *Registry) Search(term string) []RecipeMatch {
+	term = strings.ToLower(term)
+	var matches []RecipeMatch
+	for _, source := range r.order {
+		for _, recipe := range r.sources[source].Recipes {
+			if recipeMatches(recipe, term) {
+				matches = append(matches, RecipeMatch{Registry: source, Recipe: recipe, Shadowed: r.origins[recipe.Name] != source})
+			}
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Recipe.Name < matches[j].Recipe.Name })
+	return matches
+}
+
+// recipeMatches reports whether a recipe's name, description or tags contain
+// the lowercase term
+func recipeMatches(recipe Dependency, term string) bool {
+	for _, field := range append([]string{recipe.Name, recipe.Description}, recipe.Tags...) {
+		if strings.Contains(strings.ToLower(field), term) {
+			return true
+		}
+	}
+	return false
+}
+
+// Lookup returns the recipe a from reference names: "registry/<tool>" for
+// the recipe of the registry with the highest priority that has one, or
+// "<registry>/<tool>" for the recipe of a particular registry
+func (r *Registry) Lookup(from string) (Dependency, error) {
+	source, name, err := parseFrom(from)
+	if err != nil {
+		return Dependency{}, err
+	}
+	if source == anyRegistry {
+		if recipe, ok := r.Recipe(name); ok {
+			return recipe, nil
+		}
+		return Dependency{}, fmt.Errorf("no recipe '%s' in the registries (recipes: %s)", name, strings.Join(r.Names(), ", "))
+	}
+
+	registry, ok := r.sources[source]
+	if !ok {
+		names := make([]string, 0, len(r.sources))
+		for name := range r.sources {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return Dependency{}, fmt.Errorf("no registry '%s' has been fetched (registries: %s)", source, strings.Join(names, ", "))
+	}
+	if recipe, ok := registry.Recipe(name); ok {
+		return recipe, nil
+	}
+	return Dependency{}, fmt.Errorf("no recipe '%s' in registry %s (recipes: %s)", name, source, strings.Join(registry.Names(), ", "))
+}
+
+// Dependency returns a dependency using the recipe a reference names, either
+// "<tool>" or "<registry>/<tool>", satisfied by versions matching constraint
+// (the recipe's default if empty)
+func (r *Registry) Dependency(ref, constraint string) (Dependency, bool) {
+	from := ref
+	if !strings.Contains(ref, "/") {
+		from = anyRegistry + "/" + ref
+	}
+	if _, err := r.Lookup(from); err != nil {
+		return Dependency{}, false
+	}
+	_, name, _ := parseFrom(from)
+	dep := fromRegistry(name, constraint)
+	dep.From = from
+	return dep, true
+}
+
+// parseFrom splits a from reference into its registry and tool names
+func parseFrom(from string) (string, string, error) {
+	source, name, ok := strings.Cut(from, "/")
+	if !ok || source == "" || name == "" || strings.Contains(name, "/") {
+		return "", "", fmt.Errorf("invalid from '%s' (expected %s/<name> or <registry>/<name>)", from, anyRegistry)
+	}
+	return source, name, nil
+}
+
+// resolveRecipes returns the configuration with each dependency using a
+// registry recipe merged on top of it, like an overlay of the recipe
+func resolveRecipes(config *DependencyConfig) (*DependencyConfig, error) {
+	var registry *Registry
+	var resolved *DependencyConfig
+	for i, dep := range config.Dependencies {
+		if dep.From == "" {
+			continue
+		}
+		if registry == nil {
+			var err error
+			if registry, err = LoadRegistry(DefaultHomeDir()); err != nil {
+				return nil, err
+			}
+			copied := *config
+			copied.Dependencies = append([]Dependency(nil), config.Dependencies...)
+			resolved = &copied
+		}
+
+		recipe, err := registry.Lookup(dep.From)
+		if err != nil {
+			return nil, fmt.Errorf("dependency '%s': %w", dep.Name, err)
+		}
+		recipe.Name = dep.Name
+		resolved.Dependencies[i] = mergeDependency(recipe, dep)
+	}
+	if resolved == nil {
+		return config, nil
+	}
+	return resolved, nil
+}
@@ -0,0 +1,414 @@
+package depman
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const fetchedRegistry = `version: 1
+recipes:
+  - name: jq
+    description: jq from the fetched registry
+    version:
+      constraint: ">=1.7"
+    platforms:
+      linux:
+        installer: {method: system, package: jq}
+  - name: ripgrep
+    version:
+      constraint: ">=14"
+    platforms:
+      linux:
+        installer: {method: github-release, repo: BurntSushi/ripgrep, binary: rg}
+`
+
+func TestBuiltinRegistry(t *testing.T) {
+	registry, err := LoadRegistry(t.TempDir())
+	if err != nil {
+		t.Fatalf("Expected the built-in registry to load but got %v", err)
+	}
+	for _, name := range []string{"gh", "jq", "kubectl", "node", "protoc", "terraform"} {
+		recipe, ok := registry.Recipe(name)
+		if !ok {
+			t.Errorf("Expected a recipe for %s", name)
+			continue
+		}
+		if len(recipe.Platforms) != 3 {
+			t.Errorf("Expected %s to have installers for 3 platforms but got %d", name, len(recipe.Platforms))
+		}
+	}
+}
+
+func TestParseRegistryErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		expectErr string
+	}{
+		{name: "newer format", data: "version: 2\nrecipes: []\n", expectErr: "registry format 2 is not supported"},
+		{name: "invalid recipe", data: "version: 1\nrecipes:\n  - name: jq\n", expectErr: "version.required"},
+		{name: "recipe with from", data: "version: 1\nrecipes:\n  - name: jq\n    from: registry/yq\n", expectErr: "cannot use from"},
+		{name: "malformed", data: "version: [", expectErr: "failed to parse registry"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseRegistry([]byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.expectErr) {
+				t.Errorf("Expected error containing %q but got %v", tt.expectErr, err)
+			}
+		})
+	}
+}
+
+const acmeRegistry = `version: 1
+recipes:
+  - name: jq
+    description: jq built by acme
+    version:
+      constraint: ">=1.7"
+    platforms:
+      linux:
+        installer: {method: archive, url: "https://tools.acme.internal/jq-{{version}}"}
+  - name: deployer
+    description: Acme deployment tool
+    version:
+      constraint: ">=2"
+    platforms:
+      linux:
+        installer: {method: archive, url: "https://tools.acme.internal/deployer-{{version}}"}
+`
+
+// writeFetchedRegistry stores data as the fetched copy of a registry
+func writeFetchedRegistry(t *testing.T, home, name, data string) {
+	t.Helper()
+	path := registryCachePath(home, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadRegistryPrefersFetched(t *testing.T) {
+	home := t.TempDir()
+	writeFetchedRegistry(t, home, publicRegistry, fetchedRegistry)
+
+	registry, err := LoadRegistry(home)
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	if jq, _ := registry.Recipe("jq"); jq.Description != "jq from the fetched registry" {
+		t.Errorf("Expected the fetched jq recipe but got %q", jq.Description)
+	}
+	if _, ok := registry.Recipe("ripgrep"); !ok {
+		t.Error("Expected the fetched ripgrep recipe")
+	}
+	if _, ok := registry.Recipe("kubectl"); !ok {
+		t.Error("Expected built-in recipes missing from the fetched registry to be kept")
+	}
+}
+
+func TestRegistryPriority(t *testing.T) {
+	tests := []struct {
+		name       string
+		priority   int
+		expectJq   string
+		expectFrom string
+	}{
+		{name: "above the public registry", priority: 10, expectJq: "jq built by acme", expectFrom: "acme"},
+		{name: "below the public registry", priority: -1, expectJq: "Command-line JSON processor", expectFrom: "depman"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			if err := AddRegistrySource(home, RegistrySource{Name: "acme", URL: "https://tools.acme.internal/registry.yml", Priority: tt.priority}); err != nil {
+				t.Fatalf("Expected no error but got %v", err)
+			}
+
+			// Registries are only used once fetched
+			registry, err := LoadRegistry(home)
+			if err != nil {
+				t.Fatalf("Expected no error but got %v", err)
+			}
+			if _, ok := registry.Recipe("deployer"); ok {
+				t.Error("Expected no recipes from a registry that was never fetched")
+			}
+
+			writeFetchedRegistry(t, home, "acme", acmeRegistry)
+			if registry, err = LoadRegistry(home); err != nil {
+				t.Fatalf("Expected no error but got %v", err)
+			}
+			if jq, _ := registry.Recipe("jq"); jq.Description != tt.expectJq {
+				t.Errorf("Expected jq to be %q but got %q", tt.expectJq, jq.Description)
+			}
+			if origin := registry.Origin("jq"); origin != tt.expectFrom {
+				t.Errorf("Expected jq to come from %s but got %s", tt.expectFrom, origin)
+			}
+			if _, ok := registry.Recipe("deployer"); !ok {
+				t.Error("Expected the recipes only acme has")
+			}
+
+			// A pinned registry wins regardless of priority
+			pinned, err := registry.Lookup("acme/jq")
+			if err != nil || pinned.Description != "jq built by acme" {
+				t.Errorf("Expected acme/jq to be acme's recipe but got %q (%v)", pinned.Description, err)
+			}
+			if public, err := registry.Lookup("depman/jq"); err != nil || public.Description != "Command-line JSON processor" {
+				t.Errorf("Expected depman/jq to be the public recipe but got %q (%v)", public.Description, err)
+			}
+		})
+	}
+}
+
+func TestRegistrySources(t *testing.T) {
+	home := t.TempDir()
+	for _, source := range []RegistrySource{
+		{Name: "acme", URL: "https://tools.acme.internal/registry.yml", Priority: 10},
+		{Name: "team", URL: "git::github.com/acme/team//registry.yml?ref=main", Priority: 20},
+	} {
+		if err := AddRegistrySource(home, source); err != nil {
+			t.Fatalf("Expected no error but got %v", err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		source    RegistrySource
+		expectErr string
+	}{
+		{name: "duplicate", source: RegistrySource{Name: "acme", URL: "https://example.com/registry.yml"}, expectErr: "already configured"},
+		{name: "reserved name", source: RegistrySource{Name: "registry", URL: "https://example.com/registry.yml"}, expectErr: "invalid registry name"},
+		{name: "invalid name", source: RegistrySource{Name: "Acme Tools", URL: "https://example.com/registry.yml"}, expectErr: "invalid registry name"},
+		{name: "local path", source: RegistrySource{Name: "local", URL: "/tmp/registry.yml"}, expectErr: "invalid registry URL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := AddRegistrySource(home, tt.source)
+			if err == nil || !strings.Contains(err.Error(), tt.expectErr) {
+				t.Errorf("Expected error containing %q but got %v", tt.expectErr, err)
+			}
+		})
+	}
+
+	sources, err := RegistrySources(home)
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	var names []string
+	for _, source := range sources {
+		names = append(names, source.Name)
+	}
+	if strings.Join(names, ",") != "team,acme,depman" {
+		t.Errorf("Expected registries by priority but got %v", names)
+	}
+
+	writeFetchedRegistry(t, home, "acme", acmeRegistry)
+	if err := RemoveRegistrySource(home, "acme"); err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	if _, err := os.Stat(registryCachePath(home, "acme")); !os.IsNotExist(err) {
+		t.Errorf("Expected the fetched registry to be removed but got %v", err)
+	}
+	if err := RemoveRegistrySource(home, "acme"); err == nil || !strings.Contains(err.Error(), "not configured") {
+		t.Errorf("Expected an error for a registry that is not configured but got %v", err)
+	}
+}
+
+func TestRegistrySearch(t *testing.T) {
+	registry, err := LoadRegistry(t.TempDir())
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	tests := []struct {
+		term   string
+		expect []string
+	}{
+		{term: "kubernetes", expect: []string{"helm", "kubectl"}},
+		{term: "YQ", expect: []string{"yq"}},
+		{term: "javascript", expect: []string{"node", "pnpm", "yarn"}},
+		{term: "nothing-matches", expect: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.term, func(t *testing.T) {
+			var names []string
+			for _, match := range registry.Search(tt.term) {
+				names = append(names, match.Recipe.Name)
+			}
+			if strings.Join(names, ",") != strings.Join(tt.expect, ",") {
+				t.Errorf("Expected %v but got %v", tt.expect, names)
+			}
+		})
+	}
+}
+
+func TestUpdateRegistry(t *testing.T) {
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		if r.URL.Path == "/broken.yml" {
+			w.Write([]byte("version: 9\n"))
+			return
+		}
+		w.Write([]byte(acmeRegistry))
+	}))
+	defer server.Close()
+	t.Setenv("DEPMAN_AUTH_127_0_0_1_TOKEN", "secret")
+
+	home := t.TempDir()
+	for _, source := range []RegistrySource{
+		{Name: "broken", URL: server.URL + "/broken.yml"},
+		{Name: "acme", URL: server.URL + "/registry.yml", Credential: &Credential{Type: "bearer"}},
+	} {
+		if err := AddRegistrySource(home, source); err != nil {
+			t.Fatalf("Expected no error but got %v", err)
+		}
+	}
+
+	if _, err := UpdateRegistry(context.Background(), home, "broken", WithHTTPClient(server.Client())); err == nil {
+		t.Fatal("Expected an error for an unsupported registry")
+	}
+	if _, err := os.Stat(registryCachePath(home, "broken")); !os.IsNotExist(err) {
+		t.Errorf("Expected an invalid registry not to be stored but got %v", err)
+	}
+	if _, err := UpdateRegistry(context.Background(), home, "missing"); err == nil || !strings.Contains(err.Error(), "not configured") {
+		t.Errorf("Expected an error for a registry that is not configured but got %v", err)
+	}
+
+	registry, err := UpdateRegistry(context.Background(), home, "acme", WithHTTPClient(server.Client()))
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	if _, ok := registry.Recipe("deployer"); !ok {
+		t.Error("Expected the updated registry to include deployer")
+	}
+	if authorization != "Bearer secret" {
+		t.Errorf("Expected the registry's credential to be used but got %q", authorization)
+	}
+
+	statuses, err := RegistryStatuses(home)
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	for _, status := range statuses {
+		if status.Name == "acme" && (status.Recipes != 2 || status.Updated.IsZero()) {
+			t.Errorf("Expected acme to be fetched with 2 recipes but got %+v", status)
+		}
+		if status.Name == "broken" && !status.Updated.IsZero() {
+			t.Errorf("Expected broken never to be fetched but got %+v", status)
+		}
+	}
+}
+
+func TestFetchGitRegistry(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	repo := t.TempDir()
+	if err := os.WriteFile(filepath.Join(repo, "registry.yml"), []byte(acmeRegistry), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "registry"},
+	} {
+		if output, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v: %s", args, err, output)
+		}
+	}
+
+	home := t.TempDir()
+	if err := AddRegistrySource(home, RegistrySource{Name: "acme", URL: "git::file://" + repo + "//registry.yml"}); err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	if _, err := UpdateRegistry(context.Background(), home, "acme"); err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	registry, err := LoadRegistry(home)
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	if registry.Origin("deployer") != "acme" {
+		t.Errorf("Expected deployer from acme but got %q", registry.Origin("deployer"))
+	}
+}
+
+func TestDependencyFromRegistry(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+
+	path := filepath.Join(t.TempDir(), "app-dependencies.yml")
+	content := `version: "1.0"
+name: example
+dependencies:
+  - name: kubectl
+    from: registry/kubectl
+    version:
+      required: "1.29.3"
+  - name: yq
+    from: registry/yq
+    platforms:
+      linux:
+        installer: {method: system, package: yq}
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := LoadDependencyConfig(path)
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	kubectl := config.Dependencies[0]
+	if kubectl.Version.Required != "1.29.3" || kubectl.Version.Constraint != "" {
+		t.Errorf("Expected the pinned version to replace the recipe's but got %+v", kubectl.Version)
+	}
+	if kubectl.Platforms["linux"].Installer.ChecksumURL == "" || kubectl.Description != "Kubernetes CLI" {
+		t.Errorf("Expected kubectl to inherit the recipe but got %+v", kubectl)
+	}
+
+	yq := config.Dependencies[1]
+	if yq.Version.Constraint != ">=4.0" {
+		t.Errorf("Expected the recipe's default constraint but got %q", yq.Version.Constraint)
+	}
+	if yq.Platforms["linux"].Installer.Method != "system" || yq.Platforms["darwin"].Installer.Method != "brew" {
+		t.Errorf("Expected the linux installer to be overridden and the others inherited but got %+v", yq.Platforms)
+	}
+}
+
+func TestDependencyFromUnknownRecipe(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+
+	tests := []struct {
+		from      string
+		expectErr string
+	}{
+		{from: "registry/kubctl", expectErr: "no recipe 'kubctl' in the registries"},
+		{from: "depman/kubctl", expectErr: "no recipe 'kubctl' in registry depman"},
+		{from: "acme/kubectl", expectErr: "no registry 'acme' has been fetched"},
+		{from: "kubectl", expectErr: "invalid from 'kubectl'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from, func(t *testing.T) {
+			config := NewConfig("example", Dependency{Name: "kubectl", From: tt.from})
+			err := config.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.expectErr) {
+				t.Errorf("Expected error containing %q but got %v", tt.expectErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,274 @@
+package depman
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/devnadeemashraf/depman/internal/logger"
+	"github.com/devnadeemashraf/depman/internal/oci"
+	"github.com/devnadeemashraf/depman/internal/verify"
+)
+
+// Prefixes of configuration references fetched from a remote source
+const (
+	gitConfigPrefix = "git::"
+	ociConfigPrefix = "oci://"
+)
+
+// remoteConfigDir is the directory under the depman home holding fetched configurations
+const remoteConfigDir = "configs"
+
+// IsRemoteConfig reports whether a configuration path is a remote reference:
+// an http(s) URL, git::<repo>//<file>?ref=<ref> or oci://<registry>/<repo>:<tag>
+func IsRemoteConfig(path string) bool {
+	for _, prefix := range []string{"https://", "http://", gitConfigPrefix, ociConfigPrefix} {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitChecksum removes the checksum=<algorithm:hash> integrity pin from the
+// query of a remote reference
+func splitChecksum(ref string) (string, string, error) {
+	base, rawQuery, ok := strings.Cut(ref, "?")
+	if !ok {
+		return ref, "", nil
+	}
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid query in %s: %w", ref, err)
+	}
+	checksum := query.Get("checksum")
+	if checksum == "" {
+		return ref, "", nil
+	}
+	if _, _, err := verify.ParseChecksum(checksum); err != nil {
+		return "", "", fmt.Errorf("invalid checksum in %s: %w", ref, err)
+	}
+
+	query.Del("checksum")
+	if len(query) > 0 {
+		base += "?" + query.Encode()
+	}
+	return base, checksum, nil
+}
+
+// FetchRemoteConfig fetches a remote configuration into the local cache and
+// returns the path of the cached copy without loading it
+func FetchRemoteConfig(ctx context.Context, ref string, opts ...Option) (string, error) {
+	manager := &Manager{logger: logger.Default().WithOutput(io.Discard)}
+	for _, opt := range opts {
+		opt(manager)
+	}
+	return manager.fetchRemoteConfig(ctx, ref)
+}
+
+// fetchRemoteConfig fetches a remote configuration into the local cache and
+// returns the path of the cached copy. A copy matching the integrity pin is
+// used without fetching; if fetching fails, the last cached copy is used.
+func (m *Manager) fetchRemoteConfig(ctx context.Context, ref string) (string, error) {
+	source, checksum, err := splitChecksum(ref)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256([]byte(source))
+	dir := filepath.Join(m.globalHomeDir(), remoteConfigDir, hex.EncodeToString(sum[:8]))
+	cached := cachedConfig(dir)
+
+	if cached != "" && checksum != "" && verify.Checksum(cached, checksum) == nil {
+		m.logger.Debugf("Using cached configuration %s for %s", cached, source)
+		return cached, nil
+	}
+
+	data, filename, err := m.fetchConfigData(ctx, source)
+	if err == nil {
+		err = verifyConfigData(data, checksum)
+	}
+	if err != nil {
+		if cached != "" && (checksum == "" || verify.Checksum(cached, checksum) == nil) {
+			m.logger.Warnf("Failed to fetch %s, using the cached copy: %v", source, err)
+			return cached, nil
+		}
+		return "", fmt.Errorf("failed to fetch configuration %s: %w", source, err)
+	}
+
+	filename, err = cachedFileName(filename)
+	if err != nil {
+		return "", fmt.Errorf("failed to cache configuration %s: %w", source, err)
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return "", fmt.Errorf("failed to clear cached configuration: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to cache configuration: %w", err)
+	}
+	target := filepath.Join(dir, filename)
+	if err := os.WriteFile(target, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to cache configuration: %w", err)
+	}
+	return target, nil
+}
+
+// cachedFileName returns the name a fetched configuration is cached under.
+// The source picks it, e.g. through an OCI title annotation, so only its
+// last element is kept and it cannot leave the cache directory.
+func cachedFileName(name string) (string, error) {
+	base := filepath.Base(name)
+	switch {
+	case base == "." || base == string(filepath.Separator):
+		// No file name, e.g. a URL path of "/"
+		return configBaseName + ".yml", nil
+	case base == ".." || strings.ContainsAny(base, `/\`):
+		return "", fmt.Errorf("invalid configuration file name %q", name)
+	case filepath.Ext(base) == "":
+		return configBaseName + ".yml", nil
+	}
+	return base, nil
+}
+
+// cachedConfig returns the configuration file cached in dir, or ""
+func cachedConfig(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			return filepath.Join(dir, entry.Name())
+		}
+	}
+	return ""
+}
+
+// verifyConfigData checks fetched data against the integrity pin, if any
+func verifyConfigData(data []byte, checksum string) error {
+	if checksum == "" {
+		return nil
+	}
+	algorithm, expected, err := verify.ParseChecksum(checksum)
+	if err != nil {
+		return err
+	}
+	h, err := verify.NewHash(algorithm)
+	if err != nil {
+		return err
+	}
+	h.Write(data)
+	if actual := hex.EncodeToString(h.Sum(nil)); actual != expected {
+		return &verify.ChecksumError{Algorithm: algorithm, Expected: expected, Actual: actual}
+	}
+	return nil
+}
+
+// fetchConfigData downloads a remote configuration and returns its content and file name
+func (m *Manager) fetchConfigData(ctx context.Context, source string) ([]byte, string, error) {
+	switch {
+	case strings.HasPrefix(source, gitConfigPrefix):
+		return fetchGitConfig(ctx, strings.TrimPrefix(source, gitConfigPrefix))
+	case strings.HasPrefix(source, ociConfigPrefix):
+		ref, err := oci.ParseReference(strings.TrimPrefix(source, ociConfigPrefix))
+		if err != nil {
+			return nil, "", err
+		}
+		return oci.Pull(ctx, m.newClient(), ref, isConfigFileName)
+	default:
+		return fetchHTTPConfig(ctx, m.newClient(), source)
+	}
+}
+
+// isConfigFileName reports whether a file name has a configuration extension
+func isConfigFileName(name string) bool {
+	for _, ext := range configExtensions {
+		if strings.EqualFold(filepath.Ext(name), ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// fetchHTTPConfig downloads a configuration from an http(s) URL
+func fetchHTTPConfig(ctx context.Context, client *http.Client, source string) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("bad status: %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, path.Base(req.URL.Path), nil
+}
+
+// fetchGitConfig reads a file from a git repository, referenced as
+// <repository>//<file>?ref=<branch, tag or commit>. Repositories without a
+// scheme are cloned over https.
+func fetchGitConfig(ctx context.Context, ref string) ([]byte, string, error) {
+	repo, rawQuery, _ := strings.Cut(ref, "?")
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid git reference: %w", err)
+	}
+
+	// The file path follows the first "//" after the scheme
+	start := 0
+	if i := strings.Index(repo, "://"); i >= 0 {
+		start = i + 3
+	}
+	i := strings.Index(repo[start:], "//")
+	if i < 0 {
+		return nil, "", fmt.Errorf("invalid git reference '%s': expected <repository>//<file>", ref)
+	}
+	repo, file := repo[:start+i], repo[start+i+2:]
+	if start == 0 {
+		repo = "https://" + repo
+	}
+
+	revision := query.Get("ref")
+	if revision == "" {
+		revision = "HEAD"
+	}
+
+	dir, err := os.MkdirTemp("", "depman-config-*")
+	if err != nil {
+		return nil, "", err
+	}
+	defer os.RemoveAll(dir)
+
+	// Fetching a single revision works for branches, tags and commit hashes alike
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"fetch", "--quiet", "--depth", "1", repo, revision},
+		{"checkout", "--quiet", "FETCH_HEAD"},
+	} {
+		if _, err := runCommand(ctx, "git", append([]string{"-C", dir}, args...)...); err != nil {
+			return nil, "", err
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(file)))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read %s from %s: %w", file, repo, err)
+	}
+	return data, path.Base(file), nil
+}
@@ -0,0 +1,239 @@
+package depman
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const remoteConfig = `
+dependencies:
+  - name: "jq"
+    version:
+      required: "1.7.1"
+    platforms:
+      linux:
+        installer: {method: "system"}
+`
+
+func TestSplitChecksum(t *testing.T) {
+	testCases := []struct {
+		ref       string
+		source    string
+		checksum  string
+		expectErr bool
+	}{
+		{ref: "https://example.com/deps.yml", source: "https://example.com/deps.yml"},
+		{ref: "https://example.com/deps.yml?checksum=sha256:abc", source: "https://example.com/deps.yml", checksum: "sha256:abc"},
+		{ref: "git::github.com/org/repo//deps.yml?ref=v2&checksum=sha256:abc", source: "git::github.com/org/repo//deps.yml?ref=v2", checksum: "sha256:abc"},
+		{ref: "https://example.com/deps.yml?checksum=md5:abc", expectErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.ref, func(t *testing.T) {
+			source, checksum, err := splitChecksum(tc.ref)
+			if tc.expectErr {
+				if err == nil {
+					t.Errorf("Expected an error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Did not expect an error but got: %v", err)
+			}
+			if source != tc.source || checksum != tc.checksum {
+				t.Errorf("Expected %s and %s but got %s and %s", tc.source, tc.checksum, source, checksum)
+			}
+		})
+	}
+}
+
+func TestRemoteConfig(t *testing.T) {
+	available := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !available {
+			http.Error(w, "down", http.StatusServiceUnavailable)
+			return
+		}
+		io.WriteString(w, remoteConfig)
+	}))
+	defer server.Close()
+
+	tempDir, err := os.MkdirTemp("", "depman-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	sum := sha256.Sum256([]byte(remoteConfig))
+	checksum := "sha256:" + hex.EncodeToString(sum[:])
+	url := server.URL + "/platform/app-dependencies.yml"
+
+	t.Run("Fetch and cache", func(t *testing.T) {
+		manager, err := NewManager(url+"?checksum="+checksum, WithHomeDir(tempDir), WithLogOutput(io.Discard))
+		if err != nil {
+			t.Fatalf("Failed to create manager: %v", err)
+		}
+		if manager.FindDependency("jq") == nil {
+			t.Errorf("Expected the remote configuration to define jq")
+		}
+		if manager.ConfigSource == "" {
+			t.Errorf("Expected the remote reference to be recorded")
+		}
+
+		wd, _ := os.Getwd()
+		if got := manager.LockfilePath(); got != filepath.Join(wd, LockfileName) {
+			t.Errorf("Expected the lockfile in the working directory but got %s", got)
+		}
+	})
+
+	t.Run("Cached copy when the source is down", func(t *testing.T) {
+		available = false
+		defer func() { available = true }()
+
+		if _, err := NewManager(server.URL+"/never-fetched.yml", WithHomeDir(tempDir), WithLogOutput(io.Discard)); err == nil {
+			t.Errorf("Expected an error for a reference that was never cached")
+		}
+		if _, err := NewManager(url, WithHomeDir(tempDir), WithLogOutput(io.Discard)); err != nil {
+			t.Errorf("Expected the cached copy to be used but got: %v", err)
+		}
+	})
+
+	t.Run("Checksum mismatch", func(t *testing.T) {
+		_, err := NewManager(server.URL+"/other.yml?checksum=sha256:0000", WithHomeDir(tempDir), WithLogOutput(io.Discard))
+		if err == nil {
+			t.Errorf("Expected a checksum error but got none")
+		}
+	})
+}
+
+func TestFetchGitConfig(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	repo, err := os.MkdirTemp("", "depman-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(repo)
+
+	if err := os.MkdirAll(filepath.Join(repo, "configs"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "configs", "depman.yaml"), []byte(remoteConfig), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "config"},
+		{"tag", "v2"},
+	} {
+		if output, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v: %s", args, err, output)
+		}
+	}
+
+	data, name, err := fetchGitConfig(context.Background(), "file://"+repo+"//configs/depman.yaml?ref=v2")
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if string(data) != remoteConfig || name != "depman.yaml" {
+		t.Errorf("Expected depman.yaml with the committed content but got %s: %q", name, data)
+	}
+}
+
+// TestRemoteConfigFileName tests that a fetched configuration is cached under
+// its own directory whatever name the source gives it
+func TestRemoteConfigFileName(t *testing.T) {
+	testCases := []struct {
+		name        string
+		filename    string
+		expected    string
+		expectError bool
+	}{
+		{name: "Plain name", filename: "depman.yaml", expected: "depman.yaml"},
+		{name: "No extension", filename: "config", expected: configBaseName + ".yml"},
+		{name: "No name", filename: "", expected: configBaseName + ".yml"},
+		{name: "Path", filename: "configs/team/depman.yaml", expected: "depman.yaml"},
+		{name: "Traversal", filename: "../../../.bashrc.yml", expected: ".bashrc.yml"},
+		{name: "Parent directory", filename: "..", expectError: true},
+		{name: "Backslashes", filename: `..\..\depman.yaml`, expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := cachedFileName(tc.filename)
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("Expected an error but got %s", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Did not expect an error but got: %v", err)
+			}
+			if got != tc.expected {
+				t.Errorf("Expected %s but got %s", tc.expected, got)
+			}
+		})
+	}
+
+	t.Run("OCI title outside the cache", func(t *testing.T) {
+		content := []byte(remoteConfig)
+		sum := sha256.Sum256(content)
+		layerDigest := "sha256:" + hex.EncodeToString(sum[:])
+		manifest, _ := json.Marshal(map[string]interface{}{
+			"schemaVersion": 2,
+			"mediaType":     "application/vnd.oci.image.manifest.v1+json",
+			"layers": []map[string]interface{}{{
+				"mediaType":   "application/yaml",
+				"digest":      layerDigest,
+				"annotations": map[string]string{"org.opencontainers.image.title": "../../../escaped.yml"},
+			}},
+		})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/v2/org/configs/manifests/v1":
+				w.Write(manifest)
+			case "/v2/org/configs/blobs/" + layerDigest:
+				w.Write(content)
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+		defer server.Close()
+
+		tempDir := t.TempDir()
+		homeDir := filepath.Join(tempDir, "home")
+		ref := "oci://" + strings.TrimPrefix(server.URL, "http://") + "/org/configs:v1"
+		manager, err := NewManager(ref, WithHomeDir(homeDir), WithLogOutput(io.Discard))
+		if err != nil {
+			t.Fatalf("Failed to create manager: %v", err)
+		}
+		if manager.FindDependency("jq") == nil {
+			t.Errorf("Expected the remote configuration to define jq")
+		}
+
+		cacheDir := filepath.Join(homeDir, remoteConfigDir)
+		err = filepath.Walk(tempDir, func(path string, info os.FileInfo, err error) error {
+			if err == nil && info.Name() == "escaped.yml" && filepath.Dir(filepath.Dir(path)) != cacheDir {
+				t.Errorf("Expected the configuration in the cache but it was written to %s", path)
+			}
+			return err
+		})
+		if err != nil {
+			t.Fatalf("Failed to walk %s: %v", tempDir, err)
+		}
+	})
+}
@@ -0,0 +1,98 @@
+package depman
+
+import (
+	_ "embed"
+	"time"
+)
+
+// CheckReportSchemaVersion is the version of the check report format. It is
+// raised when a field is removed or changes meaning; fields may be added
+// without raising it.
+const CheckReportSchemaVersion = 1
+
+// checkReportSchema is the JSON Schema document describing CheckReport
+//
+//go:embed check-report.schema.json
+var checkReportSchema []byte
+
+// CheckReportSchema returns the JSON Schema document describing CheckReport
+func CheckReportSchema() []byte {
+	return append([]byte(nil), checkReportSchema...)
+}
+
+// CheckReport is the machine-readable result of checking dependencies, as
+// printed by 'depman check --output json'
+type CheckReport struct {
+	SchemaVersion int                `json:"schema_version"` // Version of this format (CheckReportSchemaVersion)
+	Platform      string             `json:"platform"`       // Platform the dependencies were checked on
+	CheckedAt     time.Time          `json:"checked_at"`     // When the report was created
+	Dependencies  []DependencyResult `json:"dependencies"`   // Results in configuration order
+}
+
+// DependencyResult is the machine-readable status of a single dependency
+type DependencyResult struct {
+	Name               string           `json:"name"`                          // Name of the dependency
+	Installed          bool             `json:"installed"`                     // Whether the dependency is installed
+	Version            string           `json:"version"`                       // Installed version, or "" if not installed
+	Update             UpdateType       `json:"update"`                        // Update needed to reach the required version
+	Compatible         bool             `json:"compatible"`                    // Whether the installed version satisfies the constraint
+	Error              string           `json:"error,omitempty"`               // Why the check or install failed
+	VerificationFailed bool             `json:"verification_failed,omitempty"` // Whether the error is a failed post-install verification
+	Attempts           int              `json:"attempts,omitempty"`            // Attempts the last install took
+	Problem            Problem          `json:"problem,omitempty"`             // Most severe problem found, or omitted if none
+	Vulnerabilities    []Vulnerability  `json:"vulnerabilities,omitempty"`     // Known vulnerabilities, if audited
+	Skipped            bool             `json:"skipped,omitempty"`             // Whether an interactive ensure skipped the dependency
+	Output             string           `json:"output,omitempty"`              // Captured output of the last install command or script
+	Log                string           `json:"log,omitempty"`                 // Log of every command the last install ran
+	Change             Change           `json:"change,omitempty"`              // What the run changed, or omitted if nothing
+	PreviousVersion    string           `json:"previous_version,omitempty"`    // Version installed before the change
+	ProvidedBy         string           `json:"provided_by,omitempty"`         // Alternative that satisfies the dependency in its place
+	Projects           []string         `json:"projects,omitempty"`            // Workspace projects declaring the dependency
+	ShadowedBy         *Installation    `json:"shadowed_by,omitempty"`         // Copy earlier on PATH that shells run instead of the one checked
+	Override           *VersionOverride `json:"override,omitempty"`            // Local override of the required version
+}
+
+// Result returns the machine-readable form of the status
+func (s *DependencyStatus) Result() DependencyResult {
+	result := DependencyResult{
+		Name:            s.Name,
+		Installed:       s.Installed,
+		Version:         s.CurrentVersion,
+		Update:          s.RequiredUpdate,
+		Compatible:      s.Compatible,
+		Attempts:        s.Attempts,
+		Problem:         s.Problem(),
+		Vulnerabilities: s.Vulnerabilities,
+		Skipped:         s.Skipped,
+		Output:          s.InstallOutput,
+		Log:             s.InstallLog,
+		Change:          s.Change,
+		PreviousVersion: s.PreviousVersion,
+		ProvidedBy:      s.ProvidedBy,
+		Projects:        s.Projects,
+		ShadowedBy:      s.ShadowedBy,
+		Override:        s.Override,
+	}
+	if s.Error != nil {
+		result.Error = s.Error.Error()
+		result.VerificationFailed = s.VerificationFailed()
+	}
+	return result
+}
+
+// NewCheckReport builds the report of statuses returned by
+// CheckAllDependencies, in configuration order
+func (m *Manager) NewCheckReport(statuses map[string]*DependencyStatus) *CheckReport {
+	report := &CheckReport{
+		SchemaVersion: CheckReportSchemaVersion,
+		Platform:      m.Platform,
+		CheckedAt:     time.Now().UTC(),
+		Dependencies:  []DependencyResult{},
+	}
+	for _, dep := range m.Config.Dependencies {
+		if status, ok := statuses[dep.Name]; ok {
+			report.Dependencies = append(report.Dependencies, status.Result())
+		}
+	}
+	return report
+}
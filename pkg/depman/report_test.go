@@ -0,0 +1,104 @@
+package depman
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// jsonFieldNames returns the JSON names of a struct's fields, sorted
+func jsonFieldNames(t reflect.Type) []string {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestCheckReportSchema(t *testing.T) {
+	var schema struct {
+		Properties map[string]struct {
+			Const int `json:"const"`
+		} `json:"properties"`
+		Defs map[string]struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(CheckReportSchema(), &schema); err != nil {
+		t.Fatalf("Failed to parse schema: %v", err)
+	}
+
+	if got := schema.Properties["schema_version"].Const; got != CheckReportSchemaVersion {
+		t.Errorf("Expected schema_version %d in the schema but got %d", CheckReportSchemaVersion, got)
+	}
+
+	// The schema must describe exactly the fields that are encoded
+	testCases := []struct {
+		name       string
+		typ        reflect.Type
+		properties []string
+	}{
+		{name: "report", typ: reflect.TypeOf(CheckReport{})},
+		{name: "dependency", typ: reflect.TypeOf(DependencyResult{})},
+		{name: "vulnerability", typ: reflect.TypeOf(Vulnerability{})},
+	}
+	for _, tc := range testCases {
+		var properties []string
+		if tc.name == "report" {
+			for name := range schema.Properties {
+				properties = append(properties, name)
+			}
+		} else {
+			for name := range schema.Defs[tc.name].Properties {
+				properties = append(properties, name)
+			}
+		}
+		sort.Strings(properties)
+
+		if expected := jsonFieldNames(tc.typ); !reflect.DeepEqual(properties, expected) {
+			t.Errorf("Expected %s properties %v in the schema but got %v", tc.name, expected, properties)
+		}
+	}
+}
+
+func TestNewCheckReport(t *testing.T) {
+	manager := &Manager{
+		Platform: "linux",
+		Config:   &DependencyConfig{Dependencies: []Dependency{{Name: "b"}, {Name: "a"}, {Name: "unchecked"}}},
+		logger:   &mockLogger{},
+	}
+	statuses := map[string]*DependencyStatus{
+		"a": {Name: "a", Installed: true, CurrentVersion: "1.0.0", Compatible: true},
+		"b": {Name: "b", Error: errors.New("not found")},
+	}
+
+	data, err := json.Marshal(manager.NewCheckReport(statuses))
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+
+	var report map[string]interface{}
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("Failed to parse report: %v", err)
+	}
+	if report["schema_version"] != float64(CheckReportSchemaVersion) || report["platform"] != "linux" {
+		t.Errorf("Expected schema version %d on linux but got %v", CheckReportSchemaVersion, report)
+	}
+
+	deps := report["dependencies"].([]interface{})
+	if len(deps) != 2 {
+		t.Fatalf("Expected 2 dependencies but got %d", len(deps))
+	}
+	first, second := deps[0].(map[string]interface{}), deps[1].(map[string]interface{})
+	if first["name"] != "b" || first["error"] != "not found" || first["problem"] != "missing" {
+		t.Errorf("Expected b to be first with its error but got %v", first)
+	}
+	if second["name"] != "a" || second["update"] != "none" || second["problem"] != nil {
+		t.Errorf("Expected a to be up to date but got %v", second)
+	}
+}
@@ -0,0 +1,76 @@
+package depman
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// Reporter receives everything depman has to show or ask the user while it
+// works. The library never writes to or reads from the terminal itself: the
+// CLI implements Reporter to draw progress and ask on the terminal, and
+// embedders implement it to restyle or suppress the same in their own
+// interface. Implementations must be safe for concurrent use.
+type Reporter interface {
+	ProgressReporter
+
+	// Confirm asks a yes/no question, such as whether to run a command with
+	// sudo under the prompt privilege policy
+	Confirm(question string) (bool, error)
+}
+
+// WithReporter sets the reporter receiving progress events and questions.
+// It replaces a reporter set with WithProgress.
+func WithReporter(reporter Reporter) Option {
+	return func(m *Manager) {
+		m.progress = reporter
+	}
+}
+
+// errNoReporter is returned for questions when no Reporter can ask them
+var errNoReporter = errors.New("no reporter is set to ask the user; see WithReporter")
+
+// confirm asks the reporter a yes/no question
+func (m *Manager) confirm(question string) (bool, error) {
+	reporter, ok := m.progress.(Reporter)
+	if !ok {
+		return false, errNoReporter
+	}
+	return reporter.Confirm(question)
+}
+
+// logWriter logs each line written to it, for output of child processes
+// that would otherwise go straight to the terminal
+type logWriter struct {
+	mu      sync.Mutex
+	log     func(format string, args ...interface{})
+	pending []byte
+}
+
+func (w *logWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.pending = append(w.pending, p...)
+	for {
+		i := bytes.IndexByte(w.pending, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		if line := strings.TrimRight(string(w.pending[:i]), "\r"); line != "" {
+			w.log("%s", line)
+		}
+		w.pending = w.pending[i+1:]
+	}
+}
+
+// Close logs output not ended by a newline
+func (w *logWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if line := strings.TrimSpace(string(w.pending)); line != "" {
+		w.log("%s", line)
+	}
+	w.pending = nil
+	return nil
+}
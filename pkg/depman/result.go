@@ -0,0 +1,132 @@
+package depman
+
+import (
+	"context"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// Result is the outcome of Check or Ensure. It holds data only; printing it
+// is up to the caller.
+type Result struct {
+	Statuses  []*DependencyStatus // Status of each dependency, in configuration order
+	Installed []string            // Dependencies installed or upgraded by the run, in configuration order
+	Duration  time.Duration       // How long the run took
+
+	byName map[string]*DependencyStatus
+}
+
+// Status returns the status of a dependency, or nil if it was not part of the run
+func (r *Result) Status(name string) *DependencyStatus {
+	return r.byName[name]
+}
+
+// Failed returns the statuses of dependencies that could not be checked or installed
+func (r *Result) Failed() []*DependencyStatus {
+	var failed []*DependencyStatus
+	for _, status := range r.Statuses {
+		if status.Error != nil {
+			failed = append(failed, status)
+		}
+	}
+	return failed
+}
+
+// OK reports whether every dependency is installed and satisfies its
+// requirements, apart from those an interactive ensure skipped
+func (r *Result) OK() bool {
+	for _, status := range r.Statuses {
+		if status.Skipped {
+			continue
+		}
+		if status.Problem() != ProblemNone {
+			return false
+		}
+	}
+	return true
+}
+
+// Changed returns the statuses of dependencies the run installed, upgraded,
+// downgraded or reinstalled
+func (r *Result) Changed() []*DependencyStatus {
+	var changed []*DependencyStatus
+	for _, status := range r.Statuses {
+		if status.Change != ChangeNone {
+			changed = append(changed, status)
+		}
+	}
+	return changed
+}
+
+// recordChange sets what installing a dependency changed, comparing its
+// status after the install with the one before
+func recordChange(before, after *DependencyStatus) {
+	switch {
+	case before == nil || !before.Installed:
+		after.Change = ChangeInstalled
+		return
+	case before.CurrentVersion == after.CurrentVersion:
+		after.Change = ChangeReinstalled
+	default:
+		after.Change = ChangeUpgraded
+		previous, errPrevious := semver.NewVersion(before.CurrentVersion)
+		current, errCurrent := semver.NewVersion(after.CurrentVersion)
+		if errPrevious == nil && errCurrent == nil && current.LessThan(previous) {
+			after.Change = ChangeDowngraded
+		}
+	}
+	after.PreviousVersion = before.CurrentVersion
+}
+
+// newResult orders statuses and the names of installed artifacts by
+// configuration. It returns nil if there are no statuses because the run
+// failed before checking any dependency.
+func (m *Manager) newResult(statuses map[string]*DependencyStatus, artifacts map[string]LockedArtifact, start time.Time) *Result {
+	if statuses == nil {
+		return nil
+	}
+	result := &Result{Statuses: []*DependencyStatus{}, Duration: time.Since(start), byName: statuses}
+	if m.Config == nil {
+		return result
+	}
+	for _, dep := range m.Config.Dependencies {
+		if status, ok := statuses[dep.Name]; ok {
+			result.Statuses = append(result.Statuses, status)
+		}
+		if _, ok := artifacts[dep.Name]; ok {
+			result.Installed = append(result.Installed, dep.Name)
+		}
+	}
+	return result
+}
+
+// Check checks every selected dependency without installing anything. The
+// result is nil if the check failed.
+func (m *Manager) Check(ctx context.Context) (*Result, error) {
+	start := time.Now()
+	m.startRun()
+	statuses, err := m.CheckAllDependencies(ctx)
+	result := m.newResult(statuses, nil, start)
+	m.recordRun("check", start, result, err)
+	return result, err
+}
+
+// Ensure checks every selected dependency and installs or upgrades those
+// that need it. This is the main function that most applications should use.
+// The result is returned even if installs fail, and is nil only if the
+// dependencies could not be planned.
+func (m *Manager) Ensure(ctx context.Context) (*Result, error) {
+	start := time.Now()
+	artifacts := make(map[string]LockedArtifact)
+	m.startRun()
+
+	ctx, done := m.operation(ctx, "depman.ensure")
+	statuses, err := m.ensureDependencies(ctx, artifacts)
+	done(err)
+	m.notifyWebhooks(ctx, statuses, err)
+
+	result := m.newResult(statuses, artifacts, start)
+	m.recordRun("ensure", start, result, err)
+	return result, err
+}
@@ -0,0 +1,95 @@
+package depman
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestResult(t *testing.T) {
+	manager := &Manager{Config: &DependencyConfig{Dependencies: []Dependency{{Name: "jq"}, {Name: "yq"}, {Name: "go"}}}}
+	statuses := map[string]*DependencyStatus{
+		"go": {Name: "go", Installed: true, Compatible: true},
+		"jq": {Name: "jq", Error: errors.New("download failed")},
+		"yq": {Name: "yq", Installed: true, Compatible: true},
+	}
+	artifacts := map[string]LockedArtifact{"yq": {}, "go": {}}
+
+	result := manager.newResult(statuses, artifacts, time.Now())
+
+	if len(result.Statuses) != 3 || result.Statuses[0].Name != "jq" || result.Statuses[2].Name != "go" {
+		t.Errorf("Expected statuses in configuration order but got %v", result.Statuses)
+	}
+	if len(result.Installed) != 2 || result.Installed[0] != "yq" || result.Installed[1] != "go" {
+		t.Errorf("Expected yq and go to be installed but got %v", result.Installed)
+	}
+	if failed := result.Failed(); len(failed) != 1 || failed[0].Name != "jq" {
+		t.Errorf("Expected jq to fail but got %v", failed)
+	}
+	if result.OK() {
+		t.Error("Expected the result not to be OK with a failed dependency")
+	}
+	if result.Status("go") != statuses["go"] || result.Status("missing") != nil {
+		t.Error("Expected statuses to be looked up by name")
+	}
+
+	if manager.newResult(nil, nil, time.Now()) != nil {
+		t.Error("Expected no result without statuses")
+	}
+}
+
+func TestCheckResult(t *testing.T) {
+	manager := &Manager{
+		Config: &DependencyConfig{Name: "Test App", Dependencies: []Dependency{{
+			Name:      "missing-tool",
+			Version:   Version{Required: "1.0.0"},
+			Platforms: map[string]PlatformConfig{"linux": {Commands: Commands{Verify: []string{"depman-test-missing-tool", "--version"}}}},
+		}}},
+		Platform: "linux",
+		homeDir:  t.TempDir(),
+		logger:   &mockLogger{},
+		progress: noProgress{},
+	}
+
+	result, err := manager.Check(context.Background())
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if len(result.Statuses) != 1 || result.Statuses[0].Installed || len(result.Installed) != 0 {
+		t.Errorf("Expected one missing dependency but got %+v", result)
+	}
+	if result.OK() {
+		t.Error("Expected the result not to be OK with a missing dependency")
+	}
+}
+
+func TestRecordChange(t *testing.T) {
+	testCases := []struct {
+		name     string
+		before   *DependencyStatus
+		after    string
+		expected Change
+		previous string
+	}{
+		{name: "Missing", before: &DependencyStatus{}, after: "1.6.0", expected: ChangeInstalled},
+		{name: "Upgrade", before: &DependencyStatus{Installed: true, CurrentVersion: "1.5.0"}, after: "1.6.0", expected: ChangeUpgraded, previous: "1.5.0"},
+		{name: "Downgrade", before: &DependencyStatus{Installed: true, CurrentVersion: "2.0.0"}, after: "1.9.0", expected: ChangeDowngraded, previous: "2.0.0"},
+		{name: "Same version", before: &DependencyStatus{Installed: true, CurrentVersion: "1.6.0"}, after: "1.6.0", expected: ChangeReinstalled, previous: "1.6.0"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			after := &DependencyStatus{Installed: true, CurrentVersion: tc.after}
+			recordChange(tc.before, after)
+			if after.Change != tc.expected || after.PreviousVersion != tc.previous {
+				t.Errorf("Expected %q from %q but got %q from %q", tc.expected, tc.previous, after.Change, after.PreviousVersion)
+			}
+		})
+	}
+
+	result := &Result{Statuses: []*DependencyStatus{{Name: "jq"}, {Name: "yq", Change: ChangeUpgraded}}}
+	if changed := result.Changed(); len(changed) != 1 || changed[0].Name != "yq" {
+		t.Errorf("Expected only yq to have changed but got %v", changed)
+	}
+}
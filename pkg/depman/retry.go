@@ -0,0 +1,215 @@
+package depman
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/devnadeemashraf/depman/internal/downloader"
+	"github.com/devnadeemashraf/depman/internal/github"
+)
+
+// RetryPolicy controls how failed downloads, API lookups and package-manager
+// installs are retried. Only errors that look transient are retried.
+type RetryPolicy struct {
+	MaxAttempts  int           // Attempts per operation, including the first (1 disables retries)
+	InitialDelay time.Duration // Delay before the first retry
+	MaxDelay     time.Duration // Upper bound on the delay between attempts
+	Multiplier   float64       // Factor the delay grows by after each retry
+}
+
+// DefaultRetryPolicy is used unless WithRetryPolicy sets another
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:  3,
+	InitialDelay: time.Second,
+	MaxDelay:     30 * time.Second,
+	Multiplier:   2,
+}
+
+// WithRetryPolicy sets how transient failures are retried
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(m *Manager) {
+		m.retryPolicy = &policy
+	}
+}
+
+// transientOutput lists fragments of package-manager output that point at
+// network trouble or a briefly held lock rather than a real install failure
+var transientOutput = []string{
+	"could not resolve",
+	"temporary failure",
+	"timed out",
+	"connection reset",
+	"connection refused",
+	"network is unreachable",
+	"failed to fetch",
+	"failed to download",
+	"unable to connect",
+	"could not get lock",
+	"the remote name could not be resolved",
+}
+
+// policy returns the retry policy in effect
+func (m *Manager) policy() RetryPolicy {
+	policy := DefaultRetryPolicy
+	if m.retryPolicy != nil {
+		policy = *m.retryPolicy
+	}
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
+	}
+	if policy.Multiplier < 1 {
+		policy.Multiplier = 1
+	}
+	return policy
+}
+
+// retry runs fn until it succeeds, fails with an error that is not
+// transient, ctx is done or the policy runs out of attempts. The attempts
+// taken are recorded against the dependency.
+func (m *Manager) retry(ctx context.Context, dep *Dependency, operation string, fn func() error) error {
+	policy := m.policy()
+	delay := policy.InitialDelay
+
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		m.recordAttempts(dep.Name, attempt)
+		if err == nil || attempt >= policy.MaxAttempts || !isRetryable(err) || ctx.Err() != nil {
+			return err
+		}
+
+		m.logger.Warnf("%s for %s failed (attempt %d of %d), retrying in %s: %v",
+			operation, dep.Name, attempt, policy.MaxAttempts, delay, err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%w (gave up retrying: %v)", err, ctx.Err())
+		case <-timer.C:
+		}
+
+		delay = time.Duration(float64(delay) * policy.Multiplier)
+		if policy.MaxDelay > 0 && delay > policy.MaxDelay {
+			delay = policy.MaxDelay
+		}
+	}
+}
+
+// retryCommand runs a package-manager command under the retry policy
+func (m *Manager) retryCommand(ctx context.Context, dep *Dependency, name string, args ...string) (string, error) {
+	var output string
+	err := m.retry(ctx, dep, name, func() error {
+		var err error
+		output, err = runCommand(ctx, name, args...)
+		return err
+	})
+	return output, err
+}
+
+// isRetryable reports whether an error looks transient
+func isRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	// Verification failures and exhausted rate limits do not clear up on their own
+	var verr *VerificationError
+	var rateLimit *github.RateLimitError
+	if errors.As(err, &verr) || errors.As(err, &rateLimit) {
+		return false
+	}
+
+	var status *downloader.StatusError
+	var opErr *net.OpError
+	var dnsErr *net.DNSError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &status):
+		return status.Temporary()
+	case errors.As(err, &opErr), errors.As(err, &dnsErr):
+		return true
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return true
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return true
+	}
+
+	// Package managers report network trouble only in their output
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return false
+	}
+	message := strings.ToLower(err.Error())
+	for _, fragment := range transientOutput {
+		if strings.Contains(message, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// recordAttempts notes the attempts an operation on a dependency took,
+// keeping the highest count seen during the current install
+func (m *Manager) recordAttempts(name string, attempts int) {
+	m.attemptsMu.Lock()
+	defer m.attemptsMu.Unlock()
+	if m.attempts == nil {
+		m.attempts = make(map[string]int)
+	}
+	if attempts > m.attempts[name] {
+		m.attempts[name] = attempts
+	}
+}
+
+// takeAttempts returns and clears the attempt count recorded for a dependency
+func (m *Manager) takeAttempts(name string) int {
+	m.attemptsMu.Lock()
+	defer m.attemptsMu.Unlock()
+	attempts := m.attempts[name]
+	delete(m.attempts, name)
+	return attempts
+}
+
+// recordOutput keeps the output of an install command or script run for a dependency
+func (m *Manager) recordOutput(name, output string) {
+	m.attemptsMu.Lock()
+	defer m.attemptsMu.Unlock()
+	if m.outputs == nil {
+		m.outputs = make(map[string]string)
+	}
+	m.outputs[name] = output
+}
+
+// takeOutput returns and clears the install script output recorded for a dependency
+func (m *Manager) takeOutput(name string) string {
+	m.attemptsMu.Lock()
+	defer m.attemptsMu.Unlock()
+	output := m.outputs[name]
+	delete(m.outputs, name)
+	return output
+}
+
+// recordLog keeps the install log written for a dependency
+func (m *Manager) recordLog(name, path string) {
+	m.attemptsMu.Lock()
+	defer m.attemptsMu.Unlock()
+	if m.installLogs == nil {
+		m.installLogs = make(map[string]string)
+	}
+	m.installLogs[name] = path
+}
+
+// takeLog returns and clears the install log recorded for a dependency
+func (m *Manager) takeLog(name string) string {
+	m.attemptsMu.Lock()
+	defer m.attemptsMu.Unlock()
+	path := m.installLogs[name]
+	delete(m.installLogs, name)
+	return path
+}
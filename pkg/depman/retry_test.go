@@ -0,0 +1,150 @@
+package depman
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/devnadeemashraf/depman/internal/downloader"
+	"github.com/devnadeemashraf/depman/internal/github"
+)
+
+// TestIsRetryable tests the classification of transient and permanent errors
+func TestIsRetryable(t *testing.T) {
+	exitErr := exec.Command("sh", "-c", "exit 1").Run()
+
+	testCases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"Server error", &downloader.StatusError{StatusCode: http.StatusServiceUnavailable}, true},
+		{"Too many requests", fmt.Errorf("lookup: %w", &downloader.StatusError{StatusCode: http.StatusTooManyRequests}), true},
+		{"Not found", &downloader.StatusError{StatusCode: http.StatusNotFound}, false},
+		{"Connection refused", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, true},
+		{"DNS failure", &net.DNSError{Err: "no such host", Name: "example.invalid"}, true},
+		{"Cancelled", fmt.Errorf("download: %w", context.Canceled), false},
+		{"Rate limited", &github.RateLimitError{Reset: time.Now()}, false},
+		{"Verification", &VerificationError{Kind: "checksum", Err: errors.New("mismatch")}, false},
+		{"Package manager network error", fmt.Errorf("apt-get failed: %w, output: Temporary failure resolving 'deb.debian.org'", exitErr), true},
+		{"Package manager failure", fmt.Errorf("apt-get failed: %w, output: Unable to locate package nope", exitErr), false},
+		{"Plain error mentioning network", errors.New("failed to download file: unsupported protocol scheme"), false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := isRetryable(tc.err); got != tc.expected {
+				t.Errorf("Expected retryable %v but got %v for %v", tc.expected, got, tc.err)
+			}
+		})
+	}
+}
+
+// TestRetry tests backoff, attempt limits and attempt counting
+func TestRetry(t *testing.T) {
+	transient := &downloader.StatusError{StatusCode: http.StatusBadGateway, Status: "502 Bad Gateway"}
+	dep := &Dependency{Name: "tool"}
+	manager := &Manager{logger: &mockLogger{}}
+	WithRetryPolicy(RetryPolicy{MaxAttempts: 3, InitialDelay: time.Millisecond, Multiplier: 2})(manager)
+
+	t.Run("Succeeds after transient failures", func(t *testing.T) {
+		calls := 0
+		err := manager.retry(context.Background(), dep, "Download", func() error {
+			calls++
+			if calls < 3 {
+				return transient
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Did not expect an error but got: %v", err)
+		}
+		if attempts := manager.takeAttempts(dep.Name); attempts != 3 {
+			t.Errorf("Expected 3 attempts but got %d", attempts)
+		}
+	})
+
+	t.Run("Gives up at the limit", func(t *testing.T) {
+		calls := 0
+		err := manager.retry(context.Background(), dep, "Download", func() error {
+			calls++
+			return transient
+		})
+		if !errors.Is(err, transient) {
+			t.Errorf("Expected the last error but got %v", err)
+		}
+		if calls != 3 {
+			t.Errorf("Expected 3 calls but got %d", calls)
+		}
+		manager.takeAttempts(dep.Name)
+	})
+
+	t.Run("Permanent errors are not retried", func(t *testing.T) {
+		calls := 0
+		manager.retry(context.Background(), dep, "Download", func() error {
+			calls++
+			return &downloader.StatusError{StatusCode: http.StatusNotFound, Status: "404 Not Found"}
+		})
+		if calls != 1 {
+			t.Errorf("Expected 1 call but got %d", calls)
+		}
+		manager.takeAttempts(dep.Name)
+	})
+
+	t.Run("Stops when cancelled", func(t *testing.T) {
+		slow := &Manager{logger: &mockLogger{}}
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 5, InitialDelay: time.Hour})(slow)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		calls := 0
+		err := slow.retry(ctx, dep, "Download", func() error {
+			calls++
+			cancel()
+			return transient
+		})
+		if err == nil || calls != 1 {
+			t.Errorf("Expected one failed call but got %d calls and %v", calls, err)
+		}
+	})
+}
+
+// TestDownloadRetry tests that flaky downloads are retried and counted in the status
+func TestDownloadRetry(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("binary"))
+	}))
+	defer server.Close()
+
+	tempDir, err := os.MkdirTemp("", "depman-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	dep := &Dependency{Name: "tool"}
+	manager := &Manager{logger: &mockLogger{}, homeDir: tempDir}
+	WithRetryPolicy(RetryPolicy{MaxAttempts: 2, InitialDelay: time.Millisecond})(manager)
+
+	if _, err := manager.downloadArtifact(context.Background(), dep, &PlatformConfig{}, server.URL+"/tool", "", tempDir); err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("Expected 2 requests but got %d", requests)
+	}
+	if attempts := manager.takeAttempts(dep.Name); attempts != 2 {
+		t.Errorf("Expected 2 attempts to be recorded but got %d", attempts)
+	}
+}
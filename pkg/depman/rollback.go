@@ -0,0 +1,155 @@
+package depman
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// WithRollback sets whether a failed install of a tool kept side by side is
+// rolled back to the version that was active before it (default true)
+func WithRollback(enabled bool) Option {
+	return func(m *Manager) {
+		m.noRollback = !enabled
+	}
+}
+
+// toolSnapshot is the state of a side-by-side tool before an install changed it
+type toolSnapshot struct {
+	dep      *Dependency
+	binary   string          // Binary the shim runs, inside the version directory
+	active   string          // Version the shim ran, or "" if there was no shim
+	versions map[string]bool // Versions already in the tools directory
+}
+
+// snapshotTool records what a failed install of dep has to restore, or
+// returns nil if its install method does not keep versions side by side.
+// Other methods replace the installed version in place, so there is nothing
+// left to go back to.
+func (m *Manager) snapshotTool(dep *Dependency) *toolSnapshot {
+	if m.noRollback {
+		return nil
+	}
+	platformConfig, err := m.GetPlatformConfig(dep)
+	if err != nil {
+		return nil
+	}
+	strategy, err := m.strategyFor(platformConfig)
+	if err != nil {
+		return nil
+	}
+	sideBySide, ok := strategy.(sideBySideInstaller)
+	if !ok {
+		return nil
+	}
+
+	versions, active, err := m.InstalledVersions(dep.Name)
+	if err != nil {
+		m.logger.Warnf("Cannot roll back a failed install of %s: %v", dep.Name, err)
+		return nil
+	}
+	snapshot := &toolSnapshot{
+		dep:      dep,
+		binary:   sideBySide.binaryName(m, dep, platformConfig),
+		active:   active,
+		versions: make(map[string]bool, len(versions)),
+	}
+	for _, version := range versions {
+		snapshot.versions[version] = true
+	}
+	return snapshot
+}
+
+// restoreTool undoes a failed install: versions it added are deleted and the
+// shim runs the previously active version again, or is removed if there was none
+func (m *Manager) restoreTool(snapshot *toolSnapshot) error {
+	toolDir := filepath.Join(m.ToolsDir(), snapshot.dep.Name)
+	versions, _, err := m.InstalledVersions(snapshot.dep.Name)
+	if err != nil {
+		return err
+	}
+	for _, version := range versions {
+		if !snapshot.versions[version] {
+			if err := os.RemoveAll(filepath.Join(toolDir, version)); err != nil {
+				return fmt.Errorf("failed to remove %s %s: %w", snapshot.dep.Name, version, err)
+			}
+		}
+	}
+
+	if snapshot.active != "" {
+		_, err := m.writeShim(snapshot.dep, snapshot.binary, snapshot.active)
+		return err
+	}
+
+	shim := m.shimPath(snapshot.binary)
+	if err := os.Remove(shim); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove %s: %w", shim, err)
+	}
+	if len(snapshot.versions) == 0 {
+		if err := os.RemoveAll(toolDir); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", toolDir, err)
+		}
+	}
+	return nil
+}
+
+// rollbackFailedInstall restores a tool after its install failed with err,
+// returning err annotated with what it was rolled back to
+func (m *Manager) rollbackFailedInstall(snapshot *toolSnapshot, err error) error {
+	if snapshot == nil {
+		return err
+	}
+	if rollbackErr := m.restoreTool(snapshot); rollbackErr != nil {
+		m.logger.Errorf("Failed to roll back %s: %v", snapshot.dep.Name, rollbackErr)
+		return err
+	}
+	if snapshot.active == "" {
+		m.logger.Warnf("Removed the failed install of %s", snapshot.dep.Name)
+		return err
+	}
+	m.logger.Warnf("Rolled %s back to %s after its install failed", snapshot.dep.Name, snapshot.active)
+	return fmt.Errorf("%w (rolled back to %s)", err, snapshot.active)
+}
+
+// RollbackDependency reverts the last install or upgrade of a dependency to
+// the version installed before it. Tools kept side by side switch back to the
+// previous version; other install methods install it again. The previous
+// version becomes the one to roll back to, so rolling back twice undoes the
+// rollback.
+func (m *Manager) RollbackDependency(ctx context.Context, name string) (*DependencyStatus, error) {
+	dep := m.FindDependency(name)
+	if dep == nil {
+		return nil, fmt.Errorf("dependency '%s' not found in configuration", name)
+	}
+
+	install, err := m.findInstall(name)
+	if err != nil {
+		return nil, err
+	}
+	if install == nil || install.PreviousVersion == "" {
+		return nil, fmt.Errorf("no previous version of %s is recorded to roll back to", name)
+	}
+	previous, current := install.PreviousVersion, install.Version
+
+	if m.isToolVersionInstalled(dep, previous) {
+		if err := m.UseVersion(name, previous); err != nil {
+			return nil, err
+		}
+		if err := m.recordRollback(name, previous, current); err != nil {
+			m.logger.Warnf("Failed to record the rollback of %s: %v", name, err)
+		}
+	} else {
+		statuses, err := m.InstallDependencies(ctx, []string{name + "@" + previous}, true)
+		if err != nil {
+			return statuses[name], fmt.Errorf("failed to install %s %s: %w", name, previous, err)
+		}
+	}
+
+	m.logger.Infof("Rolled %s back from %s to %s", name, current, previous)
+	status, err := m.CheckDependency(ctx, dep)
+	if err == nil && (!status.Compatible || status.RequiredUpdate != NoUpdate) {
+		m.logger.Warnf("The configuration does not accept %s %s; the next ensure will change it again", name, previous)
+	}
+	return status, err
+}
@@ -0,0 +1,118 @@
+package depman
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// newRollbackTestManager returns a manager for an archive-installed tool with
+// the given versions in its tools directory, the last one active
+func newRollbackTestManager(t *testing.T, versions ...string) (*Manager, *Dependency) {
+	homeDir := t.TempDir()
+	manager := &Manager{
+		Platform:   "linux",
+		ConfigPath: filepath.Join(homeDir, "app-dependencies.yml"),
+		homeDir:    homeDir,
+		logger:     &mockLogger{},
+		progress:   noProgress{},
+		Config: &DependencyConfig{Dependencies: []Dependency{{
+			Name:      "tool",
+			Version:   Version{Constraint: ">=1.0.0"},
+			Platforms: map[string]PlatformConfig{"linux": {Installer: Installer{Method: "archive"}, Commands: Commands{Verify: []string{"tool"}}}},
+		}}},
+	}
+	dep := manager.FindDependency("tool")
+
+	for _, version := range versions {
+		if err := installBinaryContent(filepath.Join(manager.toolVersionDir("tool", version), "tool"), "#!/bin/sh\necho tool "+version+"\n"); err != nil {
+			t.Fatalf("Failed to write binary: %v", err)
+		}
+		if _, err := manager.writeShim(dep, "tool", version); err != nil {
+			t.Fatalf("Did not expect an error but got: %v", err)
+		}
+	}
+	return manager, dep
+}
+
+func TestRollbackFailedInstall(t *testing.T) {
+	t.Run("Upgrade", func(t *testing.T) {
+		manager, dep := newRollbackTestManager(t, "1.0.0")
+		snapshot := manager.snapshotTool(dep)
+
+		// The upgrade installs 2.0.0 and then fails its verification
+		if err := installBinaryContent(filepath.Join(manager.toolVersionDir("tool", "2.0.0"), "tool"), "#!/bin/sh\nexit 1\n"); err != nil {
+			t.Fatalf("Failed to write binary: %v", err)
+		}
+		manager.writeShim(dep, "tool", "2.0.0")
+		failure := errors.New("verification failed")
+
+		err := manager.rollbackFailedInstall(snapshot, failure)
+		if !errors.Is(err, failure) || !strings.Contains(err.Error(), "rolled back to 1.0.0") {
+			t.Errorf("Expected the failure annotated with the rollback but got: %v", err)
+		}
+		versions, active, _ := manager.InstalledVersions("tool")
+		if active != "1.0.0" || len(versions) != 1 {
+			t.Errorf("Expected only 1.0.0 to be left and active but got %v (active %s)", versions, active)
+		}
+	})
+
+	t.Run("First install", func(t *testing.T) {
+		manager, dep := newRollbackTestManager(t)
+		snapshot := manager.snapshotTool(dep)
+		if err := installBinaryContent(filepath.Join(manager.toolVersionDir("tool", "1.0.0"), "tool"), "#!/bin/sh\n"); err != nil {
+			t.Fatalf("Failed to write binary: %v", err)
+		}
+		manager.writeShim(dep, "tool", "1.0.0")
+
+		manager.rollbackFailedInstall(snapshot, errors.New("post-install hook failed"))
+		if _, err := os.Stat(filepath.Join(manager.ToolsDir(), "tool")); !os.IsNotExist(err) {
+			t.Errorf("Expected the tool directory to be removed")
+		}
+		if _, err := os.Stat(manager.shimPath("tool")); !os.IsNotExist(err) {
+			t.Errorf("Expected the shim to be removed")
+		}
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		manager, dep := newRollbackTestManager(t, "1.0.0")
+		WithRollback(false)(manager)
+		if manager.snapshotTool(dep) != nil {
+			t.Errorf("Expected no snapshot with rollback disabled")
+		}
+	})
+}
+
+func TestRollbackDependency(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell shims are not used on Windows")
+	}
+
+	manager, dep := newRollbackTestManager(t, "1.0.0", "2.0.0")
+	if _, err := manager.RollbackDependency(context.Background(), "tool"); err == nil {
+		t.Fatalf("Expected an error without a recorded previous version")
+	}
+
+	for _, version := range []string{"1.0.0", "2.0.0"} {
+		if err := manager.recordInstall(dep, &DependencyStatus{CurrentVersion: version}, LockedArtifact{}); err != nil {
+			t.Fatalf("Did not expect an error but got: %v", err)
+		}
+	}
+
+	status, err := manager.RollbackDependency(context.Background(), "tool")
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if status.CurrentVersion != "1.0.0" {
+		t.Errorf("Expected 1.0.0 to run after the rollback but got %s", status.CurrentVersion)
+	}
+
+	install, _ := manager.findInstall("tool")
+	if install.Version != "1.0.0" || install.PreviousVersion != "2.0.0" {
+		t.Errorf("Expected 2.0.0 to become the version to roll back to but got %+v", install)
+	}
+}
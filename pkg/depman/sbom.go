@@ -0,0 +1,141 @@
+package depman
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/devnadeemashraf/depman/internal/sbom"
+)
+
+// SBOMFormat is a software bill of materials format
+type SBOMFormat string
+
+// Supported SBOM formats
+const (
+	SBOMCycloneDX SBOMFormat = "cyclonedx" // CycloneDX 1.5 JSON
+	SBOMSPDX      SBOMFormat = "spdx"      // SPDX 2.3 JSON
+)
+
+// ParseSBOMFormat parses an SBOM format name
+func ParseSBOMFormat(format string) (SBOMFormat, error) {
+	switch SBOMFormat(strings.ToLower(format)) {
+	case SBOMCycloneDX:
+		return SBOMCycloneDX, nil
+	case SBOMSPDX:
+		return SBOMSPDX, nil
+	default:
+		return "", fmt.Errorf("invalid SBOM format '%s' (expected cyclonedx or spdx)", format)
+	}
+}
+
+// WriteSBOM writes a software bill of materials for the dependencies on the
+// current platform. Versions, download URLs and checksums come from the
+// lockfile where it has them, else from the installed version and the
+// configuration. Licenses that are not declared come from the lockfile or
+// the package or release metadata.
+func (m *Manager) WriteSBOM(ctx context.Context, w io.Writer, format SBOMFormat) error {
+	if err := m.validateConfiguration(); err != nil {
+		return fmt.Errorf("invalid dependency configuration: %w", err)
+	}
+
+	deps, err := m.SelectedDependencies()
+	if err != nil {
+		return err
+	}
+
+	lock, err := LoadLockfile(m.LockfilePath())
+	if err != nil && !errors.Is(err, ErrLockfileNotFound) {
+		return err
+	}
+
+	// Installed versions are only needed for dependencies missing from the lockfile
+	var statuses map[string]*DependencyStatus
+	for _, dep := range deps {
+		if lock == nil || lock.Find(dep.Name) == nil {
+			if statuses, err = m.CheckAllDependencies(ctx); err != nil {
+				return err
+			}
+			break
+		}
+	}
+
+	doc := sbom.Document{Name: m.Config.Name, Tool: "depman", Created: time.Now()}
+	for _, dep := range deps {
+		component := sbom.Component{
+			Name:        dep.Name,
+			Description: dep.Description,
+			License:     dep.License,
+			DependsOn:   dep.Dependencies,
+		}
+
+		// Prefer what was recorded when the dependency was installed
+		if entry := lockEntry(lock, dep.Name); entry != nil {
+			component.Version = entry.Version
+			component.DownloadURL = entry.Platforms[m.lockPlatform(dep)].URL
+			component.Checksum = entry.Platforms[m.lockPlatform(dep)].Checksum
+		} else if status := statuses[dep.Name]; status != nil && status.Installed {
+			component.Version = status.CurrentVersion
+		}
+		if component.Version == "" {
+			component.Version = dep.Version.Required
+		}
+
+		var installer Installer
+		if platformConfig, err := m.resolvedPlatformConfig(dep); err == nil {
+			installer = platformConfig.Installer
+			if component.DownloadURL == "" && !strings.Contains(installer.URL, "{{") {
+				component.DownloadURL = installer.URL
+			}
+			if component.Checksum == "" {
+				component.Checksum = installer.Checksum
+			}
+		}
+
+		component.DownloadURL = redactURL(component.DownloadURL)
+		component.Purl = packageURL(dep.Name, component.Version, installer, component.DownloadURL)
+		if license := m.dependencyLicense(ctx, dep, lock); !isUnknownLicense(license) {
+			component.License = license
+		}
+
+		doc.Components = append(doc.Components, component)
+	}
+
+	if format == SBOMSPDX {
+		return sbom.WriteSPDX(w, doc)
+	}
+	return sbom.WriteCycloneDX(w, doc)
+}
+
+// lockEntry returns the locked entry for a dependency in a possibly missing lockfile
+func lockEntry(lock *Lockfile, name string) *LockedDependency {
+	if lock == nil {
+		return nil
+	}
+	return lock.Find(name)
+}
+
+// packageURL returns the package URL of a dependency: pkg:github for GitHub
+// releases and pkg:generic, with the download URL if known, otherwise
+func packageURL(name, version string, installer Installer, downloadURL string) string {
+	if installer.Repo != "" && strings.EqualFold(installer.Method, "github-release") {
+		purl := "pkg:github/" + strings.ToLower(installer.Repo)
+		if version != "" {
+			purl += "@" + url.PathEscape(version)
+		}
+		return purl
+	}
+
+	purl := "pkg:generic/" + url.PathEscape(name)
+	if version != "" {
+		purl += "@" + url.PathEscape(version)
+	}
+	if downloadURL != "" {
+		purl += "?download_url=" + url.QueryEscape(downloadURL)
+	}
+	return purl
+}
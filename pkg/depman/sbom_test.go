@@ -0,0 +1,117 @@
+package depman
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/devnadeemashraf/depman/internal/github"
+)
+
+func TestWriteSBOM(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/owner/tool/license" {
+			http.NotFound(w, r)
+			return
+		}
+		io.WriteString(w, `{"license": {"spdx_id": "Apache-2.0"}}`)
+	}))
+	defer server.Close()
+
+	originalURL := github.APIURL
+	github.APIURL = server.URL
+	defer func() { github.APIURL = originalURL }()
+
+	tempDir, err := os.MkdirTemp("", "depman-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	configPath := filepath.Join(tempDir, "app-dependencies.yml")
+	config := `
+name: "Test App"
+dependencies:
+  - name: "tool"
+    version:
+      constraint: "^2.0"
+    platforms:
+      linux:
+        installer: {method: "github-release", repo: "owner/tool"}
+  - name: "jq"
+    license: "MIT"
+    version:
+      required: "1.7.1"
+    platforms:
+      linux:
+        installer:
+          url: "https://example.com/jq"
+          checksum: "sha256:abcd"
+`
+	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	lock := &Lockfile{Dependencies: []LockedDependency{
+		{Name: "tool", Version: "2.3.0", Platforms: map[string]LockedArtifact{
+			"linux": {URL: "https://github.com/owner/tool/releases/download/v2.3.0/tool.tar.gz", Checksum: "sha256:1234"},
+		}},
+		{Name: "jq", Version: "1.7.1"},
+	}}
+	if err := lock.Save(filepath.Join(tempDir, LockfileName)); err != nil {
+		t.Fatalf("Failed to write lockfile: %v", err)
+	}
+
+	manager, err := NewManager(configPath, WithPlatform("linux"), WithHomeDir(tempDir), WithLogOutput(io.Discard))
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := manager.WriteSBOM(context.Background(), &buf, SBOMCycloneDX); err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+
+	var bom struct {
+		Components []struct {
+			Name     string `json:"name"`
+			Version  string `json:"version"`
+			Purl     string `json:"purl"`
+			Licenses []struct {
+				Expression string `json:"expression"`
+			} `json:"licenses"`
+			Hashes []struct {
+				Content string `json:"content"`
+			} `json:"hashes"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &bom); err != nil {
+		t.Fatalf("Failed to parse SBOM: %v", err)
+	}
+	if len(bom.Components) != 2 {
+		t.Fatalf("Expected 2 components but got %s", buf.String())
+	}
+
+	tool, jq := bom.Components[0], bom.Components[1]
+	if tool.Version != "2.3.0" || tool.Purl != "pkg:github/owner/tool@2.3.0" {
+		t.Errorf("Expected the locked version of tool but got %+v", tool)
+	}
+	if len(tool.Licenses) != 1 || tool.Licenses[0].Expression != "Apache-2.0" {
+		t.Errorf("Expected the repository license of tool but got %+v", tool.Licenses)
+	}
+	if len(tool.Hashes) != 1 || tool.Hashes[0].Content != "1234" {
+		t.Errorf("Expected the locked checksum of tool but got %+v", tool.Hashes)
+	}
+	if len(jq.Licenses) != 1 || jq.Licenses[0].Expression != "MIT" || len(jq.Hashes) != 1 || jq.Hashes[0].Content != "abcd" {
+		t.Errorf("Expected the configured license and checksum of jq but got %+v", jq)
+	}
+	if jq.Purl != "pkg:generic/jq@1.7.1?download_url=https%3A%2F%2Fexample.com%2Fjq" {
+		t.Errorf("Expected a generic purl with the download URL but got %s", jq.Purl)
+	}
+}
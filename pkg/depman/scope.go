@@ -0,0 +1,88 @@
+package depman
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Scope selects where download-based installers place tools
+type Scope string
+
+const (
+	ScopeGlobal  Scope = "global"  // Install into the user's depman directory (default)
+	ScopeProject Scope = "project" // Install into .depman/ next to the configuration file
+)
+
+// projectDirName is the directory holding project-scoped installs
+const projectDirName = ".depman"
+
+// ParseScope parses an install scope name
+func ParseScope(name string) (Scope, error) {
+	switch scope := Scope(strings.ToLower(name)); scope {
+	case ScopeGlobal, ScopeProject:
+		return scope, nil
+	default:
+		return "", fmt.Errorf("invalid scope '%s' (expected project or global)", name)
+	}
+}
+
+// WithScope sets where download-based installers place tools, overriding the
+// scope named in the configuration
+func WithScope(scope Scope) Option {
+	return func(m *Manager) {
+		m.scope = scope
+	}
+}
+
+// Scope returns the install scope in effect
+func (m *Manager) Scope() Scope {
+	if m.scope != "" {
+		return m.scope
+	}
+	if m.Config != nil && m.Config.Scope != "" {
+		return Scope(m.Config.Scope)
+	}
+	return ScopeGlobal
+}
+
+// ProjectDir returns the directory holding project-scoped installs
+func (m *Manager) ProjectDir() string {
+	return filepath.Join(m.configDir(), projectDirName)
+}
+
+// configDir returns the directory project files such as the lockfile live
+// in: next to the configuration, or the working directory if the
+// configuration is remote or not loaded from a file
+func (m *Manager) configDir() string {
+	if m.ConfigPath == "" || m.ConfigSource != "" {
+		dir, _ := os.Getwd()
+		return dir
+	}
+	return filepath.Dir(m.ConfigPath)
+}
+
+// globalHomeDir returns the user-level root directory for files managed by depman
+func (m *Manager) globalHomeDir() string {
+	if m.homeDir == "" {
+		return defaultHomeDir()
+	}
+	return m.homeDir
+}
+
+// binDirs returns every bin directory searched for managed tools, project-scoped
+// ones first so they take precedence over global installs
+func (m *Manager) binDirs() []string {
+	dirs := []string{filepath.Join(m.ProjectDir(), "bin"), m.BinDir(), filepath.Join(m.globalHomeDir(), "bin")}
+
+	var unique []string
+	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		if !seen[dir] {
+			seen[dir] = true
+			unique = append(unique, dir)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,87 @@
+package depman
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestScopeDirectories(t *testing.T) {
+	root, err := os.MkdirTemp("", "depman-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	projectDir := filepath.Join(root, "repo")
+	globalDir := filepath.Join(root, "home")
+
+	testCases := []struct {
+		name        string
+		option      Scope
+		config      string
+		expectedDir string
+	}{
+		{"Default is global", "", "", globalDir},
+		{"Scope from configuration", "", "project", filepath.Join(projectDir, ".depman")},
+		{"Option overrides configuration", ScopeGlobal, "project", globalDir},
+		{"Project option", ScopeProject, "", filepath.Join(projectDir, ".depman")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			manager := &Manager{
+				Config:     &DependencyConfig{Scope: tc.config},
+				ConfigPath: filepath.Join(projectDir, "app-dependencies.yml"),
+				homeDir:    globalDir,
+				scope:      tc.option,
+			}
+			if got := manager.HomeDir(); got != tc.expectedDir {
+				t.Errorf("Expected home directory %s but got %s", tc.expectedDir, got)
+			}
+			if got := manager.PluginDir(); got != filepath.Join(globalDir, "plugins") {
+				t.Errorf("Expected plugins to stay global but got %s", got)
+			}
+		})
+	}
+}
+
+func TestResolveExecutablePrefersProjectScope(t *testing.T) {
+	root, err := os.MkdirTemp("", "depman-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	manager := &Manager{
+		Platform:   "linux",
+		Config:     &DependencyConfig{},
+		ConfigPath: filepath.Join(root, "repo", "app-dependencies.yml"),
+		homeDir:    filepath.Join(root, "home"),
+	}
+
+	global := filepath.Join(root, "home", "bin", "tool")
+	if err := installBinaryContent(global, "#!/bin/sh\n"); err != nil {
+		t.Fatalf("Failed to write binary: %v", err)
+	}
+	if got := manager.resolveExecutable("tool"); got != global {
+		t.Errorf("Expected global binary %s but got %s", global, got)
+	}
+
+	project := filepath.Join(root, "repo", ".depman", "bin", "tool")
+	if err := installBinaryContent(project, "#!/bin/sh\n"); err != nil {
+		t.Fatalf("Failed to write binary: %v", err)
+	}
+	if got := manager.resolveExecutable("tool"); got != project {
+		t.Errorf("Expected project binary %s but got %s", project, got)
+	}
+}
+
+func TestParseScope(t *testing.T) {
+	if scope, err := ParseScope("Project"); err != nil || scope != ScopeProject {
+		t.Errorf("Expected project scope but got %s (%v)", scope, err)
+	}
+	if _, err := ParseScope("system"); err == nil {
+		t.Errorf("Expected an error but got none")
+	}
+}
@@ -0,0 +1,63 @@
+package depman
+
+import (
+	"context"
+	"sort"
+)
+
+// SearchResult is a registry recipe matching a search
+type SearchResult struct {
+	Name        string   `json:"name"`                  // Name of the tool
+	Registry    string   `json:"registry"`              // Registry the recipe is in
+	From        string   `json:"from"`                  // Reference that uses the recipe, e.g. "registry/jq"
+	Description string   `json:"description,omitempty"` // Description of the tool
+	Constraint  string   `json:"constraint,omitempty"`  // Default version constraint of the recipe
+	Platforms   []string `json:"platforms"`             // Platforms the recipe has installers for
+	Tags        []string `json:"tags,omitempty"`        // Tags of the recipe
+	Versions    []string `json:"versions,omitempty"`    // Newest versions the installer for this platform offers, newest first
+	Error       string   `json:"error,omitempty"`       // Why the versions could not be listed
+}
+
+// SearchRecipes searches every registry for recipes whose name, description
+// or tags contain term. For each match, up to versions of the newest
+// versions its installer for the manager's platform offers are listed; with
+// versions 0, nothing is fetched.
+func (m *Manager) SearchRecipes(ctx context.Context, term string, versions int) ([]*SearchResult, error) {
+	registry, err := LoadRegistry(m.globalHomeDir())
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*SearchResult
+	for _, match := range registry.Search(term) {
+		recipe := match.Recipe
+		result := &SearchResult{
+			Name:        recipe.Name,
+			Registry:    match.Registry,
+			From:        match.From(),
+			Description: recipe.Description,
+			Constraint:  recipe.Version.Constraint,
+			Tags:        recipe.Tags,
+		}
+		for platform := range recipe.Platforms {
+			result.Platforms = append(result.Platforms, platform)
+		}
+		sort.Strings(result.Platforms)
+		results = append(results, result)
+
+		if versions <= 0 {
+			continue
+		}
+		available, err := m.sourceVersions(ctx, &recipe)
+		if err != nil {
+			result.Error = err.Error()
+			continue
+		}
+		sortVersionsNewestFirst(available)
+		if len(available) > versions {
+			available = available[:versions]
+		}
+		result.Versions = available
+	}
+	return results, nil
+}
@@ -0,0 +1,73 @@
+package depman
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/devnadeemashraf/depman/internal/github"
+)
+
+func TestSearchRecipes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]github.Release{
+			{TagName: "jq-1.6"},
+			{TagName: "jq-1.8.1"},
+			{TagName: "jq-1.7.1"},
+			{TagName: "jq-1.9.0-rc1", Prerelease: true},
+		})
+	}))
+	defer server.Close()
+
+	originalURL := github.APIURL
+	github.APIURL = server.URL
+	defer func() { github.APIURL = originalURL }()
+
+	home := t.TempDir()
+	if err := AddRegistrySource(home, RegistrySource{Name: "acme", URL: "https://tools.acme.internal/registry.yml", Priority: 10}); err != nil {
+		t.Fatal(err)
+	}
+	writeFetchedRegistry(t, home, "acme", acmeRegistry)
+
+	manager, err := NewManager("", WithConfig(NewConfig("search")), WithPlatform("linux"), WithHomeDir(home), WithLogOutput(io.Discard))
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+
+	results, err := manager.SearchRecipes(context.Background(), "JSON", 2)
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("Expected only the public jq recipe to match by its tag but got %d results", len(results))
+	}
+	jq := results[0]
+	if jq.From != "depman/jq" || jq.Registry != "depman" {
+		t.Errorf("Expected the public jq recipe, shadowed by acme's, but got %s from %s", jq.From, jq.Registry)
+	}
+	if strings.Join(jq.Platforms, ",") != "darwin,linux,windows" {
+		t.Errorf("Expected the recipe's platforms but got %v", jq.Platforms)
+	}
+	if strings.Join(jq.Versions, ",") != "1.8.1,1.7.1" || jq.Error != "" {
+		t.Errorf("Expected the two newest releases but got %v (%s)", jq.Versions, jq.Error)
+	}
+
+	results, err = manager.SearchRecipes(context.Background(), "acme", 1)
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	var froms []string
+	for _, result := range results {
+		froms = append(froms, result.From)
+		if result.Error == "" {
+			t.Errorf("Expected the archive recipe %s not to list versions", result.From)
+		}
+	}
+	if strings.Join(froms, ",") != "registry/deployer,registry/jq" {
+		t.Errorf("Expected acme's recipes but got %v", froms)
+	}
+}
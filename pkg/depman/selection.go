@@ -0,0 +1,109 @@
+package depman
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Selection narrows check, ensure and list to part of the configuration.
+// Selected dependencies always bring along everything they depend on, even
+// when it is skipped or belongs to another tag.
+type Selection struct {
+	Only []string // Dependencies to select by name (all if empty and no Tags)
+	Tags []string // Select dependencies carrying any of these tags
+	Skip []string // Leave out dependencies with these names or tags
+}
+
+// empty reports whether the selection includes every dependency
+func (s Selection) empty() bool {
+	return len(s.Only) == 0 && len(s.Tags) == 0 && len(s.Skip) == 0
+}
+
+// WithSelection limits check, ensure and list to the selected dependencies
+func WithSelection(selection Selection) Option {
+	return func(m *Manager) {
+		m.selection = selection
+	}
+}
+
+// HasTag reports whether the dependency carries a tag
+func (d *Dependency) HasTag(tag string) bool {
+	for _, t := range d.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// SelectedDependencies returns the dependencies the selection includes, with
+// their transitive dependencies, in configuration order
+func (m *Manager) SelectedDependencies() ([]*Dependency, error) {
+	all := make([]*Dependency, len(m.Config.Dependencies))
+	for i := range m.Config.Dependencies {
+		all[i] = &m.Config.Dependencies[i]
+	}
+	if m.selection.empty() {
+		return all, nil
+	}
+
+	roots, err := m.selectionRoots()
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := m.resolveInstallOrder(roots)
+	if err != nil {
+		return nil, err
+	}
+	included := make(map[string]bool, len(order))
+	for _, dep := range order {
+		included[dep.Name] = true
+	}
+
+	var selected []*Dependency
+	isRoot := make(map[string]bool, len(roots))
+	for _, name := range roots {
+		isRoot[name] = true
+	}
+	for _, dep := range all {
+		if !included[dep.Name] {
+			continue
+		}
+		if !isRoot[dep.Name] {
+			m.logger.Debugf("Including %s because a selected dependency requires it", dep.Name)
+		}
+		selected = append(selected, dep)
+	}
+	return selected, nil
+}
+
+// selectionRoots returns the names of the dependencies the selection picks
+// itself, before what they depend on is added
+func (m *Manager) selectionRoots() ([]string, error) {
+	for _, name := range m.selection.Only {
+		if m.FindDependency(name) == nil {
+			return nil, fmt.Errorf("dependency '%s' not found in configuration", name)
+		}
+	}
+
+	var roots []string
+	for _, dep := range m.Config.Dependencies {
+		selected := len(m.selection.Only) == 0 && len(m.selection.Tags) == 0
+		for _, name := range m.selection.Only {
+			selected = selected || dep.Name == name
+		}
+		for _, tag := range m.selection.Tags {
+			selected = selected || dep.HasTag(tag)
+		}
+		for _, skip := range m.selection.Skip {
+			if dep.Name == skip || dep.HasTag(skip) {
+				selected = false
+			}
+		}
+		if selected {
+			roots = append(roots, dep.Name)
+		}
+	}
+	return roots, nil
+}
@@ -0,0 +1,81 @@
+package depman
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestSelectedDependencies tests filtering by name and tag while keeping transitive requirements
+func TestSelectedDependencies(t *testing.T) {
+	config := &DependencyConfig{
+		Dependencies: []Dependency{
+			{Name: "runtime"},
+			{Name: "compiler", Tags: []string{"build"}, Dependencies: []string{"runtime"}},
+			{Name: "linter", Tags: []string{"test", "build"}},
+			{Name: "docgen", Tags: []string{"docs"}, Dependencies: []string{"runtime"}},
+			{Name: "jq"},
+		},
+	}
+
+	testCases := []struct {
+		name      string
+		selection Selection
+		expected  []string
+		expectErr bool
+	}{
+		{
+			name:     "Everything by default",
+			expected: []string{"runtime", "compiler", "linter", "docgen", "jq"},
+		},
+		{
+			name:      "Only by name with requirements",
+			selection: Selection{Only: []string{"compiler"}},
+			expected:  []string{"runtime", "compiler"},
+		},
+		{
+			name:      "By tag",
+			selection: Selection{Tags: []string{"BUILD"}},
+			expected:  []string{"runtime", "compiler", "linter"},
+		},
+		{
+			name:      "Skip by tag",
+			selection: Selection{Skip: []string{"docs"}},
+			expected:  []string{"runtime", "compiler", "linter", "jq"},
+		},
+		{
+			name:      "Skipped requirement is kept",
+			selection: Selection{Tags: []string{"docs"}, Skip: []string{"runtime"}},
+			expected:  []string{"runtime", "docgen"},
+		},
+		{
+			name:      "Unknown name",
+			selection: Selection{Only: []string{"missing"}},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			manager := &Manager{Config: config, logger: &mockLogger{}, selection: tc.selection}
+
+			deps, err := manager.SelectedDependencies()
+			if tc.expectErr {
+				if err == nil {
+					t.Errorf("Expected an error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Did not expect an error but got: %v", err)
+			}
+
+			var names []string
+			for _, dep := range deps {
+				names = append(names, dep.Name)
+			}
+			if strings.Join(names, ",") != strings.Join(tc.expected, ",") {
+				t.Errorf("Expected %v but got %v", tc.expected, names)
+			}
+		})
+	}
+}
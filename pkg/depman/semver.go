@@ -0,0 +1,114 @@
+package depman
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// ParseConstraint parses a semver range such as ">=1.2 <2.0", "~1.4",
+// "^2.3.1", "1.x" or "1.2 - 1.4 || ^2". Space- or comma-separated
+// comparisons must all hold; "||" separates alternatives.
+func ParseConstraint(constraint string) (*semver.Constraints, error) {
+	c, err := semver.NewConstraint(normalizeConstraint(constraint))
+	if err != nil {
+		return nil, fmt.Errorf("invalid constraint '%s': %w", constraint, err)
+	}
+	return c, nil
+}
+
+// constraintOperator matches a comparison operator followed by whitespace
+var constraintOperator = regexp.MustCompile(`(>=|<=|!=|==|=|>|<|~>|~|\^)\s+`)
+
+// normalizeConstraint rewrites common spellings the semver library does not
+// accept, such as "==1.2" or ">= 1.2 && < 2"
+func normalizeConstraint(constraint string) string {
+	constraint = strings.TrimSpace(constraint)
+	constraint = strings.ReplaceAll(constraint, "&&", " ")
+	constraint = constraintOperator.ReplaceAllString(constraint, "$1")
+	constraint = strings.ReplaceAll(constraint, "==", "=")
+	constraint = strings.ReplaceAll(constraint, "~>", "~")
+	return strings.Join(strings.Fields(constraint), " ")
+}
+
+// Satisfied reports whether an installed version meets the constraint.
+// Without a constraint every version is acceptable.
+func (v Version) Satisfied(current string) (bool, error) {
+	if v.Constraint == "" {
+		return true, nil
+	}
+	return IsVersionCompatible(current, v.Constraint)
+}
+
+// Classify returns the kind of update needed to move an installed version to
+// the required version. Without a required version, a version satisfying the
+// constraint needs no update.
+func (v Version) Classify(current string) (UpdateType, error) {
+	if v.Required == "" {
+		return NoUpdate, nil
+	}
+	return CheckVersionUpdate(current, v.Required)
+}
+
+// Target returns a description of the version that will be installed
+func (v Version) Target() string {
+	if v.Required != "" {
+		return v.Required
+	}
+	return v.Constraint
+}
+
+// Resolve picks the version to install from the available candidates: the
+// exact required version if it is available, otherwise the newest candidate
+// satisfying the constraint. Without either, the newest candidate is chosen.
+// The returned index refers to the chosen candidate.
+func (v Version) Resolve(candidates []string) (int, *semver.Version, error) {
+	var constraint *semver.Constraints
+	if v.Constraint != "" {
+		c, err := ParseConstraint(v.Constraint)
+		if err != nil {
+			return -1, nil, err
+		}
+		constraint = c
+	}
+
+	var exact *semver.Version
+	if v.Required != "" {
+		if parsed, err := semver.NewVersion(v.Required); err == nil {
+			exact = parsed
+		}
+	}
+
+	best := -1
+	var bestVersion *semver.Version
+	for i, candidate := range candidates {
+		parsed, err := semver.NewVersion(candidate)
+		if err != nil {
+			continue
+		}
+
+		if exact != nil && parsed.Equal(exact) {
+			return i, parsed, nil
+		}
+		if constraint != nil && !constraint.Check(parsed) {
+			continue
+		}
+		if exact != nil && constraint == nil {
+			continue
+		}
+		if bestVersion == nil || parsed.GreaterThan(bestVersion) {
+			best, bestVersion = i, parsed
+		}
+	}
+
+	if best < 0 {
+		if exact != nil && constraint == nil {
+			return -1, nil, fmt.Errorf("version %s is not available", v.Required)
+		}
+		return -1, nil, fmt.Errorf("no available version satisfies the version requirements")
+	}
+
+	return best, bestVersion, nil
+}
@@ -0,0 +1,317 @@
+package depman
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/devnadeemashraf/depman/internal/httpclient"
+	"gopkg.in/yaml.v3"
+)
+
+// settingsFileNames are the names the settings file is looked for under, in order
+var settingsFileNames = []string{"config.yaml", "config.yml", "config.toml"}
+
+// Settings are machine- or user-level defaults kept outside any project, in
+// ~/.config/depman/config.yaml or config.toml. Flags and options override
+// them, and the credentials of a project configuration are layered on top of
+// theirs.
+type Settings struct {
+	HomeDir     string           `yaml:"home_dir,omitempty"`    // Root directory for files managed by depman (default ~/.depman)
+	CacheDir    string           `yaml:"cache_dir,omitempty"`   // Directory of the download cache (default <home_dir>/cache)
+	Proxy       string           `yaml:"proxy,omitempty"`       // Proxy for every request, instead of HTTPS_PROXY and HTTP_PROXY
+	Jobs        int              `yaml:"jobs,omitempty"`        // Dependencies to check or install in parallel (default: number of CPUs)
+	PolicyFile  string           `yaml:"policy_file,omitempty"` // Organization policy file instead of the machine-wide one
+	Credentials []Credential     `yaml:"credentials,omitempty"` // How to authenticate to artifact hosts, under the configuration's
+	Registries  []RegistrySource `yaml:"registries,omitempty"`  // Recipe registries besides those added with 'depman registry add'
+}
+
+// SettingKeys are the keys of the settings file, in the order they are listed
+var SettingKeys = []string{"home_dir", "cache_dir", "proxy", "jobs", "policy_file", "credentials", "registries"}
+
+// WithSettings uses settings instead of the user's settings file
+func WithSettings(settings Settings) Option {
+	return func(m *Manager) {
+		m.settings = &settings
+	}
+}
+
+// SettingsPath returns the settings file: the first of config.yaml,
+// config.yml and config.toml that exists in $XDG_CONFIG_HOME/depman
+// (~/.config/depman by default, %AppData%\depman on Windows), else where
+// config.yaml would be
+func SettingsPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" && runtime.GOOS == "windows" {
+		dir, _ = os.UserConfigDir()
+	}
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			home = os.TempDir()
+		}
+		dir = filepath.Join(home, ".config")
+	}
+
+	dir = filepath.Join(dir, "depman")
+	for _, name := range settingsFileNames {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return filepath.Join(dir, name)
+		}
+	}
+	return filepath.Join(dir, settingsFileNames[0])
+}
+
+// LoadSettings reads a settings file, which may not exist. Unknown keys are
+// errors so a misspelled setting is not silently ignored.
+func LoadSettings(path string) (*Settings, error) {
+	settings := &Settings{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return settings, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read settings: %w", err)
+	}
+
+	data, err = toYAML(data, DetectConfigFormat(path, data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid settings file %s: %w", path, err)
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(settings); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid settings file %s: %w", path, err)
+	}
+	if err := settings.validate(); err != nil {
+		return nil, fmt.Errorf("invalid settings file %s: %w", path, err)
+	}
+	return settings, nil
+}
+
+// SaveSettings writes settings to path, as TOML if it ends in .toml and as
+// YAML otherwise
+func SaveSettings(path string, settings *Settings) error {
+	if err := settings.validate(); err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(settings)
+	if err != nil {
+		return fmt.Errorf("failed to encode settings: %w", err)
+	}
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		var doc map[string]interface{}
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("failed to encode settings: %w", err)
+		}
+		var buf bytes.Buffer
+		if err := toml.NewEncoder(&buf).Encode(doc); err != nil {
+			return fmt.Errorf("failed to encode settings: %w", err)
+		}
+		data = buf.Bytes()
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to write settings: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write settings: %w", err)
+	}
+	return nil
+}
+
+// validate checks the values of the settings
+func (s *Settings) validate() error {
+	if s.Jobs < 0 {
+		return fmt.Errorf("jobs: expected a positive number but got %d", s.Jobs)
+	}
+	if s.Proxy != "" {
+		if u, err := url.Parse(s.Proxy); err != nil || u.Host == "" {
+			return fmt.Errorf("proxy: invalid URL '%s'", s.Proxy)
+		}
+	}
+	for i, cred := range s.Credentials {
+		if cred.Host == "" {
+			return fmt.Errorf("credentials[%d]: missing required field 'host'", i)
+		}
+	}
+	for i, source := range s.Registries {
+		if !registryName.MatchString(source.Name) || source.Name == anyRegistry {
+			return fmt.Errorf("registries[%d]: invalid registry name '%s'", i, source.Name)
+		}
+		if !IsRemoteConfig(source.URL) {
+			return fmt.Errorf("registries[%d]: invalid registry URL '%s'", i, source.URL)
+		}
+	}
+	return nil
+}
+
+// Get returns the value of a setting: scalars as they are and lists as a
+// single line of flow-style YAML, or "" if it is not set
+func (s *Settings) Get(key string) (string, error) {
+	switch key {
+	case "home_dir":
+		return s.HomeDir, nil
+	case "cache_dir":
+		return s.CacheDir, nil
+	case "proxy":
+		return s.Proxy, nil
+	case "jobs":
+		if s.Jobs == 0 {
+			return "", nil
+		}
+		return strconv.Itoa(s.Jobs), nil
+	case "policy_file":
+		return s.PolicyFile, nil
+	case "credentials":
+		if len(s.Credentials) == 0 {
+			return "", nil
+		}
+		return encodeFlow(s.Credentials)
+	case "registries":
+		if len(s.Registries) == 0 {
+			return "", nil
+		}
+		return encodeFlow(s.Registries)
+	}
+	return "", unknownSettingError(key)
+}
+
+// Set changes a setting from a value in the form Get returns; an empty value
+// removes it
+func (s *Settings) Set(key, value string) error {
+	updated := *s
+	switch key {
+	case "home_dir":
+		updated.HomeDir = value
+	case "cache_dir":
+		updated.CacheDir = value
+	case "proxy":
+		updated.Proxy = value
+	case "jobs":
+		updated.Jobs = 0
+		if value != "" {
+			jobs, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("jobs: expected a number but got '%s'", value)
+			}
+			updated.Jobs = jobs
+		}
+	case "policy_file":
+		updated.PolicyFile = value
+	case "credentials":
+		updated.Credentials = nil
+		if err := yaml.Unmarshal([]byte(value), &updated.Credentials); err != nil {
+			return fmt.Errorf("credentials: expected a YAML list, e.g. [{host: artifacts.example.com, type: bearer}]: %w", err)
+		}
+	case "registries":
+		updated.Registries = nil
+		if err := yaml.Unmarshal([]byte(value), &updated.Registries); err != nil {
+			return fmt.Errorf("registries: expected a YAML list, e.g. [{name: acme, url: https://example.com/registry.yml}]: %w", err)
+		}
+	default:
+		return unknownSettingError(key)
+	}
+
+	if err := updated.validate(); err != nil {
+		return err
+	}
+	*s = updated
+	return nil
+}
+
+// unknownSettingError reports a key that is not a setting
+func unknownSettingError(key string) error {
+	return fmt.Errorf("unknown setting '%s' (settings: %s)", key, strings.Join(SettingKeys, ", "))
+}
+
+// encodeFlow encodes v as a single line of flow-style YAML
+func encodeFlow(v interface{}) (string, error) {
+	var node yaml.Node
+	if err := node.Encode(v); err != nil {
+		return "", err
+	}
+	pruneEmpty(&node)
+	setFlowStyle(&node)
+	data, err := yaml.Marshal(&node)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// setFlowStyle marks every collection under node as flow style
+func setFlowStyle(node *yaml.Node) {
+	if node.Kind == yaml.MappingNode || node.Kind == yaml.SequenceNode {
+		node.Style = yaml.FlowStyle
+	}
+	for _, child := range node.Content {
+		setFlowStyle(child)
+	}
+}
+
+// userSettings returns the user's settings, or empty settings if the file
+// cannot be read, for places that have no way to report the error
+func userSettings() *Settings {
+	settings, err := LoadSettings(SettingsPath())
+	if err != nil {
+		return &Settings{}
+	}
+	return settings
+}
+
+// loadSettings loads the user's settings unless WithSettings set some, and
+// applies them where no option did
+func (m *Manager) loadSettings() error {
+	if m.settings == nil {
+		settings, err := LoadSettings(SettingsPath())
+		if err != nil {
+			return err
+		}
+		m.settings = settings
+	}
+
+	// $DEPMAN_HOME relocates everything, the cache included
+	s := m.settings
+	envHome := envHomeDir()
+	if m.homeDir == "" && envHome != "" {
+		m.homeDir = envHome
+	} else if m.homeDir == "" && s.HomeDir != "" {
+		m.homeDir = expandSettingsPath(s.HomeDir)
+	}
+	if m.cacheDir == "" && s.CacheDir != "" && envHome == "" {
+		m.cacheDir = expandSettingsPath(s.CacheDir)
+	}
+	if m.concurrency == 0 {
+		m.concurrency = s.Jobs
+	}
+	if m.orgPolicy == nil && m.orgPolicyPath == "" && s.PolicyFile != "" {
+		m.orgPolicyPath = expandSettingsPath(s.PolicyFile)
+	}
+	if m.httpClient == nil && s.Proxy != "" {
+		client, err := httpclient.New(httpclient.Options{Proxy: s.Proxy})
+		if err != nil {
+			return err
+		}
+		m.httpClient = client
+	}
+	return nil
+}
+
+// expandSettingsPath expands a leading ~ in a path from the settings file
+func expandSettingsPath(path string) string {
+	if strings.HasPrefix(path, "~/") || path == "~" {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, strings.TrimPrefix(path[1:], "/"))
+		}
+	}
+	return path
+}
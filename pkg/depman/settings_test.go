@@ -0,0 +1,202 @@
+package depman
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSettingsPath(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	if path := SettingsPath(); path != filepath.Join(dir, "depman", "config.yaml") {
+		t.Errorf("Expected config.yaml by default but got %s", path)
+	}
+
+	os.MkdirAll(filepath.Join(dir, "depman"), 0755)
+	os.WriteFile(filepath.Join(dir, "depman", "config.toml"), []byte("jobs = 2\n"), 0644)
+	if path := SettingsPath(); path != filepath.Join(dir, "depman", "config.toml") {
+		t.Errorf("Expected the existing config.toml but got %s", path)
+	}
+}
+
+func TestLoadSettings(t *testing.T) {
+	dir := t.TempDir()
+
+	settings, err := LoadSettings(filepath.Join(dir, "missing.yaml"))
+	if err != nil || settings.Jobs != 0 {
+		t.Errorf("Expected empty settings for a missing file but got %+v, %v", settings, err)
+	}
+
+	tomlPath := filepath.Join(dir, "config.toml")
+	os.WriteFile(tomlPath, []byte("jobs = 3\nproxy = \"http://proxy.example.com:3128\"\n\n[[credentials]]\nhost = \"artifacts.example.com\"\ntype = \"bearer\"\n"), 0644)
+	settings, err = LoadSettings(tomlPath)
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if settings.Jobs != 3 || settings.Proxy != "http://proxy.example.com:3128" || len(settings.Credentials) != 1 {
+		t.Errorf("Unexpected settings: %+v", settings)
+	}
+
+	// Misspelled settings must not be ignored
+	yamlPath := filepath.Join(dir, "config.yaml")
+	os.WriteFile(yamlPath, []byte("cachedir: /tmp/cache\n"), 0644)
+	if _, err := LoadSettings(yamlPath); err == nil {
+		t.Error("Expected an error for an unknown setting but got none")
+	}
+
+	os.WriteFile(yamlPath, []byte("jobs: -1\n"), 0644)
+	if _, err := LoadSettings(yamlPath); err == nil {
+		t.Error("Expected an error for negative jobs but got none")
+	}
+}
+
+func TestSettingsGetSet(t *testing.T) {
+	testCases := []struct {
+		name        string
+		key         string
+		value       string
+		expected    string
+		expectError bool
+	}{
+		{name: "Scalar", key: "cache_dir", value: "/srv/depman-cache", expected: "/srv/depman-cache"},
+		{name: "Number", key: "jobs", value: "4", expected: "4"},
+		{name: "List", key: "registries", value: "[{name: acme, url: 'https://example.com/registry.yml', priority: 10}]", expected: "[{name: acme, url: 'https://example.com/registry.yml', priority: 10}]"},
+		{name: "Cleared", key: "proxy", value: "", expected: ""},
+		{name: "Not a number", key: "jobs", value: "many", expectError: true},
+		{name: "Invalid proxy", key: "proxy", value: "proxy", expectError: true},
+		{name: "Credential without host", key: "credentials", value: "[{type: bearer}]", expectError: true},
+		{name: "Unknown key", key: "cachedir", value: "/tmp", expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			settings := &Settings{Proxy: "http://proxy.example.com"}
+			err := settings.Set(tc.key, tc.value)
+			if tc.expectError {
+				if err == nil {
+					t.Error("Expected an error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Did not expect an error but got: %v", err)
+			}
+			value, err := settings.Get(tc.key)
+			if err != nil {
+				t.Fatalf("Did not expect an error but got: %v", err)
+			}
+			if value != tc.expected {
+				t.Errorf("Expected %q but got %q", tc.expected, value)
+			}
+		})
+	}
+}
+
+func TestSaveSettings(t *testing.T) {
+	dir := t.TempDir()
+	settings := &Settings{Jobs: 2, CacheDir: "/srv/cache", Credentials: []Credential{{Host: "artifacts.example.com", Type: "bearer"}}}
+
+	for _, name := range []string{"config.yaml", "config.toml"} {
+		path := filepath.Join(dir, name)
+		if err := SaveSettings(path, settings); err != nil {
+			t.Fatalf("Did not expect an error but got: %v", err)
+		}
+		loaded, err := LoadSettings(path)
+		if err != nil {
+			t.Fatalf("Did not expect an error reading %s but got: %v", name, err)
+		}
+		if loaded.Jobs != 2 || loaded.CacheDir != "/srv/cache" || len(loaded.Credentials) != 1 || loaded.Credentials[0].Host != "artifacts.example.com" {
+			t.Errorf("Expected the saved settings back from %s but got %+v", name, loaded)
+		}
+	}
+
+	data, _ := os.ReadFile(filepath.Join(dir, "config.toml"))
+	if !strings.Contains(string(data), "jobs = 2") {
+		t.Errorf("Expected TOML but got:\n%s", data)
+	}
+}
+
+func TestManagerSettings(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "deps.yml")
+	os.WriteFile(configPath, []byte(`version: "1.0"
+credentials:
+  - host: artifacts.example.com
+    type: basic
+`), 0644)
+
+	settings := Settings{
+		HomeDir:  filepath.Join(dir, "home"),
+		CacheDir: filepath.Join(dir, "cache"),
+		Jobs:     2,
+		Credentials: []Credential{
+			{Host: "artifacts.example.com", Type: "bearer"},
+			{Host: "other.example.com", Type: "bearer"},
+		},
+	}
+	manager, err := NewManager(configPath, WithSettings(settings), WithConcurrency(5), WithLogOutput(io.Discard))
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+
+	if manager.HomeDir() != settings.HomeDir {
+		t.Errorf("Expected home directory %s but got %s", settings.HomeDir, manager.HomeDir())
+	}
+	if manager.CacheDir() != settings.CacheDir {
+		t.Errorf("Expected cache directory %s but got %s", settings.CacheDir, manager.CacheDir())
+	}
+	if manager.concurrency != 5 {
+		t.Errorf("Expected the option to override jobs but got %d", manager.concurrency)
+	}
+
+	// The configuration's credentials win over the settings for the same host
+	provider := manager.credentialProvider().(*hostCredentials)
+	if provider.configs["artifacts.example.com"].Type != "basic" || provider.configs["other.example.com"].Type != "bearer" {
+		t.Errorf("Unexpected credentials: %+v", provider.configs)
+	}
+}
+
+func TestHomeEnvVar(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "deps.yml")
+	os.WriteFile(configPath, []byte(`version: "1.0"`), 0644)
+	home := filepath.Join(dir, "job-42")
+	t.Setenv(HomeEnvVar, home)
+
+	// $DEPMAN_HOME wins over the settings, the cache directory included
+	settings := Settings{HomeDir: filepath.Join(dir, "home"), CacheDir: filepath.Join(dir, "cache")}
+	manager, err := NewManager(configPath, WithSettings(settings), WithLogOutput(io.Discard))
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	for name, path := range map[string]string{
+		"home":    manager.HomeDir(),
+		"cache":   manager.CacheDir(),
+		"bin":     manager.BinDir(),
+		"tools":   manager.ToolsDir(),
+		"state":   manager.StatePath(),
+		"logs":    manager.LogsDir(),
+		"plugins": manager.PluginDir(),
+	} {
+		if !isWithin(path, home) {
+			t.Errorf("Expected the %s path %s to be under %s", name, path, home)
+		}
+	}
+	if DefaultCacheDir() != filepath.Join(home, "cache") || DefaultStatePath() != filepath.Join(home, stateFileName) {
+		t.Errorf("Expected the defaults to be under %s but got %s and %s", home, DefaultCacheDir(), DefaultStatePath())
+	}
+
+	// An explicit option still wins
+	other := filepath.Join(dir, "other")
+	manager, err = NewManager(configPath, WithHomeDir(other), WithSettings(Settings{}), WithLogOutput(io.Discard))
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if manager.HomeDir() != other {
+		t.Errorf("Expected home directory %s but got %s", other, manager.HomeDir())
+	}
+}
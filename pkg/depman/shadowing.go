@@ -0,0 +1,241 @@
+package depman
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Installation is one copy of a dependency's executable
+type Installation struct {
+	Path    string `json:"path"`              // Executable file
+	Version string `json:"version,omitempty"` // Version it reports, or "" if it could not be read
+	Managed bool   `json:"managed"`           // Whether it is in one of depman's bin directories
+}
+
+// PathAudit lists every copy of a dependency's executable that a shell or
+// depman could run
+type PathAudit struct {
+	Dependency    string         `json:"dependency"`        // Name of the dependency
+	Binary        string         `json:"binary"`            // Executable its verify command runs
+	Active        *Installation  `json:"active,omitempty"`  // First copy on PATH, which shells run
+	Checked       *Installation  `json:"checked,omitempty"` // Copy depman checks and runs: a managed one before any on PATH
+	Installations []Installation `json:"installations"`     // Every copy, managed ones first, then in PATH order
+	ManagedOnPath bool           `json:"managed_on_path"`   // Whether the checked managed copy's directory is on PATH
+}
+
+// Shadowed reports whether shells run a different copy than the one depman checked
+func (a *PathAudit) Shadowed() bool {
+	return a.Active != nil && a.Checked != nil && !sameFile(a.Active.Path, a.Checked.Path)
+}
+
+// Suggestions returns how to resolve the conflict, or nothing if there is none
+func (a *PathAudit) Suggestions() []string {
+	var steps []string
+	if a.Shadowed() {
+		if a.Checked.Managed {
+			dir := filepath.Dir(a.Checked.Path)
+			if a.ManagedOnPath {
+				steps = append(steps, fmt.Sprintf("Put %s before %s on PATH, e.g. by running 'eval \"$(depman env)\"' at the end of your shell profile", dir, filepath.Dir(a.Active.Path)))
+			} else {
+				steps = append(steps, fmt.Sprintf("Add %s to PATH with 'eval \"$(depman env)\"' in your shell profile, or 'depman hook'", dir))
+			}
+		}
+		if !a.Active.Managed {
+			steps = append(steps, fmt.Sprintf("Uninstall the copy at %s if nothing else needs it", a.Active.Path))
+		}
+		steps = append(steps, "Open a new shell, or run 'hash -r' (bash) or 'rehash' (zsh), so the shell forgets where it found the tool before")
+		return steps
+	}
+	for _, installation := range a.Installations {
+		if a.Active != nil && !sameFile(installation.Path, a.Active.Path) && !installation.Managed {
+			steps = append(steps, fmt.Sprintf("%s is hidden by %s; uninstall it if nothing else needs it", installation.Path, a.Active.Path))
+		}
+	}
+	return steps
+}
+
+// AuditPath finds every copy of each selected dependency's executable on
+// PATH and in the managed bin directories, with the version each reports,
+// and returns those with more than one copy in configuration order.
+// Dependencies whose verify command does not name an executable are skipped.
+func (m *Manager) AuditPath(ctx context.Context) ([]*PathAudit, error) {
+	deps, err := m.SelectedDependencies()
+	if err != nil {
+		return nil, err
+	}
+	audits := []*PathAudit{}
+	for _, dep := range deps {
+		audit := m.auditDependencyPath(dep)
+		if audit == nil || len(audit.Installations) < 2 {
+			continue
+		}
+		platformConfig, _ := m.GetPlatformConfig(dep)
+		for i := range audit.Installations {
+			audit.Installations[i].Version = m.versionAt(ctx, dep, platformConfig, audit.Installations[i].Path)
+		}
+		audit.Active = audit.find(audit.Active)
+		audit.Checked = audit.find(audit.Checked)
+		audits = append(audits, audit)
+	}
+	return audits, nil
+}
+
+// detectShadowing records in the status of an installed dependency the copy
+// of its executable that shells run instead of the managed one depman
+// checked, if there is one
+func (m *Manager) detectShadowing(ctx context.Context, dep *Dependency, status *DependencyStatus) {
+	audit := m.auditDependencyPath(dep)
+	if audit == nil || !audit.Shadowed() || !audit.Checked.Managed {
+		return
+	}
+	platformConfig, _ := m.GetPlatformConfig(dep)
+	shadow := *audit.Active
+	shadow.Version = m.versionAt(ctx, dep, platformConfig, shadow.Path)
+	status.ShadowedBy = &shadow
+	m.logger.Warnf("%s on PATH (version %s) shadows the managed %s that was checked", shadow.Path, orUnknown(shadow.Version), audit.Checked.Path)
+}
+
+// auditDependencyPath finds the copies of a dependency's executable without
+// reading their versions, or returns nil if it cannot be audited here
+func (m *Manager) auditDependencyPath(dep *Dependency) *PathAudit {
+	// Only executables of this machine can be found and run
+	if m.Platform != runtime.GOOS || m.environments(dep)[0] != WSLLinux {
+		return nil
+	}
+	platformConfig, err := m.GetPlatformConfig(dep)
+	if err != nil {
+		return nil
+	}
+	verifyCmd := platformConfig.Commands.Verify
+	if len(dep.VersionCommand) > 0 {
+		verifyCmd = dep.VersionCommand
+	}
+	if len(verifyCmd) == 0 || verifyCmd[0] == "" || strings.ContainsAny(verifyCmd[0], `/\{$`) {
+		return nil
+	}
+	binary := verifyCmd[0]
+
+	audit := &PathAudit{Dependency: dep.Name, Binary: binary}
+	managedDirs := make(map[string]bool)
+	for _, dir := range m.binDirs() {
+		managedDirs[filepath.Clean(dir)] = true
+		if path := m.executableIn(dir, binary); path != "" && audit.Checked == nil {
+			audit.add(Installation{Path: path, Managed: true})
+			audit.Checked = &Installation{Path: path, Managed: true}
+		}
+	}
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir == "" {
+			continue
+		}
+		path := m.executableIn(dir, binary)
+		if path == "" {
+			continue
+		}
+		installation := Installation{Path: path, Managed: managedDirs[filepath.Clean(dir)]}
+		if audit.Active == nil {
+			audit.Active = &installation
+		}
+		if audit.Checked != nil && sameFile(path, audit.Checked.Path) {
+			audit.ManagedOnPath = true
+		}
+		audit.add(installation)
+	}
+	if audit.Checked == nil {
+		audit.Checked = audit.Active
+	}
+	return audit
+}
+
+// add records a copy unless the same file was already found, e.g. through a
+// symlinked PATH entry
+func (a *PathAudit) add(installation Installation) {
+	for _, existing := range a.Installations {
+		if sameFile(existing.Path, installation.Path) {
+			return
+		}
+	}
+	a.Installations = append(a.Installations, installation)
+}
+
+// find returns the recorded copy that is the same file as installation
+func (a *PathAudit) find(installation *Installation) *Installation {
+	if installation == nil {
+		return nil
+	}
+	for i := range a.Installations {
+		if sameFile(a.Installations[i].Path, installation.Path) {
+			return &a.Installations[i]
+		}
+	}
+	return installation
+}
+
+// executableIn returns the path of an executable named name in dir, or ""
+func (m *Manager) executableIn(dir, name string) string {
+	candidates := []string{filepath.Join(dir, name)}
+	if m.Platform == "windows" && filepath.Ext(name) == "" {
+		candidates = []string{filepath.Join(dir, name+".exe"), filepath.Join(dir, name+".cmd"), filepath.Join(dir, name+".bat")}
+	}
+	for _, candidate := range candidates {
+		info, err := os.Stat(candidate)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		if m.Platform != "windows" && info.Mode()&0111 == 0 {
+			continue
+		}
+		return candidate
+	}
+	return ""
+}
+
+// versionAt runs a dependency's verify command with the executable at path
+// and returns the version it reports, or "" if it cannot be read
+func (m *Manager) versionAt(ctx context.Context, dep *Dependency, platformConfig *PlatformConfig, path string) string {
+	verifyCmd := platformConfig.Commands.Verify
+	if len(dep.VersionCommand) > 0 {
+		var err error
+		if verifyCmd, err = interpolated(m, dep.VersionCommand, dep); err != nil {
+			return ""
+		}
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	output, err := hostCommand(ctx, path, verifyCmd[1:]...).CombinedOutput()
+	if err != nil {
+		return ""
+	}
+	outputStr := strings.TrimSpace(string(output))
+	if dep.VersionRegex != "" {
+		version, err := extractVersionWith(dep.VersionRegex, outputStr)
+		if err != nil {
+			return ""
+		}
+		return version
+	}
+	return extractVersion(outputStr)
+}
+
+// sameFile reports whether two paths name the same file
+func sameFile(a, b string) bool {
+	if a == b {
+		return true
+	}
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	return errA == nil && errB == nil && os.SameFile(infoA, infoB)
+}
+
+// orUnknown returns version, or "unknown" if it is empty
+func orUnknown(version string) string {
+	if version == "" {
+		return "unknown"
+	}
+	return version
+}
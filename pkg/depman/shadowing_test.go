@@ -0,0 +1,83 @@
+package depman
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestPathShadowing(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses shell scripts")
+	}
+	dir := t.TempDir()
+	homeDir := filepath.Join(dir, "home")
+	managed := filepath.Join(homeDir, "bin")
+	system := filepath.Join(dir, "system")
+	for path, version := range map[string]string{filepath.Join(managed, "tool"): "1.2.0", filepath.Join(system, "tool"): "1.0.0"} {
+		if err := installBinaryContent(path, "#!/bin/sh\necho tool version "+version+"\n"); err != nil {
+			t.Fatalf("Failed to write binary: %v", err)
+		}
+	}
+	configPath := filepath.Join(dir, "deps.yml")
+	os.WriteFile(configPath, []byte(`version: "1.0"
+dependencies:
+  - name: tool
+    version:
+      required: "1.2.0"
+    platforms:
+      `+runtime.GOOS+`:
+        commands:
+          verify: ["tool", "--version"]
+`), 0644)
+
+	manager, err := NewManager(configPath, WithPlatform(runtime.GOOS+"/"+runtime.GOARCH), WithHomeDir(homeDir), WithSettings(Settings{}), WithLogOutput(io.Discard))
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		path     []string
+		shadowed bool
+	}{
+		{"system copy first", []string{system, managed}, true},
+		{"managed copy first", []string{managed, system}, false},
+		{"managed directory not on PATH", []string{system}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("PATH", strings.Join(tt.path, string(os.PathListSeparator)))
+
+			statuses, err := manager.CheckAllDependencies(context.Background())
+			if err != nil {
+				t.Fatalf("Did not expect an error but got: %v", err)
+			}
+			status := statuses["tool"]
+			if status.CurrentVersion != "1.2.0" {
+				t.Errorf("Expected the managed copy to be checked but got version %s", status.CurrentVersion)
+			}
+			if tt.shadowed && (status.ShadowedBy == nil || status.ShadowedBy.Path != filepath.Join(system, "tool") || status.ShadowedBy.Version != "1.0.0") {
+				t.Errorf("Expected the system copy to shadow the managed one but got %+v", status.ShadowedBy)
+			}
+			if !tt.shadowed && status.ShadowedBy != nil {
+				t.Errorf("Did not expect shadowing but got %+v", status.ShadowedBy)
+			}
+
+			audits, err := manager.AuditPath(context.Background())
+			if err != nil {
+				t.Fatalf("Did not expect an error but got: %v", err)
+			}
+			if len(audits) != 1 || len(audits[0].Installations) != 2 || audits[0].Shadowed() != tt.shadowed {
+				t.Fatalf("Expected an audit of both copies but got %+v", audits)
+			}
+			if audits[0].Checked.Version != "1.2.0" || len(audits[0].Suggestions()) == 0 {
+				t.Errorf("Unexpected audit: %+v", audits[0])
+			}
+		})
+	}
+}
@@ -0,0 +1,357 @@
+package depman
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// shimHeader marks files in the bin directory that depman generated
+const shimHeader = "Generated by depman. Do not edit."
+
+// activeVersionFile records the version a tool's shim runs by default
+const activeVersionFile = ".active"
+
+// sideBySideInstaller is implemented by install strategies that keep every
+// installed version in the tools directory behind a shim
+type sideBySideInstaller interface {
+	binaryName(m *Manager, dep *Dependency, platformConfig *PlatformConfig) string
+}
+
+// WithBinDir sets the managed directory holding shims for download-based
+// installs (default: <home>/bin)
+func WithBinDir(dir string) Option {
+	return func(m *Manager) {
+		m.binDir = dir
+	}
+}
+
+// ToolsDir returns the directory holding every installed version of download-based tools
+func (m *Manager) ToolsDir() string {
+	return filepath.Join(m.HomeDir(), "tools")
+}
+
+// toolVersionDir returns the directory holding one installed version of a tool
+func (m *Manager) toolVersionDir(name, version string) string {
+	return filepath.Join(m.ToolsDir(), name, version)
+}
+
+// toolVersion returns the directory name used for a version, without a leading "v"
+func toolVersion(version string) string {
+	version = strings.TrimPrefix(version, "v")
+	if version == "" {
+		return "current"
+	}
+	return version
+}
+
+// shimName returns the file name of the shim for a binary, which may be a
+// slash-separated path inside the version directory like "bin/tool"
+func shimName(binary, platform string) string {
+	name := path.Base(binary)
+	if platform == "windows" {
+		name = strings.TrimSuffix(strings.TrimSuffix(name, ".exe"), ".cmd") + ".cmd"
+	}
+	return name
+}
+
+// shimPath returns the path of the shim for a binary in the bin directory
+func (m *Manager) shimPath(binary string) string {
+	return filepath.Join(m.BinDir(), shimName(binary, m.Platform))
+}
+
+// findShim returns the shim for a binary from the first bin directory that has
+// one, or the current scope's shim path if none exists
+func (m *Manager) findShim(binary string) string {
+	name := shimName(binary, m.Platform)
+	for _, dir := range m.binDirs() {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return filepath.Join(dir, name)
+		}
+	}
+	return m.shimPath(binary)
+}
+
+// versionOverrideVar returns the environment variable that switches the
+// version a shim runs, e.g. DEPMAN_NODE_VERSION for "node"
+func versionOverrideVar(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(name) {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	return "DEPMAN_" + b.String() + "_VERSION"
+}
+
+// writeShim creates the bin directory shim for a tool binary. The shim runs
+// the given version unless the tool's version override variable names
+// another installed version.
+func (m *Manager) writeShim(dep *Dependency, binary, version string) (string, error) {
+	shim := m.shimPath(binary)
+	if err := os.MkdirAll(filepath.Dir(shim), 0755); err != nil {
+		return "", fmt.Errorf("failed to create bin directory: %w", err)
+	}
+
+	toolDir := filepath.Join(m.ToolsDir(), dep.Name)
+	override := versionOverrideVar(dep.Name)
+
+	var content string
+	if m.Platform == "windows" {
+		content = fmt.Sprintf("@echo off\r\nrem %s\r\nset \"version=%%%s%%\"\r\nif \"%%version%%\"==\"\" set \"version=%s\"\r\n\"%s\\%%version%%\\%s\" %%*\r\n",
+			shimHeader, override, version, toolDir, strings.ReplaceAll(binary, "/", `\`))
+	} else {
+		content = fmt.Sprintf("#!/bin/sh\n# %s\nexec \"%s/${%s:-%s}/%s\" \"$@\"\n",
+			shimHeader, toolDir, override, version, binary)
+	}
+
+	tmp := shim + ".tmp"
+	if err := os.WriteFile(tmp, []byte(content), 0755); err != nil {
+		return "", fmt.Errorf("failed to write shim %s: %w", shim, err)
+	}
+	if err := os.Rename(tmp, shim); err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("failed to write shim %s: %w", shim, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(toolDir, activeVersionFile), []byte(version+"\n"), 0644); err != nil {
+		return "", fmt.Errorf("failed to record active version of %s: %w", dep.Name, err)
+	}
+
+	return shim, nil
+}
+
+// InstalledVersions returns the versions of a tool kept in the tools
+// directory, oldest first, together with the version its shim runs
+func (m *Manager) InstalledVersions(name string) ([]string, string, error) {
+	toolDir := filepath.Join(m.ToolsDir(), name)
+	entries, err := os.ReadDir(toolDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, "", nil
+		}
+		return nil, "", fmt.Errorf("failed to read %s: %w", toolDir, err)
+	}
+
+	var versions []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			versions = append(versions, entry.Name())
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		a, errA := semver.NewVersion(versions[i])
+		b, errB := semver.NewVersion(versions[j])
+		if errA != nil || errB != nil {
+			return versions[i] < versions[j]
+		}
+		return a.LessThan(b)
+	})
+
+	active, _ := os.ReadFile(filepath.Join(toolDir, activeVersionFile))
+	return versions, strings.TrimSpace(string(active)), nil
+}
+
+// UseVersion points a tool's shim at another installed version
+func (m *Manager) UseVersion(name, version string) error {
+	dep := m.FindDependency(name)
+	if dep == nil {
+		return fmt.Errorf("dependency '%s' not found in configuration", name)
+	}
+
+	platformConfig, err := m.GetPlatformConfig(dep)
+	if err != nil {
+		return err
+	}
+
+	strategy, err := m.strategyFor(platformConfig)
+	if err != nil {
+		return err
+	}
+	sideBySide, ok := strategy.(sideBySideInstaller)
+	if !ok {
+		return fmt.Errorf("install method '%s' does not support switching versions", installMethod(platformConfig))
+	}
+
+	version = toolVersion(version)
+	binary := sideBySide.binaryName(m, dep, platformConfig)
+	if _, err := os.Stat(filepath.Join(m.toolVersionDir(dep.Name, version), binary)); err != nil {
+		installed, _, _ := m.InstalledVersions(dep.Name)
+		if len(installed) == 0 {
+			return fmt.Errorf("%s %s is not installed", dep.Name, version)
+		}
+		return fmt.Errorf("%s %s is not installed (installed: %s)", dep.Name, version, strings.Join(installed, ", "))
+	}
+
+	if _, err := m.writeShim(dep, binary, version); err != nil {
+		return err
+	}
+
+	m.logger.Debugf("Switched %s to %s", dep.Name, version)
+	return nil
+}
+
+// isToolVersionInstalled reports whether a version of a side-by-side tool is
+// already in the tools directory
+func (m *Manager) isToolVersionInstalled(dep *Dependency, version string) bool {
+	platformConfig, err := m.GetPlatformConfig(dep)
+	if err != nil {
+		return false
+	}
+	strategy, err := m.strategyFor(platformConfig)
+	if err != nil {
+		return false
+	}
+	sideBySide, ok := strategy.(sideBySideInstaller)
+	if !ok {
+		return false
+	}
+	_, err = os.Stat(filepath.Join(m.toolVersionDir(dep.Name, toolVersion(version)), sideBySide.binaryName(m, dep, platformConfig)))
+	return err == nil
+}
+
+// splitVersionSpec splits "name@version" into its parts
+func splitVersionSpec(spec string) (string, string) {
+	name, version, _ := strings.Cut(spec, "@")
+	return name, version
+}
+
+// removeTool deletes a tool's shim and every installed version of it
+func (m *Manager) removeTool(dep *Dependency, binary string) error {
+	shim := m.shimPath(binary)
+	if err := os.Remove(shim); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove %s: %w", shim, err)
+	}
+
+	toolDir := filepath.Join(m.ToolsDir(), dep.Name)
+	if err := os.RemoveAll(toolDir); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", toolDir, err)
+	}
+
+	return nil
+}
+
+// Activation lists the environment changes that put depman-managed tools on PATH
+type Activation struct {
+	Paths     []string          `json:"paths"`     // Directories to prepend to PATH, in order
+	Variables map[string]string `json:"variables"` // Environment variables to set
+}
+
+// Environ returns environ, a list of KEY=value entries such as os.Environ
+// returns, with the activation applied: its directories in front of PATH and
+// its variables set. Keys are matched case-insensitively on Windows.
+func (a *Activation) Environ(environ []string) []string {
+	sameKey := func(a, b string) bool { return a == b }
+	if runtime.GOOS == "windows" {
+		sameKey = strings.EqualFold
+	}
+	pathKey, currentPath := "PATH", ""
+	var result []string
+	for _, entry := range environ {
+		key, value, _ := strings.Cut(entry, "=")
+		if sameKey(key, "PATH") {
+			pathKey, currentPath = key, value
+			continue
+		}
+		replaced := false
+		for name := range a.Variables {
+			replaced = replaced || sameKey(key, name)
+		}
+		if !replaced {
+			result = append(result, entry)
+		}
+	}
+
+	paths := append([]string{}, a.Paths...)
+	if currentPath != "" {
+		paths = append(paths, currentPath)
+	}
+	result = append(result, pathKey+"="+strings.Join(paths, string(os.PathListSeparator)))
+	keys := make([]string, 0, len(a.Variables))
+	for key := range a.Variables {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		result = append(result, key+"="+a.Variables[key])
+	}
+	return result
+}
+
+// ActivationEnvironment returns the PATH entries and variables a shell needs to
+// use the managed bin directories, project-scoped first, and every dependency's
+// environment settings. When two dependencies set the same variable, the later
+// one in the configuration wins.
+func (m *Manager) ActivationEnvironment() (*Activation, error) {
+	activation := &Activation{Variables: make(map[string]string)}
+	setBy := make(map[string]string)
+	for _, dir := range m.binDirs() {
+		if _, err := os.Stat(dir); err == nil || dir == m.BinDir() {
+			activation.Paths = append(activation.Paths, dir)
+		}
+	}
+
+	for i := range m.Config.Dependencies {
+		dep := &m.Config.Dependencies[i]
+		if !m.supportsPlatform(dep) {
+			continue
+		}
+
+		environment, err := interpolated(m, dep.Environment, dep)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range environment.Path {
+			activation.Paths = append(activation.Paths, m.envManager.ExpandVariables(path))
+		}
+		for key, value := range environment.Variables {
+			if err := ValidateVariableName(key); err != nil {
+				return nil, fmt.Errorf("dependency '%s': %w", dep.Name, err)
+			}
+			if other, ok := setBy[key]; ok {
+				m.logger.Warnf("%s is set by both %s and %s; using the value of %s", key, other, dep.Name, dep.Name)
+			}
+			activation.Variables[key] = m.envManager.ExpandVariables(value)
+			setBy[key] = dep.Name
+		}
+	}
+
+	return activation, nil
+}
+
+// variableName matches the names of variables every shell can export
+var variableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// deniedVariables are variables a configuration may not set, because shells
+// run code from them or the dynamic loader injects code with them. PATH is
+// extended with environment.path instead.
+var deniedVariables = map[string]bool{
+	"PATH": true, "PROMPT_COMMAND": true, "PS0": true, "PS1": true, "PS2": true, "PS4": true,
+	"BASH_ENV": true, "ENV": true, "SHELLOPTS": true, "BASHOPTS": true, "IFS": true, "ZDOTDIR": true,
+	"LD_PRELOAD": true, "LD_AUDIT": true, "DYLD_INSERT_LIBRARIES": true, "DEPMAN_HOOK_STATE": true,
+}
+
+// ValidateVariableName returns an error if a configuration may not set a
+// variable of that name: names must be shell identifiers, and variables that
+// make shells or the dynamic loader run code are refused
+func ValidateVariableName(name string) error {
+	if !variableName.MatchString(name) {
+		return fmt.Errorf("invalid environment variable name '%s' (expected letters, digits and underscores, not starting with a digit)", name)
+	}
+	if deniedVariables[strings.ToUpper(name)] {
+		if strings.EqualFold(name, "PATH") {
+			return fmt.Errorf("environment variable PATH cannot be set; list directories under environment.path instead")
+		}
+		return fmt.Errorf("environment variable %s cannot be set by the configuration", name)
+	}
+	return nil
+}
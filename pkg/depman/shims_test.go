@@ -0,0 +1,259 @@
+package depman
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/devnadeemashraf/depman/internal/environment"
+)
+
+func TestVersionOverrideVar(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected string
+	}{
+		{"node", "DEPMAN_NODE_VERSION"},
+		{"golangci-lint", "DEPMAN_GOLANGCI_LINT_VERSION"},
+		{"protoc.gen", "DEPMAN_PROTOC_GEN_VERSION"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := versionOverrideVar(tc.name); got != tc.expected {
+				t.Errorf("Expected %s but got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestShimSwitchesVersion(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell shims are not used on Windows")
+	}
+
+	homeDir, err := os.MkdirTemp("", "depman-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(homeDir)
+
+	binDir := filepath.Join(homeDir, "custom-bin")
+	manager := &Manager{Platform: "linux", homeDir: homeDir, binDir: binDir, logger: &mockLogger{}}
+	dep := &Dependency{Name: "tool"}
+
+	for _, version := range []string{"1.0.0", "2.0.0"} {
+		target := filepath.Join(manager.toolVersionDir("tool", version), "tool")
+		if err := installBinaryContent(target, "#!/bin/sh\necho tool "+version+"\n"); err != nil {
+			t.Fatalf("Failed to write binary: %v", err)
+		}
+	}
+
+	shim, err := manager.writeShim(dep, "tool", "2.0.0")
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if shim != filepath.Join(binDir, "tool") {
+		t.Errorf("Expected shim in the configured bin directory but got %s", shim)
+	}
+
+	out, err := exec.Command(shim).Output()
+	if err != nil {
+		t.Fatalf("Failed to run shim: %v", err)
+	}
+	if got := strings.TrimSpace(string(out)); got != "tool 2.0.0" {
+		t.Errorf("Expected the active version to run but got %q", got)
+	}
+
+	cmd := exec.Command(shim)
+	cmd.Env = append(os.Environ(), "DEPMAN_TOOL_VERSION=1.0.0")
+	out, err = cmd.Output()
+	if err != nil {
+		t.Fatalf("Failed to run shim: %v", err)
+	}
+	if got := strings.TrimSpace(string(out)); got != "tool 1.0.0" {
+		t.Errorf("Expected the overridden version to run but got %q", got)
+	}
+}
+
+// installBinaryContent writes an executable file, creating its directory
+func installBinaryContent(path, content string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(content), 0755)
+}
+
+func TestActivationEnvironment(t *testing.T) {
+	homeDir, err := os.MkdirTemp("", "depman-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(homeDir)
+
+	manager := &Manager{
+		Platform:   "linux",
+		ConfigPath: filepath.Join(homeDir, "app-dependencies.yml"),
+		homeDir:    filepath.Join(homeDir, "global"),
+		binDir:     "/managed/bin",
+		logger:     &mockLogger{},
+		envManager: environment.NewManager(),
+		Config: &DependencyConfig{Dependencies: []Dependency{
+			{
+				Name:        "tool",
+				Environment: Environment{Path: []string{"/opt/tool/bin"}, Variables: map[string]string{"TOOL_HOME": "/opt/tool"}},
+				Platforms:   map[string]PlatformConfig{"linux": {}},
+			},
+			{
+				Name:        "jdk",
+				Version:     Version{Required: "v21.0.2"},
+				Environment: Environment{Variables: map[string]string{"JAVA_HOME": "{{ .InstallDir }}"}},
+				Platforms:   map[string]PlatformConfig{"linux": {}},
+			},
+			{
+				Name:        "other",
+				Environment: Environment{Path: []string{"/opt/other/bin"}},
+				Platforms:   map[string]PlatformConfig{"darwin": {}},
+			},
+		}},
+	}
+
+	activation, err := manager.ActivationEnvironment()
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if strings.Join(activation.Paths, ",") != "/managed/bin,/opt/tool/bin" {
+		t.Errorf("Expected bin directory followed by tool paths but got %v", activation.Paths)
+	}
+
+	// An existing project bin directory comes first
+	projectBin := filepath.Join(manager.ProjectDir(), "bin")
+	if err := os.MkdirAll(projectBin, 0755); err != nil {
+		t.Fatalf("Failed to create project bin directory: %v", err)
+	}
+	activation, err = manager.ActivationEnvironment()
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if len(activation.Paths) == 0 || activation.Paths[0] != projectBin {
+		t.Errorf("Expected project bin directory first but got %v", activation.Paths)
+	}
+	if activation.Variables["TOOL_HOME"] != "/opt/tool" {
+		t.Errorf("Expected TOOL_HOME to be set but got %v", activation.Variables)
+	}
+	if javaHome := filepath.Join(manager.ToolsDir(), "jdk", "21.0.2"); activation.Variables["JAVA_HOME"] != javaHome {
+		t.Errorf("Expected JAVA_HOME to be %s but got %v", javaHome, activation.Variables)
+	}
+}
+
+func TestValidateVariableName(t *testing.T) {
+	for _, name := range []string{"JAVA_HOME", "_private", "GOFLAGS2"} {
+		if err := ValidateVariableName(name); err != nil {
+			t.Errorf("Expected %s to be valid but got: %v", name, err)
+		}
+	}
+	for _, name := range []string{"X; touch pwned; Y", "1ST", "A-B", "", "PROMPT_COMMAND", "bash_env", "LD_PRELOAD", "PATH"} {
+		if err := ValidateVariableName(name); err == nil {
+			t.Errorf("Expected %q to be refused", name)
+		}
+	}
+
+	// Activation refuses them too, whatever loaded the configuration
+	manager := &Manager{
+		Platform:   "linux",
+		binDir:     "/managed/bin",
+		logger:     &mockLogger{},
+		envManager: environment.NewManager(),
+		Config: &DependencyConfig{Dependencies: []Dependency{{
+			Name:        "tool",
+			Environment: Environment{Variables: map[string]string{"X; touch pwned; Y": "1"}},
+			Platforms:   map[string]PlatformConfig{"linux": {}},
+		}}},
+	}
+	if _, err := manager.ActivationEnvironment(); err == nil {
+		t.Errorf("Expected an invalid variable name to be refused")
+	}
+}
+
+func TestActivationEnviron(t *testing.T) {
+	sep := string(os.PathListSeparator)
+	activation := &Activation{
+		Paths:     []string{"/managed/bin", "/opt/tool/bin"},
+		Variables: map[string]string{"TOOL_HOME": "/opt/tool", "JAVA_HOME": "/opt/jdk"},
+	}
+	got := activation.Environ([]string{"HOME=/home/user", "PATH=/usr/bin" + sep + "/bin", "TOOL_HOME=/old"})
+	expected := []string{"HOME=/home/user", "PATH=/managed/bin" + sep + "/opt/tool/bin" + sep + "/usr/bin" + sep + "/bin", "JAVA_HOME=/opt/jdk", "TOOL_HOME=/opt/tool"}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected %v but got %v", expected, got)
+	}
+
+	// Without a PATH, the activation's directories are all of it
+	got = (&Activation{Paths: []string{"/managed/bin"}}).Environ(nil)
+	if len(got) != 1 || got[0] != "PATH=/managed/bin" {
+		t.Errorf("Expected only PATH=/managed/bin but got %v", got)
+	}
+}
+
+func TestUseVersion(t *testing.T) {
+	homeDir, err := os.MkdirTemp("", "depman-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(homeDir)
+
+	manager := &Manager{
+		Platform: "linux",
+		homeDir:  homeDir,
+		logger:   &mockLogger{},
+		Config: &DependencyConfig{Dependencies: []Dependency{
+			{Name: "tool", Platforms: map[string]PlatformConfig{"linux": {Installer: Installer{Method: "archive"}}}},
+			{Name: "other", Platforms: map[string]PlatformConfig{"linux": {Installer: Installer{Method: "brew"}}}},
+		}},
+	}
+	dep := manager.FindDependency("tool")
+
+	for _, version := range []string{"1.10.0", "1.2.0"} {
+		if err := installBinaryContent(filepath.Join(manager.toolVersionDir("tool", version), "tool"), "#!/bin/sh\n"); err != nil {
+			t.Fatalf("Failed to write binary: %v", err)
+		}
+	}
+	if _, err := manager.writeShim(dep, "tool", "1.10.0"); err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+
+	if err := manager.UseVersion("tool", "v1.2.0"); err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+
+	versions, active, err := manager.InstalledVersions("tool")
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if strings.Join(versions, ",") != "1.2.0,1.10.0" {
+		t.Errorf("Expected versions in semantic order but got %v", versions)
+	}
+	if active != "1.2.0" {
+		t.Errorf("Expected active version 1.2.0 but got %s", active)
+	}
+
+	testCases := []struct {
+		name    string
+		dep     string
+		version string
+	}{
+		{"Version not installed", "tool", "2.0.0"},
+		{"Method without side-by-side installs", "other", "1.0.0"},
+		{"Unknown dependency", "missing", "1.0.0"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := manager.UseVersion(tc.dep, tc.version); err == nil {
+				t.Errorf("Expected an error but got none")
+			}
+		})
+	}
+}
@@ -0,0 +1,343 @@
+package depman
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// stateFileName is the file under the global home directory recording what depman installed
+const stateFileName = "state.json"
+
+// stateVersion is the format version of the state file
+const stateVersion = 1
+
+// ManagedInstall records a dependency that depman itself installed
+type ManagedInstall struct {
+	Name            string    `json:"name"`                       // Name of the dependency
+	Version         string    `json:"version"`                    // Version installed
+	PreviousVersion string    `json:"previous_version,omitempty"` // Version installed before the last install or upgrade changed it
+	Method          string    `json:"method"`                     // Install method used
+	Source          string    `json:"source,omitempty"`           // Download URL, repository or package installed from
+	Home            string    `json:"home"`                       // depman home directory of the install's scope
+	Configs         []string  `json:"configs"`                    // Configuration files that installed the dependency
+	InstalledAt     time.Time `json:"installed_at"`               // When the dependency was last installed
+	Files           []string  `json:"files,omitempty"`            // Files depman created for the install
+	Orphaned        bool      `json:"orphaned,omitempty"`         // Whether no recorded configuration defines the dependency any more
+}
+
+// installState is the content of the state file
+type installState struct {
+	Version  int               `json:"version"`
+	Installs []*ManagedInstall `json:"installs"`
+	Runs     []*Run            `json:"runs,omitempty"` // History of checks and ensures, oldest first
+}
+
+// StatePath returns the file recording the installs depman made in every scope and project
+func (m *Manager) StatePath() string {
+	return filepath.Join(m.globalHomeDir(), stateFileName)
+}
+
+// loadState reads the state file, which may not exist
+func loadState(path string) (*installState, error) {
+	state := &installState{Version: stateVersion}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read install state: %w", err)
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if state.Version > stateVersion {
+		return nil, fmt.Errorf("%s was written by a newer depman (state version %d)", path, state.Version)
+	}
+	return state, nil
+}
+
+// save writes the state file atomically so a concurrent reader never sees a partial file
+func (s *installState) save(path string) error {
+	sort.Slice(s.Installs, func(i, j int) bool {
+		if s.Installs[i].Name != s.Installs[j].Name {
+			return s.Installs[i].Name < s.Installs[j].Name
+		}
+		return s.Installs[i].Home < s.Installs[j].Home
+	})
+	s.Version = stateVersion
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode install state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to write install state: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write install state: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write install state: %w", err)
+	}
+	return nil
+}
+
+// find returns the recorded install of a dependency in a home directory, or nil
+func (s *installState) find(name, home string) *ManagedInstall {
+	for _, install := range s.Installs {
+		if install.Name == name && install.Home == home {
+			return install
+		}
+	}
+	return nil
+}
+
+// remove drops the recorded install of a dependency in a home directory
+func (s *installState) remove(name, home string) {
+	for i, install := range s.Installs {
+		if install.Name == name && install.Home == home {
+			s.Installs = append(s.Installs[:i], s.Installs[i+1:]...)
+			return
+		}
+	}
+}
+
+// configIdentity returns how the state file refers to the current configuration:
+// its remote reference, or the absolute path of the local file
+func (m *Manager) configIdentity() string {
+	if m.ConfigSource != "" {
+		return m.ConfigSource
+	}
+	if abs, err := filepath.Abs(m.ConfigPath); err == nil {
+		return abs
+	}
+	return m.ConfigPath
+}
+
+// trackFiles notes files an install strategy created for a dependency
+func (m *Manager) trackFiles(name string, files ...string) {
+	m.stateMu.Lock()
+	defer m.stateMu.Unlock()
+	if m.createdFiles == nil {
+		m.createdFiles = make(map[string][]string)
+	}
+	m.createdFiles[name] = append(m.createdFiles[name], files...)
+}
+
+// takeFiles returns and clears the files recorded as created for a dependency
+func (m *Manager) takeFiles(name string) []string {
+	m.stateMu.Lock()
+	defer m.stateMu.Unlock()
+	files := m.createdFiles[name]
+	delete(m.createdFiles, name)
+	return files
+}
+
+// recordInstall adds a successful install to the state file, together with
+// the files created for it since the install began
+func (m *Manager) recordInstall(dep *Dependency, status *DependencyStatus, artifact LockedArtifact) error {
+	files := m.takeFiles(dep.Name)
+
+	// Starting a service or checking a requirement leaves nothing behind to track
+	if dep.installsNothing() {
+		return nil
+	}
+
+	m.stateMu.Lock()
+	defer m.stateMu.Unlock()
+
+	path := m.StatePath()
+	state, err := loadState(path)
+	if err != nil {
+		return err
+	}
+
+	home := m.HomeDir()
+	install := state.find(dep.Name, home)
+	if install == nil {
+		install = &ManagedInstall{Name: dep.Name, Home: home}
+		state.Installs = append(state.Installs, install)
+	}
+
+	if install.Version != "" && install.Version != status.CurrentVersion {
+		install.PreviousVersion = install.Version
+	}
+	install.Version = status.CurrentVersion
+	install.InstalledAt = time.Now().UTC()
+	install.Source = artifact.URL
+	if platformConfig, err := m.resolvedPlatformConfig(dep); err == nil {
+		install.Method = installMethod(platformConfig)
+		if install.Source == "" {
+			install.Source = installSource(dep, platformConfig)
+		}
+	}
+	if config := m.configIdentity(); !containsString(install.Configs, config) {
+		install.Configs = append(install.Configs, config)
+	}
+	for _, file := range files {
+		if !containsString(install.Files, file) {
+			install.Files = append(install.Files, file)
+		}
+	}
+
+	return state.save(path)
+}
+
+// recordRollback notes in the state file that a dependency went back from
+// one version to another, keeping the version rolled back from to return to
+func (m *Manager) recordRollback(name, version, from string) error {
+	m.stateMu.Lock()
+	defer m.stateMu.Unlock()
+
+	path := m.StatePath()
+	state, err := loadState(path)
+	if err != nil {
+		return err
+	}
+	install := state.find(name, m.HomeDir())
+	if install == nil {
+		return nil
+	}
+	install.Version, install.PreviousVersion = version, from
+	install.InstalledAt = time.Now().UTC()
+	return state.save(path)
+}
+
+// installSource describes where a dependency without a download URL came from
+func installSource(dep *Dependency, platformConfig *PlatformConfig) string {
+	installer := platformConfig.Installer
+	switch {
+	case installer.Repo != "":
+		return installer.Repo
+	case installer.Package != "":
+		return installer.Package
+	default:
+		return dep.Name
+	}
+}
+
+// findInstall returns the current scope's record of a dependency, or nil if
+// depman has no record of installing it
+func (m *Manager) findInstall(name string) (*ManagedInstall, error) {
+	m.stateMu.Lock()
+	defer m.stateMu.Unlock()
+
+	state, err := loadState(m.StatePath())
+	if err != nil {
+		return nil, err
+	}
+	return state.find(name, m.HomeDir()), nil
+}
+
+// forgetInstall removes the current scope's record of a dependency from the state file
+func (m *Manager) forgetInstall(name string) error {
+	m.stateMu.Lock()
+	defer m.stateMu.Unlock()
+
+	path := m.StatePath()
+	state, err := loadState(path)
+	if err != nil {
+		return err
+	}
+	if state.find(name, m.HomeDir()) == nil {
+		return nil
+	}
+	state.remove(name, m.HomeDir())
+	return state.save(path)
+}
+
+// removeInstalledFiles deletes the files and package prefixes recorded for an
+// install that are still present, along with tool directories they leave empty
+func removeInstalledFiles(install *ManagedInstall) error {
+	toolsDir := filepath.Join(install.Home, "tools")
+	for _, file := range install.Files {
+		remove := os.Remove
+		if isWithin(file, toolsDir) {
+			// Package prefixes are recorded as whole directories
+			remove = os.RemoveAll
+		}
+		if err := remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove %s: %w", file, err)
+		}
+		// Prune the version and tool directories once they are empty
+		for dir := filepath.Dir(file); isWithin(dir, toolsDir) && dir != toolsDir; dir = filepath.Dir(dir) {
+			if os.Remove(dir) != nil {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// isWithin reports whether path is dir or inside it
+func isWithin(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// ManagedInstalls returns what depman has installed in every scope and
+// project, as recorded in the manager's state file
+func (m *Manager) ManagedInstalls() ([]*ManagedInstall, error) {
+	m.stateMu.Lock()
+	defer m.stateMu.Unlock()
+	return LoadManagedInstalls(m.StatePath())
+}
+
+// LoadManagedInstalls reads the installs recorded in a state file, marking
+// those that none of the configurations that made them still defines as
+// orphaned. Remote configurations are assumed to still define their
+// dependencies.
+func LoadManagedInstalls(path string) ([]*ManagedInstall, error) {
+	state, err := loadState(path)
+	if err != nil {
+		return nil, err
+	}
+
+	configs := make(map[string]*DependencyConfig)
+	for _, install := range state.Installs {
+		install.Orphaned = true
+		for _, path := range install.Configs {
+			if IsRemoteConfig(path) {
+				install.Orphaned = false
+				break
+			}
+			config, ok := configs[path]
+			if !ok {
+				config, _ = LoadDependencyConfig(path)
+				configs[path] = config
+			}
+			if config == nil {
+				// A missing file no longer defines anything; one that fails
+				// to load is given the benefit of the doubt
+				if _, err := os.Stat(path); err == nil {
+					install.Orphaned = false
+					break
+				}
+				continue
+			}
+			if configDefines(config, install.Name) {
+				install.Orphaned = false
+				break
+			}
+		}
+	}
+	return state.Installs, nil
+}
+
+// configDefines reports whether a configuration has a dependency with the given name
+func configDefines(config *DependencyConfig, name string) bool {
+	for _, dep := range config.Dependencies {
+		if dep.Name == name {
+			return true
+		}
+	}
+	return false
+}
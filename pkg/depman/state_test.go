@@ -0,0 +1,179 @@
+package depman
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newStateTestManager(t *testing.T) (*Manager, string) {
+	t.Helper()
+
+	tempDir, err := os.MkdirTemp("", "depman-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(tempDir) })
+
+	configPath := filepath.Join(tempDir, "app-dependencies.yml")
+	config := `
+dependencies:
+  - name: "tool"
+    version:
+      required: "1.2.3"
+    platforms:
+      linux:
+        installer:
+          method: "archive"
+          url: "https://example.com/tool-{{version}}.tar.gz"
+`
+	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	manager, err := NewManager(configPath, WithPlatform("linux"), WithHomeDir(tempDir), WithLogOutput(io.Discard))
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	return manager, tempDir
+}
+
+func TestRecordInstall(t *testing.T) {
+	manager, _ := newStateTestManager(t)
+	dep := manager.FindDependency("tool")
+	status := &DependencyStatus{Name: "tool", Installed: true, CurrentVersion: "1.2.3"}
+
+	binary := filepath.Join(manager.toolVersionDir("tool", "1.2.3"), "tool")
+	manager.trackFiles("tool", binary, manager.shimPath("tool"))
+	if err := manager.recordInstall(dep, status, LockedArtifact{URL: "https://example.com/tool-1.2.3.tar.gz"}); err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+
+	// Reinstalling another version keeps the files of the first
+	status.CurrentVersion = "1.3.0"
+	manager.trackFiles("tool", filepath.Join(manager.toolVersionDir("tool", "1.3.0"), "tool"), manager.shimPath("tool"))
+	if err := manager.recordInstall(dep, status, LockedArtifact{URL: "https://example.com/tool-1.3.0.tar.gz"}); err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+
+	installs, err := manager.ManagedInstalls()
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if len(installs) != 1 {
+		t.Fatalf("Expected 1 install but got %d", len(installs))
+	}
+
+	install := installs[0]
+	if install.Version != "1.3.0" || install.Method != "archive" || install.Source != "https://example.com/tool-1.3.0.tar.gz" {
+		t.Errorf("Expected version 1.3.0 from the archive URL but got %+v", install)
+	}
+	if install.Home != manager.HomeDir() {
+		t.Errorf("Expected home %s but got %s", manager.HomeDir(), install.Home)
+	}
+	if len(install.Configs) != 1 || install.Configs[0] != manager.ConfigPath {
+		t.Errorf("Expected config %s but got %v", manager.ConfigPath, install.Configs)
+	}
+	if len(install.Files) != 3 {
+		t.Errorf("Expected both versions and the shim to be recorded but got %v", install.Files)
+	}
+	if install.InstalledAt.IsZero() {
+		t.Errorf("Expected the install time to be recorded")
+	}
+	if install.Orphaned {
+		t.Errorf("Did not expect an install in the configuration to be orphaned")
+	}
+}
+
+func TestManagedInstallsOrphans(t *testing.T) {
+	manager, tempDir := newStateTestManager(t)
+
+	withoutTool := filepath.Join(tempDir, "other-dependencies.yml")
+	if err := os.WriteFile(withoutTool, []byte("dependencies: []\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	state := &installState{Installs: []*ManagedInstall{
+		{Name: "tool", Home: tempDir, Configs: []string{manager.ConfigPath}},
+		{Name: "tool", Home: filepath.Join(tempDir, "project"), Configs: []string{withoutTool}},
+		{Name: "gone", Home: tempDir, Configs: []string{filepath.Join(tempDir, "missing.yml")}},
+		{Name: "shared", Home: tempDir, Configs: []string{withoutTool, manager.ConfigPath, "https://example.com/deps.yml"}},
+	}}
+	if err := state.save(manager.StatePath()); err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+
+	installs, err := manager.ManagedInstalls()
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+
+	testCases := []struct {
+		name     string
+		home     string
+		orphaned bool
+	}{
+		{"tool", tempDir, false},
+		{"tool", filepath.Join(tempDir, "project"), true},
+		{"gone", tempDir, true},
+		{"shared", tempDir, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name+" in "+tc.home, func(t *testing.T) {
+			var found *ManagedInstall
+			for _, install := range installs {
+				if install.Name == tc.name && install.Home == tc.home {
+					found = install
+				}
+			}
+			if found == nil {
+				t.Fatalf("Expected an install of %s", tc.name)
+			}
+			if found.Orphaned != tc.orphaned {
+				t.Errorf("Expected orphaned %v but got %v", tc.orphaned, found.Orphaned)
+			}
+		})
+	}
+}
+
+func TestRemoveDependencyForgetsInstall(t *testing.T) {
+	manager, _ := newStateTestManager(t)
+	dep := manager.FindDependency("tool")
+
+	binary := filepath.Join(manager.toolVersionDir("tool", "1.2.3"), "tool")
+	if err := os.MkdirAll(filepath.Dir(binary), 0755); err != nil {
+		t.Fatalf("Failed to create tool directory: %v", err)
+	}
+	if err := os.WriteFile(binary, []byte("#!/bin/sh\necho tool 1.2.3\n"), 0755); err != nil {
+		t.Fatalf("Failed to write binary: %v", err)
+	}
+	shim, err := manager.writeShim(dep, "tool", "1.2.3")
+	if err != nil {
+		t.Fatalf("Failed to write shim: %v", err)
+	}
+	manager.trackFiles("tool", binary, shim)
+	status := &DependencyStatus{Name: "tool", Installed: true, CurrentVersion: "1.2.3"}
+	if err := manager.recordInstall(dep, status, LockedArtifact{}); err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+
+	if err := manager.RemoveDependency(context.Background(), "tool", false); err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+
+	for _, file := range []string{binary, shim} {
+		if _, err := os.Stat(file); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be removed", file)
+		}
+	}
+	installs, err := manager.ManagedInstalls()
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if len(installs) != 0 {
+		t.Errorf("Expected the install to be forgotten but got %d installs", len(installs))
+	}
+}
@@ -0,0 +1,165 @@
+package depman
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Task is a named command run with the managed toolchain by 'depman run'
+type Task struct {
+	Description string   `yaml:"description"` // What the task does, listed by 'depman run'
+	Run         []string `yaml:"run"`         // Command lines run in order by the shell as written, stopping at the first failure
+	Needs       []string `yaml:"needs"`       // Dependencies or tags ensured before it runs (default: every dependency)
+	Dir         string   `yaml:"dir"`         // Working directory, relative to the configuration's directory (default: that directory)
+}
+
+// TaskOptions says how RunTask runs a task
+type TaskOptions struct {
+	Args     []string  // Arguments appended, quoted, to the task's last command line
+	NoEnsure bool      // Run without ensuring the dependencies first
+	Stdin    io.Reader // Input of the commands (default: none)
+	Stdout   io.Writer // Output of the commands (default: discarded)
+	Stderr   io.Writer // Error output of the commands (default: discarded)
+}
+
+// TaskNames returns the names of the configuration's tasks, sorted
+func (m *Manager) TaskNames() []string {
+	names := make([]string, 0, len(m.Config.Tasks))
+	for name := range m.Config.Tasks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RunTask ensures the dependencies a task needs, then runs its command lines
+// in order with the managed bin directories in front of PATH and every
+// dependency's environment settings applied, stopping at the first failure.
+// The error of a failed command wraps its *exec.ExitError. RunTask narrows
+// the manager's selection to the task's needs while it ensures them, so it
+// must not run concurrently with other operations of the same manager.
+func (m *Manager) RunTask(ctx context.Context, name string, opts TaskOptions) error {
+	task, ok := m.Config.Tasks[name]
+	if !ok {
+		if len(m.Config.Tasks) == 0 {
+			return fmt.Errorf("unknown task '%s' (the configuration defines no tasks)", name)
+		}
+		return fmt.Errorf("unknown task '%s' (defined: %s)", name, strings.Join(m.TaskNames(), ", "))
+	}
+	if len(task.Run) == 0 {
+		return fmt.Errorf("task '%s' has nothing to run", name)
+	}
+
+	if !opts.NoEnsure {
+		if err := m.ensureTaskNeeds(ctx, name, task); err != nil {
+			return err
+		}
+	}
+
+	activation, err := m.ActivationEnvironment()
+	if err != nil {
+		return err
+	}
+	env := activation.Environ(os.Environ())
+	env = append(env, "DEPMAN_TASK="+name)
+
+	dir := m.configDir()
+	if task.Dir != "" {
+		dir = task.Dir
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(m.configDir(), dir)
+		}
+	}
+
+	// The shell expands ${VAR} references itself, including DEPMAN_TASK and
+	// its own variables, so the lines are not interpolated
+	commands := slices.Clone(task.Run)
+	if len(opts.Args) > 0 {
+		last := len(commands) - 1
+		commands[last] += " " + quoteArgs(opts.Args)
+	}
+
+	log := m.componentLogger("tasks", nil)
+	for _, command := range commands {
+		log.Infof("Running task %s: %s", name, command)
+		cmd := hookShell(ctx, command)
+		cmd.Env = env
+		cmd.Dir = dir
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = opts.Stdin, opts.Stdout, opts.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("task %s failed running '%s': %w", name, command, err)
+		}
+	}
+	return nil
+}
+
+// mergeTasks lays overlay tasks on top of base ones, replacing tasks of the
+// same name
+func mergeTasks(base, overlay map[string]Task) map[string]Task {
+	if len(overlay) == 0 {
+		return base
+	}
+	merged := make(map[string]Task, len(base)+len(overlay))
+	for name, task := range base {
+		merged[name] = task
+	}
+	for name, task := range overlay {
+		merged[name] = task
+	}
+	return merged
+}
+
+// ensureTaskNeeds ensures the dependencies a task needs: those named in its
+// needs and those carrying a tag listed there, or every dependency, if any
+func (m *Manager) ensureTaskNeeds(ctx context.Context, name string, task Task) error {
+	if len(task.Needs) == 0 && len(m.Config.Dependencies) == 0 {
+		return nil
+	}
+	if len(task.Needs) > 0 {
+		selection := Selection{}
+		for _, need := range task.Needs {
+			if m.FindDependency(need) != nil {
+				selection.Only = append(selection.Only, need)
+			} else {
+				selection.Tags = append(selection.Tags, need)
+			}
+		}
+		previous := m.selection
+		m.selection = selection
+		defer func() { m.selection = previous }()
+
+		deps, err := m.SelectedDependencies()
+		if err != nil {
+			return err
+		}
+		if len(deps) == 0 {
+			return fmt.Errorf("task '%s' needs %s, which match no dependency or tag", name, strings.Join(task.Needs, ", "))
+		}
+	}
+
+	if _, err := m.Ensure(ctx); err != nil {
+		return fmt.Errorf("failed to ensure the dependencies of task %s: %w", name, err)
+	}
+	return nil
+}
+
+// quoteArgs joins arguments into a command line for the shell hookShell runs,
+// quoting each so it arrives unchanged
+func quoteArgs(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if runtime.GOOS == "windows" {
+			quoted[i] = `"` + strings.ReplaceAll(arg, `"`, `\"`) + `"`
+		} else {
+			quoted[i] = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+		}
+	}
+	return strings.Join(quoted, " ")
+}
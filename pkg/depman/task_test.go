@@ -0,0 +1,131 @@
+package depman
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestRunTask(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "deps.yml")
+	os.WriteFile(configPath, []byte(`version: "1.0"
+tasks:
+  greet:
+    description: Print the environment
+    needs: [lint]
+    dir: sub
+    run:
+      - echo "$DEPMAN_TASK $TOOL_HOME $(pwd)" > out.txt
+      - echo args >> out.txt
+  fail:
+    run: ["exit 3", "echo never"]
+dependencies:
+  - name: tool
+    tags: [lint]
+    version:
+      constraint: ">=1.0"
+    environment:
+      variables:
+        TOOL_HOME: /opt/tool
+    platforms:
+      `+runtime.GOOS+`:
+        installer:
+          method: command
+        commands:
+          verify: ["echo", "1.2.0"]
+`), 0644)
+	os.MkdirAll(filepath.Join(dir, "sub"), 0755)
+
+	manager, err := NewManager(configPath, WithPlatform(runtime.GOOS+"/"+runtime.GOARCH), WithHomeDir(filepath.Join(dir, "home")), WithSettings(Settings{}), WithLogOutput(io.Discard))
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if got := strings.Join(manager.TaskNames(), ","); got != "fail,greet" {
+		t.Errorf("Expected tasks fail,greet but got %s", got)
+	}
+
+	if err := manager.RunTask(context.Background(), "greet", TaskOptions{Args: []string{"it's", "$HOME"}}); err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	output, err := os.ReadFile(filepath.Join(dir, "sub", "out.txt"))
+	if err != nil {
+		t.Fatalf("Expected the task to write out.txt: %v", err)
+	}
+	realSub, _ := filepath.EvalSymlinks(filepath.Join(dir, "sub"))
+	expected := "greet /opt/tool " + realSub + "\nargs it's $HOME\n"
+	if string(output) != expected {
+		t.Errorf("Expected output %q but got %q", expected, output)
+	}
+
+	// The first failing command stops the task with its exit code
+	err = manager.RunTask(context.Background(), "fail", TaskOptions{NoEnsure: true})
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 3 {
+		t.Errorf("Expected the task to fail with exit code 3 but got: %v", err)
+	}
+
+	if err := manager.RunTask(context.Background(), "deploy", TaskOptions{}); err == nil || !strings.Contains(err.Error(), "defined: fail, greet") {
+		t.Errorf("Expected an unknown task to list the defined ones but got: %v", err)
+	}
+}
+
+func TestRunTaskShellVariables(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "deps.yml")
+	os.WriteFile(configPath, []byte(`version: "1.0"
+tasks:
+  loop:
+    run:
+      - for f in a b; do echo "item ${f} of ${DEPMAN_TASK}" >> out.txt; done
+dependencies: []
+`), 0644)
+
+	// Strict interpolation does not apply to the shell's own variables, and
+	// with no dependencies there is nothing to ensure
+	manager, err := NewManager(configPath, WithPlatform(runtime.GOOS+"/"+runtime.GOARCH), WithHomeDir(filepath.Join(dir, "home")),
+		WithSettings(Settings{}), WithLogOutput(io.Discard), WithStrictInterpolation(true))
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if err := manager.RunTask(context.Background(), "loop", TaskOptions{}); err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	output, err := os.ReadFile(filepath.Join(dir, "out.txt"))
+	if err != nil {
+		t.Fatalf("Expected the task to write out.txt: %v", err)
+	}
+	if expected := "item a of loop\nitem b of loop\n"; string(output) != expected {
+		t.Errorf("Expected output %q but got %q", expected, output)
+	}
+}
+
+func TestTaskValidation(t *testing.T) {
+	_, err := parseAndValidate("deps.yml", []byte(`version: "1.0"
+tasks:
+  lint:
+    description: Lint the code
+  test:
+    run: "go test ./..."
+`), nil)
+	if err == nil {
+		t.Fatalf("Expected validation errors")
+	}
+	for _, expected := range []string{"tasks.lint", "missing required field 'run'", "tasks.test.run"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected the error to mention %q but got: %v", expected, err)
+		}
+	}
+}
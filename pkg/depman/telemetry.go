@@ -0,0 +1,66 @@
+package depman
+
+import (
+	"context"
+	"os"
+
+	"github.com/devnadeemashraf/depman/internal/telemetry"
+)
+
+// Metrics recorded while dependencies are installed
+var (
+	metricInstallDuration = telemetry.Histogram("depman.install.duration", "s", "Time taken to install and verify a dependency",
+		0.5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600)
+	metricInstallFailures = telemetry.Counter("depman.install.failures", "{failure}", "Dependency installs that failed")
+	metricCacheLookups    = telemetry.Counter("depman.download.cache.lookups", "{lookup}", "Download cache lookups, by result (hit or miss)")
+)
+
+// setupTelemetry enables exporting traces and metrics when an OTLP endpoint
+// is configured with the standard OTEL_* environment variables
+func (m *Manager) setupTelemetry() {
+	config, err := telemetry.ConfigFromEnv(os.Getenv)
+	if err != nil {
+		m.logger.Warnf("Not exporting telemetry: %v", err)
+		return
+	}
+	if config == nil {
+		return
+	}
+	config.Resource = append(config.Resource,
+		telemetry.String("os.type", m.Platform),
+		telemetry.String("host.arch", m.targetArch()),
+	)
+	m.telemetry = telemetry.New(*config)
+}
+
+// operation starts the span of a public operation. The returned function
+// ends it and, for the outermost operation, exports what was recorded.
+func (m *Manager) operation(ctx context.Context, name string) (context.Context, func(error)) {
+	outermost := telemetry.FromContext(ctx) == nil
+	ctx, span := m.telemetry.Start(ctx, name)
+	return ctx, func(err error) {
+		span.End(err)
+		if outermost && m.telemetry != nil {
+			if err := m.telemetry.Flush(context.WithoutCancel(ctx), m.client()); err != nil {
+				m.logger.Warnf("%v", err)
+			}
+		}
+	}
+}
+
+// dependencyAttributes describe a dependency on its spans and metric points
+func (m *Manager) dependencyAttributes(dep *Dependency) []telemetry.Attribute {
+	attrs := []telemetry.Attribute{telemetry.String("depman.dependency", dep.Name)}
+	if platformConfig, err := m.GetPlatformConfig(dep); err == nil {
+		attrs = append(attrs, telemetry.String("depman.method", installMethod(platformConfig)))
+	}
+	return attrs
+}
+
+// cacheResult labels a download cache lookup for metrics
+func cacheResult(hit bool) string {
+	if hit {
+		return "hit"
+	}
+	return "miss"
+}
@@ -0,0 +1,65 @@
+package depman
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestCheckTelemetry(t *testing.T) {
+	var mu sync.Mutex
+	var spans []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var request struct {
+			ResourceSpans []struct {
+				ScopeSpans []struct {
+					Spans []map[string]interface{} `json:"spans"`
+				} `json:"scopeSpans"`
+			} `json:"resourceSpans"`
+		}
+		if err := json.Unmarshal(body, &request); err != nil {
+			t.Errorf("Invalid export to %s: %v", r.URL.Path, err)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for _, resource := range request.ResourceSpans {
+			for _, scope := range resource.ScopeSpans {
+				spans = append(spans, scope.Spans...)
+			}
+		}
+	}))
+	defer server.Close()
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", server.URL)
+
+	manager := &Manager{
+		Config: &DependencyConfig{Name: "Test App", Dependencies: []Dependency{{
+			Name:      "missing-tool",
+			Version:   Version{Required: "1.0.0"},
+			Platforms: map[string]PlatformConfig{"linux": {Commands: Commands{Verify: []string{"depman-test-missing-tool", "--version"}}}},
+		}}},
+		Platform: "linux",
+		logger:   &mockLogger{},
+	}
+	manager.setupTelemetry()
+
+	if _, err := manager.CheckAllDependencies(context.Background()); err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+
+	names := make(map[string]map[string]interface{})
+	for _, span := range spans {
+		names[span["name"].(string)] = span
+	}
+	root, check := names["depman.check"], names["depman.step.check"]
+	if root == nil || check == nil {
+		t.Fatalf("Expected the check and its step to be exported but got %v", spans)
+	}
+	if check["parentSpanId"] != root["spanId"] || check["traceId"] != root["traceId"] {
+		t.Errorf("Expected the step span to be a child of the check span")
+	}
+}
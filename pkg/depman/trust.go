@@ -0,0 +1,135 @@
+package depman
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// allowedConfigsFile is the file, in the user-level home directory, recording
+// the configurations allowed to change shells' environment
+const allowedConfigsFile = "allowed.json"
+
+// AllowedConfigsPath returns the file recording the configurations 'depman
+// allow' trusted, with the digest of their contents when they were allowed
+func AllowedConfigsPath() string {
+	return filepath.Join(DefaultHomeDir(), allowedConfigsFile)
+}
+
+// ConfigDigest digests the contents of the files a configuration is loaded
+// from, so any edit to them, or a new local overlay, changes it
+func ConfigDigest(path string) (string, error) {
+	hash := sha256.New()
+	for _, file := range ConfigFiles(path) {
+		data, err := os.ReadFile(file)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		fmt.Fprintf(hash, "%s\x00%d\x00", file, len(data))
+		hash.Write(data)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// AllowConfig trusts a configuration, as its files are now, to change the
+// environment of shells through 'depman hook'. Editing them revokes it.
+func AllowConfig(path string) error {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	digest, err := ConfigDigest(path)
+	if err != nil {
+		return err
+	}
+	allowed, err := loadAllowedConfigs()
+	if err != nil {
+		return err
+	}
+	allowed[path] = digest
+	return saveAllowedConfigs(allowed)
+}
+
+// DenyConfig revokes the trust AllowConfig gave a configuration
+func DenyConfig(path string) error {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	allowed, err := loadAllowedConfigs()
+	if err != nil {
+		return err
+	}
+	if _, ok := allowed[path]; !ok {
+		return nil
+	}
+	delete(allowed, path)
+	return saveAllowedConfigs(allowed)
+}
+
+// IsConfigAllowed reports whether a configuration was allowed with the
+// contents its files have now
+func IsConfigAllowed(path string) (bool, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return false, err
+	}
+	allowed, err := loadAllowedConfigs()
+	if err != nil {
+		return false, err
+	}
+	recorded, ok := allowed[path]
+	if !ok {
+		return false, nil
+	}
+	digest, err := ConfigDigest(path)
+	if err != nil {
+		return false, err
+	}
+	return recorded == digest, nil
+}
+
+// loadAllowedConfigs reads the allowed configurations, by absolute path
+func loadAllowedConfigs() (map[string]string, error) {
+	allowed := make(map[string]string)
+	data, err := os.ReadFile(AllowedConfigsPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return allowed, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read allowed configurations: %w", err)
+	}
+	if err := json.Unmarshal(data, &allowed); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", AllowedConfigsPath(), err)
+	}
+	return allowed, nil
+}
+
+// saveAllowedConfigs writes the allowed configurations atomically, readable
+// only by the user
+func saveAllowedConfigs(allowed map[string]string) error {
+	data, err := json.MarshalIndent(allowed, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode allowed configurations: %w", err)
+	}
+	path := AllowedConfigsPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to write allowed configurations: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("failed to write allowed configurations: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write allowed configurations: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package depman
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAllowConfig(t *testing.T) {
+	t.Setenv(HomeEnvVar, t.TempDir())
+
+	dir := t.TempDir()
+	config := filepath.Join(dir, "app-dependencies.yml")
+	if err := os.WriteFile(config, []byte("dependencies: []\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	allowed := func() bool {
+		t.Helper()
+		ok, err := IsConfigAllowed(config)
+		if err != nil {
+			t.Fatalf("Did not expect an error but got: %v", err)
+		}
+		return ok
+	}
+
+	if allowed() {
+		t.Errorf("Expected a configuration not to be allowed before depman allow")
+	}
+	if err := AllowConfig(config); err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if !allowed() {
+		t.Errorf("Expected the configuration to be allowed")
+	}
+
+	// Any edit, including a new local overlay, revokes the trust
+	if err := os.WriteFile(localOverlayPath(config), []byte("dependencies: []\n"), 0644); err != nil {
+		t.Fatalf("Failed to write overlay: %v", err)
+	}
+	if allowed() {
+		t.Errorf("Expected a new local overlay to revoke the trust")
+	}
+	if err := AllowConfig(config); err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if err := os.WriteFile(config, []byte("dependencies: []\nhooks: {}\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if allowed() {
+		t.Errorf("Expected an edit to revoke the trust")
+	}
+
+	if err := AllowConfig(config); err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if err := DenyConfig(config); err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if allowed() {
+		t.Errorf("Expected depman deny to revoke the trust")
+	}
+}
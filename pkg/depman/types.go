@@ -1,10 +1,20 @@
 package depman
 
 import (
-	"fmt"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/devnadeemashraf/depman/internal/credentials"
+	"github.com/devnadeemashraf/depman/internal/downloader"
 	"github.com/devnadeemashraf/depman/internal/environment"
+	"github.com/devnadeemashraf/depman/internal/httpclient"
 	"github.com/devnadeemashraf/depman/internal/logger"
+	"github.com/devnadeemashraf/depman/internal/telemetry"
 )
 
 // Version represents dependency version information with semver support
@@ -15,9 +25,98 @@ type Version struct {
 
 // Installer contains information about how to install a dependency
 type Installer struct {
-	Type     string `yaml:"type"`     // Installation type (e.g., "msi", "pkg", "binary")
-	URL      string `yaml:"url"`      // URL to download the dependency
-	Checksum string `yaml:"checksum"` // Checksum for verification (format: "algorithm:hash")
+	Method   string `yaml:"method"`                // Install method (defaults to "command")
+	Type     string `yaml:"type"`                  // Installation type (e.g., "msi", "pkg", "binary")
+	URL      string `yaml:"url" interpolate:"url"` // URL to download the dependency (supports {{version}}, {{os}} and {{arch}})
+	Checksum string `yaml:"checksum"`              // Checksum for verification (format: "algorithm:hash")
+
+	// Verification of downloaded artifacts
+	ChecksumURL   string `yaml:"checksum_url" interpolate:"url"`  // URL of a checksums file listing the artifact (e.g., SHA256SUMS)
+	SignatureURL  string `yaml:"signature_url" interpolate:"url"` // URL of a detached signature over the artifact
+	SignatureType string `yaml:"signature_type"`                  // Signature format: "gpg" (default) or "minisign"
+	PublicKey     string `yaml:"public_key"`                      // GPG keyring file, or minisign public key or key file
+
+	// Package managers ("winget", "choco", "system", "apt", "dnf", ... methods)
+	Package  string            `yaml:"package"`  // Package identifier (defaults to the dependency name; for "native", the MSI product code or pkg receipt id)
+	Packages map[string]string `yaml:"packages"` // Package names per system package manager (e.g., apt: "fd-find")
+	Version  string            `yaml:"version"`  // Exact package version to pin for system package managers
+	Args     []string          `yaml:"args"`     // Extra arguments passed to the package manager
+
+	// Archives ("archive", "github-release", "go", "pipx", "npm", "cargo" methods)
+	Binary string `yaml:"binary"` // Name of the binary to extract or shim (defaults to the dependency name; for "go", the package name)
+
+	// GitHub releases ("github-release" method)
+	Repo       string `yaml:"repo"`       // Repository in "owner/name" form
+	Asset      string `yaml:"asset"`      // Glob pattern selecting the release asset (supports {{version}}, {{os}} and {{arch}})
+	TokenEnv   string `yaml:"token_env"`  // Environment variable holding an API token (defaults to GITHUB_TOKEN)
+	Prerelease bool   `yaml:"prerelease"` // Whether prereleases may be selected
+
+	// Go modules ("go" method)
+	Module  string `yaml:"module"`  // Package path passed to `go install`, e.g. "golang.org/x/tools/gopls"
+	GoFlags string `yaml:"goflags"` // GOFLAGS for the build, e.g. "-trimpath"
+
+	// Plugins (any method provided by an installed plugin)
+	Options map[string]string `yaml:"options"` // Plugin-specific settings passed through to the plugin
+
+	// Homebrew ("brew" method)
+	Formula string `yaml:"formula"` // Formula or cask name (use versioned formulae like "node@18" to pin a major version)
+	Cask    bool   `yaml:"cask"`    // Whether the formula is a cask
+	Pin     bool   `yaml:"pin"`     // Whether to pin the formula so `brew upgrade` leaves it alone (for "scoop", hold the app)
+
+	// Scoop ("scoop" method)
+	Bucket    string `yaml:"bucket"`     // Bucket holding the app, e.g. "extras"; added when missing
+	BucketURL string `yaml:"bucket_url"` // Git URL of a bucket that is not one of scoop's known buckets
+	Manifest  string `yaml:"manifest"`   // URL or path of an app manifest to install instead of a bucket's, to pin its exact contents
+
+	// Native installers ("native" method)
+	Format      string `yaml:"format"`       // Installer format: "msi", "pkg" or "dmg" (defaults to the URL's extension)
+	InstallPath string `yaml:"install_path"` // File or app bundle the installer creates; checked after installing and read for the version
+
+	// Scripts ("script" method)
+	Script     string   `yaml:"script" interpolate:"-"` // Inline install script (not interpolated, so it only sees the allowlisted variables)
+	ScriptFile string   `yaml:"script_file"`            // Install script file, relative to the configuration
+	Shell      string   `yaml:"shell"`                  // Interpreter: sh, bash, pwsh, powershell or cmd (defaults to sh, or powershell on Windows)
+	Env        []string `yaml:"env" interpolate:"-"`    // Environment variables passed through to the script; all others are withheld
+	WorkDir    string   `yaml:"work_dir"`               // Working directory, relative to the configuration (defaults to an empty temporary directory)
+	Timeout    string   `yaml:"timeout"`                // Maximum run time of the script, e.g. "5m" (default 10m)
+
+	// Nix ("nix" method)
+	Flake string `yaml:"flake"` // Flake reference to install, e.g. "github:NixOS/nixpkgs/<rev>#jq" (defaults to "nixpkgs#<package>")
+}
+
+// ImageSpec describes a container image dependency
+type ImageSpec struct {
+	Name    string `yaml:"name"`    // Image reference, e.g. "postgres:16.2" or "ghcr.io/org/tool:1.0"
+	Digest  string `yaml:"digest"`  // Manifest digest the image is pinned to, e.g. "sha256:..."
+	Runtime string `yaml:"runtime"` // Container runtime: docker, podman or nerdctl (defaults to the first installed)
+}
+
+// ServiceSpec describes a service dependency: a daemon that must be running
+type ServiceSpec struct {
+	Probes []ServiceProbe `yaml:"probes"` // Health checks that must all pass
+	Start  []string       `yaml:"start"`  // Command starting the service on ensure when it is not healthy
+	Wait   string         `yaml:"wait"`   // How long ensure waits for the started service to become healthy (default 30s)
+}
+
+// ServiceProbe is one health check of a service; exactly one of its checks is set
+type ServiceProbe struct {
+	TCP     string   `yaml:"tcp"`                    // Address that must accept connections, e.g. "localhost:5432"
+	HTTP    string   `yaml:"http" interpolate:"url"` // URL that must answer with a 2xx or 3xx status
+	Command []string `yaml:"command"`                // Command that must exit successfully, e.g. ["docker", "info"]
+	Systemd string   `yaml:"systemd"`                // systemd unit that must be active
+	Timeout string   `yaml:"timeout"`                // How long the check may take (default 5s)
+}
+
+// RequirementSpec describes preconditions of the machine that depman checks
+// but cannot install; every check that is set must pass
+type RequirementSpec struct {
+	DiskSpace      string `yaml:"disk_space"`     // Minimum free space, e.g. "20GB"
+	DiskPath       string `yaml:"disk_path"`      // Path whose filesystem must have the space (defaults to the depman home)
+	Memory         string `yaml:"memory"`         // Minimum total memory, e.g. "16GB"
+	CPUs           int    `yaml:"cpus"`           // Minimum number of logical CPUs
+	OSVersion      string `yaml:"os_version"`     // Constraint on the Linux kernel, macOS or Windows version, e.g. ">=13.0"
+	Virtualization bool   `yaml:"virtualization"` // Whether hardware virtualization must be enabled
+	Fix            string `yaml:"fix"`            // Remediation shown when a check fails (defaults to a hint per check)
 }
 
 // Commands for different operations on a dependency
@@ -41,29 +140,102 @@ type Environment struct {
 
 // Dependency represents a single dependency with all its properties
 type Dependency struct {
-	Name         string                    `yaml:"name"`         // Unique name of the dependency
-	Description  string                    `yaml:"description"`  // Human-readable description
-	Version      Version                   `yaml:"version"`      // Version requirements
-	Platforms    map[string]PlatformConfig `yaml:"platforms"`    // Platform-specific configurations
-	Environment  Environment               `yaml:"environment"`  // Environment configuration
-	Dependencies []string                  `yaml:"dependencies"` // Dependencies of this dependency
+	Name           string                    `yaml:"name"`                     // Unique name of the dependency
+	From           string                    `yaml:"from,omitempty"`           // Registry recipe the dependency is based on, e.g. "registry/kubectl"
+	Description    string                    `yaml:"description"`              // Human-readable description
+	Version        Version                   `yaml:"version"`                  // Version requirements
+	VersionCommand []string                  `yaml:"version_command"`          // Command printing the installed version (overrides commands.verify)
+	VersionRegex   string                    `yaml:"version_regex"`            // Regex extracting the version from its output (named group "version" or first group)
+	Platforms      map[string]PlatformConfig `yaml:"platforms"`                // Platform-specific configurations
+	Environment    Environment               `yaml:"environment"`              // Environment configuration
+	Dependencies   []string                  `yaml:"dependencies"`             // Dependencies of this dependency
+	Hooks          Hooks                     `yaml:"hooks"`                    // Lifecycle hooks for this dependency
+	Timeout        string                    `yaml:"timeout"`                  // Maximum time to check or install the dependency, e.g. "10m"
+	Tags           []string                  `yaml:"tags"`                     // Groups the dependency belongs to, e.g. "build" or "docs"
+	License        string                    `yaml:"license"`                  // SPDX license expression, e.g. "MIT" (looked up for GitHub releases if empty)
+	Audit          AuditConfig               `yaml:"audit"`                    // How to look the dependency up in the OSV vulnerability database
+	WSL            string                    `yaml:"wsl,omitempty"`            // Where to install inside WSL: linux (default), windows or both
+	Kind           string                    `yaml:"kind,omitempty"`           // What the dependency provides: tool (default), image, service or requirement
+	Image          ImageSpec                 `yaml:"image,omitempty"`          // Container image to pull, for the image kind
+	Service        ServiceSpec               `yaml:"service,omitempty"`        // Health probes and start command, for the service kind
+	Requirement    RequirementSpec           `yaml:"requirement,omitempty"`    // Machine preconditions, for the requirement kind
+	Healthcheck    HealthCheck               `yaml:"healthcheck,omitempty"`    // Check ensure runs after installing to confirm the dependency works
+	When           string                    `yaml:"when,omitempty"`           // Condition the machine must meet for the dependency to apply, e.g. platform == "darwin"
+	Provides       []string                  `yaml:"provides,omitempty"`       // Other names the dependency satisfies, e.g. "docker" for colima
+	ConflictsWith  []string                  `yaml:"conflicts_with,omitempty"` // Dependencies that must not be installed alongside it
+	Projects       []string                  `yaml:"-"`                        // Workspace projects declaring the dependency, "." for the workspace file
 }
 
 // DependencyConfig represents the entire dependency configuration file
 type DependencyConfig struct {
-	Version      string       `yaml:"version"`      // Configuration format version
-	Name         string       `yaml:"name"`         // Application name
-	Description  string       `yaml:"description"`  // Application description
-	Dependencies []Dependency `yaml:"dependencies"` // List of dependencies
+	Version        string             `yaml:"version"`         // Configuration format version
+	Name           string             `yaml:"name"`            // Application name
+	Description    string             `yaml:"description"`     // Application description
+	RequiresDepman string             `yaml:"requires_depman"` // Constraint on the depman version that can load the file, e.g. ">=0.5"
+	Features       []string           `yaml:"features"`        // Features the file needs depman to support, e.g. "when"
+	Includes       []string           `yaml:"includes"`        // Base configuration files merged underneath this one
+	Scope          string             `yaml:"scope"`           // Default install scope (project or global)
+	Hooks          Hooks              `yaml:"hooks"`           // Hooks run once per run or around every install
+	Credentials    []Credential       `yaml:"credentials"`     // How to authenticate to private artifact hosts
+	Mirrors        []Mirror           `yaml:"mirrors"`         // Rewrite rules for artifact URLs, first match wins
+	Policy         Policy             `yaml:"policy"`          // Rules installed dependencies must follow
+	Webhooks       []Webhook          `yaml:"webhooks"`        // Endpoints notified of the result of each ensure
+	Dependencies   []Dependency       `yaml:"dependencies"`    // List of dependencies
+	Profiles       map[string]Profile `yaml:"profiles"`        // Named adjustments for environments such as ci or prod
+	Workspace      Workspace          `yaml:"workspace"`       // Projects of a monorepo checked and ensured together with this file
+	Tasks          map[string]Task    `yaml:"tasks"`           // Named commands 'depman run' runs with the managed toolchain
 }
 
 // Manager handles dependency management operations
 type Manager struct {
-	Config     *DependencyConfig    // Dependency configuration
-	ConfigPath string               // Path to configuration file
-	Platform   string               // Current platform (windows, linux, darwin)
-	logger     Logger               // Logger for operations
-	envManager *environment.Manager // Environment manager
+	Config        *DependencyConfig           // Dependency configuration
+	ConfigPath    string                      // Path to configuration file
+	ConfigSource  string                      // Remote reference the configuration was fetched from, if any
+	Platform      string                      // Current platform (windows, linux, darwin)
+	Arch          string                      // Target CPU architecture (amd64, arm64, ...; defaults to runtime.GOARCH)
+	distro        *Distro                     // Linux distribution platform entries and package managers are selected for
+	wsl           bool                        // Whether depman runs inside WSL, where dependencies can target the Windows host
+	logger        Logger                      // Logger for operations
+	envManager    *environment.Manager        // Environment manager
+	envMu         sync.Mutex                  // Guards envManager while dependencies install in parallel
+	homeDir       string                      // Root directory for files managed by depman
+	cacheDir      string                      // Directory of the download cache (defaults to <homeDir>/cache)
+	binDir        string                      // Directory holding shims (defaults to <homeDir>/bin)
+	scope         Scope                       // Install scope overriding the configuration
+	concurrency   int                         // Maximum number of dependencies checked or installed in parallel
+	privilege     PrivilegePolicy             // How to gain root privileges for system package managers
+	frozen        bool                        // Whether ensure must follow the lockfile exactly
+	lock          *Lockfile                   // Lockfile being followed in frozen mode
+	bundlePath    string                      // Offline bundle to install from, if set
+	bundle        *BundleManifest             // Manifest of the loaded offline bundle
+	strict        bool                        // Whether undefined variables in the configuration are errors
+	noRollback    bool                        // Whether failed installs are left as they are instead of rolled back
+	selection     Selection                   // Dependencies check, ensure and list are limited to
+	profile       string                      // Profile applied to the configuration, if set
+	pins          map[string]string           // Exact versions set with WithVersionPins, by dependency name
+	overrides     map[string]*VersionOverride // Local version overrides applied to the configuration, by dependency name
+	progress      ProgressReporter            // Receives progress events, if set
+	prompt        PromptFunc                  // Asks before each install or upgrade of an interactive ensure, if set
+	httpClient    *http.Client                // Client for downloads and API calls (defaults to a proxy-aware client)
+	credentials   credentials.Provider        // Looks up credentials for artifact hosts (defaults to the configured sources)
+	clientOnce    sync.Once                   // Guards authClient
+	authClient    *http.Client                // httpClient with credentials added to requests
+	limiter       *downloader.Limiter         // Caps the number and rate of downloads, if set
+	retryPolicy   *RetryPolicy                // How transient failures are retried (defaults to DefaultRetryPolicy)
+	attempts      map[string]int              // Attempts taken by the current install of each dependency
+	attemptsMu    sync.Mutex                  // Guards attempts, outputs and installLogs
+	outputs       map[string]string           // Output of install scripts run by the current install of each dependency
+	installLogs   map[string]string           // Log file of the current install of each dependency
+	noScripts     bool                        // Whether the script install method is disallowed
+	orgPolicy     *OrgPolicy                  // Organization policy installs must follow, if any
+	orgPolicyPath string                      // Policy file to load instead of the machine-wide one
+	settings      *Settings                   // User-level settings defaults come from (loaded from SettingsPath by default)
+	telemetry     *telemetry.Provider         // Records spans and metrics when an OTLP endpoint is configured
+	createdFiles  map[string][]string         // Files created by the current install of each dependency
+	runID         string                      // ID of the check or ensure in progress, which its logs and history entry are filed under
+	stepTimes     map[string]time.Duration    // Time the run in progress spent on each dependency
+	runMu         sync.Mutex                  // Guards runID and stepTimes
+	stateMu       sync.Mutex                  // Guards createdFiles and the state file
 }
 
 // UpdateType represents the type of update needed
@@ -80,26 +252,130 @@ func (u UpdateType) String() string {
 	return [...]string{"No Update", "Patch Update", "Minor Update", "Major Update"}[u]
 }
 
+// MarshalText encodes the update type as a short lowercase identifier
+// ("none", "patch", "minor", "major") for machine-readable output
+func (u UpdateType) MarshalText() ([]byte, error) {
+	return []byte([...]string{"none", "patch", "minor", "major"}[u]), nil
+}
+
+// Problem is the kind of issue a check found with a dependency
+type Problem string
+
+const (
+	ProblemNone         Problem = ""             // Installed and up to date
+	ProblemError        Problem = "error"        // The installed version could not be checked
+	ProblemMissing      Problem = "missing"      // Not installed
+	ProblemUnhealthy    Problem = "unhealthy"    // Installed, but its health check failed after an install
+	ProblemIncompatible Problem = "incompatible" // The installed version violates the constraint
+	ProblemMajor        Problem = "major"        // A major update to the required version is needed
+	ProblemMinor        Problem = "minor"        // A minor update to the required version is needed
+	ProblemPatch        Problem = "patch"        // A patch update to the required version is needed
+)
+
+// Change is what an ensure or install run did to a dependency
+type Change string
+
+const (
+	ChangeNone        Change = ""            // Left as it was: already satisfied, skipped or failed
+	ChangeInstalled   Change = "installed"   // Installed where it was missing
+	ChangeUpgraded    Change = "upgraded"    // Moved to a newer version
+	ChangeDowngraded  Change = "downgraded"  // Moved to an older version, e.g. to follow the lockfile
+	ChangeReinstalled Change = "reinstalled" // Installed again at the same version
+)
+
 // DependencyStatus represents the installation status of a dependency
 type DependencyStatus struct {
-	Name           string     // Name of the dependency
-	Installed      bool       // Whether the dependency is installed
-	CurrentVersion string     // Current installed version
-	RequiredUpdate UpdateType // Type of update required
-	Compatible     bool       // Whether the current version is compatible with constraints
-	Error          error      // Any error that occurred during checking
+	Name            string           // Name of the dependency
+	Installed       bool             // Whether the dependency is installed
+	CurrentVersion  string           // Current installed version
+	RequiredUpdate  UpdateType       // Type of update required
+	Compatible      bool             // Whether the current version is compatible with constraints
+	Error           error            // Any error that occurred during checking
+	Attempts        int              // Attempts the most retried operation of the last install took (0 if nothing was installed)
+	Vulnerabilities []Vulnerability  // Known vulnerabilities of the installed version, if audited
+	Skipped         bool             // Whether an interactive ensure was told to leave the dependency alone
+	InstallOutput   string           // Captured output of the last install command or script, if one ran
+	InstallLog      string           // Log of every command the last install ran, with its full output, if it ran any
+	Change          Change           // What the run changed (ChangeNone for checks)
+	PreviousVersion string           // Version installed before the run changed it, if any
+	Unhealthy       bool             // Whether the health check failed after installing
+	ProvidedBy      string           // Alternative that satisfies the dependency in its place, if any
+	Projects        []string         // Workspace projects declaring the dependency, if loaded from a workspace
+	ShadowedBy      *Installation    // Copy earlier on PATH that shells run instead of the managed one checked, if any
+	Override        *VersionOverride // Local override of the version the configuration requires, if any
+}
+
+// MarshalJSON encodes the status as its DependencyResult
+func (s DependencyStatus) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Result())
+}
+
+// Problem returns the most severe issue found with the dependency, or
+// ProblemNone if it is installed and up to date
+func (s *DependencyStatus) Problem() Problem {
+	switch {
+	case s.ProvidedBy != "":
+		return ProblemNone
+	case !s.Installed:
+		return ProblemMissing
+	case s.Unhealthy:
+		return ProblemUnhealthy
+	case s.Error != nil:
+		return ProblemError
+	case !s.Compatible:
+		return ProblemIncompatible
+	}
+	switch s.RequiredUpdate {
+	case MajorUpdate:
+		return ProblemMajor
+	case MinorUpdate:
+		return ProblemMinor
+	case PatchUpdate:
+		return ProblemPatch
+	}
+	return ProblemNone
+}
+
+// VerificationFailed reports whether the status error is a VerificationError
+func (s *DependencyStatus) VerificationFailed() bool {
+	var verr *VerificationError
+	return errors.As(s.Error, &verr)
 }
 
 // Option represents a configuration option for the dependency manager
 type Option func(*Manager)
 
-// WithPlatform sets a specific platform to use instead of auto-detecting
+// WithPlatform sets a specific platform to use instead of auto-detecting. An
+// "os/arch" value such as "linux/arm64" sets the architecture as well.
 func WithPlatform(platform string) Option {
 	return func(m *Manager) {
-		m.Platform = platform
+		goos, arch, hasArch := strings.Cut(platform, "/")
+		m.Platform = goos
+		if hasArch {
+			m.Arch = arch
+		}
+	}
+}
+
+// WithConcurrency sets how many dependencies may be checked or installed in parallel.
+// Values below 1 fall back to the number of available CPUs.
+func WithConcurrency(n int) Option {
+	return func(m *Manager) {
+		m.concurrency = n
 	}
 }
 
+// WithHTTPClient sets the client used for every download and API call, e.g.
+// one built with custom CA certificates, client certificates or a proxy
+func WithHTTPClient(client *http.Client) Option {
+	return func(m *Manager) {
+		m.httpClient = client
+	}
+}
+
+// defaultHTTPClient honors HTTPS_PROXY, HTTP_PROXY and NO_PROXY
+var defaultHTTPClient = httpclient.Default()
+
 // WithLogLevel sets the log level for the dependency manager
 func WithLogLevel(level logger.Level) Option {
 	return func(m *Manager) {
@@ -109,25 +385,52 @@ func WithLogLevel(level logger.Level) Option {
 	}
 }
 
-// Logger interface for logging dependency operations
-type Logger interface {
-	Debugf(format string, args ...interface{})
-	Infof(format string, args ...interface{})
-	Warnf(format string, args ...interface{})
-	Errorf(format string, args ...interface{})
+// WithLogOutput redirects the default logger to the given writer
+func WithLogOutput(output io.Writer) Option {
+	return func(m *Manager) {
+		if l, ok := m.logger.(*logger.Logger); ok {
+			m.logger = l.WithOutput(output)
+		}
+	}
 }
 
-// defaultLogger is a simple logger that prints to stdout
-type defaultLogger struct{}
+// WithLogFormat sets how the default logger encodes entries (text or JSON)
+func WithLogFormat(format logger.Format) Option {
+	return func(m *Manager) {
+		if l, ok := m.logger.(*logger.Logger); ok {
+			m.logger = l.WithFormat(format)
+		}
+	}
+}
 
-func (l *defaultLogger) Infof(format string, args ...interface{}) {
-	fmt.Printf("[INFO] "+format+"\n", args...)
+// WithComponentLogLevels sets minimum log levels for components of the
+// default logger ("download", "hooks", "plugin"), overriding the log level
+func WithComponentLogLevels(levels map[string]logger.Level) Option {
+	return func(m *Manager) {
+		if l, ok := m.logger.(*logger.Logger); ok {
+			m.logger = l.WithComponentLevels(levels)
+		}
+	}
 }
 
-func (l *defaultLogger) Errorf(format string, args ...interface{}) {
-	fmt.Printf("[ERROR] "+format+"\n", args...)
+// componentLogger returns the logger for a component, tagging entries with
+// the dependency when the default logger is in use
+func (m *Manager) componentLogger(component string, dep *Dependency) Logger {
+	l, ok := m.logger.(*logger.Logger)
+	if !ok {
+		return m.logger
+	}
+	l = l.Named(component)
+	if dep != nil {
+		l = l.With("dependency", dep.Name)
+	}
+	return l
 }
 
-func (l *defaultLogger) Debugf(format string, args ...interface{}) {
-	// By default, debug logs are disabled
+// Logger interface for logging dependency operations
+type Logger interface {
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
 }
@@ -0,0 +1,241 @@
+package depman
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Upgrade is a newer version available for a dependency
+type Upgrade struct {
+	Name           string     `json:"name"`            // Name of the dependency
+	CurrentVersion string     `json:"current_version"` // Pinned version, or the installed one if the dependency is not pinned
+	LatestVersion  string     `json:"latest_version"`  // Newest available version the constraint allows
+	Type           UpdateType `json:"type"`            // Size of the step from the current version
+	Pinned         bool       `json:"pinned"`          // Whether the configuration pins version.required
+}
+
+// UpdatePlan lists the upgrades available for the configured dependencies
+type UpdatePlan struct {
+	Upgrades []*Upgrade        `json:"upgrades"`          // Available upgrades, in configuration order
+	UpToDate []string          `json:"up_to_date"`        // Dependencies already at the newest allowed version
+	Unknown  map[string]string `json:"unknown,omitempty"` // Dependencies whose versions could not be listed, with the reason
+}
+
+// Group returns the upgrades of a single type
+func (p *UpdatePlan) Group(updateType UpdateType) []*Upgrade {
+	var group []*Upgrade
+	for _, upgrade := range p.Upgrades {
+		if upgrade.Type == updateType {
+			group = append(group, upgrade)
+		}
+	}
+	return group
+}
+
+// PlanUpdates looks up the versions available for each dependency and
+// returns the newest one its constraint allows, compared against the pinned
+// version or, for unpinned dependencies, the installed one. Dependencies
+// whose install method cannot list versions are reported as unknown.
+func (m *Manager) PlanUpdates(ctx context.Context) (*UpdatePlan, error) {
+	if err := m.validateConfiguration(); err != nil {
+		return nil, fmt.Errorf("invalid dependency configuration: %w", err)
+	}
+
+	statuses, err := m.CheckAllDependencies(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deps, err := m.SelectedDependencies()
+	if err != nil {
+		return nil, err
+	}
+	plan := &UpdatePlan{Unknown: make(map[string]string)}
+	for _, dep := range deps {
+		current := dep.Version.Required
+		if current == "" {
+			current = statuses[dep.Name].CurrentVersion
+		}
+		if current == "" {
+			plan.Unknown[dep.Name] = "not pinned and not installed"
+			continue
+		}
+
+		latest, err := m.latestVersion(ctx, dep)
+		if err != nil {
+			plan.Unknown[dep.Name] = err.Error()
+			continue
+		}
+
+		updateType, err := CheckVersionUpdate(current, latest)
+		if err != nil {
+			plan.Unknown[dep.Name] = err.Error()
+			continue
+		}
+		if updateType == NoUpdate {
+			plan.UpToDate = append(plan.UpToDate, dep.Name)
+			continue
+		}
+
+		plan.Upgrades = append(plan.Upgrades, &Upgrade{
+			Name:           dep.Name,
+			CurrentVersion: current,
+			LatestVersion:  latest,
+			Type:           updateType,
+			Pinned:         dep.Version.Required != "",
+		})
+	}
+
+	return plan, nil
+}
+
+// latestVersion returns the newest available version of a dependency that
+// satisfies its constraint
+func (m *Manager) latestVersion(ctx context.Context, dep *Dependency) (string, error) {
+	versions, err := m.sourceVersions(ctx, dep)
+	if err != nil {
+		return "", err
+	}
+	return newestVersion(versions, dep.Version.Constraint)
+}
+
+// sourceVersions lists the versions a dependency's install source offers
+func (m *Manager) sourceVersions(ctx context.Context, dep *Dependency) ([]string, error) {
+	platformConfig, err := m.resolvedPlatformConfig(dep)
+	if err != nil {
+		return nil, err
+	}
+	strategy, err := m.strategyFor(platformConfig)
+	if err != nil {
+		return nil, err
+	}
+	lister, ok := strategy.(versionLister)
+	if !ok {
+		return nil, fmt.Errorf("install method '%s' cannot list available versions", installMethod(platformConfig))
+	}
+
+	ctx, cancel := m.dependencyContext(ctx, dep)
+	defer cancel()
+
+	return lister.availableVersions(ctx, m, dep, platformConfig)
+}
+
+// newestVersion returns the newest of the versions that satisfies constraint,
+// or the newest overall if constraint is empty
+func newestVersion(versions []string, constraint string) (string, error) {
+	_, newest, err := Version{Constraint: constraint}.Resolve(versions)
+	if err != nil {
+		return "", err
+	}
+	return newest.String(), nil
+}
+
+// ApplyUpdates installs the given upgrades. With write, the pinned versions
+// in the configuration file are rewritten first, so the lockfile records the
+// upgrades; otherwise every upgrade is a one-off install like "name@version"
+// and the configuration and lockfile are left alone.
+func (m *Manager) ApplyUpdates(ctx context.Context, upgrades []*Upgrade, write bool) (map[string]*DependencyStatus, error) {
+	rewrites := make(map[string]string)
+	var specs []string
+	for _, upgrade := range upgrades {
+		if write && upgrade.Pinned {
+			rewrites[upgrade.Name] = upgrade.LatestVersion
+			specs = append(specs, upgrade.Name)
+			continue
+		}
+		specs = append(specs, upgrade.Name+"@"+upgrade.LatestVersion)
+	}
+
+	if len(rewrites) > 0 {
+		if m.ConfigSource != "" {
+			return nil, fmt.Errorf("cannot rewrite versions in remote configuration %s; update its source", m.ConfigSource)
+		}
+		if m.ConfigPath == "" {
+			return nil, fmt.Errorf("cannot rewrite versions in a configuration built in code")
+		}
+		if err := rewritePinnedVersions(m.ConfigPath, rewrites); err != nil {
+			return nil, err
+		}
+		for name, version := range rewrites {
+			if dep := m.FindDependency(name); dep != nil {
+				dep.Version.Required = version
+			}
+		}
+	}
+
+	return m.InstallDependencies(ctx, specs, false)
+}
+
+// rewritePinnedVersions replaces the version.required values of the named
+// dependencies in a YAML or JSON configuration file, editing only the values
+// so comments and formatting are kept
+func rewritePinnedVersions(path string, versions map[string]string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read configuration: %w", err)
+	}
+	if DetectConfigFormat(path, data) == FormatTOML {
+		return fmt.Errorf("rewriting versions in TOML configurations is not supported")
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 {
+		return fmt.Errorf("failed to parse configuration: %v", err)
+	}
+
+	lines := strings.SplitAfter(string(data), "\n")
+	remaining := make(map[string]bool, len(versions))
+	for name := range versions {
+		remaining[name] = true
+	}
+
+	deps := mappingValue(doc.Content[0], "dependencies")
+	if deps != nil {
+		for _, dep := range deps.Content {
+			name := mappingValue(dep, "name")
+			if name == nil || !remaining[name.Value] {
+				continue
+			}
+			required := mappingValue(mappingValue(dep, "version"), "required")
+			if required == nil || required.Value == "" {
+				continue
+			}
+			if strings.Contains(required.Value, "${") || strings.Contains(required.Value, "{{") {
+				return fmt.Errorf("the version of %s is set from a variable; update it where the variable is defined", name.Value)
+			}
+
+			line := []rune(lines[required.Line-1])
+			start := required.Column - 1
+			offset := strings.Index(string(line[start:]), required.Value)
+			if offset < 0 {
+				return fmt.Errorf("failed to locate the version of %s in %s", name.Value, path)
+			}
+			prefix := string(line[:start]) + string(line[start:])[:offset]
+			lines[required.Line-1] = prefix + versions[name.Value] + string(line[start:])[offset+len(required.Value):]
+			delete(remaining, name.Value)
+		}
+	}
+
+	if len(remaining) > 0 {
+		names := make([]string, 0, len(remaining))
+		for name := range remaining {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("%s does not pin version.required for %s; update the file that does", path, strings.Join(names, ", "))
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "")), info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to write configuration: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,245 @@
+package depman
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/devnadeemashraf/depman/internal/github"
+)
+
+func TestPlanUpdates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]github.Release{
+			{TagName: "v2.0.0"},
+			{TagName: "v1.7.0"},
+			{TagName: "v1.6.1"},
+			{TagName: "v3.0.0-rc.1", Prerelease: true},
+		})
+	}))
+	defer server.Close()
+
+	originalURL := github.APIURL
+	github.APIURL = server.URL
+	defer func() { github.APIURL = originalURL }()
+
+	tempDir, err := os.MkdirTemp("", "depman-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	configPath := filepath.Join(tempDir, "app-dependencies.yml")
+	config := `
+dependencies:
+  - name: "jq"
+    version:
+      required: "1.6.0"
+    platforms:
+      linux:
+        installer: {method: "github-release", repo: "owner/jq"}
+  - name: "yq"
+    version:
+      required: "1.6.0"
+      constraint: "^1.6"
+    platforms:
+      linux:
+        installer: {method: "github-release", repo: "owner/yq"}
+  - name: "rg"
+    version:
+      required: "1.6.1"
+      constraint: "~1.6"
+    platforms:
+      linux:
+        installer: {method: "github-release", repo: "owner/rg"}
+  - name: "tool"
+    version:
+      required: "1.0.0"
+    platforms:
+      linux:
+        commands:
+          verify: ["false"]
+`
+	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to write configuration: %v", err)
+	}
+
+	manager, err := NewManager(configPath, WithPlatform("linux"), WithHomeDir(tempDir), WithLogOutput(io.Discard))
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+
+	plan, err := manager.PlanUpdates(context.Background())
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+
+	expected := map[string]struct {
+		latest     string
+		updateType UpdateType
+	}{
+		"jq": {"2.0.0", MajorUpdate},
+		"yq": {"1.7.0", MinorUpdate},
+	}
+	if len(plan.Upgrades) != len(expected) {
+		t.Fatalf("Expected %d upgrades but got %d", len(expected), len(plan.Upgrades))
+	}
+	for _, upgrade := range plan.Upgrades {
+		want, ok := expected[upgrade.Name]
+		if !ok {
+			t.Errorf("Did not expect an upgrade for %s", upgrade.Name)
+			continue
+		}
+		if upgrade.LatestVersion != want.latest || upgrade.Type != want.updateType {
+			t.Errorf("Expected %s to %s (%s) but got %s (%s)", upgrade.Name, want.latest, want.updateType, upgrade.LatestVersion, upgrade.Type)
+		}
+	}
+
+	if len(plan.UpToDate) != 1 || plan.UpToDate[0] != "rg" {
+		t.Errorf("Expected rg to be up to date but got %v", plan.UpToDate)
+	}
+	if _, ok := plan.Unknown["tool"]; !ok {
+		t.Errorf("Expected tool to be unknown but got %v", plan.Unknown)
+	}
+	if len(plan.Group(MinorUpdate)) != 1 {
+		t.Errorf("Expected 1 minor upgrade but got %d", len(plan.Group(MinorUpdate)))
+	}
+
+	t.Run("Outdated report", func(t *testing.T) {
+		report, err := manager.Outdated(context.Background())
+		if err != nil {
+			t.Fatalf("Did not expect an error but got: %v", err)
+		}
+		if len(report) != 4 {
+			t.Fatalf("Expected 4 entries but got %d", len(report))
+		}
+
+		yq := report[1]
+		if yq.Wanted != "1.7.0" || yq.Latest != "2.0.0" || !yq.Outdated {
+			t.Errorf("Expected missing yq wanting 1.7.0 of 2.0.0 but got %+v", yq)
+		}
+		if tool := report[3]; tool.Error == "" || tool.Outdated {
+			t.Errorf("Expected tool to report an error without counting as outdated but got %+v", tool)
+		}
+	})
+}
+
+func TestWantedVersion(t *testing.T) {
+	versions := []string{"1.6.0", "1.6.2", "1.7.0", "2.0.0"}
+
+	testCases := []struct {
+		name     string
+		version  Version
+		expected string
+	}{
+		{"Newest satisfying constraint", Version{Required: "1.6.0", Constraint: "~1.6"}, "1.6.2"},
+		{"Pinned without constraint", Version{Required: "1.6.0"}, "1.6.0"},
+		{"Unconstrained", Version{}, "2.0.0"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			wanted, err := wantedVersion(&Dependency{Version: tc.version}, versions)
+			if err != nil {
+				t.Fatalf("Did not expect an error but got: %v", err)
+			}
+			if wanted != tc.expected {
+				t.Errorf("Expected %s but got %s", tc.expected, wanted)
+			}
+		})
+	}
+}
+
+func TestRewritePinnedVersions(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "depman-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	testCases := []struct {
+		name        string
+		file        string
+		content     string
+		expected    string
+		expectError bool
+	}{
+		{
+			name: "YAML keeps comments",
+			file: "app.yml",
+			content: `dependencies:
+  # JSON processor
+  - name: "jq"
+    version:
+      required: "1.6.0" # pinned for CI
+  - name: "yq"
+    version: {required: '4.0.0'}
+`,
+			expected: `dependencies:
+  # JSON processor
+  - name: "jq"
+    version:
+      required: "1.7.1" # pinned for CI
+  - name: "yq"
+    version: {required: '4.1.0'}
+`,
+		},
+		{
+			name:     "JSON",
+			file:     "app.json",
+			content:  `{"dependencies": [{"name": "jq", "version": {"required": "1.6.0"}}, {"name": "yq", "version": {"required": "4.0.0"}}]}`,
+			expected: `{"dependencies": [{"name": "jq", "version": {"required": "1.7.1"}}, {"name": "yq", "version": {"required": "4.1.0"}}]}`,
+		},
+		{
+			name:        "Version not pinned in this file",
+			file:        "partial.yml",
+			content:     "dependencies:\n  - name: \"jq\"\n    version:\n      required: \"1.6.0\"\n",
+			expectError: true,
+		},
+		{
+			name:        "Version from a variable",
+			file:        "variable.yml",
+			content:     "dependencies:\n  - name: \"jq\"\n    version:\n      required: \"${JQ_VERSION}\"\n  - name: \"yq\"\n    version:\n      required: \"4.0.0\"\n",
+			expectError: true,
+		},
+		{
+			name:        "TOML",
+			file:        "app.toml",
+			content:     "[[dependencies]]\nname = \"jq\"\n",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(tempDir, tc.file)
+			if err := os.WriteFile(path, []byte(tc.content), 0644); err != nil {
+				t.Fatalf("Failed to write configuration: %v", err)
+			}
+
+			err := rewritePinnedVersions(path, map[string]string{"jq": "1.7.1", "yq": "4.1.0"})
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("Expected an error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Did not expect an error but got: %v", err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("Failed to read configuration: %v", err)
+			}
+			if string(data) != tc.expected {
+				t.Errorf("Expected:\n%s\nbut got:\n%s", tc.expected, data)
+			}
+		})
+	}
+}
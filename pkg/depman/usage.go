@@ -0,0 +1,157 @@
+package depman
+
+import (
+	"sort"
+	"time"
+)
+
+// UsageReport sums up the runs in the local history for sharing, e.g. with
+// the team maintaining a configuration. It holds dependency names, counts and
+// durations only: no paths, hosts, errors or installer output. depman never
+// sends it anywhere by itself.
+type UsageReport struct {
+	GeneratedAt   time.Time         `json:"generated_at"`             // When the report was created
+	Since         time.Time         `json:"since"`                    // Start of the period the report covers
+	Platform      string            `json:"platform,omitempty"`       // Platform the runs were made on
+	DepmanVersion string            `json:"depman_version,omitempty"` // Version of depman that created the report
+	Runs          int               `json:"runs"`                     // Number of runs in the period
+	Checks        int               `json:"checks"`                   // Number of them that were checks
+	Ensures       int               `json:"ensures"`                  // Number of them that were ensures
+	FailedRuns    int               `json:"failed_runs"`              // Number of runs that failed
+	Configs       int               `json:"configs"`                  // Number of distinct configurations the runs used
+	Dependencies  []DependencyUsage `json:"dependencies"`             // Figures for each dependency, by name
+}
+
+// DependencyUsage is what the runs in a usage report found and did for one
+// dependency
+type DependencyUsage struct {
+	Name           string        `json:"name"`            // Name of the dependency
+	Runs           int           `json:"runs"`            // Number of runs that included it
+	Failures       int           `json:"failures"`        // Number of runs that failed to check or install it
+	Drifted        int           `json:"drifted"`         // Number of runs that found it missing or out of date
+	Installs       int           `json:"installs"`        // Number of runs that installed, upgraded, downgraded or reinstalled it
+	SlowestInstall time.Duration `json:"slowest_install"` // Longest time a run took to install it, in nanoseconds
+	AverageInstall time.Duration `json:"average_install"` // Average time a run took to install it, in nanoseconds
+}
+
+// DriftRate returns the share of runs that found the dependency missing or
+// out of date, between 0 and 1
+func (u DependencyUsage) DriftRate() float64 {
+	if u.Runs == 0 {
+		return 0
+	}
+	return float64(u.Drifted) / float64(u.Runs)
+}
+
+// NewUsageReport sums up the runs that started at or after since
+func NewUsageReport(runs []*Run, since time.Time) *UsageReport {
+	report := &UsageReport{GeneratedAt: time.Now().UTC(), Since: since.UTC(), Dependencies: []DependencyUsage{}}
+	configs := make(map[string]bool)
+	usage := make(map[string]*DependencyUsage)
+	installTime := make(map[string]time.Duration)
+
+	for _, run := range runs {
+		if run.StartedAt.Before(since) {
+			continue
+		}
+		report.Runs++
+		switch run.Command {
+		case "check":
+			report.Checks++
+		case "ensure":
+			report.Ensures++
+		}
+		if run.Error != "" {
+			report.FailedRuns++
+		}
+		configs[run.ConfigHash] = true
+
+		for _, result := range run.Results {
+			u := usage[result.Name]
+			if u == nil {
+				u = &DependencyUsage{Name: result.Name}
+				usage[result.Name] = u
+			}
+			u.Runs++
+			if result.Error != "" {
+				u.Failures++
+			}
+			if drifted(result) {
+				u.Drifted++
+			}
+			if result.Change != ChangeNone {
+				u.Installs++
+				installTime[result.Name] += result.Duration
+				if result.Duration > u.SlowestInstall {
+					u.SlowestInstall = result.Duration
+				}
+			}
+		}
+	}
+
+	report.Configs = len(configs)
+	for name, u := range usage {
+		if u.Installs > 0 {
+			u.AverageInstall = installTime[name] / time.Duration(u.Installs)
+		}
+		report.Dependencies = append(report.Dependencies, *u)
+	}
+	sort.Slice(report.Dependencies, func(i, j int) bool {
+		return report.Dependencies[i].Name < report.Dependencies[j].Name
+	})
+	return report
+}
+
+// drifted reports whether a run found a dependency missing or not matching
+// its configuration: either a check found a problem other than an error, or
+// an ensure had to install or change it
+func drifted(result RunResult) bool {
+	switch result.Change {
+	case ChangeInstalled, ChangeUpgraded, ChangeDowngraded:
+		return true
+	}
+	return result.Problem != ProblemNone && result.Problem != ProblemError
+}
+
+// MostFailing returns up to n dependencies that failed at least once, most
+// failures first
+func (r *UsageReport) MostFailing(n int) []DependencyUsage {
+	return r.top(n, func(u DependencyUsage) bool { return u.Failures > 0 }, func(a, b DependencyUsage) bool {
+		return a.Failures > b.Failures
+	})
+}
+
+// SlowestInstalls returns up to n dependencies that were installed at least
+// once, slowest install first
+func (r *UsageReport) SlowestInstalls(n int) []DependencyUsage {
+	return r.top(n, func(u DependencyUsage) bool { return u.Installs > 0 }, func(a, b DependencyUsage) bool {
+		return a.SlowestInstall > b.SlowestInstall
+	})
+}
+
+// MostDrifting returns up to n dependencies that drifted at least once,
+// highest drift rate first
+func (r *UsageReport) MostDrifting(n int) []DependencyUsage {
+	return r.top(n, func(u DependencyUsage) bool { return u.Drifted > 0 }, func(a, b DependencyUsage) bool {
+		return a.DriftRate() > b.DriftRate()
+	})
+}
+
+// top returns up to n dependencies matching keep, ordered by less and then
+// by name. n <= 0 means no limit.
+func (r *UsageReport) top(n int, keep func(DependencyUsage) bool, less func(a, b DependencyUsage) bool) []DependencyUsage {
+	var selected []DependencyUsage
+	for _, u := range r.Dependencies {
+		if keep(u) {
+			selected = append(selected, u)
+		}
+	}
+	// Dependencies are sorted by name, so ties stay in that order
+	sort.SliceStable(selected, func(i, j int) bool {
+		return less(selected[i], selected[j])
+	})
+	if n > 0 && len(selected) > n {
+		selected = selected[:n]
+	}
+	return selected
+}
@@ -0,0 +1,55 @@
+package depman
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUsageReport(t *testing.T) {
+	now := time.Now()
+	runs := []*Run{
+		{Command: "ensure", ConfigHash: "a", StartedAt: now.Add(-time.Hour), Error: "failed", Results: []RunResult{
+			{Name: "node", Change: ChangeUpgraded, Duration: 4 * time.Second},
+			{Name: "go", Problem: ProblemMissing, Error: "install failed"},
+		}},
+		{Command: "check", ConfigHash: "a", StartedAt: now.Add(-2 * time.Hour), Results: []RunResult{
+			{Name: "node", Problem: ProblemMinor},
+			{Name: "go", Problem: ProblemError, Error: "no version"},
+		}},
+		{Command: "ensure", ConfigHash: "b", StartedAt: now.Add(-3 * time.Hour), Results: []RunResult{
+			{Name: "node", Change: ChangeInstalled, Duration: 2 * time.Second},
+			{Name: "go", Change: ChangeInstalled, Duration: 10 * time.Second},
+		}},
+		// Before the period
+		{Command: "ensure", ConfigHash: "c", StartedAt: now.Add(-48 * time.Hour), Results: []RunResult{
+			{Name: "jq", Error: "install failed"},
+		}},
+	}
+
+	report := NewUsageReport(runs, now.Add(-24*time.Hour))
+	if report.Runs != 3 || report.Checks != 1 || report.Ensures != 2 || report.FailedRuns != 1 || report.Configs != 2 {
+		t.Errorf("Unexpected run counts: %+v", report)
+	}
+	if len(report.Dependencies) != 2 || report.Dependencies[0].Name != "go" || report.Dependencies[1].Name != "node" {
+		t.Fatalf("Expected go and node but got %+v", report.Dependencies)
+	}
+
+	node := report.Dependencies[1]
+	if node.Runs != 3 || node.Failures != 0 || node.Drifted != 3 || node.Installs != 2 || node.SlowestInstall != 4*time.Second || node.AverageInstall != 3*time.Second {
+		t.Errorf("Unexpected figures for node: %+v", node)
+	}
+	goUsage := report.Dependencies[0]
+	if goUsage.Failures != 2 || goUsage.Drifted != 2 || goUsage.Installs != 1 {
+		t.Errorf("Unexpected figures for go: %+v", goUsage)
+	}
+
+	if failing := report.MostFailing(5); len(failing) != 1 || failing[0].Name != "go" {
+		t.Errorf("Expected only go to be failing but got %+v", failing)
+	}
+	if slowest := report.SlowestInstalls(1); len(slowest) != 1 || slowest[0].Name != "go" {
+		t.Errorf("Expected go to be the slowest install but got %+v", slowest)
+	}
+	if drifting := report.MostDrifting(0); len(drifting) != 2 || drifting[0].Name != "node" {
+		t.Errorf("Expected node to drift most but got %+v", drifting)
+	}
+}
@@ -0,0 +1,583 @@
+package depman
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/devnadeemashraf/depman/internal/credentials"
+	"gopkg.in/yaml.v3"
+)
+
+// knownPlatforms are the platform names accepted under a dependency's platforms
+var knownPlatforms = []string{"windows", "linux", "darwin", "freebsd", "openbsd", "netbsd"}
+
+// ValidationIssue is a single problem found in a configuration file
+type ValidationIssue struct {
+	File    string // Configuration file the issue was found in
+	Line    int    // Line of the offending value (0 if unknown)
+	Column  int    // Column of the offending value (0 if unknown)
+	Path    string // Location in the document, e.g. "dependencies[0].platforms"
+	Message string // What is wrong
+}
+
+func (i ValidationIssue) String() string {
+	location := i.File
+	if i.Line > 0 {
+		location = fmt.Sprintf("%s:%d:%d", i.File, i.Line, i.Column)
+	}
+	if i.Path == "" {
+		return fmt.Sprintf("%s: %s", location, i.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", location, i.Path, i.Message)
+}
+
+// ValidationError reports every schema problem found in a configuration file
+type ValidationError struct {
+	Issues []ValidationIssue
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, len(e.Issues))
+	for i, issue := range e.Issues {
+		lines[i] = issue.String()
+	}
+	return fmt.Sprintf("invalid configuration:\n  %s", strings.Join(lines, "\n  "))
+}
+
+// ValidateConfigFile checks a configuration file, its includes and its local
+// overlay against the schema without loading them into a manager. Schema
+// problems are returned as a *ValidationError.
+func ValidateConfigFile(path string) error {
+	config, err := LoadDependencyConfig(path)
+	if err != nil {
+		return err
+	}
+
+	// Every profile must apply cleanly, e.g. only remove defined dependencies
+	names := make([]string, 0, len(config.Profiles))
+	for name := range config.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, err := applyProfile(config, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseAndValidate parses configuration data and validates it against the
+// schema. Dependencies named in known were defined by an earlier layer, so
+// this file may override parts of them without repeating required fields.
+func parseAndValidate(path string, data []byte, known map[string]bool) (*DependencyConfig, error) {
+	format := DetectConfigFormat(path, data)
+
+	// TOML is converted to YAML first, so its positions do not match the source
+	converted, err := toYAML(data, format)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse dependency file: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(converted, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse dependency file: %w", err)
+	}
+
+	// A file written for a newer depman is rejected before its fields are checked
+	if len(doc.Content) > 0 {
+		if err := checkRequirements(path, doc.Content[0]); err != nil {
+			return nil, err
+		}
+	}
+
+	v := &schemaValidator{file: path, positions: format != FormatTOML, known: known}
+	if len(doc.Content) > 0 {
+		v.validateNode(doc.Content[0], reflect.TypeOf(DependencyConfig{}), "")
+		v.validateSemantics(doc.Content[0])
+	}
+	if len(v.issues) > 0 {
+		sort.SliceStable(v.issues, func(i, j int) bool {
+			return v.issues[i].Line < v.issues[j].Line
+		})
+		return nil, &ValidationError{Issues: v.issues}
+	}
+
+	var config DependencyConfig
+	if err := doc.Decode(&config); err != nil {
+		return nil, fmt.Errorf("failed to parse dependency file: %w", err)
+	}
+
+	return &config, nil
+}
+
+// schemaValidator walks a YAML node tree alongside the configuration types
+type schemaValidator struct {
+	file      string
+	positions bool            // Whether node positions refer to the source file
+	known     map[string]bool // Dependencies defined by earlier layers
+	issues    []ValidationIssue
+}
+
+// addIssue records a problem at a node
+func (v *schemaValidator) addIssue(node *yaml.Node, path, format string, args ...interface{}) {
+	issue := ValidationIssue{File: v.file, Path: path, Message: fmt.Sprintf(format, args...)}
+	if v.positions && node != nil {
+		issue.Line, issue.Column = node.Line, node.Column
+	}
+	v.issues = append(v.issues, issue)
+}
+
+// validateNode checks that a node has the shape of the Go type t, reporting
+// unknown fields and values of the wrong kind
+func (v *schemaValidator) validateNode(node *yaml.Node, t reflect.Type, path string) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Tag == "!!null" {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			v.addIssue(node, path, "expected a mapping")
+			return
+		}
+		fields := yamlFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			field, ok := fields[key.Value]
+			if !ok {
+				v.addIssue(key, path, "unknown field '%s'%s", key.Value, suggestField(key.Value, fields))
+				continue
+			}
+			v.validateNode(value, field, joinPath(path, key.Value))
+		}
+
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			v.addIssue(node, path, "expected a mapping")
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			v.validateNode(value, t.Elem(), joinPath(path, key.Value))
+		}
+
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			v.addIssue(node, path, "expected a list")
+			return
+		}
+		for i, item := range node.Content {
+			v.validateNode(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i))
+		}
+
+	case reflect.String:
+		if node.Kind != yaml.ScalarNode {
+			v.addIssue(node, path, "expected a string")
+		} else if node.Tag != "!!str" {
+			v.addIssue(node, path, "expected a string but got %s; quote the value", strings.TrimPrefix(node.Tag, "!!"))
+		}
+
+	case reflect.Bool:
+		if node.Kind != yaml.ScalarNode || node.Tag != "!!bool" {
+			v.addIssue(node, path, "expected true or false")
+		}
+
+	case reflect.Int:
+		if node.Kind != yaml.ScalarNode || node.Tag != "!!int" {
+			v.addIssue(node, path, "expected an integer")
+		}
+	}
+}
+
+// validateSemantics checks the scope, credential and mirror entries, required keys, platform names, duplicate
+// names and malformed version constraints, patterns and timeouts, in the
+// top-level dependencies and in those of each profile
+func (v *schemaValidator) validateSemantics(root *yaml.Node) {
+	if scope := mappingValue(root, "scope"); scope != nil && scope.Value != "" {
+		if _, err := ParseScope(scope.Value); err != nil {
+			v.addIssue(scope, "scope", "%v", err)
+		}
+	}
+
+	if requires := mappingValue(root, "requires_depman"); requires != nil && requires.Value != "" {
+		if _, err := ParseConstraint(requires.Value); err != nil {
+			v.addIssue(requires, "requires_depman", "%v", err)
+		}
+	}
+
+	if creds := mappingValue(root, "credentials"); creds != nil && creds.Kind == yaml.SequenceNode {
+		for i, cred := range creds.Content {
+			if cred.Kind != yaml.MappingNode {
+				continue
+			}
+			path := fmt.Sprintf("credentials[%d]", i)
+			if host := mappingValue(cred, "host"); host == nil || host.Value == "" {
+				v.addIssue(cred, path, "missing required field 'host'")
+			}
+			if kind := mappingValue(cred, "type"); kind != nil && kind.Value != "" {
+				if _, err := credentials.ParseKind(kind.Value); err != nil {
+					v.addIssue(kind, path+".type", "%v", err)
+				}
+			}
+		}
+	}
+
+	if mirrors := mappingValue(root, "mirrors"); mirrors != nil && mirrors.Kind == yaml.SequenceNode {
+		for i, node := range mirrors.Content {
+			var mirror Mirror
+			if node.Kind != yaml.MappingNode || node.Decode(&mirror) != nil {
+				continue
+			}
+			if err := mirror.validate(); err != nil {
+				v.addIssue(node, fmt.Sprintf("mirrors[%d]", i), "%v", err)
+			}
+		}
+	}
+
+	if webhooks := mappingValue(root, "webhooks"); webhooks != nil && webhooks.Kind == yaml.SequenceNode {
+		for i, webhook := range webhooks.Content {
+			if webhook.Kind != yaml.MappingNode {
+				continue
+			}
+			path := fmt.Sprintf("webhooks[%d]", i)
+			if url := mappingValue(webhook, "url"); url == nil || url.Value == "" {
+				v.addIssue(webhook, path, "missing required field 'url'")
+			}
+			if events := mappingValue(webhook, "events"); events != nil && events.Kind == yaml.SequenceNode {
+				for j, event := range events.Content {
+					if event.Value != EventEnsureSucceeded && event.Value != EventEnsureFailed {
+						v.addIssue(event, fmt.Sprintf("%s.events[%d]", path, j), "unknown event '%s' (expected %s or %s)",
+							event.Value, EventEnsureSucceeded, EventEnsureFailed)
+					}
+				}
+			}
+			if timeout := mappingValue(webhook, "timeout"); timeout != nil {
+				if _, err := parseTimeout(timeout.Value); err != nil {
+					v.addIssue(timeout, path+".timeout", "invalid timeout: %v", err)
+				}
+			}
+		}
+	}
+
+	if tasks := mappingValue(root, "tasks"); tasks != nil && tasks.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(tasks.Content); i += 2 {
+			key, task := tasks.Content[i], tasks.Content[i+1]
+			if task.Kind != yaml.MappingNode {
+				continue
+			}
+			if run := mappingValue(task, "run"); run == nil || len(run.Content) == 0 {
+				v.addIssue(task, "tasks."+key.Value, "missing required field 'run'")
+			}
+		}
+	}
+
+	defined := v.validateDependencies(mappingValue(root, "dependencies"), "dependencies", v.known)
+
+	// Profiles may override any dependency defined so far
+	profiles := mappingValue(root, "profiles")
+	if profiles == nil || profiles.Kind != yaml.MappingNode {
+		return
+	}
+	for name := range v.known {
+		defined[name] = true
+	}
+	for i := 0; i+1 < len(profiles.Content); i += 2 {
+		key, profile := profiles.Content[i], profiles.Content[i+1]
+		v.validateDependencies(mappingValue(profile, "dependencies"), joinPath("profiles."+key.Value, "dependencies"), defined)
+	}
+}
+
+// validateKindSpec checks the image or service block of a dependency of that kind
+func (v *schemaValidator) validateKindSpec(dep *yaml.Node, path string, kind DependencyKind) {
+	field := string(kind)
+	block := mappingValue(dep, field)
+	if block == nil || block.Kind != yaml.MappingNode {
+		v.addIssue(dep, path, "missing required field '%s'", field)
+	} else {
+		// Type mismatches were reported by the schema check
+		var decoded Dependency
+		if err := dep.Decode(&decoded); err == nil {
+			if err := validateKindSpec(&decoded); err != nil {
+				v.addIssue(block, path+"."+field, "%v", err)
+			}
+		}
+	}
+	if version := mappingValue(dep, "version"); version != nil {
+		v.addIssue(version, path+".version", "%s dependencies must not set a version", kind)
+	}
+}
+
+// validateDependencies checks a list of dependencies and returns the names it
+// defines. Dependencies named in known only need to list what they override.
+func (v *schemaValidator) validateDependencies(deps *yaml.Node, prefix string, known map[string]bool) map[string]bool {
+	seen := make(map[string]bool)
+	if deps == nil || deps.Kind != yaml.SequenceNode {
+		return seen
+	}
+
+	for i, dep := range deps.Content {
+		if dep.Kind != yaml.MappingNode {
+			continue
+		}
+		path := fmt.Sprintf("%s[%d]", prefix, i)
+
+		name := mappingValue(dep, "name")
+		if name == nil || name.Value == "" {
+			v.addIssue(dep, path, "missing required field 'name'")
+		} else {
+			if seen[name.Value] {
+				v.addIssue(name, path, "duplicate dependency '%s'", name.Value)
+			}
+			seen[name.Value] = true
+			path = fmt.Sprintf("%s[%d] (%s)", prefix, i, name.Value)
+		}
+
+		// Overrides of dependencies from included files and dependencies
+		// based on registry recipes only list what changes
+		from := mappingValue(dep, "from")
+		if from != nil && from.Value != "" {
+			v.validateFrom(from, path+".from")
+		}
+		override := name != nil && known[name.Value] || from != nil && from.Value != ""
+
+		if variables := mappingValue(mappingValue(dep, "environment"), "variables"); variables != nil && variables.Kind == yaml.MappingNode {
+			for j := 0; j+1 < len(variables.Content); j += 2 {
+				if err := ValidateVariableName(variables.Content[j].Value); err != nil {
+					v.addIssue(variables.Content[j], path+".environment.variables", "%v", err)
+				}
+			}
+		}
+
+		// Images and services are described by their own block instead of
+		// versions and platform entries
+		if kind := mappingValue(dep, "kind"); kind != nil && kind.Value != "" {
+			parsed, err := ParseDependencyKind(kind.Value)
+			if err != nil {
+				v.addIssue(kind, path+".kind", "%v", err)
+			} else if parsed != KindTool {
+				v.validateKindSpec(dep, path, parsed)
+				continue
+			}
+		}
+
+		version := mappingValue(dep, "version")
+		required := mappingValue(version, "required")
+		constraint := mappingValue(version, "constraint")
+		if !override && (required == nil || required.Value == "") && (constraint == nil || constraint.Value == "") {
+			v.addIssue(dep, path, "missing required field 'version.required' or 'version.constraint'")
+		}
+		if constraint != nil && constraint.Value != "" {
+			if _, err := ParseConstraint(constraint.Value); err != nil {
+				v.addIssue(constraint, path+".version.constraint", "%v", err)
+			}
+		}
+
+		if timeout := mappingValue(dep, "timeout"); timeout != nil && timeout.Value != "" {
+			if _, err := parseTimeout(timeout.Value); err != nil {
+				v.addIssue(timeout, path+".timeout", "invalid timeout: %v", err)
+			}
+		}
+
+		if target := mappingValue(dep, "wsl"); target != nil && target.Value != "" {
+			if _, err := ParseWSLTarget(target.Value); err != nil {
+				v.addIssue(target, path+".wsl", "%v", err)
+			}
+		}
+
+		// A dependency cannot provide or conflict with itself, nor conflict with what it needs
+		if name != nil && name.Value != "" {
+			var requires []string
+			if deps := mappingValue(dep, "dependencies"); deps != nil && deps.Kind == yaml.SequenceNode {
+				for _, required := range deps.Content {
+					requires = append(requires, required.Value)
+				}
+			}
+			for _, field := range []string{"provides", "conflicts_with"} {
+				list := mappingValue(dep, field)
+				if list == nil || list.Kind != yaml.SequenceNode {
+					continue
+				}
+				for j, item := range list.Content {
+					itemPath := fmt.Sprintf("%s.%s[%d]", path, field, j)
+					switch {
+					case item.Value == name.Value:
+						v.addIssue(item, itemPath, "dependency '%s' cannot list itself", name.Value)
+					case field == "conflicts_with" && slices.Contains(requires, item.Value):
+						v.addIssue(item, itemPath, "dependency '%s' conflicts with '%s', which it depends on", name.Value, item.Value)
+					}
+				}
+			}
+		}
+
+		if when := mappingValue(dep, "when"); when != nil && when.Value != "" {
+			if err := checkCondition(when.Value); err != nil {
+				v.addIssue(when, path+".when", "invalid when: %v", err)
+			}
+		}
+
+		if check := mappingValue(dep, "healthcheck"); check != nil && check.Kind == yaml.MappingNode {
+			// Type mismatches were reported by the schema check
+			var decoded HealthCheck
+			if err := check.Decode(&decoded); err == nil {
+				if err := decoded.validate(); err != nil {
+					v.addIssue(check, path+".healthcheck", "%v", err)
+				}
+			}
+		}
+
+		if pattern := mappingValue(dep, "version_regex"); pattern != nil {
+			if _, err := regexp.Compile(pattern.Value); err != nil {
+				v.addIssue(pattern, path+".version_regex", "invalid pattern: %v", err)
+			}
+		}
+
+		platforms := mappingValue(dep, "platforms")
+		if platforms == nil || platforms.Kind != yaml.MappingNode || len(platforms.Content) == 0 {
+			if !override {
+				v.addIssue(dep, path, "missing required field 'platforms'")
+			}
+			continue
+		}
+		for j := 0; j+1 < len(platforms.Content); j += 2 {
+			key := platforms.Content[j]
+			if !isKnownPlatformKey(key.Value) {
+				v.addIssue(key, path+".platforms", "unknown platform '%s' (expected one of %s, optionally with /%s)",
+					key.Value, strings.Join(knownPlatforms, ", "), strings.Join(knownArchs, ", /"))
+			}
+			if installer := mappingValue(platforms.Content[j+1], "installer"); installer != nil {
+				v.validateScriptInstaller(installer, path+".platforms."+key.Value+".installer")
+			}
+		}
+	}
+
+	return seen
+}
+
+// validateScriptInstaller checks the script settings of a "script" installer
+func (v *schemaValidator) validateScriptInstaller(installer *yaml.Node, path string) {
+	method := mappingValue(installer, "method")
+	if method == nil || strings.ToLower(method.Value) != "script" {
+		return
+	}
+
+	script, file := mappingValue(installer, "script"), mappingValue(installer, "script_file")
+	if (script == nil || script.Value == "") == (file == nil || file.Value == "") {
+		v.addIssue(installer, path, "exactly one of 'script' and 'script_file' is required")
+	}
+	if shell := mappingValue(installer, "shell"); shell != nil && shell.Value != "" {
+		if _, ok := scriptShells[strings.ToLower(shell.Value)]; !ok {
+			v.addIssue(shell, path+".shell", "unknown shell '%s' (expected sh, bash, pwsh, powershell or cmd)", shell.Value)
+		}
+	}
+	if timeout := mappingValue(installer, "timeout"); timeout != nil && timeout.Value != "" {
+		if _, err := parseTimeout(timeout.Value); err != nil {
+			v.addIssue(timeout, path+".timeout", "invalid timeout: %v", err)
+		}
+	}
+}
+
+// isKnownPlatform reports whether a platform name is supported
+func isKnownPlatform(platform string) bool {
+	for _, known := range knownPlatforms {
+		if platform == known {
+			return true
+		}
+	}
+	return false
+}
+
+// validateFrom checks a from reference names a recipe in the registries
+func (v *schemaValidator) validateFrom(from *yaml.Node, path string) {
+	registry, err := LoadRegistry(DefaultHomeDir())
+	if err != nil {
+		v.addIssue(from, path, "%v", err)
+		return
+	}
+	if _, err := registry.Lookup(from.Value); err != nil {
+		v.addIssue(from, path, "%v", err)
+	}
+}
+
+// mappingValue returns the value for a key in a mapping node, or nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// yamlFields maps the YAML keys of a struct type to their field types
+func yamlFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if tag == "" || tag == "-" {
+			continue
+		}
+		fields[tag] = field.Type
+	}
+	return fields
+}
+
+// suggestField returns a hint naming the closest known field, if any is close
+func suggestField(key string, fields map[string]reflect.Type) string {
+	var names []string
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	best, bestDistance := "", 3
+	for _, name := range names {
+		if d := editDistance(key, name); d < bestDistance {
+			best, bestDistance = name, d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (did you mean '%s'?)", best)
+}
+
+// editDistance returns the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+// joinPath appends a key to a document path
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,281 @@
+package depman
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateConfigFile(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "depman-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	testCases := []struct {
+		name     string
+		file     string
+		content  string
+		expected []string // Expected issues, as substrings in order
+	}{
+		{
+			name: "Valid config",
+			file: "valid.yml",
+			content: `
+version: "1.0"
+name: "Test App"
+dependencies:
+  - name: "jq"
+    version:
+      constraint: "^1.6"
+    platforms:
+      linux:
+        installer:
+          method: "system"
+`,
+		},
+		{
+			name: "Unknown field with suggestion",
+			file: "typo.yml",
+			content: `
+version: "1.0"
+dependencies:
+  - name: "jq"
+    version:
+      required: "1.7.1"
+    platforms:
+      linux:
+        instaler:
+          method: "system"
+`,
+			expected: []string{"typo.yml:9:9: dependencies[0].platforms.linux: unknown field 'instaler' (did you mean 'installer'?)"},
+		},
+		{
+			name: "Missing required keys",
+			file: "missing.yml",
+			content: `
+version: "1.0"
+dependencies:
+  - description: "no name"
+`,
+			expected: []string{
+				"missing.yml:4:5: dependencies[0]: missing required field 'name'",
+				"missing required field 'version.required' or 'version.constraint'",
+				"missing required field 'platforms'",
+			},
+		},
+		{
+			name: "Invalid platform and constraint",
+			file: "invalid.yml",
+			content: `
+version: "1.0"
+dependencies:
+  - name: "jq"
+    version:
+      constraint: ">=1 <<2"
+    platforms:
+      macos:
+        installer:
+          method: "brew"
+`,
+			expected: []string{
+				"invalid.yml:6:19: dependencies[0] (jq).version.constraint: invalid constraint '>=1 <<2'",
+				"invalid.yml:8:7: dependencies[0] (jq).platforms: unknown platform 'macos'",
+			},
+		},
+		{
+			name: "Wrong value types",
+			file: "types.yml",
+			content: `
+version: "1.0"
+dependencies:
+  - name: "jq"
+    version:
+      required: 1.7
+    platforms:
+      linux:
+        installer:
+          pin: "yes please"
+`,
+			expected: []string{
+				"types.yml:6:17: dependencies[0].version.required: expected a string but got float",
+				"types.yml:10:16: dependencies[0].platforms.linux.installer.pin: expected true or false",
+			},
+		},
+		{
+			name: "Unknown scope",
+			file: "scope.yml",
+			content: `
+scope: "system"
+dependencies: []
+`,
+			expected: []string{"scope.yml:2:8: scope: invalid scope 'system'"},
+		},
+		{
+			name: "Invalid environment variable names",
+			file: "variables.yml",
+			content: `
+dependencies:
+  - name: "jq"
+    version:
+      required: "1.7.1"
+    environment:
+      variables:
+        "X; touch pwned; Y": "1"
+        PROMPT_COMMAND: "curl evil"
+    platforms:
+      linux: {}
+`,
+			expected: []string{
+				"variables.yml:8:9: dependencies[0] (jq).environment.variables: invalid environment variable name 'X; touch pwned; Y'",
+				"variables.yml:9:9: dependencies[0] (jq).environment.variables: environment variable PROMPT_COMMAND cannot be set",
+			},
+		},
+		{
+			name: "Invalid timeout",
+			file: "timeout.yml",
+			content: `
+dependencies:
+  - name: "jq"
+    version:
+      required: "1.7.1"
+    timeout: "soon"
+    platforms:
+      linux: {}
+`,
+			expected: []string{"timeout.yml:6:14: dependencies[0] (jq).timeout: invalid timeout"},
+		},
+		{
+			name: "Invalid healthcheck",
+			file: "healthcheck.yml",
+			content: `
+dependencies:
+  - name: "docker"
+    version:
+      required: "25.0.0"
+    healthcheck:
+      command: ["docker", "info"]
+      http: "http://localhost:2375/_ping"
+    platforms:
+      linux: {}
+`,
+			expected: []string{"healthcheck.yml:7:7: dependencies[0] (docker).healthcheck: expected exactly one of command or http"},
+		},
+		{
+			name: "Invalid credentials",
+			file: "credentials.yml",
+			content: `
+credentials:
+  - type: "token"
+dependencies: []
+`,
+			expected: []string{
+				"credentials.yml:3:5: credentials[0]: missing required field 'host'",
+				"credentials.yml:3:11: credentials[0].type: unknown credential type 'token'",
+			},
+		},
+		{
+			name: "Invalid webhooks",
+			file: "webhooks.yml",
+			content: `
+webhooks:
+  - events: ["ensure_done"]
+    timeout: "soon"
+dependencies: []
+`,
+			expected: []string{
+				"webhooks.yml:3:5: webhooks[0]: missing required field 'url'",
+				"webhooks.yml:3:14: webhooks[0].events[0]: unknown event 'ensure_done' (expected ensure_succeeded or ensure_failed)",
+				"webhooks.yml:4:14: webhooks[0].timeout: invalid timeout",
+			},
+		},
+		{
+			name: "Invalid profile",
+			file: "profile.yml",
+			content: `
+dependencies:
+  - name: "jq"
+    version:
+      required: "1.7.1"
+    platforms:
+      linux: {}
+profiles:
+  ci:
+    dependencies:
+      - name: "jq"
+        version:
+          constraint: "^1.7"
+      - name: "go"
+`,
+			expected: []string{
+				"profile.yml:14:9: profiles.ci.dependencies[1] (go): missing required field 'version.required' or 'version.constraint'",
+				"missing required field 'platforms'",
+			},
+		},
+		{
+			name: "Image",
+			file: "image.yml",
+			content: `
+dependencies:
+  - name: "postgres"
+    kind: "image"
+    image:
+      name: "postgres:16.2"
+      digest: "sha256:4aea012537edfad80f98d870a36e6b90b4c09b27be7f4b4759d72db863baeebb"
+  - name: "redis"
+    kind: "image"
+    version:
+      required: "7.2.4"
+    image:
+      name: "redis:7.2.4"
+      digest: "latest"
+`,
+			expected: []string{
+				"image.yml:11:7: dependencies[1] (redis).version: image dependencies must not set a version",
+				"image.yml:13:7: dependencies[1] (redis).image: invalid image digest 'latest'",
+			},
+		},
+		{
+			name: "Duplicate names in JSON",
+			file: "dup.json",
+			content: `{"dependencies": [
+  {"name": "jq", "version": {"required": "1.0.0"}, "platforms": {"linux": {}}},
+  {"name": "jq", "version": {"required": "1.0.0"}, "platforms": {"linux": {}}}
+]}`,
+			expected: []string{"dup.json:3:12: dependencies[1]: duplicate dependency 'jq'"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(tempDir, tc.file)
+			if err := os.WriteFile(path, []byte(tc.content), 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+
+			err := ValidateConfigFile(path)
+			if len(tc.expected) == 0 {
+				if err != nil {
+					t.Errorf("Did not expect an error but got: %v", err)
+				}
+				return
+			}
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("Expected a ValidationError but got %v", err)
+			}
+			if len(validationErr.Issues) != len(tc.expected) {
+				t.Fatalf("Expected %d issues but got: %v", len(tc.expected), validationErr)
+			}
+			for i, expected := range tc.expected {
+				if got := validationErr.Issues[i].String(); !strings.Contains(got, expected) {
+					t.Errorf("Expected issue %q but got %q", expected, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,273 @@
+package depman
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/devnadeemashraf/depman/internal/cache"
+	"github.com/devnadeemashraf/depman/internal/downloader"
+	"github.com/devnadeemashraf/depman/internal/telemetry"
+	"github.com/devnadeemashraf/depman/internal/verify"
+)
+
+// VerificationError reports that a downloaded artifact failed checksum or
+// signature verification. It is returned by installs and recorded in the
+// dependency's status so callers can tell tampered or corrupt downloads
+// apart from ordinary install failures.
+type VerificationError struct {
+	Dependency string // Name of the dependency
+	URL        string // URL of the artifact that failed verification
+	Kind       string // What failed: "checksum" or "signature"
+	Err        error  // Underlying cause
+}
+
+func (e *VerificationError) Error() string {
+	return fmt.Sprintf("%s verification failed for %s (%s): %v", e.Kind, e.Dependency, redactURL(e.URL), e.Err)
+}
+
+func (e *VerificationError) Unwrap() error {
+	return e.Err
+}
+
+// downloadArtifact downloads an installer artifact into destDir and verifies it
+// against the configured checksum, checksums file and signature. Every
+// download-based install method goes through here.
+func (m *Manager) downloadArtifact(ctx context.Context, dep *Dependency, platformConfig *PlatformConfig, url, checksum, destDir string) (*downloader.Result, error) {
+	installer := platformConfig.Installer
+	source, err := m.fetchURL(url, dep)
+	if err != nil {
+		return nil, err
+	}
+
+	var checksumURL, signatureURL string
+	if installer.ChecksumURL != "" {
+		if checksumURL, err = m.fetchURL(m.expandArtifactURL(installer.ChecksumURL, dep, url), dep); err != nil {
+			return nil, err
+		}
+	}
+	if installer.SignatureURL != "" {
+		if signatureURL, err = m.fetchURL(m.expandArtifactURL(installer.SignatureURL, dep, url), dep); err != nil {
+			return nil, err
+		}
+	}
+	if err := m.orgPolicy.checkArtifact(dep, installer, source, checksumURL, signatureURL, checksum); err != nil {
+		return nil, err
+	}
+
+	// Offline installs take every artifact from the bundle
+	if m.bundle != nil {
+		result, err := m.offlineArtifact(dep, url, checksum, destDir)
+		if err != nil {
+			return nil, err
+		}
+		if err := m.verifyArtifact(ctx, dep, platformConfig, url, result); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	if checksum == "" && installer.ChecksumURL != "" {
+		err := m.retry(ctx, dep, "Checksums lookup", func() error {
+			var err error
+			checksum, err = fetchChecksum(ctx, m.client(), checksumURL, path.Base(url))
+			return err
+		})
+		if err != nil {
+			return nil, &VerificationError{Dependency: dep.Name, URL: url, Kind: "checksum", Err: err}
+		}
+	}
+
+	// Artifacts with a known checksum may already be cached
+	downloads := cache.New(m.CacheDir())
+	cached, ok := downloads.Lookup(url, checksum, destDir)
+	m.telemetry.Record(metricCacheLookups, 1, telemetry.String("depman.cache.result", cacheResult(ok)))
+	if ok {
+		m.componentLogger("download", dep).Infof("Using cached download of %s from %s", dep.Name, redactURL(url))
+		result := &downloader.Result{FilePath: cached, Checksum: checksum}
+		if info, err := os.Stat(cached); err == nil {
+			result.Size = info.Size()
+		}
+		if err := m.verifyArtifact(ctx, dep, platformConfig, url, result); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	// Artifacts without a known checksum, such as "latest" downloads, are
+	// revalidated with the server and taken from the cache while unchanged
+	var validators cache.Validators
+	var revalidated string
+	if checksum == "" {
+		if v, ok := downloads.Validators(url); ok {
+			if cached, ok := downloads.Lookup(url, v.Checksum, destDir); ok {
+				validators, revalidated = v, cached
+			}
+		}
+	}
+
+	m.componentLogger("download", dep).Infof("Downloading %s from %s", dep.Name, redactURL(source))
+	reporter := m.reporter()
+	ctx, span := m.telemetry.Start(ctx, "depman.step."+string(StepDownload),
+		telemetry.String("depman.dependency", dep.Name), telemetry.String("depman.step", string(StepDownload)))
+	reporter.OnStepStart(dep.Name, StepDownload)
+	var result *downloader.Result
+	err = m.retry(ctx, dep, "Download", func() error {
+		var err error
+		result, err = downloader.Download(ctx, downloader.DownloadOptions{
+			URL:          source,
+			Checksum:     checksum,
+			DestDir:      destDir,
+			ShowProgress: true,
+			Client:       m.client(),
+			Progress: func(downloaded, total int64) {
+				reporter.OnDownloadProgress(dep.Name, downloaded, total)
+			},
+			ETag:         validators.ETag,
+			LastModified: validators.LastModified,
+			Limiter:      m.limiter,
+		})
+		return err
+	})
+	unchanged := errors.Is(err, downloader.ErrNotModified)
+	if unchanged {
+		result, err = &downloader.Result{FilePath: revalidated, Checksum: validators.Checksum}, nil
+		if info, statErr := os.Stat(revalidated); statErr == nil {
+			result.Size = info.Size()
+		}
+	}
+	reporter.OnStepEnd(dep.Name, StepDownload, err)
+	span.End(err)
+	if err != nil {
+		var mismatch *verify.ChecksumError
+		if errors.As(err, &mismatch) {
+			return nil, &VerificationError{Dependency: dep.Name, URL: url, Kind: "checksum", Err: err}
+		}
+		return nil, fmt.Errorf("failed to download dependency: %w", err)
+	}
+	if unchanged {
+		m.componentLogger("download", dep).Infof("%s at %s is unchanged, using the cached download", dep.Name, redactURL(url))
+		if err := m.verifyArtifact(ctx, dep, platformConfig, url, result); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+	m.componentLogger("download", dep).Infof("Downloaded %s (%d bytes)", dep.Name, result.Size)
+
+	if err := m.verifyArtifact(ctx, dep, platformConfig, url, result); err != nil {
+		return nil, err
+	}
+
+	// Only verified artifacts are cached, with the validators of downloads
+	// whose checksum was not known so the next run can revalidate them
+	if err := downloads.Store(url, result.Checksum, result.FilePath); err != nil {
+		m.componentLogger("download", dep).Warnf("Failed to cache download of %s: %v", dep.Name, err)
+	} else if checksum == "" && (result.ETag != "" || result.LastModified != "") {
+		v := cache.Validators{Checksum: result.Checksum, ETag: result.ETag, LastModified: result.LastModified}
+		if err := downloads.SetValidators(url, v); err != nil {
+			m.componentLogger("download", dep).Warnf("Failed to cache download of %s: %v", dep.Name, err)
+		}
+	}
+
+	return result, nil
+}
+
+// verifyArtifact checks the signature of a downloaded or cached artifact, if
+// one is configured, removing the artifact when the check fails
+func (m *Manager) verifyArtifact(ctx context.Context, dep *Dependency, platformConfig *PlatformConfig, url string, result *downloader.Result) error {
+	if platformConfig.Installer.SignatureURL == "" {
+		return nil
+	}
+
+	if err := m.verifySignature(ctx, dep, platformConfig, url, result.FilePath); err != nil {
+		os.Remove(result.FilePath)
+		return &VerificationError{Dependency: dep.Name, URL: url, Kind: "signature", Err: err}
+	}
+	return nil
+}
+
+// verifySignature downloads the detached signature for an artifact and checks it
+func (m *Manager) verifySignature(ctx context.Context, dep *Dependency, platformConfig *PlatformConfig, url, filePath string) error {
+	installer := platformConfig.Installer
+
+	signatureURL := m.expandArtifactURL(installer.SignatureURL, dep, url)
+	var signature *downloader.Result
+	var err error
+	if m.bundle != nil {
+		signature, err = m.offlineArtifact(dep, signatureURL, "", filepath.Dir(filePath))
+	} else {
+		var source string
+		if source, err = m.fetchURL(signatureURL, dep); err != nil {
+			return err
+		}
+		err = m.retry(ctx, dep, "Signature download", func() error {
+			var err error
+			signature, err = downloader.Download(ctx, downloader.DownloadOptions{
+				URL:     source,
+				DestDir: filepath.Dir(filePath),
+				Client:  m.client(),
+				Limiter: m.limiter,
+			})
+			return err
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to download signature: %w", err)
+	}
+	defer os.Remove(signature.FilePath)
+
+	kind := installer.SignatureType
+	if kind == "" {
+		kind = "gpg"
+	}
+
+	m.componentLogger("download", dep).Debugf("Verifying %s signature of %s", kind, filepath.Base(filePath))
+	return verify.Signature(ctx, kind, filePath, signature.FilePath, installer.PublicKey)
+}
+
+// expandArtifactURL expands a checksum or signature URL template, which may
+// also refer to the artifact itself with {{url}}
+func (m *Manager) expandArtifactURL(template string, dep *Dependency, artifactURL string) string {
+	return expandURLTemplate(strings.ReplaceAll(template, "{{url}}", artifactURL), dep, m.Platform, m.targetArch())
+}
+
+// fetchURL returns the URL an artifact is fetched from: with the ${VAR}
+// references interpolateURL kept resolved and the mirrors applied
+func (m *Manager) fetchURL(url string, dep *Dependency) (string, error) {
+	expanded, err := m.expandURL(url, dep)
+	if err != nil {
+		return "", err
+	}
+	return m.mirrorURL(expanded), nil
+}
+
+// fetchChecksum downloads a checksums file and returns the entry for filename
+func fetchChecksum(ctx context.Context, client *http.Client, url, filename string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch checksums file: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to fetch checksums file: %w",
+			&downloader.StatusError{URL: url, StatusCode: resp.StatusCode, Status: resp.Status})
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read checksums file: %w", err)
+	}
+
+	return verify.FindInChecksumsFile(string(data), filename)
+}
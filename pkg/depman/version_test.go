@@ -1,6 +1,7 @@
 package depman
 
 import (
+	"errors"
 	"testing"
 )
 
@@ -165,3 +166,115 @@ func TestIsVersionCompatible(t *testing.T) {
 		})
 	}
 }
+
+func TestParseConstraint(t *testing.T) {
+	testCases := []struct {
+		constraint string
+		version    string
+		expected   bool
+	}{
+		{constraint: ">=1.2 <2.0", version: "1.9.9", expected: true},
+		{constraint: ">=1.2 <2.0", version: "2.0.0", expected: false},
+		{constraint: ">= 1.2, < 2.0", version: "1.5.0", expected: true},
+		{constraint: ">=1.2 && <2.0", version: "1.1.0", expected: false},
+		{constraint: "~1.4", version: "1.4.7", expected: true},
+		{constraint: "~1.4", version: "1.5.0", expected: false},
+		{constraint: "~> 1.4", version: "1.4.2", expected: true},
+		{constraint: "^2.3.1", version: "2.9.0", expected: true},
+		{constraint: "^2.3.1", version: "2.3.0", expected: false},
+		{constraint: "1.x", version: "1.8.0", expected: true},
+		{constraint: "1.x", version: "2.0.0", expected: false},
+		{constraint: "==1.2.3", version: "1.2.3", expected: true},
+		{constraint: "^1 || ^3", version: "3.1.0", expected: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.constraint+" "+tc.version, func(t *testing.T) {
+			compatible, err := Version{Constraint: tc.constraint}.Satisfied(tc.version)
+			if err != nil {
+				t.Fatalf("Did not expect an error but got: %v", err)
+			}
+			if compatible != tc.expected {
+				t.Errorf("Expected compatible=%v but got %v", tc.expected, compatible)
+			}
+		})
+	}
+}
+
+func TestVersionResolve(t *testing.T) {
+	candidates := []string{"1.2.0", "1.4.2", "2.0.0", "not-a-version", "1.5.1"}
+
+	testCases := []struct {
+		name        string
+		version     Version
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "Exact required version",
+			version:  Version{Required: "1.4.2", Constraint: "^1.0.0"},
+			expected: "1.4.2",
+		},
+		{
+			name:     "Newest satisfying constraint",
+			version:  Version{Constraint: "~1.4"},
+			expected: "1.4.2",
+		},
+		{
+			name:     "Required unavailable falls back to constraint",
+			version:  Version{Required: "1.9.0", Constraint: "^1.0.0"},
+			expected: "1.5.1",
+		},
+		{
+			name:     "Newest without requirements",
+			version:  Version{},
+			expected: "2.0.0",
+		},
+		{
+			name:        "Required unavailable",
+			version:     Version{Required: "3.0.0"},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			i, version, err := tc.version.Resolve(candidates)
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("Expected an error but got %s", version)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Did not expect an error but got: %v", err)
+			}
+			if candidates[i] != tc.expected || version.String() != tc.expected {
+				t.Errorf("Expected %s but got %s", tc.expected, candidates[i])
+			}
+		})
+	}
+}
+
+func TestDependencyStatusProblem(t *testing.T) {
+	testCases := []struct {
+		name     string
+		status   DependencyStatus
+		expected Problem
+	}{
+		{"Up to date", DependencyStatus{Installed: true, Compatible: true}, ProblemNone},
+		{"Missing", DependencyStatus{Error: errors.New("not found")}, ProblemMissing},
+		{"Unparsable version", DependencyStatus{Installed: true, Compatible: true, Error: errors.New("invalid version")}, ProblemError},
+		{"Incompatible", DependencyStatus{Installed: true, RequiredUpdate: MajorUpdate}, ProblemIncompatible},
+		{"Minor update", DependencyStatus{Installed: true, Compatible: true, RequiredUpdate: MinorUpdate}, ProblemMinor},
+		{"Patch update", DependencyStatus{Installed: true, Compatible: true, RequiredUpdate: PatchUpdate}, ProblemPatch},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if problem := tc.status.Problem(); problem != tc.expected {
+				t.Errorf("Expected problem %q but got %q", tc.expected, problem)
+			}
+		})
+	}
+}
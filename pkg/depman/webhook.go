@@ -0,0 +1,152 @@
+package depman
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+// defaultWebhookTimeout limits webhooks that do not set a timeout
+const defaultWebhookTimeout = 10 * time.Second
+
+// Webhook events
+const (
+	EventEnsureSucceeded = "ensure_succeeded" // Every dependency is in place
+	EventEnsureFailed    = "ensure_failed"    // The run or at least one dependency failed
+)
+
+// Webhook posts the result of each ensure to an external endpoint as JSON
+type Webhook struct {
+	URL     string            `yaml:"url"`     // Endpoint the payload is POSTed to
+	Events  []string          `yaml:"events"`  // ensure_succeeded and/or ensure_failed (default: both)
+	Headers map[string]string `yaml:"headers"` // Extra request headers, e.g. "Authorization: Bearer ${TOKEN}"
+	Secret  string            `yaml:"secret"`  // Key the payload is signed with in X-Depman-Signature, if set
+	Timeout string            `yaml:"timeout"` // Maximum time per delivery, e.g. "5s" (default 10s)
+}
+
+// WebhookPayload is the body of a webhook request: the check report of the
+// run and where it ran
+type WebhookPayload struct {
+	Event   string `json:"event"`             // ensure_succeeded or ensure_failed
+	Project string `json:"project"`           // Name of the configuration
+	Host    string `json:"host"`              // Host name of the machine
+	Profile string `json:"profile,omitempty"` // Configuration profile applied, if any
+	Error   string `json:"error,omitempty"`   // Why the run failed, if it did
+	*CheckReport
+}
+
+// subscribes reports whether the webhook wants an event
+func (w Webhook) subscribes(event string) bool {
+	return len(w.Events) == 0 || containsFold(w.Events, event)
+}
+
+// notifyWebhooks delivers the result of an ensure to every webhook that
+// subscribes to it. Failed deliveries are logged and do not fail the run.
+func (m *Manager) notifyWebhooks(ctx context.Context, statuses map[string]*DependencyStatus, runErr error) {
+	if m.Config == nil || len(m.Config.Webhooks) == 0 {
+		return
+	}
+
+	payload := m.webhookPayload(statuses, runErr)
+	body, err := json.Marshal(payload)
+	if err != nil {
+		m.logger.Warnf("Failed to encode webhook payload: %v", err)
+		return
+	}
+
+	// Deliver the result of a cancelled run too
+	ctx = context.WithoutCancel(ctx)
+	for _, webhook := range m.Config.Webhooks {
+		if !webhook.subscribes(payload.Event) {
+			continue
+		}
+		if err := m.deliverWebhook(ctx, webhook, payload.Event, body); err != nil {
+			m.componentLogger("webhook", nil).Warnf("Webhook delivery failed: %v", err)
+		}
+	}
+}
+
+// webhookPayload describes the outcome of a run
+func (m *Manager) webhookPayload(statuses map[string]*DependencyStatus, runErr error) *WebhookPayload {
+	host, _ := os.Hostname()
+	payload := &WebhookPayload{
+		Event:       EventEnsureSucceeded,
+		Project:     m.Config.Name,
+		Host:        host,
+		Profile:     m.profile,
+		CheckReport: m.NewCheckReport(statuses),
+	}
+
+	if runErr != nil {
+		payload.Event = EventEnsureFailed
+		payload.Error = runErr.Error()
+	}
+	for _, status := range statuses {
+		if status.Error != nil {
+			payload.Event = EventEnsureFailed
+		}
+	}
+	return payload
+}
+
+// deliverWebhook posts a payload to one webhook
+func (m *Manager) deliverWebhook(ctx context.Context, webhook Webhook, event string, body []byte) error {
+	project := &Dependency{Name: m.Config.Name}
+	url, err := m.interpolate(webhook.URL, project)
+	if err != nil {
+		return err
+	}
+	timeout, err := parseTimeout(webhook.Timeout)
+	if err != nil {
+		return fmt.Errorf("invalid timeout of webhook %s: %w", webhook.URL, err)
+	}
+	if timeout == 0 {
+		timeout = defaultWebhookTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Depman-Event", event)
+	for name, value := range webhook.Headers {
+		if value, err = m.interpolate(value, project); err != nil {
+			return err
+		}
+		req.Header.Set(name, value)
+	}
+	if webhook.Secret != "" {
+		secret, err := m.interpolate(webhook.Secret, project)
+		if err != nil {
+			return err
+		}
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		req.Header.Set("X-Depman-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	// Messages show the URL as configured, without expanded secrets
+	resp, err := m.client().Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post to %s: %w", webhook.URL, errors.Unwrap(err))
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s answered %s", webhook.URL, resp.Status)
+	}
+	m.componentLogger("webhook", nil).Debugf("Delivered %s to %s", event, webhook.URL)
+	return nil
+}
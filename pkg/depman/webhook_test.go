@@ -0,0 +1,98 @@
+package depman
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNotifyWebhooks(t *testing.T) {
+	type delivery struct {
+		path      string
+		event     string
+		token     string
+		signature string
+		body      []byte
+	}
+	var deliveries []delivery
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		deliveries = append(deliveries, delivery{
+			path:      r.URL.Path,
+			event:     r.Header.Get("X-Depman-Event"),
+			token:     r.Header.Get("Authorization"),
+			signature: r.Header.Get("X-Depman-Signature"),
+			body:      body,
+		})
+	}))
+	defer server.Close()
+
+	t.Setenv("DEPMAN_TEST_TOKEN", "secret-token")
+	manager := &Manager{
+		Platform: "linux",
+		logger:   &mockLogger{},
+		Config: &DependencyConfig{
+			Name:         "app",
+			Dependencies: []Dependency{{Name: "jq"}, {Name: "yq"}},
+			Webhooks: []Webhook{
+				{URL: server.URL + "/all", Headers: map[string]string{"Authorization": "Bearer ${DEPMAN_TEST_TOKEN}"}, Secret: "key"},
+				{URL: server.URL + "/failures", Events: []string{EventEnsureFailed}},
+			},
+		},
+	}
+
+	statuses := map[string]*DependencyStatus{
+		"jq": {Name: "jq", Installed: true, CurrentVersion: "1.7.1", Compatible: true},
+		"yq": {Name: "yq", Installed: true, CurrentVersion: "4.44.2", Compatible: true},
+	}
+	manager.notifyWebhooks(context.Background(), statuses, nil)
+	if len(deliveries) != 1 || deliveries[0].path != "/all" {
+		t.Fatalf("Expected a single delivery to the webhook for all events but got %+v", deliveries)
+	}
+
+	success := deliveries[0]
+	if success.event != EventEnsureSucceeded || success.token != "Bearer secret-token" {
+		t.Errorf("Expected an %s event with the expanded token but got %s, %q", EventEnsureSucceeded, success.event, success.token)
+	}
+	mac := hmac.New(sha256.New, []byte("key"))
+	mac.Write(success.body)
+	if expected := "sha256=" + hex.EncodeToString(mac.Sum(nil)); success.signature != expected {
+		t.Errorf("Expected signature %s but got %s", expected, success.signature)
+	}
+	var payload struct {
+		Event        string `json:"event"`
+		Project      string `json:"project"`
+		Error        string `json:"error"`
+		Dependencies []struct {
+			Version string `json:"version"`
+			Error   string `json:"error"`
+		} `json:"dependencies"`
+	}
+	if err := json.Unmarshal(success.body, &payload); err != nil {
+		t.Fatalf("Failed to decode payload: %v", err)
+	}
+	if payload.Project != "app" || len(payload.Dependencies) != 2 || payload.Dependencies[1].Version != "4.44.2" {
+		t.Errorf("Unexpected payload: %s", success.body)
+	}
+
+	// A failed dependency fails the run and reaches both webhooks
+	deliveries = nil
+	statuses["yq"].Error = errors.New("download failed")
+	manager.notifyWebhooks(context.Background(), statuses, errors.New("installation of yq failed"))
+	if len(deliveries) != 2 {
+		t.Fatalf("Expected deliveries to both webhooks but got %d", len(deliveries))
+	}
+	if err := json.Unmarshal(deliveries[1].body, &payload); err != nil {
+		t.Fatalf("Failed to decode payload: %v", err)
+	}
+	if payload.Event != EventEnsureFailed || payload.Error != "installation of yq failed" || payload.Dependencies[1].Error != "download failed" {
+		t.Errorf("Unexpected failure payload: %s", deliveries[1].body)
+	}
+}
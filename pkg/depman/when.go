@@ -0,0 +1,103 @@
+package depman
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/devnadeemashraf/depman/internal/expr"
+)
+
+// conditionVars are the variables a when expression can compare, besides env.NAME
+var conditionVars = []string{"platform", "arch", "distro", "profile"}
+
+// conditionEnv returns what when expressions are evaluated against on this machine
+func (m *Manager) conditionEnv() expr.Env {
+	vars := map[string]string{
+		"platform": m.Platform,
+		"arch":     m.targetArch(),
+		"distro":   "",
+		"profile":  m.profile,
+	}
+	if m.distro != nil {
+		vars["distro"] = m.distro.ID
+	}
+	return expr.Env{
+		Vars:   vars,
+		Lookup: os.Getenv,
+		Funcs: map[string]func(string) bool{
+			"exists": func(path string) bool {
+				if strings.HasPrefix(path, "~/") {
+					if home, err := os.UserHomeDir(); err == nil {
+						path = filepath.Join(home, path[2:])
+					}
+				}
+				_, err := os.Stat(path)
+				return err == nil
+			},
+		},
+	}
+}
+
+// checkCondition parses a when expression and checks it only uses known
+// variables and functions, without evaluating it
+func checkCondition(source string) error {
+	e, err := expr.Parse(source)
+	if err != nil {
+		return err
+	}
+	env := expr.Env{Vars: map[string]string{}, Funcs: map[string]func(string) bool{"exists": nil}}
+	for _, name := range conditionVars {
+		env.Vars[name] = ""
+	}
+	return e.Check(env)
+}
+
+// applyConditions returns the configuration without the dependencies whose
+// when expression is false on this machine. Dependencies that depended on a
+// dropped dependency no longer do.
+func (m *Manager) applyConditions(config *DependencyConfig) (*DependencyConfig, error) {
+	env := m.conditionEnv()
+	dropped := make(map[string]bool)
+	kept := make([]Dependency, 0, len(config.Dependencies))
+	for _, dep := range config.Dependencies {
+		if dep.When == "" {
+			kept = append(kept, dep)
+			continue
+		}
+		e, err := expr.Parse(dep.When)
+		if err != nil {
+			return nil, fmt.Errorf("dependency '%s': invalid when: %w", dep.Name, err)
+		}
+		ok, err := e.Eval(env)
+		if err != nil {
+			return nil, fmt.Errorf("dependency '%s': invalid when: %w", dep.Name, err)
+		}
+		if !ok {
+			m.logger.Debugf("Skipping %s: when %s is false", dep.Name, dep.When)
+			dropped[dep.Name] = true
+			continue
+		}
+		kept = append(kept, dep)
+	}
+	if len(dropped) == 0 {
+		return config, nil
+	}
+
+	for i, dep := range kept {
+		var deps []string
+		for _, name := range dep.Dependencies {
+			if !dropped[name] {
+				deps = append(deps, name)
+			}
+		}
+		if len(deps) != len(dep.Dependencies) {
+			kept[i].Dependencies = deps
+		}
+	}
+
+	applied := *config
+	applied.Dependencies = kept
+	return &applied, nil
+}
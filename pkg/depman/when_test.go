@@ -0,0 +1,66 @@
+package depman
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestApplyConditions(t *testing.T) {
+	t.Setenv("DEPMAN_TEST_CI", "true")
+	cuda := t.TempDir()
+
+	linux := PlatformConfig{Commands: Commands{
+		Install: []string{"true"},
+		Verify:  []string{"true"},
+	}}
+	config := NewConfig("Conditional App",
+		NewDependency("jq", "^1.6").OnPlatform("linux", linux),
+		NewDependency("rosetta", "*").OnPlatform("linux", linux).OnlyWhen(`platform == "darwin" && arch == "arm64"`),
+		NewDependency("nvcc", "*").OnPlatform("linux", linux).OnlyWhen(`exists("`+filepath.ToSlash(cuda)+`")`),
+		NewDependency("linters", "*").OnPlatform("linux", linux).OnlyWhen(`env.DEPMAN_TEST_CI != "true"`),
+		NewDependency("app", "*").OnPlatform("linux", linux).DependsOn("jq", "rosetta", "nvcc"),
+	)
+
+	manager, err := NewManager("", WithConfig(config), WithPlatform("linux"), WithArch("amd64"), WithHomeDir(t.TempDir()))
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+
+	var names []string
+	for _, dep := range manager.Config.Dependencies {
+		names = append(names, dep.Name)
+	}
+	if strings.Join(names, ",") != "jq,nvcc,app" {
+		t.Errorf("Expected jq, nvcc and app to apply but got %v", names)
+	}
+	if app := manager.FindDependency("app"); strings.Join(app.Dependencies, ",") != "jq,nvcc" {
+		t.Errorf("Expected app to no longer depend on rosetta but got %v", app.Dependencies)
+	}
+	if len(config.Dependencies) != 5 {
+		t.Errorf("Expected the given configuration to be left alone but it has %d dependencies", len(config.Dependencies))
+	}
+}
+
+func TestValidateConditions(t *testing.T) {
+	testCases := []struct {
+		when     string
+		expected string
+	}{
+		{when: `platform == "darwin" &&`, expected: "unexpected end of expression"},
+		{when: `os == "linux"`, expected: "unknown variable 'os'"},
+		{when: `which("go")`, expected: "unknown function 'which'"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.when, func(t *testing.T) {
+			config := NewConfig("Conditional App", NewDependency("jq", "^1.6").OnlyWhen(tc.when))
+			err := config.Validate()
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) || !strings.Contains(err.Error(), tc.expected) {
+				t.Errorf("Expected a validation error containing %q but got %v", tc.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package depman
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Provenance names what put an executable where it is
+type Provenance string
+
+const (
+	ProvenanceDepman   Provenance = "depman"   // A managed bin or tools directory
+	ProvenanceSystem   Provenance = "system"   // The operating system's directories, e.g. from apt or dnf
+	ProvenanceHomebrew Provenance = "homebrew" // A Homebrew prefix or cellar
+	ProvenanceUnknown  Provenance = "unknown"  // Anywhere else, e.g. /usr/local/bin or a manual install
+)
+
+// Resolution is the executable that currently satisfies a dependency, found
+// the way a check finds it
+type Resolution struct {
+	Dependency string        `json:"dependency"`            // Name of the dependency
+	Binary     string        `json:"binary,omitempty"`      // Executable its verify command runs, or "" if it has none
+	Path       string        `json:"path,omitempty"`        // Absolute path of the executable a check runs, or "" if none was found
+	RealPath   string        `json:"real_path,omitempty"`   // Path with symlinks resolved, if different
+	Version    string        `json:"version,omitempty"`     // Version the check found
+	Method     string        `json:"method"`                // Install method configured for the platform
+	Manager    Provenance    `json:"manager"`               // What put the executable there
+	Error      string        `json:"error,omitempty"`       // Why the check failed, if it did
+	ShadowedBy *Installation `json:"shadowed_by,omitempty"` // Copy earlier on PATH that shells run instead, if any
+}
+
+// Which checks a dependency and returns the executable satisfying it, with
+// its version, install method and what installed it. The executable is
+// resolved as checks resolve it: managed bin directories first, then PATH.
+func (m *Manager) Which(ctx context.Context, name string) (*Resolution, error) {
+	dep := m.FindDependency(name)
+	if dep == nil {
+		return nil, fmt.Errorf("dependency %s not found in configuration", name)
+	}
+
+	resolution := &Resolution{Dependency: dep.Name, Method: m.InstallMethod(dep), Manager: ProvenanceUnknown}
+	status, err := m.checkWithHooks(ctx, dep)
+	if status != nil {
+		resolution.Version = status.CurrentVersion
+		resolution.ShadowedBy = status.ShadowedBy
+	}
+	if err != nil {
+		resolution.Error = err.Error()
+	}
+
+	audit := m.auditDependencyPath(dep)
+	if audit == nil {
+		return resolution, nil
+	}
+	resolution.Binary = audit.Binary
+	if audit.Checked == nil {
+		return resolution, nil
+	}
+	resolution.Path, _ = filepath.Abs(audit.Checked.Path)
+	if real, err := filepath.EvalSymlinks(resolution.Path); err == nil && real != resolution.Path {
+		resolution.RealPath = real
+	}
+	resolution.Manager = m.provenance(audit.Checked, resolution.RealPath)
+	return resolution, nil
+}
+
+// homebrewPrefixes are where Homebrew installs on macOS and Linux
+var homebrewPrefixes = []string{"/opt/homebrew", "/home/linuxbrew/.linuxbrew", "/usr/local/Cellar", "/usr/local/Caskroom"}
+
+// systemDirs hold executables installed by the operating system and its
+// package manager
+var systemDirs = []string{"/bin", "/sbin", "/usr/bin", "/usr/sbin", "/usr/libexec", "/usr/lib"}
+
+// provenance tells what put an executable where it is from its path and, if
+// it is a symlink, where the symlink points
+func (m *Manager) provenance(installation *Installation, realPath string) Provenance {
+	if installation.Managed {
+		return ProvenanceDepman
+	}
+	paths := []string{installation.Path}
+	if realPath != "" {
+		paths = append(paths, realPath)
+	}
+
+	managedRoots := []string{m.HomeDir(), m.globalHomeDir(), m.ProjectDir()}
+	for _, path := range paths {
+		for _, root := range managedRoots {
+			if isWithin(path, root) {
+				return ProvenanceDepman
+			}
+		}
+	}
+	for _, path := range paths {
+		if strings.Contains(filepath.ToSlash(path), "/Cellar/") {
+			return ProvenanceHomebrew
+		}
+		for _, prefix := range homebrewPrefixes {
+			if isWithin(path, prefix) {
+				return ProvenanceHomebrew
+			}
+		}
+	}
+
+	// Where symlinks point decides, e.g. /usr/bin/java into /usr/lib/jvm
+	path := paths[len(paths)-1]
+	if m.Platform == "windows" {
+		if root := os.Getenv("SystemRoot"); root != "" && isWithin(path, root) {
+			return ProvenanceSystem
+		}
+		return ProvenanceUnknown
+	}
+	for _, dir := range systemDirs {
+		if isWithin(path, dir) {
+			return ProvenanceSystem
+		}
+	}
+	return ProvenanceUnknown
+}
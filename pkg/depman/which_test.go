@@ -0,0 +1,77 @@
+package depman
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestProvenance(t *testing.T) {
+	manager := &Manager{Platform: "linux", homeDir: "/home/user/.depman", ConfigPath: "/work/project/app-dependencies.yml"}
+
+	tests := []struct {
+		path     string
+		realPath string
+		managed  bool
+		expected Provenance
+	}{
+		{"/home/user/.depman/bin/go", "", true, ProvenanceDepman},
+		{"/work/project/.depman/tools/go/1.22.0/bin/go", "", false, ProvenanceDepman},
+		{"/opt/homebrew/bin/jq", "", false, ProvenanceHomebrew},
+		{"/usr/local/bin/jq", "/usr/local/Cellar/jq/1.7/bin/jq", false, ProvenanceHomebrew},
+		{"/usr/bin/java", "/usr/lib/jvm/java-21/bin/java", false, ProvenanceSystem},
+		{"/usr/bin/git", "", false, ProvenanceSystem},
+		{"/usr/local/bin/terraform", "", false, ProvenanceUnknown},
+		{"/usr/bin/node", "/home/user/.nvm/versions/node/v20/bin/node", false, ProvenanceUnknown},
+	}
+	for _, tt := range tests {
+		if got := manager.provenance(&Installation{Path: tt.path, Managed: tt.managed}, tt.realPath); got != tt.expected {
+			t.Errorf("Expected %s (-> %q) to be from %s but got %s", tt.path, tt.realPath, tt.expected, got)
+		}
+	}
+}
+
+func TestWhich(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses shell scripts")
+	}
+	dir := t.TempDir()
+	homeDir := filepath.Join(dir, "home")
+	if err := installBinaryContent(filepath.Join(homeDir, "bin", "tool"), "#!/bin/sh\necho tool 1.4.2\n"); err != nil {
+		t.Fatalf("Failed to write binary: %v", err)
+	}
+	configPath := filepath.Join(dir, "deps.yml")
+	os.WriteFile(configPath, []byte(`version: "1.0"
+dependencies:
+  - name: tool
+    version:
+      required: "1.4.2"
+    platforms:
+      `+runtime.GOOS+`:
+        installer:
+          method: archive
+        commands:
+          verify: ["tool", "--version"]
+`), 0644)
+	t.Setenv("PATH", strings.Join([]string{filepath.Join(dir, "empty"), "/usr/bin", "/bin"}, string(os.PathListSeparator)))
+
+	manager, err := NewManager(configPath, WithPlatform(runtime.GOOS+"/"+runtime.GOARCH), WithHomeDir(homeDir), WithSettings(Settings{}), WithLogOutput(io.Discard))
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	r, err := manager.Which(context.Background(), "tool")
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if r.Path != filepath.Join(homeDir, "bin", "tool") || r.Version != "1.4.2" || r.Method != "archive" || r.Manager != ProvenanceDepman || r.Error != "" {
+		t.Errorf("Unexpected resolution: %+v", r)
+	}
+
+	if _, err := manager.Which(context.Background(), "missing"); err == nil {
+		t.Errorf("Expected an error for an unknown dependency")
+	}
+}
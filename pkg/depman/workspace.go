@@ -0,0 +1,265 @@
+package depman
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// rootProject names the project of the workspace file itself in attributions
+const rootProject = "."
+
+// Workspace lists the projects of a monorepo whose configurations are
+// checked and ensured together with the file declaring it
+type Workspace struct {
+	Projects []string `yaml:"projects"` // Glob patterns of project directories relative to the file, e.g. "services/*" or "tools/**"
+	Exclude  []string `yaml:"exclude"`  // Glob patterns of directories to leave out, e.g. "**/testdata"
+}
+
+// WorkspaceConflictError reports projects of a workspace whose requirements
+// on a dependency cannot be met together
+type WorkspaceConflictError struct {
+	Dependency string   // Name of the dependency
+	Projects   []string // Projects whose requirements conflict
+	Reason     string   // How they conflict
+}
+
+func (e *WorkspaceConflictError) Error() string {
+	return fmt.Sprintf("projects %s disagree on %s: %s", strings.Join(e.Projects, " and "), e.Dependency, e.Reason)
+}
+
+// discoverProjects returns the project directories under root matching the
+// workspace patterns, relative to root with forward slashes and sorted.
+// Hidden directories are not searched.
+func discoverProjects(root string, workspace Workspace) ([]string, error) {
+	var projects []string
+	err := filepath.WalkDir(root, func(dir string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, dir)
+		if err != nil || rel == "." {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if strings.HasPrefix(entry.Name(), ".") || matchesAny(workspace.Exclude, rel) {
+			return filepath.SkipDir
+		}
+		if _, ok := findConfigIn(dir); ok && matchesAny(workspace.Projects, rel) {
+			projects = append(projects, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search workspace %s: %w", root, err)
+	}
+	sort.Strings(projects)
+	return projects, nil
+}
+
+// matchesAny reports whether the slash-separated path matches one of the patterns
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matchGlob(strings.Split(strings.Trim(pattern, "/"), "/"), strings.Split(name, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob matches path segments against pattern segments, where "**"
+// matches any number of segments and other segments use path.Match
+func matchGlob(pattern, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(name); i++ {
+			if matchGlob(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(name) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], name[0]); !ok {
+		return false
+	}
+	return matchGlob(pattern[1:], name[1:])
+}
+
+// loadWorkspace merges the dependencies of every project of the workspace
+// declared by the configuration at configPath into it. Each dependency records
+// the projects declaring it; dependencies declared by several projects must
+// all be satisfied by one version. Projects contribute dependencies and
+// credentials; their hooks, webhooks, policies and profiles are not used.
+func loadWorkspace(config *DependencyConfig, configPath string) (*DependencyConfig, error) {
+	root := filepath.Dir(configPath)
+	projects, err := discoverProjects(root, config.Workspace)
+	if err != nil {
+		return nil, err
+	}
+	if len(projects) == 0 {
+		return nil, fmt.Errorf("workspace projects %s match no directory with a dependency configuration under %s",
+			strings.Join(config.Workspace.Projects, ", "), root)
+	}
+
+	merged := *config
+	merged.Dependencies = make([]Dependency, len(config.Dependencies))
+	for i, dep := range config.Dependencies {
+		dep.Projects = []string{rootProject}
+		merged.Dependencies[i] = dep
+	}
+
+	for _, project := range projects {
+		path, _ := findConfigIn(filepath.Join(root, filepath.FromSlash(project)))
+		member, err := loadConfigFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("workspace project %s: %w", project, err)
+		}
+		if len(member.Workspace.Projects) > 0 {
+			return nil, fmt.Errorf("workspace project %s declares a workspace itself; nested workspaces are not supported", project)
+		}
+
+		// Credentials of the workspace file win over those of its projects
+		merged.Credentials = mergeCredentials(member.Credentials, merged.Credentials)
+		merged.Mirrors = append(merged.Mirrors, member.Mirrors...)
+
+		for _, dep := range member.Dependencies {
+			dep.Projects = []string{project}
+			i := slices.IndexFunc(merged.Dependencies, func(d Dependency) bool { return d.Name == dep.Name })
+			if i < 0 {
+				merged.Dependencies = append(merged.Dependencies, dep)
+				continue
+			}
+			if merged.Dependencies[i], err = combineDependency(merged.Dependencies[i], dep); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &merged, nil
+}
+
+// combineDependency merges two projects' definitions of a dependency into one
+// satisfying both: constraints are combined, conditions and lists are joined
+// and platform entries missing from the first definition are taken from the
+// second. Everything else comes from the first definition.
+func combineDependency(first, second Dependency) (Dependency, error) {
+	combined := first
+	combined.Projects = append(append([]string(nil), first.Projects...), second.Projects...)
+	conflict := func(format string, args ...interface{}) error {
+		return &WorkspaceConflictError{Dependency: first.Name, Projects: combined.Projects, Reason: fmt.Sprintf(format, args...)}
+	}
+
+	switch {
+	case first.Version.Required == "":
+		combined.Version.Required = second.Version.Required
+	case second.Version.Required != "" && second.Version.Required != first.Version.Required:
+		return combined, conflict("pinned to %s and %s", first.Version.Required, second.Version.Required)
+	}
+
+	combined.Version.Constraint = intersectConstraints(first.Version.Constraint, second.Version.Constraint)
+	if required := combined.Version.Required; required != "" && combined.Version.Constraint != "" {
+		if ok, err := IsVersionCompatible(required, combined.Version.Constraint); err == nil && !ok {
+			return combined, conflict("%s is pinned but must satisfy %s", required, combined.Version.Constraint)
+		}
+	}
+
+	switch {
+	case first.When == "" || second.When == "":
+		combined.When = ""
+	case first.When != second.When:
+		combined.When = fmt.Sprintf("(%s) || (%s)", first.When, second.When)
+	}
+
+	combined.Dependencies = union(first.Dependencies, second.Dependencies)
+	combined.Tags = union(first.Tags, second.Tags)
+	combined.Provides = union(first.Provides, second.Provides)
+	combined.ConflictsWith = union(first.ConflictsWith, second.ConflictsWith)
+
+	if len(second.Platforms) > 0 {
+		combined.Platforms = make(map[string]PlatformConfig, len(first.Platforms)+len(second.Platforms))
+		for platform, config := range second.Platforms {
+			combined.Platforms[platform] = config
+		}
+		for platform, config := range first.Platforms {
+			combined.Platforms[platform] = config
+		}
+	}
+
+	return combined, nil
+}
+
+// intersectConstraints returns a constraint satisfied by the versions that
+// satisfy both. Comma-separated constraints must all hold, and a comma binds
+// tighter than ||, so alternatives are distributed: (a || b), c becomes
+// a, c || b, c.
+func intersectConstraints(a, b string) string {
+	if a == "" || a == b {
+		return b
+	}
+	if b == "" {
+		return a
+	}
+	var alternatives []string
+	for _, left := range strings.Split(a, "||") {
+		for _, right := range strings.Split(b, "||") {
+			alternatives = append(alternatives, strings.TrimSpace(left)+", "+strings.TrimSpace(right))
+		}
+	}
+	return strings.Join(alternatives, " || ")
+}
+
+// union returns the items of a followed by those of b not in a
+func union(a, b []string) []string {
+	if len(b) == 0 {
+		return a
+	}
+	merged := append([]string(nil), a...)
+	for _, item := range b {
+		if !slices.Contains(merged, item) {
+			merged = append(merged, item)
+		}
+	}
+	return merged
+}
+
+// workspaceFiles returns the configuration files of the projects of the
+// workspace declared in the file at path, if it declares one
+func workspaceFiles(path string, data []byte) []string {
+	converted, err := toYAML(data, DetectConfigFormat(path, data))
+	if err != nil {
+		return nil
+	}
+	var header struct {
+		Workspace Workspace `yaml:"workspace"`
+	}
+	if err := yaml.Unmarshal(converted, &header); err != nil || len(header.Workspace.Projects) == 0 {
+		return nil
+	}
+
+	root := filepath.Dir(path)
+	projects, err := discoverProjects(root, header.Workspace)
+	if err != nil {
+		return nil
+	}
+	var files []string
+	for _, project := range projects {
+		if file, ok := findConfigIn(filepath.Join(root, filepath.FromSlash(project))); ok {
+			files = append(files, file)
+		}
+	}
+	return files
+}
@@ -0,0 +1,184 @@
+package depman
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeWorkspace writes files relative to a temporary workspace root
+func writeWorkspace(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	return root
+}
+
+// projectConfig returns a configuration declaring jq with a constraint
+func projectConfig(name, constraint, extra string) string {
+	return `
+version: "1.0"
+name: "` + name + `"
+dependencies:
+  - name: "jq"
+    version:
+      constraint: "` + constraint + `"
+    platforms:
+      linux:
+        installer:
+          method: "system"
+` + extra
+}
+
+func TestLoadWorkspace(t *testing.T) {
+	root := writeWorkspace(t, map[string]string{
+		"app-dependencies.yml": `
+version: "1.0"
+name: "Monorepo"
+workspace:
+  projects: ["services/*", "tools/**"]
+  exclude: ["**/testdata"]
+dependencies:
+  - name: "make"
+    version:
+      constraint: ">=4"
+    platforms:
+      linux:
+        installer:
+          method: "system"
+`,
+		"services/api/app-dependencies.yml": projectConfig("API", "^1.6", `
+  - name: "go"
+    version:
+      constraint: "^1.22"
+    platforms:
+      linux:
+        installer:
+          method: "system"
+`),
+		"services/web/app-dependencies.yml":              projectConfig("Web", ">=1.7", ""),
+		"tools/lint/app-dependencies.yml":                projectConfig("Lint", "^1.6", ""),
+		"tools/lint/testdata/app-dependencies.yml":       projectConfig("Fixture", "^9", ""),
+		"services/.hidden/app-dependencies.yml":          projectConfig("Hidden", "^9", ""),
+		"docs/app-dependencies.yml":                      projectConfig("Docs", "^9", ""),
+		"services/api/node_modules/x/app-dependencies.x": "not a configuration",
+	})
+
+	config, err := LoadDependencyConfig(filepath.Join(root, "app-dependencies.yml"))
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+
+	var names []string
+	for _, dep := range config.Dependencies {
+		names = append(names, dep.Name)
+	}
+	if strings.Join(names, ",") != "make,jq,go" {
+		t.Fatalf("Expected make, jq and go but got %v", names)
+	}
+
+	jq := config.Dependencies[1]
+	if strings.Join(jq.Projects, ",") != "services/api,services/web,tools/lint" {
+		t.Errorf("Expected jq to be attributed to three projects but got %v", jq.Projects)
+	}
+	for _, version := range []string{"1.7.1", "1.8.0"} {
+		if ok, err := IsVersionCompatible(version, jq.Version.Constraint); err != nil || !ok {
+			t.Errorf("Expected %s to satisfy %q but got %v, %v", version, jq.Version.Constraint, ok, err)
+		}
+	}
+	if ok, _ := IsVersionCompatible("1.6.0", jq.Version.Constraint); ok {
+		t.Errorf("Expected 1.6.0 not to satisfy %q", jq.Version.Constraint)
+	}
+	if projects := config.Dependencies[0].Projects; len(projects) != 1 || projects[0] != rootProject {
+		t.Errorf("Expected make to be attributed to the workspace file but got %v", projects)
+	}
+
+	files := ConfigFiles(filepath.Join(root, "app-dependencies.yml"))
+	var found bool
+	for _, file := range files {
+		found = found || strings.HasSuffix(filepath.ToSlash(file), "tools/lint/app-dependencies.yml")
+	}
+	if !found {
+		t.Errorf("Expected project files to be watched but got %v", files)
+	}
+}
+
+func TestLoadWorkspaceConflicts(t *testing.T) {
+	testCases := []struct {
+		name     string
+		files    map[string]string
+		expected string
+	}{
+		{
+			name: "Different pins",
+			files: map[string]string{
+				"a/app-dependencies.yml": strings.Replace(projectConfig("A", "", ""), `constraint: ""`, `required: "1.6.0"`, 1),
+				"b/app-dependencies.yml": strings.Replace(projectConfig("B", "", ""), `constraint: ""`, `required: "1.7.1"`, 1),
+			},
+			expected: "projects a and b disagree on jq: pinned to 1.6.0 and 1.7.1",
+		},
+		{
+			name: "Pin outside constraint",
+			files: map[string]string{
+				"a/app-dependencies.yml": strings.Replace(projectConfig("A", "", ""), `constraint: ""`, `required: "1.6.0"`, 1),
+				"b/app-dependencies.yml": projectConfig("B", ">=1.7", ""),
+			},
+			expected: "1.6.0 is pinned but must satisfy >=1.7",
+		},
+		{
+			name:     "No projects",
+			files:    map[string]string{},
+			expected: "match no directory",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.files["app-dependencies.yml"] = `
+version: "1.0"
+name: "Monorepo"
+workspace:
+  projects: ["*"]
+dependencies: []
+`
+			root := writeWorkspace(t, tc.files)
+
+			_, err := LoadDependencyConfig(filepath.Join(root, "app-dependencies.yml"))
+			if err == nil || !strings.Contains(err.Error(), tc.expected) {
+				t.Errorf("Expected an error containing %q but got %v", tc.expected, err)
+			}
+			var conflict *WorkspaceConflictError
+			if errors.As(err, &conflict) != strings.Contains(tc.expected, "pinned") {
+				t.Errorf("Expected a *WorkspaceConflictError only for version conflicts but got %T", err)
+			}
+		})
+	}
+}
+
+func TestIntersectConstraints(t *testing.T) {
+	testCases := []struct {
+		a, b     string
+		expected string
+	}{
+		{a: "^1.6", b: "", expected: "^1.6"},
+		{a: "^1.6", b: "^1.6", expected: "^1.6"},
+		{a: "^1.6", b: ">=1.7", expected: "^1.6, >=1.7"},
+		{a: "^1 || ^2", b: ">=1.5", expected: "^1, >=1.5 || ^2, >=1.5"},
+	}
+
+	for _, tc := range testCases {
+		if got := intersectConstraints(tc.a, tc.b); got != tc.expected {
+			t.Errorf("intersectConstraints(%q, %q) = %q, expected %q", tc.a, tc.b, got, tc.expected)
+		}
+	}
+}
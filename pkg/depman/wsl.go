@@ -0,0 +1,166 @@
+package depman
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// WSLTarget selects where a dependency is installed when depman runs inside
+// the Windows Subsystem for Linux
+type WSLTarget string
+
+const (
+	WSLLinux   WSLTarget = "linux"   // Install in the WSL distribution (default)
+	WSLWindows WSLTarget = "windows" // Install on the Windows host through interop
+	WSLBoth    WSLTarget = "both"    // Install in the distribution and on the Windows host
+)
+
+// ParseWSLTarget parses a dependency's wsl setting; an empty setting means linux
+func ParseWSLTarget(name string) (WSLTarget, error) {
+	switch target := WSLTarget(strings.ToLower(name)); target {
+	case "":
+		return WSLLinux, nil
+	case WSLLinux, WSLWindows, WSLBoth:
+		return target, nil
+	default:
+		return "", fmt.Errorf("invalid wsl target '%s' (expected linux, windows or both)", name)
+	}
+}
+
+// wslReleasePath holds the kernel release, which names Microsoft under WSL; replaced in tests
+var wslReleasePath = "/proc/sys/kernel/osrelease"
+
+// windowsShellPath is where cmd.exe is mounted when the Windows PATH is not
+// appended to PATH inside WSL
+var windowsShellPath = "/mnt/c/Windows/System32/cmd.exe"
+
+// windowsHostDir is a working directory cmd.exe accepts; it refuses to run in
+// Linux directories, which it sees as UNC paths
+var windowsHostDir = "/mnt/c"
+
+// DetectWSL reports whether depman runs inside WSL
+func DetectWSL() bool {
+	if runtime.GOOS != "linux" {
+		return false
+	}
+	if os.Getenv("WSL_DISTRO_NAME") != "" {
+		return true
+	}
+	release, err := os.ReadFile(wslReleasePath)
+	return err == nil && strings.Contains(strings.ToLower(string(release)), "microsoft")
+}
+
+// WithWSL sets whether depman runs inside WSL instead of detecting it
+func WithWSL(wsl bool) Option {
+	return func(m *Manager) {
+		m.wsl = wsl
+	}
+}
+
+// InWSL reports whether tools are installed from inside WSL, where
+// dependencies can also target the Windows host
+func (m *Manager) InWSL() bool {
+	return m.wsl && m.Platform == "linux"
+}
+
+// environments returns the environments a dependency is installed in, the
+// primary one first: the Linux distribution, the Windows host, or both.
+// Outside WSL this is always the current platform.
+func (m *Manager) environments(dep *Dependency) []WSLTarget {
+	target, err := ParseWSLTarget(dep.WSL)
+	if err != nil || !m.InWSL() {
+		return []WSLTarget{WSLLinux}
+	}
+	if target == WSLBoth {
+		return []WSLTarget{WSLLinux, WSLWindows}
+	}
+	return []WSLTarget{target}
+}
+
+// environmentTarget describes the platform an environment installs for
+func (m *Manager) environmentTarget(env WSLTarget) string {
+	if env == WSLWindows {
+		return "windows/" + m.targetArch() + " (Windows host)"
+	}
+	return m.PlatformTarget()
+}
+
+// missingPlatform returns the target of the first environment the dependency
+// has no platform entry for, if any
+func (m *Manager) missingPlatform(dep *Dependency) (string, bool) {
+	if _, ok := kindPlatformConfig(dep); ok {
+		return "", false
+	}
+	for _, env := range m.environments(dep) {
+		if _, ok := m.platformKeyIn(dep, env); !ok {
+			return m.environmentTarget(env), true
+		}
+	}
+	return "", false
+}
+
+// interopMethods are the install methods that can run on the Windows host
+// from WSL; the others place files inside the distribution
+var interopMethods = map[string]bool{
+	"command": true,
+	"winget":  true,
+	"choco":   true,
+	"scoop":   true,
+}
+
+// windowsHostKey marks contexts whose commands run on the Windows host
+type windowsHostKey struct{}
+
+// environmentContext returns a context whose commands run in the environment
+func environmentContext(ctx context.Context, env WSLTarget) context.Context {
+	if env != WSLWindows {
+		return ctx
+	}
+	return context.WithValue(ctx, windowsHostKey{}, true)
+}
+
+// onWindowsHost reports whether commands run with ctx target the Windows host
+func onWindowsHost(ctx context.Context) bool {
+	host, _ := ctx.Value(windowsHostKey{}).(bool)
+	return host
+}
+
+// hostCommand prepares a command for the environment ctx targets. Windows
+// host commands run through cmd.exe, which finds them on the Windows PATH
+// whether or not WSL appends it to PATH.
+func hostCommand(ctx context.Context, name string, args ...string) *exec.Cmd {
+	if !onWindowsHost(ctx) {
+		cmd := exec.CommandContext(ctx, name, args...)
+		if env := commandEnv(ctx); len(env) > 0 {
+			cmd.Env = append(os.Environ(), env...)
+		}
+		return cmd
+	}
+
+	shell, err := lookPath("cmd.exe")
+	if err != nil {
+		shell = windowsShellPath
+	}
+	cmd := exec.CommandContext(ctx, shell, append([]string{"/d", "/c", name}, args...)...)
+	if info, err := os.Stat(windowsHostDir); err == nil && info.IsDir() {
+		cmd.Dir = windowsHostDir
+	}
+	return cmd
+}
+
+// hostLookPath reports the location of an executable in the environment ctx
+// targets. Windows host executables are looked up with `where` and returned
+// by name for cmd.exe to resolve.
+func hostLookPath(ctx context.Context, name string) (string, error) {
+	if !onWindowsHost(ctx) {
+		return lookPath(name)
+	}
+	if _, err := runCommand(ctx, "where", name); err != nil {
+		return "", fmt.Errorf("%s not found on the Windows host: %w", name, err)
+	}
+	return name, nil
+}
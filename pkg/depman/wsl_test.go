@@ -0,0 +1,128 @@
+package depman
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDetectWSL(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "depman-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	originalPath := wslReleasePath
+	defer func() { wslReleasePath = originalPath }()
+	wslReleasePath = filepath.Join(tempDir, "osrelease")
+	t.Setenv("WSL_DISTRO_NAME", "")
+
+	testCases := []struct {
+		name     string
+		release  string
+		distro   string
+		expected bool
+	}{
+		{"WSL 2 kernel", "5.15.153.1-microsoft-standard-WSL2", "", true},
+		{"WSL 1 kernel", "4.4.0-19041-Microsoft", "", true},
+		{"Distribution variable", "6.8.0-45-generic", "Ubuntu", true},
+		{"Native Linux", "6.8.0-45-generic", "", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := os.WriteFile(wslReleasePath, []byte(tc.release+"\n"), 0644); err != nil {
+				t.Fatalf("Failed to write kernel release: %v", err)
+			}
+			t.Setenv("WSL_DISTRO_NAME", tc.distro)
+
+			if got := DetectWSL(); got != tc.expected {
+				t.Errorf("Expected %v but got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestPlatformKeyWSL(t *testing.T) {
+	testCases := []struct {
+		name         string
+		wsl          bool
+		target       string
+		expected     string
+		environments []WSLTarget
+	}{
+		{"Outside WSL", false, "windows", "linux", []WSLTarget{WSLLinux}},
+		{"Default", true, "", "linux", []WSLTarget{WSLLinux}},
+		{"Windows host", true, "windows", "windows", []WSLTarget{WSLWindows}},
+		{"Both", true, "both", "linux", []WSLTarget{WSLLinux, WSLWindows}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dep := &Dependency{Name: "docker", WSL: tc.target, Platforms: map[string]PlatformConfig{
+				"linux":   {Installer: Installer{Method: "apt"}},
+				"windows": {Installer: Installer{Method: "winget"}},
+			}}
+			manager := &Manager{Platform: "linux", Arch: "amd64", wsl: tc.wsl, logger: &mockLogger{}}
+
+			if key, _ := manager.platformKey(dep); key != tc.expected {
+				t.Errorf("Expected the %s entry but got %q", tc.expected, key)
+			}
+			if got := manager.environments(dep); len(got) != len(tc.environments) || got[len(got)-1] != tc.environments[len(tc.environments)-1] {
+				t.Errorf("Expected environments %v but got %v", tc.environments, got)
+			}
+		})
+	}
+
+	// Both environments need a platform entry
+	dep := &Dependency{Name: "docker", WSL: "both", Platforms: map[string]PlatformConfig{"linux": {}}}
+	manager := &Manager{Platform: "linux", Arch: "amd64", wsl: true, logger: &mockLogger{}}
+	if target, missing := manager.missingPlatform(dep); !missing || !strings.HasPrefix(target, "windows/amd64") {
+		t.Errorf("Expected the Windows host to be missing but got %q", target)
+	}
+}
+
+func TestVerifyDependencyWSLBoth(t *testing.T) {
+	dep := &Dependency{Name: "jq", Version: Version{Required: "1.7.1"}, WSL: "both", Platforms: map[string]PlatformConfig{
+		"linux":   {Installer: Installer{Method: "brew"}},
+		"windows": {Installer: Installer{Method: "winget", Package: "jqlang.jq"}},
+	}}
+	manager := &Manager{Platform: "linux", Arch: "amd64", wsl: true, logger: &mockLogger{}}
+	wingetList := "winget list --id jqlang.jq --exact --accept-source-agreements --disable-interactivity"
+
+	t.Run("Outdated on the Windows host", func(t *testing.T) {
+		calls := fakeCommands(t, map[string]string{
+			"brew list --versions jq": "jq 1.7.1",
+			"where winget":            `C:\Users\dev\AppData\Local\Microsoft\WindowsApps\winget.exe`,
+			wingetList:                "jq  jqlang.jq  1.6",
+		})
+
+		status, err := manager.VerifyDependency(context.Background(), dep)
+		if err != nil {
+			t.Fatalf("Did not expect an error but got: %v", err)
+		}
+		if status.CurrentVersion != "1.6" || status.RequiredUpdate != MinorUpdate {
+			t.Errorf("Expected the Windows host's 1.6 to need a minor update but got %s (%s)", status.CurrentVersion, status.RequiredUpdate)
+		}
+		if !strings.Contains(strings.Join(*calls, "|"), "where winget") {
+			t.Errorf("Expected winget to be looked up on the Windows host but got calls %v", *calls)
+		}
+	})
+
+	t.Run("Missing on the Windows host", func(t *testing.T) {
+		fakeCommands(t, map[string]string{
+			"brew list --versions jq": "jq 1.7.1",
+		})
+
+		status, err := manager.VerifyDependency(context.Background(), dep)
+		if err == nil || status.Installed {
+			t.Fatalf("Expected the dependency to be missing but got %+v", status)
+		}
+		if !strings.Contains(err.Error(), "Windows host") {
+			t.Errorf("Expected the error to name the Windows host but got: %v", err)
+		}
+	})
+}
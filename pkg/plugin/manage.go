@@ -0,0 +1,138 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Info describes an installed plugin
+type Info struct {
+	Name string `json:"name"` // Plugin name, used as the install method
+	Path string `json:"path"` // Path of the plugin executable
+}
+
+// binaryName returns the executable name of a plugin
+func binaryName(name string) string {
+	if runtime.GOOS == "windows" {
+		return BinaryPrefix + name + ".exe"
+	}
+	return BinaryPrefix + name
+}
+
+// baseName returns the plugin executable name without directory or .exe suffix
+func baseName(path string) string {
+	return strings.TrimSuffix(filepath.Base(path), ".exe")
+}
+
+// NameFromPath derives a plugin name from its executable, e.g.
+// "./depman-plugin-corp" becomes "corp"
+func NameFromPath(path string) string {
+	return strings.TrimPrefix(baseName(path), BinaryPrefix)
+}
+
+// Find returns the executable of a named plugin from dir, falling back to PATH
+func Find(dir, name string) (string, bool) {
+	path := filepath.Join(dir, binaryName(name))
+	if info, err := os.Stat(path); err == nil && !info.IsDir() {
+		return path, true
+	}
+	if path, err := exec.LookPath(binaryName(name)); err == nil {
+		return path, true
+	}
+	return "", false
+}
+
+// Install copies a plugin executable into dir under the given name after
+// checking that it speaks the plugin protocol
+func Install(source, dir, name string) (string, error) {
+	if name == "" {
+		name = NameFromPath(source)
+	}
+	if name == "" || strings.ContainsAny(name, `/\ `) {
+		return "", fmt.Errorf("invalid plugin name '%s'", name)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := Call(ctx, source, &Request{Method: MethodHandshake, Name: name}, io.Discard); err != nil {
+		return "", fmt.Errorf("%s is not a depman plugin: %w", source, err)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create plugin directory: %w", err)
+	}
+
+	in, err := os.Open(source)
+	if err != nil {
+		return "", fmt.Errorf("failed to open plugin: %w", err)
+	}
+	defer in.Close()
+
+	target := filepath.Join(dir, binaryName(name))
+	tmp := target + ".tmp"
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0755)
+	if err != nil {
+		return "", fmt.Errorf("failed to install plugin: %w", err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return "", fmt.Errorf("failed to install plugin: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("failed to install plugin: %w", err)
+	}
+
+	if err := os.Rename(tmp, target); err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("failed to install plugin: %w", err)
+	}
+
+	return target, nil
+}
+
+// List returns the plugins installed in dir, sorted by name
+func List(dir string) ([]Info, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read plugin directory: %w", err)
+	}
+
+	var plugins []Info
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), BinaryPrefix) || strings.HasSuffix(entry.Name(), ".tmp") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		plugins = append(plugins, Info{Name: NameFromPath(path), Path: path})
+	}
+
+	sort.Slice(plugins, func(i, j int) bool {
+		return plugins[i].Name < plugins[j].Name
+	})
+	return plugins, nil
+}
+
+// Remove deletes a named plugin from dir
+func Remove(dir, name string) error {
+	path := filepath.Join(dir, binaryName(name))
+	if err := os.Remove(path); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("plugin '%s' is not installed", name)
+		}
+		return fmt.Errorf("failed to remove plugin: %w", err)
+	}
+	return nil
+}
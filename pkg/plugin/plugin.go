@@ -0,0 +1,152 @@
+// Package plugin implements depman's install strategy plugin protocol.
+//
+// A plugin is an executable named depman-plugin-<name>. For every operation
+// depman starts the plugin, writes a single JSON Request to its standard
+// input and reads a single JSON Response from its standard output. Anything
+// the plugin writes to standard error is shown to the user as log output.
+// Plugins written in Go implement Installer and call Serve from main.
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// ProtocolVersion is the version of the request/response protocol
+const ProtocolVersion = 1
+
+// BinaryPrefix is the file name prefix of plugin executables
+const BinaryPrefix = "depman-plugin-"
+
+// Methods understood by plugins
+const (
+	MethodHandshake = "handshake" // Confirms the plugin speaks the protocol
+	MethodCheck     = "check"     // Reports whether the dependency is installed
+	MethodInstall   = "install"   // Installs the dependency
+	MethodUninstall = "uninstall" // Removes the dependency
+	MethodVersion   = "version"   // Reports the installed version
+)
+
+// Request is sent to a plugin on standard input
+type Request struct {
+	ProtocolVersion int               `json:"protocol_version"`     // Protocol version spoken by depman
+	Method          string            `json:"method"`               // One of the Method constants
+	Name            string            `json:"name"`                 // Name of the dependency
+	Version         string            `json:"version,omitempty"`    // Exact version required
+	Constraint      string            `json:"constraint,omitempty"` // Semver constraint
+	Platform        string            `json:"platform"`             // Target platform (windows, linux, darwin)
+	Arch            string            `json:"arch"`                 // Target architecture (amd64, arm64, ...)
+	Package         string            `json:"package,omitempty"`    // Package identifier from the installer config
+	URL             string            `json:"url,omitempty"`        // Download URL from the installer config
+	Args            []string          `json:"args,omitempty"`       // Extra arguments from the installer config
+	Options         map[string]string `json:"options,omitempty"`    // Plugin-specific settings from the installer config
+	HomeDir         string            `json:"home_dir"`             // Root directory for files managed by depman
+	BinDir          string            `json:"bin_dir"`              // Managed directory for installed binaries
+}
+
+// Response is written by a plugin to standard output
+type Response struct {
+	ProtocolVersion int    `json:"protocol_version"`    // Protocol version spoken by the plugin
+	Installed       bool   `json:"installed,omitempty"` // Result of check
+	Version         string `json:"version,omitempty"`   // Result of check and version
+	URL             string `json:"url,omitempty"`       // Artifact installed by install, recorded in the lockfile
+	Checksum        string `json:"checksum,omitempty"`  // Checksum of that artifact ("algorithm:hash")
+	Error           string `json:"error,omitempty"`     // Set when the operation failed
+}
+
+// Installer is implemented by plugins to provide a custom install method
+type Installer interface {
+	// Check reports whether the dependency is installed
+	Check(req *Request) (bool, error)
+
+	// Install installs the dependency and returns the artifact it used, if any
+	Install(req *Request) (url, checksum string, err error)
+
+	// Uninstall removes the dependency
+	Uninstall(req *Request) error
+
+	// Version returns the installed version of the dependency
+	Version(req *Request) (string, error)
+}
+
+// Serve reads one request from standard input, dispatches it to the
+// installer and writes the response to standard output
+func Serve(installer Installer) {
+	if err := serve(installer, os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "plugin error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// serve handles a single request
+func serve(installer Installer, in io.Reader, out io.Writer) error {
+	var req Request
+	if err := json.NewDecoder(in).Decode(&req); err != nil {
+		return fmt.Errorf("failed to decode request: %w", err)
+	}
+
+	resp := Response{ProtocolVersion: ProtocolVersion}
+	var err error
+	switch req.Method {
+	case MethodHandshake:
+	case MethodCheck:
+		resp.Installed, err = installer.Check(&req)
+	case MethodInstall:
+		resp.URL, resp.Checksum, err = installer.Install(&req)
+	case MethodUninstall:
+		err = installer.Uninstall(&req)
+	case MethodVersion:
+		resp.Version, err = installer.Version(&req)
+	default:
+		err = fmt.Errorf("unsupported method: %s", req.Method)
+	}
+	if err != nil {
+		resp.Error = err.Error()
+	}
+
+	return json.NewEncoder(out).Encode(resp)
+}
+
+// Call runs a plugin executable with a request and returns its response.
+// A response carrying an error is returned as an error.
+func Call(ctx context.Context, path string, req *Request, stderr io.Writer) (*Response, error) {
+	req.ProtocolVersion = ProtocolVersion
+
+	input, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode plugin request: %w", err)
+	}
+
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(ctx, path)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = stderr
+	runErr := cmd.Run()
+
+	var resp Response
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		if runErr != nil {
+			return nil, fmt.Errorf("plugin %s failed: %w", path, runErr)
+		}
+		return nil, fmt.Errorf("plugin %s returned an invalid response: %w", path, err)
+	}
+
+	if resp.ProtocolVersion != ProtocolVersion {
+		return nil, fmt.Errorf("plugin %s speaks protocol version %d, expected %d", path, resp.ProtocolVersion, ProtocolVersion)
+	}
+	if resp.Error != "" {
+		return &resp, fmt.Errorf("plugin %s: %s: %s", strings.TrimPrefix(baseName(path), BinaryPrefix), req.Method, resp.Error)
+	}
+	if runErr != nil {
+		return &resp, fmt.Errorf("plugin %s failed: %w", path, runErr)
+	}
+
+	return &resp, nil
+}
@@ -0,0 +1,168 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// fakeInstaller is served by the test binary when it is run as a plugin
+type fakeInstaller struct{}
+
+func (fakeInstaller) Check(req *Request) (bool, error) {
+	return req.Name == "installed-tool", nil
+}
+
+func (fakeInstaller) Install(req *Request) (string, string, error) {
+	if req.Options["fail"] == "true" {
+		return "", "", errors.New("install refused")
+	}
+	return "corp://" + req.Name + "@" + req.Version, "", nil
+}
+
+func (fakeInstaller) Uninstall(req *Request) error {
+	return nil
+}
+
+func (fakeInstaller) Version(req *Request) (string, error) {
+	return "1.2.3", nil
+}
+
+// TestMain lets the test binary act as a plugin when DEPMAN_TEST_PLUGIN is set
+func TestMain(m *testing.M) {
+	if os.Getenv("DEPMAN_TEST_PLUGIN") == "1" {
+		Serve(fakeInstaller{})
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// testPlugin returns a plugin executable backed by the test binary
+func testPlugin(t *testing.T) string {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin wrapper script requires a POSIX shell")
+	}
+
+	dir, err := os.MkdirTemp("", "depman-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, BinaryPrefix+"corp")
+	script := "#!/bin/sh\nDEPMAN_TEST_PLUGIN=1 exec '" + os.Args[0] + "'\n"
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatalf("Failed to write plugin: %v", err)
+	}
+	return path
+}
+
+func TestCall(t *testing.T) {
+	path := testPlugin(t)
+
+	testCases := []struct {
+		name        string
+		req         Request
+		expected    Response
+		expectError bool
+	}{
+		{
+			name:     "Handshake",
+			req:      Request{Method: MethodHandshake},
+			expected: Response{ProtocolVersion: ProtocolVersion},
+		},
+		{
+			name:     "Check installed",
+			req:      Request{Method: MethodCheck, Name: "installed-tool"},
+			expected: Response{ProtocolVersion: ProtocolVersion, Installed: true},
+		},
+		{
+			name:     "Install",
+			req:      Request{Method: MethodInstall, Name: "tool", Version: "2.0.0"},
+			expected: Response{ProtocolVersion: ProtocolVersion, URL: "corp://tool@2.0.0"},
+		},
+		{
+			name:     "Version",
+			req:      Request{Method: MethodVersion, Name: "tool"},
+			expected: Response{ProtocolVersion: ProtocolVersion, Version: "1.2.3"},
+		},
+		{
+			name:        "Plugin error",
+			req:         Request{Method: MethodInstall, Name: "tool", Options: map[string]string{"fail": "true"}},
+			expectError: true,
+		},
+		{
+			name:        "Unknown method",
+			req:         Request{Method: "explode"},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := Call(context.Background(), path, &tc.req, io.Discard)
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("Expected an error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Did not expect an error but got: %v", err)
+			}
+			if *resp != tc.expected {
+				t.Errorf("Expected %+v but got %+v", tc.expected, *resp)
+			}
+		})
+	}
+}
+
+func TestInstallListRemove(t *testing.T) {
+	source := testPlugin(t)
+
+	dir, err := os.MkdirTemp("", "depman-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	target, err := Install(source, dir, "")
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if target != filepath.Join(dir, BinaryPrefix+"corp") {
+		t.Errorf("Expected plugin to be named after its file but got %s", target)
+	}
+
+	if path, ok := Find(dir, "corp"); !ok || path != target {
+		t.Errorf("Expected to find installed plugin but got %s", path)
+	}
+
+	plugins, err := List(dir)
+	if err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if len(plugins) != 1 || plugins[0].Name != "corp" {
+		t.Errorf("Expected the corp plugin to be listed but got %+v", plugins)
+	}
+
+	if err := Remove(dir, "corp"); err != nil {
+		t.Fatalf("Did not expect an error but got: %v", err)
+	}
+	if err := Remove(dir, "corp"); err == nil {
+		t.Errorf("Expected an error removing a missing plugin but got none")
+	}
+
+	t.Run("Rejects non-plugins", func(t *testing.T) {
+		notPlugin := filepath.Join(dir, "not-a-plugin")
+		os.WriteFile(notPlugin, []byte("#!/bin/sh\necho hello\n"), 0755)
+
+		if _, err := Install(notPlugin, dir, "bad"); err == nil {
+			t.Errorf("Expected an error but got none")
+		}
+	})
+}